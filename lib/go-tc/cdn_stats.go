@@ -0,0 +1,73 @@
+package tc
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+// HealthDataResponse is the response of /cdns/health and /cdns/{name}/health.
+type HealthDataResponse struct {
+	Response HealthData `json:"response"`
+}
+
+// HealthData is the number of online and offline reported edge caches, in total and per cachegroup, according to the CDNs' Traffic Monitors. UnavailableCDNs are the CDNs whose monitors couldn't be reached, and whose caches aren't counted.
+type HealthData struct {
+	TotalOffline    int                    `json:"totalOffline"`
+	TotalOnline     int                    `json:"totalOnline"`
+	CacheGroups     []HealthDataCacheGroup `json:"cachegroups"`
+	UnavailableCDNs []string               `json:"unavailableCdns"`
+}
+
+// HealthDataCacheGroup is the number of online and offline reported edge caches in a cachegroup.
+type HealthDataCacheGroup struct {
+	Offline int    `json:"offline"`
+	Online  int    `json:"online"`
+	Name    string `json:"name"`
+}
+
+// CDNCapacityResponse is the response of /cdns/capacity.
+type CDNCapacityResponse struct {
+	Response CDNCapacity `json:"response"`
+}
+
+// CDNCapacity is the percentage of the edge caches' bandwidth capacity which is available, utilized, unavailable, or in maintenance, according to the CDNs' Traffic Monitors. UnavailableCDNs are the CDNs whose monitors couldn't be reached, and whose caches aren't counted.
+type CDNCapacity struct {
+	AvailablePercent   float64  `json:"availablePercent"`
+	UnavailablePercent float64  `json:"unavailablePercent"`
+	UtilizedPercent    float64  `json:"utilizedPercent"`
+	MaintenancePercent float64  `json:"maintenancePercent"`
+	UnavailableCDNs    []string `json:"unavailableCdns"`
+}
+
+// CDNRoutingResponse is the response of /cdns/routing.
+type CDNRoutingResponse struct {
+	Response CDNRouting `json:"response"`
+}
+
+// CDNRouting is the percentage of requests routed by each method, across all online Traffic Routers.
+type CDNRouting struct {
+	StaticRoute       float64 `json:"staticRoute"`
+	Geo               float64 `json:"geo"`
+	Err               float64 `json:"err"`
+	Fed               float64 `json:"fed"`
+	CZ                float64 `json:"cz"`
+	DeepCZ            float64 `json:"deepCz"`
+	RegionalAlternate float64 `json:"regionalAlternate"`
+	DSR               float64 `json:"dsr"`
+	Miss              float64 `json:"miss"`
+	RegionalDenied    float64 `json:"regionalDenied"`
+}
@@ -185,3 +185,14 @@ type CRConfigStats struct {
 	TMUser          *string `json:"tm_user,omitempty"`
 	TMVersion       *string `json:"tm_version,omitempty"`
 }
+
+// CRConfigValidation is the result of validating a CRConfig before it's snapshotted. Errors block the snapshot, unless it's forced. Warnings don't.
+type CRConfigValidation struct {
+	Errors   []string `json:"errors"`
+	Warnings []string `json:"warnings"`
+}
+
+// CRConfigValidationResponse is the response of the CRConfig validation endpoint.
+type CRConfigValidationResponse struct {
+	Response CRConfigValidation `json:"response"`
+}
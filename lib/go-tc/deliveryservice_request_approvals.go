@@ -0,0 +1,83 @@
+package tc
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+// Verdicts a reviewer may give a delivery service request, as the verdict of a comment on it. A comment without a verdict isn't a review.
+const (
+	RequestVerdictApprove = "approve"
+	RequestVerdictReject  = "reject"
+)
+
+// DeliveryServiceRequestApprovalPoliciesResponse is the response of the /deliveryservice_request_approval_policies endpoint.
+type DeliveryServiceRequestApprovalPoliciesResponse struct {
+	Response []DeliveryServiceRequestApprovalPolicy `json:"response"`
+}
+
+// DeliveryServiceRequestApprovalPolicy requires a number of approvals from its approvers before requests for delivery services of a tenant, or of one of its descendants, and of a CDN may become pending. A policy without a tenant or CDN applies to every tenant or CDN. Where several policies apply, each must be satisfied.
+type DeliveryServiceRequestApprovalPolicy struct {
+	CDNID             *int      `json:"cdnId" db:"cdn_id"`
+	CDNName           *string   `json:"cdnName" db:"cdn_name"`
+	ID                int       `json:"id" db:"id"`
+	LastUpdated       TimeNoMod `json:"lastUpdated" db:"last_updated"`
+	RequiredApprovals int       `json:"requiredApprovals" db:"required_approvals"`
+	TenantID          *int      `json:"tenantId" db:"tenant_id"`
+	Tenant            *string   `json:"tenant" db:"tenant"`
+	// ApproverIDs are the users, and ApproverRoleIDs the roles of the users, whose verdicts count towards the policy.
+	ApproverIDs     []int `json:"approverIds" db:"-"`
+	ApproverRoleIDs []int `json:"approverRoleIds" db:"-"`
+}
+
+type DeliveryServiceRequestApprovalPolicyNullable struct {
+	CDNID             *int       `json:"cdnId" db:"cdn_id"`
+	CDNName           *string    `json:"cdnName" db:"cdn_name"`
+	ID                *int       `json:"id" db:"id"`
+	LastUpdated       *TimeNoMod `json:"lastUpdated" db:"last_updated"`
+	RequiredApprovals *int       `json:"requiredApprovals" db:"required_approvals"`
+	TenantID          *int       `json:"tenantId" db:"tenant_id"`
+	Tenant            *string    `json:"tenant" db:"tenant"`
+	ApproverIDs       []int      `json:"approverIds" db:"-"`
+	ApproverRoleIDs   []int      `json:"approverRoleIds" db:"-"`
+}
+
+// DeliveryServiceRequestApprovalsResponse is the response of the /deliveryservice_requests/{id}/approvals endpoint.
+type DeliveryServiceRequestApprovalsResponse struct {
+	Response DeliveryServiceRequestApprovals `json:"response"`
+}
+
+// DeliveryServiceRequestApprovals is the review state of a delivery service request. It is satisfied, and the request may become pending, if each policy applying to it has the approvals it requires from its approvers, and no approver's verdict is a rejection. RequiredApprovals is the most approvals any of the policies requires, and Approvals and Rejections count only the verdicts of approvers.
+type DeliveryServiceRequestApprovals struct {
+	RequiredApprovals int  `json:"requiredApprovals"`
+	Approvals         int  `json:"approvals"`
+	Rejections        int  `json:"rejections"`
+	Satisfied         bool `json:"satisfied"`
+	// Reviews are the current verdict of each reviewer, that is, of their latest comment with a verdict.
+	Reviews []DeliveryServiceRequestReview `json:"reviews"`
+}
+
+// DeliveryServiceRequestReview is a reviewer's verdict on a delivery service request.
+type DeliveryServiceRequestReview struct {
+	ReviewerID  int       `json:"reviewerId" db:"author_id"`
+	Reviewer    string    `json:"reviewer" db:"author"`
+	Verdict     string    `json:"verdict" db:"verdict"`
+	CommentID   int       `json:"commentId" db:"id"`
+	LastUpdated TimeNoMod `json:"lastUpdated" db:"last_updated"`
+	// Approver is whether the reviewer is an approver of a policy applying to the request. Only the verdicts of approvers count.
+	Approver bool `json:"approver" db:"-"`
+}
@@ -31,6 +31,7 @@ type DeliveryServiceRequestComment struct {
 	LastUpdated              TimeNoMod `json:"lastUpdated" db:"last_updated"`
 	Value                    string    `json:"value" db:"value"`
 	XMLID                    string    `json:"xmlId" db:"xml_id"`
+	Verdict                  *string   `json:"verdict" db:"verdict"`
 }
 
 type DeliveryServiceRequestCommentNullable struct {
@@ -41,4 +42,5 @@ type DeliveryServiceRequestCommentNullable struct {
 	LastUpdated              *TimeNoMod `json:"lastUpdated" db:"last_updated"`
 	Value                    *string    `json:"value" db:"value"`
 	XMLID                    *string    `json:"xmlId" db:"xml_id"`
+	Verdict                  *string    `json:"verdict" db:"verdict"`
 }
@@ -63,6 +63,36 @@ type DeliveryServiceRequestNullable struct {
 	DeliveryService *DeliveryServiceNullable `json:"deliveryService" db:"deliveryservice"`
 	Status          *RequestStatus           `json:"status" db:"status"`
 	XMLID           *string                  `json:"-" db:"xml_id"`
+	// ChangeLogID is the change log entry of the delivery service change made when the request was fulfilled.
+	ChangeLogID *int `json:"changeLogId" db:"change_log_id"`
+}
+
+// Delivery service request change types.
+const (
+	RequestChangeTypeCreate = "create"
+	RequestChangeTypeUpdate = "update"
+	RequestChangeTypeDelete = "delete"
+)
+
+// DeliveryServiceRequestFulfillReq is the request to fulfill a delivery service request.
+type DeliveryServiceRequestFulfillReq struct {
+	// QueueUpdates is whether to queue updates on the servers affected by the delivery service change.
+	QueueUpdates bool `json:"queueUpdates"`
+}
+
+// DeliveryServiceRequestFulfillmentResponse is the response of the /deliveryservice_requests/{id}/fulfill endpoint.
+type DeliveryServiceRequestFulfillmentResponse struct {
+	Response DeliveryServiceRequestFulfillment `json:"response"`
+	Alerts
+}
+
+// DeliveryServiceRequestFulfillment is a completed delivery service request, and the change it made.
+type DeliveryServiceRequestFulfillment struct {
+	Request DeliveryServiceRequestNullable `json:"request"`
+	// DeliveryService is the delivery service as created or updated, or nil if it was deleted.
+	DeliveryService *DeliveryServiceNullable `json:"deliveryService"`
+	// QueuedServers are the host names of the servers updates were queued on.
+	QueuedServers []string `json:"queuedServers"`
 }
 
 // UnmarshalJSON implements the json.Unmarshaller interface to suppress unmarshalling for IDNoMod
@@ -19,6 +19,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strconv"
+	"time"
 )
 
 // DeliveryServiceSSLKeysResponse ...
@@ -84,3 +85,63 @@ func (v *DeliveryServiceSSLKeys) UnmarshalJSON(b []byte) (err error) {
 	}
 	return err
 }
+
+const (
+	// SSLKeysSignerSelf signs generated delivery service certificates with their own key.
+	SSLKeysSignerSelf = "self"
+	// SSLKeysSignerCA signs generated delivery service certificates with the internal certificate authority configured in Traffic Ops, for lab CDNs whose clients trust it.
+	SSLKeysSignerCA = "ca"
+)
+
+// DeliveryServiceSSLKeysGenerateReq is the request to generate the SSL keys of a delivery service.
+type DeliveryServiceSSLKeysGenerateReq struct {
+	// Hostname is the certificate common name. The default is the first host of the delivery service's host regexes, which is a wildcard for HTTP delivery services.
+	Hostname     *string `json:"hostname"`
+	Country      *string `json:"country"`
+	State        *string `json:"state"`
+	City         *string `json:"city"`
+	Organization *string `json:"organization"`
+	BusinessUnit *string `json:"businessUnit"`
+	// Version is the version of the keys, which can't be less than the delivery service's SSL key version. The default is one more than the delivery service's SSL key version.
+	Version *int `json:"version"`
+	// Signer is SSLKeysSignerSelf or SSLKeysSignerCA. The default is SSLKeysSignerSelf.
+	Signer *string `json:"signer"`
+}
+
+// Chain statuses of a DeliveryServiceSSLCertificate.
+const (
+	// SSLChainStatusValid is a certificate which chains to a trusted root, or to the internal certificate authority.
+	SSLChainStatusValid = "valid"
+	// SSLChainStatusSelfSigned is a current certificate signed by its own key, such as a generated certificate which hasn't been replaced by one from a CA.
+	SSLChainStatusSelfSigned = "self-signed"
+	// SSLChainStatusInvalid is a certificate which can't be parsed, has expired, or doesn't chain to a trusted root.
+	SSLChainStatusInvalid = "invalid"
+	// SSLChainStatusMissing is an HTTPS delivery service without SSL keys.
+	SSLChainStatusMissing = "missing"
+)
+
+// DeliveryServiceSSLCertificatesResponse is the response of the /deliveryservices/sslkeys/certificates endpoint.
+type DeliveryServiceSSLCertificatesResponse struct {
+	Response []DeliveryServiceSSLCertificate `json:"response"`
+}
+
+// DeliveryServiceSSLCertificate is the certificate of the latest SSL keys of an HTTPS delivery service. The certificate fields are empty if the delivery service has no keys, or its certificate can't be parsed.
+type DeliveryServiceSSLCertificate struct {
+	DeliveryService string     `json:"deliveryService"`
+	CDN             string     `json:"cdn"`
+	Version         *int       `json:"version"`
+	Subject         string     `json:"subject"`
+	SANs            []string   `json:"sans"`
+	Issuer          string     `json:"issuer"`
+	NotBefore       *time.Time `json:"notBefore"`
+	NotAfter        *time.Time `json:"notAfter"`
+	// DaysUntilExpiration is the whole days until NotAfter, which are negative once the certificate has expired.
+	DaysUntilExpiration *int `json:"daysUntilExpiration"`
+	// ChainStatus is one of the SSLChainStatus constants. ChainError is why the chain is invalid.
+	ChainStatus string `json:"chainStatus"`
+	ChainError  string `json:"chainError,omitempty"`
+	// RoutingHostname is the name clients request the delivery service by, from its first host regex. It's empty if that regex isn't a routed name or a single hostname.
+	RoutingHostname string `json:"routingHostname"`
+	// HostnameMismatch is whether the certificate isn't valid for the routing hostname.
+	HostnameMismatch bool `json:"hostnameMismatch"`
+}
@@ -32,6 +32,11 @@ type DeliveryServicesResponse struct {
 	Response []DeliveryService `json:"response"`
 }
 
+// DeliveryServicesNullableResponse ...
+type DeliveryServicesNullableResponse struct {
+	Response []DeliveryServiceNullable `json:"response"`
+}
+
 // CreateDeliveryServiceResponse ...
 type CreateDeliveryServiceResponse struct {
 	Response []DeliveryService      `json:"response"`
@@ -171,6 +176,7 @@ type DeliveryServiceNullable struct {
 	TRRequestHeaders         *string                 `json:"trRequestHeaders" db:"tr_request_headers"`
 	TRResponseHeaders        *string                 `json:"trResponseHeaders" db:"tr_response_headers"`
 	TenantID                 *int                    `json:"tenantId" db:"tenant_id"`
+	Tenant                   *string                 `json:"tenant"`
 	TypeName                 *string                 `json:"typeName"`
 	TypeID                   *int                    `json:"typeId" db:"type"`
 	XMLID                    *string                 `json:"xmlId" db:"xml_id"`
@@ -0,0 +1,114 @@
+package tc
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"encoding/json"
+)
+
+// DNSSEC key statuses. A CDN or delivery service has one new key of each type; keys it replaced are kept until they expire, so cached signatures still validate.
+const (
+	// DNSSECKeyStatusNew is the status of the current key.
+	DNSSECKeyStatusNew = "new"
+	// DNSSECKeyStatusExisting is the status of a key replaced by generating new keys, which expires when the new key is effective.
+	DNSSECKeyStatusExisting = "existing"
+	// DNSSECKeyStatusExpired is the status of a key replaced by a rollover.
+	DNSSECKeyStatusExpired = "expired"
+)
+
+// DNSSECKeys are the DNSSEC keys of a CDN, by the CDN name for its top level domain, and by the xml_id of each of its delivery services. This is the form stored in the secret store and fetched by Traffic Router.
+type DNSSECKeys map[string]DNSSECKeySet
+
+// DNSSECKeysResponse is the response of the /cdns/name/{name}/dnsseckeys endpoint.
+type DNSSECKeysResponse struct {
+	Response DNSSECKeys `json:"response"`
+}
+
+// DNSSECKeySet is the zone signing and key signing keys of a zone.
+type DNSSECKeySet struct {
+	ZSK []DNSSECKey `json:"zsk"`
+	KSK []DNSSECKey `json:"ksk"`
+}
+
+// DNSSECKey is a DNSSEC key pair of a zone. Dates are Unix epoch seconds.
+type DNSSECKey struct {
+	InceptionDateUnix  int64 `json:"inceptionDate"`
+	ExpirationDateUnix int64 `json:"expirationDate"`
+	// Name is the zone name, with a trailing period.
+	Name string `json:"name"`
+	// TTL is the DNSKEY TTL. It's a number, but keys generated by the Perl Traffic Ops may have it as a string.
+	TTL               json.Number `json:"ttl"`
+	Status            string      `json:"status"`
+	EffectiveDateUnix int64       `json:"effectiveDate"`
+	// Private is the base64 of the private key, in the BIND private key format.
+	Private string `json:"private"`
+	// Public is the base64 of the DNSKEY record, in the zone file format.
+	Public string `json:"public"`
+	// DSRecord is the DS record of the key, for the parent zone. It's only set on CDN key signing keys.
+	DSRecord *DNSSECKeyDSRecord `json:"dsRecord,omitempty"`
+}
+
+// DNSSECKeyDSRecord is the DS record of a key signing key, as stored with the key.
+type DNSSECKeyDSRecord struct {
+	Algorithm  int    `json:"algorithm"`
+	DigestType int    `json:"digestType"`
+	Digest     string `json:"digest"`
+}
+
+// CDNDNSSECGenerateReq is the request to generate new DNSSEC keys for a CDN and its delivery services. Numbers may be given as JSON strings, like the Perl Traffic Ops accepts.
+type CDNDNSSECGenerateReq struct {
+	// Key is the CDN name.
+	Key *string `json:"key"`
+	// Name is the CDN domain name.
+	Name              *string      `json:"name"`
+	TTL               *json.Number `json:"ttl"`
+	KSKExpirationDays *json.Number `json:"kskExpirationDays"`
+	ZSKExpirationDays *json.Number `json:"zskExpirationDays"`
+	// EffectiveDateUnix is when the new keys become effective, and the keys they replace expire. The default is now.
+	EffectiveDateUnix *json.Number `json:"effectiveDate"`
+}
+
+// CDNDNSSECKSKGenerateReq is the request to roll over the key signing key of a CDN's top level domain.
+type CDNDNSSECKSKGenerateReq struct {
+	// ExpirationDays is the lifetime of the new key. The default is the lifetime of the key it replaces.
+	ExpirationDays *int64 `json:"expirationDays"`
+	// EffectiveDateUnix is when the new key becomes effective, and the key it replaces expires. The default is now.
+	EffectiveDateUnix *int64 `json:"effectiveDate"`
+}
+
+// CDNDNSSECDSRecordsResponse is the response of the /cdns/{name}/dnsseckeys/ds endpoint.
+type CDNDNSSECDSRecordsResponse struct {
+	Response []CDNDNSSECDSRecord `json:"response"`
+}
+
+// CDNDNSSECDSRecord is a DS record of a key signing key of a CDN's top level domain, to be published in the parent zone.
+type CDNDNSSECDSRecord struct {
+	Name               string `json:"name"`
+	TTL                int64  `json:"ttl"`
+	KeyTag             int    `json:"keyTag"`
+	Algorithm          int    `json:"algorithm"`
+	DigestType         int    `json:"digestType"`
+	Digest             string `json:"digest"`
+	Status             string `json:"status"`
+	EffectiveDateUnix  int64  `json:"effectiveDate"`
+	ExpirationDateUnix int64  `json:"expirationDate"`
+	// Text is the record in the zone file format.
+	Text string `json:"text"`
+}
@@ -0,0 +1,107 @@
+package tc
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"encoding/json"
+)
+
+// Event types published to the event stream and webhooks.
+const (
+	// EventTypeChangeLog is published for every change log entry.
+	EventTypeChangeLog = "changelog"
+	// EventTypeSnapshot is published when a CDN's CRConfig is snapshotted or rolled back.
+	EventTypeSnapshot = "snapshot"
+	// EventTypeQueueUpdate is published when server updates or revalidations are queued or dequeued.
+	EventTypeQueueUpdate = "queue_update"
+	// EventTypeSSLCertificate is published when the periodic check finds a delivery service certificate expiring, invalid, or not valid for the delivery service's routing hostname.
+	EventTypeSSLCertificate = "ssl_certificate"
+)
+
+// EventTypes are the types of events which may be published.
+var EventTypes = []string{EventTypeChangeLog, EventTypeSnapshot, EventTypeQueueUpdate, EventTypeSSLCertificate}
+
+// Event is a change in Traffic Ops, as published to the event stream and webhooks. Data is specific to the event type.
+type Event struct {
+	ID      int64           `json:"id"`
+	Type    string          `json:"type"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+	User    *string         `json:"user"`
+	Created TimeNoMod       `json:"created"`
+}
+
+// SnapshotEventData is the data of snapshot events.
+type SnapshotEventData struct {
+	CDN string `json:"cdn"`
+	// RolledBackTo is the id of the snapshot in the CDN's history which was made current again, if the snapshot was rolled back rather than made.
+	RolledBackTo *int `json:"rolledBackTo,omitempty"`
+}
+
+// QueueUpdateEventData is the data of queue update events.
+type QueueUpdateEventData struct {
+	Action string `json:"action"`
+	Reval  bool   `json:"reval"`
+	// Level is the server type prefix the request was limited to, if any.
+	Level string `json:"level,omitempty"`
+	// Servers are the host names of the servers whose pending flag was set or cleared.
+	Servers []string `json:"servers"`
+}
+
+// SSLCertificateEventData is the data of SSL certificate events.
+type SSLCertificateEventData struct {
+	DeliveryServiceSSLCertificate
+	// Warnings are the problems found with the certificate.
+	Warnings []string `json:"warnings"`
+}
+
+// WebhooksResponse is the response of the /webhooks endpoint.
+type WebhooksResponse struct {
+	Response []WebhookNullable `json:"response"`
+}
+
+// WebhookNullable is an outbound webhook. Its secret signs deliveries, and is never returned once set.
+type WebhookNullable struct {
+	ID  *int    `json:"id" db:"id"`
+	URL *string `json:"url" db:"url"`
+	// Secret is the key of the HMAC-SHA256 signature of each delivery, sent in the X-TC-Signature header.
+	Secret *string `json:"secret,omitempty" db:"secret"`
+	// EventTypes are the event types delivered. If empty, all events are delivered.
+	EventTypes  []string   `json:"eventTypes" db:"event_types"`
+	Active      *bool      `json:"active" db:"active"`
+	LastUpdated *TimeNoMod `json:"lastUpdated" db:"last_updated"`
+}
+
+// WebhookDeadLettersResponse is the response of the /webhooks/dead_letters endpoint.
+type WebhookDeadLettersResponse struct {
+	Response []WebhookDeadLetter `json:"response"`
+}
+
+// WebhookDeadLetter is an event which failed to be delivered to a webhook, after every attempt.
+type WebhookDeadLetter struct {
+	ID        int64           `json:"id"`
+	WebhookID int             `json:"webhookId"`
+	EventID   int64           `json:"eventId"`
+	EventType string          `json:"eventType"`
+	Payload   json.RawMessage `json:"payload"`
+	Attempts  int             `json:"attempts"`
+	LastError string          `json:"lastError"`
+	Created   TimeNoMod       `json:"created"`
+}
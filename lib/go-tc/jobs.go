@@ -0,0 +1,57 @@
+package tc
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+// JobKeywordPurge is the keyword of content invalidation jobs, which are the only jobs Traffic Ops creates.
+const JobKeywordPurge = "PURGE"
+
+// JobsResponse is the response of the /jobs endpoint.
+type JobsResponse struct {
+	Response []Job `json:"response"`
+}
+
+// Job is a content invalidation job, which invalidates the cached content matching its asset URL regex, until its TTL expires.
+type Job struct {
+	ID                int    `json:"id" db:"id"`
+	AssetURL          string `json:"assetUrl" db:"asset_url"`
+	CreatedBy         string `json:"createdBy" db:"created_by"`
+	DeliveryService   string `json:"deliveryService" db:"deliveryservice"`
+	DeliveryServiceID int    `json:"dsId" db:"ds_id"`
+	Keyword           string `json:"keyword" db:"keyword"`
+	Parameters        string `json:"parameters" db:"parameters"`
+	StartTime         string `json:"startTime" db:"start_time"`
+}
+
+// JobNullable is a Job, plus the fields used to create or update one. The asset URL and parameters are built from the regex and TTL, and the start time is delayed by a minute unless the job is urgent.
+type JobNullable struct {
+	ID                *int    `json:"id" db:"id"`
+	AssetURL          *string `json:"assetUrl" db:"asset_url"`
+	CreatedBy         *string `json:"createdBy" db:"created_by"`
+	DeliveryService   *string `json:"deliveryService" db:"deliveryservice"`
+	DeliveryServiceID *int    `json:"dsId" db:"ds_id"`
+	Keyword           *string `json:"keyword" db:"keyword"`
+	Parameters        *string `json:"parameters" db:"parameters"`
+	StartTime         *string `json:"startTime" db:"start_time"`
+	// Regex is the path regex to invalidate, relative to the delivery service origin.
+	Regex *string `json:"regex,omitempty" db:"-"`
+	// TTL is the number of hours the invalidation lasts.
+	TTL    *int  `json:"ttl,omitempty" db:"-"`
+	Urgent *bool `json:"urgent,omitempty" db:"-"`
+}
@@ -0,0 +1,31 @@
+package tc
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+// PaginationLinks is returned by paginated API endpoints when there are more results. Next is the path and query of the next page.
+type PaginationLinks struct {
+	Next string `json:"next,omitempty"`
+}
+
+// PaginationSummary is returned by paginated API endpoints. Count is the number of results of all pages, and Next is the path and query of the next page, if there are more results.
+type PaginationSummary struct {
+	Count int    `json:"count"`
+	Next  string `json:"next,omitempty"`
+}
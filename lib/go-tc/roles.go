@@ -0,0 +1,45 @@
+package tc
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+// RolesResponse ...
+type RolesResponse struct {
+	Response []Role `json:"response"`
+}
+
+// Role is a named set of capabilities, assigned to users.
+type Role struct {
+	ID           int       `json:"id" db:"id"`
+	Name         string    `json:"name" db:"name"`
+	Description  string    `json:"description" db:"description"`
+	PrivLevel    int       `json:"privLevel" db:"priv_level"`
+	Capabilities []string  `json:"capabilities" db:"capabilities"`
+	LastUpdated  TimeNoMod `json:"lastUpdated" db:"last_updated"`
+}
+
+// RoleNullable ...
+type RoleNullable struct {
+	ID           *int       `json:"id" db:"id"`
+	Name         *string    `json:"name" db:"name"`
+	Description  *string    `json:"description" db:"description"`
+	PrivLevel    *int       `json:"privLevel" db:"priv_level"`
+	Capabilities *[]string  `json:"capabilities" db:"capabilities"`
+	LastUpdated  *TimeNoMod `json:"lastUpdated" db:"last_updated"`
+}
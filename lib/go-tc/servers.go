@@ -72,6 +72,7 @@ type Server struct {
 	Status           string              `json:"status" db:"status"`
 	StatusID         int                 `json:"statusId" db:"status_id"`
 	TCPPort          int                 `json:"tcpPort" db:"tcp_port"`
+	TenantID         *int                `json:"tenantId" db:"tenant_id"`
 	Type             string              `json:"type" db:"server_type"`
 	TypeID           int                 `json:"typeId" db:"server_type_id"`
 	UpdPending       bool                `json:"updPending" db:"upd_pending"`
@@ -121,6 +122,7 @@ type ServerNullable struct {
 	Status           *string              `json:"status" db:"status"`
 	StatusID         *int                 `json:"statusId" db:"status_id"`
 	TCPPort          *int                 `json:"tcpPort" db:"tcp_port"`
+	TenantID         *int                 `json:"tenantId" db:"tenant_id"`
 	Type             string               `json:"type" db:"server_type"`
 	TypeID           *int                 `json:"typeId" db:"server_type_id"`
 	UpdPending       *bool                `json:"updPending" db:"upd_pending"`
@@ -138,3 +140,80 @@ type ServerUpdateStatus struct {
 	ParentPending      bool   `json:"parent_pending"`
 	ParentRevalPending bool   `json:"parent_reval_pending"`
 }
+
+// ServerTypeCountsResponse is the response of /servers/totals.
+type ServerTypeCountsResponse struct {
+	Response []ServerTypeCount `json:"response"`
+}
+
+// ServerTypeCount is the number of servers of a type.
+type ServerTypeCount struct {
+	Type  string `json:"type"`
+	Count int    `json:"count"`
+}
+
+// ServerStatusCountsResponse is the response of /servers/status, of the number of servers with each status.
+type ServerStatusCountsResponse struct {
+	Response map[string]int `json:"response"`
+}
+
+const QueueUpdateActionQueue = "queue"
+const QueueUpdateActionDequeue = "dequeue"
+
+const QueueUpdateLevelEdge = "EDGE"
+const QueueUpdateLevelMid = "MID"
+
+// ServerQueueUpdateRequest is the request to queue or dequeue updates or revalidation on a server, or the servers in a cachegroup or CDN.
+type ServerQueueUpdateRequest struct {
+	// Action is either "queue" or "dequeue".
+	Action string `json:"action"`
+	// Reval is whether to queue or dequeue revalidation, rather than updates.
+	Reval bool `json:"reval"`
+	// Level limits a cachegroup or CDN to its edges or mids, "EDGE" or "MID", by server type. If empty, all servers are queued.
+	Level string `json:"level"`
+	// CDN or CDNID is the CDN of the cachegroup servers to queue. One is required for cachegroups, and ignored otherwise.
+	CDN   *string `json:"cdn"`
+	CDNID *int    `json:"cdnId"`
+}
+
+// ServerQueueUpdateResponse is the response of /servers/{id}/queue_update.
+type ServerQueueUpdateResponse struct {
+	Response ServerQueueUpdate `json:"response"`
+	Alerts
+}
+
+type ServerQueueUpdate struct {
+	ServerID int    `json:"serverId"`
+	Action   string `json:"action"`
+	Reval    bool   `json:"reval"`
+}
+
+// CachegroupQueueUpdatesResponse is the response of /cachegroups/{id}/queue_update.
+type CachegroupQueueUpdatesResponse struct {
+	Response CachegroupQueueUpdates `json:"response"`
+	Alerts
+}
+
+type CachegroupQueueUpdates struct {
+	CachegroupID   int      `json:"cachegroupId"`
+	CachegroupName string   `json:"cachegroupName"`
+	CDN            string   `json:"cdn"`
+	Action         string   `json:"action"`
+	Reval          bool     `json:"reval"`
+	Level          string   `json:"level"`
+	ServerNames    []string `json:"serverNames"`
+}
+
+// CDNQueueUpdatesResponse is the response of /cdns/{id}/queue_update.
+type CDNQueueUpdatesResponse struct {
+	Response CDNQueueUpdates `json:"response"`
+	Alerts
+}
+
+type CDNQueueUpdates struct {
+	CDNID       int      `json:"cdnId"`
+	Action      string   `json:"action"`
+	Reval       bool     `json:"reval"`
+	Level       string   `json:"level"`
+	ServerNames []string `json:"serverNames"`
+}
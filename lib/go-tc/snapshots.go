@@ -0,0 +1,61 @@
+package tc
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+// SnapshotHistory is a CRConfig snapshot of a CDN kept in the snapshot history, without its content.
+type SnapshotHistory struct {
+	ID          int     `json:"id"`
+	CDN         string  `json:"cdn"`
+	TMUser      *string `json:"tmUser"`
+	LastUpdated Time    `json:"lastUpdated"`
+}
+
+// SnapshotHistoryResponse is the response of the snapshot history endpoint, newest first.
+type SnapshotHistoryResponse struct {
+	Response []SnapshotHistory `json:"response"`
+}
+
+// SnapshotDiff is the difference between the current CRConfig snapshot of a CDN and a new CRConfig, as would be created by snapshotting the CDN now.
+type SnapshotDiff struct {
+	ContentServers   SnapshotDiffSet      `json:"contentServers"`
+	ContentRouters   SnapshotDiffSet      `json:"contentRouters"`
+	Monitors         SnapshotDiffSet      `json:"monitors"`
+	DeliveryServices SnapshotDiffSet      `json:"deliveryServices"`
+	Config           []SnapshotConfigDiff `json:"config"`
+}
+
+// SnapshotDiffSet is the sorted names of the CRConfig objects of one kind which were added, removed, or changed.
+type SnapshotDiffSet struct {
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+	Changed []string `json:"changed"`
+}
+
+// SnapshotConfigDiff is a CRConfig config parameter which was added, removed, or changed. Old is null if the parameter was added, and New is null if it was removed.
+type SnapshotConfigDiff struct {
+	Name string      `json:"name"`
+	Old  interface{} `json:"old"`
+	New  interface{} `json:"new"`
+}
+
+// SnapshotDiffResponse is the response of the snapshot diff endpoint.
+type SnapshotDiffResponse struct {
+	Response SnapshotDiff `json:"response"`
+}
@@ -35,6 +35,16 @@ type Tenant struct {
 	ParentName string `json:"parentName,omitempty"`
 }
 
+// TenantNullable is used as the CRUD representation of a tenant, so that absent fields can be distinguished from zero values.
+type TenantNullable struct {
+	ID          *int       `json:"id" db:"id"`
+	Name        *string    `json:"name" db:"name"`
+	Active      *bool      `json:"active" db:"active"`
+	ParentID    *int       `json:"parentId" db:"parent_id"`
+	ParentName  *string    `json:"parentName,omitempty" db:"parent_name"`
+	LastUpdated *TimeNoMod `json:"lastUpdated" db:"last_updated"`
+}
+
 // DeleteTenantResponse ...
 type DeleteTenantResponse struct {
 	Alerts []TenantAlert `json:"alerts"`
@@ -212,3 +212,15 @@ func TrafficMonitorTransformToMap(tmConfig *TrafficMonitorConfig) (*TrafficMonit
 
 	return &tm, nil
 }
+
+// TMCacheStats is the Traffic Monitor /publish/CacheStats response, of the latest values of each cache's stats.
+type TMCacheStats struct {
+	Caches map[CacheName]map[string][]TMCacheStat `json:"caches"`
+}
+
+// TMCacheStat is a value of a Traffic Monitor cache stat. Traffic Monitor serves all values as strings.
+type TMCacheStat struct {
+	Value string `json:"value"`
+	Time  int64  `json:"time"`
+	Span  uint64 `json:"span"`
+}
@@ -148,3 +148,14 @@ type tsdeliveryService struct {
 	Xmlid  string   `json:"xmlId"`
 	Remaps []string `json:"remaps"`
 }
+
+// TRStats is the Traffic Router /crs/stats response. Only the routing counts are unmarshalled.
+type TRStats struct {
+	Stats TRStatsStats `json:"stats"`
+}
+
+// TRStatsStats is the counts of each routing method, by the FQDN requested, of the Traffic Router's DNS and HTTP routing.
+type TRStatsStats struct {
+	DNSMap  map[string]map[string]float64 `json:"dnsMap"`
+	HTTPMap map[string]map[string]float64 `json:"httpMap"`
+}
@@ -19,6 +19,10 @@ package tc
  * under the License.
  */
 
+import (
+	"time"
+)
+
 // UsersResponse ...
 type UsersResponse struct {
 	Response []User `json:"response"`
@@ -40,8 +44,79 @@ type User struct {
 	LastUpdated  string `json:"lastUpdated,omitempty"`
 }
 
+// UserNullable is a Traffic Ops user, as returned by the 1.3 users endpoint. Secrets such as the password and registration token are never included.
+type UserNullable struct {
+	AddressLine1     *string    `json:"addressLine1" db:"address_line1"`
+	AddressLine2     *string    `json:"addressLine2" db:"address_line2"`
+	City             *string    `json:"city" db:"city"`
+	Company          *string    `json:"company" db:"company"`
+	Country          *string    `json:"country" db:"country"`
+	Email            *string    `json:"email" db:"email"`
+	FullName         *string    `json:"fullName" db:"full_name"`
+	GID              *int       `json:"gid" db:"gid"`
+	ID               *int       `json:"id" db:"id"`
+	LastUpdated      *TimeNoMod `json:"lastUpdated" db:"last_updated"`
+	NewUser          *bool      `json:"newUser" db:"new_user"`
+	PhoneNumber      *string    `json:"phoneNumber" db:"phone_number"`
+	PostalCode       *string    `json:"postalCode" db:"postal_code"`
+	PublicSSHKey     *string    `json:"publicSshKey" db:"public_ssh_key"`
+	RegistrationSent *TimeNoMod `json:"registrationSent" db:"registration_sent"`
+	Role             *int       `json:"role" db:"role"`
+	RoleName         *string    `json:"rolename" db:"role_name"`
+	StateOrProvince  *string    `json:"stateOrProvince" db:"state_or_province"`
+	Tenant           *string    `json:"tenant" db:"tenant"`
+	TenantID         *int       `json:"tenantId" db:"tenant_id"`
+	UID              *int       `json:"uid" db:"uid"`
+	Username         *string    `json:"username" db:"username"`
+}
+
+// UserSession is a server-side login session. Each session is a cookie, which is no longer valid once its session is revoked. LastSeen is only updated when the session isn't cached, so it may be behind by the user cache TTL.
+type UserSession struct {
+	ID         string    `json:"id"`
+	Created    time.Time `json:"created"`
+	LastSeen   time.Time `json:"lastSeen"`
+	RemoteAddr *string   `json:"remoteAddr"`
+	UserAgent  *string   `json:"userAgent"`
+}
+
+// UserSessionsResponse is the response of the user sessions endpoint.
+type UserSessionsResponse struct {
+	Response []UserSession `json:"response"`
+}
+
 // Credentials contains Traffic Ops login credentials
 type UserCredentials struct {
 	Username string `json:"u"`
 	Password string `json:"p"`
 }
+
+// APIToken is a long-lived, revocable token which authenticates API requests as its user, sent in an `Authorization: Bearer` header. The token itself is only returned when it's created, and only its hash is stored.
+type APIToken struct {
+	ID          int        `json:"id"`
+	Name        string     `json:"name"`
+	Expires     *time.Time `json:"expires"`
+	LastUpdated Time       `json:"lastUpdated"`
+}
+
+// APITokenRequest is the request to create an API token. Tokens without an expiration never expire, until they're revoked.
+type APITokenRequest struct {
+	Name    string     `json:"name"`
+	Expires *time.Time `json:"expires"`
+}
+
+// APITokenCreated is a newly created API token, which is the only time the token is returned.
+type APITokenCreated struct {
+	APIToken
+	Token string `json:"token"`
+}
+
+// APITokensResponse is the response of the API tokens endpoint.
+type APITokensResponse struct {
+	Response []APIToken `json:"response"`
+}
+
+// APITokenCreatedResponse is the response of creating an API token.
+type APITokenCreatedResponse struct {
+	Response APITokenCreated `json:"response"`
+	Alerts
+}
@@ -76,6 +76,7 @@ type Server struct {
 	Status           string              `json:"status" db:"status"`
 	StatusID         int                 `json:"statusId" db:"status_id"`
 	TCPPort          int                 `json:"tcpPort" db:"tcp_port"`
+	TenantID         *int                `json:"tenantId" db:"tenant_id"`
 	Type             string              `json:"type" db:"server_type"`
 	TypeID           int                 `json:"typeId" db:"server_type_id"`
 	UpdPending       bool                `json:"updPending" db:"upd_pending"`
@@ -125,6 +126,7 @@ type ServerNullable struct {
 	Status           *string              `json:"status" db:"status"`
 	StatusID         *int                 `json:"statusId" db:"status_id"`
 	TCPPort          *int                 `json:"tcpPort" db:"tcp_port"`
+	TenantID         *int                 `json:"tenantId" db:"tenant_id"`
 	Type             string               `json:"type" db:"server_type"`
 	TypeID           *int                 `json:"typeId" db:"server_type_id"`
 	UpdPending       *bool                `json:"updPending" db:"upd_pending"`
@@ -0,0 +1,31 @@
+/*
+
+    Licensed under the Apache License, Version 2.0 (the "License");
+    you may not use this file except in compliance with the License.
+    You may obtain a copy of the License at
+
+        http://www.apache.org/licenses/LICENSE-2.0
+
+    Unless required by applicable law or agreed to in writing, software
+    distributed under the License is distributed on an "AS IS" BASIS,
+    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+    See the License for the specific language governing permissions and
+    limitations under the License.
+*/
+
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE snapshot_history (
+    id bigserial primary key NOT NULL,
+    cdn text NOT NULL REFERENCES cdn (name) ON UPDATE CASCADE ON DELETE CASCADE,
+    content json NOT NULL,
+    last_updated timestamp WITH time zone NOT NULL DEFAULT now()
+);
+
+CREATE INDEX snapshot_history_cdn_idx ON snapshot_history (cdn, id);
+
+INSERT INTO snapshot_history (cdn, content, last_updated) SELECT cdn, content, last_updated FROM snapshot;
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE snapshot_history;
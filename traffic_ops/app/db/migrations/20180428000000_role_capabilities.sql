@@ -0,0 +1,182 @@
+/*
+
+    Licensed under the Apache License, Version 2.0 (the "License");
+    you may not use this file except in compliance with the License.
+    You may obtain a copy of the License at
+
+        http://www.apache.org/licenses/LICENSE-2.0
+
+    Unless required by applicable law or agreed to in writing, software
+    distributed under the License is distributed on an "AS IS" BASIS,
+    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+    See the License for the specific language governing permissions and
+    limitations under the License.
+*/
+
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+-- Traffic Ops Golang routes require a capability rather than a priv_level, so every route's capability must exist.
+INSERT INTO capability (name, description) VALUES ('asn-read', 'View ASN configuration') ON CONFLICT (name) DO NOTHING;
+INSERT INTO capability (name, description) VALUES ('asn-write', 'Create, edit or delete ASN configuration') ON CONFLICT (name) DO NOTHING;
+INSERT INTO capability (name, description) VALUES ('basic-read', 'Basic read operations. Every user should have this capability') ON CONFLICT (name) DO NOTHING;
+INSERT INTO capability (name, description) VALUES ('basic-write', 'Basic write operations. Every user should have this capability') ON CONFLICT (name) DO NOTHING;
+INSERT INTO capability (name, description) VALUES ('cache-config-files-read', 'View the generated cache configuration files') ON CONFLICT (name) DO NOTHING;
+INSERT INTO capability (name, description) VALUES ('cache-group-read', 'View cache-group configuration') ON CONFLICT (name) DO NOTHING;
+INSERT INTO capability (name, description) VALUES ('cache-group-write', 'Create, edit or delete cache-group configuration') ON CONFLICT (name) DO NOTHING;
+INSERT INTO capability (name, description) VALUES ('cdn-config-snapshot-read', 'View config snapshot at CDN level') ON CONFLICT (name) DO NOTHING;
+INSERT INTO capability (name, description) VALUES ('cdn-config-snapshot-write', 'Config snapshot write access at CDN level') ON CONFLICT (name) DO NOTHING;
+INSERT INTO capability (name, description) VALUES ('cdn-read', 'View CDN configuration') ON CONFLICT (name) DO NOTHING;
+INSERT INTO capability (name, description) VALUES ('cdn-write', 'Create, edit or delete CDN configuration') ON CONFLICT (name) DO NOTHING;
+INSERT INTO capability (name, description) VALUES ('division-read', 'View division configuration') ON CONFLICT (name) DO NOTHING;
+INSERT INTO capability (name, description) VALUES ('division-write', 'Create, edit or delete division configuration') ON CONFLICT (name) DO NOTHING;
+INSERT INTO capability (name, description) VALUES ('ds-cache-write', 'Create, edit or delete delivery-service cache assignment') ON CONFLICT (name) DO NOTHING;
+INSERT INTO capability (name, description) VALUES ('ds-read', 'View delivery-service configuration') ON CONFLICT (name) DO NOTHING;
+INSERT INTO capability (name, description) VALUES ('ds-request-assign', 'Assign delivery service requests') ON CONFLICT (name) DO NOTHING;
+INSERT INTO capability (name, description) VALUES ('ds-request-read', 'View delivery service requests and their comments') ON CONFLICT (name) DO NOTHING;
+INSERT INTO capability (name, description) VALUES ('ds-request-write', 'Create, edit or delete delivery service requests and their comments') ON CONFLICT (name) DO NOTHING;
+INSERT INTO capability (name, description) VALUES ('ds-security-keys-read', 'View delivery-service security keys') ON CONFLICT (name) DO NOTHING;
+INSERT INTO capability (name, description) VALUES ('ds-security-keys-write', 'Create, edit or delete delivery-service security keys') ON CONFLICT (name) DO NOTHING;
+INSERT INTO capability (name, description) VALUES ('ds-write', 'Create, edit or delete delivery-service configuration') ON CONFLICT (name) DO NOTHING;
+INSERT INTO capability (name, description) VALUES ('params-read', 'View parameters') ON CONFLICT (name) DO NOTHING;
+INSERT INTO capability (name, description) VALUES ('params-write', 'Create, edit or delete parameters') ON CONFLICT (name) DO NOTHING;
+INSERT INTO capability (name, description) VALUES ('phys-location-read', 'View physical location configuration') ON CONFLICT (name) DO NOTHING;
+INSERT INTO capability (name, description) VALUES ('phys-location-write', 'Create, edit or delete physical location configuration') ON CONFLICT (name) DO NOTHING;
+INSERT INTO capability (name, description) VALUES ('profile-read', 'View profiles') ON CONFLICT (name) DO NOTHING;
+INSERT INTO capability (name, description) VALUES ('profile-write', 'Create, edit or delete profiles') ON CONFLICT (name) DO NOTHING;
+INSERT INTO capability (name, description) VALUES ('region-read', 'View region configuration') ON CONFLICT (name) DO NOTHING;
+INSERT INTO capability (name, description) VALUES ('region-write', 'Create, edit or delete region configuration') ON CONFLICT (name) DO NOTHING;
+INSERT INTO capability (name, description) VALUES ('role-read', 'View role configuration') ON CONFLICT (name) DO NOTHING;
+INSERT INTO capability (name, description) VALUES ('role-write', 'Create, edit or delete role configuration') ON CONFLICT (name) DO NOTHING;
+INSERT INTO capability (name, description) VALUES ('server-read', 'View server configuration') ON CONFLICT (name) DO NOTHING;
+INSERT INTO capability (name, description) VALUES ('server-write', 'Create, edit or delete server configuration') ON CONFLICT (name) DO NOTHING;
+INSERT INTO capability (name, description) VALUES ('status-read', 'View the list of defined statuses') ON CONFLICT (name) DO NOTHING;
+INSERT INTO capability (name, description) VALUES ('status-write', 'Create, edit or delete statuses') ON CONFLICT (name) DO NOTHING;
+INSERT INTO capability (name, description) VALUES ('type-read', 'View types configuration') ON CONFLICT (name) DO NOTHING;
+INSERT INTO capability (name, description) VALUES ('type-write', 'Create, edit or delete type configuration') ON CONFLICT (name) DO NOTHING;
+
+-- Grant each existing role the capabilities of the routes its priv_level could use, keeping any it already has.
+INSERT INTO role_capability (role_id, cap_name)
+SELECT r.id, c.name FROM role AS r JOIN (VALUES
+    ('asn-read', 10),
+    ('basic-read', 10),
+    ('basic-write', 10),
+    ('cache-group-read', 10),
+    ('cdn-read', 10),
+    ('division-read', 10),
+    ('ds-read', 10),
+    ('ds-request-read', 10),
+    ('params-read', 10),
+    ('phys-location-read', 10),
+    ('profile-read', 10),
+    ('region-read', 10),
+    ('role-read', 10),
+    ('server-read', 10),
+    ('status-read', 10),
+    ('type-read', 10),
+    ('ds-request-write', 15),
+    ('asn-write', 20),
+    ('cache-config-files-read', 20),
+    ('cache-group-write', 20),
+    ('cdn-write', 20),
+    ('division-write', 20),
+    ('ds-cache-write', 20),
+    ('ds-request-assign', 20),
+    ('ds-write', 20),
+    ('params-write', 20),
+    ('phys-location-write', 20),
+    ('profile-write', 20),
+    ('region-write', 20),
+    ('server-write', 20),
+    ('status-write', 20),
+    ('type-write', 20),
+    ('cdn-config-snapshot-read', 30),
+    ('cdn-config-snapshot-write', 30),
+    ('ds-security-keys-read', 30),
+    ('ds-security-keys-write', 30),
+    ('role-write', 30)
+) AS c (name, priv_level) ON r.priv_level >= c.priv_level
+ON CONFLICT (role_id, cap_name) DO NOTHING;
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+
+-- Revoke every grant, and delete every capability, inserted above. Capabilities still used by the api_capability seeds are kept, since they can't be deleted while referenced.
+DELETE FROM role_capability WHERE cap_name IN (
+    'asn-read',
+    'asn-write',
+    'basic-read',
+    'basic-write',
+    'cache-config-files-read',
+    'cache-group-read',
+    'cache-group-write',
+    'cdn-config-snapshot-read',
+    'cdn-config-snapshot-write',
+    'cdn-read',
+    'cdn-write',
+    'division-read',
+    'division-write',
+    'ds-cache-write',
+    'ds-read',
+    'ds-request-assign',
+    'ds-request-read',
+    'ds-request-write',
+    'ds-security-keys-read',
+    'ds-security-keys-write',
+    'ds-write',
+    'params-read',
+    'params-write',
+    'phys-location-read',
+    'phys-location-write',
+    'profile-read',
+    'profile-write',
+    'region-read',
+    'region-write',
+    'role-read',
+    'role-write',
+    'server-read',
+    'server-write',
+    'status-read',
+    'status-write',
+    'type-read',
+    'type-write'
+);
+DELETE FROM capability AS c WHERE c.name IN (
+    'asn-read',
+    'asn-write',
+    'basic-read',
+    'basic-write',
+    'cache-config-files-read',
+    'cache-group-read',
+    'cache-group-write',
+    'cdn-config-snapshot-read',
+    'cdn-config-snapshot-write',
+    'cdn-read',
+    'cdn-write',
+    'division-read',
+    'division-write',
+    'ds-cache-write',
+    'ds-read',
+    'ds-request-assign',
+    'ds-request-read',
+    'ds-request-write',
+    'ds-security-keys-read',
+    'ds-security-keys-write',
+    'ds-write',
+    'params-read',
+    'params-write',
+    'phys-location-read',
+    'phys-location-write',
+    'profile-read',
+    'profile-write',
+    'region-read',
+    'region-write',
+    'role-read',
+    'role-write',
+    'server-read',
+    'server-write',
+    'status-read',
+    'status-write',
+    'type-read',
+    'type-write'
+) AND NOT EXISTS (SELECT 1 FROM api_capability AS a WHERE a.capability = c.name);
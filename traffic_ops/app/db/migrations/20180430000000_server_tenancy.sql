@@ -0,0 +1,44 @@
+/*
+
+    Licensed under the Apache License, Version 2.0 (the "License");
+    you may not use this file except in compliance with the License.
+    You may obtain a copy of the License at
+
+        http://www.apache.org/licenses/LICENSE-2.0
+
+    Unless required by applicable law or agreed to in writing, software
+    distributed under the License is distributed on an "AS IS" BASIS,
+    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+    See the License for the specific language governing permissions and
+    limitations under the License.
+*/
+
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+-- Servers may belong to a tenant, in which case only users in that tenant's tree may see or modify them. Servers with no tenant are shared.
+ALTER TABLE server
+    ADD tenant_id BIGINT,
+    ADD CONSTRAINT fk_server_tenant FOREIGN KEY (tenant_id) REFERENCES tenant (id) MATCH FULL,
+    ALTER COLUMN tenant_id SET DEFAULT NULL;
+CREATE INDEX idx_k_server_tenant ON server USING btree (tenant_id);
+
+INSERT INTO capability (name, description) VALUES ('tenant-read', 'View tenants') ON CONFLICT (name) DO NOTHING;
+INSERT INTO capability (name, description) VALUES ('tenant-write', 'Create, edit or delete tenants') ON CONFLICT (name) DO NOTHING;
+
+-- Roles get the tenant and user capabilities of the priv_level the Perl routes required.
+INSERT INTO role_capability (role_id, cap_name)
+SELECT r.id, c.name FROM role AS r JOIN (VALUES
+    ('tenant-read', 10),
+    ('user-read', 10),
+    ('tenant-write', 20)
+) AS c (name, priv_level) ON r.priv_level >= c.priv_level
+ON CONFLICT (role_id, cap_name) DO NOTHING;
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DELETE FROM role_capability WHERE cap_name IN ('tenant-read', 'tenant-write');
+DELETE FROM capability WHERE name IN ('tenant-read', 'tenant-write');
+
+ALTER TABLE server
+DROP COLUMN tenant_id;
@@ -0,0 +1,77 @@
+/*
+
+    Licensed under the Apache License, Version 2.0 (the "License");
+    you may not use this file except in compliance with the License.
+    You may obtain a copy of the License at
+
+        http://www.apache.org/licenses/LICENSE-2.0
+
+    Unless required by applicable law or agreed to in writing, software
+    distributed under the License is distributed on an "AS IS" BASIS,
+    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+    See the License for the specific language governing permissions and
+    limitations under the License.
+*/
+
+
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+-- Login sessions issued by Traffic Ops Golang, so they may be listed and revoked. A session cookie is only valid while its row exists.
+CREATE TABLE user_session (
+    id text NOT NULL,
+    tm_user bigint NOT NULL,
+    created timestamp with time zone NOT NULL DEFAULT now(),
+    last_seen timestamp with time zone NOT NULL DEFAULT now(),
+    remote_addr text,
+    user_agent text,
+    CONSTRAINT pk_user_session PRIMARY KEY (id),
+    CONSTRAINT fk_user_session_tm_user FOREIGN KEY (tm_user) REFERENCES tm_user (id) ON DELETE CASCADE
+);
+CREATE INDEX idx_k_user_session_tm_user ON user_session USING btree (tm_user);
+
+-- Tells Traffic Ops Golang user caches what changed: 'user:<name>' for a user, 'session:<id>' for a revoked session, 'token:<hash>' for a changed or revoked API token, or '' for anything, e.g. role capabilities.
+-- +goose StatementBegin
+CREATE FUNCTION notify_auth_change() RETURNS trigger AS $$
+BEGIN
+    IF TG_TABLE_NAME = 'tm_user' THEN
+        IF TG_OP <> 'INSERT' THEN
+            PERFORM pg_notify('auth_change', 'user:' || OLD.username);
+        END IF;
+        IF TG_OP = 'UPDATE' AND NEW.username <> OLD.username THEN
+            PERFORM pg_notify('auth_change', 'user:' || NEW.username);
+        END IF;
+    ELSIF TG_TABLE_NAME = 'user_session' THEN
+        PERFORM pg_notify('auth_change', 'session:' || OLD.id);
+    ELSIF TG_TABLE_NAME = 'api_token' THEN
+        PERFORM pg_notify('auth_change', 'token:' || OLD.token_hash);
+    ELSE
+        PERFORM pg_notify('auth_change', '');
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER on_tm_user_auth_change AFTER UPDATE OR DELETE ON tm_user FOR EACH ROW EXECUTE PROCEDURE notify_auth_change();
+CREATE TRIGGER on_user_session_auth_change AFTER DELETE ON user_session FOR EACH ROW EXECUTE PROCEDURE notify_auth_change();
+CREATE TRIGGER on_api_token_auth_change AFTER UPDATE OR DELETE ON api_token FOR EACH ROW EXECUTE PROCEDURE notify_auth_change();
+CREATE TRIGGER on_role_auth_change AFTER UPDATE OR DELETE ON role FOR EACH STATEMENT EXECUTE PROCEDURE notify_auth_change();
+CREATE TRIGGER on_role_capability_auth_change AFTER INSERT OR UPDATE OR DELETE ON role_capability FOR EACH STATEMENT EXECUTE PROCEDURE notify_auth_change();
+
+-- Revoking sessions needs user-write, which admins had under Perl.
+INSERT INTO capability (name, description) VALUES ('user-write', 'Create, edit or delete user configuration') ON CONFLICT (name) DO NOTHING;
+INSERT INTO role_capability (role_id, cap_name)
+SELECT r.id, 'user-write' FROM role AS r WHERE r.priv_level >= 30
+ON CONFLICT (role_id, cap_name) DO NOTHING;
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TRIGGER IF EXISTS on_role_capability_auth_change ON role_capability;
+DROP TRIGGER IF EXISTS on_role_auth_change ON role;
+DROP TRIGGER IF EXISTS on_api_token_auth_change ON api_token;
+DROP TRIGGER IF EXISTS on_user_session_auth_change ON user_session;
+DROP TRIGGER IF EXISTS on_tm_user_auth_change ON tm_user;
+DROP FUNCTION IF EXISTS notify_auth_change();
+
+DROP TABLE IF EXISTS user_session;
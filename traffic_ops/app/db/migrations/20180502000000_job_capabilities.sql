@@ -0,0 +1,39 @@
+/*
+
+    Licensed under the Apache License, Version 2.0 (the "License");
+    you may not use this file except in compliance with the License.
+    You may obtain a copy of the License at
+
+        http://www.apache.org/licenses/LICENSE-2.0
+
+    Unless required by applicable law or agreed to in writing, software
+    distributed under the License is distributed on an "AS IS" BASIS,
+    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+    See the License for the specific language governing permissions and
+    limitations under the License.
+*/
+
+
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+INSERT INTO capability (name, description) VALUES ('job-read', 'View jobs') ON CONFLICT (name) DO NOTHING;
+INSERT INTO capability (name, description) VALUES ('job-write', 'Create, edit or delete jobs') ON CONFLICT (name) DO NOTHING;
+INSERT INTO capability (name, description) VALUES ('job-unassigned-read', 'View jobs of delivery services not assigned to the user, when tenancy is disabled') ON CONFLICT (name) DO NOTHING;
+INSERT INTO capability (name, description) VALUES ('job-unassigned-write', 'Create, edit or delete jobs of delivery services not assigned to the user, when tenancy is disabled') ON CONFLICT (name) DO NOTHING;
+
+-- Roles get the job capabilities of the priv_level the Perl routes required. Portal users may invalidate content of their own delivery services, and operations users of any delivery service.
+INSERT INTO role_capability (role_id, cap_name)
+SELECT r.id, c.name FROM role AS r JOIN (VALUES
+    ('job-read', 10),
+    ('job-write', 15),
+    ('job-unassigned-read', 20),
+    ('job-unassigned-write', 20)
+) AS c (name, priv_level) ON r.priv_level >= c.priv_level
+ON CONFLICT (role_id, cap_name) DO NOTHING;
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DELETE FROM role_capability WHERE cap_name IN ('job-read', 'job-write', 'job-unassigned-read', 'job-unassigned-write');
+DELETE FROM capability AS c WHERE c.name IN ('job-read', 'job-write', 'job-unassigned-read', 'job-unassigned-write')
+AND NOT EXISTS (SELECT 1 FROM api_capability AS a WHERE a.capability = c.name);
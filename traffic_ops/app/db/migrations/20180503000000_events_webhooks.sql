@@ -0,0 +1,115 @@
+/*
+
+    Licensed under the Apache License, Version 2.0 (the "License");
+    you may not use this file except in compliance with the License.
+    You may obtain a copy of the License at
+
+        http://www.apache.org/licenses/LICENSE-2.0
+
+    Unless required by applicable law or agreed to in writing, software
+    distributed under the License is distributed on an "AS IS" BASIS,
+    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+    See the License for the specific language governing permissions and
+    limitations under the License.
+*/
+
+
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+-- Changes published to Traffic Ops Golang's event stream and webhooks. The dispatched flag is set by the one instance which queues the event's webhook deliveries.
+CREATE TABLE event (
+    id bigserial NOT NULL,
+    type text NOT NULL,
+    message text NOT NULL DEFAULT '',
+    data json,
+    tm_user bigint,
+    created timestamp with time zone NOT NULL DEFAULT now(),
+    dispatched boolean NOT NULL DEFAULT FALSE,
+    CONSTRAINT pk_event PRIMARY KEY (id),
+    CONSTRAINT fk_event_tm_user FOREIGN KEY (tm_user) REFERENCES tm_user (id) ON DELETE SET NULL
+);
+CREATE INDEX idx_k_event_created ON event USING btree (created);
+CREATE INDEX idx_k_event_undispatched ON event USING btree (id) WHERE NOT dispatched;
+
+-- Tells Traffic Ops Golang event buses the id of each new event. Listeners read the event itself from the table, so payloads aren't limited by the size of a notification.
+-- +goose StatementBegin
+CREATE FUNCTION notify_event() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('tc_event', NEW.id::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER on_event_notify AFTER INSERT ON event FOR EACH ROW EXECUTE PROCEDURE notify_event();
+
+-- Every change log entry, whether written by Traffic Ops Golang or Perl, is published as a 'changelog' event.
+-- +goose StatementBegin
+CREATE FUNCTION log_event() RETURNS trigger AS $$
+BEGIN
+    INSERT INTO event (type, message, data, tm_user) VALUES ('changelog', NEW.message, json_build_object('id', NEW.id, 'level', NEW.level, 'ticketNum', NEW.ticketnum), NEW.tm_user);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER on_log_event AFTER INSERT ON log FOR EACH ROW EXECUTE PROCEDURE log_event();
+
+-- Outbound webhooks. Deliveries are signed with the secret, and only events of the given types are delivered, or all events if there are none.
+CREATE TABLE webhook (
+    id bigserial NOT NULL,
+    url text NOT NULL,
+    secret text NOT NULL,
+    event_types text[] NOT NULL DEFAULT '{}',
+    active boolean NOT NULL DEFAULT TRUE,
+    last_updated timestamp with time zone NOT NULL DEFAULT now(),
+    CONSTRAINT pk_webhook PRIMARY KEY (id)
+);
+CREATE TRIGGER on_update_current_timestamp BEFORE UPDATE ON webhook FOR EACH ROW EXECUTE PROCEDURE on_update_current_timestamp_last_updated();
+
+-- Deliveries of events to webhooks. The event is copied, so it outlives the event's retention. Each webhook's deliveries are attempted in event order, and a delivery is pending until it succeeds, or fails every attempt and is kept as a dead letter.
+CREATE TABLE webhook_delivery (
+    id bigserial NOT NULL,
+    webhook bigint NOT NULL,
+    event_id bigint NOT NULL,
+    event_type text NOT NULL,
+    payload json NOT NULL,
+    attempts integer NOT NULL DEFAULT 0,
+    next_attempt_at timestamp with time zone NOT NULL DEFAULT now(),
+    last_error text NOT NULL DEFAULT '',
+    delivered boolean NOT NULL DEFAULT FALSE,
+    dead_lettered boolean NOT NULL DEFAULT FALSE,
+    created timestamp with time zone NOT NULL DEFAULT now(),
+    CONSTRAINT pk_webhook_delivery PRIMARY KEY (id),
+    CONSTRAINT webhook_delivery_event_unique UNIQUE (webhook, event_id),
+    CONSTRAINT fk_webhook_delivery_webhook FOREIGN KEY (webhook) REFERENCES webhook (id) ON DELETE CASCADE
+);
+CREATE INDEX idx_k_webhook_delivery_pending ON webhook_delivery USING btree (webhook, event_id) WHERE NOT delivered AND NOT dead_lettered;
+CREATE INDEX idx_k_webhook_delivery_dead_lettered ON webhook_delivery USING btree (webhook) WHERE dead_lettered;
+
+INSERT INTO capability (name, description) VALUES ('event-read', 'Stream change events') ON CONFLICT (name) DO NOTHING;
+INSERT INTO capability (name, description) VALUES ('webhook-read', 'View webhooks and their failed deliveries') ON CONFLICT (name) DO NOTHING;
+INSERT INTO capability (name, description) VALUES ('webhook-write', 'Create, edit or delete webhooks') ON CONFLICT (name) DO NOTHING;
+
+-- Events name objects of every tenant, so they're only streamed to operations users. Webhook secrets are for admins.
+INSERT INTO role_capability (role_id, cap_name)
+SELECT r.id, c.name FROM role AS r JOIN (VALUES
+    ('event-read', 20),
+    ('webhook-read', 30),
+    ('webhook-write', 30)
+) AS c (name, priv_level) ON r.priv_level >= c.priv_level
+ON CONFLICT (role_id, cap_name) DO NOTHING;
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DELETE FROM role_capability WHERE cap_name IN ('event-read', 'webhook-read', 'webhook-write');
+
+DROP TABLE IF EXISTS webhook_delivery;
+DROP TABLE IF EXISTS webhook;
+
+DROP TRIGGER IF EXISTS on_log_event ON log;
+DROP FUNCTION IF EXISTS log_event();
+DROP TRIGGER IF EXISTS on_event_notify ON event;
+DROP FUNCTION IF EXISTS notify_event();
+DROP TABLE IF EXISTS event;
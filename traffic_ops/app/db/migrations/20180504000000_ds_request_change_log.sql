@@ -0,0 +1,47 @@
+/*
+
+    Licensed under the Apache License, Version 2.0 (the "License");
+    you may not use this file except in compliance with the License.
+    You may obtain a copy of the License at
+
+        http://www.apache.org/licenses/LICENSE-2.0
+
+    Unless required by applicable law or agreed to in writing, software
+    distributed under the License is distributed on an "AS IS" BASIS,
+    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+    See the License for the specific language governing permissions and
+    limitations under the License.
+*/
+
+
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+-- The change log entry of the delivery service change made when a request is fulfilled.
+ALTER TABLE deliveryservice_request ADD COLUMN change_log_id bigint;
+ALTER TABLE deliveryservice_request ADD CONSTRAINT fk_deliveryservice_request_change_log FOREIGN KEY (change_log_id) REFERENCES log (id) ON DELETE SET NULL;
+
+-- The last_updated of the delivery service the request is based on, when the request was opened or its delivery service last edited. Fulfilling the request fails if the delivery service has changed since.
+ALTER TABLE deliveryservice_request ADD COLUMN ds_last_updated timestamp with time zone;
+
+-- +goose StatementBegin
+CREATE FUNCTION set_ds_request_ds_last_updated() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        NEW.ds_last_updated := (SELECT ds.last_updated FROM deliveryservice AS ds WHERE ds.xml_id = NEW.deliveryservice->>'xmlId');
+    ELSIF NEW.deliveryservice IS DISTINCT FROM OLD.deliveryservice THEN
+        NEW.ds_last_updated := (SELECT ds.last_updated FROM deliveryservice AS ds WHERE ds.xml_id = NEW.deliveryservice->>'xmlId');
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER on_ds_request_ds_change BEFORE INSERT OR UPDATE ON deliveryservice_request FOR EACH ROW EXECUTE PROCEDURE set_ds_request_ds_last_updated();
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TRIGGER IF EXISTS on_ds_request_ds_change ON deliveryservice_request;
+DROP FUNCTION IF EXISTS set_ds_request_ds_last_updated();
+ALTER TABLE deliveryservice_request DROP COLUMN IF EXISTS ds_last_updated;
+ALTER TABLE deliveryservice_request DROP COLUMN IF EXISTS change_log_id;
@@ -0,0 +1,108 @@
+/*
+
+    Licensed under the Apache License, Version 2.0 (the "License");
+    you may not use this file except in compliance with the License.
+    You may obtain a copy of the License at
+
+        http://www.apache.org/licenses/LICENSE-2.0
+
+    Unless required by applicable law or agreed to in writing, software
+    distributed under the License is distributed on an "AS IS" BASIS,
+    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+    See the License for the specific language governing permissions and
+    limitations under the License.
+*/
+
+
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+-- A comment may carry a reviewer's verdict on the request it is on.
+CREATE TYPE deliveryservice_request_verdict AS ENUM ('approve', 'reject');
+
+ALTER TABLE deliveryservice_request_comment ADD COLUMN verdict deliveryservice_request_verdict;
+
+-- The last comment id allocated when the request's delivery service was last edited. Only verdicts in later comments count, so editing a request invalidates the reviews of what it was before.
+ALTER TABLE deliveryservice_request ADD COLUMN ds_edit_comment_id bigint NOT NULL DEFAULT 0;
+
+-- +goose StatementBegin
+CREATE FUNCTION set_ds_request_ds_edit_comment_id() RETURNS trigger AS $$
+BEGIN
+    IF NEW.deliveryservice IS DISTINCT FROM OLD.deliveryservice THEN
+        NEW.ds_edit_comment_id := (SELECT CASE WHEN is_called THEN last_value ELSE 0 END FROM deliveryservice_request_comment_id_seq);
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER on_ds_request_ds_edit BEFORE UPDATE ON deliveryservice_request FOR EACH ROW EXECUTE PROCEDURE set_ds_request_ds_edit_comment_id();
+
+-- An approval policy requires a number of approvals before requests for delivery services of a tenant, or of one of its descendants, and of a CDN may become pending.
+-- A policy without a tenant or CDN applies to every tenant or CDN.
+CREATE TABLE deliveryservice_request_approval_policy (
+    id bigserial primary key NOT NULL,
+    tenant_id bigint,
+    cdn_id bigint,
+    required_approvals integer NOT NULL CHECK (required_approvals > 0),
+    last_updated timestamp WITH time zone NOT NULL DEFAULT now()
+);
+
+ALTER TABLE deliveryservice_request_approval_policy
+    ADD CONSTRAINT fk_tenant FOREIGN KEY (tenant_id) REFERENCES tenant(id) ON DELETE CASCADE;
+
+ALTER TABLE deliveryservice_request_approval_policy
+    ADD CONSTRAINT fk_cdn FOREIGN KEY (cdn_id) REFERENCES cdn(id) ON DELETE CASCADE;
+
+CREATE UNIQUE INDEX deliveryservice_request_approval_policy_scope ON deliveryservice_request_approval_policy (COALESCE(tenant_id, 0), COALESCE(cdn_id, 0));
+
+CREATE TRIGGER on_update_current_timestamp BEFORE UPDATE ON deliveryservice_request_approval_policy FOR EACH ROW EXECUTE PROCEDURE on_update_current_timestamp_last_updated();
+
+-- The approvers of a policy are the users, and the users of the roles, whose verdicts count towards it.
+CREATE TABLE deliveryservice_request_approval_policy_user (
+    policy_id bigint NOT NULL,
+    tm_user_id bigint NOT NULL,
+    PRIMARY KEY (policy_id, tm_user_id)
+);
+
+ALTER TABLE deliveryservice_request_approval_policy_user
+    ADD CONSTRAINT fk_policy FOREIGN KEY (policy_id) REFERENCES deliveryservice_request_approval_policy(id) ON DELETE CASCADE;
+
+ALTER TABLE deliveryservice_request_approval_policy_user
+    ADD CONSTRAINT fk_tm_user FOREIGN KEY (tm_user_id) REFERENCES tm_user(id) ON DELETE CASCADE;
+
+CREATE TABLE deliveryservice_request_approval_policy_role (
+    policy_id bigint NOT NULL,
+    role_id bigint NOT NULL,
+    PRIMARY KEY (policy_id, role_id)
+);
+
+ALTER TABLE deliveryservice_request_approval_policy_role
+    ADD CONSTRAINT fk_policy FOREIGN KEY (policy_id) REFERENCES deliveryservice_request_approval_policy(id) ON DELETE CASCADE;
+
+ALTER TABLE deliveryservice_request_approval_policy_role
+    ADD CONSTRAINT fk_role FOREIGN KEY (role_id) REFERENCES role(id) ON DELETE CASCADE;
+
+INSERT INTO capability (name, description) VALUES ('ds-request-approve', 'Approve or reject delivery service requests of other users') ON CONFLICT (name) DO NOTHING;
+INSERT INTO capability (name, description) VALUES ('ds-request-policy-write', 'Create, edit or delete delivery service request approval policies') ON CONFLICT (name) DO NOTHING;
+
+-- Roles which could assign requests may review them, and admins may manage the policies.
+INSERT INTO role_capability (role_id, cap_name)
+SELECT r.id, c.name FROM role AS r JOIN (VALUES
+    ('ds-request-approve', 20),
+    ('ds-request-policy-write', 30)
+) AS c (name, priv_level) ON r.priv_level >= c.priv_level
+ON CONFLICT (role_id, cap_name) DO NOTHING;
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DELETE FROM role_capability WHERE cap_name IN ('ds-request-approve', 'ds-request-policy-write');
+DELETE FROM capability WHERE name IN ('ds-request-approve', 'ds-request-policy-write');
+DROP TABLE deliveryservice_request_approval_policy_role;
+DROP TABLE deliveryservice_request_approval_policy_user;
+DROP TABLE deliveryservice_request_approval_policy;
+DROP TRIGGER IF EXISTS on_ds_request_ds_edit ON deliveryservice_request;
+DROP FUNCTION IF EXISTS set_ds_request_ds_edit_comment_id();
+ALTER TABLE deliveryservice_request DROP COLUMN IF EXISTS ds_edit_comment_id;
+ALTER TABLE deliveryservice_request_comment DROP COLUMN verdict;
+DROP TYPE deliveryservice_request_verdict;
@@ -0,0 +1,36 @@
+/*
+
+    Licensed under the Apache License, Version 2.0 (the "License");
+    you may not use this file except in compliance with the License.
+    You may obtain a copy of the License at
+
+        http://www.apache.org/licenses/LICENSE-2.0
+
+    Unless required by applicable law or agreed to in writing, software
+    distributed under the License is distributed on an "AS IS" BASIS,
+    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+    See the License for the specific language governing permissions and
+    limitations under the License.
+*/
+
+
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+INSERT INTO capability (name, description) VALUES ('ds-unassigned-read', 'View delivery services not assigned to the user, when tenancy is disabled') ON CONFLICT (name) DO NOTHING;
+INSERT INTO capability (name, description) VALUES ('secure-params-read', 'View the values of secure parameters') ON CONFLICT (name) DO NOTHING;
+INSERT INTO capability (name, description) VALUES ('server-passwords-read', 'View the ILO and XMPP passwords of servers') ON CONFLICT (name) DO NOTHING;
+
+-- Roles get the capabilities of the priv_level which could see delivery services, secure parameters and server passwords.
+INSERT INTO role_capability (role_id, cap_name)
+SELECT r.id, c.name FROM role AS r JOIN (VALUES
+    ('ds-unassigned-read', 20),
+    ('secure-params-read', 30),
+    ('server-passwords-read', 30)
+) AS c (name, priv_level) ON r.priv_level >= c.priv_level
+ON CONFLICT (role_id, cap_name) DO NOTHING;
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DELETE FROM role_capability WHERE cap_name IN ('ds-unassigned-read', 'secure-params-read', 'server-passwords-read');
+DELETE FROM capability WHERE name IN ('ds-unassigned-read', 'secure-params-read', 'server-passwords-read');
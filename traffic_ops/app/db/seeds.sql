@@ -110,9 +110,15 @@ insert into capability (name, description) values ('ds-cache-read', 'View delive
 insert into capability (name, description) values ('ds-cache-write', 'Create, edit or delete delivery-service cache assignment') ON CONFLICT (name) DO NOTHING;
 insert into capability (name, description) values ('ds-health-read', 'View delivery-service health') ON CONFLICT (name) DO NOTHING;
 insert into capability (name, description) values ('ds-read', 'View delivery-service configuration') ON CONFLICT (name) DO NOTHING;
+insert into capability (name, description) values ('ds-request-approve', 'Approve or reject delivery service requests of other users') ON CONFLICT (name) DO NOTHING;
+insert into capability (name, description) values ('ds-request-assign', 'Assign delivery service requests') ON CONFLICT (name) DO NOTHING;
+insert into capability (name, description) values ('ds-request-policy-write', 'Create, edit or delete delivery service request approval policies') ON CONFLICT (name) DO NOTHING;
+insert into capability (name, description) values ('ds-request-read', 'View delivery service requests and their comments') ON CONFLICT (name) DO NOTHING;
+insert into capability (name, description) values ('ds-request-write', 'Create, edit or delete delivery service requests and their comments') ON CONFLICT (name) DO NOTHING;
 insert into capability (name, description) values ('ds-write', 'Create, edit or delete delivery-service configuration') ON CONFLICT (name) DO NOTHING;
 insert into capability (name, description) values ('ds-security-keys-read', 'View delivery-service security keys') ON CONFLICT (name) DO NOTHING;
 insert into capability (name, description) values ('ds-security-keys-write', 'Create, edit or delete delivery-service security keys') ON CONFLICT (name) DO NOTHING;
+insert into capability (name, description) values ('ds-unassigned-read', 'View delivery services not assigned to the user, when tenancy is disabled') ON CONFLICT (name) DO NOTHING;
 insert into capability (name, description) values ('ds-stats-read', 'View delivery-service statistics') ON CONFLICT (name) DO NOTHING;
 insert into capability (name, description) values ('ds-steering-read', 'View delivery-service steering configuration') ON CONFLICT (name) DO NOTHING;
 insert into capability (name, description) values ('ds-steering-write', 'Create, edit or delete delivery-service steering configuration') ON CONFLICT (name) DO NOTHING;
@@ -122,7 +128,12 @@ insert into capability (name, description) values ('federation-routing-read', 'V
 insert into capability (name, description) values ('federation-routing-write', 'Create, edit or delete federation routing') ON CONFLICT (name) DO NOTHING;
 insert into capability (name, description) values ('iso-generate', 'Generate ISOs') ON CONFLICT (name) DO NOTHING;
 insert into capability (name, description) values ('job-read', 'View jobs') ON CONFLICT (name) DO NOTHING;
+insert into capability (name, description) values ('job-unassigned-read', 'View jobs of delivery services not assigned to the user, when tenancy is disabled') ON CONFLICT (name) DO NOTHING;
+insert into capability (name, description) values ('job-unassigned-write', 'Create, edit or delete jobs of delivery services not assigned to the user, when tenancy is disabled') ON CONFLICT (name) DO NOTHING;
 insert into capability (name, description) values ('job-write', 'Create, edit or delete jobs') ON CONFLICT (name) DO NOTHING;
+insert into capability (name, description) values ('event-read', 'Stream change events') ON CONFLICT (name) DO NOTHING;
+insert into capability (name, description) values ('webhook-read', 'View webhooks and their failed deliveries') ON CONFLICT (name) DO NOTHING;
+insert into capability (name, description) values ('webhook-write', 'Create, edit or delete webhooks') ON CONFLICT (name) DO NOTHING;
 insert into capability (name, description) values ('params-read', 'View parameters') ON CONFLICT (name) DO NOTHING;
 insert into capability (name, description) values ('params-write', 'Create, edit or delete parameters') ON CONFLICT (name) DO NOTHING;
 insert into capability (name, description) values ('phys-location-read', 'View physical location configuration') ON CONFLICT (name) DO NOTHING;
@@ -134,8 +145,10 @@ insert into capability (name, description) values ('region-read', 'View region c
 insert into capability (name, description) values ('region-write', 'Create, edit or delete region configuration') ON CONFLICT (name) DO NOTHING;
 insert into capability (name, description) values ('role-read', 'View role configuration') ON CONFLICT (name) DO NOTHING;
 insert into capability (name, description) values ('role-write', 'Create, edit or delete role configuration') ON CONFLICT (name) DO NOTHING;
+insert into capability (name, description) values ('secure-params-read', 'View the values of secure parameters') ON CONFLICT (name) DO NOTHING;
 insert into capability (name, description) values ('security-keys-read', 'View security keys') ON CONFLICT (name) DO NOTHING;
 insert into capability (name, description) values ('security-keys-write', 'Create, edit or delete security keys') ON CONFLICT (name) DO NOTHING;
+insert into capability (name, description) values ('server-passwords-read', 'View the ILO and XMPP passwords of servers') ON CONFLICT (name) DO NOTHING;
 insert into capability (name, description) values ('server-pull-updates-read', 'Read server update indication') ON CONFLICT (name) DO NOTHING;
 insert into capability (name, description) values ('server-pull-updates-write', 'Write server update indication') ON CONFLICT (name) DO NOTHING;
 insert into capability (name, description) values ('server-read', 'View server configuration') ON CONFLICT (name) DO NOTHING;
@@ -143,6 +156,9 @@ insert into capability (name, description) values ('server-write', 'Create, edit
 insert into capability (name, description) values ('static-dns-read', 'View static DNS configuration') ON CONFLICT (name) DO NOTHING;
 insert into capability (name, description) values ('static-dns-write', 'Create, edit or delete static DNS configuration') ON CONFLICT (name) DO NOTHING;
 insert into capability (name, description) values ('status-read', 'View the list of defined statuses') ON CONFLICT (name) DO NOTHING;
+insert into capability (name, description) values ('status-write', 'Create, edit or delete statuses') ON CONFLICT (name) DO NOTHING;
+insert into capability (name, description) values ('tenant-read', 'View tenants') ON CONFLICT (name) DO NOTHING;
+insert into capability (name, description) values ('tenant-write', 'Create, edit or delete tenants') ON CONFLICT (name) DO NOTHING;
 insert into capability (name, description) values ('to-extension-read', 'View Traffic Ops extensions') ON CONFLICT (name) DO NOTHING;
 insert into capability (name, description) values ('to-extension-write', 'Create, edit or delete Traffic Ops extensions') ON CONFLICT (name) DO NOTHING;
 insert into capability (name, description) values ('type-read', 'View types configuration') ON CONFLICT (name) DO NOTHING;
@@ -153,6 +169,65 @@ insert into capability (name, description) values ('user-write', 'Create, edit o
 -- roles_capabilities
 insert into role_capability (role_id, cap_name) values ((select id from role where name='admin'), 'all-read') ON CONFLICT (role_id, cap_name) DO NOTHING;
 insert into role_capability (role_id, cap_name) values ((select id from role where name='admin'), 'all-write') ON CONFLICT (role_id, cap_name) DO NOTHING;
+-- every other default role gets the capabilities of the routes its priv_level could use, unless it already has capabilities
+INSERT INTO role_capability (role_id, cap_name)
+SELECT r.id, c.name FROM role AS r JOIN (VALUES
+    ('asn-read', 10),
+    ('basic-read', 10),
+    ('basic-write', 10),
+    ('cache-group-read', 10),
+    ('cdn-read', 10),
+    ('division-read', 10),
+    ('ds-read', 10),
+    ('ds-request-read', 10),
+    ('job-read', 10),
+    ('params-read', 10),
+    ('phys-location-read', 10),
+    ('profile-read', 10),
+    ('region-read', 10),
+    ('role-read', 10),
+    ('server-read', 10),
+    ('status-read', 10),
+    ('tenant-read', 10),
+    ('type-read', 10),
+    ('user-read', 10),
+    ('ds-request-write', 15),
+    ('job-write', 15),
+    ('asn-write', 20),
+    ('cache-config-files-read', 20),
+    ('cache-group-write', 20),
+    ('cdn-write', 20),
+    ('division-write', 20),
+    ('ds-cache-write', 20),
+    ('ds-request-approve', 20),
+    ('ds-request-assign', 20),
+    ('ds-unassigned-read', 20),
+    ('ds-write', 20),
+    ('event-read', 20),
+    ('job-unassigned-read', 20),
+    ('job-unassigned-write', 20),
+    ('params-write', 20),
+    ('phys-location-write', 20),
+    ('profile-write', 20),
+    ('region-write', 20),
+    ('server-write', 20),
+    ('status-write', 20),
+    ('tenant-write', 20),
+    ('type-write', 20),
+    ('cdn-config-snapshot-read', 30),
+    ('cdn-config-snapshot-write', 30),
+    ('ds-security-keys-read', 30),
+    ('ds-security-keys-write', 30),
+    ('ds-request-policy-write', 30),
+    ('role-write', 30),
+    ('secure-params-read', 30),
+    ('server-passwords-read', 30),
+    ('user-write', 30),
+    ('webhook-read', 30),
+    ('webhook-write', 30)
+) AS c (name, priv_level) ON r.priv_level >= c.priv_level
+WHERE NOT EXISTS (SELECT 1 FROM role_capability AS rc WHERE rc.role_id = r.id)
+ON CONFLICT (role_id, cap_name) DO NOTHING;
 
 -- api_capabilities
 insert into api_capability (http_method, route, capability) values ('GET', '/', 'all-read') ON CONFLICT (http_method, route, capability) DO NOTHING;
@@ -0,0 +1,81 @@
+/*
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v13
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
+)
+
+const (
+	API_v13_USER_TOKENS = "/api/1.3/user/tokens"
+)
+
+// GetAPITokens returns the API tokens of the current user. The tokens themselves aren't returned, only their names and expirations.
+func (to *Session) GetAPITokens() ([]tc.APIToken, ReqInf, error) {
+	resp, remoteAddr, err := to.request(http.MethodGet, API_v13_USER_TOKENS, nil)
+	reqInf := ReqInf{CacheHitStatus: CacheHitStatusMiss, RemoteAddr: remoteAddr}
+	if err != nil {
+		return nil, reqInf, err
+	}
+	defer resp.Body.Close()
+
+	var data tc.APITokensResponse
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return nil, reqInf, err
+	}
+	return data.Response, reqInf, nil
+}
+
+// CreateAPIToken creates an API token for the current user. The returned token can't be retrieved again, so it must be saved by the caller.
+func (to *Session) CreateAPIToken(req tc.APITokenRequest) (tc.APITokenCreated, ReqInf, error) {
+	var remoteAddr net.Addr
+	reqInf := ReqInf{CacheHitStatus: CacheHitStatusMiss, RemoteAddr: remoteAddr}
+	reqBody, err := json.Marshal(req)
+	if err != nil {
+		return tc.APITokenCreated{}, reqInf, err
+	}
+	resp, remoteAddr, err := to.request(http.MethodPost, API_v13_USER_TOKENS, reqBody)
+	reqInf.RemoteAddr = remoteAddr
+	if err != nil {
+		return tc.APITokenCreated{}, reqInf, err
+	}
+	defer resp.Body.Close()
+
+	var data tc.APITokenCreatedResponse
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return tc.APITokenCreated{}, reqInf, err
+	}
+	return data.Response, reqInf, nil
+}
+
+// DeleteAPIToken revokes the API token with the given id.
+func (to *Session) DeleteAPIToken(id int) (tc.Alerts, ReqInf, error) {
+	route := fmt.Sprintf("%s/%d", API_v13_USER_TOKENS, id)
+	resp, remoteAddr, err := to.request(http.MethodDelete, route, nil)
+	reqInf := ReqInf{CacheHitStatus: CacheHitStatusMiss, RemoteAddr: remoteAddr}
+	if err != nil {
+		return tc.Alerts{}, reqInf, err
+	}
+	defer resp.Body.Close()
+	var alerts tc.Alerts
+	err = json.NewDecoder(resp.Body).Decode(&alerts)
+	return alerts, reqInf, err
+}
@@ -0,0 +1,52 @@
+/*
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v13
+
+import (
+	"encoding/json"
+)
+
+// BulkPath is appended to the path of an API endpoint which supports creating, updating, or deleting many objects at once.
+const BulkPath = "/bulk"
+
+// keyID is the request body for bulk deleting an object by its ID.
+type keyID struct {
+	ID int `json:"id"`
+}
+
+func keyIDs(ids []int) []keyID {
+	keys := make([]keyID, 0, len(ids))
+	for _, id := range ids {
+		keys = append(keys, keyID{ID: id})
+	}
+	return keys
+}
+
+// bulkRequest sends the objs array to the bulk endpoint of path, and decodes the response into resp. The request succeeds or fails as a whole: if any object can't be changed, none are, and the returned error contains the alerts for each failed element.
+func (to *Session) bulkRequest(method string, path string, objs interface{}, resp interface{}) (ReqInf, error) {
+	reqInf := ReqInf{CacheHitStatus: CacheHitStatusMiss}
+	reqBody, err := json.Marshal(objs)
+	if err != nil {
+		return reqInf, err
+	}
+	httpResp, remoteAddr, err := to.request(method, path+BulkPath, reqBody)
+	reqInf.RemoteAddr = remoteAddr
+	if err != nil {
+		return reqInf, err
+	}
+	defer httpResp.Body.Close()
+	return reqInf, json.NewDecoder(httpResp.Body).Decode(resp)
+}
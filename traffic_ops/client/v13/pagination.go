@@ -0,0 +1,102 @@
+/*
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v13
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
+)
+
+// Pager iterates over the pages of a paginated Traffic Ops API endpoint, following the next link of each page.
+// Use it like a bufio.Scanner:
+//
+//	pager := to.NewPager(API_v13_Servers, 1000)
+//	servers := []v13.Server{}
+//	for pager.Next(&servers) {
+//		// use servers
+//	}
+//	if err := pager.Err(); err != nil {
+//		// handle err
+//	}
+type Pager struct {
+	to     *Session
+	next   string
+	count  int
+	reqInf ReqInf
+	err    error
+}
+
+// NewPager returns a Pager over the given API path, which may have query parameters, with pages of up to limit results.
+func (to *Session) NewPager(path string, limit int) *Pager {
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+	return &Pager{to: to, next: path + sep + "limit=" + strconv.Itoa(limit)}
+}
+
+// Next requests the next page, and decodes its results into response, which must be a pointer to a slice. It returns false when there are no more pages, or on error.
+func (p *Pager) Next(response interface{}) bool {
+	if p.err != nil || p.next == "" {
+		return false
+	}
+	resp, remoteAddr, err := p.to.request(http.MethodGet, p.next, nil)
+	p.reqInf = ReqInf{CacheHitStatus: CacheHitStatusMiss, RemoteAddr: remoteAddr}
+	if err != nil {
+		p.err = err
+		return false
+	}
+	defer resp.Body.Close()
+
+	data := struct {
+		Response json.RawMessage      `json:"response"`
+		Summary  tc.PaginationSummary `json:"summary"`
+		Links    tc.PaginationLinks   `json:"links"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		p.err = err
+		return false
+	}
+	if err := json.Unmarshal(data.Response, response); err != nil {
+		p.err = err
+		return false
+	}
+	p.count = data.Summary.Count
+	p.next = data.Summary.Next
+	if p.next == "" {
+		p.next = data.Links.Next
+	}
+	return true
+}
+
+// Count returns the number of results of all pages, from the summary of the last page requested.
+func (p *Pager) Count() int {
+	return p.count
+}
+
+// Err returns the error which stopped the Pager, if any.
+func (p *Pager) Err() error {
+	return p.err
+}
+
+// ReqInf returns the request info of the last page requested.
+func (p *Pager) ReqInf() ReqInf {
+	return p.reqInf
+}
@@ -82,6 +82,21 @@ func (to *Session) GetParameters() ([]tc.Parameter, ReqInf, error) {
 	return data.Response, reqInf, nil
 }
 
+// Calls fn with each page of up to limit Parameters, until all Parameters have been returned or fn returns an error
+func (to *Session) GetParametersPaged(limit int, fn func([]tc.Parameter) error) (ReqInf, error) {
+	pager := to.NewPager(API_v13_Parameters, limit)
+	for {
+		params := []tc.Parameter{}
+		if !pager.Next(&params) {
+			break
+		}
+		if err := fn(params); err != nil {
+			return pager.ReqInf(), err
+		}
+	}
+	return pager.ReqInf(), pager.Err()
+}
+
 // GET a Parameter by the Parameter ID
 func (to *Session) GetParameterByID(id int) ([]tc.Parameter, ReqInf, error) {
 	route := fmt.Sprintf("%s/%d", API_v13_Parameters, id)
@@ -167,3 +182,24 @@ func (to *Session) DeleteParameterByID(id int) (tc.Alerts, ReqInf, error) {
 	err = json.NewDecoder(resp.Body).Decode(&alerts)
 	return alerts, reqInf, nil
 }
+
+// Create many Parameters in a single transaction, returning the created Parameters with their IDs
+func (to *Session) CreateParameters(pls []tc.Parameter) ([]tc.Parameter, ReqInf, error) {
+	var data tc.ParametersResponse
+	reqInf, err := to.bulkRequest(http.MethodPost, API_v13_Parameters, pls, &data)
+	return data.Response, reqInf, err
+}
+
+// Update many Parameters by their IDs in a single transaction
+func (to *Session) UpdateParameters(pls []tc.Parameter) ([]tc.Parameter, ReqInf, error) {
+	var data tc.ParametersResponse
+	reqInf, err := to.bulkRequest(http.MethodPut, API_v13_Parameters, pls, &data)
+	return data.Response, reqInf, err
+}
+
+// DELETE many Parameters by ID in a single transaction
+func (to *Session) DeleteParameters(ids []int) (tc.Alerts, ReqInf, error) {
+	var alerts tc.Alerts
+	reqInf, err := to.bulkRequest(http.MethodDelete, API_v13_Parameters, keyIDs(ids), &alerts)
+	return alerts, reqInf, err
+}
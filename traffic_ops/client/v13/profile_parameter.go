@@ -95,3 +95,17 @@ func (to *Session) DeleteParameterByProfileParameter(profile int, parameter int)
 	err = json.NewDecoder(resp.Body).Decode(&alerts)
 	return alerts, reqInf, nil
 }
+
+// Create many ProfileParameters in a single transaction
+func (to *Session) CreateProfileParameters(pps []v13.ProfileParameter) ([]v13.ProfileParameter, ReqInf, error) {
+	var data v13.ProfileParametersResponse
+	reqInf, err := to.bulkRequest(http.MethodPost, API_v13_Profile_Parameters, pps, &data)
+	return data.Response, reqInf, err
+}
+
+// DELETE many ProfileParameters by their profile and parameter IDs in a single transaction
+func (to *Session) DeleteProfileParameters(pps []v13.ProfileParameter) (tc.Alerts, ReqInf, error) {
+	var alerts tc.Alerts
+	reqInf, err := to.bulkRequest(http.MethodDelete, API_v13_Profile_Parameters, pps, &alerts)
+	return alerts, reqInf, err
+}
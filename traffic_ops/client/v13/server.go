@@ -82,6 +82,21 @@ func (to *Session) GetServers() ([]v13.Server, ReqInf, error) {
 	return data.Response, reqInf, nil
 }
 
+// Calls fn with each page of up to limit Servers, until all Servers have been returned or fn returns an error
+func (to *Session) GetServersPaged(limit int, fn func([]v13.Server) error) (ReqInf, error) {
+	pager := to.NewPager(API_v13_Servers, limit)
+	for {
+		servers := []v13.Server{}
+		if !pager.Next(&servers) {
+			break
+		}
+		if err := fn(servers); err != nil {
+			return pager.ReqInf(), err
+		}
+	}
+	return pager.ReqInf(), pager.Err()
+}
+
 // GET a Server by the Server ID
 func (to *Session) GetServerByID(id int) ([]v13.Server, ReqInf, error) {
 	route := fmt.Sprintf("%s/%d", API_v13_Servers, id)
@@ -131,3 +146,24 @@ func (to *Session) DeleteServerByID(id int) (tc.Alerts, ReqInf, error) {
 	err = json.NewDecoder(resp.Body).Decode(&alerts)
 	return alerts, reqInf, nil
 }
+
+// Create many Servers in a single transaction, returning the created Servers with their IDs
+func (to *Session) CreateServers(servers []v13.Server) ([]v13.Server, ReqInf, error) {
+	var data v13.ServersResponse
+	reqInf, err := to.bulkRequest(http.MethodPost, API_v13_Servers, servers, &data)
+	return data.Response, reqInf, err
+}
+
+// Update many Servers by their IDs in a single transaction
+func (to *Session) UpdateServers(servers []v13.Server) ([]v13.Server, ReqInf, error) {
+	var data v13.ServersResponse
+	reqInf, err := to.bulkRequest(http.MethodPut, API_v13_Servers, servers, &data)
+	return data.Response, reqInf, err
+}
+
+// DELETE many Servers by ID in a single transaction
+func (to *Session) DeleteServers(ids []int) (tc.Alerts, ReqInf, error) {
+	var alerts tc.Alerts
+	reqInf, err := to.bulkRequest(http.MethodDelete, API_v13_Servers, keyIDs(ids), &alerts)
+	return alerts, reqInf, err
+}
@@ -46,6 +46,8 @@ type Session struct {
 	cacheMutex   *sync.RWMutex
 	useCache     bool
 	UserAgentStr string
+	// Token is an API token sent as an `Authorization: Bearer` header. If it's set, requests aren't retried by logging in with the UserName and Password.
+	Token string
 }
 
 func NewSession(user, password, url, userAgent string, client *http.Client, useCache bool) *Session {
@@ -207,6 +209,13 @@ func LoginWithAgent(toURL string, toUser string, toPasswd string, insecure bool,
 	return to, remoteAddr, nil
 }
 
+// LoginWithToken returns a Session which authenticates with the given API token, rather than a user name and password. Unlike LoginWithAgent, no request is made, so an invalid token isn't detected until the first request.
+func LoginWithToken(toURL string, token string, insecure bool, userAgent string, useCache bool, requestTimeout time.Duration) *Session {
+	to := NewNoAuthSession(toURL, insecure, userAgent, useCache, requestTimeout)
+	to.Token = token
+	return to
+}
+
 // Logout of traffic_ops
 func LogoutWithAgent(toURL string, toUser string, toPasswd string, insecure bool, userAgent string, useCache bool, requestTimeout time.Duration) (*Session, net.Addr, error) {
 	options := cookiejar.Options{
@@ -269,7 +278,7 @@ func (to *Session) request(method, path string, body []byte) (*http.Response, ne
 	if err != nil {
 		return r, remoteAddr, err
 	}
-	if r.StatusCode != http.StatusUnauthorized && r.StatusCode != http.StatusForbidden {
+	if (r.StatusCode != http.StatusUnauthorized && r.StatusCode != http.StatusForbidden) || to.Token != "" {
 		return to.ErrUnlessOK(r, remoteAddr, err, path)
 	}
 	if _, lerr := to.login(); lerr != nil {
@@ -310,6 +319,9 @@ func (to *Session) rawRequest(method, path string, body []byte) (*http.Response,
 	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
 
 	req.Header.Set("User-Agent", to.UserAgentStr)
+	if to.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+to.Token)
+	}
 
 	resp, err := to.Client.Do(req)
 	if err != nil {
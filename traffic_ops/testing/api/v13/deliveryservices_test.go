@@ -0,0 +1,172 @@
+package v13
+
+/*
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"testing"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-log"
+	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
+)
+
+func TestDeliveryServices(t *testing.T) {
+
+	CreateTestCDNs(t)
+	CreateTestTypes(t)
+	CreateTestDeliveryServices(t)
+	GetTestDeliveryServices(t)
+	UpdateTestDeliveryServices(t)
+	DeleteTestDeliveryServices(t)
+	DeleteTestTypes(t)
+	DeleteTestCDNs(t)
+
+}
+
+func CreateTestDeliveryServices(t *testing.T) {
+
+	// loop through delivery services, assign FKs and create
+	for _, ds := range testData.DeliveryServices {
+		respCDNs, _, err := TOSession.GetCDNByName(*ds.CDNName)
+		if err != nil || len(respCDNs) == 0 {
+			t.Fatalf("cannot GET CDN by name: %v - %v\n", *ds.CDNName, err)
+		}
+		ds.CDNID = &respCDNs[0].ID
+
+		respTypes, _, err := TOSession.GetTypeByName(*ds.TypeName)
+		if err != nil || len(respTypes) == 0 {
+			t.Fatalf("cannot GET Type by name: %v - %v\n", *ds.TypeName, err)
+		}
+		ds.TypeID = &respTypes[0].ID
+
+		respTenants, _, err := TOSession.GetTenantByName(*ds.Tenant)
+		if err != nil || len(respTenants) == 0 {
+			t.Fatalf("cannot GET Tenant by name: %v - %v\n", *ds.Tenant, err)
+		}
+		ds.TenantID = &respTenants[0].ID
+
+		resp, _, err := TOSession.CreateDeliveryService(ds)
+		log.Debugln("Response: ", *ds.XMLID, " ", resp)
+		if err != nil {
+			t.Errorf("could not CREATE delivery service: %v\n", err)
+		}
+	}
+
+}
+
+func GetTestDeliveryServices(t *testing.T) {
+
+	for _, ds := range testData.DeliveryServices {
+		resp, _, err := TOSession.GetDeliveryServiceByXMLID(*ds.XMLID)
+		if err != nil {
+			t.Errorf("cannot GET DeliveryService by xmlId: %v - %v\n", err, resp)
+		}
+		if len(resp) != 1 {
+			t.Fatalf("expected 1 DeliveryService with xmlId %s, actual: %d\n", *ds.XMLID, len(resp))
+		}
+		respDS := resp[0]
+
+		// delivery services created without a match list get the default host regex
+		expectedRegex := `.*\.` + *ds.XMLID + `\..*`
+		if respDS.MatchList == nil || len(*respDS.MatchList) != 1 {
+			t.Errorf("expected DeliveryService %s to have 1 regex, actual: %v\n", *ds.XMLID, respDS.MatchList)
+		} else if match := (*respDS.MatchList)[0]; match.Type != "HOST_REGEXP" || match.Pattern != expectedRegex || match.SetNumber != 0 {
+			t.Errorf("expected DeliveryService %s default regex HOST_REGEXP %s at 0, actual: %+v\n", *ds.XMLID, expectedRegex, match)
+		}
+
+		if ds.GeoLimitCountries != nil && (respDS.GeoLimitCountries == nil || *respDS.GeoLimitCountries != "US,CA") {
+			t.Errorf("expected DeliveryService %s geoLimitCountries to be sanitized to US,CA, actual: %v\n", *ds.XMLID, respDS.GeoLimitCountries)
+		}
+	}
+}
+
+func UpdateTestDeliveryServices(t *testing.T) {
+
+	firstDS := testData.DeliveryServices[0]
+	// Retrieve the delivery service by xmlId so we can get the id for the Update
+	resp, _, err := TOSession.GetDeliveryServiceByXMLID(*firstDS.XMLID)
+	if err != nil || len(resp) == 0 {
+		t.Fatalf("cannot GET DeliveryService by xmlId: %v - %v\n", *firstDS.XMLID, err)
+	}
+	remoteDS := resp[0]
+	updatedLongDesc := "something different"
+	updatedMaxDNSAnswers := 164598
+
+	// update longDesc and maxDnsAnswers values on the delivery service
+	remoteDS.LongDesc = &updatedLongDesc
+	remoteDS.MaxDNSAnswers = &updatedMaxDNSAnswers
+	var alert tc.Alerts
+	alert, _, err = TOSession.UpdateDeliveryServiceByID(*remoteDS.ID, remoteDS)
+	if err != nil {
+		t.Errorf("cannot UPDATE DeliveryService by ID: %v - %v\n", err, alert)
+	}
+
+	// Retrieve the delivery service to check longDesc and maxDnsAnswers values were updated
+	resp, _, err = TOSession.GetDeliveryServiceByID(*remoteDS.ID)
+	if err != nil || len(resp) == 0 {
+		t.Fatalf("cannot GET DeliveryService by ID: %v - %v\n", *remoteDS.XMLID, err)
+	}
+
+	respDS := resp[0]
+	if respDS.LongDesc == nil || *respDS.LongDesc != updatedLongDesc {
+		t.Errorf("results do not match actual: %v, expected: %s\n", respDS.LongDesc, updatedLongDesc)
+	}
+	if respDS.MaxDNSAnswers == nil || *respDS.MaxDNSAnswers != updatedMaxDNSAnswers {
+		t.Errorf("results do not match actual: %v, expected: %d\n", respDS.MaxDNSAnswers, updatedMaxDNSAnswers)
+	}
+
+	// the xmlId of a delivery service is immutable
+	changedXMLID := *remoteDS.XMLID + "-changed"
+	remoteDS.XMLID = &changedXMLID
+	alert, _, err = TOSession.UpdateDeliveryServiceByID(*remoteDS.ID, remoteDS)
+	if err == nil {
+		t.Errorf("expected UPDATE of DeliveryService xmlId to be rejected, actual: success - %v\n", alert)
+	}
+	resp, _, err = TOSession.GetDeliveryServiceByID(*remoteDS.ID)
+	if err != nil || len(resp) == 0 {
+		t.Fatalf("cannot GET DeliveryService by ID: %v - %v\n", *firstDS.XMLID, err)
+	}
+	if resp[0].XMLID == nil || *resp[0].XMLID != *firstDS.XMLID {
+		t.Errorf("expected xmlId change to be rejected, actual: %v, expected: %s\n", resp[0].XMLID, *firstDS.XMLID)
+	}
+
+}
+
+func DeleteTestDeliveryServices(t *testing.T) {
+
+	for _, ds := range testData.DeliveryServices {
+		resp, _, err := TOSession.GetDeliveryServiceByXMLID(*ds.XMLID)
+		if err != nil {
+			t.Errorf("cannot GET DeliveryService by xmlId: %v - %v\n", *ds.XMLID, err)
+		}
+		if len(resp) > 0 {
+			respDS := resp[0]
+
+			delResp, _, err := TOSession.DeleteDeliveryServiceByID(*respDS.ID)
+			if err != nil {
+				t.Errorf("cannot DELETE DeliveryService by ID: %v - %v\n", err, delResp)
+			}
+
+			// Retrieve the DeliveryService to see if it got deleted
+			dses, _, err := TOSession.GetDeliveryServiceByXMLID(*ds.XMLID)
+			if err != nil {
+				t.Errorf("error deleting DeliveryService xmlId: %s\n", err.Error())
+			}
+			if len(dses) > 0 {
+				t.Errorf("expected DeliveryService xmlId: %s to be deleted\n", *ds.XMLID)
+			}
+		}
+	}
+}
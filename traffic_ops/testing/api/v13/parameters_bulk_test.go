@@ -0,0 +1,120 @@
+/*
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v13
+
+import (
+	"testing"
+
+	tc "github.com/apache/incubator-trafficcontrol/lib/go-tc"
+)
+
+func TestParametersBulk(t *testing.T) {
+
+	CreateTestParametersBulk(t)
+	CreateTestParametersBulkInvalid(t)
+	UpdateTestParametersBulk(t)
+	DeleteTestParametersBulk(t)
+
+}
+
+var bulkParameters = []tc.Parameter{
+	{Name: "bulk1", ConfigFile: "bulk.config", Value: "1"},
+	{Name: "bulk2", ConfigFile: "bulk.config", Value: "2"},
+}
+
+func CreateTestParametersBulk(t *testing.T) {
+
+	resp, _, err := TOSession.CreateParameters(bulkParameters)
+	if err != nil {
+		t.Fatalf("could not bulk CREATE parameters: %v\n", err)
+	}
+	if len(resp) != len(bulkParameters) {
+		t.Fatalf("expected %d created parameters, actual: %d\n", len(bulkParameters), len(resp))
+	}
+	for i, pl := range resp {
+		if pl.ID == 0 || pl.Name != bulkParameters[i].Name {
+			t.Errorf("expected created parameter %s with an id, actual: %+v\n", bulkParameters[i].Name, pl)
+		}
+		bulkParameters[i] = pl
+	}
+
+}
+
+func CreateTestParametersBulkInvalid(t *testing.T) {
+
+	pls := []tc.Parameter{
+		{Name: "bulkValid", ConfigFile: "bulk.config", Value: "3"},
+		{Name: "", ConfigFile: "bulk.config", Value: "4"},
+	}
+	if _, _, err := TOSession.CreateParameters(pls); err == nil {
+		t.Errorf("expected bulk CREATE with an invalid parameter to fail\n")
+	}
+
+	// nothing is created if any element is invalid
+	resp, _, err := TOSession.GetParameterByName(pls[0].Name)
+	if err != nil {
+		t.Errorf("cannot GET Parameter by name: %v - %v\n", pls[0].Name, err)
+	}
+	if len(resp) != 0 {
+		t.Errorf("expected no parameter %s to be created, actual: %+v\n", pls[0].Name, resp)
+	}
+
+}
+
+func UpdateTestParametersBulk(t *testing.T) {
+
+	expectedParameterValue := "UPDATED"
+	for i := range bulkParameters {
+		bulkParameters[i].Value = expectedParameterValue
+	}
+	if _, _, err := TOSession.UpdateParameters(bulkParameters); err != nil {
+		t.Fatalf("could not bulk UPDATE parameters: %v\n", err)
+	}
+
+	for _, pl := range bulkParameters {
+		resp, _, err := TOSession.GetParameterByID(pl.ID)
+		if err != nil || len(resp) != 1 {
+			t.Errorf("cannot GET Parameter by id: %v - %v\n", pl.ID, err)
+			continue
+		}
+		if resp[0].Value != expectedParameterValue {
+			t.Errorf("results do not match actual: %s, expected: %s\n", resp[0].Value, expectedParameterValue)
+		}
+	}
+
+}
+
+func DeleteTestParametersBulk(t *testing.T) {
+
+	ids := []int{}
+	for _, pl := range bulkParameters {
+		ids = append(ids, pl.ID)
+	}
+	if _, _, err := TOSession.DeleteParameters(ids); err != nil {
+		t.Fatalf("could not bulk DELETE parameters: %v\n", err)
+	}
+
+	for _, pl := range bulkParameters {
+		resp, _, err := TOSession.GetParameterByID(pl.ID)
+		if err != nil {
+			t.Errorf("cannot GET Parameter by id: %v - %v\n", pl.ID, err)
+		}
+		if len(resp) > 0 {
+			t.Errorf("expected Parameter %s to be deleted\n", pl.Name)
+		}
+	}
+
+}
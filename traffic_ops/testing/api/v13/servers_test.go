@@ -18,6 +18,7 @@ package v13
 import (
 	"github.com/apache/incubator-trafficcontrol/lib/go-log"
 	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
+	"github.com/apache/incubator-trafficcontrol/lib/go-tc/v13"
 	"testing"
 )
 
@@ -34,6 +35,7 @@ func TestServers(t *testing.T) {
 	CreateTestServers(t)
 	UpdateTestServers(t)
 	GetTestServers(t)
+	GetTestServersPaged(t)
 	DeleteTestServers(t)
 	DeleteTestCacheGroups(t)
 	DeleteTestPhysLocations(t)
@@ -111,6 +113,31 @@ func GetTestServers(t *testing.T) {
 	}
 }
 
+func GetTestServersPaged(t *testing.T) {
+
+	servers, _, err := TOSession.GetServers()
+	if err != nil {
+		t.Fatalf("cannot GET Servers: %v\n", err)
+	}
+
+	pages := 0
+	pagedServers := 0
+	_, err = TOSession.GetServersPaged(1, func(page []v13.Server) error {
+		pages++
+		pagedServers += len(page)
+		if len(page) > 1 {
+			t.Errorf("expected at most 1 Server per page, actual: %d\n", len(page))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Errorf("cannot GET Servers paged: %v\n", err)
+	}
+	if pagedServers != len(servers) || pages != len(servers) {
+		t.Errorf("expected %d Servers in %d pages, actual: %d Servers in %d pages\n", len(servers), len(servers), pagedServers, pages)
+	}
+}
+
 func UpdateTestServers(t *testing.T) {
 
 	firstServer := testData.Servers[0]
@@ -27,7 +27,7 @@ type TrafficControl struct {
 	CacheGroups                    []v13.CacheGroup                    `json:"cachegroups"`
 	DeliveryServiceRequests        []v12.DeliveryServiceRequest        `json:"deliveryServiceRequests"`
 	DeliveryServiceRequestComments []v12.DeliveryServiceRequestComment `json:"deliveryServiceRequestComments"`
-	DeliveryServices               []v12.DeliveryService               `json:"deliveryservices"`
+	DeliveryServices               []v12.DeliveryServiceNullable       `json:"deliveryservices"`
 	Divisions                      []v12.Division                      `json:"divisions"`
 	Profiles                       []v13.Profile                       `json:"profiles"`
 	Parameters                     []v12.Parameter                     `json:"parameters"`
@@ -0,0 +1,305 @@
+package api
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-log"
+	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+
+	"github.com/jmoiron/sqlx"
+)
+
+//this creates a handler function from the pointer to a struct implementing the BulkCreator interface
+//      the request body is a json array of objects, which are all validated before any are created
+//      validation errors are returned for every invalid element, prefixed with the element's index
+//      all objects are created in a single transaction, so if any create fails, none are created
+//      a single change log entry is written for the whole request
+func BulkCreateHandler(typeRef BulkCreator, db *sqlx.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		handleErrs := tc.GetHandleErrorsFunc(w, r)
+
+		objs, err := decodeBulkRequestBody(r, typeRef)
+		if err != nil {
+			handleErrs(http.StatusBadRequest, err)
+			return
+		}
+		if errs := validateBulk(objs, db); len(errs) > 0 {
+			handleErrs(http.StatusBadRequest, errs...)
+			return
+		}
+
+		user, err := auth.GetCurrentUser(r.Context())
+		if err != nil {
+			log.Errorf("unable to retrieve current user from context: %s", err)
+			handleErrs(http.StatusInternalServerError, err)
+			return
+		}
+		if status, err := checkBulkTenancy(objs, *user, db); err != nil {
+			handleErrs(status, err)
+			return
+		}
+
+		err, errType := bulkTx(db, *user, Created, typeRef.GetType(), objs, func(i Identifier, tx *sqlx.Tx) (error, tc.ApiErrorType) {
+			return i.(BulkCreator).CreateTx(tx, *user)
+		})
+		if err != nil {
+			tc.HandleErrorsWithType([]error{err}, errType, handleErrs)
+			return
+		}
+		writeBulkResponse(w, handleErrs, objs, tc.CreateAlerts(tc.SuccessLevel, bulkAlert(len(objs), typeRef.GetType(), Created)))
+	}
+}
+
+//this creates a handler function from the pointer to a struct implementing the BulkUpdater interface
+//      the request body is a json array of objects, each of which must contain its keys
+//      validation errors are returned for every invalid element, prefixed with the element's index
+//      all objects are updated in a single transaction, so if any update fails, none are updated
+//      a single change log entry is written for the whole request
+func BulkUpdateHandler(typeRef BulkUpdater, db *sqlx.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		handleErrs := tc.GetHandleErrorsFunc(w, r)
+
+		objs, err := decodeBulkRequestBody(r, typeRef)
+		if err != nil {
+			handleErrs(http.StatusBadRequest, err)
+			return
+		}
+		errs := validateBulkKeys(objs)
+		errs = append(errs, validateBulk(objs, db)...)
+		if len(errs) > 0 {
+			handleErrs(http.StatusBadRequest, errs...)
+			return
+		}
+
+		user, err := auth.GetCurrentUser(r.Context())
+		if err != nil {
+			log.Errorf("unable to retrieve current user from context: %s", err)
+			handleErrs(http.StatusInternalServerError, err)
+			return
+		}
+		if status, err := checkBulkTenancy(objs, *user, db); err != nil {
+			handleErrs(status, err)
+			return
+		}
+
+		err, errType := bulkTx(db, *user, Updated, typeRef.GetType(), objs, func(i Identifier, tx *sqlx.Tx) (error, tc.ApiErrorType) {
+			return i.(BulkUpdater).UpdateTx(tx, *user)
+		})
+		if err != nil {
+			tc.HandleErrorsWithType([]error{err}, errType, handleErrs)
+			return
+		}
+		writeBulkResponse(w, handleErrs, objs, tc.CreateAlerts(tc.SuccessLevel, bulkAlert(len(objs), typeRef.GetType(), Updated)))
+	}
+}
+
+//this creates a handler function from the pointer to a struct implementing the BulkDeleter interface
+//      the request body is a json array of objects containing only the keys of the objects to delete
+//      all objects are deleted in a single transaction, so if any delete fails, none are deleted
+//      a single change log entry is written for the whole request
+func BulkDeleteHandler(typeRef BulkDeleter, db *sqlx.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		handleErrs := tc.GetHandleErrorsFunc(w, r)
+
+		objs, err := decodeBulkRequestBody(r, typeRef)
+		if err != nil {
+			handleErrs(http.StatusBadRequest, err)
+			return
+		}
+		if errs := validateBulkKeys(objs); len(errs) > 0 {
+			handleErrs(http.StatusBadRequest, errs...)
+			return
+		}
+
+		user, err := auth.GetCurrentUser(r.Context())
+		if err != nil {
+			log.Errorf("unable to retrieve current user from context: %s", err)
+			handleErrs(http.StatusInternalServerError, err)
+			return
+		}
+		if status, err := checkBulkTenancy(objs, *user, db); err != nil {
+			handleErrs(status, err)
+			return
+		}
+
+		err, errType := bulkTx(db, *user, Deleted, typeRef.GetType(), objs, func(i Identifier, tx *sqlx.Tx) (error, tc.ApiErrorType) {
+			return i.(BulkDeleter).DeleteTx(tx, *user)
+		})
+		if err != nil {
+			tc.HandleErrorsWithType([]error{err}, errType, handleErrs)
+			return
+		}
+
+		resp := struct {
+			tc.Alerts
+		}{tc.CreateAlerts(tc.SuccessLevel, bulkAlert(len(objs), typeRef.GetType(), Deleted))}
+
+		respBts, err := json.Marshal(resp)
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, err)
+			return
+		}
+
+		w.Header().Set(tc.ContentType, tc.ApplicationJson)
+		fmt.Fprintf(w, "%s", respBts)
+	}
+}
+
+// decodeBulkRequestBody decodes the request body as a json array of the type of v, returning a new pointer to the type for each element. Like decodeAndValidateRequestBody, new copies are created so there are no issues with concurrent goroutines.
+func decodeBulkRequestBody(r *http.Request, v Identifier) ([]Identifier, error) {
+	typ := reflect.TypeOf(v)
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	payload := reflect.New(reflect.SliceOf(typ))
+	defer r.Body.Close()
+
+	if err := json.NewDecoder(r.Body).Decode(payload.Interface()); err != nil {
+		return nil, err
+	}
+	elems := payload.Elem()
+	if elems.Len() == 0 {
+		return nil, errors.New("request must contain at least one " + v.GetType())
+	}
+	objs := make([]Identifier, 0, elems.Len())
+	for i := 0; i < elems.Len(); i++ {
+		objs = append(objs, elems.Index(i).Addr().Interface().(Identifier))
+	}
+	return objs, nil
+}
+
+// validateBulk validates each of the given objects, returning the errors of all invalid objects, prefixed with their index in the request.
+func validateBulk(objs []Identifier, db *sqlx.DB) []error {
+	errs := []error{}
+	for n, obj := range objs {
+		for _, err := range obj.(Validator).Validate(db) {
+			errs = append(errs, bulkElementError(n, err))
+		}
+	}
+	return errs
+}
+
+// validateBulkKeys returns an error for each of the given objects which doesn't contain its keys, prefixed with its index in the request.
+func validateBulkKeys(objs []Identifier) []error {
+	errs := []error{}
+	for n, obj := range objs {
+		if _, ok := obj.GetKeys(); !ok {
+			errs = append(errs, bulkElementError(n, errors.New("unable to parse required keys")))
+		}
+	}
+	return errs
+}
+
+// checkBulkTenancy checks that the user is authorized on the tenant of each of the given objects which have tenancy, returning the HTTP status and error of the first which isn't.
+func checkBulkTenancy(objs []Identifier, user auth.CurrentUser, db *sqlx.DB) (int, error) {
+	for n, obj := range objs {
+		t, ok := obj.(Tenantable)
+		if !ok {
+			continue
+		}
+		authorized, err := t.IsTenantAuthorized(user, db)
+		if err != nil {
+			log.Errorln(bulkElementError(n, errors.New("checking tenancy: "+err.Error())))
+			return http.StatusInternalServerError, tc.DBError
+		}
+		if !authorized {
+			return http.StatusForbidden, bulkElementError(n, errors.New("not authorized on this tenant"))
+		}
+	}
+	return http.StatusOK, nil
+}
+
+// bulkTx calls op with each of the given objects in a single transaction, which is only committed if all of them succeed. The change log entry summarizing the change is written in the same transaction, so it's only logged if the change is committed.
+func bulkTx(db *sqlx.DB, user auth.CurrentUser, action string, typeName string, objs []Identifier, op func(Identifier, *sqlx.Tx) (error, tc.ApiErrorType)) (error, tc.ApiErrorType) {
+	rollbackTransaction := true
+	tx, err := db.Beginx()
+	defer func() {
+		if tx == nil || !rollbackTransaction {
+			return
+		}
+		err := tx.Rollback()
+		if err != nil {
+			log.Errorln(errors.New("rolling back transaction: " + err.Error()))
+		}
+	}()
+
+	if err != nil {
+		log.Error.Printf("could not begin transaction: %v", err)
+		return tc.DBError, tc.SystemError
+	}
+	for n, obj := range objs {
+		if err, errType := op(obj, tx); err != nil {
+			log.Errorf("bulk %s %s element %d: %v", strings.ToLower(action), typeName, n, err)
+			return bulkElementError(n, err), errType
+		}
+	}
+	if err := CreateChangeLogRawTx(ApiChange, bulkChangeLogMessage(action, typeName, objs), user, tx); err != nil {
+		return tc.DBError, tc.SystemError
+	}
+	err = tx.Commit()
+	if err != nil {
+		log.Errorln("Could not commit transaction: ", err)
+		return tc.DBError, tc.SystemError
+	}
+	rollbackTransaction = false
+	return nil, tc.NoError
+}
+
+// bulkChangeLogMessage returns the change log message for a bulk change, with the audit names of all changed objects.
+func bulkChangeLogMessage(action string, typeName string, objs []Identifier) string {
+	names := make([]string, 0, len(objs))
+	for _, obj := range objs {
+		names = append(names, obj.GetAuditName())
+	}
+	return action + " " + strconv.Itoa(len(objs)) + " " + typeName + " objects in bulk: " + strings.Join(names, ", ")
+}
+
+func bulkAlert(count int, typeName string, action string) string {
+	return strconv.Itoa(count) + " " + typeName + " objects were " + strings.ToLower(action) + "."
+}
+
+func bulkElementError(n int, err error) error {
+	return errors.New("element " + strconv.Itoa(n) + ": " + err.Error())
+}
+
+func writeBulkResponse(w http.ResponseWriter, handleErrs func(status int, errs ...error), objs []Identifier, alerts tc.Alerts) {
+	resp := struct {
+		Response interface{} `json:"response"`
+		tc.Alerts
+	}{objs, alerts}
+
+	respBts, err := json.Marshal(resp)
+	if err != nil {
+		handleErrs(http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Set(tc.ContentType, tc.ApplicationJson)
+	fmt.Fprintf(w, "%s", respBts)
+}
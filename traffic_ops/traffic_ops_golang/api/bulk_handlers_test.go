@@ -0,0 +1,238 @@
+package api
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+	"github.com/jmoiron/sqlx"
+
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+//BulkCreator interface functions
+func (i *tester) CreateTx(tx *sqlx.Tx, user auth.CurrentUser) (error, tc.ApiErrorType) {
+	return i.error, i.errorType
+}
+
+//BulkUpdater interface functions
+func (i *tester) UpdateTx(tx *sqlx.Tx, user auth.CurrentUser) (error, tc.ApiErrorType) {
+	return i.error, i.errorType
+}
+
+//BulkDeleter interface functions
+func (i *tester) DeleteTx(tx *sqlx.Tx, user auth.CurrentUser) (error, tc.ApiErrorType) {
+	return i.error, i.errorType
+}
+
+// tenantTester is a tester with tenancy, whose tenancy check fails with its error
+type tenantTester struct {
+	tester
+}
+
+//Tenantable interface functions
+func (i *tenantTester) IsTenantAuthorized(user auth.CurrentUser, db *sqlx.DB) (bool, error) {
+	return i.error == nil, i.error
+}
+
+func newBulkRequest(t *testing.T, body string) *http.Request {
+	r, err := http.NewRequest("", "", strings.NewReader(body))
+	if err != nil {
+		t.Fatal("Error creating new request")
+	}
+	ctx := context.WithValue(r.Context(), auth.CurrentUserKey,
+		auth.CurrentUser{UserName: "username", ID: 1, PrivLevel: auth.PrivLevelAdmin})
+	return r.WithContext(ctx)
+}
+
+func TestBulkCreateHandler(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	w := httptest.NewRecorder()
+	r := newBulkRequest(t, `[{"ID":1},{"ID":2}]`)
+
+	typeRef := tester{}
+	createFunc := BulkCreateHandler(&typeRef, db)
+
+	//verifies a single changelog is written in the transaction
+	expectedMessage := Created + " 2 tester objects in bulk: testerInstance:1, testerInstance:2"
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT").WithArgs(ApiChange, expectedMessage, 1).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	createFunc(w, r)
+
+	body := `{"response":[{"ID":1},{"ID":2}],"alerts":[{"text":"2 tester objects were created.","level":"success"}]}`
+	if w.Body.String() != body {
+		t.Error("Expected body", body, "got", w.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestBulkCreateHandlerValidationErrors(t *testing.T) {
+	mockDB, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	w := httptest.NewRecorder()
+	r := newBulkRequest(t, `[{"ID":0},{"ID":1},{"ID":-1}]`)
+
+	typeRef := tester{}
+	createFunc := BulkCreateHandler(&typeRef, db)
+
+	//nothing is created when any element is invalid, so the mock has no expectations
+	createFunc(w, r)
+
+	body := `{"alerts":[{"text":"element 0: ID is too low","level":"error"},{"text":"element 2: ID is too low","level":"error"}]}`
+	if w.Body.String() != body {
+		t.Error("Expected body", body, "got", w.Body.String())
+	}
+	if status, _ := r.Context().Value(tc.StatusKey).(int); status != http.StatusBadRequest {
+		t.Error("Expected status", http.StatusBadRequest, "got", status)
+	}
+}
+
+func TestBulkCreateHandlerEmpty(t *testing.T) {
+	mockDB, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	w := httptest.NewRecorder()
+	r := newBulkRequest(t, `[]`)
+
+	typeRef := tester{}
+	BulkCreateHandler(&typeRef, db)(w, r)
+
+	if status, _ := r.Context().Value(tc.StatusKey).(int); status != http.StatusBadRequest {
+		t.Error("Expected status", http.StatusBadRequest, "got", status)
+	}
+}
+
+func TestBulkDeleteHandler(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	w := httptest.NewRecorder()
+	r := newBulkRequest(t, `[{"ID":1},{"ID":2},{"ID":3}]`)
+
+	typeRef := tester{}
+	deleteFunc := BulkDeleteHandler(&typeRef, db)
+
+	expectedMessage := Deleted + " 3 tester objects in bulk: testerInstance:1, testerInstance:2, testerInstance:3"
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT").WithArgs(ApiChange, expectedMessage, 1).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	deleteFunc(w, r)
+
+	body := `{"alerts":[{"text":"3 tester objects were deleted.","level":"success"}]}`
+	if w.Body.String() != body {
+		t.Error("Expected body", body, "got", w.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestBulkTxRollback(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	objs := []Identifier{&tester{ID: 1}, &tester{ID: 2}, &tester{ID: 3}}
+	called := 0
+	op := func(i Identifier, tx *sqlx.Tx) (error, tc.ApiErrorType) {
+		called++
+		if i.(*tester).ID == 2 {
+			return errors.New("a tester with id 2 already exists"), tc.DataConflictError
+		}
+		return nil, tc.NoError
+	}
+
+	//no change log is written, and nothing is committed
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	err, errType := bulkTx(db, auth.CurrentUser{ID: 1}, Updated, "tester", objs, op)
+	if err == nil || err.Error() != "element 1: a tester with id 2 already exists" {
+		t.Errorf("expected element 1 error, actual %v", err)
+	}
+	if errType != tc.DataConflictError {
+		t.Errorf("expected error type %v, actual %v", tc.DataConflictError, errType)
+	}
+	if called != 2 {
+		t.Errorf("expected the transaction to stop at the failing element after 2 calls, actual %v", called)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestCheckBulkTenancy(t *testing.T) {
+	user := auth.CurrentUser{ID: 1}
+	objs := []Identifier{&tenantTester{tester{ID: 1}}, &tester{ID: 2}}
+	if status, err := checkBulkTenancy(objs, user, nil); status != http.StatusOK || err != nil {
+		t.Errorf("expected status %v, actual %v %v", http.StatusOK, status, err)
+	}
+
+	//a failed tenancy check is a server error, whose details are logged rather than returned
+	objs = []Identifier{&tenantTester{tester{ID: 1}}, &tenantTester{tester{ID: 2, error: errors.New("pq: connection refused")}}}
+	status, err := checkBulkTenancy(objs, user, nil)
+	if status != http.StatusInternalServerError || err != tc.DBError {
+		t.Errorf("expected status %v and %v, actual %v %v", http.StatusInternalServerError, tc.DBError, status, err)
+	}
+}
@@ -67,6 +67,11 @@ func CreateChangeLog(level string, action string, i Identifier, user auth.Curren
 		}
 	}
 
+	return CreateChangeLogRaw(level, message, user, db)
+}
+
+// CreateChangeLogRaw writes the given message to the change log, for changes which aren't a whole Identifier, such as a part of an object created along with it.
+func CreateChangeLogRaw(level string, message string, user auth.CurrentUser, db *sqlx.DB) error {
 	query := `INSERT INTO log (level, message, tm_user) VALUES ($1, $2, $3)`
 	log.Debugf("about to exec %s with %s", query, message)
 	_, err := db.Exec(query, level, message, user.ID)
@@ -76,3 +81,27 @@ func CreateChangeLog(level string, action string, i Identifier, user auth.Curren
 	}
 	return nil
 }
+
+// CreateChangeLogRawTx is like CreateChangeLogRaw, but writes the message in the given transaction, so it's only logged if the change is committed.
+func CreateChangeLogRawTx(level string, message string, user auth.CurrentUser, tx *sqlx.Tx) error {
+	query := `INSERT INTO log (level, message, tm_user) VALUES ($1, $2, $3)`
+	log.Debugf("about to exec %s with %s", query, message)
+	_, err := tx.Exec(query, level, message, user.ID)
+	if err != nil {
+		log.Errorf("received error: %++v from audit log insertion", err)
+		return err
+	}
+	return nil
+}
+
+// CreateChangeLogRawTxID is like CreateChangeLogRawTx, but returns the id of the change log entry, so it can be referenced by the change it logs.
+func CreateChangeLogRawTxID(level string, message string, user auth.CurrentUser, tx *sqlx.Tx) (int, error) {
+	query := `INSERT INTO log (level, message, tm_user) VALUES ($1, $2, $3) RETURNING id`
+	log.Debugf("about to exec %s with %s", query, message)
+	id := 0
+	if err := tx.QueryRow(query, level, message, user.ID).Scan(&id); err != nil {
+		log.Errorf("received error: %++v from audit log insertion", err)
+		return 0, err
+	}
+	return id, nil
+}
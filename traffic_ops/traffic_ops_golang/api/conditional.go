@@ -0,0 +1,256 @@
+package api
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-log"
+	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	ETagHeader              = "ETag"
+	LastModifiedHeader      = "Last-Modified"
+	IfMatchHeader           = "If-Match"
+	IfNoneMatchHeader       = "If-None-Match"
+	IfModifiedSinceHeader   = "If-Modified-Since"
+	IfUnmodifiedSinceHeader = "If-Unmodified-Since"
+)
+
+// ErrPreconditionsUnsupported is the error of conditional writes to objects whose preconditions can't be evaluated in the transaction of the write. They fail, rather than being made unconditionally.
+var ErrPreconditionsUnsupported = errors.New("conditional requests are not supported for this resource, remove the " + IfMatchHeader + " and " + IfUnmodifiedSinceHeader + " headers")
+
+// resourceState is the current ETag and last modified time of a resource, used to evaluate conditional requests.
+type resourceState struct {
+	ETag            string
+	LastModified    time.Time
+	HasLastModified bool
+	Exists          bool
+}
+
+// ETag returns the strong entity tag of the given response body.
+func ETag(body []byte) string {
+	sum := sha1.Sum(body)
+	return `"` + base64.RawURLEncoding.EncodeToString(sum[:]) + `"`
+}
+
+// LastModified returns the latest lastUpdated of the given results, and false if none of them have one.
+func LastModified(results []interface{}) (time.Time, bool) {
+	latest := time.Time{}
+	found := false
+	for _, result := range results {
+		bts, err := json.Marshal(result)
+		if err != nil {
+			continue
+		}
+		obj := struct {
+			LastUpdated *string `json:"lastUpdated"`
+		}{}
+		if err := json.Unmarshal(bts, &obj); err != nil || obj.LastUpdated == nil {
+			continue
+		}
+		t, err := time.Parse(tc.TimeLayout, *obj.LastUpdated)
+		if err != nil {
+			continue
+		}
+		if !found || t.After(latest) {
+			latest = t
+			found = true
+		}
+	}
+	return latest, found
+}
+
+// newResourceState returns the state of the given read results and the response body they're returned in.
+func newResourceState(results []interface{}, body []byte) resourceState {
+	lastModified, hasLastModified := LastModified(results)
+	return resourceState{ETag: ETag(body), LastModified: lastModified, HasLastModified: hasLastModified, Exists: len(results) > 0}
+}
+
+// setHeaders sets the ETag and Last-Modified response headers of the state.
+func (s resourceState) setHeaders(w http.ResponseWriter) {
+	w.Header().Set(ETagHeader, s.ETag)
+	if s.HasLastModified {
+		w.Header().Set(LastModifiedHeader, s.LastModified.UTC().Format(http.TimeFormat))
+	}
+}
+
+// hasPreconditions returns whether the request has If-Match or If-Unmodified-Since headers, which a write must satisfy.
+func hasPreconditions(r *http.Request) bool {
+	return r.Header.Get(IfMatchHeader) != "" || r.Header.Get(IfUnmodifiedSinceHeader) != ""
+}
+
+// checkPreconditions returns an error if the If-Match or If-Unmodified-Since headers of the request don't match the current state of the resource, per RFC7232.
+// If-Unmodified-Since is only evaluated without If-Match, and is an error for resources without a last updated time.
+func checkPreconditions(r *http.Request, s resourceState) error {
+	if ifMatch := r.Header.Get(IfMatchHeader); ifMatch != "" {
+		if !s.Exists {
+			return errors.New("the resource does not exist")
+		}
+		if strings.TrimSpace(ifMatch) == "*" || etagListContains(ifMatch, s.ETag, false) {
+			return nil
+		}
+		return errors.New("the resource has been modified, its ETag does not match " + IfMatchHeader)
+	}
+	if ifUnmodifiedSince := r.Header.Get(IfUnmodifiedSinceHeader); ifUnmodifiedSince != "" {
+		since, err := http.ParseTime(ifUnmodifiedSince)
+		if err != nil {
+			return nil // invalid dates are ignored, per RFC7232 3.4
+		}
+		if !s.Exists || !s.HasLastModified {
+			return errors.New("the resource has no last modified time to compare to " + IfUnmodifiedSinceHeader)
+		}
+		if s.LastModified.After(since) {
+			return errors.New("the resource has been modified since " + ifUnmodifiedSince)
+		}
+	}
+	return nil
+}
+
+// notModified returns whether the If-None-Match or If-Modified-Since headers of the request match the current state of the resource, so a read can return 304 Not Modified.
+// If-Modified-Since is only evaluated without If-None-Match.
+func notModified(r *http.Request, s resourceState) bool {
+	if ifNoneMatch := r.Header.Get(IfNoneMatchHeader); ifNoneMatch != "" {
+		return strings.TrimSpace(ifNoneMatch) == "*" || etagListContains(ifNoneMatch, s.ETag, true)
+	}
+	if ifModifiedSince := r.Header.Get(IfModifiedSinceHeader); ifModifiedSince != "" && s.HasLastModified {
+		since, err := http.ParseTime(ifModifiedSince)
+		if err != nil {
+			return false
+		}
+		return !s.LastModified.After(since)
+	}
+	return false
+}
+
+// etagListContains returns whether the comma-separated list of entity tags contains the given tag. Weak tags only match with weak comparison.
+func etagListContains(list string, etag string, weak bool) bool {
+	for _, tag := range strings.Split(list, ",") {
+		tag = strings.TrimSpace(tag)
+		if strings.HasPrefix(tag, "W/") {
+			if !weak {
+				continue
+			}
+			tag = strings.TrimPrefix(tag, "W/")
+		}
+		if tag == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// getResourceState reads the current state of the object with the given keys, if the object is a Reader.
+// The state is the same as the unpaginated GET of the object, so ETags from it can be used in If-Match.
+// It returns false if the object isn't a Reader, and its preconditions can't be evaluated.
+func getResourceState(obj interface{}, keyFields []KeyFieldInfo, params map[string]string, user auth.CurrentUser, db *sqlx.DB) (resourceState, bool, []error, tc.ApiErrorType) {
+	reader, ok := obj.(Reader)
+	if !ok {
+		return resourceState{}, false, nil, tc.NoError
+	}
+	keyParams := map[string]string{}
+	for _, keyFieldInfo := range keyFields {
+		keyParams[keyFieldInfo.Field] = params[keyFieldInfo.Field]
+	}
+	results, errs, errType := reader.Read(db, keyParams, user)
+	if len(errs) > 0 {
+		return resourceState{}, true, errs, errType
+	}
+	if len(results) > 1 {
+		log.Errorf("reading current state for precondition: expected at most 1 result for keys %v, got %v", keyParams, len(results))
+		return resourceState{}, true, []error{errors.New("reading current state: keys matched multiple objects")}, tc.SystemError
+	}
+	body, err := json.Marshal(readResponse{Response: results})
+	if err != nil {
+		return resourceState{}, true, []error{err}, tc.SystemError
+	}
+	return newResourceState(results, body), true, nil, tc.NoError
+}
+
+// canWriteIfUnmodified returns whether the request's preconditions can be evaluated for the object in the transaction of its write, which requires it to be a Reader.
+func canWriteIfUnmodified(r *http.Request, obj interface{}) bool {
+	_, ok := obj.(Reader)
+	return ok && hasPreconditions(r)
+}
+
+// writeIfUnmodified runs the write in a new transaction, and commits it only if the request's If-Match and If-Unmodified-Since preconditions hold for the object's current state.
+// The write locks the object's row first, so the committed state read afterwards is the state the write replaced, and can't change before the transaction ends.
+// Errors, including 412 Precondition Failed, are written with handleErrs, and false is returned.
+func writeIfUnmodified(r *http.Request, handleErrs func(status int, errs ...error), obj interface{}, keyFields []KeyFieldInfo, params map[string]string, user auth.CurrentUser, db *sqlx.DB, write func(tx *sqlx.Tx) (error, tc.ApiErrorType)) bool {
+	tx, err := db.Beginx()
+	if err != nil {
+		log.Errorln("could not begin transaction: " + err.Error())
+		handleErrs(http.StatusInternalServerError, tc.DBError)
+		return false
+	}
+	committed := false
+	defer func() {
+		if committed {
+			return
+		}
+		if err := tx.Rollback(); err != nil {
+			log.Errorln("rolling back transaction: " + err.Error())
+		}
+	}()
+
+	if err, errType := write(tx); err != nil {
+		tc.HandleErrorsWithType([]error{err}, errType, handleErrs)
+		return false
+	}
+	state, _, errs, errType := getResourceState(obj, keyFields, params, user, db)
+	if len(errs) > 0 {
+		tc.HandleErrorsWithType(errs, errType, handleErrs)
+		return false
+	}
+	if err := checkPreconditions(r, state); err != nil {
+		handleErrs(http.StatusPreconditionFailed, err)
+		return false
+	}
+	if err := tx.Commit(); err != nil {
+		log.Errorln("could not commit transaction: " + err.Error())
+		handleErrs(http.StatusInternalServerError, tc.DBError)
+		return false
+	}
+	committed = true
+	return true
+}
+
+// readResponse is the body of a ReadHandler response. The summary is only added to paginated responses, and the links to those with a next page, so unpaginated responses are unchanged.
+type readResponse struct {
+	Response []interface{}         `json:"response"`
+	Summary  *tc.PaginationSummary `json:"summary,omitempty"`
+	Links    *tc.PaginationLinks   `json:"links,omitempty"`
+}
+
+// setStatus sets the status the response will be written with, as tc.GetHandleErrorsFunc does for errors.
+func setStatus(r *http.Request, status int) {
+	*r = *r.WithContext(context.WithValue(r.Context(), tc.StatusKey, status))
+}
@@ -0,0 +1,108 @@
+package api
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
+)
+
+func TestLastModified(t *testing.T) {
+	older := time.Date(2018, 1, 19, 19, 1, 21, 0, time.UTC)
+	newer := older.Add(time.Hour)
+	type obj struct {
+		LastUpdated *tc.TimeNoMod `json:"lastUpdated"`
+	}
+	results := []interface{}{
+		obj{&tc.TimeNoMod{Time: older, Valid: true}},
+		obj{&tc.TimeNoMod{Time: newer, Valid: true}},
+		obj{nil},
+	}
+	if actual, ok := LastModified(results); !ok || !actual.Equal(newer) {
+		t.Errorf("expected: last modified %v, actual: %v %v", newer, actual, ok)
+	}
+	if _, ok := LastModified([]interface{}{obj{nil}, tester{ID: 1}}); ok {
+		t.Errorf("expected: no last modified for results without lastUpdated, actual: found")
+	}
+}
+
+func TestCheckPreconditions(t *testing.T) {
+	lastModified := time.Date(2018, 1, 19, 19, 1, 21, 0, time.UTC)
+	state := resourceState{ETag: ETag([]byte("body")), LastModified: lastModified, HasLastModified: true, Exists: true}
+	tests := []struct {
+		headers     map[string]string
+		expectedErr bool
+	}{
+		{map[string]string{}, false},
+		{map[string]string{IfMatchHeader: state.ETag}, false},
+		{map[string]string{IfMatchHeader: `"other", ` + state.ETag}, false},
+		{map[string]string{IfMatchHeader: "*"}, false},
+		{map[string]string{IfMatchHeader: `"other"`}, true},
+		{map[string]string{IfMatchHeader: "W/" + state.ETag}, true},
+		{map[string]string{IfUnmodifiedSinceHeader: lastModified.Format(http.TimeFormat)}, false},
+		{map[string]string{IfUnmodifiedSinceHeader: lastModified.Add(-time.Second).Format(http.TimeFormat)}, true},
+		{map[string]string{IfUnmodifiedSinceHeader: "not a date"}, false},
+		{map[string]string{IfMatchHeader: state.ETag, IfUnmodifiedSinceHeader: lastModified.Add(-time.Second).Format(http.TimeFormat)}, false},
+	}
+	for _, test := range tests {
+		r, _ := http.NewRequest(http.MethodPut, "", nil)
+		for k, v := range test.headers {
+			r.Header.Set(k, v)
+		}
+		if err := checkPreconditions(r, state); (err != nil) != test.expectedErr {
+			t.Errorf("expected: error %v for headers %v, actual: %v", test.expectedErr, test.headers, err)
+		}
+	}
+
+	r, _ := http.NewRequest(http.MethodPut, "", nil)
+	r.Header.Set(IfMatchHeader, "*")
+	if err := checkPreconditions(r, resourceState{}); err == nil {
+		t.Errorf("expected: error for If-Match of a missing resource, actual: nil")
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	lastModified := time.Date(2018, 1, 19, 19, 1, 21, 0, time.UTC)
+	state := resourceState{ETag: ETag([]byte("body")), LastModified: lastModified, HasLastModified: true, Exists: true}
+	tests := []struct {
+		headers     map[string]string
+		notModified bool
+	}{
+		{map[string]string{}, false},
+		{map[string]string{IfNoneMatchHeader: state.ETag}, true},
+		{map[string]string{IfNoneMatchHeader: "W/" + state.ETag}, true},
+		{map[string]string{IfNoneMatchHeader: `"other"`}, false},
+		{map[string]string{IfModifiedSinceHeader: lastModified.Format(http.TimeFormat)}, true},
+		{map[string]string{IfModifiedSinceHeader: lastModified.Add(-time.Second).Format(http.TimeFormat)}, false},
+		{map[string]string{IfNoneMatchHeader: `"other"`, IfModifiedSinceHeader: lastModified.Format(http.TimeFormat)}, false},
+	}
+	for _, test := range tests {
+		r, _ := http.NewRequest(http.MethodGet, "", nil)
+		for k, v := range test.headers {
+			r.Header.Set(k, v)
+		}
+		if actual := notModified(r, state); actual != test.notModified {
+			t.Errorf("expected: not modified %v for headers %v, actual: %v", test.notModified, test.headers, actual)
+		}
+	}
+}
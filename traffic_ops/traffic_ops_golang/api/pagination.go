@@ -0,0 +1,239 @@
+package api
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/dbhelpers"
+)
+
+const (
+	LimitParam  = dbhelpers.LimitParam
+	OffsetParam = dbhelpers.OffsetParam
+	CursorParam = "cursor"
+	FieldsParam = "fields"
+)
+
+const cursorPrefix = "offset:"
+
+// Pagination is the page of results requested with the limit, and either the offset or cursor, query parameters. A Limit of 0 is all results.
+type Pagination struct {
+	Limit  int
+	Offset int
+}
+
+// ParsePagination returns the requested page from the given parameters. The offset and cursor parameters are mutually exclusive.
+func ParsePagination(params map[string]string) (Pagination, []error) {
+	p := Pagination{}
+	errs := []error{}
+	if limit, ok := params[LimitParam]; ok {
+		l, err := strconv.Atoi(limit)
+		if err != nil || l < 1 {
+			errs = append(errs, errors.New(LimitParam+" must be a positive integer"))
+		}
+		p.Limit = l
+	}
+	offset, hasOffset := params[OffsetParam]
+	cursor, hasCursor := params[CursorParam]
+	switch {
+	case hasOffset && hasCursor:
+		errs = append(errs, errors.New(OffsetParam+" and "+CursorParam+" cannot both be given"))
+	case hasOffset:
+		o, err := strconv.Atoi(offset)
+		if err != nil || o < 0 {
+			errs = append(errs, errors.New(OffsetParam+" must be a non-negative integer"))
+		}
+		p.Offset = o
+	case hasCursor:
+		o, err := DecodeCursor(cursor)
+		if err != nil {
+			errs = append(errs, errors.New(CursorParam+" "+err.Error()))
+		}
+		p.Offset = o
+	}
+	if (hasOffset || hasCursor) && p.Limit == 0 && len(errs) == 0 {
+		errs = append(errs, errors.New(LimitParam+" is required with "+OffsetParam+" or "+CursorParam))
+	}
+	return p, errs
+}
+
+// EncodeCursor returns the opaque cursor of the page starting at the given offset.
+func EncodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(cursorPrefix + strconv.Itoa(offset)))
+}
+
+// DecodeCursor returns the offset of the given cursor, created by EncodeCursor.
+func DecodeCursor(cursor string) (int, error) {
+	bts, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || !strings.HasPrefix(string(bts), cursorPrefix) {
+		return 0, errors.New("is not a valid cursor")
+	}
+	offset, err := strconv.Atoi(strings.TrimPrefix(string(bts), cursorPrefix))
+	if err != nil || offset < 0 {
+		return 0, errors.New("is not a valid cursor")
+	}
+	return offset, nil
+}
+
+// SetParams replaces the pagination parameters with the limit and offset of the page, which Readers add to their queries with dbhelpers.BuildWhereAndOrderBy.
+// One more result than the limit is requested, so Trim can tell whether there is a next page.
+func (p Pagination) SetParams(params map[string]string) {
+	delete(params, CursorParam)
+	if p.Limit == 0 {
+		return
+	}
+	params[LimitParam] = strconv.Itoa(p.Limit + 1)
+	params[OffsetParam] = strconv.Itoa(p.Offset)
+}
+
+// Trim returns the page of the results read with SetParams, and whether there are more results after it.
+func (p Pagination) Trim(results []interface{}) ([]interface{}, bool) {
+	if p.Limit == 0 || len(results) <= p.Limit {
+		return results, false
+	}
+	return results[:p.Limit], true
+}
+
+// Count returns the number of results of all pages, from the page read with SetParams, if it can be known from the page.
+// It can't be when there are more results after the page, or when the page is empty because it is past the end of the results,
+// in which case the results must be counted with a read of the UnpagedParams.
+func (p Pagination) Count(page []interface{}, more bool) (int, bool) {
+	if more || (len(page) == 0 && p.Offset > 0) {
+		return 0, false
+	}
+	return p.Offset + len(page), true
+}
+
+// UnpagedParams returns a copy of the given parameters without the pagination parameters, to read all results with the same filters.
+func UnpagedParams(params map[string]string) map[string]string {
+	unpaged := make(map[string]string, len(params))
+	for k, v := range params {
+		unpaged[k] = v
+	}
+	delete(unpaged, LimitParam)
+	delete(unpaged, OffsetParam)
+	delete(unpaged, CursorParam)
+	return unpaged
+}
+
+// NextLink returns the path and query of the page after this one, or the empty string if there are no more results.
+// The next page uses a cursor rather than an offset, and keeps all other query parameters of the given URL.
+func (p Pagination) NextLink(u *url.URL, more bool) string {
+	if p.Limit == 0 || !more {
+		return ""
+	}
+	q := u.Query()
+	q.Del(OffsetParam)
+	q.Set(CursorParam, EncodeCursor(p.Offset+p.Limit))
+	return u.Path + "?" + q.Encode()
+}
+
+// ParseFields returns the JSON field names of the comma-separated fields parameter, or nil if it wasn't given.
+func ParseFields(params map[string]string) []string {
+	fieldsParam, ok := params[FieldsParam]
+	if !ok {
+		return nil
+	}
+	fields := []string{}
+	for _, field := range strings.Split(fieldsParam, ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// SelectFields returns the results with only the given JSON fields. If the results are structs, fields they don't have are an error.
+func SelectFields(results []interface{}, fields []string) ([]interface{}, []error) {
+	if len(fields) == 0 || len(results) == 0 {
+		return results, nil
+	}
+	if known := jsonFieldNames(reflect.TypeOf(results[0])); len(known) > 0 {
+		errs := []error{}
+		for _, field := range fields {
+			if _, ok := known[field]; !ok {
+				errs = append(errs, errors.New(FieldsParam+" '"+field+"' is not a field of this object"))
+			}
+		}
+		if len(errs) > 0 {
+			return nil, errs
+		}
+	}
+
+	selected := make([]interface{}, 0, len(results))
+	for _, result := range results {
+		bts, err := json.Marshal(result)
+		if err != nil {
+			return nil, []error{errors.New("marshalling result for field selection: " + err.Error())}
+		}
+		all := map[string]json.RawMessage{}
+		if err := json.Unmarshal(bts, &all); err != nil {
+			return nil, []error{errors.New("unmarshalling result for field selection: " + err.Error())}
+		}
+		obj := make(map[string]json.RawMessage, len(fields))
+		for _, field := range fields {
+			if val, ok := all[field]; ok {
+				obj[field] = val
+			}
+		}
+		selected = append(selected, obj)
+	}
+	return selected, nil
+}
+
+// jsonFieldNames returns the JSON names of the exported fields of the given struct type, including those of embedded structs.
+func jsonFieldNames(typ reflect.Type) map[string]struct{} {
+	names := map[string]struct{}{}
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return names
+	}
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		tag := field.Tag.Get("json")
+		name := strings.Split(tag, ",")[0]
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" {
+			for embedded := range jsonFieldNames(field.Type) {
+				names[embedded] = struct{}{}
+			}
+			continue
+		}
+		if field.PkgPath != "" {
+			continue // unexported
+		}
+		if name == "" {
+			name = field.Name
+		}
+		names[name] = struct{}{}
+	}
+	return names
+}
@@ -0,0 +1,186 @@
+package api
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestParsePagination(t *testing.T) {
+	p, errs := ParsePagination(map[string]string{})
+	if len(errs) > 0 || p.Limit != 0 || p.Offset != 0 {
+		t.Errorf("expected: no pagination, actual: %+v %v", p, errs)
+	}
+
+	p, errs = ParsePagination(map[string]string{"limit": "10", "offset": "20"})
+	if len(errs) > 0 || p.Limit != 10 || p.Offset != 20 {
+		t.Errorf("expected: limit 10 offset 20, actual: %+v %v", p, errs)
+	}
+
+	p, errs = ParsePagination(map[string]string{"limit": "10", "cursor": EncodeCursor(30)})
+	if len(errs) > 0 || p.Limit != 10 || p.Offset != 30 {
+		t.Errorf("expected: limit 10 offset 30, actual: %+v %v", p, errs)
+	}
+
+	invalid := []map[string]string{
+		{"limit": "0"},
+		{"limit": "-1"},
+		{"limit": "ten"},
+		{"limit": "10", "offset": "-1"},
+		{"limit": "10", "offset": "1", "cursor": EncodeCursor(1)},
+		{"limit": "10", "cursor": "not a cursor"},
+		{"offset": "10"},
+	}
+	for _, params := range invalid {
+		if _, errs := ParsePagination(params); len(errs) == 0 {
+			t.Errorf("expected: error for %v, actual: nil", params)
+		}
+	}
+}
+
+func TestCursor(t *testing.T) {
+	for _, offset := range []int{0, 1, 1000000} {
+		actual, err := DecodeCursor(EncodeCursor(offset))
+		if err != nil || actual != offset {
+			t.Errorf("expected: cursor offset %v, actual: %v %v", offset, actual, err)
+		}
+	}
+}
+
+func TestSetParams(t *testing.T) {
+	params := map[string]string{"cursor": EncodeCursor(4), "type": "EDGE"}
+	Pagination{Limit: 2, Offset: 4}.SetParams(params)
+	expected := map[string]string{"limit": "3", "offset": "4", "type": "EDGE"}
+	if !reflect.DeepEqual(params, expected) {
+		t.Errorf("expected: params %v, actual: %v", expected, params)
+	}
+
+	params = map[string]string{"type": "EDGE"}
+	Pagination{}.SetParams(params)
+	if expected := map[string]string{"type": "EDGE"}; !reflect.DeepEqual(params, expected) {
+		t.Errorf("expected: unpaginated params %v, actual: %v", expected, params)
+	}
+}
+
+func TestTrim(t *testing.T) {
+	tests := []struct {
+		p        Pagination
+		results  []interface{}
+		expected []interface{}
+		more     bool
+	}{
+		{Pagination{}, []interface{}{1, 2, 3}, []interface{}{1, 2, 3}, false},
+		{Pagination{Limit: 2}, []interface{}{1, 2, 3}, []interface{}{1, 2}, true},
+		{Pagination{Limit: 2, Offset: 2}, []interface{}{3, 4}, []interface{}{3, 4}, false},
+		{Pagination{Limit: 2, Offset: 4}, []interface{}{}, []interface{}{}, false},
+	}
+	for _, test := range tests {
+		actual, more := test.p.Trim(test.results)
+		if !reflect.DeepEqual(actual, test.expected) || more != test.more {
+			t.Errorf("expected: page %+v of %v to be %v %v, actual: %v %v", test.p, test.results, test.expected, test.more, actual, more)
+		}
+	}
+}
+
+func TestCount(t *testing.T) {
+	tests := []struct {
+		p     Pagination
+		page  []interface{}
+		more  bool
+		count int
+		ok    bool
+	}{
+		{Pagination{Limit: 2}, []interface{}{1}, false, 1, true},
+		{Pagination{Limit: 2}, []interface{}{1, 2}, true, 0, false},
+		{Pagination{Limit: 2, Offset: 2}, []interface{}{3, 4}, false, 4, true},
+		{Pagination{Limit: 2}, []interface{}{}, false, 0, true},
+		{Pagination{Limit: 2, Offset: 4}, []interface{}{}, false, 0, false},
+	}
+	for _, test := range tests {
+		count, ok := test.p.Count(test.page, test.more)
+		if count != test.count || ok != test.ok {
+			t.Errorf("expected: count of page %+v %v %v to be %v %v, actual: %v %v", test.p, test.page, test.more, test.count, test.ok, count, ok)
+		}
+	}
+}
+
+func TestUnpagedParams(t *testing.T) {
+	params := map[string]string{"limit": "3", "offset": "2", "cursor": EncodeCursor(2), "type": "EDGE"}
+	unpaged := UnpagedParams(params)
+	if !reflect.DeepEqual(unpaged, map[string]string{"type": "EDGE"}) {
+		t.Errorf("expected: params without pagination, actual: %v", unpaged)
+	}
+	if len(params) != 4 {
+		t.Errorf("expected: given params unchanged, actual: %v", params)
+	}
+}
+
+func TestNextLink(t *testing.T) {
+	u, _ := url.Parse("/api/1.3/servers?limit=2&offset=2&type=EDGE")
+	p := Pagination{Limit: 2, Offset: 2}
+	next, err := url.Parse(p.NextLink(u, true))
+	if err != nil {
+		t.Fatalf("expected: valid next link, actual: %v", err)
+	}
+	q := next.Query()
+	if next.Path != "/api/1.3/servers" || q.Get("offset") != "" || q.Get("limit") != "2" || q.Get("type") != "EDGE" {
+		t.Errorf("expected: next link with limit, type, and cursor, actual: %v", next)
+	}
+	if offset, err := DecodeCursor(q.Get("cursor")); err != nil || offset != 4 {
+		t.Errorf("expected: next link cursor offset 4, actual: %v %v", offset, err)
+	}
+
+	if link := (Pagination{Limit: 2, Offset: 4}).NextLink(u, false); link != "" {
+		t.Errorf("expected: no next link on the last page, actual: %v", link)
+	}
+}
+
+func TestSelectFields(t *testing.T) {
+	type embedded struct {
+		Name string `json:"name"`
+	}
+	type obj struct {
+		embedded
+		ID     int    `json:"id"`
+		Desc   string `json:"description,omitempty"`
+		Secret string `json:"-"`
+	}
+
+	results := []interface{}{obj{embedded{"one"}, 1, "first", "s"}, &obj{embedded{"two"}, 2, "", "s"}}
+	selected, errs := SelectFields(results, []string{"id", "name"})
+	if len(errs) > 0 {
+		t.Fatalf("expected: nil errors, actual: %v", errs)
+	}
+	bts, _ := json.Marshal(selected)
+	if expected := `[{"id":1,"name":"one"},{"id":2,"name":"two"}]`; string(bts) != expected {
+		t.Errorf("expected: %v, actual: %v", expected, string(bts))
+	}
+
+	if _, errs := SelectFields(results, []string{"id", "Secret"}); len(errs) != 1 {
+		t.Errorf("expected: 1 error for unknown field, actual: %v", errs)
+	}
+
+	if actual, errs := SelectFields(results, nil); len(errs) > 0 || !reflect.DeepEqual(actual, results) {
+		t.Errorf("expected: unselected results, actual: %v %v", actual, errs)
+	}
+}
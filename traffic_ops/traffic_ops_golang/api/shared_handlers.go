@@ -120,6 +120,9 @@ func decodeAndValidateRequestBody(r *http.Request, v Validator, db *sqlx.DB) (in
 //this creates a handler function from the pointer to a struct implementing the Reader interface
 //      this handler retrieves the user from the context
 //      combines the path and query parameters
+//      pages the results and selects their fields, per the limit, offset, cursor, and fields parameters
+//      summarizes paginated results with the count of all results and the next page
+//      sets the ETag and Last-Modified headers, and returns 304 Not Modified if If-None-Match or If-Modified-Since match
 //      produces the proper status code based on the error code returned
 //      marshals the structs returned into the proper response json
 func ReadHandler(typeRef Reader, db *sqlx.DB) http.HandlerFunc {
@@ -143,14 +146,42 @@ func ReadHandler(typeRef Reader, db *sqlx.DB) http.HandlerFunc {
 			return
 		}
 
+		pagination, errs := ParsePagination(params)
+		if len(errs) > 0 {
+			handleErrs(http.StatusBadRequest, errs...)
+			return
+		}
+
+		pagination.SetParams(params)
 		results, errs, errType := typeRef.Read(db, params, *user)
 		if len(errs) > 0 {
 			tc.HandleErrorsWithType(errs, errType, handleErrs)
 			return
 		}
-		resp := struct {
-			Response []interface{} `json:"response"`
-		}{results}
+		results, more := pagination.Trim(results)
+
+		page, errs := SelectFields(results, ParseFields(params))
+		if len(errs) > 0 {
+			handleErrs(http.StatusBadRequest, errs...)
+			return
+		}
+		resp := readResponse{Response: page}
+		if pagination.Limit > 0 {
+			count, ok := pagination.Count(results, more)
+			if !ok {
+				all, errs, errType := typeRef.Read(db, UnpagedParams(params), *user)
+				if len(errs) > 0 {
+					tc.HandleErrorsWithType(errs, errType, handleErrs)
+					return
+				}
+				count = len(all)
+			}
+			next := pagination.NextLink(r.URL, more)
+			resp.Summary = &tc.PaginationSummary{Count: count, Next: next}
+			if next != "" {
+				resp.Links = &tc.PaginationLinks{Next: next}
+			}
+		}
 
 		respBts, err := json.Marshal(resp)
 		if err != nil {
@@ -158,6 +189,13 @@ func ReadHandler(typeRef Reader, db *sqlx.DB) http.HandlerFunc {
 			return
 		}
 
+		state := newResourceState(results, respBts)
+		state.setHeaders(w)
+		if notModified(r, state) {
+			setStatus(r, http.StatusNotModified)
+			return
+		}
+
 		w.Header().Set("Content-Type", "application/json")
 		fmt.Fprintf(w, "%s", respBts)
 	}
@@ -169,6 +207,7 @@ func ReadHandler(typeRef Reader, db *sqlx.DB) http.HandlerFunc {
 //   *fetching the id from the path parameter
 //   *current user
 //   *decoding and validating the struct
+//   *If-Match and If-Unmodified-Since preconditions, evaluated in the update's transaction; they fail unless the struct is also a Reader and BulkUpdater
 //   *change log entry
 //   *forming and writing the body over the wire
 func UpdateHandler(typeRef Updater, db *sqlx.DB) http.HandlerFunc {
@@ -240,11 +279,24 @@ func UpdateHandler(typeRef Updater, db *sqlx.DB) http.HandlerFunc {
 			}
 		}
 
-		//run the update and handle any error
-		err, errType := u.Update(db, *user)
-		if err != nil {
-			tc.HandleErrorsWithType([]error{err}, errType, handleErrs)
-			return
+		// if the request is conditional, update only if the object hasn't been modified since the user read it
+		if hasPreconditions(r) {
+			bu, ok := u.(BulkUpdater)
+			if !ok || !canWriteIfUnmodified(r, u) {
+				handleErrs(http.StatusPreconditionFailed, ErrPreconditionsUnsupported)
+				return
+			}
+			update := func(tx *sqlx.Tx) (error, tc.ApiErrorType) { return bu.UpdateTx(tx, *user) }
+			if !writeIfUnmodified(r, handleErrs, u, keyFields, params, *user, db, update) {
+				return
+			}
+		} else {
+			//run the update and handle any error
+			err, errType := u.Update(db, *user)
+			if err != nil {
+				tc.HandleErrorsWithType([]error{err}, errType, handleErrs)
+				return
+			}
 		}
 		//auditing here
 		CreateChangeLog(ApiChange, Updated, u, *user, db)
@@ -270,6 +322,7 @@ func UpdateHandler(typeRef Updater, db *sqlx.DB) http.HandlerFunc {
 //   this generic handler encapsulates the logic for handling:
 //   *fetching the id from the path parameter
 //   *current user
+//   *If-Match and If-Unmodified-Since preconditions, evaluated in the delete's transaction; they fail unless the struct is also a Reader and BulkDeleter
 //   *change log entry
 //   *forming and writing the body over the wire
 func DeleteHandler(typeRef Deleter, db *sqlx.DB) http.HandlerFunc {
@@ -324,11 +377,24 @@ func DeleteHandler(typeRef Deleter, db *sqlx.DB) http.HandlerFunc {
 		}
 
 		log.Debugf("calling delete on object: %++v", d) //should have id set now
-		err, errType := d.Delete(db, *user)
-		if err != nil {
-			log.Errorf("error deleting: %++v", err)
-			tc.HandleErrorsWithType([]error{err}, errType, handleErrs)
-			return
+		// if the request is conditional, delete only if the object hasn't been modified since the user read it
+		if hasPreconditions(r) {
+			bd, ok := d.(BulkDeleter)
+			if !ok || !canWriteIfUnmodified(r, d) {
+				handleErrs(http.StatusPreconditionFailed, ErrPreconditionsUnsupported)
+				return
+			}
+			del := func(tx *sqlx.Tx) (error, tc.ApiErrorType) { return bd.DeleteTx(tx, *user) }
+			if !writeIfUnmodified(r, handleErrs, d, keyFields, params, *user, db, del) {
+				return
+			}
+		} else {
+			err, errType := d.Delete(db, *user)
+			if err != nil {
+				log.Errorf("error deleting: %++v", err)
+				tc.HandleErrorsWithType([]error{err}, errType, handleErrs)
+				return
+			}
 		}
 		//audit here
 		log.Debugf("changelog for delete on object")
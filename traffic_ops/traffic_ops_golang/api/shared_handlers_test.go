@@ -27,6 +27,7 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
 	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/auth"
@@ -88,6 +89,26 @@ func (i *emptyTester) Read(db *sqlx.DB, v map[string]string, user auth.CurrentUs
 	return []interface{}{}, nil, tc.NoError
 }
 
+// pagedTester reads 5 results, limited and offset like a database query built with dbhelpers.BuildWhereAndOrderBy
+type pagedTester tester
+
+func (i *pagedTester) Read(db *sqlx.DB, v map[string]string, user auth.CurrentUser) ([]interface{}, []error, tc.ApiErrorType) {
+	results := []interface{}{}
+	for id := 1; id <= 5; id++ {
+		results = append(results, tester{ID: id})
+	}
+	if offset, err := strconv.Atoi(v[OffsetParam]); err == nil {
+		if offset > len(results) {
+			offset = len(results)
+		}
+		results = results[offset:]
+	}
+	if limit, err := strconv.Atoi(v[LimitParam]); err == nil && limit < len(results) {
+		results = results[:limit]
+	}
+	return results, nil, tc.NoError
+}
+
 //Updater interface functions
 func (i *tester) Update(db *sqlx.DB, user auth.CurrentUser) (error, tc.ApiErrorType) {
 	return i.error, i.errorType
@@ -213,6 +234,355 @@ func TestReadHandler(t *testing.T) {
 	}
 }
 
+func TestReadHandlerPaginated(t *testing.T) {
+	mockDB, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	w := httptest.NewRecorder()
+	r, err := http.NewRequest("", "/api/1.3/testers?limit=1&fields=ID", nil)
+	if err != nil {
+		t.Error("Error creating new request")
+	}
+
+	ctx := r.Context()
+	ctx = context.WithValue(ctx, auth.CurrentUserKey,
+		auth.CurrentUser{UserName: "username", ID: 1, PrivLevel: auth.PrivLevelAdmin})
+	ctx = context.WithValue(ctx, PathParamsKey, map[string]string{})
+	r = r.WithContext(ctx)
+
+	typeRef := tester{}
+	readFunc := ReadHandler(&typeRef, db)
+
+	readFunc(w, r)
+
+	//verifies the body has no next link because there is only one result
+	body := `{"response":[{"ID":1}],"summary":{"count":1}}`
+	if w.Body.String() != body {
+		t.Error("Expected body", body, "got", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	r, err = http.NewRequest("", "/api/1.3/testers?limit=1&offset=1&cursor="+EncodeCursor(1), nil)
+	if err != nil {
+		t.Error("Error creating new request")
+	}
+	r = r.WithContext(ctx)
+	readFunc(w, r)
+
+	//verifies an offset and cursor together are an error
+	body = `{"alerts":[{"text":"offset and cursor cannot both be given","level":"error"}]}`
+	if w.Body.String() != body {
+		t.Error("Expected body", body, "got", w.Body.String())
+	}
+	if status, _ := r.Context().Value(tc.StatusKey).(int); status != http.StatusBadRequest {
+		t.Error("Expected status", http.StatusBadRequest, "got", status)
+	}
+}
+
+func TestReadHandlerSummary(t *testing.T) {
+	mockDB, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	ctx := context.WithValue(context.Background(), auth.CurrentUserKey,
+		auth.CurrentUser{UserName: "username", ID: 1, PrivLevel: auth.PrivLevelAdmin})
+	ctx = context.WithValue(ctx, PathParamsKey, map[string]string{})
+
+	typeRef := pagedTester{}
+	readFunc := ReadHandler(&typeRef, db)
+
+	tests := []struct {
+		query string
+		body  string
+	}{
+		//verifies the count of all results is read when there are more pages
+		{"limit=2&fields=ID", `{"response":[{"ID":1},{"ID":2}],"summary":{"count":5,"next":"/api/1.3/testers?cursor=` + EncodeCursor(2) + `\u0026fields=ID\u0026limit=2"},"links":{"next":"/api/1.3/testers?cursor=` + EncodeCursor(2) + `\u0026fields=ID\u0026limit=2"}}`},
+		//verifies the count of the last page is known from its offset
+		{"limit=2&offset=4&fields=ID", `{"response":[{"ID":5}],"summary":{"count":5}}`},
+		//verifies the count of all results is read when the page is past them
+		{"limit=2&offset=6&fields=ID", `{"response":[],"summary":{"count":5}}`},
+		//verifies unpaginated responses have no summary
+		{"fields=ID", `{"response":[{"ID":1},{"ID":2},{"ID":3},{"ID":4},{"ID":5}]}`},
+	}
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		r, err := http.NewRequest("", "/api/1.3/testers?"+test.query, nil)
+		if err != nil {
+			t.Error("Error creating new request")
+		}
+		r = r.WithContext(ctx)
+		readFunc(w, r)
+
+		if w.Body.String() != test.body {
+			t.Error("Expected body", test.body, "got", w.Body.String())
+		}
+	}
+}
+
+func TestReadHandlerNotModified(t *testing.T) {
+	mockDB, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	w := httptest.NewRecorder()
+	r, err := http.NewRequest("", "", nil)
+	if err != nil {
+		t.Error("Error creating new request")
+	}
+	r.Header.Set(IfNoneMatchHeader, ETag([]byte(`{"response":[{"ID":1}]}`)))
+
+	ctx := r.Context()
+	ctx = context.WithValue(ctx, auth.CurrentUserKey,
+		auth.CurrentUser{UserName: "username", ID: 1, PrivLevel: auth.PrivLevelAdmin})
+	ctx = context.WithValue(ctx, PathParamsKey, map[string]string{"id": "1"})
+	r = r.WithContext(ctx)
+
+	typeRef := tester{}
+	readFunc := ReadHandler(&typeRef, db)
+
+	readFunc(w, r)
+
+	//verifies the matching ETag returns no body and 304 Not Modified
+	if w.Body.String() != "" {
+		t.Error("Expected empty body, got", w.Body.String())
+	}
+	if status, _ := r.Context().Value(tc.StatusKey).(int); status != http.StatusNotModified {
+		t.Error("Expected status", http.StatusNotModified, "got", status)
+	}
+	if etag := w.Header().Get(ETagHeader); etag != r.Header.Get(IfNoneMatchHeader) {
+		t.Error("Expected ETag", r.Header.Get(IfNoneMatchHeader), "got", etag)
+	}
+}
+
+func TestUpdateHandlerPreconditionFailed(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	w := httptest.NewRecorder()
+	r, err := http.NewRequest("", "", strings.NewReader(`{"ID":1}`))
+	if err != nil {
+		t.Error("Error creating new request")
+	}
+	r.Header.Set(IfMatchHeader, `"stale"`)
+
+	ctx := r.Context()
+	ctx = context.WithValue(ctx, auth.CurrentUserKey,
+		auth.CurrentUser{UserName: "username", ID: 1, PrivLevel: auth.PrivLevelAdmin})
+	ctx = context.WithValue(ctx, PathParamsKey, map[string]string{"id": "1"})
+	r = r.WithContext(ctx)
+
+	typeRef := tester{}
+	updateFunc := UpdateHandler(&typeRef, db)
+
+	//verifies the update is rolled back, and no change log is written
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	updateFunc(w, r)
+
+	body := `{"alerts":[{"text":"the resource has been modified, its ETag does not match If-Match","level":"error"}]}`
+	if w.Body.String() != body {
+		t.Error("Expected body", body, "got", w.Body.String())
+	}
+	if status, _ := r.Context().Value(tc.StatusKey).(int); status != http.StatusPreconditionFailed {
+		t.Error("Expected status", http.StatusPreconditionFailed, "got", status)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expected the update to be rolled back: %v", err)
+	}
+}
+
+func TestUpdateHandlerPreconditionMet(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	w := httptest.NewRecorder()
+	r, err := http.NewRequest("", "", strings.NewReader(`{"ID":1}`))
+	if err != nil {
+		t.Error("Error creating new request")
+	}
+	r.Header.Set(IfMatchHeader, ETag([]byte(`{"response":[{"ID":1}]}`)))
+
+	ctx := r.Context()
+	ctx = context.WithValue(ctx, auth.CurrentUserKey,
+		auth.CurrentUser{UserName: "username", ID: 1, PrivLevel: auth.PrivLevelAdmin})
+	ctx = context.WithValue(ctx, PathParamsKey, map[string]string{"id": "1"})
+	r = r.WithContext(ctx)
+
+	typeRef := tester{}
+	updateFunc := UpdateHandler(&typeRef, db)
+
+	//verifies the update is committed in its transaction before the change log is written
+	expectedMessage := Updated + " tester: testerInstance:1 keys: { id:1 }"
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+	mock.ExpectExec("INSERT").WithArgs(ApiChange, expectedMessage, 1).WillReturnResult(sqlmock.NewResult(1, 1))
+
+	updateFunc(w, r)
+
+	body := `{"response":{"ID":1},"alerts":[{"text":"tester was updated.","level":"success"}]}`
+	if w.Body.String() != body {
+		t.Error("Expected body", body, "got", w.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expected the update to be committed: %v", err)
+	}
+}
+
+func TestDeleteHandlerPreconditionFailed(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	w := httptest.NewRecorder()
+	r, err := http.NewRequest("", "", nil)
+	if err != nil {
+		t.Error("Error creating new request")
+	}
+	r.Header.Set(IfMatchHeader, `"stale"`)
+
+	ctx := r.Context()
+	ctx = context.WithValue(ctx, auth.CurrentUserKey,
+		auth.CurrentUser{UserName: "username", ID: 1, PrivLevel: auth.PrivLevelAdmin})
+	ctx = context.WithValue(ctx, PathParamsKey, map[string]string{"id": "1"})
+	r = r.WithContext(ctx)
+
+	typeRef := tester{}
+	deleteFunc := DeleteHandler(&typeRef, db)
+
+	//verifies the delete is rolled back, and no change log is written
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	deleteFunc(w, r)
+
+	if status, _ := r.Context().Value(tc.StatusKey).(int); status != http.StatusPreconditionFailed {
+		t.Error("Expected status", http.StatusPreconditionFailed, "got", status)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expected the delete to be rolled back: %v", err)
+	}
+}
+
+// noTxTester is a Reader, Updater and Deleter which can't be written in a caller's transaction, so its writes can't be conditional.
+type noTxTester struct {
+	ID int
+}
+
+func (i noTxTester) GetKeyFieldsInfo() []KeyFieldInfo {
+	return []KeyFieldInfo{{"id", GetIntKey}}
+}
+
+func (i noTxTester) GetKeys() (map[string]interface{}, bool) {
+	return map[string]interface{}{"id": i.ID}, true
+}
+
+func (i *noTxTester) SetKeys(keys map[string]interface{}) {
+	i.ID, _ = keys["id"].(int)
+}
+
+func (i noTxTester) GetType() string {
+	return "noTxTester"
+}
+
+func (i noTxTester) GetAuditName() string {
+	return "noTxTesterInstance:" + strconv.Itoa(i.ID)
+}
+
+func (i noTxTester) Validate(db *sqlx.DB) []error {
+	return nil
+}
+
+func (i *noTxTester) Read(db *sqlx.DB, v map[string]string, user auth.CurrentUser) ([]interface{}, []error, tc.ApiErrorType) {
+	return []interface{}{noTxTester{ID: 1}}, nil, tc.NoError
+}
+
+func (i *noTxTester) Update(db *sqlx.DB, user auth.CurrentUser) (error, tc.ApiErrorType) {
+	return errors.New("conditional update must not be made unconditionally"), tc.SystemError
+}
+
+func (i *noTxTester) Delete(db *sqlx.DB, user auth.CurrentUser) (error, tc.ApiErrorType) {
+	return errors.New("conditional delete must not be made unconditionally"), tc.SystemError
+}
+
+func TestWriteHandlersPreconditionsUnsupported(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	handlers := map[string]http.HandlerFunc{
+		"update": UpdateHandler(&noTxTester{}, db),
+		"delete": DeleteHandler(&noTxTester{}, db),
+	}
+	for name, handler := range handlers {
+		for _, header := range []string{IfMatchHeader, IfUnmodifiedSinceHeader} {
+			w := httptest.NewRecorder()
+			r, err := http.NewRequest("", "", strings.NewReader(`{"ID":1}`))
+			if err != nil {
+				t.Error("Error creating new request")
+			}
+			r.Header.Set(header, ETag([]byte(`{"response":[{"ID":1}]}`)))
+			if header == IfUnmodifiedSinceHeader {
+				r.Header.Set(header, time.Now().UTC().Format(http.TimeFormat))
+			}
+
+			ctx := r.Context()
+			ctx = context.WithValue(ctx, auth.CurrentUserKey,
+				auth.CurrentUser{UserName: "username", ID: 1, PrivLevel: auth.PrivLevelAdmin})
+			ctx = context.WithValue(ctx, PathParamsKey, map[string]string{"id": "1"})
+			r = r.WithContext(ctx)
+
+			//verifies the write is neither made nor change logged
+			handler(w, r)
+
+			if status, _ := r.Context().Value(tc.StatusKey).(int); status != http.StatusPreconditionFailed {
+				t.Errorf("%s with %s expected status %v, got %v: %s", name, header, http.StatusPreconditionFailed, status, w.Body.String())
+			}
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expected no queries: %v", err)
+	}
+}
+
 func TestUpdateHandler(t *testing.T) {
 	mockDB, mock, err := sqlmock.New()
 	if err != nil {
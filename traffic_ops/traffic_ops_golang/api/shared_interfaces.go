@@ -62,3 +62,21 @@ type Tenantable interface {
 type Reader interface {
 	Read(db *sqlx.DB, parameters map[string]string, user auth.CurrentUser) ([]interface{}, []error, tc.ApiErrorType)
 }
+
+// BulkCreator is a Creator which can be created in a transaction owned by the caller, so many can be created atomically.
+type BulkCreator interface {
+	CreateTx(tx *sqlx.Tx, user auth.CurrentUser) (error, tc.ApiErrorType)
+	Creator
+}
+
+// BulkUpdater is an Updater which can be updated in a transaction owned by the caller, so many can be updated atomically.
+type BulkUpdater interface {
+	UpdateTx(tx *sqlx.Tx, user auth.CurrentUser) (error, tc.ApiErrorType)
+	Updater
+}
+
+// BulkDeleter is a Deleter which can be deleted in a transaction owned by the caller, so many can be deleted atomically.
+type BulkDeleter interface {
+	DeleteTx(tx *sqlx.Tx, user auth.CurrentUser) (error, tc.ApiErrorType)
+	Deleter
+}
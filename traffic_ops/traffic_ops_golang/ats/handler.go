@@ -0,0 +1,166 @@
+package ats
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-log"
+	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/api"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const ContentTypeTextPlain = "text/plain"
+
+// ServerConfigFunc generates the text of a config file for the given server.
+type ServerConfigFunc func(db *sql.DB, server ServerInfo) (string, error)
+
+// ServerConfigHandler returns a handler which serves the config file created by the given func, for the server in the 'id' path parameter. The server may be given as an ID or host name, like the Perl Traffic Ops configfiles routes.
+func ServerConfigHandler(db *sqlx.DB, makeConfig ServerConfigFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		handleErrs := tc.GetHandleErrorsFunc(w, r)
+		params, err := api.GetCombinedParams(r)
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, err)
+			return
+		}
+		idOrHost, ok := params["id"]
+		if !ok {
+			handleErrs(http.StatusInternalServerError, errors.New("params missing server id"))
+			return
+		}
+		server, ok, err := GetServerInfo(db.DB, idOrHost)
+		if err != nil {
+			log.Errorln("getting server info: " + err.Error())
+			handleErrs(http.StatusInternalServerError, tc.DBError)
+			return
+		}
+		if !ok {
+			handleErrs(http.StatusNotFound, errors.New("server not found"))
+			return
+		}
+		text, err := makeConfig(db.DB, server)
+		if err != nil {
+			log.Errorln("making config for server '" + server.HostName + "': " + err.Error())
+			handleErrs(http.StatusInternalServerError, tc.DBError)
+			return
+		}
+		w.Header().Set(tc.ContentType, ContentTypeTextPlain)
+		w.Write([]byte(text))
+	}
+}
+
+// CDNConfigFunc generates the text of a config file for the given CDN.
+type CDNConfigFunc func(db *sql.DB, cdn CDNInfo) (string, error)
+
+// CDNConfigHandler returns a handler which serves the config file created by the given func, for the CDN in the 'id' path parameter. The CDN may be given as an ID or name, like the Perl Traffic Ops configfiles routes.
+func CDNConfigHandler(db *sqlx.DB, makeConfig CDNConfigFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		handleErrs := tc.GetHandleErrorsFunc(w, r)
+		params, err := api.GetCombinedParams(r)
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, err)
+			return
+		}
+		idOrName, ok := params["id"]
+		if !ok {
+			handleErrs(http.StatusInternalServerError, errors.New("params missing cdn id"))
+			return
+		}
+		cdn, ok, err := GetCDNInfo(db.DB, idOrName)
+		if err != nil {
+			log.Errorln("getting cdn info: " + err.Error())
+			handleErrs(http.StatusInternalServerError, tc.DBError)
+			return
+		}
+		if !ok {
+			handleErrs(http.StatusNotFound, errors.New("cdn not found"))
+			return
+		}
+		text, err := makeConfig(db.DB, cdn)
+		if err != nil {
+			log.Errorln("making config for cdn '" + cdn.Name + "': " + err.Error())
+			handleErrs(http.StatusInternalServerError, tc.DBError)
+			return
+		}
+		w.Header().Set(tc.ContentType, ContentTypeTextPlain)
+		w.Write([]byte(text))
+	}
+}
+
+// RemapDotConfigHandler serves the remap.config for the server in the 'id' path parameter.
+func RemapDotConfigHandler(db *sqlx.DB) http.HandlerFunc {
+	return ServerConfigHandler(db, GetRemapDotConfig)
+}
+
+// GetRemapDotConfig fetches the data for, and returns the remap.config text of, the given server.
+func GetRemapDotConfig(db *sql.DB, server ServerInfo) (string, error) {
+	serverData, err := GetRemapConfigServerData(db, server)
+	if err != nil {
+		return "", errors.New("getting server data: " + err.Error())
+	}
+	dses, err := GetRemapDSData(db, server)
+	if err != nil {
+		return "", errors.New("getting delivery service data: " + err.Error())
+	}
+	nameVersionStr, err := GetNameVersionString(db)
+	if err != nil {
+		return "", errors.New("getting name version string: " + err.Error())
+	}
+	header := HeaderComment(server.HostName, nameVersionStr, time.Now())
+	return MakeRemapDotConfig(server, serverData, dses, header), nil
+}
+
+// ParentDotConfigHandler serves the parent.config for the server in the 'id' path parameter.
+func ParentDotConfigHandler(db *sqlx.DB) http.HandlerFunc {
+	return ServerConfigHandler(db, GetParentDotConfig)
+}
+
+// GetParentDotConfig fetches the data for, and returns the parent.config text of, the given server.
+func GetParentDotConfig(db *sql.DB, server ServerInfo) (string, error) {
+	serverData, err := GetParentConfigServerData(db, server)
+	if err != nil {
+		return "", errors.New("getting server data: " + err.Error())
+	}
+	dses, err := GetParentConfigDSData(db, server)
+	if err != nil {
+		return "", errors.New("getting delivery service data: " + err.Error())
+	}
+	parents, err := GetParents(db, server)
+	if err != nil {
+		return "", errors.New("getting parents: " + err.Error())
+	}
+	nameVersionStr, err := GetNameVersionString(db)
+	if err != nil {
+		return "", errors.New("getting name version string: " + err.Error())
+	}
+	header := HeaderComment(server.HostName, nameVersionStr, time.Now())
+	return MakeParentDotConfig(server, serverData, dses, parents, header), nil
+}
+
+// RegexRevalidateDotConfigHandler serves the regex_revalidate.config for the CDN in the 'id' path parameter.
+func RegexRevalidateDotConfigHandler(db *sqlx.DB) http.HandlerFunc {
+	return CDNConfigHandler(db, GetRegexRevalidateDotConfig)
+}
@@ -0,0 +1,586 @@
+package ats
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"errors"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-log"
+
+	"github.com/lib/pq"
+)
+
+const ParentConfigFile = "parent.config"
+
+// AllParentsKey is the key in the parents map for parent caches, as opposed to origins, which are keyed on the origin host.
+const AllParentsKey = "all_parents"
+
+const ParentConfigParamQStringHandling = "psel.qstring_handling"
+const ParentConfigParamMSOAlgorithm = "mso.algorithm"
+const ParentConfigParamMSOParentRetry = "mso.parent_retry"
+const ParentConfigParamUnavailableServerRetryResponses = "mso.unavailable_server_retry_responses"
+const ParentConfigParamMaxSimpleRetries = "mso.max_simple_retries"
+const ParentConfigParamMaxUnavailableServerRetries = "mso.max_unavailable_server_retries"
+
+const ParentConfigParamAlgorithm = "algorithm"
+const ParentConfigParamQString = "qstring"
+const ParentConfigParamWeight = "weight"
+const ParentConfigParamPort = "port"
+const ParentConfigParamUseIP = "use_ip_address"
+const ParentConfigParamRank = "rank"
+const ParentConfigParamNotAParent = "not_a_parent"
+
+const ParentConfigDefaultWeight = "0.999"
+const ParentConfigDefaultMSOAlgorithm = "consistent_hash"
+const ParentConfigDefaultMSOParentRetry = "both"
+const ParentConfigDefaultMaxSimpleRetries = "1"
+const ParentConfigDefaultMaxUnavailableServerRetries = "1"
+
+const OriginServerType = "ORG"
+const OriginCachegroupType = "ORG_LOC"
+
+var unavailableServerRetryResponsesRegex = regexp.MustCompile(`^"(?:\d{3},)+\d{3}"\s*$`)
+
+// ParentConfigDSData is the delivery service data needed to build parent.config.
+type ParentConfigDSData struct {
+	XMLID           string
+	Type            string
+	OriginFQDN      *string
+	QStringIgnore   int
+	OriginShield    *string
+	MultiSiteOrigin bool
+	// Params is the delivery service profile's parent.config parameters, as a map of names to values.
+	Params map[string]string
+}
+
+// ParentConfigServerData is the server-wide data needed to build parent.config, beyond the server info, delivery services, and parents.
+type ParentConfigServerData struct {
+	ATSMajorVersion int
+	// Params is the server profile's parent.config parameters, as a map of names to values.
+	Params map[string]string
+}
+
+// ParentInfo is a parent cache or origin of a server, with the parent.config parameters of its profile.
+type ParentInfo struct {
+	HostName        string
+	DomainName      string
+	IP              string
+	Port            int
+	Weight          string
+	UseIP           bool
+	Rank            int
+	PrimaryParent   bool
+	SecondaryParent bool
+}
+
+// Format returns the parent text used in parent.config parent lists, e.g. "host.example.net:80|0.999;".
+func (p ParentInfo) Format() string {
+	host := p.HostName + "." + p.DomainName
+	if p.UseIP {
+		host = p.IP
+	}
+	return host + ":" + strconv.Itoa(p.Port) + "|" + p.Weight + ";"
+}
+
+// DSTypeSkipsMid returns whether edges go directly to the origin for the given delivery service type, rather than through a parent.
+func DSTypeSkipsMid(dsType string) bool {
+	return dsType == "HTTP_NO_CACHE" || dsType == "HTTP_LIVE" || dsType == "DNS_LIVE"
+}
+
+// MakeParentDotConfig returns the parent.config text for the given server. The parents map is keyed on AllParentsKey for parent caches, and the origin host for multi-site origins. The header is the "DO NOT EDIT" comment, typically created with HeaderComment.
+func MakeParentDotConfig(server ServerInfo, serverData ParentConfigServerData, dses []ParentConfigDSData, parents map[string][]ParentInfo, header string) string {
+	if server.IsMid() {
+		return header + getMidParentText(serverData, dses, parents)
+	}
+	return header + getEdgeParentText(serverData, dses, parents)
+}
+
+// getMidParentText returns the parent.config lines for a mid. Mids only get lines for origin shield and multi-site origin delivery services; everything else implicitly goes direct.
+func getMidParentText(serverData ParentConfigServerData, dses []ParentConfigDSData, parents map[string][]ParentInfo) string {
+	texts := []string{}
+	seenOrigins := map[string]struct{}{}
+	for _, ds := range dses {
+		if ds.OriginFQDN == nil {
+			continue
+		}
+		if _, ok := seenOrigins[*ds.OriginFQDN]; ok {
+			continue // don't duplicate origin line if multiple seen
+		}
+		seenOrigins[*ds.OriginFQDN] = struct{}{}
+
+		orgHost, orgPort := getOriginHostPort(*ds.OriginFQDN)
+
+		if ds.OriginShield != nil {
+			algorithm := ""
+			if alg, ok := serverData.Params[ParentConfigParamAlgorithm]; ok {
+				algorithm = "round_robin=" + alg
+			}
+			texts = append(texts, "dest_domain="+orgHost+" port="+orgPort+" parent="+*ds.OriginShield+" "+algorithm+" go_direct=true\n")
+			continue
+		}
+		if !ds.MultiSiteOrigin {
+			continue
+		}
+
+		msoAlgorithm := paramOrDefault(ds.Params, ParentConfigParamMSOAlgorithm, ParentConfigDefaultMSOAlgorithm)
+		parentRetry := paramOrDefault(ds.Params, ParentConfigParamMSOParentRetry, ParentConfigDefaultMSOParentRetry)
+		unavailableServerRetryResponses := paramOrDefault(ds.Params, ParentConfigParamUnavailableServerRetryResponses, "")
+		maxSimpleRetries := paramOrDefault(ds.Params, ParentConfigParamMaxSimpleRetries, ParentConfigDefaultMaxSimpleRetries)
+		maxUnavailableServerRetries := paramOrDefault(ds.Params, ParentConfigParamMaxUnavailableServerRetries, ParentConfigDefaultMaxUnavailableServerRetries)
+
+		parentQString := "ignore" // default is ignore, unless for alg consistent_hash
+		if _, ok := ds.Params[ParentConfigParamQStringHandling]; !ok && msoAlgorithm == ParentConfigDefaultMSOAlgorithm && ds.QStringIgnore == 0 {
+			parentQString = "consider"
+		}
+
+		rankedParents, ok := parents[orgHost]
+		if !ok {
+			log.Warnln("BUG: Did not match a multi-site origin: " + *ds.OriginFQDN)
+		}
+		rankedParents = append([]ParentInfo(nil), rankedParents...)
+		sort.SliceStable(rankedParents, func(i, j int) bool { return rankOrDefault(rankedParents[i]) < rankOrDefault(rankedParents[j]) })
+
+		primaryParents, secondaryParents, nullParents := splitParents(rankedParents, true)
+
+		parentsStr := ""
+		if serverData.ATSMajorVersion >= 6 && msoAlgorithm == ParentConfigDefaultMSOAlgorithm && (len(secondaryParents) > 0 || len(nullParents) > 0) {
+			parentsStr = `parent="` + strings.Join(primaryParents, "") + `" secondary_parent="` + strings.Join(secondaryParents, "") + strings.Join(nullParents, "") + `"`
+		} else {
+			parentsStr = `parent="` + strings.Join(primaryParents, "") + strings.Join(secondaryParents, "") + strings.Join(nullParents, "") + `"`
+		}
+
+		text := "dest_domain=" + orgHost + " port=" + orgPort + " " + parentsStr + " round_robin=" + msoAlgorithm + " qstring=" + parentQString + " go_direct=false parent_is_proxy=false"
+		if serverData.ATSMajorVersion >= 6 && parentRetry != "" {
+			if unavailableServerRetryResponses != "" && unavailableServerRetryResponsesRegex.MatchString(unavailableServerRetryResponses) {
+				text += " parent_retry=" + parentRetry + " unavailable_server_retry_responses=" + unavailableServerRetryResponses
+			} else {
+				text += " parent_retry=" + parentRetry
+			}
+			text += " max_simple_retries=" + maxSimpleRetries + " max_unavailable_server_retries=" + maxUnavailableServerRetries
+		}
+		texts = append(texts, text+"\n")
+	}
+	sort.Strings(texts)
+	return strings.Join(texts, "")
+}
+
+// getEdgeParentText returns the parent.config lines for an edge. Edges get a line per origin, going to the cachegroup parents unless the delivery service type skips mids, and a default line for everything else.
+func getEdgeParentText(serverData ParentConfigServerData, dses []ParentConfigDSData, parents map[string][]ParentInfo) string {
+	primaryParents, secondaryParents, _ := splitParents(parents[AllParentsKey], false)
+	sort.Strings(primaryParents)
+	sort.Strings(secondaryParents)
+
+	parentsStr := ""
+	secondaryParentsStr := ""
+	if serverData.ATSMajorVersion >= 6 && len(secondaryParents) > 0 {
+		parentsStr = `parent="` + strings.Join(primaryParents, "") + `"`
+		secondaryParentsStr = ` secondary_parent="` + strings.Join(secondaryParents, "") + `"`
+	} else {
+		parentsStr = `parent="` + strings.Join(primaryParents, "") + strings.Join(secondaryParents, "") + `"`
+	}
+
+	serverQStringHandling, hasServerQStringHandling := serverData.Params[ParentConfigParamQStringHandling]
+
+	texts := []string{}
+	doneOrigins := map[string]struct{}{}
+	for _, ds := range dses {
+		if ds.OriginFQDN == nil || *ds.OriginFQDN == "" {
+			continue
+		}
+		if _, ok := doneOrigins[*ds.OriginFQDN]; ok {
+			continue
+		}
+		doneOrigins[*ds.OriginFQDN] = struct{}{}
+
+		orgHost, orgPort := getOriginHostPort(*ds.OriginFQDN)
+		if DSTypeSkipsMid(ds.Type) {
+			texts = append(texts, "dest_domain="+orgHost+" port="+orgPort+" go_direct=true\n")
+			continue
+		}
+
+		// A server profile psel.qstring_handling applies to all delivery services, otherwise a delivery service profile psel.qstring_handling applies to that delivery service.
+		qStringHandling, hasQStringHandling := serverQStringHandling, hasServerQStringHandling
+		if !hasQStringHandling {
+			qStringHandling, hasQStringHandling = ds.Params[ParentConfigParamQStringHandling]
+		}
+		parentQString := "ignore"
+		if hasQStringHandling {
+			parentQString = qStringHandling
+		} else if ds.QStringIgnore == 0 {
+			parentQString = "consider"
+		}
+		texts = append(texts, "dest_domain="+orgHost+" port="+orgPort+" "+parentsStr+" "+secondaryParentsStr+" round_robin=consistent_hash go_direct=false qstring="+parentQString+"\n")
+	}
+	sort.Strings(texts)
+
+	defaultDestText := "dest_domain=. "
+	if alg, ok := serverData.Params[ParentConfigParamAlgorithm]; ok && alg == ParentConfigDefaultMSOAlgorithm {
+		defaultDestText += parentsStr + secondaryParentsStr + " round_robin=consistent_hash go_direct=false"
+	} else { // default to old situation.
+		defaultDestText += parentsStr + " round_robin=urlhash go_direct=false"
+	}
+	if qstring, ok := serverData.Params[ParentConfigParamQString]; ok {
+		defaultDestText += " qstring=" + qstring
+	}
+	defaultDestText += "\n"
+
+	return strings.Join(texts, "") + defaultDestText
+}
+
+// splitParents returns the formatted primary, secondary, and null (neither primary nor secondary) parents, with duplicates removed. If there are no primary parents, the secondary parents become the primary. If useNullParents and there are no secondary parents either, the null parents become the primary. If !useNullParents, null parents are omitted.
+func splitParents(parents []ParentInfo, useNullParents bool) ([]string, []string, []string) {
+	primaryParents := []string{}
+	secondaryParents := []string{}
+	nullParents := []string{}
+	for _, parent := range parents {
+		switch {
+		case parent.PrimaryParent:
+			primaryParents = append(primaryParents, parent.Format())
+		case parent.SecondaryParent:
+			secondaryParents = append(secondaryParents, parent.Format())
+		case useNullParents:
+			nullParents = append(nullParents, parent.Format())
+		}
+	}
+
+	// If there are no primary parents, use the secondary parents as primary. If there are no secondary parents either, use the null parents, to prevent blank parent entries.
+	if len(primaryParents) == 0 {
+		if len(secondaryParents) == 0 {
+			secondaryParents = nullParents
+			nullParents = []string{}
+		}
+		primaryParents = secondaryParents
+		secondaryParents = []string{}
+	}
+	return uniqueStrs(primaryParents), uniqueStrs(secondaryParents), uniqueStrs(nullParents)
+}
+
+// uniqueStrs returns strs with duplicates removed, preserving the order of first occurrence.
+func uniqueStrs(strs []string) []string {
+	seen := map[string]struct{}{}
+	unique := []string{}
+	for _, s := range strs {
+		if _, ok := seen[s]; ok {
+			continue
+		}
+		seen[s] = struct{}{}
+		unique = append(unique, s)
+	}
+	return unique
+}
+
+// rankOrDefault returns the parent's rank, or 1 if it has none, matching the Perl Traffic Ops parent sort.
+func rankOrDefault(p ParentInfo) int {
+	if p.Rank == 0 {
+		return 1
+	}
+	return p.Rank
+}
+
+// paramOrDefault returns the given parameter, or the default if it doesn't exist or is a false value (empty or "0"), matching the Perl Traffic Ops.
+func paramOrDefault(params map[string]string, name string, defaultVal string) string {
+	if val := params[name]; val != "" && val != "0" {
+		return val
+	}
+	return defaultVal
+}
+
+// getOriginHostPort returns the host and port of the given origin URI. If the URI has no port, the scheme default is returned.
+func getOriginHostPort(origin string) (string, string) {
+	u, err := url.Parse(origin)
+	if err != nil {
+		return "", ""
+	}
+	port := u.Port()
+	if port == "" {
+		switch strings.ToLower(u.Scheme) {
+		case "http":
+			port = "80"
+		case "https":
+			port = "443"
+		}
+	}
+	return u.Hostname(), port
+}
+
+// GetParentConfigServerData returns the server-wide data needed to build parent.config for the given server.
+func GetParentConfigServerData(db *sql.DB, server ServerInfo) (ParentConfigServerData, error) {
+	atsMajorVersion, err := GetATSMajorVersion(db, server.ProfileID)
+	if err != nil {
+		return ParentConfigServerData{}, errors.New("getting ATS major version: " + err.Error())
+	}
+	params, err := GetProfileParamData(db, server.ProfileID, ParentConfigFile)
+	if err != nil {
+		return ParentConfigServerData{}, errors.New("getting parent.config parameters: " + err.Error())
+	}
+	return ParentConfigServerData{ATSMajorVersion: atsMajorVersion, Params: params}, nil
+}
+
+// GetParentConfigDSData returns the delivery service data for building the given server's parent.config. Mids get every delivery service in their CDN which is assigned to any server, edges get the delivery services assigned to them.
+func GetParentConfigDSData(db *sql.DB, server ServerInfo) ([]ParentConfigDSData, error) {
+	qry := `
+SELECT
+  ds.xml_id,
+  dstype.name AS ds_type,
+  ds.org_server_fqdn,
+  COALESCE(ds.qstring_ignore, 0),
+  ds.origin_shield,
+  COALESCE(ds.multi_site_origin, false),
+  ds.profile
+FROM deliveryservice AS ds
+JOIN type AS dstype ON ds.type = dstype.id
+JOIN cdn ON cdn.id = ds.cdn_id
+WHERE EXISTS (SELECT dsr.deliveryservice FROM deliveryservice_regex AS dsr WHERE dsr.deliveryservice = ds.id)
+`
+	where := `AND ds.id IN (SELECT dss.deliveryservice FROM deliveryservice_server AS dss WHERE dss.server = $1)
+`
+	arg := interface{}(server.ID)
+	if server.IsMid() {
+		where = `AND cdn.name = $1 AND ds.id IN (SELECT dss.deliveryservice FROM deliveryservice_server AS dss)
+`
+		arg = server.CDN
+	}
+	orderBy := `ORDER BY ds.id
+`
+	rows, err := db.Query(qry+where+orderBy, arg)
+	if err != nil {
+		return nil, errors.New("querying delivery services: " + err.Error())
+	}
+	defer rows.Close()
+
+	dses := []ParentConfigDSData{}
+	dsProfiles := map[int]int{} // map[dsIndex]profileID
+	for rows.Next() {
+		ds := ParentConfigDSData{}
+		orgFQDN := sql.NullString{}
+		originShield := sql.NullString{}
+		profileID := sql.NullInt64{}
+		if err := rows.Scan(&ds.XMLID, &ds.Type, &orgFQDN, &ds.QStringIgnore, &originShield, &ds.MultiSiteOrigin, &profileID); err != nil {
+			return nil, errors.New("scanning delivery services: " + err.Error())
+		}
+		ds.OriginFQDN = nullStrPtr(orgFQDN)
+		ds.OriginShield = nullStrPtr(originShield)
+		if profileID.Valid {
+			dsProfiles[len(dses)] = int(profileID.Int64)
+		}
+		dses = append(dses, ds)
+	}
+
+	profileIDs := []int64{}
+	for _, profileID := range dsProfiles {
+		profileIDs = append(profileIDs, int64(profileID))
+	}
+	profileParams, err := getProfilesConfigParams(db, profileIDs, ParentConfigFile)
+	if err != nil {
+		return nil, errors.New("getting delivery service parent.config parameters: " + err.Error())
+	}
+	for i, profileID := range dsProfiles {
+		dses[i].Params = profileParams[profileID]
+	}
+	return dses, nil
+}
+
+// GetParents returns the parents of the given server, keyed on AllParentsKey for parent caches, and the origin host for origins. Mids use every origin cachegroup, edges use their cachegroup's primary and secondary parents.
+func GetParents(db *sql.DB, server ServerInfo) (map[string][]ParentInfo, error) {
+	primaryParentCG := sql.NullInt64{}
+	secondaryParentCG := sql.NullInt64{}
+	if err := db.QueryRow(`SELECT parent_cachegroup_id, secondary_parent_cachegroup_id FROM cachegroup WHERE id = $1`, server.CachegroupID).Scan(&primaryParentCG, &secondaryParentCG); err != nil && err != sql.ErrNoRows {
+		return nil, errors.New("querying server cachegroup parents: " + err.Error())
+	}
+
+	parentCGs := []int64{}
+	if server.IsMid() {
+		// multi-site origins take all the origin groups into account
+		rows, err := db.Query(`SELECT cg.id FROM cachegroup AS cg JOIN type AS t ON cg.type = t.id WHERE t.name = $1`, OriginCachegroupType)
+		if err != nil {
+			return nil, errors.New("querying origin cachegroups: " + err.Error())
+		}
+		defer rows.Close()
+		for rows.Next() {
+			id := int64(0)
+			if err := rows.Scan(&id); err != nil {
+				return nil, errors.New("scanning origin cachegroups: " + err.Error())
+			}
+			parentCGs = append(parentCGs, id)
+		}
+	} else {
+		if primaryParentCG.Valid {
+			parentCGs = append(parentCGs, primaryParentCG.Int64)
+		}
+		if secondaryParentCG.Valid {
+			parentCGs = append(parentCGs, secondaryParentCG.Int64)
+		}
+	}
+
+	serverDomain := sql.NullString{}
+	if err := db.QueryRow(`SELECT c.domain_name FROM profile AS p JOIN cdn AS c ON p.cdn = c.id WHERE p.id = $1`, server.ProfileID).Scan(&serverDomain); err != nil && err != sql.ErrNoRows {
+		return nil, errors.New("querying server profile CDN domain: " + err.Error())
+	}
+
+	parents := map[string][]ParentInfo{}
+	if len(parentCGs) == 0 || !serverDomain.Valid {
+		return parents, nil
+	}
+
+	qry := `
+SELECT
+  s.id,
+  s.host_name,
+  s.domain_name,
+  s.ip_address,
+  COALESCE(s.tcp_port, 0),
+  s.cachegroup,
+  t.name AS type,
+  s.profile,
+  cdn.domain_name AS cdn_domain
+FROM server AS s
+JOIN type AS t ON s.type = t.id
+JOIN status AS st ON s.status = st.id
+JOIN cdn ON s.cdn_id = cdn.id
+WHERE s.cachegroup = ANY($1)
+AND st.name IN ('ONLINE', 'REPORTED')
+AND (t.name = $2 OR t.name LIKE 'EDGE%' OR t.name LIKE 'MID%')
+ORDER BY s.id
+`
+	rows, err := db.Query(qry, pq.Array(parentCGs), OriginServerType)
+	if err != nil {
+		return nil, errors.New("querying parent servers: " + err.Error())
+	}
+	defer rows.Close()
+
+	type parentServer struct {
+		ID         int
+		HostName   string
+		DomainName string
+		IP         string
+		Port       int
+		Cachegroup int
+		Type       string
+		ProfileID  int
+		CDNDomain  string
+	}
+	servers := []parentServer{}
+	profileIDs := []int64{}
+	originServerIDs := []int64{}
+	for rows.Next() {
+		s := parentServer{}
+		if err := rows.Scan(&s.ID, &s.HostName, &s.DomainName, &s.IP, &s.Port, &s.Cachegroup, &s.Type, &s.ProfileID, &s.CDNDomain); err != nil {
+			return nil, errors.New("scanning parent servers: " + err.Error())
+		}
+		servers = append(servers, s)
+		profileIDs = append(profileIDs, int64(s.ProfileID))
+		if s.Type == OriginServerType {
+			originServerIDs = append(originServerIDs, int64(s.ID))
+		}
+	}
+
+	profileParams, err := getProfilesConfigParams(db, profileIDs, ParentConfigFile)
+	if err != nil {
+		return nil, errors.New("getting parent profile parameters: " + err.Error())
+	}
+	originHosts, err := getOriginServerHosts(db, originServerIDs)
+	if err != nil {
+		return nil, errors.New("getting origin server delivery services: " + err.Error())
+	}
+
+	for _, s := range servers {
+		params := profileParams[s.ProfileID]
+		if notAParent, ok := params[ParentConfigParamNotAParent]; ok && notAParent != "false" {
+			continue
+		}
+		if s.CDNDomain != serverDomain.String {
+			continue
+		}
+		parent := ParentInfo{
+			HostName:        s.HostName,
+			DomainName:      s.DomainName,
+			IP:              s.IP,
+			Port:            s.Port,
+			Weight:          ParentConfigDefaultWeight,
+			Rank:            1,
+			PrimaryParent:   primaryParentCG.Valid && int(primaryParentCG.Int64) == s.Cachegroup,
+			SecondaryParent: secondaryParentCG.Valid && int(secondaryParentCG.Int64) == s.Cachegroup,
+		}
+		if weight, ok := params[ParentConfigParamWeight]; ok {
+			parent.Weight = weight
+		}
+		if portStr, ok := params[ParentConfigParamPort]; ok {
+			port, err := strconv.Atoi(portStr)
+			if err != nil {
+				log.Warnln("parent server '" + s.HostName + "' profile has invalid parent.config port '" + portStr + "', using server port")
+			} else {
+				parent.Port = port
+			}
+		}
+		if useIP, ok := params[ParentConfigParamUseIP]; ok {
+			parent.UseIP = useIP == "1"
+		}
+		if rankStr, ok := params[ParentConfigParamRank]; ok {
+			parent.Rank, _ = strconv.Atoi(rankStr) // an invalid rank is 0, which sorts as the default, like Perl
+		}
+
+		if s.Type != OriginServerType {
+			parents[AllParentsKey] = append(parents[AllParentsKey], parent)
+			continue
+		}
+		for _, host := range originHosts[s.ID] {
+			parents[host] = append(parents[host], parent)
+		}
+	}
+	return parents, nil
+}
+
+// getOriginServerHosts returns the origin hosts of the delivery services assigned to the given origin servers, as a map of server IDs to hosts. A host is included once per assigned delivery service.
+func getOriginServerHosts(db *sql.DB, serverIDs []int64) (map[int][]string, error) {
+	hosts := map[int][]string{}
+	if len(serverIDs) == 0 {
+		return hosts, nil
+	}
+	qry := `
+SELECT
+  dss.server,
+  ds.org_server_fqdn
+FROM deliveryservice_server AS dss
+JOIN deliveryservice AS ds ON ds.id = dss.deliveryservice
+WHERE dss.server = ANY($1)
+ORDER BY dss.server, ds.id
+`
+	rows, err := db.Query(qry, pq.Array(serverIDs))
+	if err != nil {
+		return nil, errors.New("querying: " + err.Error())
+	}
+	defer rows.Close()
+	for rows.Next() {
+		serverID := 0
+		orgFQDN := sql.NullString{}
+		if err := rows.Scan(&serverID, &orgFQDN); err != nil {
+			return nil, errors.New("scanning: " + err.Error())
+		}
+		host, _ := getOriginHostPort(orgFQDN.String)
+		hosts[serverID] = append(hosts[serverID], host)
+	}
+	return hosts, nil
+}
@@ -0,0 +1,193 @@
+package ats
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"strings"
+	"testing"
+)
+
+func testParent(host string, primary bool, secondary bool) ParentInfo {
+	return ParentInfo{
+		HostName:        host,
+		DomainName:      "example.net",
+		IP:              "192.0.2.1",
+		Port:            80,
+		Weight:          ParentConfigDefaultWeight,
+		Rank:            1,
+		PrimaryParent:   primary,
+		SecondaryParent: secondary,
+	}
+}
+
+func TestMakeParentDotConfig(t *testing.T) {
+	mid1 := testParent("mid1", true, false)
+	mid2 := testParent("mid2", true, false)
+	mid2.Port = 8080
+	mid2.Weight = "0.5"
+	secMid := testParent("secmid", false, true)
+	secMidIP := testParent("secmidip", false, true)
+	secMidIP.UseIP = true
+
+	httpDS := ParentConfigDSData{XMLID: "http-ds", Type: "HTTP", OriginFQDN: strPtr("http://origin.http.example.net")}
+	httpQStringDS := ParentConfigDSData{XMLID: "http-qstring-ds", Type: "HTTP", OriginFQDN: strPtr("http://origin.qstring.example.net:8080"), QStringIgnore: QStringIgnoreIgnoreInCacheKeyAndPassUp}
+	httpQStringParamDS := ParentConfigDSData{XMLID: "http-qstring-param-ds", Type: "DNS", OriginFQDN: strPtr("https://origin.qstringparam.example.net"), Params: map[string]string{ParentConfigParamQStringHandling: "consider"}}
+	noCacheDS := ParentConfigDSData{XMLID: "no-cache-ds", Type: "HTTP_NO_CACHE", OriginFQDN: strPtr("http://origin.nocache.example.net")}
+	liveDS := ParentConfigDSData{XMLID: "live-ds", Type: "DNS_LIVE", OriginFQDN: strPtr("http://origin.live.example.net")}
+	liveNatnlDS := ParentConfigDSData{XMLID: "live-natnl-ds", Type: "HTTP_LIVE_NATNL", OriginFQDN: strPtr("http://origin.livenatnl.example.net")}
+	dupOriginDS := ParentConfigDSData{XMLID: "dup-origin-ds", Type: "HTTP_NO_CACHE", OriginFQDN: strPtr("http://origin.http.example.net")}
+	noOriginDS := ParentConfigDSData{XMLID: "no-origin-ds", Type: "HTTP"}
+
+	edge := testEdge()
+	mid := testMid()
+
+	org1 := testParent("org1", false, false)
+	org2 := testParent("org2", false, false)
+	org2.Rank = 2
+	org3 := testParent("org3", false, false)
+	org3.Rank = 0
+	orgPrimary := testParent("orgprimary", true, false)
+	orgPrimary.Rank = 5
+
+	msoDS := ParentConfigDSData{XMLID: "mso-ds", Type: "HTTP", OriginFQDN: strPtr("http://mso.example.net"), MultiSiteOrigin: true}
+	msoRetryDS := ParentConfigDSData{XMLID: "mso-retry-ds", Type: "HTTP", OriginFQDN: strPtr("http://mso.example.net:81"), MultiSiteOrigin: true, QStringIgnore: QStringIgnoreIgnoreInCacheKeyAndPassUp, Params: map[string]string{
+		ParentConfigParamMSOAlgorithm:                    "true",
+		ParentConfigParamMSOParentRetry:                  "unavailable_server_retry",
+		ParentConfigParamUnavailableServerRetryResponses: `"502,503"`,
+		ParentConfigParamMaxSimpleRetries:                "2",
+		ParentConfigParamMaxUnavailableServerRetries:     "0",
+	}}
+	shieldDS := ParentConfigDSData{XMLID: "shield-ds", Type: "HTTP", OriginFQDN: strPtr("http://shield.example.net"), OriginShield: strPtr("shield1.example.net:80|0.5;")}
+
+	type testCase struct {
+		name       string
+		server     ServerInfo
+		serverData ParentConfigServerData
+		dses       []ParentConfigDSData
+		parents    map[string][]ParentInfo
+		expected   []string
+	}
+
+	tests := []testCase{
+		{
+			name:       "edge with primary and secondary parents on ATS 7",
+			server:     edge,
+			serverData: ParentConfigServerData{ATSMajorVersion: 7},
+			dses:       []ParentConfigDSData{httpDS, httpQStringDS, httpQStringParamDS, noCacheDS, liveDS, liveNatnlDS, dupOriginDS, noOriginDS},
+			parents:    map[string][]ParentInfo{AllParentsKey: {mid2, secMidIP, mid1, secMid, mid1}},
+			expected: []string{
+				`dest_domain=origin.http.example.net port=80 parent="mid1.example.net:80|0.999;mid2.example.net:8080|0.5;"  secondary_parent="192.0.2.1:80|0.999;secmid.example.net:80|0.999;" round_robin=consistent_hash go_direct=false qstring=consider`,
+				`dest_domain=origin.live.example.net port=80 go_direct=true`,
+				`dest_domain=origin.livenatnl.example.net port=80 parent="mid1.example.net:80|0.999;mid2.example.net:8080|0.5;"  secondary_parent="192.0.2.1:80|0.999;secmid.example.net:80|0.999;" round_robin=consistent_hash go_direct=false qstring=consider`,
+				`dest_domain=origin.nocache.example.net port=80 go_direct=true`,
+				`dest_domain=origin.qstring.example.net port=8080 parent="mid1.example.net:80|0.999;mid2.example.net:8080|0.5;"  secondary_parent="192.0.2.1:80|0.999;secmid.example.net:80|0.999;" round_robin=consistent_hash go_direct=false qstring=ignore`,
+				`dest_domain=origin.qstringparam.example.net port=443 parent="mid1.example.net:80|0.999;mid2.example.net:8080|0.5;"  secondary_parent="192.0.2.1:80|0.999;secmid.example.net:80|0.999;" round_robin=consistent_hash go_direct=false qstring=consider`,
+				`dest_domain=. parent="mid1.example.net:80|0.999;mid2.example.net:8080|0.5;" round_robin=urlhash go_direct=false`,
+			},
+		},
+		{
+			name:       "edge with secondary parents on ATS 5, server qstring handling, consistent hash, and qstring param",
+			server:     edge,
+			serverData: ParentConfigServerData{ATSMajorVersion: 5, Params: map[string]string{ParentConfigParamQStringHandling: "ignore", ParentConfigParamAlgorithm: "consistent_hash", ParentConfigParamQString: "consider"}},
+			dses:       []ParentConfigDSData{httpDS, httpQStringParamDS},
+			parents:    map[string][]ParentInfo{AllParentsKey: {mid1, secMid}},
+			expected: []string{
+				`dest_domain=origin.http.example.net port=80 parent="mid1.example.net:80|0.999;secmid.example.net:80|0.999;"  round_robin=consistent_hash go_direct=false qstring=ignore`,
+				`dest_domain=origin.qstringparam.example.net port=443 parent="mid1.example.net:80|0.999;secmid.example.net:80|0.999;"  round_robin=consistent_hash go_direct=false qstring=ignore`,
+				`dest_domain=. parent="mid1.example.net:80|0.999;secmid.example.net:80|0.999;" round_robin=consistent_hash go_direct=false qstring=consider`,
+			},
+		},
+		{
+			name:       "edge with only secondary parents",
+			server:     edge,
+			serverData: ParentConfigServerData{ATSMajorVersion: 7},
+			dses:       []ParentConfigDSData{httpDS},
+			parents:    map[string][]ParentInfo{AllParentsKey: {secMid, testParent("neither", false, false)}},
+			expected: []string{
+				`dest_domain=origin.http.example.net port=80 parent="secmid.example.net:80|0.999;"  round_robin=consistent_hash go_direct=false qstring=consider`,
+				`dest_domain=. parent="secmid.example.net:80|0.999;" round_robin=urlhash go_direct=false`,
+			},
+		},
+		{
+			name:       "edge with no parents",
+			server:     edge,
+			serverData: ParentConfigServerData{ATSMajorVersion: 7},
+			dses:       nil,
+			parents:    map[string][]ParentInfo{},
+			expected: []string{
+				`dest_domain=. parent="" round_robin=urlhash go_direct=false`,
+			},
+		},
+		{
+			name:       "mid with multi-site origin and origin shield on ATS 7",
+			server:     mid,
+			serverData: ParentConfigServerData{ATSMajorVersion: 7, Params: map[string]string{ParentConfigParamAlgorithm: "consistent_hash"}},
+			dses:       []ParentConfigDSData{httpDS, msoDS, msoRetryDS, shieldDS, shieldDS},
+			parents:    map[string][]ParentInfo{"mso.example.net": {org2, org1, orgPrimary, org3, org1}},
+			expected: []string{
+				`dest_domain=mso.example.net port=80 parent="orgprimary.example.net:80|0.999;" secondary_parent="org1.example.net:80|0.999;org3.example.net:80|0.999;org2.example.net:80|0.999;" round_robin=consistent_hash qstring=consider go_direct=false parent_is_proxy=false parent_retry=both max_simple_retries=1 max_unavailable_server_retries=1`,
+				`dest_domain=mso.example.net port=81 parent="orgprimary.example.net:80|0.999;org1.example.net:80|0.999;org3.example.net:80|0.999;org2.example.net:80|0.999;" round_robin=true qstring=ignore go_direct=false parent_is_proxy=false parent_retry=unavailable_server_retry unavailable_server_retry_responses="502,503" max_simple_retries=2 max_unavailable_server_retries=1`,
+				`dest_domain=shield.example.net port=80 parent=shield1.example.net:80|0.5; round_robin=consistent_hash go_direct=true`,
+			},
+		},
+		{
+			name:       "mid with multi-site origin on ATS 5 and no parent algorithm",
+			server:     mid,
+			serverData: ParentConfigServerData{ATSMajorVersion: 5},
+			dses:       []ParentConfigDSData{msoDS, shieldDS},
+			parents:    map[string][]ParentInfo{"mso.example.net": {org1, org2}},
+			expected: []string{
+				`dest_domain=mso.example.net port=80 parent="org1.example.net:80|0.999;org2.example.net:80|0.999;" round_robin=consistent_hash qstring=consider go_direct=false parent_is_proxy=false`,
+				`dest_domain=shield.example.net port=80 parent=shield1.example.net:80|0.5;  go_direct=true`,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		expected := testHeader + strings.Join(test.expected, "\n") + "\n"
+		if len(test.expected) == 0 {
+			expected = testHeader
+		}
+		actual := MakeParentDotConfig(test.server, test.serverData, test.dses, test.parents, testHeader)
+		if actual != expected {
+			t.Errorf("MakeParentDotConfig %s expected:\n%s\nactual:\n%s", test.name, expected, actual)
+		}
+	}
+}
+
+func TestGetOriginHostPort(t *testing.T) {
+	type testCase struct {
+		origin string
+		host   string
+		port   string
+	}
+	tests := []testCase{
+		{"http://origin.example.net", "origin.example.net", "80"},
+		{"https://origin.example.net", "origin.example.net", "443"},
+		{"http://origin.example.net:8080/", "origin.example.net", "8080"},
+		{"HTTPS://origin.example.net:8443", "origin.example.net", "8443"},
+	}
+	for _, test := range tests {
+		host, port := getOriginHostPort(test.origin)
+		if host != test.host || port != test.port {
+			t.Errorf("getOriginHostPort(%q) expected: %s %s actual: %s %s", test.origin, test.host, test.port, host, port)
+		}
+	}
+}
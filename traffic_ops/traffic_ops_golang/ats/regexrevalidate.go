@@ -0,0 +1,192 @@
+package ats
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"errors"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
+)
+
+const RegexRevalidateFileName = "regex_revalidate.config"
+
+// MaxRevalDurationDaysParamName is the regex_revalidate.config parameter of the maximum number of days an invalidation job may last.
+const MaxRevalDurationDaysParamName = "maxRevalDurationDays"
+
+// DefaultMaxRevalDurationDays is the maximum number of days an invalidation job may last, if no maxRevalDurationDays parameter exists.
+const DefaultMaxRevalDurationDays = 90
+
+// MinRevalTTLHours is the minimum number of hours an invalidation job may last.
+const MinRevalTTLHours = 1
+
+var revalTTLRegex = regexp.MustCompile(`TTL:(\d+)h`)
+
+// RevalJob is the invalidation job data needed to build regex_revalidate.config.
+type RevalJob struct {
+	AssetURL   string
+	StartTime  time.Time
+	Parameters string
+}
+
+// CDNInfo is the CDN data needed to generate ATS config files for a given CDN.
+type CDNInfo struct {
+	ID   int
+	Name string
+}
+
+// GetCDNInfo returns the CDN info for the given CDN ID or name. Like GetServerInfo, an all-numeric idOrName is treated as an ID. If the CDN doesn't exist, false is returned.
+func GetCDNInfo(db *sql.DB, idOrName string) (CDNInfo, bool, error) {
+	qry := `SELECT c.id, c.name FROM cdn as c `
+	where := `WHERE c.name = $1`
+	arg := interface{}(idOrName)
+	if id, err := strconv.Atoi(idOrName); err == nil {
+		where = `WHERE c.id = $1`
+		arg = id
+	}
+	c := CDNInfo{}
+	if err := db.QueryRow(qry+where, arg).Scan(&c.ID, &c.Name); err != nil {
+		if err == sql.ErrNoRows {
+			return CDNInfo{}, false, nil
+		}
+		return CDNInfo{}, false, errors.New("querying cdn info: " + err.Error())
+	}
+	return c, true, nil
+}
+
+// GetMaxRevalDurationDays returns the maxRevalDurationDays regex_revalidate.config parameter, or DefaultMaxRevalDurationDays if it doesn't exist.
+func GetMaxRevalDurationDays(db *sql.DB) (int, error) {
+	qry := `
+SELECT
+  p.value
+FROM parameter as p
+WHERE p.name = $1 AND p.config_file = $2
+ORDER BY p.id
+LIMIT 1
+`
+	val := ""
+	if err := db.QueryRow(qry, MaxRevalDurationDaysParamName, RegexRevalidateFileName).Scan(&val); err != nil {
+		if err == sql.ErrNoRows {
+			return DefaultMaxRevalDurationDays, nil
+		}
+		return 0, errors.New("querying max reval duration days: " + err.Error())
+	}
+	days, err := strconv.Atoi(val)
+	if err != nil {
+		return 0, errors.New("parameter " + MaxRevalDurationDaysParamName + " value '" + val + "' is not an integer")
+	}
+	return days, nil
+}
+
+// GetRevalJobs returns the purge jobs of the delivery services in the given CDN, which started within the given number of days.
+func GetRevalJobs(db *sql.DB, cdnID int, maxDays int) ([]RevalJob, error) {
+	qry := `
+SELECT
+  j.asset_url,
+  j.start_time,
+  COALESCE(j.parameters, '')
+FROM job as j
+JOIN deliveryservice as ds ON j.job_deliveryservice = ds.id
+WHERE ds.cdn_id = $1 AND j.keyword = $2 AND j.start_time > now() - ($3 * interval '1 day')
+`
+	rows, err := db.Query(qry, cdnID, tc.JobKeywordPurge, maxDays)
+	if err != nil {
+		return nil, errors.New("querying jobs: " + err.Error())
+	}
+	defer rows.Close()
+	jobs := []RevalJob{}
+	for rows.Next() {
+		j := RevalJob{}
+		if err := rows.Scan(&j.AssetURL, &j.StartTime, &j.Parameters); err != nil {
+			return nil, errors.New("scanning jobs: " + err.Error())
+		}
+		jobs = append(jobs, j)
+	}
+	return jobs, nil
+}
+
+// RevalTTLHours returns the TTL in hours of the given job parameters, e.g. 48 for "TTL:48h", and whether the parameters had a TTL.
+func RevalTTLHours(parameters string) (int, bool) {
+	match := revalTTLRegex.FindStringSubmatch(parameters)
+	if match == nil {
+		return 0, false
+	}
+	ttl, err := strconv.Atoi(match[1])
+	if err != nil {
+		return 0, false
+	}
+	return ttl, true
+}
+
+// MakeRegexRevalidateDotConfig returns the regex_revalidate.config text for the given jobs. Like the Perl Traffic Ops, job TTLs are clamped between MinRevalTTLHours and maxDays, jobs without a TTL or which ended before now are omitted, and if several jobs have the same asset URL, the one which ends last is used.
+func MakeRegexRevalidateDotConfig(jobs []RevalJob, maxDays int, now time.Time, header string) string {
+	maxHours := maxDays * 24
+	ends := map[string]int64{}
+	for _, job := range jobs {
+		ttl, ok := RevalTTLHours(job.Parameters)
+		if !ok {
+			continue
+		}
+		if ttl < MinRevalTTLHours {
+			ttl = MinRevalTTLHours
+		} else if ttl > maxHours {
+			ttl = maxHours
+		}
+		end := job.StartTime.Add(time.Duration(ttl) * time.Hour)
+		if end.Before(now) {
+			continue
+		}
+		if prevEnd, ok := ends[job.AssetURL]; !ok || end.Unix() > prevEnd {
+			ends[job.AssetURL] = end.Unix()
+		}
+	}
+	assetURLs := make([]string, 0, len(ends))
+	for assetURL := range ends {
+		assetURLs = append(assetURLs, assetURL)
+	}
+	sort.Strings(assetURLs)
+	text := header
+	for _, assetURL := range assetURLs {
+		text += assetURL + " " + strconv.FormatInt(ends[assetURL], 10) + "\n"
+	}
+	return text
+}
+
+// GetRegexRevalidateDotConfig fetches the data for, and returns the regex_revalidate.config text of, the given CDN.
+func GetRegexRevalidateDotConfig(db *sql.DB, cdn CDNInfo) (string, error) {
+	maxDays, err := GetMaxRevalDurationDays(db)
+	if err != nil {
+		return "", errors.New("getting max reval duration: " + err.Error())
+	}
+	jobs, err := GetRevalJobs(db, cdn.ID, maxDays)
+	if err != nil {
+		return "", errors.New("getting jobs: " + err.Error())
+	}
+	nameVersionStr, err := GetNameVersionString(db)
+	if err != nil {
+		return "", errors.New("getting name version string: " + err.Error())
+	}
+	header := HeaderComment("CDN "+cdn.Name, nameVersionStr, time.Now())
+	return MakeRegexRevalidateDotConfig(jobs, maxDays, time.Now(), header), nil
+}
@@ -0,0 +1,64 @@
+package ats
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRevalTTLHours(t *testing.T) {
+	tests := []struct {
+		params string
+		ttl    int
+		ok     bool
+	}{
+		{"TTL:48h", 48, true},
+		{"TTL:1h", 1, true},
+		{"", 0, false},
+		{"TTL:48", 0, false},
+	}
+	for _, test := range tests {
+		ttl, ok := RevalTTLHours(test.params)
+		if ttl != test.ttl || ok != test.ok {
+			t.Errorf("RevalTTLHours(%q) expected: %v %v, actual: %v %v", test.params, test.ttl, test.ok, ttl, ok)
+		}
+	}
+}
+
+func TestMakeRegexRevalidateDotConfig(t *testing.T) {
+	now := time.Date(2018, 5, 1, 12, 0, 0, 0, time.UTC)
+	jobs := []RevalJob{
+		{AssetURL: "http://origin.b.example.net/b/.*", StartTime: now.Add(-time.Hour), Parameters: "TTL:24h"},
+		// a later job with the same regex which ends sooner must not shorten the invalidation
+		{AssetURL: "http://origin.b.example.net/b/.*", StartTime: now, Parameters: "TTL:2h"},
+		{AssetURL: "http://origin.a.example.net/a\\.png", StartTime: now, Parameters: "TTL:1000h"},
+		{AssetURL: "http://origin.a.example.net/expired", StartTime: now.Add(-48 * time.Hour), Parameters: "TTL:24h"},
+		{AssetURL: "http://origin.a.example.net/nottl", StartTime: now, Parameters: ""},
+	}
+
+	actual := MakeRegexRevalidateDotConfig(jobs, 30, now, testHeader)
+	expected := testHeader +
+		"http://origin.a.example.net/a\\.png 1527768000\n" +
+		"http://origin.b.example.net/b/.* 1525258800\n"
+	if actual != expected {
+		t.Errorf("MakeRegexRevalidateDotConfig expected:\n%s\nactual:\n%s", expected, actual)
+	}
+}
@@ -0,0 +1,435 @@
+package ats
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+const DSProtocolHTTP = 0
+const DSProtocolHTTPS = 1
+const DSProtocolHTTPAndHTTPS = 2
+const DSProtocolHTTPToHTTPS = 3
+
+const RangeRequestHandlingDontCache = 0
+const RangeRequestHandlingBackgroundFetch = 1
+const RangeRequestHandlingCacheRangeRequest = 2
+
+const QStringIgnoreUseInCacheKeyAndPassUp = 0
+const QStringIgnoreIgnoreInCacheKeyAndPassUp = 1
+const QStringIgnoreDrop = 2
+
+const RemapHostRegexType = "HOST_REGEXP"
+const RemapHTTPPlaceholder = "__http__"
+const CacheKeyConfigFile = "cachekey.config"
+const DropQStringConfigFile = "drop_qstring.config"
+const MidHeaderRewritePrefix = "hdr_rw_mid_"
+const URLSigPrefix = "url_sig_"
+const URISigningPrefix = "uri_signing_"
+
+// RemapDSData is the delivery service data needed to build remap.config. There is one RemapDSData per delivery service regex, matching the Perl Traffic Ops.
+type RemapDSData struct {
+	ID                   int
+	XMLID                string
+	Type                 string
+	OriginFQDN           *string
+	DSCP                 int
+	RoutingName          string
+	SigningAlgorithm     *string
+	QStringIgnore        int
+	Regex                string
+	RegexType            string
+	Domain               string
+	EdgeHeaderRewrite    *string
+	MidHeaderRewrite     *string
+	RegexRemap           *string
+	CacheURL             *string
+	RemapText            *string
+	Protocol             int
+	RangeRequestHandling int
+	FQPacingRate         *int
+	// CacheKeyParams is the delivery service profile's cachekey.config parameters, as a map of names to values.
+	CacheKeyParams map[string]string
+}
+
+// RemapConfigServerData is the server-wide data needed to build remap.config, beyond the server info and delivery services.
+type RemapConfigServerData struct {
+	// PackageParams is the server profile's 'package' parameters, as a map of names to values.
+	PackageParams   map[string]string
+	ATSMajorVersion int
+	// CacheURLGlobalExists is whether the server profile has a global cacheurl.config 'location' parameter.
+	CacheURLGlobalExists bool
+}
+
+// MakeRemapDotConfig returns the remap.config text for the given server. The header is the "DO NOT EDIT" comment, typically created with HeaderComment.
+func MakeRemapDotConfig(server ServerInfo, serverData RemapConfigServerData, dses []RemapDSData, header string) string {
+	if server.IsMid() {
+		return header + getMidRemapText(server, serverData, dses)
+	}
+	return header + getEdgeRemapText(server, serverData, dses)
+}
+
+// getMidRemapText returns the remap lines for a mid. Mids get a single line per origin, for every delivery service in the CDN which has remap plugins.
+func getMidRemapText(server ServerInfo, serverData RemapConfigServerData, dses []RemapDSData) string {
+	midRemaps := map[string]string{}
+	for _, ds := range dses {
+		if strings.Contains(ds.Type, "LIVE") && !strings.Contains(ds.Type, "NATNL") {
+			continue // Live local delivery services skip mids
+		}
+		org := ""
+		if ds.OriginFQDN != nil {
+			org = *ds.OriginFQDN
+		}
+		if _, ok := midRemaps[org]; ok {
+			continue // skip remap rules from extra HOST_REGEXP entries
+		}
+		text := ""
+		hasText := false
+		if ds.MidHeaderRewrite != nil && *ds.MidHeaderRewrite != "" {
+			text += ` @plugin=header_rewrite.so @pparam=` + GetConfigFile(MidHeaderRewritePrefix, ds.XMLID)
+			hasText = true
+		}
+		if ds.QStringIgnore == QStringIgnoreIgnoreInCacheKeyAndPassUp {
+			text += getQStringIgnoreRemap(serverData.ATSMajorVersion)
+			hasText = true
+		}
+		if ds.CacheURL != nil && *ds.CacheURL != "" {
+			text += ` @plugin=cacheurl.so @pparam=` + GetConfigFile(CacheUrlPrefix, ds.XMLID)
+			hasText = true
+		}
+		if ds.CacheKeyParams != nil {
+			text += getCacheKeyRemap(ds.CacheKeyParams)
+			hasText = true
+		}
+		if ds.RangeRequestHandling == RangeRequestHandlingCacheRangeRequest {
+			text += ` @plugin=cache_range_requests.so`
+			hasText = true
+		}
+		if hasText {
+			midRemaps[org] = text
+		}
+	}
+
+	lines := []string{}
+	for org, text := range midRemaps {
+		lines = append(lines, "map "+org+" "+org+text+"\n")
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "")
+}
+
+// getEdgeRemapText returns the remap lines for an edge. Edges get a line per host regex of every delivery service assigned to them.
+func getEdgeRemapText(server ServerInfo, serverData RemapConfigServerData, dses []RemapDSData) string {
+	texts := []string{}
+	for _, ds := range dses {
+		text := ""
+		if ds.Type == "ANY_MAP" {
+			if ds.RemapText != nil {
+				text = *ds.RemapText
+			}
+			texts = append(texts, text+"\n")
+			continue
+		}
+		for _, mapFrom := range getMapFroms(server, ds) {
+			text += buildRemapLine(server, serverData, ds, mapFrom, *ds.OriginFQDN+"/")
+		}
+		texts = append(texts, text)
+	}
+	sort.Strings(texts)
+	return strings.Join(texts, "")
+}
+
+// getMapFroms returns the remap 'from' URLs for the given delivery service regex. Only host regexes with an origin produce remap lines.
+func getMapFroms(server ServerInfo, ds RemapDSData) []string {
+	if ds.RegexType != RemapHostRegexType || ds.OriginFQDN == nil {
+		return nil
+	}
+
+	httpFrom := ""
+	httpsFrom := ""
+	if strings.HasSuffix(ds.Regex, `.*`) {
+		re := strings.Replace(ds.Regex, `\`, ``, -1)
+		re = strings.Replace(re, `.*`, ``, -1)
+		hName := RemapHTTPPlaceholder
+		if strings.HasPrefix(ds.Type, "DNS") {
+			hName = ds.RoutingName
+		}
+		portStr := ""
+		if hName == RemapHTTPPlaceholder && server.TCPPort > 0 && server.TCPPort != 80 {
+			portStr = ":" + strconv.Itoa(server.TCPPort)
+		}
+		httpFrom = "http://" + hName + re + ds.Domain + portStr + "/"
+		httpsFrom = "https://" + hName + re + ds.Domain + "/"
+	} else {
+		httpFrom = "http://" + ds.Regex + "/"
+		httpsFrom = "https://" + ds.Regex + "/"
+	}
+
+	switch ds.Protocol {
+	case DSProtocolHTTP:
+		return []string{httpFrom}
+	case DSProtocolHTTPS:
+		fallthrough
+	case DSProtocolHTTPToHTTPS:
+		return []string{httpsFrom}
+	case DSProtocolHTTPAndHTTPS:
+		return []string{httpFrom, httpsFrom}
+	}
+	return nil
+}
+
+// buildRemapLine returns a single edge remap line, including the trailing newline.
+func buildRemapLine(server ServerInfo, serverData RemapConfigServerData, ds RemapDSData, mapFrom string, mapTo string) string {
+	mapFrom = strings.Replace(mapFrom, RemapHTTPPlaceholder, server.HostName, 1)
+
+	dscpStr := strconv.Itoa(ds.DSCP)
+	text := "map	" + mapFrom + "     " + mapTo
+	if _, ok := serverData.PackageParams["dscp_remap"]; ok {
+		text += ` @plugin=dscp_remap.so @pparam=` + dscpStr
+	} else {
+		text += ` @plugin=header_rewrite.so @pparam=dscp/set_dscp_` + dscpStr + configSuffix
+	}
+	if ds.EdgeHeaderRewrite != nil {
+		text += ` @plugin=header_rewrite.so @pparam=` + GetConfigFile(HeaderRewritePrefix, ds.XMLID)
+	}
+	if ds.SigningAlgorithm != nil {
+		if *ds.SigningAlgorithm == "url_sig" {
+			text += ` @plugin=url_sig.so @pparam=` + GetConfigFile(URLSigPrefix, ds.XMLID)
+		} else if *ds.SigningAlgorithm == "uri_signing" {
+			text += ` @plugin=uri_signing.so @pparam=` + GetConfigFile(URISigningPrefix, ds.XMLID)
+		}
+	}
+	if ds.QStringIgnore == QStringIgnoreDrop {
+		text += ` @plugin=regex_remap.so @pparam=` + DropQStringConfigFile
+	} else if ds.QStringIgnore == QStringIgnoreIgnoreInCacheKeyAndPassUp && !serverData.CacheURLGlobalExists {
+		text += getQStringIgnoreRemap(serverData.ATSMajorVersion)
+	}
+	if ds.CacheURL != nil && *ds.CacheURL != "" {
+		text += ` @plugin=cacheurl.so @pparam=` + GetConfigFile(CacheUrlPrefix, ds.XMLID)
+	}
+	if ds.CacheKeyParams != nil {
+		text += getCacheKeyRemap(ds.CacheKeyParams)
+	}
+	if ds.RegexRemap != nil && *ds.RegexRemap != "" {
+		text += ` @plugin=regex_remap.so @pparam=` + GetConfigFile(RegexRemapPrefix, ds.XMLID)
+	}
+	if ds.RangeRequestHandling == RangeRequestHandlingBackgroundFetch {
+		text += ` @plugin=background_fetch.so @pparam=bg_fetch.config`
+	} else if ds.RangeRequestHandling == RangeRequestHandlingCacheRangeRequest {
+		text += ` @plugin=cache_range_requests.so `
+	}
+	if ds.RemapText != nil {
+		text += " " + *ds.RemapText
+	}
+	if ds.FQPacingRate != nil && *ds.FQPacingRate > 0 {
+		text += ` @plugin=fq_pacing.so @pparam=--rate=` + strconv.Itoa(*ds.FQPacingRate)
+	}
+	return text + "\n"
+}
+
+// getQStringIgnoreRemap returns the remap plugin text to ignore query strings in the cache key. ATS 6 and later use the cachekey plugin, earlier versions use cacheurl.
+func getQStringIgnoreRemap(atsMajorVersion int) string {
+	if atsMajorVersion >= 6 {
+		return ` @plugin=cachekey.so @pparam=--separator= @pparam=--remove-all-params=true @pparam=--remove-path=true @pparam=--capture-prefix-uri=/http:\/\/([^?]*)/http:\/\/$1/`
+	}
+	return ` @plugin=cacheurl.so @pparam=cacheurl_qstring.config`
+}
+
+// getCacheKeyRemap returns the cachekey plugin text for the given delivery service profile cachekey.config parameters. Parameters are sorted by name, so the output is deterministic.
+func getCacheKeyRemap(params map[string]string) string {
+	names := []string{}
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	text := ` @plugin=cachekey.so`
+	for _, name := range names {
+		text += ` @pparam=--` + name + "=" + params[name]
+	}
+	return text
+}
+
+// GetRemapConfigServerData returns the server-wide data needed to build remap.config for the given server.
+func GetRemapConfigServerData(db *sql.DB, server ServerInfo) (RemapConfigServerData, error) {
+	packageParams, err := GetProfileParamData(db, server.ProfileID, "package")
+	if err != nil {
+		return RemapConfigServerData{}, errors.New("getting package parameters: " + err.Error())
+	}
+	atsMajorVersion, err := GetATSMajorVersion(db, server.ProfileID)
+	if err != nil {
+		return RemapConfigServerData{}, errors.New("getting ATS major version: " + err.Error())
+	}
+	cacheURLGlobalVal, cacheURLGlobalExists, err := GetProfileParamValue(db, server.ProfileID, "cacheurl.config", "location")
+	if err != nil {
+		return RemapConfigServerData{}, errors.New("getting global cacheurl parameter: " + err.Error())
+	}
+	return RemapConfigServerData{
+		PackageParams:        packageParams,
+		ATSMajorVersion:      atsMajorVersion,
+		CacheURLGlobalExists: cacheURLGlobalExists && cacheURLGlobalVal != "" && cacheURLGlobalVal != "0", // match Perl truthiness
+	}, nil
+}
+
+// GetRemapDSData returns the delivery service data for building the given server's remap.config. Mids get every delivery service in their CDN which is assigned to any server, edges get the delivery services assigned to them.
+func GetRemapDSData(db *sql.DB, server ServerInfo) ([]RemapDSData, error) {
+	qry := `
+SELECT
+  ds.id,
+  ds.xml_id,
+  dstype.name AS ds_type,
+  ds.org_server_fqdn,
+  ds.dscp,
+  COALESCE(ds.routing_name, ''),
+  ds.signing_algorithm,
+  COALESCE(ds.qstring_ignore, 0),
+  r.pattern,
+  retype.name AS re_type,
+  cdn.domain_name,
+  ds.edge_header_rewrite,
+  ds.mid_header_rewrite,
+  ds.regex_remap,
+  ds.cacheurl,
+  ds.remap_text,
+  COALESCE(ds.protocol, 0),
+  COALESCE(ds.range_request_handling, 0),
+  ds.fq_pacing_rate,
+  ds.profile
+FROM deliveryservice AS ds
+JOIN deliveryservice_regex AS dsr ON dsr.deliveryservice = ds.id
+JOIN regex AS r ON dsr.regex = r.id
+JOIN type AS retype ON r.type = retype.id
+JOIN type AS dstype ON ds.type = dstype.id
+JOIN cdn ON cdn.id = ds.cdn_id
+`
+	where := `
+WHERE ds.id IN (SELECT dss.deliveryservice FROM deliveryservice_server AS dss WHERE dss.server = $1)
+`
+	arg := interface{}(server.ID)
+	if server.IsMid() {
+		where = `
+WHERE cdn.name = $1 AND ds.id IN (SELECT dss.deliveryservice FROM deliveryservice_server AS dss)
+`
+		arg = server.CDN
+	}
+	orderBy := `
+ORDER BY ds.id, re_type, dsr.set_number
+`
+	rows, err := db.Query(qry+where+orderBy, arg)
+	if err != nil {
+		return nil, errors.New("querying delivery services: " + err.Error())
+	}
+	defer rows.Close()
+
+	dses := []RemapDSData{}
+	dsProfiles := map[int]int{} // map[dsIndex]profileID
+	for rows.Next() {
+		ds := RemapDSData{}
+		orgFQDN := sql.NullString{}
+		signingAlgorithm := sql.NullString{}
+		edgeHeaderRewrite := sql.NullString{}
+		midHeaderRewrite := sql.NullString{}
+		regexRemap := sql.NullString{}
+		cacheURL := sql.NullString{}
+		remapText := sql.NullString{}
+		fqPacingRate := sql.NullInt64{}
+		profileID := sql.NullInt64{}
+		if err := rows.Scan(&ds.ID, &ds.XMLID, &ds.Type, &orgFQDN, &ds.DSCP, &ds.RoutingName, &signingAlgorithm, &ds.QStringIgnore, &ds.Regex, &ds.RegexType, &ds.Domain, &edgeHeaderRewrite, &midHeaderRewrite, &regexRemap, &cacheURL, &remapText, &ds.Protocol, &ds.RangeRequestHandling, &fqPacingRate, &profileID); err != nil {
+			return nil, errors.New("scanning delivery services: " + err.Error())
+		}
+		ds.OriginFQDN = nullStrPtr(orgFQDN)
+		ds.SigningAlgorithm = nullStrPtr(signingAlgorithm)
+		ds.EdgeHeaderRewrite = nullStrPtr(edgeHeaderRewrite)
+		ds.MidHeaderRewrite = nullStrPtr(midHeaderRewrite)
+		ds.RegexRemap = nullStrPtr(regexRemap)
+		ds.CacheURL = nullStrPtr(cacheURL)
+		ds.RemapText = nullStrPtr(remapText)
+		if fqPacingRate.Valid {
+			rate := int(fqPacingRate.Int64)
+			ds.FQPacingRate = &rate
+		}
+		if profileID.Valid {
+			dsProfiles[len(dses)] = int(profileID.Int64)
+		}
+		dses = append(dses, ds)
+	}
+
+	profileIDs := []int64{}
+	for _, profileID := range dsProfiles {
+		profileIDs = append(profileIDs, int64(profileID))
+	}
+	cacheKeyParams, err := getProfilesConfigParams(db, profileIDs, CacheKeyConfigFile)
+	if err != nil {
+		return nil, errors.New("getting delivery service cachekey parameters: " + err.Error())
+	}
+	for i, profileID := range dsProfiles {
+		if params, ok := cacheKeyParams[profileID]; ok {
+			dses[i].CacheKeyParams = params
+		}
+	}
+	return dses, nil
+}
+
+// getProfilesConfigParams returns the parameters of the given config file for the given profiles, as a map of profile IDs to parameter names to values. Profiles with no parameters for the config file are omitted.
+func getProfilesConfigParams(db *sql.DB, profileIDs []int64, configFile string) (map[int]map[string]string, error) {
+	params := map[int]map[string]string{}
+	if len(profileIDs) == 0 {
+		return params, nil
+	}
+	qry := `
+SELECT
+  pp.profile,
+  p.name,
+  p.value
+FROM parameter AS p
+JOIN profile_parameter AS pp ON pp.parameter = p.id
+WHERE pp.profile = ANY($1) AND p.config_file = $2
+`
+	rows, err := db.Query(qry, pq.Array(profileIDs), configFile)
+	if err != nil {
+		return nil, errors.New("querying: " + err.Error())
+	}
+	defer rows.Close()
+	for rows.Next() {
+		profileID := 0
+		name := ""
+		val := ""
+		if err := rows.Scan(&profileID, &name, &val); err != nil {
+			return nil, errors.New("scanning: " + err.Error())
+		}
+		if _, ok := params[profileID]; !ok {
+			params[profileID] = map[string]string{}
+		}
+		params[profileID][name] = val
+	}
+	return params, nil
+}
+
+func nullStrPtr(s sql.NullString) *string {
+	if !s.Valid {
+		return nil
+	}
+	return &s.String
+}
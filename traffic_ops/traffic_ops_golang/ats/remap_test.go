@@ -0,0 +1,205 @@
+package ats
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+var updateGolden = flag.Bool("update", false, "update the golden files in testdata")
+
+func strPtr(s string) *string { return &s }
+func intPtr(i int) *int       { return &i }
+
+const testHeader = "# DO NOT EDIT - Generated for myhost by Traffic Ops (https://to.example.net) on Thu Oct 18 10:00:00 UTC 2018\n"
+
+func testEdge() ServerInfo {
+	return ServerInfo{ID: 1, HostName: "myedge", DomainName: "example.net", Type: "EDGE", CDN: "mycdn", CDNID: 1, ProfileID: 10, TCPPort: 80}
+}
+
+func testMid() ServerInfo {
+	return ServerInfo{ID: 2, HostName: "mymid", DomainName: "example.net", Type: "MID", CDN: "mycdn", CDNID: 1, ProfileID: 20, TCPPort: 80}
+}
+
+func testDS(xmlID string, dsType string, protocol int) RemapDSData {
+	return RemapDSData{
+		ID:          1,
+		XMLID:       xmlID,
+		Type:        dsType,
+		OriginFQDN:  strPtr("http://origin." + xmlID + ".example.net"),
+		DSCP:        8,
+		RoutingName: "cdn",
+		Regex:       `.*\.` + xmlID + `\..*`,
+		RegexType:   RemapHostRegexType,
+		Domain:      "mycdn.example.net",
+		Protocol:    protocol,
+	}
+}
+
+func checkGolden(t *testing.T, name string, actual string) {
+	path := filepath.Join("testdata", name)
+	if *updateGolden {
+		if err := ioutil.WriteFile(path, []byte(actual), 0644); err != nil {
+			t.Fatalf("writing golden file %s: %v", path, err)
+		}
+	}
+	expected, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading golden file %s: %v", path, err)
+	}
+	if string(expected) != actual {
+		t.Errorf("%s: expected:\n%s\nactual:\n%s", name, expected, actual)
+	}
+}
+
+func TestMakeRemapDotConfigEdge(t *testing.T) {
+	serverData := RemapConfigServerData{PackageParams: map[string]string{}, ATSMajorVersion: 7}
+
+	http := testDS("http-ds", "HTTP", DSProtocolHTTP)
+	http.EdgeHeaderRewrite = strPtr("set-header X-Foo bar")
+	http.SigningAlgorithm = strPtr("url_sig")
+	http.QStringIgnore = QStringIgnoreIgnoreInCacheKeyAndPassUp
+	http.RangeRequestHandling = RangeRequestHandlingBackgroundFetch
+	http.FQPacingRate = intPtr(1000)
+
+	httpPath := testDS("http-ds", "HTTP", DSProtocolHTTP)
+	httpPath.Regex = `/path/.*`
+	httpPath.RegexType = "PATH_REGEXP"
+
+	httpNoCache := testDS("http-no-cache-ds", "HTTP_NO_CACHE", DSProtocolHTTPS)
+	httpNoCache.SigningAlgorithm = strPtr("uri_signing")
+	httpNoCache.QStringIgnore = QStringIgnoreDrop
+
+	httpLive := testDS("http-live-ds", "HTTP_LIVE", DSProtocolHTTPAndHTTPS)
+	httpLive.CacheURL = strPtr("cacheurl")
+	httpLive.RegexRemap = strPtr("regex")
+	httpLive.RangeRequestHandling = RangeRequestHandlingCacheRangeRequest
+	httpLive.CacheKeyParams = map[string]string{"remove-all-params": "true", "include-params": "a,b"}
+
+	httpLiveNatnl := testDS("http-live-natnl-ds", "HTTP_LIVE_NATNL", DSProtocolHTTPToHTTPS)
+	httpLiveNatnl.Regex = `myhost.example.com`
+	httpLiveNatnl.RemapText = strPtr("@action=allow @src_ip=10.0.0.0-10.255.255.255")
+
+	dns := testDS("dns-ds", "DNS", DSProtocolHTTP)
+	dnsLive := testDS("dns-live-ds", "DNS_LIVE", DSProtocolHTTPAndHTTPS)
+	dnsLiveNatnl := testDS("dns-live-natnl-ds", "DNS_LIVE_NATNL", DSProtocolHTTP)
+
+	anyMap := testDS("any-map-ds", "ANY_MAP", DSProtocolHTTP)
+	anyMap.RemapText = strPtr("map http://any.example.net/ http://origin.any.example.net/")
+
+	noOrigin := testDS("no-origin-ds", "HTTP", DSProtocolHTTP)
+	noOrigin.OriginFQDN = nil
+
+	dses := []RemapDSData{http, httpPath, httpNoCache, httpLive, httpLiveNatnl, dns, dnsLive, dnsLiveNatnl, anyMap, noOrigin}
+
+	checkGolden(t, "remap_edge.config", MakeRemapDotConfig(testEdge(), serverData, dses, testHeader))
+
+	// ATS 5, a custom port, DSCP remap, and a global cacheurl
+	edge := testEdge()
+	edge.TCPPort = 8080
+	serverData = RemapConfigServerData{PackageParams: map[string]string{"dscp_remap": "1"}, ATSMajorVersion: 5}
+	checkGolden(t, "remap_edge_ats5.config", MakeRemapDotConfig(edge, serverData, []RemapDSData{http, dns}, testHeader))
+
+	serverData.CacheURLGlobalExists = true
+	checkGolden(t, "remap_edge_global_cacheurl.config", MakeRemapDotConfig(edge, serverData, []RemapDSData{http}, testHeader))
+}
+
+func TestMakeRemapDotConfigMid(t *testing.T) {
+	serverData := RemapConfigServerData{PackageParams: map[string]string{}, ATSMajorVersion: 7}
+
+	http := testDS("http-ds", "HTTP", DSProtocolHTTP)
+	http.MidHeaderRewrite = strPtr("set-header X-Mid bar")
+	http.QStringIgnore = QStringIgnoreIgnoreInCacheKeyAndPassUp
+
+	// a second regex for the same DS must not create a second line
+	http2 := http
+	http2.Regex = `.*\.http-ds-alias\..*`
+
+	httpNoCache := testDS("http-no-cache-ds", "HTTP_NO_CACHE", DSProtocolHTTP)
+	httpNoCache.CacheURL = strPtr("cacheurl")
+	httpNoCache.CacheKeyParams = map[string]string{"remove-all-params": "true"}
+
+	httpLive := testDS("http-live-ds", "HTTP_LIVE", DSProtocolHTTP)
+	httpLive.MidHeaderRewrite = strPtr("set-header X-Live bar")
+
+	httpLiveNatnl := testDS("http-live-natnl-ds", "HTTP_LIVE_NATNL", DSProtocolHTTP)
+	httpLiveNatnl.RangeRequestHandling = RangeRequestHandlingCacheRangeRequest
+
+	dnsLive := testDS("dns-live-ds", "DNS_LIVE", DSProtocolHTTP)
+	dnsLive.QStringIgnore = QStringIgnoreIgnoreInCacheKeyAndPassUp
+
+	// no mid plugins, so no line
+	dns := testDS("dns-ds", "DNS", DSProtocolHTTP)
+
+	dses := []RemapDSData{http, http2, httpNoCache, httpLive, httpLiveNatnl, dnsLive, dns}
+	checkGolden(t, "remap_mid.config", MakeRemapDotConfig(testMid(), serverData, dses, testHeader))
+}
+
+func TestHeaderComment(t *testing.T) {
+	date := time.Date(2018, time.March, 2, 3, 4, 5, 0, time.UTC)
+	expected := "# DO NOT EDIT - Generated for myhost by Traffic Ops (https://to.example.net) on Fri Mar  2 03:04:05 UTC 2018\n"
+	if actual := HeaderComment("myhost", "Traffic Ops (https://to.example.net)", date); actual != expected {
+		t.Errorf("HeaderComment expected: %q actual: %q", expected, actual)
+	}
+}
+
+func TestATSMajorVersionFromString(t *testing.T) {
+	expected := map[string]int{"7.1.2-1.el7": 7, "6": 6, "5.3.2": 5, "foo": 0, "": 0}
+	for ver, expectedMajor := range expected {
+		if actual := ATSMajorVersionFromString(ver); actual != expectedMajor {
+			t.Errorf("ATSMajorVersionFromString(%q) expected: %v actual: %v", ver, expectedMajor, actual)
+		}
+	}
+}
+
+func TestGetProfileParamData(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	rows := sqlmock.NewRows([]string{"id", "name", "value"})
+	rows = rows.AddRow(1, "location", "/etc/trafficserver")
+	rows = rows.AddRow(2, "trafficserver", "7.1.2")
+	rows = rows.AddRow(3, "dscp_remap", "1")
+	rows = rows.AddRow(4, "dscp_remap", "2")
+	mock.ExpectQuery("SELECT").WithArgs(10, "package").WillReturnRows(rows)
+
+	params, err := GetProfileParamData(mockDB, 10, "package")
+	if err != nil {
+		t.Fatalf("GetProfileParamData expected: nil error, actual: %v", err)
+	}
+	expected := map[string]string{"trafficserver": "7.1.2", "dscp_remap": "1", "dscp_remap__4": "2"}
+	if len(params) != len(expected) {
+		t.Fatalf("GetProfileParamData expected: %+v actual: %+v", expected, params)
+	}
+	for name, val := range expected {
+		if params[name] != val {
+			t.Errorf("GetProfileParamData param %s expected: %v actual: %v", name, val, params[name])
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package ats
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultATSVersion is the Traffic Server major version assumed when the server profile has no package.trafficserver parameter. This matches the Perl Traffic Ops default.
+const DefaultATSVersion = 5
+
+// HeaderCommentDateFormat is the format of the date in the generated file header comment. It matches the output of the `date` command, which the Perl Traffic Ops used.
+const HeaderCommentDateFormat = "Mon Jan _2 15:04:05 MST 2006"
+
+// ServerInfo is the server data needed to generate ATS config files for a given server.
+type ServerInfo struct {
+	ID           int
+	HostName     string
+	DomainName   string
+	Type         string
+	CDN          string
+	CDNID        int
+	ProfileID    int
+	ProfileName  string
+	CachegroupID int
+	TCPPort      int
+	IP           string
+}
+
+// IsMid returns whether the server is a mid-tier cache. This matches the Perl Traffic Ops, which treats any type prefixed with MID as a mid.
+func (s ServerInfo) IsMid() bool {
+	return strings.HasPrefix(s.Type, "MID")
+}
+
+// GetServerInfo returns the server info for the given server ID or host name. Like the Perl Traffic Ops, an all-numeric idOrHost is treated as an ID. If the server doesn't exist, false is returned.
+func GetServerInfo(db *sql.DB, idOrHost string) (ServerInfo, bool, error) {
+	qry := `
+SELECT
+  s.id,
+  s.host_name,
+  s.domain_name,
+  t.name as type,
+  c.name as cdn,
+  c.id as cdn_id,
+  p.id as profile_id,
+  p.name as profile_name,
+  s.cachegroup,
+  COALESCE(s.tcp_port, 0),
+  s.ip_address
+FROM server as s
+JOIN type as t ON s.type = t.id
+JOIN cdn as c ON s.cdn_id = c.id
+JOIN profile as p ON s.profile = p.id
+`
+	where := `WHERE s.host_name = $1`
+	arg := interface{}(idOrHost)
+	if id, err := strconv.Atoi(idOrHost); err == nil {
+		where = `WHERE s.id = $1`
+		arg = id
+	}
+	s := ServerInfo{}
+	if err := db.QueryRow(qry+where, arg).Scan(&s.ID, &s.HostName, &s.DomainName, &s.Type, &s.CDN, &s.CDNID, &s.ProfileID, &s.ProfileName, &s.CachegroupID, &s.TCPPort, &s.IP); err != nil {
+		if err == sql.ErrNoRows {
+			return ServerInfo{}, false, nil
+		}
+		return ServerInfo{}, false, errors.New("querying server info: " + err.Error())
+	}
+	return s, true, nil
+}
+
+// GetProfileParamData returns the parameters for the given profile and config file, as a map of names to values. Like the Perl Traffic Ops, 'location' parameters are omitted, and duplicate names are made unique by appending the parameter ID.
+func GetProfileParamData(db *sql.DB, profileID int, configFile string) (map[string]string, error) {
+	qry := `
+SELECT
+  p.id,
+  p.name,
+  p.value
+FROM parameter as p
+JOIN profile_parameter as pp ON pp.parameter = p.id
+WHERE pp.profile = $1 AND p.config_file = $2
+ORDER BY p.id
+`
+	rows, err := db.Query(qry, profileID, configFile)
+	if err != nil {
+		return nil, errors.New("querying profile parameters: " + err.Error())
+	}
+	defer rows.Close()
+	params := map[string]string{}
+	for rows.Next() {
+		id := 0
+		name := ""
+		val := ""
+		if err := rows.Scan(&id, &name, &val); err != nil {
+			return nil, errors.New("scanning profile parameters: " + err.Error())
+		}
+		if name == "location" {
+			continue
+		}
+		if _, ok := params[name]; ok {
+			name += "__" + strconv.Itoa(id)
+		}
+		params[name] = val
+	}
+	return params, nil
+}
+
+// GetProfileParamValue returns the value of the given parameter on the given profile, and whether it existed.
+func GetProfileParamValue(db *sql.DB, profileID int, configFile string, name string) (string, bool, error) {
+	qry := `
+SELECT
+  p.value
+FROM parameter as p
+JOIN profile_parameter as pp ON pp.parameter = p.id
+WHERE pp.profile = $1 AND p.config_file = $2 AND p.name = $3
+ORDER BY p.id
+LIMIT 1
+`
+	val := ""
+	if err := db.QueryRow(qry, profileID, configFile, name).Scan(&val); err != nil {
+		if err == sql.ErrNoRows {
+			return "", false, nil
+		}
+		return "", false, errors.New("querying profile parameter value: " + err.Error())
+	}
+	return val, true, nil
+}
+
+// GetATSMajorVersion returns the major version of the package.trafficserver parameter on the given profile, or DefaultATSVersion if the profile has no such parameter.
+func GetATSMajorVersion(db *sql.DB, profileID int) (int, error) {
+	ver, ok, err := GetProfileParamValue(db, profileID, "package", "trafficserver")
+	if err != nil {
+		return 0, errors.New("getting ATS version: " + err.Error())
+	}
+	if !ok {
+		return DefaultATSVersion, nil
+	}
+	return ATSMajorVersionFromString(ver), nil
+}
+
+// ATSMajorVersionFromString returns the major version number of the given Traffic Server version string, e.g. 7 for "7.1.2-1.el7". Unparseable versions return 0, which matches the Perl numeric comparison of a non-numeric string.
+func ATSMajorVersionFromString(ver string) int {
+	major := strings.SplitN(ver, ".", 2)[0]
+	i, err := strconv.Atoi(major)
+	if err != nil {
+		return 0
+	}
+	return i
+}
+
+// GetNameVersionString returns the tool name and URL string used in generated file headers, from the global tm.toolname and tm.url parameters.
+func GetNameVersionString(db *sql.DB) (string, error) {
+	qry := `
+SELECT
+  p.name,
+  p.value
+FROM parameter as p
+WHERE p.config_file = 'global' AND (p.name = 'tm.toolname' OR p.name = 'tm.url')
+`
+	rows, err := db.Query(qry)
+	if err != nil {
+		return "", errors.New("querying tool name parameters: " + err.Error())
+	}
+	defer rows.Close()
+	toolName := ""
+	url := ""
+	for rows.Next() {
+		name := ""
+		val := ""
+		if err := rows.Scan(&name, &val); err != nil {
+			return "", errors.New("scanning tool name parameters: " + err.Error())
+		}
+		switch name {
+		case "tm.toolname":
+			toolName = val
+		case "tm.url":
+			url = val
+		}
+	}
+	return toolName + " (" + url + ")", nil
+}
+
+// HeaderComment returns the "DO NOT EDIT" header comment line, including the trailing newline, which begins every generated config file.
+func HeaderComment(name string, nameVersionStr string, date time.Time) string {
+	return "# DO NOT EDIT - Generated for " + name + " by " + nameVersionStr + " on " + date.Format(HeaderCommentDateFormat) + "\n"
+}
@@ -0,0 +1,120 @@
+package auth
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"crypto/sha1"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-log"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/config"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Identity is a user whose password was accepted by an Authenticator.
+type Identity struct {
+	UserName string
+	// Role and Tenant are the names of the role and tenant the user should have, from an authenticator of users managed outside Traffic Ops, such as LDAP. They're empty if the authenticator doesn't manage roles.
+	Role   string
+	Tenant string
+	// Provision is whether the user should be created with Role and Tenant, if they don't exist in Traffic Ops.
+	Provision bool
+}
+
+// Authenticator checks the passwords of users.
+type Authenticator interface {
+	// Authenticate returns the identity of the user if the password is valid, or nil if it isn't, or the user is unknown. An error is only returned if the password couldn't be checked.
+	Authenticate(username string, password string) (*Identity, error)
+	Name() string
+}
+
+// GetAuthenticators returns the authenticators of the config, in the order they're tried. The config names are validated when it's loaded.
+func GetAuthenticators(cfg config.Config, db *sqlx.DB) []Authenticator {
+	authenticators := []Authenticator{}
+	for _, name := range cfg.Authenticators {
+		switch name {
+		case config.AuthenticatorLocal:
+			authenticators = append(authenticators, LocalAuthenticator{DB: db})
+		case config.AuthenticatorLDAP:
+			authenticators = append(authenticators, LDAPAuthenticator{Cfg: cfg.LDAP})
+		default:
+			log.Errorln("unknown authenticator '" + name + "', skipping")
+		}
+	}
+	return authenticators
+}
+
+// Authenticate tries each authenticator in order, returning the identity from the first which accepts the password. If none do, but any failed to check it, an error is returned, so an unavailable LDAP server isn't reported as a wrong password.
+func Authenticate(authenticators []Authenticator, username string, password string) (*Identity, error) {
+	errs := []string{}
+	for _, authenticator := range authenticators {
+		identity, err := authenticator.Authenticate(username, password)
+		if err != nil {
+			errs = append(errs, authenticator.Name()+": "+err.Error())
+			continue
+		}
+		if identity != nil {
+			return identity, nil
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.New("authenticating user '" + username + "': " + strings.Join(errs, "; "))
+	}
+	return nil, nil
+}
+
+// LocalAuthenticator checks passwords against the local passwords of users in the Traffic Ops database.
+type LocalAuthenticator struct {
+	DB *sqlx.DB
+}
+
+func (a LocalAuthenticator) Name() string { return config.AuthenticatorLocal }
+
+func (a LocalAuthenticator) Authenticate(username string, password string) (*Identity, error) {
+	localPasswd := sql.NullString{}
+	if err := a.DB.QueryRow(`SELECT local_passwd FROM tm_user WHERE username = $1`, username).Scan(&localPasswd); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, errors.New("querying local password: " + err.Error())
+	}
+	if !localPasswd.Valid || !verifyLocalPassword(password, localPasswd.String) {
+		return nil, nil
+	}
+	return &Identity{UserName: username}, nil
+}
+
+// verifyLocalPassword returns whether the password matches the hashed local password, which is an scrypt key, or a SHA1 hex digest created by old versions of Traffic Ops.
+func verifyLocalPassword(password string, hashed string) bool {
+	if strings.HasPrefix(hashed, DefaultParams.Algorithm+KEY_DELIM) {
+		if strings.Count(hashed, KEY_DELIM) != 5 {
+			return false // malformed scrypt key
+		}
+		return VerifyPassword(password, hashed) == nil
+	}
+	// DEPRECATED - SHA1 passwords should be removed in the next major version, like the Perl verify_pass
+	sum := sha1.Sum([]byte(password))
+	return subtle.ConstantTimeCompare([]byte(hex.EncodeToString(sum[:])), []byte(hashed)) == 1
+}
@@ -0,0 +1,122 @@
+package auth
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+type testAuthenticator struct {
+	name     string
+	identity *Identity
+	err      error
+}
+
+func (a testAuthenticator) Name() string { return a.name }
+
+func (a testAuthenticator) Authenticate(username string, password string) (*Identity, error) {
+	return a.identity, a.err
+}
+
+func TestAuthenticate(t *testing.T) {
+	user := &Identity{UserName: "user1"}
+	down := testAuthenticator{name: "ldap", err: errors.New("connection refused")}
+	rejects := testAuthenticator{name: "local"}
+	accepts := testAuthenticator{name: "local", identity: user}
+
+	if identity, err := Authenticate([]Authenticator{rejects, accepts}, "user1", "password"); err != nil || identity != user {
+		t.Errorf("expected the identity of the first authenticator to accept, actual %v %v", identity, err)
+	}
+	if identity, err := Authenticate([]Authenticator{down, accepts}, "user1", "password"); err != nil || identity != user {
+		t.Errorf("expected an error to fall through to the next authenticator, actual %v %v", identity, err)
+	}
+	if identity, err := Authenticate([]Authenticator{rejects}, "user1", "password"); err != nil || identity != nil {
+		t.Errorf("expected no identity and no error when rejected, actual %v %v", identity, err)
+	}
+	if identity, err := Authenticate([]Authenticator{rejects, down}, "user1", "password"); err == nil || identity != nil {
+		t.Errorf("expected an error when rejected and an authenticator failed, actual %v %v", identity, err)
+	}
+}
+
+func TestLocalAuthenticator(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	hashed, err := DerivePassword("password")
+	if err != nil {
+		t.Fatalf("deriving password: %v", err)
+	}
+	a := LocalAuthenticator{DB: db}
+
+	mock.ExpectQuery("SELECT local_passwd").WithArgs("user1").WillReturnRows(sqlmock.NewRows([]string{"local_passwd"}).AddRow(hashed))
+	if identity, err := a.Authenticate("user1", "password"); err != nil || identity == nil || identity.UserName != "user1" {
+		t.Errorf("expected valid password to authenticate, actual %v %v", identity, err)
+	}
+	mock.ExpectQuery("SELECT local_passwd").WithArgs("user1").WillReturnRows(sqlmock.NewRows([]string{"local_passwd"}).AddRow(hashed))
+	if identity, err := a.Authenticate("user1", "wrong"); err != nil || identity != nil {
+		t.Errorf("expected wrong password not to authenticate, actual %v %v", identity, err)
+	}
+	mock.ExpectQuery("SELECT local_passwd").WithArgs("ldapuser").WillReturnRows(sqlmock.NewRows([]string{"local_passwd"}).AddRow(nil))
+	if identity, err := a.Authenticate("ldapuser", ""); err != nil || identity != nil {
+		t.Errorf("expected user without a local password not to authenticate, actual %v %v", identity, err)
+	}
+	mock.ExpectQuery("SELECT local_passwd").WithArgs("nobody").WillReturnRows(sqlmock.NewRows([]string{"local_passwd"}))
+	if identity, err := a.Authenticate("nobody", "password"); err != nil || identity != nil {
+		t.Errorf("expected unknown user not to authenticate, actual %v %v", identity, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestVerifyLocalPassword(t *testing.T) {
+	hashed, err := DerivePassword("password")
+	if err != nil {
+		t.Fatalf("deriving password: %v", err)
+	}
+	if !verifyLocalPassword("password", hashed) {
+		t.Error("expected scrypt password to verify")
+	}
+	if verifyLocalPassword("wrong", hashed) {
+		t.Error("expected wrong scrypt password not to verify")
+	}
+	if verifyLocalPassword("password", "SCRYPT:16384:8") {
+		t.Error("expected malformed scrypt key not to verify")
+	}
+
+	// sha1_hex('password'), as created by old versions of Traffic Ops
+	sha1Passwd := "5baa61e4c9b93f3f0682250b6cf8331b7ee68fd8"
+	if !verifyLocalPassword("password", sha1Passwd) {
+		t.Error("expected SHA1 password to verify")
+	}
+	if verifyLocalPassword("wrong", sha1Passwd) {
+		t.Error("expected wrong SHA1 password not to verify")
+	}
+}
@@ -27,6 +27,7 @@ import (
 
 	"github.com/apache/incubator-trafficcontrol/lib/go-log"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 type CurrentUser struct {
@@ -34,6 +35,8 @@ type CurrentUser struct {
 	ID        int    `json:"id" db:"id"`
 	PrivLevel int    `json:"privLevel" db:"priv_level"`
 	TenantID  int    `json:"tenantId" db:"tenant_id"`
+	// Capabilities are the names of the capabilities of the user's role. See HasCapability.
+	Capabilities pq.StringArray `json:"capabilities" db:"capabilities"`
 }
 
 // PrivLevelInvalid - The Default Priv level
@@ -60,17 +63,17 @@ type key int
 
 const CurrentUserKey key = iota
 
-// GetCurrentUserFromDB  - returns the id and privilege level of the given user along with the username, or -1 as the id, - as the userName and PrivLevelInvalid if the user doesn't exist.
+// GetCurrentUserFromDB  - returns the id, privilege level, and capabilities of the given user along with the username, or -1 as the id, - as the userName and PrivLevelInvalid if the user doesn't exist.
 func GetCurrentUserFromDB(CurrentUserStmt *sqlx.Stmt, user string) CurrentUser {
 	var currentUserInfo CurrentUser
 	err := CurrentUserStmt.Get(&currentUserInfo, user)
 	switch {
 	case err == sql.ErrNoRows:
 		log.Errorf("checking user %v info: user not in database", user)
-		return CurrentUser{"-", -1, PrivLevelInvalid, TenantIDInvalid}
+		return CurrentUser{"-", -1, PrivLevelInvalid, TenantIDInvalid, nil}
 	case err != nil:
 		log.Errorf("Error checking user %v info: %v", user, err.Error())
-		return CurrentUser{"-", -1, PrivLevelInvalid, TenantIDInvalid}
+		return CurrentUser{"-", -1, PrivLevelInvalid, TenantIDInvalid, nil}
 	default:
 		return currentUserInfo
 	}
@@ -86,5 +89,5 @@ func GetCurrentUser(ctx context.Context) (*CurrentUser, error) {
 			return nil, fmt.Errorf("CurrentUser found with bad type: %T", v)
 		}
 	}
-	return &CurrentUser{"-", -1, PrivLevelInvalid, TenantIDInvalid}, errors.New("No user found in Context")
+	return &CurrentUser{"-", -1, PrivLevelInvalid, TenantIDInvalid, nil}, errors.New("No user found in Context")
 }
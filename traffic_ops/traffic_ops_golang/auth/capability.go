@@ -0,0 +1,50 @@
+package auth
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"strings"
+)
+
+// CapabilityAllRead is granted every read capability, that is, every capability ending in CapabilityReadSuffix.
+const CapabilityAllRead = "all-read"
+
+// CapabilityAllWrite is granted every capability which isn't a read capability.
+const CapabilityAllWrite = "all-write"
+
+// CapabilityReadSuffix is the suffix of capabilities which only permit reading.
+const CapabilityReadSuffix = "-read"
+
+// HasCapability returns whether the user's role has the given capability, either directly, or by CapabilityAllRead or CapabilityAllWrite. The empty capability is required by unauthenticated routes, and every user has it.
+func (u CurrentUser) HasCapability(capability string) bool {
+	if capability == "" {
+		return true
+	}
+	wildcard := CapabilityAllWrite
+	if strings.HasSuffix(capability, CapabilityReadSuffix) {
+		wildcard = CapabilityAllRead
+	}
+	for _, c := range u.Capabilities {
+		if c == capability || c == wildcard {
+			return true
+		}
+	}
+	return false
+}
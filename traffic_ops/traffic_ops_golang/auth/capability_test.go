@@ -0,0 +1,51 @@
+package auth
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"testing"
+)
+
+func TestHasCapability(t *testing.T) {
+	type testCase struct {
+		caps     []string
+		cap      string
+		expected bool
+	}
+	testCases := []testCase{
+		{nil, "", true},
+		{nil, "server-read", false},
+		{[]string{"server-read"}, "server-read", true},
+		{[]string{"server-read"}, "server-write", false},
+		{[]string{"server-write"}, "server-read", false},
+		{[]string{CapabilityAllRead}, "server-read", true},
+		{[]string{CapabilityAllRead}, "server-write", false},
+		{[]string{CapabilityAllWrite}, "server-write", true},
+		{[]string{CapabilityAllWrite}, "ds-request-assign", true},
+		{[]string{CapabilityAllWrite}, "server-read", false},
+		{[]string{CapabilityAllRead, CapabilityAllWrite}, "cdn-config-snapshot-write", true},
+	}
+	for _, tc := range testCases {
+		u := CurrentUser{Capabilities: tc.caps}
+		if actual := u.HasCapability(tc.cap); actual != tc.expected {
+			t.Errorf("HasCapability(%v) with capabilities %v expected %v, actual %v", tc.cap, tc.caps, tc.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package auth
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/config"
+
+	ldap "gopkg.in/ldap.v2"
+)
+
+const (
+	ldapSchemePrefix   = "ldap://"
+	ldapDefaultPort    = "389"
+	ldapDefaultTLSPort = "636"
+)
+
+// LDAPAuthenticator checks passwords like the Perl Traffic Ops: it binds as the admin to search for the user's DN, and binds as the user with the password. The user's groups are mapped to a role and tenant.
+type LDAPAuthenticator struct {
+	Cfg config.ConfigLDAP
+	// rootCAs verify the server's certificate. If nil, the system roots are used.
+	rootCAs *x509.CertPool
+}
+
+func (a LDAPAuthenticator) Name() string { return config.AuthenticatorLDAP }
+
+func (a LDAPAuthenticator) Authenticate(username string, password string) (*Identity, error) {
+	if username == "" || password == "" {
+		return nil, nil // LDAP servers accept binds without passwords as unauthenticated
+	}
+	conn, err := a.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if a.Cfg.AdminDN != "" {
+		if err := conn.Bind(a.Cfg.AdminDN, a.Cfg.AdminPass); err != nil {
+			return nil, errors.New("binding as admin: " + err.Error())
+		}
+	}
+	filter := strings.Replace(a.Cfg.SearchQuery, "%s", ldap.EscapeFilter(username), -1)
+	search := ldap.NewSearchRequest(a.Cfg.SearchBase, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 2, a.Cfg.TimeoutSecs, false, filter, []string{a.Cfg.GroupAttribute}, nil)
+	result, err := conn.Search(search)
+	if err != nil && !ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) {
+		return nil, errors.New("searching for user: " + err.Error())
+	}
+	switch {
+	case err != nil || len(result.Entries) > 1:
+		return nil, errors.New("search for user '" + username + "' matched multiple entries")
+	case len(result.Entries) == 0:
+		return nil, nil
+	}
+	entry := result.Entries[0]
+
+	if err := conn.Bind(entry.DN, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, nil
+		}
+		return nil, errors.New("binding as user: " + err.Error())
+	}
+	role, tenant := a.groupRole(ldapAttributeValues(entry, a.Cfg.GroupAttribute))
+	return &Identity{UserName: username, Role: role, Tenant: tenant, Provision: a.Cfg.Provision && role != ""}, nil
+}
+
+// dial connects to the LDAP server, over TLS for ldaps:// hosts, or upgraded with StartTLS if configured. Unencrypted connections are refused unless AllowCleartext is set. The timeout bounds the whole login, including the TLS handshakes.
+func (a LDAPAuthenticator) dial() (*ldap.Conn, error) {
+	addr, useTLS := ldapAddr(a.Cfg.Host)
+	if !useTLS && !a.Cfg.StartTLS && !a.Cfg.AllowCleartext {
+		return nil, errors.New("LDAP host '" + a.Cfg.Host + "' must be ldaps:// or use start_tls, unless allow_cleartext is set")
+	}
+	serverName, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, errors.New("parsing LDAP host '" + a.Cfg.Host + "': " + err.Error())
+	}
+	tlsCfg := &tls.Config{ServerName: serverName, RootCAs: a.rootCAs, InsecureSkipVerify: a.Cfg.Insecure}
+
+	timeout := time.Duration(a.Cfg.TimeoutSecs) * time.Second
+	netConn, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		return nil, errors.New("connecting to LDAP server '" + addr + "': " + err.Error())
+	}
+	netConn.SetDeadline(time.Now().Add(timeout))
+	if useTLS {
+		tlsConn := tls.Client(netConn, tlsCfg)
+		if err := tlsConn.Handshake(); err != nil {
+			netConn.Close()
+			return nil, errors.New("TLS handshake with LDAP server '" + addr + "': " + err.Error())
+		}
+		netConn = tlsConn
+	}
+
+	conn := ldap.NewConn(netConn, useTLS)
+	conn.Start()
+	conn.SetTimeout(timeout)
+	if !useTLS && a.Cfg.StartTLS {
+		if err := conn.StartTLS(tlsCfg); err != nil {
+			conn.Close()
+			return nil, errors.New("starting TLS with LDAP server '" + addr + "': " + err.Error())
+		}
+	}
+	return conn, nil
+}
+
+// ldapAddr returns the host:port of the LDAP host, which is host:port, ldap://host:port, or ldaps://host:port, and whether it's connected to over TLS.
+func ldapAddr(host string) (string, bool) {
+	useTLS := false
+	port := ldapDefaultPort
+	switch lower := strings.ToLower(host); {
+	case strings.HasPrefix(lower, config.LDAPTLSScheme):
+		host = host[len(config.LDAPTLSScheme):]
+		useTLS = true
+		port = ldapDefaultTLSPort
+	case strings.HasPrefix(lower, ldapSchemePrefix):
+		host = host[len(ldapSchemePrefix):]
+	}
+	host = strings.TrimSuffix(host, "/")
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		host = net.JoinHostPort(strings.Trim(host, "[]"), port)
+	}
+	return host, useTLS
+}
+
+// ldapAttributeValues returns the values of the entry's attribute. Attribute names are case insensitive.
+func ldapAttributeValues(entry *ldap.Entry, name string) []string {
+	for _, attr := range entry.Attributes {
+		if strings.EqualFold(attr.Name, name) {
+			return attr.Values
+		}
+	}
+	return nil
+}
+
+// groupRole returns the role and tenant of the first group mapping whose group is one of the given groups, or the default role and tenant. Group DNs are case insensitive.
+func (a LDAPAuthenticator) groupRole(groups []string) (string, string) {
+	for _, mapping := range a.Cfg.GroupMappings {
+		for _, group := range groups {
+			if strings.EqualFold(mapping.Group, group) {
+				return mapping.Role, mapping.Tenant
+			}
+		}
+	}
+	return a.Cfg.DefaultRole, a.Cfg.DefaultTenant
+}
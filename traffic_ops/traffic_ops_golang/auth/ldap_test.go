@@ -0,0 +1,518 @@
+package auth
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/config"
+
+	ber "gopkg.in/asn1-ber.v1"
+	ldap "gopkg.in/ldap.v2"
+)
+
+// testLDAPEntry is an entry of the LDAP stand-in, with its attribute values by name.
+type testLDAPEntry struct {
+	DN       string
+	Password string
+	Attrs    map[string][]string
+}
+
+// testLDAPMode is how the LDAP stand-in encrypts connections.
+type testLDAPMode int
+
+const (
+	testLDAPCleartext testLDAPMode = iota // no TLS, and StartTLS is rejected
+	testLDAPTLS                           // ldaps://
+	testLDAPStartTLS                      // no TLS until StartTLS
+)
+
+// testLDAPServer is an in-process LDAP stand-in, which serves simple binds, searches of its entries, and StartTLS. Searches require binding first, like Active Directory.
+type testLDAPServer struct {
+	ln        net.Listener
+	mode      testLDAPMode
+	tlsCfg    *tls.Config
+	rootCAs   *x509.CertPool
+	entries   []testLDAPEntry
+	mutex     sync.Mutex
+	filters   []string
+	cleartext bool
+}
+
+func newTestLDAPServer(t *testing.T, mode testLDAPMode, entries ...testLDAPEntry) *testLDAPServer {
+	cert, rootCAs := testLDAPCertificate(t)
+	tlsCfg := &tls.Config{Certificates: []tls.Certificate{cert}}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listening: %v", err)
+	}
+	if mode == testLDAPTLS {
+		ln = tls.NewListener(ln, tlsCfg)
+	}
+	s := &testLDAPServer{ln: ln, mode: mode, tlsCfg: tlsCfg, rootCAs: rootCAs, entries: entries}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+// testLDAPCertificate returns a self-signed certificate for 127.0.0.1, and a pool which trusts it.
+func testLDAPCertificate(t *testing.T) (tls.Certificate, *x509.CertPool) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "127.0.0.1"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("creating certificate: %v", err)
+	}
+	parsed, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parsing certificate: %v", err)
+	}
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(parsed)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, rootCAs
+}
+
+func (s *testLDAPServer) Addr() string { return s.ln.Addr().String() }
+
+func (s *testLDAPServer) Close() { s.ln.Close() }
+
+// Cleartext returns whether the server received a bind or search over an unencrypted connection.
+func (s *testLDAPServer) Cleartext() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.cleartext
+}
+
+func (s *testLDAPServer) serve(conn net.Conn) {
+	defer func() { conn.Close() }()
+	encrypted := s.mode == testLDAPTLS
+	bound := false
+	for {
+		msg, err := ber.ReadPacket(conn)
+		if err != nil || len(msg.Children) < 2 {
+			return
+		}
+		msgID := msg.Children[0].Value
+		op := msg.Children[1]
+		respond := func(resp *ber.Packet) {
+			envelope := ber.NewSequence("LDAP Response")
+			envelope.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, msgID, "MessageID"))
+			envelope.AppendChild(resp)
+			conn.Write(envelope.Bytes())
+		}
+		result := func(tag ber.Tag, code int) *ber.Packet {
+			p := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "Result")
+			p.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, code, "resultCode"))
+			p.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "matchedDN"))
+			p.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "diagnosticMessage"))
+			return p
+		}
+		if !encrypted && (op.Tag == ldap.ApplicationBindRequest || op.Tag == ldap.ApplicationSearchRequest) {
+			s.mutex.Lock()
+			s.cleartext = true
+			s.mutex.Unlock()
+		}
+
+		switch op.Tag {
+		case ldap.ApplicationUnbindRequest:
+			return
+		case ldap.ApplicationExtendedRequest:
+			if s.mode != testLDAPStartTLS || encrypted {
+				respond(result(ldap.ApplicationExtendedResponse, ldap.LDAPResultProtocolError))
+				continue
+			}
+			respond(result(ldap.ApplicationExtendedResponse, ldap.LDAPResultSuccess))
+			tlsConn := tls.Server(conn, s.tlsCfg)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn = tlsConn
+			encrypted = true
+		case ldap.ApplicationBindRequest:
+			dn, pass := op.Children[1].Value.(string), op.Children[2].Data.String()
+			bound = false
+			for _, entry := range s.entries {
+				if entry.DN == dn && entry.Password == pass && pass != "" {
+					bound = true
+				}
+			}
+			code := ldap.LDAPResultSuccess
+			if !bound {
+				code = ldap.LDAPResultInvalidCredentials
+			}
+			respond(result(ldap.ApplicationBindResponse, code))
+		case ldap.ApplicationSearchRequest:
+			if !bound {
+				respond(result(ldap.ApplicationSearchResultDone, ldap.LDAPResultInsufficientAccessRights))
+				continue
+			}
+			base := strings.ToLower(op.Children[0].Value.(string))
+			sizeLimit := op.Children[3].Value.(int64)
+			filter := op.Children[6]
+			decompiled, err := ldap.DecompileFilter(filter)
+			if err != nil {
+				return
+			}
+			s.mutex.Lock()
+			s.filters = append(s.filters, decompiled)
+			s.mutex.Unlock()
+			attrs := []string{}
+			for _, attr := range op.Children[7].Children {
+				attrs = append(attrs, attr.Value.(string))
+			}
+			code := ldap.LDAPResultSuccess
+			sent := int64(0)
+			for _, entry := range s.entries {
+				if !strings.HasSuffix(strings.ToLower(entry.DN), base) || !matchTestLDAPFilter(filter, entry) {
+					continue
+				}
+				if sizeLimit > 0 && sent == sizeLimit {
+					code = ldap.LDAPResultSizeLimitExceeded
+					break
+				}
+				resp := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "Search Result Entry")
+				resp.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, entry.DN, "objectName"))
+				attrPackets := ber.NewSequence("attributes")
+				for _, attr := range attrs {
+					attrPacket := ber.NewSequence("attribute")
+					attrPacket.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, attr, "type"))
+					vals := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "vals")
+					for _, val := range entry.Attrs[attr] {
+						vals.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, val, "value"))
+					}
+					attrPacket.AppendChild(vals)
+					attrPackets.AppendChild(attrPacket)
+				}
+				resp.AppendChild(attrPackets)
+				respond(resp)
+				sent++
+			}
+			respond(result(ldap.ApplicationSearchResultDone, code))
+		default:
+			return
+		}
+	}
+}
+
+// matchTestLDAPFilter evaluates a compiled filter against an entry, with case insensitive matching.
+func matchTestLDAPFilter(f *ber.Packet, entry testLDAPEntry) bool {
+	vals := func(attr string) []string {
+		for name, vals := range entry.Attrs {
+			if strings.EqualFold(name, attr) {
+				return vals
+			}
+		}
+		return nil
+	}
+	switch f.Tag {
+	case ldap.FilterAnd:
+		for _, child := range f.Children {
+			if !matchTestLDAPFilter(child, entry) {
+				return false
+			}
+		}
+		return true
+	case ldap.FilterOr:
+		for _, child := range f.Children {
+			if matchTestLDAPFilter(child, entry) {
+				return true
+			}
+		}
+		return false
+	case ldap.FilterNot:
+		return !matchTestLDAPFilter(f.Children[0], entry)
+	case ldap.FilterPresent:
+		return len(vals(f.Data.String())) > 0
+	case ldap.FilterEqualityMatch:
+		for _, val := range vals(f.Children[0].Data.String()) {
+			if strings.EqualFold(val, f.Children[1].Data.String()) {
+				return true
+			}
+		}
+		return false
+	case ldap.FilterSubstrings:
+	vals:
+		for _, val := range vals(f.Children[0].Data.String()) {
+			val = strings.ToLower(val)
+			for _, sub := range f.Children[1].Children {
+				subVal := strings.ToLower(sub.Data.String())
+				switch sub.Tag {
+				case ldap.FilterSubstringsInitial:
+					if !strings.HasPrefix(val, subVal) {
+						continue vals
+					}
+					val = val[len(subVal):]
+				case ldap.FilterSubstringsFinal:
+					if !strings.HasSuffix(val, subVal) {
+						continue vals
+					}
+				default:
+					i := strings.Index(val, subVal)
+					if i < 0 {
+						continue vals
+					}
+					val = val[i+len(subVal):]
+				}
+			}
+			return true
+		}
+		return false
+	}
+	return false
+}
+
+func testLDAPConfig(host string) config.ConfigLDAP {
+	return config.ConfigLDAP{
+		Host:           host,
+		AdminDN:        "cn=admin,dc=example,dc=net",
+		AdminPass:      "adminpass",
+		SearchBase:     "dc=example,dc=net",
+		SearchQuery:    config.LDAPSearchQueryDefault,
+		GroupAttribute: config.LDAPGroupAttributeDefault,
+		TimeoutSecs:    5,
+		AllowCleartext: true,
+		GroupMappings: []config.ConfigLDAPGroupMapping{
+			{Group: "cn=cdn-admins,ou=groups,dc=example,dc=net", Role: "admin", Tenant: "root"},
+			{Group: "cn=cdn-ops,ou=groups,dc=example,dc=net", Role: "operations", Tenant: "ops"},
+		},
+		DefaultRole: "read-only",
+		Provision:   true,
+	}
+}
+
+func testLDAPUser(name string, password string, groups ...string) testLDAPEntry {
+	return testLDAPEntry{
+		DN:       "cn=" + name + ",ou=people,dc=example,dc=net",
+		Password: password,
+		Attrs: map[string][]string{
+			"objectCategory": {"person"},
+			"objectClass":    {"top", "user"},
+			"sAMAccountName": {name},
+			"memberOf":       groups,
+		},
+	}
+}
+
+func TestLDAPAuthenticator(t *testing.T) {
+	s := newTestLDAPServer(t, testLDAPTLS,
+		testLDAPEntry{DN: "cn=admin,dc=example,dc=net", Password: "adminpass"},
+		testLDAPUser("alice", "alicepass", "cn=staff,ou=groups,dc=example,dc=net", "CN=CDN-Ops,OU=Groups,DC=example,DC=net"),
+		testLDAPUser("bob", "bobpass", "cn=staff,ou=groups,dc=example,dc=net"),
+		testLDAPUser("carol", "carolpass", "cn=cdn-admins,ou=groups,dc=example,dc=net"),
+	)
+	defer s.Close()
+	a := LDAPAuthenticator{Cfg: testLDAPConfig("ldaps://" + s.Addr()), rootCAs: s.rootCAs}
+	a.Cfg.AllowCleartext = false
+
+	tests := []struct {
+		name     string
+		user     string
+		pass     string
+		expected *Identity
+	}{
+		{"mapped group", "alice", "alicepass", &Identity{UserName: "alice", Role: "operations", Tenant: "ops", Provision: true}},
+		{"first mapping", "carol", "carolpass", &Identity{UserName: "carol", Role: "admin", Tenant: "root", Provision: true}},
+		{"default role", "bob", "bobpass", &Identity{UserName: "bob", Role: "read-only", Provision: true}},
+		{"wrong password", "alice", "bobpass", nil},
+		{"empty password", "alice", "", nil},
+		{"unknown user", "mallory", "alicepass", nil},
+		{"wildcard user", "*", "alicepass", nil},
+		{"injected filter", "alice)(sAMAccountName=*", "alicepass", nil},
+	}
+	for _, test := range tests {
+		identity, err := a.Authenticate(test.user, test.pass)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		if (identity == nil) != (test.expected == nil) || (identity != nil && *identity != *test.expected) {
+			t.Errorf("%s: expected identity %+v, actual %+v", test.name, test.expected, identity)
+		}
+	}
+
+	s.mutex.Lock()
+	lastFilter := s.filters[len(s.filters)-1]
+	s.mutex.Unlock()
+	if expected := `(&(objectCategory=person)(objectClass=user)(sAMAccountName=alice\29\28sAMAccountName=\2a))`; lastFilter != expected {
+		t.Errorf("expected the username to be escaped in the search filter %s, actual %s", expected, lastFilter)
+	}
+
+	a.Cfg.Provision = false
+	a.Cfg.DefaultRole = ""
+	if identity, err := a.Authenticate("bob", "bobpass"); err != nil || identity == nil || identity.Role != "" || identity.Provision {
+		t.Errorf("expected a user without a mapped or default role not to be provisioned, actual %+v %v", identity, err)
+	}
+
+	a.Cfg.AdminPass = "wrong"
+	if _, err := a.Authenticate("alice", "alicepass"); err == nil {
+		t.Error("expected an error binding with the wrong admin password")
+	}
+}
+
+func TestLDAPAuthenticatorMultipleEntries(t *testing.T) {
+	s := newTestLDAPServer(t, testLDAPCleartext,
+		testLDAPEntry{DN: "cn=admin,dc=example,dc=net", Password: "adminpass"},
+		testLDAPUser("alice", "alicepass"),
+		testLDAPEntry{DN: "cn=alice,ou=contractors,dc=example,dc=net", Password: "other", Attrs: testLDAPUser("alice", "").Attrs},
+	)
+	defer s.Close()
+	a := LDAPAuthenticator{Cfg: testLDAPConfig(s.Addr())}
+	if _, err := a.Authenticate("alice", "alicepass"); err == nil {
+		t.Error("expected an error when the search matches multiple entries")
+	}
+}
+
+func TestLDAPAuthenticatorUnavailable(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listening: %v", err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	a := LDAPAuthenticator{Cfg: testLDAPConfig(addr)}
+	if _, err := a.Authenticate("alice", "alicepass"); err == nil {
+		t.Error("expected an error when the LDAP server is unavailable")
+	}
+}
+
+func TestLDAPAuthenticatorStartTLS(t *testing.T) {
+	s := newTestLDAPServer(t, testLDAPStartTLS,
+		testLDAPEntry{DN: "cn=admin,dc=example,dc=net", Password: "adminpass"},
+		testLDAPUser("alice", "alicepass"),
+	)
+	defer s.Close()
+	a := LDAPAuthenticator{Cfg: testLDAPConfig("ldap://" + s.Addr()), rootCAs: s.rootCAs}
+	a.Cfg.AllowCleartext = false
+	a.Cfg.StartTLS = true
+	if identity, err := a.Authenticate("alice", "alicepass"); err != nil || identity == nil {
+		t.Errorf("expected to authenticate over StartTLS, actual %+v %v", identity, err)
+	}
+	if s.Cleartext() {
+		t.Error("expected no requests before StartTLS to be sent in cleartext")
+	}
+
+	plain := newTestLDAPServer(t, testLDAPCleartext,
+		testLDAPEntry{DN: "cn=admin,dc=example,dc=net", Password: "adminpass"},
+		testLDAPUser("alice", "alicepass"),
+	)
+	defer plain.Close()
+	a.Cfg.Host = "ldap://" + plain.Addr()
+	if _, err := a.Authenticate("alice", "alicepass"); err == nil {
+		t.Error("expected an error when the server doesn't support StartTLS")
+	}
+	if plain.Cleartext() {
+		t.Error("expected not to fall back to binding in cleartext when StartTLS fails")
+	}
+}
+
+func TestLDAPAuthenticatorCleartext(t *testing.T) {
+	s := newTestLDAPServer(t, testLDAPCleartext,
+		testLDAPEntry{DN: "cn=admin,dc=example,dc=net", Password: "adminpass"},
+		testLDAPUser("alice", "alicepass"),
+	)
+	defer s.Close()
+	a := LDAPAuthenticator{Cfg: testLDAPConfig("ldap://" + s.Addr())}
+	a.Cfg.AllowCleartext = false
+	if _, err := a.Authenticate("alice", "alicepass"); err == nil {
+		t.Error("expected an error binding in cleartext without allow_cleartext")
+	}
+	if s.Cleartext() {
+		t.Error("expected no binds in cleartext without allow_cleartext")
+	}
+
+	a.Cfg.AllowCleartext = true
+	if identity, err := a.Authenticate("alice", "alicepass"); err != nil || identity == nil {
+		t.Errorf("expected to authenticate in cleartext with allow_cleartext, actual %+v %v", identity, err)
+	}
+}
+
+func TestLDAPAuthenticatorCertificate(t *testing.T) {
+	s := newTestLDAPServer(t, testLDAPTLS,
+		testLDAPEntry{DN: "cn=admin,dc=example,dc=net", Password: "adminpass"},
+		testLDAPUser("alice", "alicepass"),
+	)
+	defer s.Close()
+	a := LDAPAuthenticator{Cfg: testLDAPConfig("ldaps://" + s.Addr())}
+	if _, err := a.Authenticate("alice", "alicepass"); err == nil {
+		t.Error("expected an error when the server's certificate isn't trusted")
+	}
+
+	a.Cfg.Insecure = true
+	if identity, err := a.Authenticate("alice", "alicepass"); err != nil || identity == nil {
+		t.Errorf("expected to authenticate without verifying the certificate with insecure, actual %+v %v", identity, err)
+	}
+}
+
+func TestLDAPAddr(t *testing.T) {
+	tests := []struct {
+		host   string
+		addr   string
+		useTLS bool
+	}{
+		{"ldap.example.net:3389", "ldap.example.net:3389", false},
+		{"ldap://ldap.example.net", "ldap.example.net:389", false},
+		{"ldaps://ldap.example.net/", "ldap.example.net:636", true},
+		{"LDAPS://ldap.example.net:6636", "ldap.example.net:6636", true},
+		{"ldaps://[::1]", "[::1]:636", true},
+	}
+	for _, test := range tests {
+		if addr, useTLS := ldapAddr(test.host); addr != test.addr || useTLS != test.useTLS {
+			t.Errorf("%s: expected %s %v, actual %s %v", test.host, test.addr, test.useTLS, addr, useTLS)
+		}
+	}
+}
+
+func TestEscapeLDAPFilter(t *testing.T) {
+	if actual := ldap.EscapeFilter(`a*(b)\c` + "\x00"); actual != `a\2a\28b\29\5cc\00` {
+		t.Errorf("expected escaped filter value, actual %s", actual)
+	}
+}
@@ -0,0 +1,74 @@
+package auth
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// StaleSessionAge is how long after a session was last seen it's deleted. Sessions are only seen when they aren't in the UserCache, so this must be well over the cookie duration plus the cache TTL.
+const StaleSessionAge = 24 * time.Hour
+
+// CheckSessionQuery is the query of whether the session $1 exists and belongs to the user named $2, marking the session as seen. It's prepared once, for CheckSession.
+const CheckSessionQuery = `UPDATE user_session AS s SET last_seen = now() FROM tm_user AS u WHERE s.id = $1 AND s.tm_user = u.id AND u.username = $2 RETURNING s.id`
+
+// CreateSession creates a server-side session for the given user, and returns its ID, which is stored in the user's cookie. Stale sessions of all users are deleted.
+func CreateSession(db *sqlx.DB, username string, remoteAddr string, userAgent string) (string, error) {
+	id, err := GenerateToken()
+	if err != nil {
+		return "", err
+	}
+	if _, err := db.Exec(`DELETE FROM user_session WHERE last_seen < now() - $1 * interval '1 second'`, int(StaleSessionAge/time.Second)); err != nil {
+		return "", errors.New("deleting stale sessions: " + err.Error())
+	}
+	q := `INSERT INTO user_session (id, tm_user, remote_addr, user_agent) SELECT $1, id, $3, $4 FROM tm_user WHERE username = $2`
+	result, err := db.Exec(q, id, username, remoteAddr, userAgent)
+	if err != nil {
+		return "", errors.New("inserting session: " + err.Error())
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return "", errors.New("inserting session: user '" + username + "' not found")
+	}
+	return id, nil
+}
+
+// CheckSession returns whether the session exists and belongs to the given user, using the prepared CheckSessionQuery. Sessions which were revoked or went stale don't exist.
+func CheckSession(checkSessionStmt *sqlx.Stmt, sessionID string, username string) (bool, error) {
+	id := ""
+	if err := checkSessionStmt.QueryRow(sessionID, username).Scan(&id); err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, errors.New("checking session: " + err.Error())
+	}
+	return true, nil
+}
+
+// DeleteSession revokes a session. Deleting a session which doesn't exist isn't an error.
+func DeleteSession(db *sqlx.DB, sessionID string) error {
+	if _, err := db.Exec(`DELETE FROM user_session WHERE id = $1`, sessionID); err != nil {
+		return errors.New("deleting session: " + err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package auth
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// TokenLen is the number of random bytes in an API token.
+const TokenLen = 32
+
+// BearerPrefix is the prefix of an API token in the Authorization header.
+const BearerPrefix = "Bearer "
+
+// GenerateToken returns a new random API token.
+func GenerateToken() (string, error) {
+	b := make([]byte, TokenLen)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.New("generating token: " + err.Error())
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashToken returns the hash of an API token, which is stored instead of the token. Unlike passwords, tokens are long and random, so they don't need a slow salted hash like scrypt, and an unsalted hash lets tokens be looked up by their hash.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// GetBearerToken returns the API token of the request's Authorization header, and false if the request has no bearer token.
+func GetBearerToken(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, BearerPrefix) {
+		return "", false
+	}
+	token := strings.TrimSpace(header[len(BearerPrefix):])
+	return token, token != ""
+}
+
+// CheckTokenQuery is the query of the user name and expiry of the unexpired API token with the hash $1. It's prepared once, for CheckToken.
+const CheckTokenQuery = `SELECT u.username, t.expires FROM api_token AS t JOIN tm_user AS u ON t.tm_user = u.id WHERE t.token_hash = $1 AND (t.expires IS NULL OR t.expires > now())`
+
+// CheckToken returns the name of the user of the API token with the given hash, and when the token expires, which is zero if it never does, using the prepared CheckTokenQuery. If the token doesn't exist or expired, false is returned.
+func CheckToken(checkTokenStmt *sqlx.Stmt, tokenHash string) (string, time.Time, bool, error) {
+	username := ""
+	expires := (*time.Time)(nil)
+	if err := checkTokenStmt.QueryRow(tokenHash).Scan(&username, &expires); err != nil {
+		if err == sql.ErrNoRows {
+			return "", time.Time{}, false, nil
+		}
+		return "", time.Time{}, false, errors.New("checking token: " + err.Error())
+	}
+	if expires == nil {
+		return username, time.Time{}, true, nil
+	}
+	return username, *expires, true, nil
+}
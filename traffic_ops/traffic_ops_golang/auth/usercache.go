@@ -0,0 +1,224 @@
+package auth
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-log"
+	"github.com/lib/pq"
+)
+
+// AuthChangeChannel is the Postgres notification channel on which database triggers announce changes to users, roles, and sessions.
+// The payload is AuthChangeUserPrefix followed by a username, AuthChangeSessionPrefix followed by a session ID, AuthChangeTokenPrefix followed by an API token hash, or empty if anything may have changed.
+const AuthChangeChannel = "auth_change"
+
+const AuthChangeUserPrefix = "user:"
+const AuthChangeSessionPrefix = "session:"
+const AuthChangeTokenPrefix = "token:"
+
+// UserCacheTTLDisabled is a TTL which disables the UserCache, so every lookup queries the database.
+const UserCacheTTLDisabled = time.Duration(0)
+
+// UserCache caches the users, sessions and API tokens of authenticated requests, so they don't query the database on every request.
+// Entries expire after the TTL, and are removed sooner when the database notifies that their user, role, or session changed. See Listen.
+// Only found users and valid sessions and tokens are cached, so new users, sessions and tokens are never missed.
+// A nil *UserCache is valid, and caches nothing.
+type UserCache struct {
+	ttl      time.Duration
+	m        sync.RWMutex
+	users    map[string]cachedUser
+	sessions map[string]time.Time
+	tokens   map[string]cachedToken
+}
+
+type cachedUser struct {
+	user    CurrentUser
+	expires time.Time
+}
+
+type cachedToken struct {
+	username string
+	expires  time.Time
+}
+
+// NewUserCache returns a cache whose entries expire after the given TTL. If the TTL is UserCacheTTLDisabled, nil is returned, which caches nothing.
+func NewUserCache(ttl time.Duration) *UserCache {
+	if ttl <= UserCacheTTLDisabled {
+		return nil
+	}
+	return &UserCache{ttl: ttl, users: map[string]cachedUser{}, sessions: map[string]time.Time{}, tokens: map[string]cachedToken{}}
+}
+
+// GetUser returns the cached user with the given username, or loads and caches it with the given func. Invalid users, with an ID of -1, aren't cached.
+func (c *UserCache) GetUser(username string, load func(username string) CurrentUser) CurrentUser {
+	if c == nil {
+		return load(username)
+	}
+	now := time.Now()
+	c.m.RLock()
+	cached, ok := c.users[username]
+	c.m.RUnlock()
+	if ok && now.Before(cached.expires) {
+		return cached.user
+	}
+	user := load(username)
+	if user.ID == -1 {
+		return user
+	}
+	c.m.Lock()
+	c.users[username] = cachedUser{user: user, expires: now.Add(c.ttl)}
+	c.m.Unlock()
+	return user
+}
+
+// IsSessionValid returns whether the session with the given ID is cached as valid, or checks and caches it with the given func. Invalid sessions aren't cached.
+func (c *UserCache) IsSessionValid(sessionID string, check func(sessionID string) (bool, error)) (bool, error) {
+	if c == nil {
+		return check(sessionID)
+	}
+	now := time.Now()
+	c.m.RLock()
+	expires, ok := c.sessions[sessionID]
+	c.m.RUnlock()
+	if ok && now.Before(expires) {
+		return true, nil
+	}
+	valid, err := check(sessionID)
+	if err != nil || !valid {
+		return valid, err
+	}
+	c.m.Lock()
+	c.sessions[sessionID] = now.Add(c.ttl)
+	c.m.Unlock()
+	return true, nil
+}
+
+// GetTokenUsername returns the name of the user of the API token with the given hash, if it's cached as valid, or checks and caches it with the given func, which also returns when the token expires, or zero if it never does. Tokens are cached until the TTL or their expiry, whichever is sooner. Invalid tokens aren't cached.
+func (c *UserCache) GetTokenUsername(tokenHash string, check func(tokenHash string) (string, time.Time, bool, error)) (string, bool, error) {
+	if c == nil {
+		username, _, valid, err := check(tokenHash)
+		return username, valid, err
+	}
+	now := time.Now()
+	c.m.RLock()
+	cached, ok := c.tokens[tokenHash]
+	c.m.RUnlock()
+	if ok && now.Before(cached.expires) {
+		return cached.username, true, nil
+	}
+	username, tokenExpires, valid, err := check(tokenHash)
+	if err != nil || !valid {
+		return username, valid, err
+	}
+	expires := now.Add(c.ttl)
+	if !tokenExpires.IsZero() && tokenExpires.Before(expires) {
+		expires = tokenExpires
+	}
+	c.m.Lock()
+	c.tokens[tokenHash] = cachedToken{username: username, expires: expires}
+	c.m.Unlock()
+	return username, true, nil
+}
+
+// Invalidate removes the cache entries named by the given AuthChangeChannel notification payload. An empty payload removes everything.
+func (c *UserCache) Invalidate(payload string) {
+	if c == nil {
+		return
+	}
+	c.m.Lock()
+	defer c.m.Unlock()
+	switch {
+	case strings.HasPrefix(payload, AuthChangeUserPrefix):
+		username := strings.TrimPrefix(payload, AuthChangeUserPrefix)
+		delete(c.users, username)
+		// the user's tokens name the user, which may have been renamed or deleted
+		for tokenHash, cached := range c.tokens {
+			if cached.username == username {
+				delete(c.tokens, tokenHash)
+			}
+		}
+	case strings.HasPrefix(payload, AuthChangeSessionPrefix):
+		delete(c.sessions, strings.TrimPrefix(payload, AuthChangeSessionPrefix))
+	case strings.HasPrefix(payload, AuthChangeTokenPrefix):
+		delete(c.tokens, strings.TrimPrefix(payload, AuthChangeTokenPrefix))
+	default:
+		c.users = map[string]cachedUser{}
+		c.sessions = map[string]time.Time{}
+		c.tokens = map[string]cachedToken{}
+	}
+}
+
+// removeExpired removes expired entries, so users, sessions and tokens which aren't used again don't stay in memory.
+func (c *UserCache) removeExpired() {
+	c.m.Lock()
+	defer c.m.Unlock()
+	now := time.Now()
+	for username, cached := range c.users {
+		if !now.Before(cached.expires) {
+			delete(c.users, username)
+		}
+	}
+	for sessionID, expires := range c.sessions {
+		if !now.Before(expires) {
+			delete(c.sessions, sessionID)
+		}
+	}
+	for tokenHash, cached := range c.tokens {
+		if !now.Before(cached.expires) {
+			delete(c.tokens, tokenHash)
+		}
+	}
+}
+
+// Listen listens for AuthChangeChannel notifications on a new connection to the given database, and invalidates the cache entries they name. It never returns, and should be called in a goroutine.
+// Notifications may be missed while the connection is down, so the whole cache is invalidated when it reconnects, and on any listener error.
+func (c *UserCache) Listen(dbConnStr string) {
+	if c == nil {
+		return
+	}
+	listener := pq.NewListener(dbConnStr, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Errorln("user cache listener: " + err.Error())
+			c.Invalidate("")
+		}
+	})
+	if err := listener.Listen(AuthChangeChannel); err != nil {
+		log.Errorln("user cache listening on " + AuthChangeChannel + ": " + err.Error())
+	}
+	for {
+		select {
+		case notification := <-listener.Notify:
+			if notification == nil {
+				// the connection was re-established, and notifications may have been missed
+				c.Invalidate("")
+				continue
+			}
+			c.Invalidate(notification.Extra)
+		case <-time.After(c.ttl):
+			c.removeExpired()
+			if err := listener.Ping(); err != nil {
+				log.Errorln("user cache listener ping: " + err.Error())
+			}
+		}
+	}
+}
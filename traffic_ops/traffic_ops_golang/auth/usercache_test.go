@@ -0,0 +1,175 @@
+package auth
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUserCacheGetUser(t *testing.T) {
+	cache := NewUserCache(time.Minute)
+	loads := 0
+	load := func(username string) CurrentUser {
+		loads++
+		if username == "nobody" {
+			return CurrentUser{ID: -1}
+		}
+		return CurrentUser{UserName: username, ID: 1}
+	}
+
+	for i := 0; i < 2; i++ {
+		if user := cache.GetUser("admin", load); user.UserName != "admin" {
+			t.Errorf("GetUser expected: admin, actual: %+v", user)
+		}
+	}
+	if loads != 1 {
+		t.Errorf("GetUser expected: 1 load of a cached user, actual: %v", loads)
+	}
+
+	cache.Invalidate(AuthChangeUserPrefix + "admin")
+	cache.GetUser("admin", load)
+	if loads != 2 {
+		t.Errorf("GetUser expected: load after user invalidated, actual loads: %v", loads)
+	}
+
+	cache.GetUser("nobody", load)
+	cache.GetUser("nobody", load)
+	if loads != 4 {
+		t.Errorf("GetUser expected: invalid users not cached, actual loads: %v", loads)
+	}
+
+	cache.Invalidate("")
+	cache.GetUser("admin", load)
+	if loads != 5 {
+		t.Errorf("GetUser expected: load after cache flushed, actual loads: %v", loads)
+	}
+}
+
+func TestUserCacheSessions(t *testing.T) {
+	cache := NewUserCache(time.Minute)
+	checks := 0
+	check := func(sessionID string) (bool, error) {
+		checks++
+		return sessionID == "valid", nil
+	}
+
+	for i := 0; i < 2; i++ {
+		if valid, err := cache.IsSessionValid("valid", check); err != nil || !valid {
+			t.Errorf("IsSessionValid expected: valid, actual: %v %v", valid, err)
+		}
+		if valid, err := cache.IsSessionValid("revoked", check); err != nil || valid {
+			t.Errorf("IsSessionValid expected: invalid, actual: %v %v", valid, err)
+		}
+	}
+	if checks != 3 {
+		t.Errorf("IsSessionValid expected: only valid sessions cached, actual checks: %v", checks)
+	}
+
+	cache.Invalidate(AuthChangeSessionPrefix + "valid")
+	cache.IsSessionValid("valid", check)
+	if checks != 4 {
+		t.Errorf("IsSessionValid expected: check after session invalidated, actual checks: %v", checks)
+	}
+}
+
+func TestUserCacheTokens(t *testing.T) {
+	cache := NewUserCache(time.Minute)
+	checks := 0
+	expires := time.Time{}
+	check := func(tokenHash string) (string, time.Time, bool, error) {
+		checks++
+		if tokenHash != "valid" {
+			return "", time.Time{}, false, nil
+		}
+		return "admin", expires, true, nil
+	}
+
+	for i := 0; i < 2; i++ {
+		if username, valid, err := cache.GetTokenUsername("valid", check); err != nil || !valid || username != "admin" {
+			t.Errorf("GetTokenUsername expected: admin, actual: %v %v %v", username, valid, err)
+		}
+		if _, valid, err := cache.GetTokenUsername("revoked", check); err != nil || valid {
+			t.Errorf("GetTokenUsername expected: invalid, actual: %v %v", valid, err)
+		}
+	}
+	if checks != 3 {
+		t.Errorf("GetTokenUsername expected: only valid tokens cached, actual checks: %v", checks)
+	}
+
+	cache.Invalidate(AuthChangeTokenPrefix + "valid")
+	cache.GetTokenUsername("valid", check)
+	if checks != 4 {
+		t.Errorf("GetTokenUsername expected: check after token invalidated, actual checks: %v", checks)
+	}
+
+	cache.Invalidate(AuthChangeUserPrefix + "admin")
+	cache.GetTokenUsername("valid", check)
+	if checks != 5 {
+		t.Errorf("GetTokenUsername expected: check after the token's user invalidated, actual checks: %v", checks)
+	}
+
+	// tokens expiring before the TTL are cached until they expire
+	cache.Invalidate("")
+	expires = time.Now().Add(time.Millisecond)
+	cache.GetTokenUsername("valid", check)
+	time.Sleep(2 * time.Millisecond)
+	cache.GetTokenUsername("valid", check)
+	if checks != 7 {
+		t.Errorf("GetTokenUsername expected: check after token expired, actual checks: %v", checks)
+	}
+}
+
+func TestUserCacheExpiry(t *testing.T) {
+	cache := NewUserCache(time.Nanosecond)
+	loads := 0
+	load := func(username string) CurrentUser {
+		loads++
+		return CurrentUser{UserName: username, ID: 1}
+	}
+	cache.GetUser("admin", load)
+	time.Sleep(time.Millisecond)
+	cache.GetUser("admin", load)
+	if loads != 2 {
+		t.Errorf("GetUser expected: load after expiry, actual loads: %v", loads)
+	}
+	cache.removeExpired()
+	if len(cache.users) != 0 {
+		t.Errorf("removeExpired expected: no users, actual: %v", len(cache.users))
+	}
+}
+
+func TestNilUserCache(t *testing.T) {
+	cache := NewUserCache(UserCacheTTLDisabled)
+	if cache != nil {
+		t.Fatalf("NewUserCache expected: nil when disabled, actual: %+v", cache)
+	}
+	loads := 0
+	load := func(username string) CurrentUser {
+		loads++
+		return CurrentUser{UserName: username, ID: 1}
+	}
+	cache.GetUser("admin", load)
+	cache.GetUser("admin", load)
+	cache.Invalidate("")
+	if loads != 2 {
+		t.Errorf("GetUser expected: nil cache to always load, actual loads: %v", loads)
+	}
+}
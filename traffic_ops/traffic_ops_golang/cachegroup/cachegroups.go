@@ -88,12 +88,12 @@ func (cachegroup *TOCacheGroup) SetID(i int) {
 }
 
 // checks if a cachegroup with the given ID is in use as a parent or secondary parent.
-func isUsedByChildCache(db *sqlx.DB, ID int) (bool, error) {
+func isUsedByChildCache(db sqlx.Queryer, ID int) (bool, error) {
 	pQuery := "SELECT count(*) from cachegroup WHERE parent_cachegroup_id=$1"
 	sQuery := "SELECT count(*) from cachegroup WHERE secondary_parent_cachegroup_id=$1"
 	count := 0
 
-	err := db.QueryRow(pQuery, ID).Scan(&count)
+	err := db.QueryRowx(pQuery, ID).Scan(&count)
 	if err != nil {
 		log.Errorf("received error: %++v from query execution", err)
 		return false, err
@@ -102,7 +102,7 @@ func isUsedByChildCache(db *sqlx.DB, ID int) (bool, error) {
 		return true, errors.New("cache is in use as a parent cache")
 	}
 
-	err = db.QueryRow(sQuery, ID).Scan(&count)
+	err = db.QueryRowx(sQuery, ID).Scan(&count)
 	if err != nil {
 		log.Errorf("received error: %++v from query execution", err)
 		return false, err
@@ -164,13 +164,13 @@ func (cachegroup TOCacheGroup) Validate(db *sqlx.DB) []error {
 // sucessful lookup sets the two ids on the struct.
 //
 // used by Create()
-func getParentCachegroupIDs(db *sqlx.DB, cachegroup *TOCacheGroup) error {
+func getParentCachegroupIDs(db sqlx.Queryer, cachegroup *TOCacheGroup) error {
 	query := `SELECT id FROM cachegroup where name=$1`
 	var parentID int
 	var secondaryParentID int
 
 	if cachegroup.ParentName != nil && *cachegroup.ParentName != "" {
-		err := db.QueryRow(query, *cachegroup.ParentName).Scan(&parentID)
+		err := db.QueryRowx(query, *cachegroup.ParentName).Scan(&parentID)
 		if err != nil {
 			log.Errorf("received error: %++v from query execution", err)
 			return err
@@ -183,7 +183,7 @@ func getParentCachegroupIDs(db *sqlx.DB, cachegroup *TOCacheGroup) error {
 	}
 
 	if cachegroup.SecondaryParentName != nil && *cachegroup.SecondaryParentName != "" {
-		err := db.QueryRow(query, *cachegroup.SecondaryParentName).Scan(&secondaryParentID)
+		err := db.QueryRowx(query, *cachegroup.SecondaryParentName).Scan(&secondaryParentID)
 		if err != nil {
 			log.Errorf("received error: %++v from query execution", err)
 			return err
@@ -311,9 +311,10 @@ func (cachegroup *TOCacheGroup) Read(db *sqlx.DB, parameters map[string]string,
 	// Query Parameters to Database Query column mappings
 	// see the fields mapped in the SQL query
 	queryParamsToQueryCols := map[string]dbhelpers.WhereColumnInfo{
-		"id":        dbhelpers.WhereColumnInfo{"cachegroup.id", api.IsInt},
-		"name":      dbhelpers.WhereColumnInfo{"cachegroup.name", nil},
-		"shortName": dbhelpers.WhereColumnInfo{"short_name", nil},
+		"id":          dbhelpers.WhereColumnInfo{"cachegroup.id", api.IsInt},
+		"name":        dbhelpers.WhereColumnInfo{"cachegroup.name", nil},
+		"shortName":   dbhelpers.WhereColumnInfo{"short_name", nil},
+		"lastUpdated": dbhelpers.WhereColumnInfo{"cachegroup.last_updated", nil},
 	}
 	where, orderBy, queryValues, errs := dbhelpers.BuildWhereAndOrderBy(parameters, queryParamsToQueryCols)
 	if len(errs) > 0 {
@@ -366,9 +367,22 @@ func (cachegroup *TOCacheGroup) Update(db *sqlx.DB, user auth.CurrentUser) (erro
 		log.Error.Printf("could not begin transaction: %v", err)
 		return tc.DBError, tc.SystemError
 	}
+	if err, errType := cachegroup.UpdateTx(tx, user); err != nil {
+		return err, errType
+	}
+	err = tx.Commit()
+	if err != nil {
+		log.Errorln("Could not commit transaction: ", err)
+		return tc.DBError, tc.SystemError
+	}
+	rollbackTransaction = false
+	return nil, tc.NoError
+}
 
+// UpdateTx updates the cachegroup in the given transaction, which the caller commits or rolls back.
+func (cachegroup *TOCacheGroup) UpdateTx(tx *sqlx.Tx, user auth.CurrentUser) (error, tc.ApiErrorType) {
 	// fix up parent ids.
-	err = getParentCachegroupIDs(db, cachegroup)
+	err := getParentCachegroupIDs(tx, cachegroup)
 	if err != nil {
 		log.Error.Printf("failure looking up parent cache groups %v", err)
 		return tc.DBError, tc.SystemError
@@ -408,12 +422,6 @@ func (cachegroup *TOCacheGroup) Update(db *sqlx.DB, user auth.CurrentUser) (erro
 			return fmt.Errorf("this update affected too many rows: %d", rowsAffected), tc.SystemError
 		}
 	}
-	err = tx.Commit()
-	if err != nil {
-		log.Errorln("Could not commit transaction: ", err)
-		return tc.DBError, tc.SystemError
-	}
-	rollbackTransaction = false
 	return nil, tc.NoError
 }
 
@@ -436,8 +444,21 @@ func (cachegroup *TOCacheGroup) Delete(db *sqlx.DB, user auth.CurrentUser) (erro
 		log.Error.Printf("could not begin transaction: %v", err)
 		return tc.DBError, tc.SystemError
 	}
+	if err, errType := cachegroup.DeleteTx(tx, user); err != nil {
+		return err, errType
+	}
+	err = tx.Commit()
+	if err != nil {
+		log.Errorln("Could not commit transaction: ", err)
+		return tc.DBError, tc.SystemError
+	}
+	rollbackTransaction = false
+	return nil, tc.NoError
+}
 
-	inUse, err := isUsedByChildCache(db, *cachegroup.ID)
+// DeleteTx deletes the cachegroup in the given transaction, which the caller commits or rolls back.
+func (cachegroup *TOCacheGroup) DeleteTx(tx *sqlx.Tx, user auth.CurrentUser) (error, tc.ApiErrorType) {
+	inUse, err := isUsedByChildCache(tx, *cachegroup.ID)
 	log.Debugf("inUse: %d, err: %v", inUse, err)
 	if inUse == false && err != nil {
 		return tc.DBError, tc.SystemError
@@ -463,12 +484,6 @@ func (cachegroup *TOCacheGroup) Delete(db *sqlx.DB, user auth.CurrentUser) (erro
 			return fmt.Errorf("this create affected too many rows: %d", rowsAffected), tc.SystemError
 		}
 	}
-	err = tx.Commit()
-	if err != nil {
-		log.Errorln("Could not commit transaction: ", err)
-		return tc.DBError, tc.SystemError
-	}
-	rollbackTransaction = false
 	return nil, tc.NoError
 }
 
@@ -0,0 +1,320 @@
+package capability
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-log"
+	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/dbhelpers"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/tovalidate"
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// pqForeignKeyViolation is the Postgres error code of a foreign key violation, returned when deleting a capability which a role still has.
+const pqForeignKeyViolation = "23503"
+
+//we need a type alias to define functions on
+type TOCapability tc.CapabilityNullable
+
+//the refType is passed into the handlers where a copy of its type is used to decode the json.
+var refType = TOCapability(tc.CapabilityNullable{})
+
+func GetRefType() *TOCapability {
+	return &refType
+}
+
+func (capability TOCapability) GetAuditName() string {
+	if capability.Name != nil {
+		return *capability.Name
+	}
+	return "unknown"
+}
+
+func (capability TOCapability) GetKeyFieldsInfo() []api.KeyFieldInfo {
+	return []api.KeyFieldInfo{{"name", api.GetStringKey}}
+}
+
+//Implementation of the Identifier, Validator interface functions
+func (capability TOCapability) GetKeys() (map[string]interface{}, bool) {
+	if capability.Name == nil {
+		return map[string]interface{}{"name": ""}, false
+	}
+	return map[string]interface{}{"name": *capability.Name}, true
+}
+
+func (capability *TOCapability) SetKeys(keys map[string]interface{}) {
+	name, _ := keys["name"].(string)
+	capability.Name = &name
+}
+
+func (capability TOCapability) GetType() string {
+	return "capability"
+}
+
+func (capability TOCapability) Validate(db *sqlx.DB) []error {
+	errs := validation.Errors{
+		"name": validation.Validate(capability.Name, validation.NotNil, validation.Required),
+	}
+	return tovalidate.ToErrors(errs)
+}
+
+//The TOCapability implementation of the Creator interface
+//ParsePQUniqueConstraintError is used to determine if a capability with the same name exists
+func (capability *TOCapability) Create(db *sqlx.DB, user auth.CurrentUser) (error, tc.ApiErrorType) {
+	rollbackTransaction := true
+	tx, err := db.Beginx()
+	defer func() {
+		if tx == nil || !rollbackTransaction {
+			return
+		}
+		err := tx.Rollback()
+		if err != nil {
+			log.Errorln(errors.New("rolling back transaction: " + err.Error()))
+		}
+	}()
+
+	if err != nil {
+		log.Error.Printf("could not begin transaction: %v", err)
+		return tc.DBError, tc.SystemError
+	}
+	resultRows, err := tx.NamedQuery(insertQuery(), capability)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok {
+			err, eType := dbhelpers.ParsePQUniqueConstraintError(pqErr)
+			if eType == tc.DataConflictError {
+				return errors.New("a capability with " + err.Error()), eType
+			}
+			return err, eType
+		}
+		log.Errorf("received non pq error: %++v from create execution", err)
+		return tc.DBError, tc.SystemError
+	}
+	defer resultRows.Close()
+
+	var lastUpdated tc.TimeNoMod
+	rowsAffected := 0
+	for resultRows.Next() {
+		rowsAffected++
+		if err := resultRows.Scan(&lastUpdated); err != nil {
+			log.Error.Printf("could not scan last_updated from insert: %s\n", err)
+			return tc.DBError, tc.SystemError
+		}
+	}
+	if rowsAffected != 1 {
+		log.Errorf("capability insert returned %d rows, expected 1", rowsAffected)
+		return tc.DBError, tc.SystemError
+	}
+	capability.LastUpdated = &lastUpdated
+	err = tx.Commit()
+	if err != nil {
+		log.Errorln("Could not commit transaction: ", err)
+		return tc.DBError, tc.SystemError
+	}
+	rollbackTransaction = false
+	return nil, tc.NoError
+}
+
+func (capability *TOCapability) Read(db *sqlx.DB, parameters map[string]string, user auth.CurrentUser) ([]interface{}, []error, tc.ApiErrorType) {
+	// Query Parameters to Database Query column mappings
+	// see the fields mapped in the SQL query
+	queryParamsToQueryCols := map[string]dbhelpers.WhereColumnInfo{
+		"name":        dbhelpers.WhereColumnInfo{"name", nil},
+		"lastUpdated": dbhelpers.WhereColumnInfo{"last_updated", nil},
+	}
+	where, orderBy, queryValues, errs := dbhelpers.BuildWhereAndOrderBy(parameters, queryParamsToQueryCols)
+	if len(errs) > 0 {
+		return nil, errs, tc.DataConflictError
+	}
+
+	query := selectQuery() + where + orderBy
+	log.Debugln("Query is ", query)
+
+	rows, err := db.NamedQuery(query, queryValues)
+	if err != nil {
+		log.Errorf("Error querying Capabilities: %v", err)
+		return nil, []error{tc.DBError}, tc.SystemError
+	}
+	defer rows.Close()
+
+	capabilities := []interface{}{}
+	for rows.Next() {
+		var c tc.Capability
+		if err = rows.StructScan(&c); err != nil {
+			log.Errorf("error parsing Capability rows: %v", err)
+			return nil, []error{tc.DBError}, tc.SystemError
+		}
+		capabilities = append(capabilities, c)
+	}
+
+	return capabilities, []error{}, tc.NoError
+}
+
+//The TOCapability implementation of the Updater interface
+//Only the description may be updated; capabilities are renamed by creating a new one, and deleting the old
+func (capability *TOCapability) Update(db *sqlx.DB, user auth.CurrentUser) (error, tc.ApiErrorType) {
+	rollbackTransaction := true
+	tx, err := db.Beginx()
+	defer func() {
+		if tx == nil || !rollbackTransaction {
+			return
+		}
+		err := tx.Rollback()
+		if err != nil {
+			log.Errorln(errors.New("rolling back transaction: " + err.Error()))
+		}
+	}()
+
+	if err != nil {
+		log.Error.Printf("could not begin transaction: %v", err)
+		return tc.DBError, tc.SystemError
+	}
+	if err, errType := capability.UpdateTx(tx, user); err != nil {
+		return err, errType
+	}
+	err = tx.Commit()
+	if err != nil {
+		log.Errorln("Could not commit transaction: ", err)
+		return tc.DBError, tc.SystemError
+	}
+	rollbackTransaction = false
+	return nil, tc.NoError
+}
+
+// UpdateTx updates the capability in the given transaction, which the caller commits or rolls back.
+func (capability *TOCapability) UpdateTx(tx *sqlx.Tx, user auth.CurrentUser) (error, tc.ApiErrorType) {
+	log.Debugf("about to run exec query: %s with capability: %++v", updateQuery(), capability)
+	resultRows, err := tx.NamedQuery(updateQuery(), capability)
+	if err != nil {
+		log.Errorf("received error: %++v from update execution", err)
+		return tc.DBError, tc.SystemError
+	}
+	defer resultRows.Close()
+
+	var lastUpdated tc.TimeNoMod
+	rowsAffected := 0
+	for resultRows.Next() {
+		rowsAffected++
+		if err := resultRows.Scan(&lastUpdated); err != nil {
+			log.Error.Printf("could not scan lastUpdated from update: %s\n", err)
+			return tc.DBError, tc.SystemError
+		}
+	}
+	capability.LastUpdated = &lastUpdated
+	if rowsAffected != 1 {
+		if rowsAffected < 1 {
+			return errors.New("no capability found with this name"), tc.DataMissingError
+		}
+		return fmt.Errorf("this update affected too many rows: %d", rowsAffected), tc.SystemError
+	}
+	return nil, tc.NoError
+}
+
+//The TOCapability implementation of the Deleter interface
+//Capabilities still assigned to a role or API route may not be deleted
+func (capability *TOCapability) Delete(db *sqlx.DB, user auth.CurrentUser) (error, tc.ApiErrorType) {
+	rollbackTransaction := true
+	tx, err := db.Beginx()
+	defer func() {
+		if tx == nil || !rollbackTransaction {
+			return
+		}
+		err := tx.Rollback()
+		if err != nil {
+			log.Errorln(errors.New("rolling back transaction: " + err.Error()))
+		}
+	}()
+
+	if err != nil {
+		log.Error.Printf("could not begin transaction: %v", err)
+		return tc.DBError, tc.SystemError
+	}
+	if err, errType := capability.DeleteTx(tx, user); err != nil {
+		return err, errType
+	}
+	err = tx.Commit()
+	if err != nil {
+		log.Errorln("Could not commit transaction: ", err)
+		return tc.DBError, tc.SystemError
+	}
+	rollbackTransaction = false
+	return nil, tc.NoError
+}
+
+// DeleteTx deletes the capability in the given transaction, which the caller commits or rolls back.
+func (capability *TOCapability) DeleteTx(tx *sqlx.Tx, user auth.CurrentUser) (error, tc.ApiErrorType) {
+	log.Debugf("about to run exec query: %s with capability: %++v", deleteQuery(), capability)
+	result, err := tx.NamedExec(deleteQuery(), capability)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == pqForeignKeyViolation {
+			return errors.New("capability is in use, remove it from all roles first"), tc.DataConflictError
+		}
+		log.Errorf("received error: %++v from delete execution", err)
+		return tc.DBError, tc.SystemError
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return tc.DBError, tc.SystemError
+	}
+	if rowsAffected != 1 {
+		if rowsAffected < 1 {
+			return errors.New("no capability with that name found"), tc.DataMissingError
+		}
+		return fmt.Errorf("this delete affected too many rows: %d", rowsAffected), tc.SystemError
+	}
+	return nil, tc.NoError
+}
+
+func insertQuery() string {
+	query := `INSERT INTO capability (
+name,
+description) VALUES (:name, :description) RETURNING last_updated`
+	return query
+}
+
+func selectQuery() string {
+	query := `SELECT
+name,
+COALESCE(description, '') AS description,
+last_updated
+
+FROM capability c`
+	return query
+}
+
+func updateQuery() string {
+	query := `UPDATE
+capability SET
+description=:description
+WHERE name=:name RETURNING last_updated`
+	return query
+}
+
+func deleteQuery() string {
+	query := `DELETE FROM capability
+WHERE name=:name`
+	return query
+}
@@ -0,0 +1,131 @@
+package capability
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/test"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func getTestCapabilities() []tc.Capability {
+	return []tc.Capability{
+		{Name: "server-read", Description: "View server configuration", LastUpdated: tc.TimeNoMod{Time: time.Now()}},
+		{Name: "server-write", Description: "Create, edit or delete server configuration", LastUpdated: tc.TimeNoMod{Time: time.Now()}},
+	}
+}
+
+func TestReadCapabilities(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	testCaps := getTestCapabilities()
+	rows := sqlmock.NewRows(test.ColsFromStructByTag("db", tc.Capability{}))
+	for _, c := range testCaps {
+		rows = rows.AddRow(c.Name, c.Description, c.LastUpdated)
+	}
+	mock.ExpectQuery("SELECT").WillReturnRows(rows)
+
+	caps, errs, errType := refType.Read(db, map[string]string{}, auth.CurrentUser{})
+	if len(errs) > 0 {
+		t.Fatalf("capability.Read expected: no errors, actual: %v with error type: %s", errs, errType.String())
+	}
+	if len(caps) != len(testCaps) {
+		t.Fatalf("capability.Read expected: len(caps) == %v, actual: %v", len(testCaps), len(caps))
+	}
+	if name := caps[1].(tc.Capability).Name; name != "server-write" {
+		t.Errorf("capability.Read expected: server-write, actual: %v", name)
+	}
+}
+
+func TestDeleteCapabilityInUse(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM capability").WithArgs("server-read").WillReturnError(&pq.Error{Code: pqForeignKeyViolation})
+	mock.ExpectRollback()
+
+	name := "server-read"
+	c := TOCapability{Name: &name}
+	err, errType := c.Delete(db, auth.CurrentUser{})
+	if err == nil {
+		t.Fatal("capability.Delete expected: error deleting a capability in use, actual: nil")
+	}
+	if errType != tc.DataConflictError {
+		t.Errorf("capability.Delete expected: error type %v, actual: %v", tc.DataConflictError, errType)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestInterfaces(t *testing.T) {
+	var i interface{}
+	i = &TOCapability{}
+
+	if _, ok := i.(api.Creator); !ok {
+		t.Errorf("capability must be creator")
+	}
+	if _, ok := i.(api.Reader); !ok {
+		t.Errorf("capability must be reader")
+	}
+	if _, ok := i.(api.Updater); !ok {
+		t.Errorf("capability must be updater")
+	}
+	if _, ok := i.(api.Deleter); !ok {
+		t.Errorf("capability must be deleter")
+	}
+	if _, ok := i.(api.Identifier); !ok {
+		t.Errorf("capability must be Identifier")
+	}
+}
+
+func TestValidation(t *testing.T) {
+	c := TOCapability{}
+	if errs := c.Validate(nil); len(errs) == 0 {
+		t.Errorf("capability.Validate expected: error for missing name, actual: none")
+	}
+	name := "server-read"
+	c.Name = &name
+	if errs := c.Validate(nil); len(errs) > 0 {
+		t.Errorf("capability.Validate expected: no errors, actual: %v", errs)
+	}
+}
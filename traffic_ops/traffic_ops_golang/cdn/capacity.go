@@ -0,0 +1,170 @@
+package cdn
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-log"
+	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// CapacityHandler serves the percentage of the edge caches' bandwidth which is available, utilized, unavailable, and in maintenance, across all CDNs, according to the CDNs' Traffic Monitors.
+// CDNs whose monitors can't be reached are listed as unavailable, and the capacity of the others is still served.
+func CapacityHandler(db *sqlx.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		handleErrs := tc.GetHandleErrorsFunc(w, r)
+		cdnMonitors, err := getCDNHosts(db, monitorType, "")
+		if err != nil {
+			log.Errorln("getting CDN capacity: " + err.Error())
+			handleErrs(http.StatusInternalServerError, tc.DBError)
+			return
+		}
+		minAvailableKbps, err := getProfileMinAvailableKbps(db)
+		if err != nil {
+			log.Errorln("getting CDN capacity: " + err.Error())
+			handleErrs(http.StatusInternalServerError, tc.DBError)
+			return
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), MonitorTotalTimeout)
+		defer cancel()
+		cdnData, cdnErrs := getAllMonitorData(ctx, newMonitorClient(), cdnMonitors, true)
+		unavailable, err := unavailableCDNs(len(cdnData), cdnErrs)
+		if err != nil {
+			log.Errorln("getting CDN capacity from Traffic Monitors: " + err.Error())
+			handleErrs(http.StatusBadGateway, err)
+			return
+		}
+		capacity := getCapacity(cdnData, minAvailableKbps)
+		capacity.UnavailableCDNs = unavailable
+		respBts, err := json.Marshal(tc.CDNCapacityResponse{Response: capacity})
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, err)
+			return
+		}
+		w.Header().Set(tc.ContentType, tc.ApplicationJson)
+		w.Write(respBts)
+	}
+}
+
+// getProfileMinAvailableKbps returns the health.threshold.availableBandwidthInKbps of each cache profile, which is the bandwidth a cache must keep available, and so isn't part of its capacity.
+func getProfileMinAvailableKbps(db *sqlx.DB) (map[string]float64, error) {
+	q := `
+SELECT pr.name, p.value
+FROM parameter AS p
+JOIN profile_parameter AS pp ON pp.parameter = p.id
+JOIN profile AS pr ON pp.profile = pr.id
+WHERE p.config_file = 'rascal.properties' AND p.name = 'health.threshold.availableBandwidthInKbps'
+`
+	rows, err := db.Query(q)
+	if err != nil {
+		return nil, errors.New("querying profile available bandwidth thresholds: " + err.Error())
+	}
+	defer rows.Close()
+	kbps := map[string]float64{}
+	for rows.Next() {
+		profile, value := "", ""
+		if err := rows.Scan(&profile, &value); err != nil {
+			return nil, errors.New("scanning profile available bandwidth thresholds: " + err.Error())
+		}
+		// the threshold may have a comparator, e.g. '>1750000', which is removed along with any other non-digits, as Perl did
+		value = strings.Map(func(r rune) rune {
+			if !unicode.IsDigit(r) {
+				return -1
+			}
+			return r
+		}, value)
+		if v, err := strconv.ParseFloat(value, 64); err == nil {
+			kbps[profile] = v
+		}
+	}
+	return kbps, nil
+}
+
+// getCapacity sums the capacity and bandwidth of the edge caches of the given CDNs' monitor data. The capacity of each cache is its maxKbps less its profile's minimum available kbps.
+// REPORTED and ONLINE caches' bandwidth is utilized if they're available and unavailable if not, and ADMIN_DOWN caches' bandwidth is maintenance. Caches with other statuses are ignored.
+func getCapacity(cdnData map[string]monitorData, minAvailableKbps map[string]float64) tc.CDNCapacity {
+	capacity, available, unavailable, maintenance := 0.0, 0.0, 0.0, 0.0
+	for _, data := range cdnData {
+		for cacheName, stats := range data.Stats.Caches {
+			server, ok := data.Config.ContentServers[string(cacheName)]
+			if !ok || server.ServerType == nil || !strings.HasPrefix(*server.ServerType, string(tc.CacheTypeEdge)) || server.ServerStatus == nil {
+				continue
+			}
+			state, ok := data.States.Caches[cacheName]
+			if !ok {
+				continue
+			}
+			maxKbps, ok := latestStat(stats, "maxKbps")
+			if !ok {
+				continue
+			}
+			kbps, ok := latestStat(stats, "kbps")
+			if !ok {
+				continue
+			}
+			switch tc.CacheStatus(*server.ServerStatus) {
+			case tc.CacheStatusReported, tc.CacheStatusOnline:
+				if state.IsAvailable {
+					available += kbps
+				} else {
+					unavailable += kbps
+				}
+			case tc.CacheStatusAdminDown:
+				maintenance += kbps
+			default:
+				continue
+			}
+			profile := ""
+			if server.Profile != nil {
+				profile = *server.Profile
+			}
+			capacity += maxKbps - minAvailableKbps[profile]
+		}
+	}
+	if capacity <= 0 {
+		return tc.CDNCapacity{UnavailableCDNs: []string{}}
+	}
+	return tc.CDNCapacity{
+		UtilizedPercent:    available / capacity * 100,
+		UnavailablePercent: unavailable / capacity * 100,
+		MaintenancePercent: maintenance / capacity * 100,
+		AvailablePercent:   (capacity - unavailable - maintenance - available) / capacity * 100,
+		UnavailableCDNs:    []string{},
+	}
+}
+
+// latestStat returns the latest value of the given stat, and whether it exists and is a number.
+func latestStat(stats map[string][]tc.TMCacheStat, name string) (float64, bool) {
+	vals := stats[name]
+	if len(vals) == 0 {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(vals[0].Value, 64)
+	return v, err == nil
+}
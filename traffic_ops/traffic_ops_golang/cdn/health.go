@@ -0,0 +1,131 @@
+package cdn
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-log"
+	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/api"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// HealthHandler serves the number of online and offline edge caches, in total and per cachegroup, of all CDNs, or of the CDN in the name path parameter, according to the CDNs' Traffic Monitors.
+// CDNs whose monitors can't be reached are listed as unavailable, and the health of the others is still served.
+func HealthHandler(db *sqlx.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		handleErrs := tc.GetHandleErrorsFunc(w, r)
+		params, err := api.GetPathParams(r.Context())
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, err)
+			return
+		}
+		cdnName := params["name"]
+		if cdnName != "" {
+			exists := false
+			if err := db.QueryRow(`SELECT EXISTS(SELECT 1 FROM cdn WHERE name = $1)`, cdnName).Scan(&exists); err != nil {
+				log.Errorln("getting CDN health: checking CDN exists: " + err.Error())
+				handleErrs(http.StatusInternalServerError, tc.DBError)
+				return
+			}
+			if !exists {
+				handleErrs(http.StatusNotFound, errors.New("cdn not found"))
+				return
+			}
+		}
+		cdnMonitors, err := getCDNHosts(db, monitorType, cdnName)
+		if err != nil {
+			log.Errorln("getting CDN health: " + err.Error())
+			handleErrs(http.StatusInternalServerError, tc.DBError)
+			return
+		}
+		if _, ok := cdnMonitors[cdnName]; cdnName != "" && !ok {
+			cdnMonitors[cdnName] = nil // the CDN has no online monitors, so it's unavailable
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), MonitorTotalTimeout)
+		defer cancel()
+		cdnData, cdnErrs := getAllMonitorData(ctx, newMonitorClient(), cdnMonitors, false)
+		unavailable, err := unavailableCDNs(len(cdnData), cdnErrs)
+		if err != nil {
+			log.Errorln("getting CDN health from Traffic Monitors: " + err.Error())
+			handleErrs(http.StatusBadGateway, err)
+			return
+		}
+		health := getHealth(cdnData)
+		health.UnavailableCDNs = unavailable
+		respBts, err := json.Marshal(tc.HealthDataResponse{Response: health})
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, err)
+			return
+		}
+		w.Header().Set(tc.ContentType, tc.ApplicationJson)
+		w.Write(respBts)
+	}
+}
+
+// getHealth counts the online and offline REPORTED edge caches of the given CDNs' monitor data. Cachegroups are sorted by the most offline caches first.
+func getHealth(cdnData map[string]monitorData) tc.HealthData {
+	health := tc.HealthData{CacheGroups: []tc.HealthDataCacheGroup{}, UnavailableCDNs: []string{}}
+	cacheGroups := map[string]*tc.HealthDataCacheGroup{}
+	for _, data := range cdnData {
+		for cacheName, state := range data.States.Caches {
+			server, ok := data.Config.ContentServers[string(cacheName)]
+			if !ok || server.ServerType == nil || !strings.HasPrefix(*server.ServerType, string(tc.CacheTypeEdge)) {
+				continue
+			}
+			if server.ServerStatus == nil || *server.ServerStatus != tc.CRConfigServerStatus(tc.CacheStatusReported) {
+				continue
+			}
+			cgName := ""
+			if server.CacheGroup != nil {
+				cgName = *server.CacheGroup
+			}
+			cg, ok := cacheGroups[cgName]
+			if !ok {
+				cg = &tc.HealthDataCacheGroup{Name: cgName}
+				cacheGroups[cgName] = cg
+			}
+			if state.IsAvailable {
+				health.TotalOnline++
+				cg.Online++
+			} else {
+				health.TotalOffline++
+				cg.Offline++
+			}
+		}
+	}
+	for _, cg := range cacheGroups {
+		health.CacheGroups = append(health.CacheGroups, *cg)
+	}
+	sort.Slice(health.CacheGroups, func(i, j int) bool {
+		if health.CacheGroups[i].Offline != health.CacheGroups[j].Offline {
+			return health.CacheGroups[i].Offline > health.CacheGroups[j].Offline
+		}
+		return health.CacheGroups[i].Name < health.CacheGroups[j].Name
+	})
+	return health
+}
@@ -0,0 +1,209 @@
+package cdn
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-log"
+	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// MonitorRequestTimeout is the timeout of each request to a Traffic Monitor or Traffic Router. If a monitor times out, the CDN's next monitor is tried.
+const MonitorRequestTimeout = 10 * time.Second
+
+// MonitorTotalTimeout bounds all the requests of an API request to Traffic Monitors and Traffic Routers, including failover. CDNs whose monitors don't respond in time are reported as unavailable.
+const MonitorTotalTimeout = 30 * time.Second
+
+// DefaultRouterAPIPort is the Traffic Router API port, if the router's profile has no api.port parameter.
+const DefaultRouterAPIPort = 3333
+
+const monitorType = "RASCAL"
+const routerType = "CCR"
+
+// newMonitorClient returns the client for requests to Traffic Monitors and Traffic Routers.
+func newMonitorClient() *http.Client {
+	return &http.Client{Timeout: MonitorRequestTimeout}
+}
+
+// getCDNHosts returns the "fqdn:port" addresses of the ONLINE servers of the given type, by CDN name. If cdnName isn't empty, only that CDN's servers are returned.
+// Monitors are addressed on their TCP port. Routers are addressed on their api.port parameter, because their TCP port serves DNS and HTTP routing.
+func getCDNHosts(db *sqlx.DB, serverType string, cdnName string) (map[string][]string, error) {
+	q := `
+SELECT c.name, s.host_name, s.domain_name,
+  CASE WHEN t.name = '` + routerType + `' THEN
+    COALESCE((SELECT p.value FROM parameter AS p JOIN profile_parameter AS pp ON pp.parameter = p.id WHERE pp.profile = s.profile AND p.name = 'api.port' FETCH FIRST 1 ROW ONLY), '` + strconv.Itoa(DefaultRouterAPIPort) + `')
+  ELSE
+    COALESCE(s.tcp_port::text, '80')
+  END AS port
+FROM server AS s
+JOIN cdn AS c ON s.cdn_id = c.id
+JOIN type AS t ON s.type = t.id
+JOIN status AS st ON s.status = st.id
+WHERE t.name = $1 AND st.name = 'ONLINE' AND ($2 = '' OR c.name = $2)
+ORDER BY c.name, s.host_name
+`
+	rows, err := db.Query(q, serverType, cdnName)
+	if err != nil {
+		return nil, errors.New("querying " + serverType + " servers: " + err.Error())
+	}
+	defer rows.Close()
+	hosts := map[string][]string{}
+	for rows.Next() {
+		cdn, host, domain, port := "", "", "", ""
+		if err := rows.Scan(&cdn, &host, &domain, &port); err != nil {
+			return nil, errors.New("scanning " + serverType + " servers: " + err.Error())
+		}
+		hosts[cdn] = append(hosts[cdn], host+"."+domain+":"+port)
+	}
+	return hosts, nil
+}
+
+// forEachCDN calls f concurrently for each CDN and its hosts, and returns the errors of the CDNs for which f failed, by CDN name. One CDN failing doesn't stop the others.
+func forEachCDN(cdnHosts map[string][]string, f func(cdn string, hosts []string) error) map[string]error {
+	wg := sync.WaitGroup{}
+	m := sync.Mutex{}
+	errs := map[string]error{}
+	for cdn, hosts := range cdnHosts {
+		wg.Add(1)
+		go func(cdn string, hosts []string) {
+			defer wg.Done()
+			if err := f(cdn, hosts); err != nil {
+				m.Lock()
+				errs[cdn] = err
+				m.Unlock()
+			}
+		}(cdn, hosts)
+	}
+	wg.Wait()
+	return errs
+}
+
+// unavailableCDNs logs the errors of the CDNs which couldn't be reached, and returns their names, sorted. An error is returned if no CDN could be reached, unless there were none to reach.
+func unavailableCDNs(reached int, cdnErrs map[string]error) ([]string, error) {
+	cdns := []string{}
+	for cdn, err := range cdnErrs {
+		log.Errorln("CDN " + cdn + " unavailable: " + err.Error())
+		cdns = append(cdns, cdn)
+	}
+	sort.Strings(cdns)
+	if reached == 0 && len(cdns) > 0 {
+		return nil, fmt.Errorf("no CDN could be reached: %v", cdnErrs)
+	}
+	return cdns, nil
+}
+
+// withFailover calls get for each monitor in random order, until one succeeds or the context is done. Random order spreads requests across each CDN's monitors. An error is returned if all monitors fail.
+func withFailover(ctx context.Context, monitors []string, get func(monitor string) error) error {
+	if len(monitors) == 0 {
+		return errors.New("no online monitors")
+	}
+	errs := []string{}
+	for _, i := range rand.Perm(len(monitors)) {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err.Error())
+			break
+		}
+		err := get(monitors[i])
+		if err == nil {
+			return nil
+		}
+		log.Warnln("getting from monitor " + monitors[i] + ", trying next monitor: " + err.Error())
+		errs = append(errs, monitors[i]+": "+err.Error())
+	}
+	return fmt.Errorf("all monitors failed: %v", errs)
+}
+
+// getJSON gets the given path of the given "host:port", and decodes the JSON response into v. The request is cancelled when the context is done.
+func getJSON(ctx context.Context, client *http.Client, host string, path string, v interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, "http://"+host+path, nil)
+	if err != nil {
+		return errors.New("creating request for " + path + ": " + err.Error())
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return errors.New("getting " + path + ": " + err.Error())
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.New("getting " + path + ": status " + strconv.Itoa(resp.StatusCode))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return errors.New("decoding " + path + ": " + err.Error())
+	}
+	return nil
+}
+
+// monitorData is the data served by a CDN's Traffic Monitor.
+type monitorData struct {
+	States tc.CRStates
+	Config tc.CRConfig
+	// Stats is only fetched if requested, because it is large.
+	Stats tc.TMCacheStats
+}
+
+// getMonitorData gets the cache states and CRConfig, and if withStats the maxKbps and kbps cache stats, from one of the given monitors. All are fetched from the same monitor, so they're consistent.
+func getMonitorData(ctx context.Context, client *http.Client, monitors []string, withStats bool) (monitorData, error) {
+	data := monitorData{}
+	err := withFailover(ctx, monitors, func(monitor string) error {
+		data = monitorData{}
+		if err := getJSON(ctx, client, monitor, "/publish/CrStates", &data.States); err != nil {
+			return err
+		}
+		if err := getJSON(ctx, client, monitor, "/publish/CrConfig", &data.Config); err != nil {
+			return err
+		}
+		if withStats {
+			if err := getJSON(ctx, client, monitor, "/publish/CacheStats?hc=1&stats=maxKbps,kbps", &data.Stats); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return data, err
+}
+
+// getAllMonitorData gets the monitorData of each CDN with online monitors, concurrently. The errors of CDNs whose monitors all failed are returned by CDN name, with the data of the others.
+func getAllMonitorData(ctx context.Context, client *http.Client, cdnMonitors map[string][]string, withStats bool) (map[string]monitorData, map[string]error) {
+	m := sync.Mutex{}
+	cdnData := map[string]monitorData{}
+	errs := forEachCDN(cdnMonitors, func(cdn string, monitors []string) error {
+		data, err := getMonitorData(ctx, client, monitors, withStats)
+		if err != nil {
+			return err
+		}
+		m.Lock()
+		cdnData[cdn] = data
+		m.Unlock()
+		return nil
+	})
+	return cdnData, errs
+}
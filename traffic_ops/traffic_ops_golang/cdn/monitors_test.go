@@ -0,0 +1,279 @@
+package cdn
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/api"
+
+	"github.com/jmoiron/sqlx"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+const testCRStates = `{"caches": {
+  "edge0": {"isAvailable": true},
+  "edge1": {"isAvailable": false},
+  "edge2": {"isAvailable": true},
+  "edge3": {"isAvailable": true},
+  "mid0": {"isAvailable": false}
+}, "deliveryServices": {}}`
+
+const testCRConfig = `{"contentServers": {
+  "edge0": {"cacheGroup": "cg0", "type": "EDGE", "status": "REPORTED", "profile": "EDGE_PROFILE"},
+  "edge1": {"cacheGroup": "cg1", "type": "EDGE", "status": "REPORTED", "profile": "EDGE_PROFILE"},
+  "edge2": {"cacheGroup": "cg1", "type": "EDGE", "status": "ADMIN_DOWN", "profile": "EDGE_PROFILE"},
+  "edge3": {"cacheGroup": "cg0", "type": "EDGE", "status": "OFFLINE", "profile": "EDGE_PROFILE"},
+  "mid0": {"cacheGroup": "cg0", "type": "MID", "status": "REPORTED", "profile": "MID_PROFILE"}
+}}`
+
+const testCacheStats = `{"caches": {
+  "edge0": {"maxKbps": [{"value": "1000", "time": 0, "span": 1}], "kbps": [{"value": "300", "time": 0, "span": 1}]},
+  "edge1": {"maxKbps": [{"value": "1000", "time": 0, "span": 1}], "kbps": [{"value": "100", "time": 0, "span": 1}]},
+  "edge2": {"maxKbps": [{"value": "1000", "time": 0, "span": 1}], "kbps": [{"value": "200", "time": 0, "span": 1}]},
+  "edge3": {"maxKbps": [{"value": "1000", "time": 0, "span": 1}], "kbps": [{"value": "900", "time": 0, "span": 1}]},
+  "mid0": {"maxKbps": [{"value": "1000", "time": 0, "span": 1}], "kbps": [{"value": "900", "time": 0, "span": 1}]}
+}}`
+
+// newTestMonitor returns a Traffic Monitor stand-in serving the test CrStates, CrConfig, and CacheStats.
+func newTestMonitor() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/publish/CrStates":
+			w.Write([]byte(testCRStates))
+		case "/publish/CrConfig":
+			w.Write([]byte(testCRConfig))
+		case "/publish/CacheStats":
+			w.Write([]byte(testCacheStats))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func approxEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func hostOf(s *httptest.Server) string {
+	return strings.TrimPrefix(s.URL, "http://")
+}
+
+func TestGetMonitorDataFailover(t *testing.T) {
+	good := newTestMonitor()
+	defer good.Close()
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer broken.Close()
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(500 * time.Millisecond)
+	}))
+	defer slow.Close()
+	down := httptest.NewServer(http.NotFoundHandler())
+	downHost := hostOf(down)
+	down.Close()
+
+	client := &http.Client{Timeout: 100 * time.Millisecond}
+	monitors := []string{hostOf(broken), hostOf(slow), downHost, hostOf(good)}
+	for i := 0; i < 5; i++ { // monitors are tried in random order
+		data, err := getMonitorData(context.Background(), client, monitors, true)
+		if err != nil {
+			t.Fatalf("getMonitorData expected: no error, actual: %v", err)
+		}
+		if len(data.States.Caches) != 5 || len(data.Config.ContentServers) != 5 || len(data.Stats.Caches) != 5 {
+			t.Fatalf("getMonitorData expected: 5 caches in states, config, and stats, actual: %+v", data)
+		}
+	}
+
+	if _, err := getMonitorData(context.Background(), client, []string{hostOf(broken), downHost}, false); err == nil {
+		t.Errorf("getMonitorData expected: error when all monitors fail, actual: nil")
+	}
+	if _, err := getMonitorData(context.Background(), client, nil, false); err == nil {
+		t.Errorf("getMonitorData expected: error with no monitors, actual: nil")
+	}
+}
+
+func TestGetAllMonitorData(t *testing.T) {
+	tm0 := newTestMonitor()
+	defer tm0.Close()
+	tm1 := newTestMonitor()
+	defer tm1.Close()
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer broken.Close()
+
+	client := &http.Client{Timeout: time.Second}
+	cdnData, errs := getAllMonitorData(context.Background(), client, map[string][]string{"cdn0": {hostOf(tm0)}, "cdn1": {hostOf(tm1)}}, false)
+	if len(errs) != 0 {
+		t.Fatalf("getAllMonitorData expected: no errors, actual: %v", errs)
+	}
+	if len(cdnData) != 2 {
+		t.Errorf("getAllMonitorData expected: 2 CDNs, actual: %v", len(cdnData))
+	}
+
+	// a failing CDN doesn't stop the others' data from being returned
+	cdnData, errs = getAllMonitorData(context.Background(), client, map[string][]string{"cdn0": {hostOf(tm0)}, "cdn1": {hostOf(broken)}}, false)
+	if _, ok := cdnData["cdn0"]; !ok || len(cdnData) != 1 {
+		t.Errorf("getAllMonitorData expected: data of cdn0, actual: %+v", cdnData)
+	}
+	if _, ok := errs["cdn1"]; !ok || len(errs) != 1 {
+		t.Errorf("getAllMonitorData expected: error of cdn1, actual: %v", errs)
+	}
+	unavailable, err := unavailableCDNs(len(cdnData), errs)
+	if err != nil || !reflect.DeepEqual(unavailable, []string{"cdn1"}) {
+		t.Errorf("unavailableCDNs expected: cdn1, actual: %v %v", unavailable, err)
+	}
+	if _, err := unavailableCDNs(0, errs); err == nil {
+		t.Errorf("unavailableCDNs expected: error when no CDN could be reached, actual: nil")
+	}
+	if unavailable, err := unavailableCDNs(0, nil); err != nil || len(unavailable) != 0 {
+		t.Errorf("unavailableCDNs expected: no error or CDNs without CDNs to reach, actual: %v %v", unavailable, err)
+	}
+}
+
+func TestGetMonitorDataDeadline(t *testing.T) {
+	requests := int32(0)
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer slow.Close()
+
+	// each request is within the client timeout, but the context bounds the failover across monitors
+	client := &http.Client{Timeout: time.Second}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := getMonitorData(ctx, client, []string{hostOf(slow), hostOf(slow), hostOf(slow)}, false); err == nil {
+		t.Errorf("getMonitorData expected: error after the deadline, actual: nil")
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("getMonitorData expected: to return at the deadline, actual: %v", elapsed)
+	}
+	if requests := atomic.LoadInt32(&requests); requests != 1 {
+		t.Errorf("getMonitorData expected: no failover after the deadline, actual requests: %v", requests)
+	}
+}
+
+func TestHealthHandlerUnknownCDN(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT EXISTS").WithArgs("nocdn").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/api/1.2/cdns/nocdn/health", nil)
+	r = r.WithContext(context.WithValue(r.Context(), api.PathParamsKey, map[string]string{"name": "nocdn"}))
+	HealthHandler(db)(w, r)
+	if status, _ := r.Context().Value(tc.StatusKey).(int); status != http.StatusNotFound {
+		t.Errorf("HealthHandler expected: status 404 for an unknown CDN, actual: %v %s", status, w.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetHealth(t *testing.T) {
+	tm := newTestMonitor()
+	defer tm.Close()
+	cdnData, errs := getAllMonitorData(context.Background(), &http.Client{Timeout: time.Second}, map[string][]string{"cdn0": {hostOf(tm)}}, false)
+	if len(errs) != 0 {
+		t.Fatalf("getAllMonitorData expected: no errors, actual: %v", errs)
+	}
+
+	health := getHealth(cdnData)
+	// only REPORTED edges count: edge0 online in cg0, edge1 offline in cg1
+	if health.TotalOnline != 1 || health.TotalOffline != 1 {
+		t.Errorf("getHealth expected: 1 online and 1 offline, actual: %+v", health)
+	}
+	if len(health.CacheGroups) != 2 || health.CacheGroups[0].Name != "cg1" || health.CacheGroups[0].Offline != 1 || health.CacheGroups[1].Name != "cg0" || health.CacheGroups[1].Online != 1 {
+		t.Errorf("getHealth expected: cg1 with 1 offline, then cg0 with 1 online, actual: %+v", health.CacheGroups)
+	}
+}
+
+func TestGetCapacity(t *testing.T) {
+	tm := newTestMonitor()
+	defer tm.Close()
+	cdnData, errs := getAllMonitorData(context.Background(), &http.Client{Timeout: time.Second}, map[string][]string{"cdn0": {hostOf(tm)}}, true)
+	if len(errs) != 0 {
+		t.Fatalf("getAllMonitorData expected: no errors, actual: %v", errs)
+	}
+
+	// edge0, edge1, and edge2 count, each with a capacity of 1000 - 200
+	capacity := getCapacity(cdnData, map[string]float64{"EDGE_PROFILE": 200})
+	expected := struct{ utilized, unavailable, maintenance, available float64 }{300.0 / 2400 * 100, 100.0 / 2400 * 100, 200.0 / 2400 * 100, 1800.0 / 2400 * 100}
+	if !approxEqual(capacity.UtilizedPercent, expected.utilized) || !approxEqual(capacity.UnavailablePercent, expected.unavailable) || !approxEqual(capacity.MaintenancePercent, expected.maintenance) || !approxEqual(capacity.AvailablePercent, expected.available) {
+		t.Errorf("getCapacity expected: %+v, actual: %+v", expected, capacity)
+	}
+
+	if capacity := getCapacity(nil, nil); capacity.AvailablePercent != 0 || capacity.UtilizedPercent != 0 {
+		t.Errorf("getCapacity expected: zero capacity with no CDNs, actual: %+v", capacity)
+	}
+}
+
+func TestGetRouting(t *testing.T) {
+	tr0 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/crs/stats" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"app": {}, "stats": {"httpMap": {"ds0.example.net": {"czCount": 6, "geoCount": 2, "missCount": 0}}, "dnsMap": {"ds1.example.net": {"czCount": 1, "deepCzCount": 1}}, "totalHttpCount": 8}}`))
+	}))
+	defer tr0.Close()
+	tr1 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"stats": {"httpMap": {"ds0.example.net": {"czCount": 1, "errCount": 1}}, "dnsMap": {}}}`))
+	}))
+	defer tr1.Close()
+
+	client := &http.Client{Timeout: time.Second}
+	stats, err := getRouterStats(context.Background(), client, []string{hostOf(tr0), hostOf(tr1)})
+	if err != nil {
+		t.Fatalf("getRouterStats expected: no error, actual: %v", err)
+	}
+	routing := getRouting(stats)
+	// 12 requests: 8 cz, 2 geo, 1 deepCz, 1 err
+	if !approxEqual(routing.CZ, 800.0/12) || !approxEqual(routing.Geo, 200.0/12) || !approxEqual(routing.DeepCZ, 100.0/12) || !approxEqual(routing.Err, 100.0/12) || routing.Miss != 0 {
+		t.Errorf("getRouting expected: cz 66.67, geo 16.67, deepCz 8.33, err 8.33, miss 0, actual: %+v", routing)
+	}
+
+	tr1.Close()
+	if _, err := getRouterStats(context.Background(), client, []string{hostOf(tr0), hostOf(tr1)}); err == nil {
+		t.Errorf("getRouterStats expected: error when a router is down, actual: nil")
+	}
+}
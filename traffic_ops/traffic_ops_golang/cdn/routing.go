@@ -0,0 +1,124 @@
+package cdn
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"sync"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-log"
+	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// RoutingHandler serves the percentage of requests routed by each method, across the online Traffic Routers of all CDNs.
+func RoutingHandler(db *sqlx.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		handleErrs := tc.GetHandleErrorsFunc(w, r)
+		cdnRouters, err := getCDNHosts(db, routerType, "")
+		if err != nil {
+			log.Errorln("getting CDN routing: " + err.Error())
+			handleErrs(http.StatusInternalServerError, tc.DBError)
+			return
+		}
+		routers := []string{}
+		for _, cdnRouters := range cdnRouters {
+			routers = append(routers, cdnRouters...)
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), MonitorTotalTimeout)
+		defer cancel()
+		stats, err := getRouterStats(ctx, newMonitorClient(), routers)
+		if err != nil {
+			log.Errorln("getting CDN routing from Traffic Routers: " + err.Error())
+			handleErrs(http.StatusBadGateway, err)
+			return
+		}
+		respBts, err := json.Marshal(tc.CDNRoutingResponse{Response: getRouting(stats)})
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, err)
+			return
+		}
+		w.Header().Set(tc.ContentType, tc.ApplicationJson)
+		w.Write(respBts)
+	}
+}
+
+// getRouterStats gets the stats of all the given "fqdn:apiPort" routers, concurrently. Every router's stats are needed for the routing percentages to be correct, so an error is returned if any router fails.
+func getRouterStats(ctx context.Context, client *http.Client, routers []string) ([]tc.TRStats, error) {
+	wg := sync.WaitGroup{}
+	stats := make([]tc.TRStats, len(routers))
+	errs := make([]error, len(routers))
+	for i, router := range routers {
+		wg.Add(1)
+		go func(i int, router string) {
+			defer wg.Done()
+			errs[i] = getJSON(ctx, client, router, "/crs/stats", &stats[i])
+		}(i, router)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			return nil, errors.New("router " + routers[i] + ": " + err.Error())
+		}
+	}
+	return stats, nil
+}
+
+// countSuffix matches the 'Count' suffix of Traffic Router stat names, e.g. 'czCount', which the routing response omits.
+var countSuffix = regexp.MustCompile(`(?i)count`)
+
+// getRouting returns the percentage of the given routers' DNS and HTTP requests routed by each method.
+func getRouting(stats []tc.TRStats) tc.CDNRouting {
+	counts := map[string]float64{}
+	total := 0.0
+	for _, routerStats := range stats {
+		for _, fqdnStats := range []map[string]map[string]float64{routerStats.Stats.HTTPMap, routerStats.Stats.DNSMap} {
+			for _, fqdnCounts := range fqdnStats {
+				for name, count := range fqdnCounts {
+					counts[countSuffix.ReplaceAllString(name, "")] += count
+					total += count
+				}
+			}
+		}
+	}
+	percent := func(name string) float64 {
+		if total <= 0 {
+			return 0
+		}
+		return counts[name] / total * 100
+	}
+	return tc.CDNRouting{
+		StaticRoute:       percent("staticRoute"),
+		Geo:               percent("geo"),
+		Err:               percent("err"),
+		Fed:               percent("fed"),
+		CZ:                percent("cz"),
+		DeepCZ:            percent("deepCz"),
+		RegionalAlternate: percent("regionalAlternate"),
+		DSR:               percent("dsr"),
+		Miss:              percent("miss"),
+		RegionalDenied:    percent("regionalDenied"),
+	}
+}
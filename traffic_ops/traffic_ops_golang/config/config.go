@@ -20,10 +20,17 @@ package config
  */
 
 import (
+	"crypto"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/url"
+	"strings"
+	"time"
 
 	"github.com/apache/incubator-trafficcontrol/lib/go-log"
 	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/riaksvc"
@@ -42,7 +49,15 @@ type Config struct {
 	// NOTE: don't care about any other fields for now..
 	RiakAuthOptions *riak.AuthOptions
 	RiakEnabled     bool
-	Version         string
+	// LDAP is loaded from the ldap.conf shared with the Perl Traffic Ops. LDAPEnabled is whether it exists.
+	LDAP        ConfigLDAP
+	LDAPEnabled bool
+	// SecretStoreKey is the AES key of the postgres secret store backend, loaded from the secret store key_file.
+	SecretStoreKey []byte
+	// SSLCACert and SSLCAKey are the internal certificate authority, loaded from the ssl_ca files. SSLCACert is nil if there is none.
+	SSLCACert *x509.Certificate
+	SSLCAKey  crypto.Signer
+	Version   string
 }
 
 // ConfigHypnotoad carries http setting for hypnotoad (mojolicious) server
@@ -70,6 +85,101 @@ type ConfigTrafficOpsGolang struct {
 	Insecure               bool           `json:"insecure"`
 	MaxDBConnections       int            `json:"max_db_connections"`
 	BackendMaxConnections  map[string]int `json:"backend_max_connections"`
+	// SnapshotHistoryRetention is the number of CRConfig snapshots kept in the history of each CDN, which can be rolled back to.
+	SnapshotHistoryRetention int `json:"snapshot_history_retention"`
+	// SecretStore is the storage backend of secrets such as SSL keys and URI signing keys.
+	SecretStore ConfigSecretStore `json:"secret_store"`
+	// Authenticators are the names of the authenticators which check login passwords, in the order they're tried. The default is AuthenticatorLocal, followed by AuthenticatorLDAP if LDAP is enabled.
+	Authenticators []string `json:"authenticators"`
+	// UserCacheTTLSecs is how long authenticated users and their sessions are cached, instead of being queried on every request. Changes made through the database are seen immediately, except while the cache's database connection is down. A negative value disables the cache.
+	UserCacheTTLSecs int `json:"user_cache_ttl_secs"`
+	// EventRetentionHours is how long change events are kept, to be replayed to event stream clients which reconnect, and delivered to webhooks which were missed while every Traffic Ops was down.
+	EventRetentionHours int `json:"event_retention_hours"`
+	// WebhookTimeoutSecs is the timeout of each webhook delivery attempt.
+	WebhookTimeoutSecs int `json:"webhook_timeout_secs"`
+	// WebhookMaxAttempts is the number of times a webhook delivery is attempted, before it's kept as one of the webhook's dead letters.
+	WebhookMaxAttempts int `json:"webhook_max_attempts"`
+	// WebhookRetrySecs is the delay before the first retry of a failed webhook delivery. The delay doubles after each retry.
+	WebhookRetrySecs int `json:"webhook_retry_secs"`
+	// WebhookWorkers is the most webhook deliveries this Traffic Ops attempts at once.
+	WebhookWorkers int `json:"webhook_workers"`
+	// DNSSECRefreshIntervalSecs is how often the DNSSEC keys of CDNs with DNSSEC enabled are checked, to generate the keys of new delivery services and roll over keys about to expire. A negative value disables the refresh.
+	DNSSECRefreshIntervalSecs int `json:"dnssec_refresh_interval_secs"`
+	// SSLCertDays is the lifetime of generated delivery service certificates.
+	SSLCertDays int `json:"ssl_cert_days"`
+	// SSLCertCheckIntervalSecs is how often the certificates of HTTPS delivery services are checked, to warn of certificates which are expiring, invalid, or not valid for their delivery service's routing hostname. A negative value disables the check.
+	SSLCertCheckIntervalSecs int `json:"ssl_cert_check_interval_secs"`
+	// SSLCertWarningDays is how many days before a delivery service certificate expires the check warns of it.
+	SSLCertWarningDays int `json:"ssl_cert_warning_days"`
+	// SSLCA is the internal certificate authority which can issue generated delivery service certificates, for lab CDNs. It's optional.
+	SSLCA ConfigSSLCA `json:"ssl_ca"`
+}
+
+// ConfigSSLCA carries the files of the internal certificate authority
+type ConfigSSLCA struct {
+	// CertFile is the path of the PEM encoded CA certificate.
+	CertFile string `json:"cert_file"`
+	// KeyFile is the path of the PEM encoded, unencrypted CA private key.
+	KeyFile string `json:"key_file"`
+}
+
+// ConfigSecretStore carries the settings of the secret storage backend
+type ConfigSecretStore struct {
+	// Backend is one of SecretStoreRiak, SecretStorePostgres, or SecretStoreFile. The default is SecretStoreRiak.
+	Backend string `json:"backend"`
+	// KeyFile is the path of the file containing the base64 encoded AES key which encrypts secrets in the postgres backend. It's loaded whenever it's set, so secrets can be migrated to the postgres backend before it's configured.
+	KeyFile string `json:"key_file"`
+	// Directory is the directory the file backend stores secrets in.
+	Directory string `json:"directory"`
+}
+
+const (
+	// SecretStoreRiak stores secrets in the online RIAK servers.
+	SecretStoreRiak = "riak"
+	// SecretStorePostgres stores secrets encrypted in the Traffic Ops database.
+	SecretStorePostgres = "postgres"
+	// SecretStoreFile stores secrets unencrypted in a local directory. It's intended for development and testing.
+	SecretStoreFile = "file"
+)
+
+const (
+	// AuthenticatorLocal checks passwords against the local passwords in the Traffic Ops database.
+	AuthenticatorLocal = "local"
+	// AuthenticatorLDAP checks passwords by binding to the LDAP server as the user.
+	AuthenticatorLDAP = "ldap"
+)
+
+// ConfigLDAP carries the settings of the LDAP server, from ldap.conf
+type ConfigLDAP struct {
+	// Host is the LDAP server, as host:port, ldap://host:port, or ldaps://host:port. The port defaults to 389 for ldap, and 636 for ldaps.
+	Host       string `json:"host"`
+	AdminDN    string `json:"admin_dn"`
+	AdminPass  string `json:"admin_pass"`
+	SearchBase string `json:"search_base"`
+	// SearchQuery is the filter which finds users, with %s replaced by the escaped username. The default is the Active Directory query used by the Perl Traffic Ops.
+	SearchQuery string `json:"search_query"`
+	// GroupAttribute is the attribute of the user entry which lists the user's groups. The default is memberOf.
+	GroupAttribute string `json:"group_attribute"`
+	// StartTLS is whether ldap:// connections are upgraded to TLS before binding. The login fails if the server doesn't support it.
+	StartTLS bool `json:"start_tls"`
+	// AllowCleartext allows ldap:// hosts without StartTLS, which send the admin and user passwords unencrypted. Hosts must otherwise be ldaps:// or use StartTLS.
+	AllowCleartext bool `json:"allow_cleartext"`
+	// Insecure skips verifying the LDAP server's certificate.
+	Insecure    bool `json:"insecure"`
+	TimeoutSecs int  `json:"timeout_secs"`
+	// Provision is whether LDAP users who aren't in Traffic Ops are created on login, with the role and tenant of their groups.
+	Provision bool `json:"provision"`
+	// GroupMappings map LDAP groups to roles and tenants. The first mapping whose group the user is in is used, otherwise the default role and tenant. Users without a local password have their role and tenant set from their groups on every login, so changes in LDAP take effect.
+	GroupMappings []ConfigLDAPGroupMapping `json:"group_mappings"`
+	DefaultRole   string                   `json:"default_role"`
+	DefaultTenant string                   `json:"default_tenant"`
+}
+
+// ConfigLDAPGroupMapping maps the members of an LDAP group, by its DN, to a Traffic Ops role and tenant, by their names.
+type ConfigLDAPGroupMapping struct {
+	Group  string `json:"group"`
+	Role   string `json:"role"`
+	Tenant string `json:"tenant"`
 }
 
 // ConfigDatabase reflects the structure of the database.conf file
@@ -108,7 +218,7 @@ func (c Config) EventLog() log.LogLocation {
 }
 
 // LoadConfig - reads the config file into the Config struct
-func LoadConfig(cdnConfPath string, dbConfPath string, riakConfPath string, appVersion string) (Config, error) {
+func LoadConfig(cdnConfPath string, dbConfPath string, riakConfPath string, ldapConfPath string, appVersion string) (Config, error) {
 	// load json from cdn.conf
 	confBytes, err := ioutil.ReadFile(cdnConfPath)
 	if err != nil {
@@ -130,11 +240,29 @@ func LoadConfig(cdnConfPath string, dbConfPath string, riakConfPath string, appV
 	if err != nil {
 		return Config{}, fmt.Errorf("unmarshalling '%s': %v", dbConfPath, err)
 	}
+	if ldapConfPath != "" {
+		if cfg.LDAP, err = loadLDAPConfig(ldapConfPath); err != nil {
+			return Config{}, err
+		}
+		cfg.LDAPEnabled = true
+	}
 	cfg, err = ParseConfig(cfg)
 	if err != nil {
 		return Config{}, fmt.Errorf("parsing config '%s': %v", dbConfPath, err)
 	}
 
+	if cfg.SecretStore.KeyFile != "" {
+		if cfg.SecretStoreKey, err = loadSecretStoreKey(cfg.SecretStore.KeyFile); err != nil {
+			return Config{}, err
+		}
+	}
+
+	if cfg.SSLCA.CertFile != "" {
+		if cfg.SSLCACert, cfg.SSLCAKey, err = loadSSLCA(cfg.SSLCA.CertFile, cfg.SSLCA.KeyFile); err != nil {
+			return Config{}, err
+		}
+	}
+
 	if riakConfPath != "" {
 		cfg.RiakEnabled, cfg.RiakAuthOptions, err = riaksvc.GetRiakConfig(riakConfPath)
 		if err != nil {
@@ -145,6 +273,93 @@ func LoadConfig(cdnConfPath string, dbConfPath string, riakConfPath string, appV
 	return cfg, err
 }
 
+// loadLDAPConfig reads the ldap.conf shared with the Perl Traffic Ops.
+func loadLDAPConfig(ldapConfPath string) (ConfigLDAP, error) {
+	ldapConfBytes, err := ioutil.ReadFile(ldapConfPath)
+	if err != nil {
+		return ConfigLDAP{}, fmt.Errorf("reading LDAP conf '%s': %v", ldapConfPath, err)
+	}
+	ldapCfg := ConfigLDAP{}
+	if err := json.Unmarshal(ldapConfBytes, &ldapCfg); err != nil {
+		return ConfigLDAP{}, fmt.Errorf("unmarshalling '%s': %v", ldapConfPath, err)
+	}
+	return ldapCfg, nil
+}
+
+// loadSecretStoreKey reads the base64 encoded AES key of the postgres secret store backend.
+func loadSecretStoreKey(keyFile string) ([]byte, error) {
+	keyBytes, err := ioutil.ReadFile(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("reading secret store key '%s': %v", keyFile, err)
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(keyBytes)))
+	if err != nil {
+		return nil, fmt.Errorf("decoding secret store key '%s': %v", keyFile, err)
+	}
+	if len(key) != 16 && len(key) != 24 && len(key) != 32 {
+		return nil, fmt.Errorf("secret store key '%s' must be 16, 24, or 32 bytes, was %d", keyFile, len(key))
+	}
+	return key, nil
+}
+
+// loadSSLCA reads the certificate and private key of the internal certificate authority.
+func loadSSLCA(certFile string, keyFile string) (*x509.Certificate, crypto.Signer, error) {
+	pair, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("loading SSL CA '%s': %v", certFile, err)
+	}
+	cert, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return nil, nil, fmt.Errorf("parsing SSL CA '%s': %v", certFile, err)
+	}
+	if !cert.IsCA {
+		return nil, nil, fmt.Errorf("SSL CA '%s' is not a CA certificate", certFile)
+	}
+	key, ok := pair.PrivateKey.(crypto.Signer)
+	if !ok {
+		return nil, nil, fmt.Errorf("SSL CA key '%s' can't sign", keyFile)
+	}
+	return cert, key, nil
+}
+
+// UserCacheTTL returns the user cache TTL, which is zero if the cache is disabled.
+func (c Config) UserCacheTTL() time.Duration {
+	if c.UserCacheTTLSecs < 0 {
+		return 0
+	}
+	return time.Duration(c.UserCacheTTLSecs) * time.Second
+}
+
+// EventRetention returns how long change events are kept.
+func (c Config) EventRetention() time.Duration {
+	return time.Duration(c.EventRetentionHours) * time.Hour
+}
+
+// SSLCertCheckInterval returns how often delivery service certificates are checked, which is 0 if the check is disabled.
+func (c Config) SSLCertCheckInterval() time.Duration {
+	if c.SSLCertCheckIntervalSecs < 0 {
+		return 0
+	}
+	return time.Duration(c.SSLCertCheckIntervalSecs) * time.Second
+}
+
+// DNSSECRefreshInterval returns how often DNSSEC keys are refreshed, which is 0 if the refresh is disabled.
+func (c Config) DNSSECRefreshInterval() time.Duration {
+	if c.DNSSECRefreshIntervalSecs < 0 {
+		return 0
+	}
+	return time.Duration(c.DNSSECRefreshIntervalSecs) * time.Second
+}
+
+// DBConnectionString returns the connection string of the Traffic Ops database.
+func (c Config) DBConnectionString() string {
+	sslStr := "require"
+	if !c.DB.SSL {
+		sslStr = "disable"
+	}
+	return fmt.Sprintf("postgres://%s:%s@%s/%s?sslmode=%s", c.DB.User, c.DB.Password, c.DB.Hostname, c.DB.DBName, sslStr)
+}
+
 // GetCertPath - extracts path to cert .cert file
 func (c Config) GetCertPath() string {
 	v, ok := c.URL.Query()["cert"]
@@ -166,6 +381,36 @@ func (c Config) GetKeyPath() string {
 const (
 	// MojoliciousConcurrentConnectionsDefault  ...
 	MojoliciousConcurrentConnectionsDefault = 12
+	// SnapshotHistoryRetentionDefault ...
+	SnapshotHistoryRetentionDefault = 10
+	// UserCacheTTLSecsDefault ...
+	UserCacheTTLSecsDefault = 60
+	// EventRetentionHoursDefault ...
+	EventRetentionHoursDefault = 24
+	// WebhookTimeoutSecsDefault ...
+	WebhookTimeoutSecsDefault = 10
+	// WebhookMaxAttemptsDefault ...
+	WebhookMaxAttemptsDefault = 5
+	// WebhookRetrySecsDefault ...
+	WebhookRetrySecsDefault = 5
+	// WebhookWorkersDefault ...
+	WebhookWorkersDefault = 4
+	// DNSSECRefreshIntervalSecsDefault ...
+	DNSSECRefreshIntervalSecsDefault = 3600
+	// SSLCertDaysDefault is the lifetime of certificates generated by the Perl Traffic Ops.
+	SSLCertDaysDefault = 365
+	// SSLCertCheckIntervalSecsDefault ...
+	SSLCertCheckIntervalSecsDefault = 86400
+	// SSLCertWarningDaysDefault ...
+	SSLCertWarningDaysDefault = 30
+	// LDAPSearchQueryDefault is the Active Directory query of the Perl Traffic Ops.
+	LDAPSearchQueryDefault = "(&(objectCategory=person)(objectClass=user)(sAMAccountName=%s))"
+	// LDAPGroupAttributeDefault ...
+	LDAPGroupAttributeDefault = "memberOf"
+	// LDAPTimeoutSecsDefault is the timeout of the Perl Traffic Ops.
+	LDAPTimeoutSecsDefault = 20
+	// LDAPTLSScheme is the scheme of LDAP hosts connected to over TLS.
+	LDAPTLSScheme = "ldaps://"
 )
 
 // ParseConfig validates required fields, and parses non-JSON types
@@ -198,6 +443,96 @@ func ParseConfig(cfg Config) (Config, error) {
 	if cfg.BackendMaxConnections["mojolicious"] == 0 {
 		cfg.BackendMaxConnections["mojolicious"] = MojoliciousConcurrentConnectionsDefault
 	}
+	if cfg.SnapshotHistoryRetention <= 0 {
+		cfg.SnapshotHistoryRetention = SnapshotHistoryRetentionDefault
+	}
+	if cfg.UserCacheTTLSecs == 0 {
+		cfg.UserCacheTTLSecs = UserCacheTTLSecsDefault
+	}
+	if cfg.EventRetentionHours <= 0 {
+		cfg.EventRetentionHours = EventRetentionHoursDefault
+	}
+	if cfg.WebhookTimeoutSecs <= 0 {
+		cfg.WebhookTimeoutSecs = WebhookTimeoutSecsDefault
+	}
+	if cfg.WebhookMaxAttempts <= 0 {
+		cfg.WebhookMaxAttempts = WebhookMaxAttemptsDefault
+	}
+	if cfg.WebhookRetrySecs <= 0 {
+		cfg.WebhookRetrySecs = WebhookRetrySecsDefault
+	}
+	if cfg.WebhookWorkers <= 0 {
+		cfg.WebhookWorkers = WebhookWorkersDefault
+	}
+	if cfg.DNSSECRefreshIntervalSecs == 0 {
+		cfg.DNSSECRefreshIntervalSecs = DNSSECRefreshIntervalSecsDefault
+	}
+	if cfg.SSLCertDays <= 0 {
+		cfg.SSLCertDays = SSLCertDaysDefault
+	}
+	if cfg.SSLCertCheckIntervalSecs == 0 {
+		cfg.SSLCertCheckIntervalSecs = SSLCertCheckIntervalSecsDefault
+	}
+	if cfg.SSLCertWarningDays <= 0 {
+		cfg.SSLCertWarningDays = SSLCertWarningDaysDefault
+	}
+	if cfg.SecretStore.Backend == "" {
+		cfg.SecretStore.Backend = SecretStoreRiak
+	}
+	switch cfg.SecretStore.Backend {
+	case SecretStoreRiak:
+	case SecretStorePostgres:
+		if cfg.SecretStore.KeyFile == "" {
+			missings += "secret_store key_file, "
+		}
+	case SecretStoreFile:
+		if cfg.SecretStore.Directory == "" {
+			missings += "secret_store directory, "
+		}
+	default:
+		return Config{}, fmt.Errorf("unknown secret_store backend '%s'", cfg.SecretStore.Backend)
+	}
+	if cfg.SSLCA.CertFile != "" && cfg.SSLCA.KeyFile == "" {
+		missings += "ssl_ca key_file, "
+	}
+	if len(cfg.Authenticators) == 0 {
+		cfg.Authenticators = []string{AuthenticatorLocal}
+		if cfg.LDAPEnabled {
+			cfg.Authenticators = append(cfg.Authenticators, AuthenticatorLDAP)
+		}
+	}
+	for _, authenticator := range cfg.Authenticators {
+		switch authenticator {
+		case AuthenticatorLocal:
+		case AuthenticatorLDAP:
+			if !cfg.LDAPEnabled {
+				return Config{}, errors.New("the ldap authenticator requires an LDAP conf")
+			}
+		default:
+			return Config{}, fmt.Errorf("unknown authenticator '%s'", authenticator)
+		}
+	}
+	if cfg.LDAPEnabled {
+		if cfg.LDAP.Host == "" {
+			missings += "ldap host, "
+		}
+		ldapTLS := strings.HasPrefix(strings.ToLower(cfg.LDAP.Host), LDAPTLSScheme)
+		if ldapTLS && cfg.LDAP.StartTLS {
+			return Config{}, errors.New("ldap start_tls can't be used with an ldaps:// host")
+		}
+		if cfg.LDAP.Host != "" && !ldapTLS && !cfg.LDAP.StartTLS && !cfg.LDAP.AllowCleartext {
+			return Config{}, errors.New("the ldap host must be ldaps:// or use start_tls, unless allow_cleartext is set")
+		}
+		if cfg.LDAP.SearchQuery == "" {
+			cfg.LDAP.SearchQuery = LDAPSearchQueryDefault
+		}
+		if cfg.LDAP.GroupAttribute == "" {
+			cfg.LDAP.GroupAttribute = LDAPGroupAttributeDefault
+		}
+		if cfg.LDAP.TimeoutSecs <= 0 {
+			cfg.LDAP.TimeoutSecs = LDAPTimeoutSecsDefault
+		}
+	}
 
 	invalidTOURLStr := ""
 	var err error
@@ -20,14 +20,22 @@ package config
  */
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"flag"
 	"fmt"
 	"io/ioutil"
+	"math/big"
 	"os"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/basho/riak-go-client"
 )
@@ -164,6 +172,63 @@ const (
 	   	`
 )
 
+const goodLDAPConfig = `
+{
+	"host": "ldaps://ldap.example.net",
+	"admin_dn": "cn=admin,dc=example,dc=net",
+	"admin_pass": "password",
+	"search_base": "dc=example,dc=net"
+}
+`
+
+func TestParseConfigAuthenticators(t *testing.T) {
+	base := Config{ConfigHypnotoad: ConfigHypnotoad{Listen: []string{"https://[::]:60443"}}, ConfigTrafficOpsGolang: ConfigTrafficOpsGolang{Port: "443"}, Secrets: []string{"secret"}}
+
+	c := base
+	c.Authenticators = []string{AuthenticatorLDAP}
+	if _, err := ParseConfig(c); err == nil {
+		t.Error("expected the ldap authenticator without an LDAP conf to be an error")
+	}
+
+	c = base
+	c.Authenticators = []string{"kerberos"}
+	if _, err := ParseConfig(c); err == nil || err.Error() != "unknown authenticator 'kerberos'" {
+		t.Errorf("expected unknown authenticator error, actual %v", err)
+	}
+
+	c = base
+	c.LDAPEnabled = true
+	c.Authenticators = []string{AuthenticatorLDAP}
+	if _, err := ParseConfig(c); err == nil || !strings.Contains(err.Error(), "ldap host") {
+		t.Errorf("expected missing ldap host error, actual %v", err)
+	}
+}
+
+func TestParseConfigLDAPTLS(t *testing.T) {
+	base := Config{ConfigHypnotoad: ConfigHypnotoad{Listen: []string{"https://[::]:60443"}}, ConfigTrafficOpsGolang: ConfigTrafficOpsGolang{Port: "443"}, Secrets: []string{"secret"}, LDAPEnabled: true}
+
+	tests := []struct {
+		ldap  ConfigLDAP
+		valid bool
+	}{
+		{ConfigLDAP{Host: "ldaps://ldap.example.net"}, true},
+		{ConfigLDAP{Host: "LDAPS://ldap.example.net:636"}, true},
+		{ConfigLDAP{Host: "ldap://ldap.example.net", StartTLS: true}, true},
+		{ConfigLDAP{Host: "ldap.example.net:389", StartTLS: true}, true},
+		{ConfigLDAP{Host: "ldap://ldap.example.net", AllowCleartext: true}, true},
+		{ConfigLDAP{Host: "ldap://ldap.example.net"}, false},
+		{ConfigLDAP{Host: "ldap.example.net:389"}, false},
+		{ConfigLDAP{Host: "ldaps://ldap.example.net", StartTLS: true}, false},
+	}
+	for _, test := range tests {
+		c := base
+		c.LDAP = test.ldap
+		if _, err := ParseConfig(c); (err == nil) != test.valid {
+			t.Errorf("ldap %+v expected valid %v, actual error %v", test.ldap, test.valid, err)
+		}
+	}
+}
+
 func TestLoadConfig(t *testing.T) {
 	var err error
 	var exp string
@@ -195,39 +260,67 @@ func TestLoadConfig(t *testing.T) {
 	}
 	defer os.Remove(goodRiakCfg) // clean up
 
+	goodLDAPCfg, err := tempFileWith([]byte(goodLDAPConfig))
+	if err != nil {
+		t.Errorf("cannot create temp file: %v", err)
+	}
+	defer os.Remove(goodLDAPCfg) // clean up
+
 	// test bad paths
-	_, err = LoadConfig(badPath, badPath, badPath, version)
+	_, err = LoadConfig(badPath, badPath, badPath, "", version)
 	exp = fmt.Sprintf("reading CDN conf '%s'", badPath)
 	if !strings.HasPrefix(err.Error(), exp) {
 		t.Error("expected", exp, "got", err)
 	}
 
 	// bad json in cdn.conf
-	_, err = LoadConfig(badCfg, badCfg, badPath, version)
+	_, err = LoadConfig(badCfg, badCfg, badPath, "", version)
 	exp = fmt.Sprintf("unmarshalling '%s'", badCfg)
 	if !strings.HasPrefix(err.Error(), exp) {
 		t.Error("expected", exp, "got", err)
 	}
 
 	// good cdn.conf, bad db conf
-	_, err = LoadConfig(goodCfg, badPath, badPath, version)
+	_, err = LoadConfig(goodCfg, badPath, badPath, "", version)
 	exp = fmt.Sprintf("reading db conf '%s'", badPath)
 	if !strings.HasPrefix(err.Error(), exp) {
 		t.Error("expected", exp, "got", err)
 	}
 
 	// good cdn.conf,  bad json in database.conf
-	_, err = LoadConfig(goodCfg, badCfg, badPath, version)
+	_, err = LoadConfig(goodCfg, badCfg, badPath, "", version)
 	exp = fmt.Sprintf("unmarshalling '%s'", badCfg)
 	if !strings.HasPrefix(err.Error(), exp) {
 		t.Error("expected", exp, "got", err)
 	}
 
+	// good cdn.conf, good database.conf, bad ldap.conf
+	_, err = LoadConfig(goodCfg, goodDbCfg, goodRiakCfg, badPath, version)
+	exp = fmt.Sprintf("reading LDAP conf '%s'", badPath)
+	if err == nil || !strings.HasPrefix(err.Error(), exp) {
+		t.Error("expected", exp, "got", err)
+	}
+
 	// good cdn.conf,  good database.conf
-	cfg, err = LoadConfig(goodCfg, goodDbCfg, goodRiakCfg, version)
+	cfg, err = LoadConfig(goodCfg, goodDbCfg, goodRiakCfg, "", version)
 	if err != nil {
 		t.Error("Good config -- unexpected error ", err)
 	}
+	if cfg.LDAPEnabled || !reflect.DeepEqual(cfg.Authenticators, []string{AuthenticatorLocal}) {
+		t.Errorf("expected only the local authenticator without an LDAP conf, actual %v", cfg.Authenticators)
+	}
+
+	// good cdn.conf, good database.conf, good ldap.conf
+	ldapCfg, err := LoadConfig(goodCfg, goodDbCfg, goodRiakCfg, goodLDAPCfg, version)
+	if err != nil {
+		t.Error("Good LDAP config -- unexpected error ", err)
+	}
+	if !ldapCfg.LDAPEnabled || ldapCfg.LDAP.Host != "ldaps://ldap.example.net" || ldapCfg.LDAP.SearchQuery != LDAPSearchQueryDefault || ldapCfg.LDAP.GroupAttribute != LDAPGroupAttributeDefault || ldapCfg.LDAP.TimeoutSecs != LDAPTimeoutSecsDefault {
+		t.Errorf("expected the LDAP conf with defaults, actual %+v", ldapCfg.LDAP)
+	}
+	if !reflect.DeepEqual(ldapCfg.Authenticators, []string{AuthenticatorLocal, AuthenticatorLDAP}) {
+		t.Errorf("expected the local and ldap authenticators with an LDAP conf, actual %v", ldapCfg.Authenticators)
+	}
 
 	expectedRiak := riak.AuthOptions{User: "riakuser", Password: "password", TlsConfig: &tls.Config{InsecureSkipVerify: true}}
 
@@ -247,3 +340,57 @@ func TestLoadConfig(t *testing.T) {
 		t.Error("Expected KeyPath() == /etc/pki/tls/private/localhost.key")
 	}
 }
+
+func TestLoadSSLCA(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyFile, err := tempFileWith(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(keyFile)
+
+	for _, isCA := range []bool{true, false} {
+		template := &x509.Certificate{
+			SerialNumber:          big.NewInt(1),
+			Subject:               pkix.Name{CommonName: "Lab CA"},
+			NotBefore:             time.Now(),
+			NotAfter:              time.Now().Add(time.Hour),
+			KeyUsage:              x509.KeyUsageCertSign,
+			BasicConstraintsValid: true,
+			IsCA:                  isCA,
+		}
+		certDER, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		certFile, err := tempFileWith(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.Remove(certFile)
+
+		cert, signer, err := loadSSLCA(certFile, keyFile)
+		if !isCA {
+			if err == nil {
+				t.Error("expected an error loading a certificate which isn't a CA, actual nil")
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("expected the CA to load, actual error %v", err)
+		}
+		if cert.Subject.CommonName != "Lab CA" {
+			t.Errorf("expected CA 'Lab CA', actual '%s'", cert.Subject.CommonName)
+		}
+		if !reflect.DeepEqual(signer.Public(), &key.PublicKey) {
+			t.Error("expected the CA signer to be the CA key")
+		}
+	}
+}
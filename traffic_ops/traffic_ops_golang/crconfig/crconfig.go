@@ -22,11 +22,26 @@ package crconfig
 import (
 	"database/sql"
 	"errors"
+	"time"
 
 	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/metrics"
 )
 
+var snapshotDuration = metrics.NewHistogram("traffic_ops_snapshot_duration_seconds", "Time to make the CRConfig snapshot of a CDN, by CDN.", []float64{.1, .25, .5, 1, 2.5, 5, 10, 30, 60, 120}, "cdn")
+var snapshotErrorsTotal = metrics.NewCounter("traffic_ops_snapshot_errors_total", "Failures making the CRConfig snapshot of a CDN, by CDN.", "cdn")
+
 func Make(db *sql.DB, cdn, user, toHost, reqPath, toVersion string) (*tc.CRConfig, error) {
+	start := time.Now()
+	crc, err := makeCRConfig(db, cdn, user, toHost, reqPath, toVersion)
+	snapshotDuration.Observe(time.Since(start).Seconds(), cdn)
+	if err != nil {
+		snapshotErrorsTotal.Inc(cdn)
+	}
+	return crc, err
+}
+
+func makeCRConfig(db *sql.DB, cdn, user, toHost, reqPath, toVersion string) (*tc.CRConfig, error) {
 	crc := tc.CRConfig{}
 	err := error(nil)
 
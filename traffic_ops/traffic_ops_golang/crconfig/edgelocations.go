@@ -50,10 +50,15 @@ and (st.name = 'REPORTED' or st.name = 'ONLINE' or st.name = 'ADMIN_DOWN')
 	for rows.Next() {
 		cachegroup := ""
 		ttype := ""
-		latlon := tc.CRConfigLatitudeLongitude{}
-		if err := rows.Scan(&cachegroup, &ttype, &latlon.Lat, &latlon.Lon); err != nil {
+		lat := sql.NullFloat64{}
+		lon := sql.NullFloat64{}
+		if err := rows.Scan(&cachegroup, &ttype, &lat, &lon); err != nil {
 			return nil, nil, errors.New("Error scanning cachegroup: " + err.Error())
 		}
+		if !lat.Valid || !lon.Valid {
+			continue // cachegroups without coordinates are omitted, and reported by Validate
+		}
+		latlon := tc.CRConfigLatitudeLongitude{Lat: lat.Float64, Lon: lon.Float64}
 		if ttype == RouterTypeName {
 			routerLocs[cachegroup] = latlon
 		} else {
@@ -74,3 +74,26 @@ func TestMakeLocations(t *testing.T) {
 		t.Errorf("makeLocations expected: %+v, actual: %+v", expectedRouterLocs, actualRouterLocs)
 	}
 }
+
+func TestMakeLocationsNoCoordinates(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	cdn := "mycdn"
+	rows := sqlmock.NewRows([]string{"name", "type", "latitude", "longitude"})
+	rows = rows.AddRow("cache0", EdgeTypePrefix, 1.0, 2.0)
+	rows = rows.AddRow("cache1", EdgeTypePrefix, nil, nil)
+	mock.ExpectQuery("select").WithArgs(cdn).WillReturnRows(rows)
+
+	edgeLocs, _, err := makeLocations(cdn, db)
+	if err != nil {
+		t.Fatalf("makeLocations expected: nil error, actual: %v", err)
+	}
+	expected := map[string]tc.CRConfigLatitudeLongitude{"cache0": tc.CRConfigLatitudeLongitude{Lat: 1, Lon: 2}}
+	if !reflect.DeepEqual(expected, edgeLocs) {
+		t.Errorf("makeLocations expected: %+v, actual: %+v", expected, edgeLocs)
+	}
+}
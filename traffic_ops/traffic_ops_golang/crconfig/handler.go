@@ -25,6 +25,7 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/apache/incubator-trafficcontrol/lib/go-log"
@@ -32,11 +33,16 @@ import (
 	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/api"
 	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/auth"
 	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/config"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/events"
 
 	"github.com/jmoiron/sqlx"
 )
 
-const PrivLevel = auth.PrivLevelAdmin
+// SnapshotReadCapability is required to view CRConfig snapshots, and the unsnapshotted CRConfig.
+const SnapshotReadCapability = "cdn-config-snapshot-read"
+
+// SnapshotWriteCapability is required to snapshot a CRConfig, or roll back to a previous snapshot.
+const SnapshotWriteCapability = "cdn-config-snapshot-write"
 
 // Handler creates and serves the CRConfig from the raw SQL data.
 // This MUST only be used for debugging or previewing, the raw un-snapshotted data MUST NOT be used by any component of the CDN.
@@ -182,20 +188,275 @@ func SnapshotHandler(db *sqlx.DB, cfg config.Config) http.HandlerFunc {
 			cdn = name
 		}
 
-		crConfig, err := Make(db.DB, cdn, user.UserName, r.Host, r.URL.Path, cfg.Version)
+		force, err := getForceParam(params)
+		if err != nil {
+			handleErrs(http.StatusBadRequest, err)
+			return
+		}
+
+		crConfig, validation, err := MakeAndValidate(db.DB, cdn, user.UserName, r.Host, r.URL.Path, cfg.Version)
 		if err != nil {
 			handleErrs(http.StatusInternalServerError, err)
 			return
 		}
+		if errs := checkValidation(cdn, validation, force); len(errs) > 0 {
+			handleErrs(http.StatusBadRequest, errs...)
+			return
+		}
 
-		if err := Snapshot(db.DB, crConfig); err != nil {
+		if err := Snapshot(db.DB, crConfig, cfg.SnapshotHistoryRetention); err != nil {
 			handleErrs(http.StatusInternalServerError, err)
 			return
 		}
+		publishSnapshot(cdn, *user, db)
 		w.WriteHeader(http.StatusOK) // TODO change to 204 No Content in new version
 	}
 }
 
+// SnapshotValidateHandler creates the CRConfig from the raw SQL data and serves its validation errors and warnings, without snapshotting it. This is a dry run of the validation done by SnapshotHandler.
+func SnapshotValidateHandler(db *sqlx.DB, cfg config.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		handleErrs := tc.GetHandleErrorsFunc(w, r)
+		params, err := api.GetCombinedParams(r)
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, err)
+			return
+		}
+		cdn, ok := params["cdn"]
+		if !ok {
+			handleErrs(http.StatusInternalServerError, errors.New("params missing CDN"))
+			return
+		}
+
+		ctx := r.Context()
+		user, err := auth.GetCurrentUser(ctx)
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, err)
+			return
+		}
+
+		_, validation, err := MakeAndValidate(db.DB, cdn, user.UserName, r.Host, r.URL.Path, cfg.Version)
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, err)
+			return
+		}
+		respBts, err := json.Marshal(tc.CRConfigValidationResponse{Response: validation})
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(respBts)
+	}
+}
+
+// getForceParam returns whether the force parameter is set, to snapshot even if the CRConfig has validation errors.
+func getForceParam(params map[string]string) (bool, error) {
+	forceStr, ok := params[ForceParam]
+	if !ok {
+		return false, nil
+	}
+	force, err := strconv.ParseBool(forceStr)
+	if err != nil {
+		return false, errors.New("param " + ForceParam + " must be a boolean")
+	}
+	return force, nil
+}
+
+// checkValidation logs the validation errors and warnings of the CDN's CRConfig, and returns the errors which block its snapshot. If force is true, nothing blocks the snapshot, and no errors are returned.
+func checkValidation(cdn string, validation tc.CRConfigValidation, force bool) []error {
+	for _, warning := range validation.Warnings {
+		log.Warnln("CDN " + cdn + " CRConfig validation: " + warning)
+	}
+	if len(validation.Errors) == 0 {
+		return nil
+	}
+	if force {
+		for _, err := range validation.Errors {
+			log.Warnln("CDN " + cdn + " CRConfig validation error, snapshot forced: " + err)
+		}
+		return nil
+	}
+	errs := []error{errors.New("CRConfig has validation errors, snapshot with " + ForceParam + "=true to snapshot anyway")}
+	for _, err := range validation.Errors {
+		errs = append(errs, errors.New(err))
+	}
+	return errs
+}
+
+// SnapshotDiffHandler serves the differences between the CDN's current snapshot and the CRConfig which would be created by snapshotting it now.
+func SnapshotDiffHandler(db *sqlx.DB, cfg config.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		handleErrs := tc.GetHandleErrorsFunc(w, r)
+		params, err := api.GetCombinedParams(r)
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, err)
+			return
+		}
+		cdn, ok := params["cdn"]
+		if !ok {
+			handleErrs(http.StatusInternalServerError, errors.New("params missing CDN"))
+			return
+		}
+
+		ctx := r.Context()
+		user, err := auth.GetCurrentUser(ctx)
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, err)
+			return
+		}
+
+		snapshot, cdnExists, err := GetSnapshot(db.DB, cdn)
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, errors.New("getting snapshot: "+err.Error()))
+			return
+		}
+		if !cdnExists {
+			handleErrs(http.StatusNotFound, errors.New("CDN not found"))
+			return
+		}
+
+		crConfig, err := Make(db.DB, cdn, user.UserName, r.Host, r.URL.Path, cfg.Version)
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, err)
+			return
+		}
+		crConfigBts, err := json.Marshal(crConfig)
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, err)
+			return
+		}
+
+		diff, err := Diff([]byte(snapshot), crConfigBts)
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, errors.New("diffing snapshot: "+err.Error()))
+			return
+		}
+		respBts, err := json.Marshal(tc.SnapshotDiffResponse{Response: diff})
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(respBts)
+	}
+}
+
+// SnapshotHistoryHandler serves the snapshots kept in the history of the CDN, newest first.
+func SnapshotHistoryHandler(db *sqlx.DB, cfg config.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		handleErrs := tc.GetHandleErrorsFunc(w, r)
+		params, err := api.GetCombinedParams(r)
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, err)
+			return
+		}
+		cdn, ok := params["cdn"]
+		if !ok {
+			handleErrs(http.StatusInternalServerError, errors.New("params missing CDN"))
+			return
+		}
+
+		history, err := GetSnapshotHistory(db.DB, cdn)
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, errors.New("getting snapshot history: "+err.Error()))
+			return
+		}
+		respBts, err := json.Marshal(tc.SnapshotHistoryResponse{Response: history})
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(respBts)
+	}
+}
+
+// SnapshotRollbackHandler makes a snapshot from the CDN's history its current snapshot again, writing a change log entry. The snapshot is given by the id parameter, or is the snapshot before the current one if no id is given.
+func SnapshotRollbackHandler(db *sqlx.DB, cfg config.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		handleErrs := tc.GetHandleErrorsFunc(w, r)
+		params, err := api.GetCombinedParams(r)
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, err)
+			return
+		}
+		cdn, ok := params["cdn"]
+		if !ok {
+			handleErrs(http.StatusInternalServerError, errors.New("params missing CDN"))
+			return
+		}
+		id := (*int)(nil)
+		if idStr, ok := params["id"]; ok {
+			idInt, err := strconv.Atoi(idStr)
+			if err != nil {
+				handleErrs(http.StatusBadRequest, errors.New("param id is not an integer"))
+				return
+			}
+			id = &idInt
+		}
+
+		ctx := r.Context()
+		user, err := auth.GetCurrentUser(ctx)
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, err)
+			return
+		}
+
+		tx, err := db.Beginx()
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, errors.New("beginning transaction: "+err.Error()))
+			return
+		}
+		commitTx := false
+		defer func() {
+			if commitTx {
+				return
+			}
+			if err := tx.Rollback(); err != nil {
+				log.Errorln("rolling back snapshot rollback transaction: " + err.Error())
+			}
+		}()
+
+		snapshot, ok, err := RollbackSnapshot(tx.Tx, cdn, id, cfg.SnapshotHistoryRetention)
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, errors.New("rolling back snapshot: "+err.Error()))
+			return
+		}
+		if !ok {
+			handleErrs(http.StatusNotFound, errors.New("no snapshot in the history of CDN '"+cdn+"' to roll back to"))
+			return
+		}
+
+		msg := "CDN: " + cdn + ", Rolled back snapshot to snapshot " + strconv.Itoa(snapshot.ID) + " of " + snapshot.LastUpdated.Time.Format(tc.TimeLayout)
+		if err := api.CreateChangeLogRawTx(api.ApiChange, msg, *user, tx); err != nil {
+			handleErrs(http.StatusInternalServerError, errors.New("writing change log: "+err.Error()))
+			return
+		}
+		if err := events.Publish(tc.EventTypeSnapshot, "Snapshot of CDN "+cdn+" rolled back to snapshot "+strconv.Itoa(snapshot.ID), tc.SnapshotEventData{CDN: cdn, RolledBackTo: &snapshot.ID}, *user, tx); err != nil {
+			handleErrs(http.StatusInternalServerError, errors.New("publishing snapshot event: "+err.Error()))
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			handleErrs(http.StatusInternalServerError, errors.New("committing transaction: "+err.Error()))
+			return
+		}
+		commitTx = true
+
+		resp := struct {
+			Response tc.SnapshotHistory `json:"response"`
+			tc.Alerts
+		}{snapshot, tc.CreateAlerts(tc.SuccessLevel, "Snapshot of CDN "+cdn+" rolled back to snapshot "+strconv.Itoa(snapshot.ID)+".")}
+		respBts, err := json.Marshal(resp)
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(respBts)
+	}
+}
+
 // SnapshotGUIHandler creates the CRConfig JSON and writes it to the snapshot table in the database. The response emulates the old Perl UI function. This should go away when the old Perl UI ceases to exist.
 func SnapshotOldGUIHandler(db *sqlx.DB, cfg config.Config) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -224,23 +485,46 @@ func SnapshotOldGUIHandler(db *sqlx.DB, cfg config.Config) http.HandlerFunc {
 			return
 		}
 
-		crConfig, err := Make(db.DB, cdn, user.UserName, r.Host, r.URL.Path, cfg.Version)
+		force, err := getForceParam(params)
+		if err != nil {
+			log.Errorln(r.RemoteAddr + " " + err.Error())
+			writePerlHTMLErr(w, r, err)
+			return
+		}
+
+		crConfig, validation, err := MakeAndValidate(db.DB, cdn, user.UserName, r.Host, r.URL.Path, cfg.Version)
 		if err != nil {
 			log.Errorln(r.RemoteAddr + " making CRConfig: " + err.Error())
 			writePerlHTMLErr(w, r, err)
 			return
 		}
+		if errs := checkValidation(cdn, validation, force); len(errs) > 0 {
+			msgs := []string{}
+			for _, err := range errs {
+				msgs = append(msgs, err.Error())
+			}
+			writePerlHTMLErr(w, r, errors.New(strings.Join(msgs, "; ")))
+			return
+		}
 
-		if err := Snapshot(db.DB, crConfig); err != nil {
+		if err := Snapshot(db.DB, crConfig, cfg.SnapshotHistoryRetention); err != nil {
 			log.Errorln(r.RemoteAddr + " making CRConfig: " + err.Error())
 			writePerlHTMLErr(w, r, err)
 			return
 		}
+		publishSnapshot(cdn, *user, db)
 
 		http.Redirect(w, r, "/tools/flash_and_close/"+url.PathEscape("Successfully wrote the CRConfig.json!"), http.StatusFound)
 	}
 }
 
+// publishSnapshot publishes the event of a new snapshot of the CDN. The snapshot was already made, so errors are logged rather than failing the request.
+func publishSnapshot(cdn string, user auth.CurrentUser, db *sqlx.DB) {
+	if err := events.Publish(tc.EventTypeSnapshot, "Snapshot of CDN "+cdn, tc.SnapshotEventData{CDN: cdn}, user, db); err != nil {
+		log.Errorln("publishing snapshot event for CDN " + cdn + ": " + err.Error())
+	}
+}
+
 func writePerlHTMLErr(w http.ResponseWriter, r *http.Request, err error) {
 	http.Redirect(w, r, "/tools/flash_and_close/"+url.PathEscape("Error: "+err.Error()), http.StatusFound)
 }
@@ -29,8 +29,8 @@ import (
 	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
 )
 
-// Snapshot takes the CRConfig JSON-serializable object (which may be generated via crconfig.Make), and writes it to the snapshot table.
-func Snapshot(db *sql.DB, crc *tc.CRConfig) error {
+// Snapshot takes the CRConfig JSON-serializable object (which may be generated via crconfig.Make), and writes it to the snapshot table. It's also added to the snapshot history, which keeps the last historyRetention snapshots of each CDN.
+func Snapshot(db *sql.DB, crc *tc.CRConfig, historyRetention int) error {
 	log.Errorln("DEBUG calling Snapshot")
 	bts, err := json.Marshal(crc)
 	if err != nil {
@@ -41,10 +41,45 @@ func Snapshot(db *sql.DB, crc *tc.CRConfig) error {
 		date = time.Unix(*crc.Stats.DateUnixSeconds, 0)
 	}
 	log.Errorf("DEBUG calling Snapshot, writing %+v\n", date)
+	cdn := ""
+	if crc.Stats.CDNName != nil {
+		cdn = *crc.Stats.CDNName
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return errors.New("beginning transaction: " + err.Error())
+	}
+	if err := writeSnapshot(tx, cdn, bts, date, historyRetention); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			log.Errorln("rolling back snapshot transaction: " + rbErr.Error())
+		}
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return errors.New("committing snapshot transaction: " + err.Error())
+	}
+	return nil
+}
+
+// writeSnapshot writes the given snapshot content to the snapshot table and the snapshot history, deleting the CDN's history beyond historyRetention.
+func writeSnapshot(tx *sql.Tx, cdn string, content []byte, date time.Time, historyRetention int) error {
 	q := `insert into snapshot (cdn, content, last_updated) values ($1, $2, $3) on conflict(cdn) do update set content=$2, last_updated=$3`
-	if _, err := db.Exec(q, crc.Stats.CDNName, bts, date); err != nil {
+	if _, err := tx.Exec(q, cdn, content, date); err != nil {
 		return errors.New("Error inserting the snapshot into database: " + err.Error())
 	}
+	q = `insert into snapshot_history (cdn, content, last_updated) values ($1, $2, $3)`
+	if _, err := tx.Exec(q, cdn, content, date); err != nil {
+		return errors.New("Error inserting the snapshot into history: " + err.Error())
+	}
+	q = `
+delete from snapshot_history
+where cdn = $1
+and id not in (select id from snapshot_history where cdn = $1 order by id desc limit $2)
+`
+	if _, err := tx.Exec(q, cdn, historyRetention); err != nil {
+		return errors.New("Error deleting old snapshot history: " + err.Error())
+	}
 	return nil
 }
 
@@ -0,0 +1,177 @@
+package crconfig
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
+)
+
+// GetSnapshotHistory returns the snapshots in the history of the given CDN, newest first.
+func GetSnapshotHistory(db *sql.DB, cdn string) ([]tc.SnapshotHistory, error) {
+	q := `
+select id, cdn, content->'stats'->>'tm_user', last_updated
+from snapshot_history
+where cdn = $1
+order by id desc
+`
+	rows, err := db.Query(q, cdn)
+	if err != nil {
+		return nil, errors.New("Error querying snapshot history: " + err.Error())
+	}
+	defer rows.Close()
+	history := []tc.SnapshotHistory{}
+	for rows.Next() {
+		h := tc.SnapshotHistory{}
+		if err := rows.Scan(&h.ID, &h.CDN, &h.TMUser, &h.LastUpdated); err != nil {
+			return nil, errors.New("Error scanning snapshot history: " + err.Error())
+		}
+		history = append(history, h)
+	}
+	return history, nil
+}
+
+// RollbackSnapshot makes the snapshot with the given history ID the current snapshot of the CDN again, adding it to the history as the newest snapshot. If id is nil, the snapshot before the current one is used.
+// The snapshot history entry which was rolled back to is returned. If the CDN has no such snapshot, false is returned.
+func RollbackSnapshot(tx *sql.Tx, cdn string, id *int, historyRetention int) (tc.SnapshotHistory, bool, error) {
+	h := tc.SnapshotHistory{}
+	content := []byte{}
+	q := `select id, cdn, content->'stats'->>'tm_user', last_updated, content from snapshot_history where cdn = $1`
+	args := []interface{}{cdn}
+	if id != nil {
+		q += ` and id = $2`
+		args = append(args, *id)
+	} else {
+		q += ` order by id desc offset 1 limit 1`
+	}
+	if err := tx.QueryRow(q, args...).Scan(&h.ID, &h.CDN, &h.TMUser, &h.LastUpdated, &content); err != nil {
+		if err == sql.ErrNoRows {
+			return tc.SnapshotHistory{}, false, nil
+		}
+		return tc.SnapshotHistory{}, false, errors.New("Error querying snapshot history: " + err.Error())
+	}
+	date := time.Now()
+	content, err := setStatsDate(content, date)
+	if err != nil {
+		return tc.SnapshotHistory{}, false, errors.New("setting the date of snapshot " + strconv.Itoa(h.ID) + ": " + err.Error())
+	}
+	if err := writeSnapshot(tx, cdn, content, date, historyRetention); err != nil {
+		return tc.SnapshotHistory{}, false, err
+	}
+	return h, true, nil
+}
+
+// setStatsDate returns the CRConfig JSON with its stats date set to the given time. Traffic Router ignores CRConfigs which aren't newer than the one it has, so a rolled back snapshot must be dated when it's rolled back, not when it was first taken.
+// The CRConfig is changed as generic JSON, so snapshots written by other versions of Traffic Ops are otherwise unchanged.
+func setStatsDate(crConfigJSON []byte, date time.Time) ([]byte, error) {
+	crConfig := map[string]json.RawMessage{}
+	if err := json.Unmarshal(crConfigJSON, &crConfig); err != nil {
+		return nil, errors.New("unmarshalling CRConfig: " + err.Error())
+	}
+	stats := map[string]interface{}{}
+	if statsJSON, ok := crConfig["stats"]; ok {
+		if err := json.Unmarshal(statsJSON, &stats); err != nil {
+			return nil, errors.New("unmarshalling CRConfig stats: " + err.Error())
+		}
+	}
+	stats["date"] = date.Unix()
+	statsJSON, err := json.Marshal(stats)
+	if err != nil {
+		return nil, errors.New("marshalling CRConfig stats: " + err.Error())
+	}
+	crConfig["stats"] = statsJSON
+	return json.Marshal(crConfig)
+}
+
+// crConfigSections is the parts of a CRConfig which are diffed, as generic JSON, so snapshots written by other versions of Traffic Ops can be compared.
+type crConfigSections struct {
+	Config           map[string]interface{} `json:"config"`
+	ContentServers   map[string]interface{} `json:"contentServers"`
+	ContentRouters   map[string]interface{} `json:"contentRouters"`
+	Monitors         map[string]interface{} `json:"monitors"`
+	DeliveryServices map[string]interface{} `json:"deliveryServices"`
+}
+
+// Diff returns the differences between the old and new CRConfig JSON. The old snapshot may be an empty object, if the CDN has never been snapshotted.
+func Diff(oldJSON []byte, newJSON []byte) (tc.SnapshotDiff, error) {
+	old := crConfigSections{}
+	if err := json.Unmarshal(oldJSON, &old); err != nil {
+		return tc.SnapshotDiff{}, errors.New("unmarshalling old CRConfig: " + err.Error())
+	}
+	new := crConfigSections{}
+	if err := json.Unmarshal(newJSON, &new); err != nil {
+		return tc.SnapshotDiff{}, errors.New("unmarshalling new CRConfig: " + err.Error())
+	}
+	return tc.SnapshotDiff{
+		ContentServers:   diffSet(old.ContentServers, new.ContentServers),
+		ContentRouters:   diffSet(old.ContentRouters, new.ContentRouters),
+		Monitors:         diffSet(old.Monitors, new.Monitors),
+		DeliveryServices: diffSet(old.DeliveryServices, new.DeliveryServices),
+		Config:           diffConfig(old.Config, new.Config),
+	}, nil
+}
+
+func diffSet(old map[string]interface{}, new map[string]interface{}) tc.SnapshotDiffSet {
+	diff := tc.SnapshotDiffSet{Added: []string{}, Removed: []string{}, Changed: []string{}}
+	for name, newVal := range new {
+		oldVal, ok := old[name]
+		if !ok {
+			diff.Added = append(diff.Added, name)
+		} else if !reflect.DeepEqual(oldVal, newVal) {
+			diff.Changed = append(diff.Changed, name)
+		}
+	}
+	for name := range old {
+		if _, ok := new[name]; !ok {
+			diff.Removed = append(diff.Removed, name)
+		}
+	}
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	sort.Strings(diff.Changed)
+	return diff
+}
+
+func diffConfig(old map[string]interface{}, new map[string]interface{}) []tc.SnapshotConfigDiff {
+	names := []string{}
+	for name, newVal := range new {
+		if oldVal, ok := old[name]; !ok || !reflect.DeepEqual(oldVal, newVal) {
+			names = append(names, name)
+		}
+	}
+	for name := range old {
+		if _, ok := new[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	diffs := make([]tc.SnapshotConfigDiff, 0, len(names))
+	for _, name := range names {
+		diffs = append(diffs, tc.SnapshotConfigDiff{Name: name, Old: old[name], New: new[name]})
+	}
+	return diffs
+}
@@ -0,0 +1,163 @@
+package crconfig
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestDiff(t *testing.T) {
+	old := `{
+  "config": {"domain_name": "old.example", "removed.param": "1", "unchanged": "2"},
+  "contentServers": {"edge1": {"status": "REPORTED"}, "edge2": {"status": "REPORTED"}, "edge3": {"status": "REPORTED"}},
+  "deliveryServices": {"ds1": {"protocol": {"acceptHttps": "false"}}},
+  "stats": {"date": 1}
+}`
+	new := `{
+  "config": {"domain_name": "new.example", "added.param": "3", "unchanged": "2"},
+  "contentServers": {"edge1": {"status": "REPORTED"}, "edge2": {"status": "ADMIN_DOWN"}, "edge4": {"status": "REPORTED"}},
+  "deliveryServices": {"ds1": {"protocol": {"acceptHttps": "true"}}, "ds2": {}},
+  "stats": {"date": 2}
+}`
+	actual, err := Diff([]byte(old), []byte(new))
+	if err != nil {
+		t.Fatalf("Diff err expected: nil, actual: %v", err)
+	}
+
+	expected := tc.SnapshotDiff{
+		ContentServers:   tc.SnapshotDiffSet{Added: []string{"edge4"}, Removed: []string{"edge3"}, Changed: []string{"edge2"}},
+		ContentRouters:   tc.SnapshotDiffSet{Added: []string{}, Removed: []string{}, Changed: []string{}},
+		Monitors:         tc.SnapshotDiffSet{Added: []string{}, Removed: []string{}, Changed: []string{}},
+		DeliveryServices: tc.SnapshotDiffSet{Added: []string{"ds2"}, Removed: []string{}, Changed: []string{"ds1"}},
+		Config: []tc.SnapshotConfigDiff{
+			{Name: "added.param", Old: nil, New: "3"},
+			{Name: "domain_name", Old: "old.example", New: "new.example"},
+			{Name: "removed.param", Old: "1", New: nil},
+		},
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Diff expected: %+v, actual: %+v", expected, actual)
+	}
+}
+
+func TestDiffNoSnapshot(t *testing.T) {
+	actual, err := Diff([]byte(`{}`), []byte(`{"contentServers": {"edge1": {}}}`))
+	if err != nil {
+		t.Fatalf("Diff err expected: nil, actual: %v", err)
+	}
+	if !reflect.DeepEqual(actual.ContentServers.Added, []string{"edge1"}) {
+		t.Errorf("Diff added servers expected: [edge1], actual: %+v", actual.ContentServers.Added)
+	}
+}
+
+func TestRollbackSnapshot(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	cdn := "mycdn"
+	content := []byte(`{"stats":{"CDN_name":"mycdn","tm_user":"admin"}}`)
+	date := time.Now().Add(-time.Hour)
+
+	mock.ExpectBegin()
+	rows := sqlmock.NewRows([]string{"id", "cdn", "tm_user", "last_updated", "content"})
+	rows = rows.AddRow(41, cdn, "admin", date, content)
+	mock.ExpectQuery("select").WithArgs(cdn).WillReturnRows(rows)
+	rolledBack := &rolledBackContent{after: time.Now().Add(-time.Second).Unix()}
+	mock.ExpectExec("insert into snapshot ").WithArgs(cdn, rolledBack, RecentTime{}).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("insert into snapshot_history").WithArgs(cdn, rolledBack, RecentTime{}).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("delete from snapshot_history").WithArgs(cdn, 10).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("beginning transaction: %v", err)
+	}
+	actual, ok, err := RollbackSnapshot(tx, cdn, nil, 10)
+	if err != nil {
+		t.Fatalf("RollbackSnapshot err expected: nil, actual: %v", err)
+	}
+	if !ok {
+		t.Fatalf("RollbackSnapshot ok expected: true, actual: false")
+	}
+	if actual.ID != 41 || actual.CDN != cdn || actual.TMUser == nil || *actual.TMUser != "admin" {
+		t.Errorf("RollbackSnapshot expected: snapshot 41 of %s by admin, actual: %+v", cdn, actual)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("RollbackSnapshot expected all queries, actual: %v", err)
+	}
+}
+
+// rolledBackContent matches rolled back CRConfig JSON from the test snapshot, whose stats date has been bumped to after the given unix time.
+type rolledBackContent struct {
+	after int64
+}
+
+func (c *rolledBackContent) Match(v driver.Value) bool {
+	b, ok := v.([]byte)
+	if !ok {
+		return false
+	}
+	crc := tc.CRConfig{}
+	if err := json.Unmarshal(b, &crc); err != nil {
+		return false
+	}
+	return crc.Stats.CDNName != nil && *crc.Stats.CDNName == "mycdn" &&
+		crc.Stats.TMUser != nil && *crc.Stats.TMUser == "admin" &&
+		crc.Stats.DateUnixSeconds != nil && *crc.Stats.DateUnixSeconds >= c.after
+}
+
+// RecentTime matches times within a minute of now.
+type RecentTime struct{}
+
+func (a RecentTime) Match(v driver.Value) bool {
+	t, ok := v.(time.Time)
+	return ok && time.Since(t) < time.Minute
+}
+
+func TestRollbackSnapshotNoHistory(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	id := 7
+	mock.ExpectBegin()
+	rows := sqlmock.NewRows([]string{"id", "cdn", "tm_user", "last_updated", "content"})
+	mock.ExpectQuery("select").WithArgs("mycdn", id).WillReturnRows(rows)
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("beginning transaction: %v", err)
+	}
+	if _, ok, err := RollbackSnapshot(tx, "mycdn", &id, 10); err != nil || ok {
+		t.Errorf("RollbackSnapshot expected: not found, actual: ok %v err %v", ok, err)
+	}
+}
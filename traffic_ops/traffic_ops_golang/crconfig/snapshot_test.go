@@ -87,7 +87,11 @@ func (a Any) Match(v driver.Value) bool {
 }
 
 func MockSnapshot(mock sqlmock.Sqlmock, expected []byte, cdn string) {
-	mock.ExpectExec("insert").WithArgs(cdn, expected, AnyTime{}).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectBegin()
+	mock.ExpectExec("insert into snapshot ").WithArgs(cdn, expected, AnyTime{}).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("insert into snapshot_history").WithArgs(cdn, expected, AnyTime{}).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("delete from snapshot_history").WithArgs(cdn, 10).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
 }
 
 func TestSnapshot(t *testing.T) {
@@ -108,7 +112,10 @@ func TestSnapshot(t *testing.T) {
 	}
 	MockSnapshot(mock, expected, cdn)
 
-	if err := Snapshot(db, crc); err != nil {
+	if err := Snapshot(db, crc, 10); err != nil {
 		t.Fatalf("GetSnapshot err expected: nil, actual: %v", err)
 	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Snapshot expected all queries, actual: %v", err)
+	}
 }
@@ -0,0 +1,158 @@
+package crconfig
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
+)
+
+// Delivery service types which don't need available edges, because Traffic Router doesn't route clients to their caches.
+const (
+	SteeringTypeName       = "STEERING"
+	ClientSteeringTypeName = "CLIENT_STEERING"
+	AnyMapTypeName         = "ANY_MAP"
+)
+
+// ForceParam is the parameter which snapshots a CRConfig even if it has validation errors.
+const ForceParam = "force"
+
+// Validate checks the CRConfig created by Make for problems which would break routing, before it's snapshotted.
+// The dsTypes are the type names of the CRConfig's delivery services, which the CRConfig itself doesn't contain, and may be created by getDSTypes.
+// Errors are delivery services with no regexes, or no available edges, and edges or routers whose cachegroup has no coordinates. Warnings are coordinates of 0,0, which are usually unset, and CDNs without routers or monitors.
+func Validate(crc *tc.CRConfig, dsTypes map[string]string) tc.CRConfigValidation {
+	v := tc.CRConfigValidation{Errors: []string{}, Warnings: []string{}}
+
+	availableDSes := map[string]struct{}{}
+	for host, s := range crc.ContentServers {
+		if s.ServerType == nil || !strings.HasPrefix(*s.ServerType, EdgeTypePrefix) {
+			continue
+		}
+		if s.CacheGroup == nil {
+			v.Errors = append(v.Errors, "edge cache '"+host+"' has no cachegroup")
+		} else if _, ok := crc.EdgeLocations[*s.CacheGroup]; !ok {
+			v.Errors = append(v.Errors, "edge cache '"+host+"' cachegroup '"+*s.CacheGroup+"' has no coordinates")
+		}
+		if s.ServerStatus == nil || (*s.ServerStatus != tc.CRConfigServerStatus(tc.CacheStatusReported) && *s.ServerStatus != tc.CRConfigServerStatus(tc.CacheStatusOnline)) {
+			continue
+		}
+		for ds := range s.DeliveryServices {
+			availableDSes[ds] = struct{}{}
+		}
+	}
+
+	for host, r := range crc.ContentRouters {
+		if r.Location == nil {
+			v.Errors = append(v.Errors, "router '"+host+"' has no cachegroup")
+		} else if _, ok := crc.RouterLocations[*r.Location]; !ok {
+			v.Errors = append(v.Errors, "router '"+host+"' cachegroup '"+*r.Location+"' has no coordinates")
+		}
+	}
+
+	for name, ds := range crc.DeliveryServices {
+		dsType := dsTypes[name]
+		if dsType != AnyMapTypeName && !hasMatchList(ds) {
+			v.Errors = append(v.Errors, "delivery service '"+name+"' has no regexes")
+		}
+		if dsType != AnyMapTypeName && dsType != SteeringTypeName && dsType != ClientSteeringTypeName {
+			if _, ok := availableDSes[name]; !ok {
+				v.Errors = append(v.Errors, "delivery service '"+name+"' has no available edge caches")
+			}
+		}
+		if ds.MissLocation != nil && ds.MissLocation.Lat == 0 && ds.MissLocation.Lon == 0 {
+			v.Warnings = append(v.Warnings, "delivery service '"+name+"' miss location is 0,0")
+		}
+	}
+
+	for name, loc := range crc.EdgeLocations {
+		if loc.Lat == 0 && loc.Lon == 0 {
+			v.Warnings = append(v.Warnings, "edge cachegroup '"+name+"' coordinates are 0,0")
+		}
+	}
+	for name, loc := range crc.RouterLocations {
+		if loc.Lat == 0 && loc.Lon == 0 {
+			v.Warnings = append(v.Warnings, "router cachegroup '"+name+"' coordinates are 0,0")
+		}
+	}
+
+	if len(crc.ContentRouters) == 0 {
+		v.Warnings = append(v.Warnings, "CDN has no routers")
+	}
+	if len(crc.Monitors) == 0 {
+		v.Warnings = append(v.Warnings, "CDN has no monitors")
+	}
+
+	sort.Strings(v.Errors)
+	sort.Strings(v.Warnings)
+	return v
+}
+
+func hasMatchList(ds tc.CRConfigDeliveryService) bool {
+	for _, ms := range ds.MatchSets {
+		if ms != nil && len(ms.MatchList) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// getDSTypes returns the type names of the active delivery services of the CDN, as a map of xml_ids to type names.
+func getDSTypes(cdn string, db *sql.DB) (map[string]string, error) {
+	q := `
+select d.xml_id, t.name as type
+from deliveryservice as d
+inner join type as t on t.id = d.type
+where d.cdn_id = (select id from cdn where name = $1)
+and d.active = true
+`
+	rows, err := db.Query(q, cdn)
+	if err != nil {
+		return nil, errors.New("querying deliveryservice types: " + err.Error())
+	}
+	defer rows.Close()
+
+	dsTypes := map[string]string{}
+	for rows.Next() {
+		xmlID := ""
+		ttype := ""
+		if err := rows.Scan(&xmlID, &ttype); err != nil {
+			return nil, errors.New("scanning deliveryservice types: " + err.Error())
+		}
+		dsTypes[xmlID] = ttype
+	}
+	return dsTypes, nil
+}
+
+// MakeAndValidate creates the CRConfig with Make, and validates it with Validate.
+func MakeAndValidate(db *sql.DB, cdn, user, toHost, reqPath, toVersion string) (*tc.CRConfig, tc.CRConfigValidation, error) {
+	crc, err := Make(db, cdn, user, toHost, reqPath, toVersion)
+	if err != nil {
+		return nil, tc.CRConfigValidation{}, err
+	}
+	dsTypes, err := getDSTypes(cdn, db)
+	if err != nil {
+		return nil, tc.CRConfigValidation{}, errors.New("Error getting Delivery Service types: " + err.Error())
+	}
+	return crc, Validate(crc, dsTypes), nil
+}
@@ -0,0 +1,112 @@
+package crconfig
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
+)
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func validCRConfig() *tc.CRConfig {
+	edgeType := EdgeTypePrefix + "_LOC"
+	online := tc.CRConfigServerStatus(tc.CacheStatusOnline)
+	offline := tc.CRConfigServerStatus(tc.CacheStatusOffline)
+	return &tc.CRConfig{
+		ContentServers: map[string]tc.CRConfigTrafficOpsServer{
+			"edge0": tc.CRConfigTrafficOpsServer{CacheGroup: strPtr("cg0"), ServerStatus: &online, ServerType: &edgeType, DeliveryServices: map[string][]string{"ds0": nil}},
+			"edge1": tc.CRConfigTrafficOpsServer{CacheGroup: strPtr("cg0"), ServerStatus: &offline, ServerType: &edgeType, DeliveryServices: map[string][]string{"ds1": nil}},
+		},
+		ContentRouters: map[string]tc.CRConfigRouter{
+			"router0": tc.CRConfigRouter{Location: strPtr("rcg0")},
+		},
+		Monitors: map[string]tc.CRConfigMonitor{
+			"monitor0": tc.CRConfigMonitor{},
+		},
+		DeliveryServices: map[string]tc.CRConfigDeliveryService{
+			"ds0":       tc.CRConfigDeliveryService{MatchSets: []*tc.MatchSet{{MatchList: []tc.MatchList{{Regex: `.*\.ds0\..*`}}}}, MissLocation: &tc.CRConfigLatitudeLongitudeShort{Lat: 1, Lon: 2}},
+			"ds1":       tc.CRConfigDeliveryService{MatchSets: []*tc.MatchSet{{MatchList: []tc.MatchList{{Regex: `.*\.ds1\..*`}}}}},
+			"steering0": tc.CRConfigDeliveryService{MatchSets: []*tc.MatchSet{{MatchList: []tc.MatchList{{Regex: `.*\.steering0\..*`}}}}},
+			"anymap0":   tc.CRConfigDeliveryService{},
+		},
+		EdgeLocations:   map[string]tc.CRConfigLatitudeLongitude{"cg0": tc.CRConfigLatitudeLongitude{Lat: 1, Lon: 2}},
+		RouterLocations: map[string]tc.CRConfigLatitudeLongitude{"rcg0": tc.CRConfigLatitudeLongitude{Lat: 3, Lon: 4}},
+	}
+}
+
+func validDSTypes() map[string]string {
+	return map[string]string{"ds0": "HTTP", "ds1": "DNS", "steering0": SteeringTypeName, "anymap0": AnyMapTypeName}
+}
+
+func TestValidate(t *testing.T) {
+	crc := validCRConfig()
+
+	// ds1 is only on an offline edge, but steering and ANY_MAP delivery services need no edges, and ANY_MAP needs no regexes
+	expected := tc.CRConfigValidation{
+		Errors:   []string{"delivery service 'ds1' has no available edge caches"},
+		Warnings: []string{},
+	}
+	actual := Validate(crc, validDSTypes())
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Validate expected: %+v, actual: %+v", expected, actual)
+	}
+
+	// an edge reporting ds1 makes the CRConfig valid
+	edgeType := EdgeTypePrefix
+	reported := tc.CRConfigServerStatus(tc.CacheStatusReported)
+	crc.ContentServers["edge2"] = tc.CRConfigTrafficOpsServer{CacheGroup: strPtr("cg0"), ServerStatus: &reported, ServerType: &edgeType, DeliveryServices: map[string][]string{"ds1": nil}}
+	expected = tc.CRConfigValidation{Errors: []string{}, Warnings: []string{}}
+	actual = Validate(crc, validDSTypes())
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Validate expected: %+v, actual: %+v", expected, actual)
+	}
+}
+
+func TestValidateErrors(t *testing.T) {
+	crc := validCRConfig()
+	crc.ContentServers["edge2"] = tc.CRConfigTrafficOpsServer{CacheGroup: strPtr("cg1"), ServerType: crc.ContentServers["edge0"].ServerType}
+	crc.ContentRouters["router1"] = tc.CRConfigRouter{Location: strPtr("rcg1")}
+	crc.DeliveryServices["ds0"] = tc.CRConfigDeliveryService{MissLocation: &tc.CRConfigLatitudeLongitudeShort{Lat: 0, Lon: 0}}
+	crc.EdgeLocations["cg0"] = tc.CRConfigLatitudeLongitude{}
+	crc.Monitors = nil
+
+	expected := tc.CRConfigValidation{
+		Errors: []string{
+			"delivery service 'ds0' has no regexes",
+			"delivery service 'ds1' has no available edge caches",
+			"edge cache 'edge2' cachegroup 'cg1' has no coordinates",
+			"router 'router1' cachegroup 'rcg1' has no coordinates",
+		},
+		Warnings: []string{
+			"CDN has no monitors",
+			"delivery service 'ds0' miss location is 0,0",
+			"edge cachegroup 'cg0' coordinates are 0,0",
+		},
+	}
+	actual := Validate(crc, validDSTypes())
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Validate expected: %+v, actual: %+v", expected, actual)
+	}
+}
@@ -21,7 +21,10 @@ package dbhelpers
 
 import (
 	"errors"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/apache/incubator-trafficcontrol/lib/go-log"
 	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
@@ -37,6 +40,28 @@ type WhereColumnInfo struct {
 const baseWhere = "\nWHERE"
 const baseOrderBy = "\nORDER BY"
 
+// FilterSeparator separates a query parameter from a filter operator, e.g. "cachegroup.in=1,2,3". A query parameter without an operator is an equality filter.
+const FilterSeparator = "."
+
+const (
+	FilterIn       = "in"       // comma-separated list of values
+	FilterNot      = "not"      // not equal, including null
+	FilterPrefix   = "prefix"   // starts with the value
+	FilterContains = "contains" // contains the value
+	FilterGT       = "gt"       // greater than
+	FilterLT       = "lt"       // less than
+	FilterIsNull   = "isnull"   // true for null, false for not null
+)
+
+// LastUpdatedQueryParam is the query parameter of last updated times. Its values are RFC3339 times, or durations before now, so "lastUpdated.gt=1h" is anything updated in the last hour.
+const LastUpdatedQueryParam = "lastUpdated"
+
+// LimitParam and OffsetParam are the parameters of the page of rows to query. Queries with a limit are ordered by id after any orderby columns, so pages are stable.
+const (
+	LimitParam  = "limit"
+	OffsetParam = "offset"
+)
+
 func BuildWhereAndOrderBy(parameters map[string]string, queryParamsToSQLCols map[string]WhereColumnInfo) (string, string, map[string]interface{}, []error) {
 	whereClause := baseWhere
 	orderBy := baseOrderBy
@@ -45,21 +70,31 @@ func BuildWhereAndOrderBy(parameters map[string]string, queryParamsToSQLCols map
 	var errs []error
 	criteria, queryValues, errs = parseCriteriaAndQueryValues(queryParamsToSQLCols, parameters)
 
-	if len(queryValues) > 0 {
+	if criteria != "" {
 		whereClause += " " + criteria
 	}
 	if len(errs) > 0 {
 		return "", "", queryValues, errs
 	}
 
+	orderByCols := []string{}
 	if orderby, ok := parameters["orderby"]; ok {
 		log.Debugln("orderby: ", orderby)
-		if colInfo, ok := queryParamsToSQLCols[orderby]; ok {
-			log.Debugln("orderby column ", colInfo)
-			orderBy += " " + colInfo.Column
-		} else {
-			log.Debugln("Incorrect name for orderby: ", orderby)
+		cols, orderErrs := buildOrderByColumns(orderby, parameters["sortOrder"], queryParamsToSQLCols)
+		if len(orderErrs) > 0 {
+			return "", "", queryValues, orderErrs
 		}
+		orderByCols = cols
+	}
+	limit, offset, paginated, pageErrs := parsePage(parameters)
+	if len(pageErrs) > 0 {
+		return "", "", queryValues, pageErrs
+	}
+	if paginated {
+		orderByCols = addDefaultOrder(orderByCols, queryParamsToSQLCols)
+	}
+	if len(orderByCols) > 0 {
+		orderBy += " " + strings.Join(orderByCols, ", ")
 	}
 	if whereClause == baseWhere {
 		whereClause = ""
@@ -67,38 +102,215 @@ func BuildWhereAndOrderBy(parameters map[string]string, queryParamsToSQLCols map
 	if orderBy == baseOrderBy {
 		orderBy = ""
 	}
+	if paginated {
+		orderBy += "\nLIMIT :" + LimitParam + " OFFSET :" + OffsetParam
+		queryValues[LimitParam] = limit
+		queryValues[OffsetParam] = offset
+	}
 	log.Debugf("\n--\n Where: %s \n Order By: %s", whereClause, orderBy)
 	return whereClause, orderBy, queryValues, errs
 }
 
-func parseCriteriaAndQueryValues(queryParamsToSQLCols map[string]WhereColumnInfo, parameters map[string]string) (string, map[string]interface{}, []error) {
-	m := make(map[string]interface{})
-	var criteria string
+// parsePage returns the limit and offset parameters, and whether the query is paginated. The API read handler sets them from the limit, and offset or cursor, parameters of the request.
+func parsePage(parameters map[string]string) (int, int, bool, []error) {
+	limitStr, ok := parameters[LimitParam]
+	if !ok {
+		return 0, 0, false, nil
+	}
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 {
+		return 0, 0, false, []error{errors.New(LimitParam + " must be a positive integer")}
+	}
+	offset := 0
+	if offsetStr, ok := parameters[OffsetParam]; ok {
+		if offset, err = strconv.Atoi(offsetStr); err != nil || offset < 0 {
+			return 0, 0, false, []error{errors.New(OffsetParam + " must be a non-negative integer")}
+		}
+	}
+	return limit, offset, true, nil
+}
+
+// addDefaultOrder appends the id column to the ORDER BY columns, so pages are stable even when the requested columns have duplicate values.
+// Objects without an id are ordered by all their columns.
+func addDefaultOrder(orderByCols []string, queryParamsToSQLCols map[string]WhereColumnInfo) []string {
+	ordered := map[string]struct{}{}
+	for _, col := range orderByCols {
+		ordered[strings.TrimSuffix(strings.TrimSuffix(col, " ASC"), " DESC")] = struct{}{}
+	}
+	names := []string{"id"}
+	if _, ok := queryParamsToSQLCols["id"]; !ok {
+		names = make([]string, 0, len(queryParamsToSQLCols))
+		for name := range queryParamsToSQLCols {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+	for _, name := range names {
+		col := queryParamsToSQLCols[name].Column
+		if _, ok := ordered[col]; ok {
+			continue
+		}
+		ordered[col] = struct{}{}
+		orderByCols = append(orderByCols, col)
+	}
+	return orderByCols
+}
+
+// buildOrderByColumns returns the ORDER BY columns of the comma-separated orderby parameter, each with its direction.
+// The sortOrder parameter is either a single "asc" or "desc" applied to all columns, or a comma-separated direction for each column.
+// Unknown orderby names are an error.
+func buildOrderByColumns(orderby string, sortOrder string, queryParamsToSQLCols map[string]WhereColumnInfo) ([]string, []error) {
+	names := strings.Split(orderby, ",")
+	directions := []string{}
+	if sortOrder != "" {
+		directions = strings.Split(sortOrder, ",")
+		if len(directions) != 1 && len(directions) != len(names) {
+			return nil, []error{errors.New("sortOrder must have a single direction, or one for each orderby column")}
+		}
+	}
+
+	cols := []string{}
+	for i, name := range names {
+		name = strings.TrimSpace(name)
+		colInfo, ok := queryParamsToSQLCols[name]
+		if !ok {
+			return nil, []error{errors.New("orderby column '" + name + "' is not a valid column")}
+		}
+		log.Debugln("orderby column ", colInfo)
+		col := colInfo.Column
+		if len(directions) > 0 {
+			direction := directions[0]
+			if len(directions) > 1 {
+				direction = directions[i]
+			}
+			switch strings.ToLower(strings.TrimSpace(direction)) {
+			case "asc":
+				col += " ASC"
+			case "desc":
+				col += " DESC"
+			default:
+				return nil, []error{errors.New("sortOrder '" + direction + "' must be one of 'asc' or 'desc'")}
+			}
+		}
+		cols = append(cols, col)
+	}
+	return cols, nil
+}
 
+func parseCriteriaAndQueryValues(queryParamsToSQLCols map[string]WhereColumnInfo, parameters map[string]string) (string, map[string]interface{}, []error) {
 	var criteriaArgs []string
 	errs := []error{}
 	queryValues := make(map[string]interface{})
-	for key, colInfo := range queryParamsToSQLCols {
-		if urlValue, ok := parameters[key]; ok {
-			var err error
-			if colInfo.Checker != nil {
-				err = colInfo.Checker(urlValue)
+
+	// sort the keys, so the query is the same for the same parameters
+	keys := make([]string, 0, len(parameters))
+	for key := range parameters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		urlValue := parameters[key]
+		param, op := key, ""
+		colInfo, ok := queryParamsToSQLCols[key]
+		if !ok {
+			sep := strings.LastIndex(key, FilterSeparator)
+			if sep < 0 {
+				continue
 			}
-			if err != nil {
-				errs = append(errs, errors.New(key+" "+err.Error()))
-			} else {
-				m[key] = urlValue
-				criteria = colInfo.Column + "=:" + key
-				criteriaArgs = append(criteriaArgs, criteria)
-				queryValues[key] = urlValue
+			param, op = key[:sep], key[sep+1:]
+			if colInfo, ok = queryParamsToSQLCols[param]; !ok {
+				continue
 			}
 		}
+		criteria, values, err := buildFilter(param, op, colInfo, urlValue)
+		if err != nil {
+			errs = append(errs, errors.New(key+" "+err.Error()))
+			continue
+		}
+		criteriaArgs = append(criteriaArgs, criteria)
+		for name, value := range values {
+			queryValues[name] = value
+		}
 	}
-	criteria = strings.Join(criteriaArgs, " AND ")
+	criteria := strings.Join(criteriaArgs, " AND ")
 
 	return criteria, queryValues, errs
 }
 
+// buildFilter returns the criteria of the given query parameter and filter operator, and its named query values. The empty operator is equality.
+func buildFilter(param string, op string, colInfo WhereColumnInfo, urlValue string) (string, map[string]interface{}, error) {
+	name := param
+	if op != "" {
+		name = param + "_" + op
+	}
+	switch op {
+	case "", FilterNot, FilterGT, FilterLT:
+		value, err := filterValue(param, colInfo, urlValue)
+		if err != nil {
+			return "", nil, err
+		}
+		sqlOp := map[string]string{"": "=", FilterNot: "IS DISTINCT FROM", FilterGT: ">", FilterLT: "<"}[op]
+		return colInfo.Column + " " + sqlOp + " :" + name, map[string]interface{}{name: value}, nil
+	case FilterIn:
+		values := map[string]interface{}{}
+		names := []string{}
+		for i, urlVal := range strings.Split(urlValue, ",") {
+			value, err := filterValue(param, colInfo, urlVal)
+			if err != nil {
+				return "", nil, err
+			}
+			inName := name + "_" + strconv.Itoa(i)
+			values[inName] = value
+			names = append(names, ":"+inName)
+		}
+		return colInfo.Column + " IN (" + strings.Join(names, ", ") + ")", values, nil
+	case FilterPrefix, FilterContains:
+		pattern := likeEscaper.Replace(urlValue) + "%"
+		if op == FilterContains {
+			pattern = "%" + pattern
+		}
+		return colInfo.Column + "::text LIKE :" + name, map[string]interface{}{name: pattern}, nil
+	case FilterIsNull:
+		isNull, err := strconv.ParseBool(urlValue)
+		if err != nil {
+			return "", nil, errors.New("must be true or false")
+		}
+		if isNull {
+			return colInfo.Column + " IS NULL", nil, nil
+		}
+		return colInfo.Column + " IS NOT NULL", nil, nil
+	default:
+		return "", nil, errors.New("unknown filter '" + op + "', must be one of " + strings.Join([]string{FilterIn, FilterNot, FilterPrefix, FilterContains, FilterGT, FilterLT, FilterIsNull}, ", "))
+	}
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// filterValue returns the query value of a single url value, after checking it. Last updated times are parsed, so they can be compared.
+func filterValue(param string, colInfo WhereColumnInfo, urlValue string) (interface{}, error) {
+	if param == LastUpdatedQueryParam {
+		return parseTimeFilter(urlValue)
+	}
+	if colInfo.Checker != nil {
+		if err := colInfo.Checker(urlValue); err != nil {
+			return nil, err
+		}
+	}
+	return urlValue, nil
+}
+
+// parseTimeFilter parses an RFC3339 time, or a duration before now.
+func parseTimeFilter(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	return time.Time{}, errors.New("must be an RFC3339 time or a duration")
+}
+
 //parses pq errors for uniqueness constraint violations
 func ParsePQUniqueConstraintError(err *pq.Error) (error, tc.ApiErrorType) {
 	if len(err.Constraint) > 0 && len(err.Detail) > 0 { //we only want to continue parsing if it is a constraint error with details
@@ -20,8 +20,11 @@ package dbhelpers
  */
 
 import (
+	"errors"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 	"unicode"
 )
 
@@ -71,3 +74,153 @@ FROM table t
 	}
 
 }
+
+func TestBuildOrderBy(t *testing.T) {
+	queryParamsToSQLCols := map[string]WhereColumnInfo{
+		"param1": WhereColumnInfo{"t.col1", nil},
+		"param2": WhereColumnInfo{"t.col2", nil},
+	}
+
+	_, orderBy, _, errs := BuildWhereAndOrderBy(map[string]string{"orderby": "param2,param1"}, queryParamsToSQLCols)
+	if len(errs) > 0 {
+		t.Fatalf("expected: nil errors, actual: %v", errs)
+	}
+	if expected := "ORDERBYt.col2,t.col1"; stripAllWhitespace(orderBy) != expected {
+		t.Errorf("expected: %v, actual: %v", expected, stripAllWhitespace(orderBy))
+	}
+
+	if _, _, _, errs = BuildWhereAndOrderBy(map[string]string{"orderby": "param2,unknown"}, queryParamsToSQLCols); len(errs) == 0 {
+		t.Errorf("expected: error for unknown orderby column, actual: nil")
+	}
+
+	_, orderBy, _, errs = BuildWhereAndOrderBy(map[string]string{"orderby": "param1,param2", "sortOrder": "desc"}, queryParamsToSQLCols)
+	if len(errs) > 0 {
+		t.Fatalf("expected: nil errors, actual: %v", errs)
+	}
+	if expected := "ORDERBYt.col1DESC,t.col2DESC"; stripAllWhitespace(orderBy) != expected {
+		t.Errorf("expected: %v, actual: %v", expected, stripAllWhitespace(orderBy))
+	}
+
+	_, orderBy, _, errs = BuildWhereAndOrderBy(map[string]string{"orderby": "param1,param2", "sortOrder": "DESC,asc"}, queryParamsToSQLCols)
+	if len(errs) > 0 {
+		t.Fatalf("expected: nil errors, actual: %v", errs)
+	}
+	if expected := "ORDERBYt.col1DESC,t.col2ASC"; stripAllWhitespace(orderBy) != expected {
+		t.Errorf("expected: %v, actual: %v", expected, stripAllWhitespace(orderBy))
+	}
+
+	if _, _, _, errs = BuildWhereAndOrderBy(map[string]string{"orderby": "param1", "sortOrder": "sideways"}, queryParamsToSQLCols); len(errs) == 0 {
+		t.Errorf("expected: error for invalid sortOrder, actual: nil")
+	}
+	if _, _, _, errs = BuildWhereAndOrderBy(map[string]string{"orderby": "param1,param2,param1", "sortOrder": "asc,desc"}, queryParamsToSQLCols); len(errs) == 0 {
+		t.Errorf("expected: error for mismatched sortOrder count, actual: nil")
+	}
+}
+
+func TestBuildPage(t *testing.T) {
+	queryParamsToSQLCols := map[string]WhereColumnInfo{
+		"id":     WhereColumnInfo{"t.id", nil},
+		"param1": WhereColumnInfo{"t.col1", nil},
+	}
+
+	_, orderBy, queryValues, errs := BuildWhereAndOrderBy(map[string]string{"limit": "3", "offset": "4", "orderby": "param1"}, queryParamsToSQLCols)
+	if len(errs) > 0 {
+		t.Fatalf("expected: nil errors, actual: %v", errs)
+	}
+	if expected := "ORDERBYt.col1,t.idLIMIT:limitOFFSET:offset"; stripAllWhitespace(orderBy) != expected {
+		t.Errorf("expected: %v, actual: %v", expected, stripAllWhitespace(orderBy))
+	}
+	if queryValues["limit"] != 3 || queryValues["offset"] != 4 {
+		t.Errorf("expected: limit 3 offset 4, actual: %v", queryValues)
+	}
+
+	_, orderBy, _, errs = BuildWhereAndOrderBy(map[string]string{"limit": "3", "orderby": "id", "sortOrder": "desc"}, queryParamsToSQLCols)
+	if len(errs) > 0 {
+		t.Fatalf("expected: nil errors, actual: %v", errs)
+	}
+	if expected := "ORDERBYt.idDESCLIMIT:limitOFFSET:offset"; stripAllWhitespace(orderBy) != expected {
+		t.Errorf("expected: %v, actual: %v", expected, stripAllWhitespace(orderBy))
+	}
+
+	delete(queryParamsToSQLCols, "id")
+	queryParamsToSQLCols["param0"] = WhereColumnInfo{"t.col0", nil}
+	_, orderBy, _, errs = BuildWhereAndOrderBy(map[string]string{"limit": "3"}, queryParamsToSQLCols)
+	if len(errs) > 0 {
+		t.Fatalf("expected: nil errors, actual: %v", errs)
+	}
+	if expected := "ORDERBYt.col0,t.col1LIMIT:limitOFFSET:offset"; stripAllWhitespace(orderBy) != expected {
+		t.Errorf("expected: %v, actual: %v", expected, stripAllWhitespace(orderBy))
+	}
+
+	if _, _, _, errs = BuildWhereAndOrderBy(map[string]string{"limit": "0"}, queryParamsToSQLCols); len(errs) == 0 {
+		t.Errorf("expected: error for invalid limit, actual: nil")
+	}
+}
+
+func TestBuildWhereFilters(t *testing.T) {
+	isInt := func(s string) error {
+		if _, err := strconv.Atoi(s); err != nil {
+			return errors.New("cannot parse to integer")
+		}
+		return nil
+	}
+	queryParamsToSQLCols := map[string]WhereColumnInfo{
+		"cachegroup":          WhereColumnInfo{"s.cachegroup", isInt},
+		"hostName":            WhereColumnInfo{"s.host_name", nil},
+		"profileId":           WhereColumnInfo{"s.profile", isInt},
+		"status":              WhereColumnInfo{"st.name", nil},
+		LastUpdatedQueryParam: WhereColumnInfo{"s.last_updated", nil},
+	}
+	params := map[string]string{
+		"cachegroup.in":    "1,2,3",
+		"hostName.prefix":  "edge_%",
+		"profileId.isnull": "false",
+		"status":           "REPORTED",
+		"lastUpdated.gt":   "1h",
+		"lastUpdated.lt":   "2018-01-19T19:01:21Z",
+		"unknown.in":       "1,2",
+	}
+
+	where, _, queryValues, errs := BuildWhereAndOrderBy(params, queryParamsToSQLCols)
+	if len(errs) > 0 {
+		t.Fatalf("expected: nil errors, actual: %v", errs)
+	}
+	expected := "WHEREs.cachegroupIN(:cachegroup_in_0,:cachegroup_in_1,:cachegroup_in_2)ANDs.host_name::textLIKE:hostName_prefixANDs.last_updated>:lastUpdated_gtANDs.last_updated<:lastUpdated_ltANDs.profileISNOTNULLANDst.name=:status"
+	if actual := stripAllWhitespace(where); actual != expected {
+		t.Errorf("expected: %v, actual: %v", expected, actual)
+	}
+	if queryValues["cachegroup_in_1"] != "2" || queryValues["status"] != "REPORTED" {
+		t.Errorf("expected: in and equality values, actual: %v", queryValues)
+	}
+	if queryValues["hostName_prefix"] != `edge\_\%%` {
+		t.Errorf("expected: escaped prefix pattern, actual: %v", queryValues["hostName_prefix"])
+	}
+	if gt, ok := queryValues["lastUpdated_gt"].(time.Time); !ok || time.Since(gt) < 59*time.Minute || time.Since(gt) > 61*time.Minute {
+		t.Errorf("expected: lastUpdated.gt an hour ago, actual: %v", queryValues["lastUpdated_gt"])
+	}
+	if lt, ok := queryValues["lastUpdated_lt"].(time.Time); !ok || !lt.Equal(time.Date(2018, 1, 19, 19, 1, 21, 0, time.UTC)) {
+		t.Errorf("expected: lastUpdated.lt parsed time, actual: %v", queryValues["lastUpdated_lt"])
+	}
+
+	invalid := []map[string]string{
+		{"cachegroup.in": "1,two"},
+		{"cachegroup.gt": "two"},
+		{"profileId.isnull": "maybe"},
+		{"status.between": "a,b"},
+		{"lastUpdated.gt": "yesterday"},
+	}
+	for _, params := range invalid {
+		if _, _, _, errs := BuildWhereAndOrderBy(params, queryParamsToSQLCols); len(errs) == 0 {
+			t.Errorf("expected: error for %v, actual: nil", params)
+		}
+	}
+
+	where, _, _, errs = BuildWhereAndOrderBy(map[string]string{"status.not": "OFFLINE", "hostName.contains": "mid"}, queryParamsToSQLCols)
+	if len(errs) > 0 {
+		t.Fatalf("expected: nil errors, actual: %v", errs)
+	}
+	expected = "WHEREs.host_name::textLIKE:hostName_containsANDst.nameISDISTINCTFROM:status_not"
+	if actual := stripAllWhitespace(where); actual != expected {
+		t.Errorf("expected: %v, actual: %v", expected, actual)
+	}
+}
@@ -20,9 +20,11 @@ package deliveryservice
  */
 
 import (
+	"database/sql"
 	"errors"
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/apache/incubator-trafficcontrol/lib/go-log"
@@ -40,6 +42,9 @@ import (
 	"github.com/lib/pq"
 )
 
+// ReadUnassignedCapability is the capability required to see delivery services which aren't assigned to the user, when tenancy is disabled.
+const ReadUnassignedCapability = "ds-unassigned-read"
+
 //we need a type alias to define functions on
 type TODeliveryService tc.DeliveryServiceNullable
 
@@ -75,7 +80,15 @@ func (ds *TODeliveryService) GetAuditName() string {
 }
 
 func (ds *TODeliveryService) GetType() string {
-	return "ds"
+	return "deliveryservice"
+}
+
+// ChangeLogMessage implements the api.ChangeLogger interface, so the change log matches the messages written by the Perl Traffic Ops
+func (ds *TODeliveryService) ChangeLogMessage(action string) (string, error) {
+	if ds.ID == nil {
+		return "", errors.New("delivery service id is nil")
+	}
+	return action + " delivery service [ '" + ds.GetAuditName() + "' ] with id: " + strconv.Itoa(*ds.ID), nil
 }
 
 func Validate(db *sqlx.DB, ds *tc.DeliveryServiceNullable) []error {
@@ -114,15 +127,16 @@ func (ds *TODeliveryService) Validate(db *sqlx.DB) []error {
 		"regionalGeoBlocking": validation.Validate(ds.RegionalGeoBlocking, validation.NotNil),
 		"routingName":         validation.Validate(ds.RoutingName, isHost, noPeriods, validation.Length(1, 48)),
 		"typeId":              validation.Validate(ds.TypeID, validation.Required, validation.Min(1)),
-		"xmlId":               validation.Validate(ds.XMLID, noSpaces, noPeriods, validation.Length(1, 48)),
+		"xmlId":               validation.Validate(ds.XMLID, validation.Required, noSpaces, noPeriods, validation.Length(1, 48)),
 	}
 
-	if errs != nil {
-		return tovalidate.ToErrors(errs)
+	// validation.Errors is a map, and is never nil, so check the converted errors for emptiness
+	if errsResponse := tovalidate.ToErrors(errs); len(errsResponse) > 0 {
+		return errsResponse
 	}
 
 	errsResponse := ds.validateTypeFields(db)
-	if errsResponse != nil {
+	if len(errsResponse) > 0 {
 		return errsResponse
 	}
 
@@ -130,7 +144,6 @@ func (ds *TODeliveryService) Validate(db *sqlx.DB) []error {
 }
 
 func (ds *TODeliveryService) validateTypeFields(db *sqlx.DB) []error {
-	fmt.Printf("validateTypeFields\n")
 	// Validate the TypeName related fields below
 	var typeName string
 	var err error
@@ -172,6 +185,7 @@ func (ds *TODeliveryService) validateTypeFields(db *sqlx.DB) []error {
 	return nil
 }
 
+// requiredIfMatchesTypeName returns a validation func which returns an error if the value is nil and the typeName matches any of the given patterns.
 func requiredIfMatchesTypeName(patterns []string, typeName string) func(interface{}) error {
 	return func(value interface{}) error {
 
@@ -180,7 +194,7 @@ func requiredIfMatchesTypeName(patterns []string, typeName string) func(interfac
 		var match bool
 		if typeName != "" {
 			match, err = regexp.MatchString(pattern, typeName)
-			if match {
+			if match && validation.NotNil.Validate(value) != nil {
 				return fmt.Errorf("is required if type is '%s'", typeName)
 			}
 		}
@@ -211,37 +225,374 @@ func getTypeName(db *sqlx.DB, typeID int) (string, error) {
 		typeResults = append(typeResults, s)
 	}
 
+	if len(typeResults) == 0 {
+		return "", fmt.Errorf("type %d not found", typeID)
+	}
 	typeName := typeResults[0].Name
 	return typeName, err
 }
 
+// IsSteeringType returns whether the given delivery service type name is a steering type, which has steering targets rather than origins.
+func IsSteeringType(typeName string) bool {
+	return strings.HasSuffix(typeName, "STEERING")
+}
+
+// SanitizeGeoLimitCountries removes all whitespace from the given comma-separated country code list and upper-cases it, as the Perl Traffic Ops did.
+func SanitizeGeoLimitCountries(countries *string) *string {
+	if countries == nil {
+		return nil
+	}
+	s := strings.ToUpper(strings.Join(strings.Fields(*countries), ""))
+	return &s
+}
+
+// deleteSteeringTargets deletes the steering targets of the given delivery service, which is no longer a steering type, and writes a change log entry if any existed.
+func deleteSteeringTargets(tx *sqlx.Tx, dsID int, xmlID string, newTypeName string, user auth.CurrentUser) error {
+	result, err := tx.Exec(`DELETE FROM steering_target WHERE deliveryservice = $1`, dsID)
+	if err != nil {
+		return errors.New("deleting steering targets: " + err.Error())
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.New("getting deleted steering targets: " + err.Error())
+	}
+	if rowsAffected == 0 {
+		return nil
+	}
+	msg := "Deleted " + strconv.FormatInt(rowsAffected, 10) + " steering targets of delivery service [ '" + xmlID + "' ] with id: " + strconv.Itoa(dsID) + " on type change to " + newTypeName
+	if err := api.CreateChangeLogRawTx(api.ApiChange, msg, user, tx); err != nil {
+		return errors.New("creating steering target change log: " + err.Error())
+	}
+	return nil
+}
+
+// Read implements the api.Reader interface. Only delivery services on tenants the user has access to are returned. Like the Perl Traffic Ops, if tenancy is disabled, users below the operations privilege level only see the delivery services assigned to them.
+func (ds *TODeliveryService) Read(db *sqlx.DB, parameters map[string]string, user auth.CurrentUser) ([]interface{}, []error, tc.ApiErrorType) {
+	queryParamsToQueryCols := map[string]dbhelpers.WhereColumnInfo{
+		"id":               dbhelpers.WhereColumnInfo{Column: "ds.id", Checker: api.IsInt},
+		"xmlId":            dbhelpers.WhereColumnInfo{Column: "ds.xml_id"},
+		"cdn":              dbhelpers.WhereColumnInfo{Column: "ds.cdn_id", Checker: api.IsInt},
+		"profile":          dbhelpers.WhereColumnInfo{Column: "ds.profile", Checker: api.IsInt},
+		"type":             dbhelpers.WhereColumnInfo{Column: "ds.type", Checker: api.IsInt},
+		"tenant":           dbhelpers.WhereColumnInfo{Column: "ds.tenant_id", Checker: api.IsInt},
+		"logsEnabled":      dbhelpers.WhereColumnInfo{Column: "ds.logs_enabled", Checker: api.IsBool},
+		"signingAlgorithm": dbhelpers.WhereColumnInfo{Column: "ds.signing_algorithm"},
+		"lastUpdated":      dbhelpers.WhereColumnInfo{Column: "ds.last_updated"},
+	}
+
+	p := parameters
+	if _, ok := parameters["orderby"]; !ok {
+		// if orderby not provided, default to orderby xmlId.  Making a copy of parameters to not modify input arg
+		p = make(map[string]string, len(parameters))
+		for k, v := range parameters {
+			p[k] = v
+		}
+		p["orderby"] = "xmlId"
+	}
+
+	where, orderBy, queryValues, errs := dbhelpers.BuildWhereAndOrderBy(p, queryParamsToQueryCols)
+	if len(errs) > 0 {
+		return nil, errs, tc.DataConflictError
+	}
+
+	if !user.HasCapability(ReadUnassignedCapability) {
+		useTenancy, err := tenant.IsTenancyEnabled(db)
+		if err != nil {
+			log.Errorln("checking tenancy: " + err.Error())
+			return nil, []error{tc.DBError}, tc.SystemError
+		}
+		if !useTenancy {
+			if where == "" {
+				where = "\nWHERE "
+			} else {
+				where += " AND "
+			}
+			where += "ds.id IN (SELECT deliveryservice FROM deliveryservice_tmuser WHERE tm_user_id = :current_user_id)"
+			queryValues["current_user_id"] = user.ID
+		}
+	}
+
+	where = tenant.AddTenancyCheck(where, queryValues, "ds.tenant_id", user)
+
+	query := selectQuery() + where + orderBy
+	log.Debugln("Query is ", query)
+
+	rows, err := db.NamedQuery(query, queryValues)
+	if err != nil {
+		log.Errorln("querying delivery services: " + err.Error())
+		return nil, []error{tc.DBError}, tc.SystemError
+	}
+	defer rows.Close()
+
+	dses := []TODeliveryService{}
+	for rows.Next() {
+		s, err := scanDS(rows)
+		if err != nil {
+			log.Errorln("scanning delivery services: " + err.Error())
+			return nil, []error{tc.DBError}, tc.SystemError
+		}
+
+		dses = append(dses, s)
+	}
+
+	ids := make([]int, 0, len(dses))
+	for _, s := range dses {
+		ids = append(ids, *s.ID)
+	}
+	matchLists, err := getMatchLists(db.DB, ids)
+	if err != nil {
+		log.Errorln("getting delivery service match lists: " + err.Error())
+		return nil, []error{tc.DBError}, tc.SystemError
+	}
+
+	iDSes := make([]interface{}, 0, len(dses))
+	for _, s := range dses {
+		matchList := matchLists[*s.ID]
+		s.MatchList = &matchList
+		iDSes = append(iDSes, s)
+	}
+	return iDSes, []error{}, tc.NoError
+}
+
+// rowScanner is the Scan func common to sql.Row, sql.Rows, and sqlx.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanDS scans a row of the selectQuery. The deep caching type is converted from its database enum value.
+func scanDS(row rowScanner) (TODeliveryService, error) {
+	s := TODeliveryService{}
+	deepCachingType := sql.NullString{}
+	lastUpdated := tc.TimeNoMod{}
+	if err := row.Scan(
+		&s.Active,
+		&s.CacheURL,
+		&s.CCRDNSTTL,
+		&s.CDNID,
+		&s.CDNName,
+		&s.CheckPath,
+		&deepCachingType,
+		&s.DisplayName,
+		&s.DNSBypassCNAME,
+		&s.DNSBypassIP,
+		&s.DNSBypassIP6,
+		&s.DNSBypassTTL,
+		&s.DSCP,
+		&s.EdgeHeaderRewrite,
+		&s.FQPacingRate,
+		&s.GeoLimit,
+		&s.GeoLimitCountries,
+		&s.GeoLimitRedirectURL,
+		&s.GeoProvider,
+		&s.GlobalMaxMBPS,
+		&s.GlobalMaxTPS,
+		&s.HTTPBypassFQDN,
+		&s.ID,
+		&s.InfoURL,
+		&s.InitialDispersion,
+		&s.IPV6RoutingEnabled,
+		&lastUpdated,
+		&s.LogsEnabled,
+		&s.LongDesc,
+		&s.LongDesc1,
+		&s.LongDesc2,
+		&s.MaxDNSAnswers,
+		&s.MidHeaderRewrite,
+		&s.MissLat,
+		&s.MissLong,
+		&s.MultiSiteOrigin,
+		&s.MultiSiteOriginAlgorithm,
+		&s.OrgServerFQDN,
+		&s.OriginShield,
+		&s.ProfileID,
+		&s.ProfileName,
+		&s.ProfileDesc,
+		&s.Protocol,
+		&s.QStringIgnore,
+		&s.RangeRequestHandling,
+		&s.RegexRemap,
+		&s.RegionalGeoBlocking,
+		&s.RemapText,
+		&s.RoutingName,
+		&s.SigningAlgorithm,
+		&s.SSLKeyVersion,
+		&s.TenantID,
+		&s.Tenant,
+		&s.TRRequestHeaders,
+		&s.TRResponseHeaders,
+		&s.TypeName,
+		&s.TypeID,
+		&s.XMLID,
+	); err != nil {
+		return TODeliveryService{}, err
+	}
+	if deepCachingType.Valid {
+		t := tc.DeepCachingTypeFromString(deepCachingType.String)
+		s.DeepCachingType = &t
+	}
+	s.LastUpdated = &lastUpdated
+	return s, nil
+}
+
+// getDSByID returns the delivery service with the given ID, and whether it existed. This is used to return the complete delivery service, including joined names, after a create or update.
+func getDSByID(tx *sqlx.Tx, id int) (TODeliveryService, bool, error) {
+	ds, err := scanDS(tx.QueryRow(selectQuery()+"\nWHERE ds.id = $1", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return TODeliveryService{}, false, nil
+		}
+		return TODeliveryService{}, false, errors.New("querying delivery service: " + err.Error())
+	}
+	matchLists, err := getMatchLists(tx, []int{id})
+	if err != nil {
+		return TODeliveryService{}, false, errors.New("getting delivery service match list: " + err.Error())
+	}
+	matchList := matchLists[id]
+	ds.MatchList = &matchList
+	return ds, true, nil
+}
+
+// getCurrentDSInfo returns the xml_id, tenant, and type name of the existing delivery service with the given ID, and whether it existed.
+func getCurrentDSInfo(tx *sqlx.Tx, id int) (string, *int, string, bool, error) {
+	xmlID := ""
+	tenantID := (*int)(nil)
+	typeName := ""
+	if err := tx.QueryRow(`SELECT ds.xml_id, ds.tenant_id, t.name FROM deliveryservice as ds JOIN type as t ON ds.type = t.id WHERE ds.id = $1`, id).Scan(&xmlID, &tenantID, &typeName); err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil, "", false, nil
+		}
+		return "", nil, "", false, errors.New("querying delivery service: " + err.Error())
+	}
+	return xmlID, tenantID, typeName, true, nil
+}
+
+// dsQueryArgs returns the column values of the insertDSQuery and updateDSQuery, in order. Empty header rewrites are stored as null and geo limit countries are sanitized, as the Perl Traffic Ops did.
+func (ds *TODeliveryService) dsQueryArgs() []interface{} {
+	deepCachingType := tc.DeepCachingTypeNever.String()
+	if ds.DeepCachingType != nil {
+		deepCachingType = ds.DeepCachingType.String()
+	}
+	return []interface{}{
+		ds.Active,
+		ds.CacheURL,
+		ds.CCRDNSTTL,
+		ds.CDNID,
+		ds.CheckPath,
+		deepCachingType,
+		ds.DisplayName,
+		ds.DNSBypassCNAME,
+		ds.DNSBypassIP,
+		ds.DNSBypassIP6,
+		ds.DNSBypassTTL,
+		ds.DSCP,
+		ds.EdgeHeaderRewrite,
+		ds.FQPacingRate,
+		ds.GeoLimit,
+		SanitizeGeoLimitCountries(ds.GeoLimitCountries),
+		ds.GeoLimitRedirectURL,
+		ds.GeoProvider,
+		ds.GlobalMaxMBPS,
+		ds.GlobalMaxTPS,
+		ds.HTTPBypassFQDN,
+		ds.InfoURL,
+		ds.InitialDispersion,
+		ds.IPV6RoutingEnabled,
+		ds.LogsEnabled,
+		ds.LongDesc,
+		ds.LongDesc1,
+		ds.LongDesc2,
+		ds.MaxDNSAnswers,
+		ds.MidHeaderRewrite,
+		ds.MissLat,
+		ds.MissLong,
+		ds.MultiSiteOrigin,
+		ds.MultiSiteOriginAlgorithm,
+		ds.OrgServerFQDN,
+		ds.OriginShield,
+		ds.ProfileID,
+		ds.Protocol,
+		ds.QStringIgnore,
+		ds.RangeRequestHandling,
+		ds.RegexRemap,
+		ds.RegionalGeoBlocking,
+		ds.RemapText,
+		ds.RoutingName,
+		ds.SigningAlgorithm,
+		ds.SSLKeyVersion,
+		ds.TenantID,
+		ds.TRRequestHeaders,
+		ds.TRResponseHeaders,
+		ds.TypeID,
+		ds.XMLID,
+	}
+}
+
+// inTx runs f in a transaction, which is committed if f succeeds, and rolled back otherwise.
+func inTx(db *sqlx.DB, f func(tx *sqlx.Tx) (error, tc.ApiErrorType)) (error, tc.ApiErrorType) {
+	tx, err := db.Beginx()
+	if err != nil {
+		log.Error.Printf("could not begin transaction: %v", err)
+		return tc.DBError, tc.SystemError
+	}
+	if err, errType := f(tx); err != nil {
+		if err := tx.Rollback(); err != nil {
+			log.Errorln("rolling back transaction: " + err.Error())
+		}
+		return err, errType
+	}
+	if err := tx.Commit(); err != nil {
+		log.Errorln("Could not commit transaction: ", err)
+		return tc.DBError, tc.SystemError
+	}
+	return nil, tc.NoError
+}
+
 //The TODeliveryService implementation of the Updater interface
 //all implementations of Updater should use transactions and return the proper errorType
+func (ds *TODeliveryService) Update(db *sqlx.DB, user auth.CurrentUser) (error, tc.ApiErrorType) {
+	return inTx(db, func(tx *sqlx.Tx) (error, tc.ApiErrorType) { return ds.UpdateTx(tx, user) })
+}
+
+// UpdateTx updates the delivery service in the given transaction, which the caller commits or rolls back.
 //ParsePQUniqueConstraintError is used to determine if a delivery service with conflicting values exists
 //if so, it will return an errorType of DataConflict and the type should be appended to the
 //generic error message returned
-func (ds *TODeliveryService) Update(db *sqlx.DB, user auth.CurrentUser) (error, tc.ApiErrorType) {
-	tx, err := db.Beginx()
-	defer func() {
-		if tx == nil {
-			return
-		}
+//Like the Perl Traffic Ops, the xmlId is immutable, and tenancy may not be cleared when it is enabled. If a steering delivery service is changed to a non-steering type, its steering targets are removed.
+func (ds *TODeliveryService) UpdateTx(tx *sqlx.Tx, user auth.CurrentUser) (error, tc.ApiErrorType) {
+	currentXMLID, currentTenantID, currentTypeName, ok, err := getCurrentDSInfo(tx, *ds.ID)
+	if err != nil {
+		log.Errorln("updating delivery service: " + err.Error())
+		return tc.DBError, tc.SystemError
+	}
+	if !ok {
+		return errors.New("no delivery service found with this id"), tc.DataMissingError
+	}
+	if *ds.XMLID != currentXMLID {
+		return errors.New("a delivery service xmlId is immutable"), tc.DataConflictError
+	}
+	if ds.TenantID == nil && currentTenantID != nil {
+		useTenancy, err := tenant.IsTenancyEnabled(tx)
 		if err != nil {
-			tx.Rollback()
-			return
+			log.Errorln("checking tenancy: " + err.Error())
+			return tc.DBError, tc.SystemError
+		}
+		if useTenancy {
+			return errors.New("invalid tenant: cannot clear the delivery service tenancy"), tc.DataConflictError
 		}
-		tx.Commit()
-	}()
+	}
 
-	if err != nil {
-		log.Error.Printf("could not begin transaction: %v", err)
+	newTypeName := ""
+	if err := tx.QueryRow(`SELECT name FROM type WHERE id = $1`, *ds.TypeID).Scan(&newTypeName); err != nil {
+		log.Errorln("updating delivery service: querying type: " + err.Error())
 		return tc.DBError, tc.SystemError
 	}
+
 	log.Debugf("about to run exec query: %s with ds: %++v", updateDSQuery(), ds)
-	resultRows, err := tx.NamedQuery(updateDSQuery(), ds)
-	if err != nil {
-		if err, ok := err.(*pq.Error); ok {
-			err, eType := dbhelpers.ParsePQUniqueConstraintError(err)
+	args := append(ds.dsQueryArgs(), *ds.ID)
+	lastUpdated := tc.TimeNoMod{}
+	if err := tx.QueryRow(updateDSQuery(), args...).Scan(&lastUpdated); err != nil {
+		if pqErr, ok := err.(*pq.Error); ok {
+			err, eType := dbhelpers.ParsePQUniqueConstraintError(pqErr)
 			if eType == tc.DataConflictError {
 				return errors.New("a delivery service with " + err.Error()), eType
 			}
@@ -250,53 +601,70 @@ func (ds *TODeliveryService) Update(db *sqlx.DB, user auth.CurrentUser) (error,
 		log.Errorf("received error: %++v from update execution", err)
 		return tc.DBError, tc.SystemError
 	}
-	var lastUpdated tc.TimeNoMod
-	rowsAffected := 0
-	for resultRows.Next() {
-		rowsAffected++
-		if err := resultRows.Scan(&lastUpdated); err != nil {
-			log.Error.Printf("could not scan lastUpdated from insert: %s\n", err)
+
+	if IsSteeringType(currentTypeName) && !IsSteeringType(newTypeName) {
+		if err := deleteSteeringTargets(tx, *ds.ID, currentXMLID, newTypeName, user); err != nil {
+			log.Errorln("updating delivery service: " + err.Error())
 			return tc.DBError, tc.SystemError
 		}
 	}
-	log.Debugf("lastUpdated: %++v", lastUpdated)
-	ds.LastUpdated = &lastUpdated
-	if rowsAffected != 1 {
-		if rowsAffected < 1 {
-			return errors.New("no delivery service found with this id"), tc.DataMissingError
-		}
-		return fmt.Errorf("this update affected too many rows: %d", rowsAffected), tc.SystemError
+
+	if err := updateLocationParams(tx, ds, newTypeName); err != nil {
+		log.Errorln("updating delivery service: " + err.Error())
+		return tc.DBError, tc.SystemError
 	}
+
+	updated, ok, err := getDSByID(tx, *ds.ID)
+	if err != nil {
+		log.Errorln("updating delivery service: " + err.Error())
+		return tc.DBError, tc.SystemError
+	}
+	if !ok {
+		log.Errorln("delivery service not found after update")
+		return tc.DBError, tc.SystemError
+	}
+	*ds = updated
 	return nil, tc.NoError
 }
 
 // Create implements the Creator interface.
 //all implementations of Creator should use transactions and return the proper errorType
+func (ds *TODeliveryService) Create(db *sqlx.DB, user auth.CurrentUser) (error, tc.ApiErrorType) {
+	return inTx(db, func(tx *sqlx.Tx) (error, tc.ApiErrorType) { return ds.CreateTx(tx, user) })
+}
+
+// CreateTx creates the delivery service in the given transaction, which the caller commits or rolls back.
 //ParsePQUniqueConstraintError is used to determine if a ds with conflicting values exists
 //if so, it will return an errorType of DataConflict and the type should be appended to the
 //generic error message returned
-//The insert sql returns the id and lastUpdated values of the newly inserted ds and have
-//to be added to the struct
-func (ds *TODeliveryService) Create(db *sqlx.DB, user auth.CurrentUser) (error, tc.ApiErrorType) {
-	tx, err := db.Beginx()
-	defer func() {
-		if tx == nil {
-			return
-		}
+//The created ds is read back, including its id, lastUpdated, and regexes, and set on the struct
+//Like the Perl Traffic Ops, if the tenant is not set, it defaults to the user's tenant when tenancy is disabled, and is an error when tenancy is enabled.
+//If no matchList is given, the default regex .*\.xmlId\..* is created.
+//DNSSEC keys aren't created, because they aren't in the database; callers must call RefreshDNSSEC after committing.
+func (ds *TODeliveryService) CreateTx(tx *sqlx.Tx, user auth.CurrentUser) (error, tc.ApiErrorType) {
+	if ds.TenantID == nil {
+		useTenancy, err := tenant.IsTenancyEnabled(tx)
 		if err != nil {
-			tx.Rollback()
-			return
+			log.Errorln("checking tenancy: " + err.Error())
+			return tc.DBError, tc.SystemError
+		}
+		if useTenancy {
+			return errors.New("invalid tenant: must set tenant for delivery service"), tc.DataConflictError
+		}
+		if user.TenantID != auth.TenantIDInvalid {
+			tenantID := user.TenantID
+			ds.TenantID = &tenantID
 		}
-		tx.Commit()
-	}()
+	}
 
-	if err != nil {
-		log.Error.Printf("could not begin transaction: %v", err)
+	typeName := ""
+	if err := tx.QueryRow(`SELECT name FROM type WHERE id = $1`, *ds.TypeID).Scan(&typeName); err != nil {
+		log.Errorln("creating delivery service: querying type: " + err.Error())
 		return tc.DBError, tc.SystemError
 	}
-	fmt.Printf("ds ---> %v\n", ds)
-	resultRows, err := tx.NamedQuery(insertDSQuery(), ds)
-	if err != nil {
+
+	id := 0
+	if err := tx.QueryRow(insertDSQuery(), ds.dsQueryArgs()...).Scan(&id); err != nil {
 		if pqerr, ok := err.(*pq.Error); ok {
 			err, eType := dbhelpers.ParsePQUniqueConstraintError(pqerr)
 			return errors.New("a delivery service with " + err.Error()), eType
@@ -304,51 +672,67 @@ func (ds *TODeliveryService) Create(db *sqlx.DB, user auth.CurrentUser) (error,
 		log.Errorf("received non pq error: %++v from create execution", err)
 		return tc.DBError, tc.SystemError
 	}
-	var id int
-	var lastUpdated tc.TimeNoMod
-	rowsAffected := 0
-	for resultRows.Next() {
-		rowsAffected++
-		if err := resultRows.Scan(&id, &lastUpdated); err != nil {
-			log.Error.Printf("could not scan id from insert: %s\n", err)
+
+	matchList := []tc.DeliveryServiceMatch{}
+	if ds.MatchList != nil {
+		matchList = *ds.MatchList
+	}
+	if len(matchList) == 0 {
+		matchList = []tc.DeliveryServiceMatch{{Type: RegexTypeHost, SetNumber: 0, Pattern: DefaultRegex(*ds.XMLID)}}
+	}
+	if err, errType := createRegexes(tx, id, matchList, user); err != nil {
+		if errType == tc.SystemError {
+			log.Errorln("creating delivery service: " + err.Error())
 			return tc.DBError, tc.SystemError
 		}
+		return err, errType
 	}
-	if rowsAffected == 0 {
-		err = errors.New("no delivery service was inserted, no id was returned")
-		log.Errorln(err)
+
+	ds.SetKeys(map[string]interface{}{"id": id})
+	if err := updateLocationParams(tx, ds, typeName); err != nil {
+		log.Errorln("creating delivery service: " + err.Error())
+		return tc.DBError, tc.SystemError
+	}
+
+	created, ok, err := getDSByID(tx, id)
+	if err != nil {
+		log.Errorln("creating delivery service: " + err.Error())
 		return tc.DBError, tc.SystemError
-	} else if rowsAffected > 1 {
-		err = errors.New("too many ids returned from delivery service insert")
-		log.Errorln(err)
+	}
+	if !ok {
+		log.Errorln("no delivery service was inserted, no id was returned")
 		return tc.DBError, tc.SystemError
 	}
-	ds.SetKeys(map[string]interface{}{"id": id})
-	ds.LastUpdated = &lastUpdated
+	*ds = created
 	return nil, tc.NoError
 }
 
 //The DeliveryService implementation of the Deleter interface
 //all implementations of Deleter should use transactions and return the proper errorType
 func (ds *TODeliveryService) Delete(db *sqlx.DB, user auth.CurrentUser) (error, tc.ApiErrorType) {
-	tx, err := db.Beginx()
-	defer func() {
-		if tx == nil {
-			return
-		}
-		if err != nil {
-			tx.Rollback()
-			return
-		}
-		tx.Commit()
-	}()
+	return inTx(db, func(tx *sqlx.Tx) (error, tc.ApiErrorType) { return ds.DeleteTx(tx, user) })
+}
 
+// DeleteTx deletes the delivery service in the given transaction, which the caller commits or rolls back.
+//Like the Perl Traffic Ops, the delivery service's regexes and config file location parameters are deleted with it. Regexes also used by other delivery services are kept. Steering targets to and from the delivery service are removed by the database cascade.
+func (ds *TODeliveryService) DeleteTx(tx *sqlx.Tx, user auth.CurrentUser) (error, tc.ApiErrorType) {
+	xmlID, _, _, ok, err := getCurrentDSInfo(tx, *ds.ID)
 	if err != nil {
-		log.Error.Printf("could not begin transaction: %v", err)
+		log.Errorln("deleting delivery service: " + err.Error())
 		return tc.DBError, tc.SystemError
 	}
+	if !ok {
+		return errors.New("no delivery service with that id found"), tc.DataMissingError
+	}
+	ds.XMLID = &xmlID
+
+	if err := deleteRegexes(tx, *ds.ID); err != nil {
+		log.Errorln("deleting delivery service: " + err.Error())
+		return tc.DBError, tc.SystemError
+	}
+
 	log.Debugf("about to run exec query: %s with Delivery Service: %++v", deleteDSQuery(), ds)
-	result, err := tx.NamedExec(deleteDSQuery(), ds)
+	result, err := tx.Exec(deleteDSQuery(), *ds.ID)
 	if err != nil {
 		log.Errorf("received error: %++v from delete execution", err)
 		return tc.DBError, tc.SystemError
@@ -361,45 +745,274 @@ func (ds *TODeliveryService) Delete(db *sqlx.DB, user auth.CurrentUser) (error,
 		if rowsAffected < 1 {
 			return errors.New("no delivery service with that id found"), tc.DataMissingError
 		}
-		return fmt.Errorf("this create affected too many rows: %d", rowsAffected), tc.SystemError
+		return fmt.Errorf("this delete affected too many rows: %d", rowsAffected), tc.SystemError
+	}
+
+	if err := deleteLocationParams(tx, xmlID); err != nil {
+		log.Errorln("deleting delivery service: " + err.Error())
+		return tc.DBError, tc.SystemError
 	}
 	return nil, tc.NoError
 }
 
 // IsTenantAuthorized implements the Tenantable interface to ensure the user is authorized on the deliveryservice tenant
+// For an existing delivery service, the user must be authorized on its current tenant as well as the requested one, so a delivery service can't be taken from, or given to, a tenant the user doesn't have.
 func (ds *TODeliveryService) IsTenantAuthorized(user auth.CurrentUser, db *sqlx.DB) (bool, error) {
+	if ds.ID != nil {
+		currentTenantID := (*int)(nil)
+		if err := db.QueryRow(`SELECT tenant_id FROM deliveryservice WHERE id = $1`, *ds.ID).Scan(&currentTenantID); err != nil && err != sql.ErrNoRows {
+			return false, errors.New("querying delivery service tenant: " + err.Error())
+		}
+		if currentTenantID != nil {
+			authorized, err := tenant.IsResourceAuthorizedToUser(*currentTenantID, user, db)
+			if err != nil || !authorized {
+				return authorized, err
+			}
+		}
+	}
 	if ds.TenantID == nil {
-		log.Debugf("tenantID is nil")
-		return false, errors.New("tenantID is nil")
+		// a missing tenant is checked against use_tenancy on create and update
+		return true, nil
 	}
 	return tenant.IsResourceAuthorizedToUser(*ds.TenantID, user, db)
 }
 
-//TODO: drichardson - plumb these out!
+func selectQuery() string {
+	query := `SELECT
+ds.active,
+ds.cacheurl,
+ds.ccr_dns_ttl,
+ds.cdn_id,
+cdn.name as cdn_name,
+ds.check_path,
+ds.deep_caching_type,
+ds.display_name,
+ds.dns_bypass_cname,
+ds.dns_bypass_ip,
+ds.dns_bypass_ip6,
+ds.dns_bypass_ttl,
+ds.dscp,
+ds.edge_header_rewrite,
+ds.fq_pacing_rate,
+ds.geo_limit,
+ds.geo_limit_countries,
+ds.geolimit_redirect_url,
+ds.geo_provider,
+ds.global_max_mbps,
+ds.global_max_tps,
+ds.http_bypass_fqdn,
+ds.id,
+ds.info_url,
+ds.initial_dispersion,
+ds.ipv6_routing_enabled,
+ds.last_updated,
+ds.logs_enabled,
+ds.long_desc,
+ds.long_desc_1,
+ds.long_desc_2,
+ds.max_dns_answers,
+ds.mid_header_rewrite,
+ds.miss_lat,
+ds.miss_long,
+ds.multi_site_origin,
+ds.multi_site_origin_algorithm,
+ds.org_server_fqdn,
+ds.origin_shield,
+ds.profile,
+p.name as profile_name,
+p.description as profile_description,
+ds.protocol,
+ds.qstring_ignore,
+ds.range_request_handling,
+ds.regex_remap,
+ds.regional_geo_blocking,
+ds.remap_text,
+ds.routing_name,
+ds.signing_algorithm,
+ds.ssl_key_version,
+ds.tenant_id,
+tenant.name as tenant_name,
+ds.tr_request_headers,
+ds.tr_response_headers,
+type.name as type_name,
+ds.type,
+ds.xml_id
+
+FROM deliveryservice as ds
+JOIN type ON ds.type = type.id
+JOIN cdn ON ds.cdn_id = cdn.id
+LEFT JOIN profile p ON ds.profile = p.id
+LEFT JOIN tenant ON ds.tenant_id = tenant.id`
+	return query
+}
+
 func updateDSQuery() string {
 	query := `UPDATE
-cdn SET
-dnssec_enabled=:dnssec_enabled,
-domain_name=:domain_name,
-name=:name
-WHERE id=:id RETURNING last_updated`
+deliveryservice SET
+active=$1,
+cacheurl=$2,
+ccr_dns_ttl=$3,
+cdn_id=$4,
+check_path=$5,
+deep_caching_type=$6,
+display_name=$7,
+dns_bypass_cname=$8,
+dns_bypass_ip=$9,
+dns_bypass_ip6=$10,
+dns_bypass_ttl=$11,
+dscp=$12,
+edge_header_rewrite=NULLIF($13, ''),
+fq_pacing_rate=$14,
+geo_limit=$15,
+geo_limit_countries=$16,
+geolimit_redirect_url=$17,
+geo_provider=$18,
+global_max_mbps=$19,
+global_max_tps=$20,
+http_bypass_fqdn=$21,
+info_url=$22,
+initial_dispersion=$23,
+ipv6_routing_enabled=$24,
+logs_enabled=$25,
+long_desc=$26,
+long_desc_1=$27,
+long_desc_2=$28,
+max_dns_answers=$29,
+mid_header_rewrite=NULLIF($30, ''),
+miss_lat=$31,
+miss_long=$32,
+multi_site_origin=$33,
+multi_site_origin_algorithm=$34,
+org_server_fqdn=$35,
+origin_shield=$36,
+profile=$37,
+protocol=$38,
+qstring_ignore=$39,
+range_request_handling=$40,
+regex_remap=$41,
+regional_geo_blocking=$42,
+remap_text=$43,
+routing_name=COALESCE(NULLIF($44, ''), routing_name),
+signing_algorithm=$45,
+ssl_key_version=$46,
+tenant_id=$47,
+tr_request_headers=$48,
+tr_response_headers=$49,
+type=$50,
+xml_id=$51
+WHERE id=$52 RETURNING last_updated`
 	return query
 }
 
-//TODO: drichardson - plumb these out!
 func insertDSQuery() string {
 	query := `INSERT INTO deliveryservice (
-dnssec_enabled,
-domain_name,
-name) VALUES (
-:dnssec_enabled,
-:domain_name,
-:name) RETURNING id,last_updated`
+active,
+cacheurl,
+ccr_dns_ttl,
+cdn_id,
+check_path,
+deep_caching_type,
+display_name,
+dns_bypass_cname,
+dns_bypass_ip,
+dns_bypass_ip6,
+dns_bypass_ttl,
+dscp,
+edge_header_rewrite,
+fq_pacing_rate,
+geo_limit,
+geo_limit_countries,
+geolimit_redirect_url,
+geo_provider,
+global_max_mbps,
+global_max_tps,
+http_bypass_fqdn,
+info_url,
+initial_dispersion,
+ipv6_routing_enabled,
+logs_enabled,
+long_desc,
+long_desc_1,
+long_desc_2,
+max_dns_answers,
+mid_header_rewrite,
+miss_lat,
+miss_long,
+multi_site_origin,
+multi_site_origin_algorithm,
+org_server_fqdn,
+origin_shield,
+profile,
+protocol,
+qstring_ignore,
+range_request_handling,
+regex_remap,
+regional_geo_blocking,
+remap_text,
+routing_name,
+signing_algorithm,
+ssl_key_version,
+tenant_id,
+tr_request_headers,
+tr_response_headers,
+type,
+xml_id) VALUES (
+$1,
+$2,
+$3,
+$4,
+$5,
+$6,
+$7,
+$8,
+$9,
+$10,
+$11,
+$12,
+NULLIF($13, ''),
+$14,
+$15,
+$16,
+$17,
+$18,
+$19,
+$20,
+$21,
+$22,
+$23,
+$24,
+$25,
+$26,
+$27,
+$28,
+$29,
+NULLIF($30, ''),
+$31,
+$32,
+$33,
+$34,
+$35,
+$36,
+$37,
+$38,
+$39,
+$40,
+$41,
+$42,
+$43,
+COALESCE(NULLIF($44, ''), 'cdn'),
+$45,
+$46,
+$47,
+$48,
+$49,
+$50,
+$51) RETURNING id`
 	return query
 }
 
 func deleteDSQuery() string {
 	query := `DELETE FROM deliveryservice
-WHERE id=:id`
+WHERE id=$1`
 	return query
 }
@@ -23,6 +23,9 @@ import (
 	"testing"
 
 	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/jmoiron/sqlx"
+
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
 )
 
 func getInterface(req *TODeliveryService) interface{} {
@@ -35,10 +38,9 @@ func TestInterfaces(t *testing.T) {
 	if _, ok := r.(api.Creator); !ok {
 		t.Errorf("DeliveryService must be Creator")
 	}
-	// TODO: uncomment when ds.Reader interface is implemented
-	/*if _, ok := r.(api.Reader); !ok {
+	if _, ok := r.(api.Reader); !ok {
 		t.Errorf("DeliveryService must be Reader")
-	}*/
+	}
 	if _, ok := r.(api.Updater); !ok {
 		t.Errorf("DeliveryService must be Updater")
 	}
@@ -52,3 +54,81 @@ func TestInterfaces(t *testing.T) {
 		t.Errorf("DeliveryService must be Tenantable")
 	}
 }
+
+func TestSanitizeGeoLimitCountries(t *testing.T) {
+	if SanitizeGeoLimitCountries(nil) != nil {
+		t.Errorf("expected nil countries to sanitize to nil")
+	}
+	countries := " us, ca ,\tmx "
+	if actual := SanitizeGeoLimitCountries(&countries); actual == nil || *actual != "US,CA,MX" {
+		t.Errorf("expected 'US,CA,MX', actual %v", actual)
+	}
+}
+
+func TestIsSteeringType(t *testing.T) {
+	expected := map[string]bool{
+		"STEERING":        true,
+		"CLIENT_STEERING": true,
+		"HTTP":            false,
+		"DNS_LIVE":        false,
+		"ANY_MAP":         false,
+	}
+	for typeName, isSteering := range expected {
+		if actual := IsSteeringType(typeName); actual != isSteering {
+			t.Errorf("IsSteeringType(%v) expected %v, actual %v", typeName, isSteering, actual)
+		}
+	}
+}
+
+func TestDefaultRegex(t *testing.T) {
+	if actual := DefaultRegex("demo1"); actual != `.*\.demo1\..*` {
+		t.Errorf("expected default regex '.*\\.demo1\\..*', actual '%v'", actual)
+	}
+}
+
+func TestRequiredIfMatchesTypeName(t *testing.T) {
+	dnsOrHTTP := []string{"^DNS.*$", "^HTTP.*$"}
+	if err := requiredIfMatchesTypeName(dnsOrHTTP, "HTTP_LIVE")(nil); err == nil {
+		t.Errorf("expected nil value with matching type to be an error")
+	}
+	val := 42
+	if err := requiredIfMatchesTypeName(dnsOrHTTP, "HTTP_LIVE")(&val); err != nil {
+		t.Errorf("expected non-nil value with matching type not to be an error, actual: %v", err)
+	}
+	if err := requiredIfMatchesTypeName(dnsOrHTTP, "STEERING")(nil); err != nil {
+		t.Errorf("expected nil value with non-matching type not to be an error, actual: %v", err)
+	}
+}
+
+func TestGetMatchLists(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"deliveryservice", "type", "pattern", "set_number"})
+	rows = rows.AddRow(1, RegexTypeHost, DefaultRegex("ds1"), 0)
+	rows = rows.AddRow(1, RegexTypePath, `/path/.*`, 1)
+	mock.ExpectQuery("SELECT").WillReturnRows(rows)
+
+	matchLists, err := getMatchLists(db, []int{1, 2})
+	if err != nil {
+		t.Fatalf("getMatchLists expected: nil error, actual: %v", err)
+	}
+	if len(matchLists[1]) != 2 {
+		t.Fatalf("getMatchLists expected: 2 matches for ds 1, actual: %v", len(matchLists[1]))
+	}
+	if matchLists[1][0].Type != RegexTypeHost || matchLists[1][0].Pattern != DefaultRegex("ds1") || matchLists[1][0].SetNumber != 0 {
+		t.Errorf("getMatchLists expected: default host regex first, actual: %+v", matchLists[1][0])
+	}
+	if matchLists[1][1].Type != RegexTypePath || matchLists[1][1].SetNumber != 1 {
+		t.Errorf("getMatchLists expected: path regex second, actual: %+v", matchLists[1][1])
+	}
+	if ml, ok := matchLists[2]; !ok || ml == nil || len(ml) != 0 {
+		t.Errorf("getMatchLists expected: empty match list for ds 2, actual: %+v", ml)
+	}
+}
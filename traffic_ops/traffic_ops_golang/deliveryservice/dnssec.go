@@ -0,0 +1,73 @@
+package deliveryservice
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"net/http"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-log"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/config"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/dnssec"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/secretsvc"
+	"github.com/jmoiron/sqlx"
+)
+
+// CreateHandler creates a delivery service like api.CreateHandler, and then, like the Perl Traffic Ops, generates its DNSSEC keys if its CDN has DNSSEC enabled.
+func CreateHandler(db *sqlx.DB, cfg config.Config) http.HandlerFunc {
+	create := api.CreateHandler(GetRefType(), db)
+	return func(w http.ResponseWriter, r *http.Request) {
+		sw := &statusWriter{ResponseWriter: w}
+		create(sw, r)
+		if sw.status == http.StatusOK {
+			RefreshDNSSEC(db, cfg)
+		}
+	}
+}
+
+// RefreshDNSSEC generates the DNSSEC keys of newly created delivery services in the background, by refreshing the keys of every CDN with DNSSEC enabled. Keys are generated after the delivery service is committed, because they are kept in the secret store rather than the database, so failures are logged and left to the periodic refresh to retry, rather than failing the create.
+func RefreshDNSSEC(db *sqlx.DB, cfg config.Config) {
+	if !secretsvc.Enabled(cfg) {
+		return
+	}
+	go func() {
+		if err := dnssec.Refresh(db, cfg); err != nil {
+			log.Errorln("generating DNSSEC keys of created delivery service: " + err.Error())
+		}
+	}()
+}
+
+// statusWriter records the status of the response it writes.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
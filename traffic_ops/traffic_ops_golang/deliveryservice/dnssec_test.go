@@ -0,0 +1,44 @@
+package deliveryservice
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStatusWriter(t *testing.T) {
+	tests := []struct {
+		write    func(w http.ResponseWriter)
+		expected int
+	}{
+		{func(w http.ResponseWriter) {}, 0},
+		{func(w http.ResponseWriter) { w.Write([]byte("{}")) }, http.StatusOK},
+		{func(w http.ResponseWriter) { w.WriteHeader(http.StatusBadRequest); w.Write([]byte("{}")) }, http.StatusBadRequest},
+	}
+	for _, test := range tests {
+		sw := &statusWriter{ResponseWriter: httptest.NewRecorder()}
+		test.write(sw)
+		if sw.status != test.expected {
+			t.Errorf("statusWriter expected: %d, actual: %d", test.expected, sw.status)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package deliveryservice
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-log"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/ats"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// locationConfigFile is a delivery service config file which needs a 'location' parameter on the profiles of servers which use it, so ORT fetches it.
+type locationConfigFile struct {
+	Name  string
+	Value *string
+	Mid   bool
+}
+
+// updateLocationParams creates the location parameters of the delivery service's header rewrite, regex remap, and cacheurl config files, and assigns them to the profiles of the servers which need them. Location parameters of config files the delivery service no longer has are deleted. This matches the Perl Traffic Ops, where edge files go to the profiles of assigned servers, and the mid header rewrite goes to the profiles of all mids in the delivery service's CDN.
+func updateLocationParams(tx *sqlx.Tx, ds *TODeliveryService, typeName string) error {
+	xmlID := *ds.XMLID
+	files := []locationConfigFile{
+		{Name: ats.GetConfigFile(ats.HeaderRewritePrefix, xmlID), Value: ds.EdgeHeaderRewrite},
+		{Name: ats.GetConfigFile(ats.RegexRemapPrefix, xmlID), Value: ds.RegexRemap},
+		{Name: ats.GetConfigFile(ats.CacheUrlPrefix, xmlID), Value: ds.CacheURL},
+	}
+	// live local delivery services don't go to mids, so they don't get mid header rewrites
+	if !(strings.Contains(typeName, "LIVE") && !strings.Contains(typeName, "NATNL")) {
+		files = append(files, locationConfigFile{Name: ats.GetConfigFile(ats.MidHeaderRewritePrefix, xmlID), Value: ds.MidHeaderRewrite, Mid: true})
+	}
+
+	deletes := []string{}
+	for _, file := range files {
+		if file.Value == nil || *file.Value == "" {
+			deletes = append(deletes, file.Name)
+			continue
+		}
+		paramID, err := getOrCreateLocationParam(tx, file.Name)
+		if err != nil {
+			return err
+		}
+		if file.Mid {
+			qry := `
+INSERT INTO profile_parameter (profile, parameter)
+SELECT DISTINCT s.profile, $1::bigint FROM server as s
+JOIN type as t ON s.type = t.id
+WHERE t.name LIKE 'MID%' AND s.cdn_id = $2
+ON CONFLICT DO NOTHING
+`
+			if _, err := tx.Exec(qry, paramID, *ds.CDNID); err != nil {
+				return errors.New("assigning mid location parameter to profiles: " + err.Error())
+			}
+			continue
+		}
+		qry := `
+INSERT INTO profile_parameter (profile, parameter)
+SELECT DISTINCT s.profile, $1::bigint FROM server as s
+JOIN deliveryservice_server as dss ON dss.server = s.id
+WHERE dss.deliveryservice = $2
+ON CONFLICT DO NOTHING
+`
+		if _, err := tx.Exec(qry, paramID, *ds.ID); err != nil {
+			return errors.New("assigning location parameter to profiles: " + err.Error())
+		}
+	}
+
+	if len(deletes) > 0 {
+		if _, err := tx.Exec(`DELETE FROM parameter WHERE name = 'location' AND config_file = ANY($1)`, pq.Array(deletes)); err != nil {
+			return errors.New("deleting location parameters: " + err.Error())
+		}
+	}
+	return nil
+}
+
+// getOrCreateLocationParam returns the ID of the location parameter of the given config file, creating it in the same directory as remap.config if it doesn't exist.
+func getOrCreateLocationParam(tx *sqlx.Tx, configFile string) (int, error) {
+	id := 0
+	err := tx.QueryRow(`SELECT id FROM parameter WHERE name = 'location' AND config_file = $1 ORDER BY id LIMIT 1`, configFile).Scan(&id)
+	if err == nil {
+		return id, nil
+	}
+	if err != sql.ErrNoRows {
+		return 0, errors.New("querying location parameter: " + err.Error())
+	}
+
+	location := ""
+	if err := tx.QueryRow(`SELECT value FROM parameter WHERE name = 'location' AND config_file = $1 ORDER BY id LIMIT 1`, ats.RemapFile).Scan(&location); err != nil {
+		if err != sql.ErrNoRows {
+			return 0, errors.New("querying remap.config location parameter: " + err.Error())
+		}
+		log.Warnln("creating location parameter for " + configFile + ": no remap.config location parameter, using empty location")
+	}
+	location = strings.TrimSuffix(location, "/")
+
+	if err := tx.QueryRow(`INSERT INTO parameter (config_file, name, value) VALUES ($1, 'location', $2) RETURNING id`, configFile, location).Scan(&id); err != nil {
+		return 0, errors.New("inserting location parameter: " + err.Error())
+	}
+	return id, nil
+}
+
+// deleteLocationParams deletes the location parameters of all config files of the given delivery service.
+func deleteLocationParams(tx *sqlx.Tx, xmlID string) error {
+	files := []string{
+		ats.GetConfigFile(ats.HeaderRewritePrefix, xmlID),
+		ats.GetConfigFile(ats.MidHeaderRewritePrefix, xmlID),
+		ats.GetConfigFile(ats.RegexRemapPrefix, xmlID),
+		ats.GetConfigFile(ats.CacheUrlPrefix, xmlID),
+	}
+	if _, err := tx.Exec(`DELETE FROM parameter WHERE name = 'location' AND config_file = ANY($1)`, pq.Array(files)); err != nil {
+		return errors.New("deleting location parameters: " + err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package deliveryservice
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"errors"
+	"strconv"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const RegexTypeHost = "HOST_REGEXP"
+const RegexTypePath = "PATH_REGEXP"
+const RegexTypeHeader = "HEADER_REGEXP"
+
+// DefaultRegex returns the host regex created for a new delivery service when no match list is given. This matches the Perl Traffic Ops, which creates it for every new delivery service, so DNS and HTTP delivery services are routable as soon as they are created.
+func DefaultRegex(xmlID string) string {
+	return `.*\.` + xmlID + `\..*`
+}
+
+// queryer is the Query func common to sql.DB, sql.Tx, and their sqlx equivalents.
+type queryer interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// getMatchLists returns the regexes of the given delivery services, ordered by set number, as a map of delivery service IDs to match lists. Every given ID is in the returned map, even if it has no regexes.
+func getMatchLists(db queryer, dsIDs []int) (map[int][]tc.DeliveryServiceMatch, error) {
+	matchLists := make(map[int][]tc.DeliveryServiceMatch, len(dsIDs))
+	for _, id := range dsIDs {
+		matchLists[id] = []tc.DeliveryServiceMatch{}
+	}
+	if len(dsIDs) == 0 {
+		return matchLists, nil
+	}
+	qry := `
+SELECT
+  dsr.deliveryservice,
+  t.name as type,
+  r.pattern,
+  COALESCE(dsr.set_number, 0)
+FROM deliveryservice_regex as dsr
+JOIN regex as r ON dsr.regex = r.id
+JOIN type as t ON r.type = t.id
+WHERE dsr.deliveryservice = ANY($1)
+ORDER BY dsr.deliveryservice, dsr.set_number, r.id
+`
+	ids := make([]int64, 0, len(dsIDs))
+	for _, id := range dsIDs {
+		ids = append(ids, int64(id))
+	}
+	rows, err := db.Query(qry, pq.Array(ids))
+	if err != nil {
+		return nil, errors.New("querying delivery service regexes: " + err.Error())
+	}
+	defer rows.Close()
+	for rows.Next() {
+		dsID := 0
+		m := tc.DeliveryServiceMatch{}
+		if err := rows.Scan(&dsID, &m.Type, &m.Pattern, &m.SetNumber); err != nil {
+			return nil, errors.New("scanning delivery service regexes: " + err.Error())
+		}
+		matchLists[dsID] = append(matchLists[dsID], m)
+	}
+	return matchLists, nil
+}
+
+// createRegexes creates the given regexes and assigns them to the given delivery service, writing a change log entry for each. Unknown regex types are a tc.DataConflictError.
+func createRegexes(tx *sqlx.Tx, dsID int, matchList []tc.DeliveryServiceMatch, user auth.CurrentUser) (error, tc.ApiErrorType) {
+	typeIDs := map[string]int{}
+	for _, m := range matchList {
+		if m.Type != RegexTypeHost && m.Type != RegexTypePath && m.Type != RegexTypeHeader {
+			return errors.New("invalid matchList type '" + m.Type + "': must be one of " + RegexTypeHost + ", " + RegexTypePath + ", or " + RegexTypeHeader), tc.DataConflictError
+		}
+		if m.Pattern == "" {
+			return errors.New("invalid matchList: pattern cannot be blank"), tc.DataConflictError
+		}
+		if _, ok := typeIDs[m.Type]; ok {
+			continue
+		}
+		typeID := 0
+		if err := tx.QueryRow(`SELECT id FROM type WHERE name = $1`, m.Type).Scan(&typeID); err != nil {
+			if err == sql.ErrNoRows {
+				return errors.New("regex type '" + m.Type + "' not found"), tc.DataConflictError
+			}
+			return errors.New("querying regex type: " + err.Error()), tc.SystemError
+		}
+		typeIDs[m.Type] = typeID
+	}
+
+	for _, m := range matchList {
+		regexID := 0
+		if err := tx.QueryRow(`INSERT INTO regex (type, pattern) VALUES ($1, $2) RETURNING id`, typeIDs[m.Type], m.Pattern).Scan(&regexID); err != nil {
+			return errors.New("inserting regex: " + err.Error()), tc.SystemError
+		}
+		if _, err := tx.Exec(`INSERT INTO deliveryservice_regex (deliveryservice, regex, set_number) VALUES ($1, $2, $3)`, dsID, regexID, m.SetNumber); err != nil {
+			return errors.New("inserting deliveryservice_regex: " + err.Error()), tc.SystemError
+		}
+		msg := "Created delivery service regex at position " + strconv.Itoa(m.SetNumber) + " [ " + m.Pattern + " ] for deliveryservice: " + strconv.Itoa(dsID)
+		if err := api.CreateChangeLogRawTx(api.ApiChange, msg, user, tx); err != nil {
+			return errors.New("creating regex change log: " + err.Error()), tc.SystemError
+		}
+	}
+	return nil, tc.NoError
+}
+
+// deleteRegexes deletes the regexes of the given delivery service, which aren't also used by another delivery service.
+func deleteRegexes(tx *sqlx.Tx, dsID int) error {
+	qry := `
+DELETE FROM regex
+WHERE id IN (SELECT regex FROM deliveryservice_regex WHERE deliveryservice = $1)
+AND id NOT IN (SELECT regex FROM deliveryservice_regex WHERE deliveryservice <> $1)
+`
+	if _, err := tx.Exec(qry, dsID); err != nil {
+		return errors.New("deleting delivery service regexes: " + err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,292 @@
+package request
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-log"
+	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// ApproveCapability is the capability required to give a verdict on a delivery service request.
+const ApproveCapability = "ds-request-approve"
+
+// policiesQuery selects the policies applying to a tenant ($1) and CDN ($2), with their approvers. Policies of a tenant apply to its descendants.
+const policiesQuery = `
+WITH RECURSIVE ancestors AS (SELECT id, parent_id FROM tenant WHERE id = $1
+UNION SELECT t.id, t.parent_id FROM tenant AS t JOIN ancestors AS a ON t.id = a.parent_id)
+SELECT p.required_approvals,
+ARRAY(SELECT u.tm_user_id FROM deliveryservice_request_approval_policy_user AS u WHERE u.policy_id = p.id) AS approver_ids,
+ARRAY(SELECT pr.role_id FROM deliveryservice_request_approval_policy_role AS pr WHERE pr.policy_id = p.id) AS approver_role_ids
+FROM deliveryservice_request_approval_policy AS p
+WHERE (p.tenant_id IS NULL OR p.tenant_id IN (SELECT id FROM ancestors))
+AND (p.cdn_id IS NULL OR p.cdn_id = $2)
+`
+
+// reviewsQuery selects the latest verdict of each reviewer of a request ($1), with the reviewer's role. Verdicts of the request's author don't count, and nor do verdicts from before the request's delivery service was last edited, which were on a different change.
+const reviewsQuery = `
+SELECT DISTINCT ON (c.author_id) c.author_id, a.username AS author, a.role AS author_role, c.verdict, c.id, c.last_updated
+FROM deliveryservice_request_comment AS c
+JOIN tm_user AS a ON a.id = c.author_id
+JOIN deliveryservice_request AS r ON r.id = c.deliveryservice_request_id
+WHERE c.deliveryservice_request_id = $1 AND c.verdict IS NOT NULL AND c.author_id <> r.author_id
+AND c.id > r.ds_edit_comment_id
+ORDER BY c.author_id, c.id DESC
+`
+
+// approvalPolicy is a policy applying to a request, and its approvers.
+type approvalPolicy struct {
+	RequiredApprovals int           `db:"required_approvals"`
+	ApproverIDs       pq.Int64Array `db:"approver_ids"`
+	ApproverRoleIDs   pq.Int64Array `db:"approver_role_ids"`
+}
+
+// isApprover returns whether the verdict of the user with the given ID and role counts towards the policy.
+func (p approvalPolicy) isApprover(userID int, roleID *int) bool {
+	for _, id := range p.ApproverIDs {
+		if id == int64(userID) {
+			return true
+		}
+	}
+	if roleID == nil {
+		return false
+	}
+	for _, id := range p.ApproverRoleIDs {
+		if id == int64(*roleID) {
+			return true
+		}
+	}
+	return false
+}
+
+// review is a reviewer's verdict, with the reviewer's role, which may make them an approver.
+type review struct {
+	tc.DeliveryServiceRequestReview
+	RoleID *int `db:"author_role"`
+}
+
+// GetApprovals returns the review state of the delivery service request with the given id, and whether the request exists.
+func GetApprovals(requestID int, db sqlx.Queryer) (tc.DeliveryServiceRequestApprovals, bool, error) {
+	var tenantID *int
+	var cdnID *int
+	if err := db.QueryRowx(`SELECT CAST(deliveryservice->>'tenantId' AS bigint), CAST(deliveryservice->>'cdnId' AS bigint) FROM deliveryservice_request WHERE id = $1`, requestID).Scan(&tenantID, &cdnID); err != nil {
+		if err == sql.ErrNoRows {
+			return tc.DeliveryServiceRequestApprovals{}, false, nil
+		}
+		return tc.DeliveryServiceRequestApprovals{}, false, errors.New("querying deliveryservice request: " + err.Error())
+	}
+	policies, err := getApplyingPolicies(tenantID, cdnID, db)
+	if err != nil {
+		return tc.DeliveryServiceRequestApprovals{}, false, err
+	}
+	rows, err := db.Queryx(reviewsQuery, requestID)
+	if err != nil {
+		return tc.DeliveryServiceRequestApprovals{}, false, errors.New("querying deliveryservice request reviews: " + err.Error())
+	}
+	defer rows.Close()
+	reviews := []review{}
+	for rows.Next() {
+		r := review{}
+		if err := rows.StructScan(&r); err != nil {
+			return tc.DeliveryServiceRequestApprovals{}, false, errors.New("scanning deliveryservice request reviews: " + err.Error())
+		}
+		reviews = append(reviews, r)
+	}
+	return evaluateApprovals(policies, reviews), true, nil
+}
+
+// getApplyingPolicies returns the approval policies applying to requests for delivery services of the given tenant and CDN.
+func getApplyingPolicies(tenantID *int, cdnID *int, db sqlx.Queryer) ([]approvalPolicy, error) {
+	rows, err := db.Queryx(policiesQuery, tenantID, cdnID)
+	if err != nil {
+		return nil, errors.New("querying deliveryservice request approval policies: " + err.Error())
+	}
+	defer rows.Close()
+	policies := []approvalPolicy{}
+	for rows.Next() {
+		p := approvalPolicy{}
+		if err := rows.StructScan(&p); err != nil {
+			return nil, errors.New("scanning deliveryservice request approval policies: " + err.Error())
+		}
+		policies = append(policies, p)
+	}
+	return policies, nil
+}
+
+// evaluateApprovals returns the review state of a request with the given reviews, to which the given policies apply. Each policy must have the approvals it requires from its own approvers, and no approver of any policy may reject the request.
+func evaluateApprovals(policies []approvalPolicy, reviews []review) tc.DeliveryServiceRequestApprovals {
+	approvals := tc.DeliveryServiceRequestApprovals{Reviews: []tc.DeliveryServiceRequestReview{}}
+	for _, p := range policies {
+		if p.RequiredApprovals > approvals.RequiredApprovals {
+			approvals.RequiredApprovals = p.RequiredApprovals
+		}
+	}
+	policiesSatisfied := true
+	for _, p := range policies {
+		policyApprovals := 0
+		for _, r := range reviews {
+			if r.Verdict == tc.RequestVerdictApprove && p.isApprover(r.ReviewerID, r.RoleID) {
+				policyApprovals++
+			}
+		}
+		if policyApprovals < p.RequiredApprovals {
+			policiesSatisfied = false
+		}
+	}
+	for _, r := range reviews {
+		for _, p := range policies {
+			if p.isApprover(r.ReviewerID, r.RoleID) {
+				r.Approver = true
+				break
+			}
+		}
+		approvals.Reviews = append(approvals.Reviews, r.DeliveryServiceRequestReview)
+		if !r.Approver {
+			continue
+		}
+		switch r.Verdict {
+		case tc.RequestVerdictApprove:
+			approvals.Approvals++
+		case tc.RequestVerdictReject:
+			approvals.Rejections++
+		}
+	}
+	approvals.Satisfied = policiesSatisfied && approvals.Rejections == 0
+	return approvals
+}
+
+// approvalsError returns why a request with the given review state may not become pending, or nil if it is satisfied.
+func approvalsError(approvals tc.DeliveryServiceRequestApprovals) error {
+	if approvals.Satisfied {
+		return nil
+	}
+	if approvals.Rejections > 0 {
+		rejecters := []string{}
+		for _, review := range approvals.Reviews {
+			if review.Approver && review.Verdict == tc.RequestVerdictReject {
+				rejecters = append(rejecters, review.Reviewer)
+			}
+		}
+		return errors.New("deliveryservice request was rejected by " + strings.Join(rejecters, ", "))
+	}
+	if approvals.Approvals < approvals.RequiredApprovals {
+		return errors.New("deliveryservice request has " + strconv.Itoa(approvals.Approvals) + " of the " + strconv.Itoa(approvals.RequiredApprovals) + " approvals required to become pending")
+	}
+	return errors.New("deliveryservice request needs approvals from the approvers of each approval policy applying to it to become pending")
+}
+
+// checkApprovals returns a conflict error if the request with the given id doesn't satisfy its approval policy. Callers making the request pending should lock it first, in the same transaction, so no verdict can be added between the check and the status change.
+func checkApprovals(requestID int, db sqlx.Queryer) (error, tc.ApiErrorType) {
+	approvals, ok, err := GetApprovals(requestID, db)
+	if err != nil {
+		log.Errorln("checking deliveryservice request approvals: " + err.Error())
+		return tc.DBError, tc.SystemError
+	}
+	if !ok {
+		return errors.New("no deliveryservice request found with this id"), tc.DataMissingError
+	}
+	if err := approvalsError(approvals); err != nil {
+		return err, tc.DataConflictError
+	}
+	return nil, tc.NoError
+}
+
+// ValidateReview returns an error if the user may not give a verdict on the request with the given id. Reviewers need the ApproveCapability, can't review their own requests, and can only review submitted requests.
+// The request is share locked in the given transaction, which should insert the review, so the request can't become pending until the review is committed.
+func ValidateReview(requestID int, user auth.CurrentUser, tx sqlx.Queryer) (error, tc.ApiErrorType) {
+	if !user.HasCapability(ApproveCapability) {
+		return errors.New("the " + ApproveCapability + " capability is required to approve or reject deliveryservice requests"), tc.ForbiddenError
+	}
+	authorID := 0
+	status := tc.RequestStatus("")
+	if err := tx.QueryRowx(`SELECT author_id, status FROM deliveryservice_request WHERE id = $1 FOR SHARE`, requestID).Scan(&authorID, &status); err != nil {
+		if err == sql.ErrNoRows {
+			return errors.New("no deliveryservice request found with this id"), tc.DataMissingError
+		}
+		log.Errorln("querying deliveryservice request: " + err.Error())
+		return tc.DBError, tc.SystemError
+	}
+	if authorID == user.ID {
+		return errors.New("deliveryservice requests cannot be approved or rejected by their author"), tc.DataConflictError
+	}
+	if status != tc.RequestStatusSubmitted {
+		return errors.New("only submitted deliveryservice requests can be approved or rejected, this request is " + string(status)), tc.DataConflictError
+	}
+	return nil, tc.NoError
+}
+
+// ApprovalsHandler serves the review state of the delivery service request with the path id.
+func ApprovalsHandler(db *sqlx.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		handleErrs := tc.GetHandleErrorsFunc(w, r)
+		user, err := auth.GetCurrentUser(r.Context())
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, err)
+			return
+		}
+		params, err := api.GetCombinedParams(r)
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, err)
+			return
+		}
+		id, err := strconv.Atoi(params["id"])
+		if err != nil {
+			handleErrs(http.StatusBadRequest, errors.New("id must be an integer"))
+			return
+		}
+		authorized, err := IsRequestTenantAuthorized(id, *user, db)
+		if err != nil {
+			log.Errorln("checking deliveryservice request tenancy: " + err.Error())
+			handleErrs(http.StatusInternalServerError, tc.DBError)
+			return
+		}
+		if !authorized {
+			handleErrs(http.StatusForbidden, errors.New("not authorized on this tenant"))
+			return
+		}
+		approvals, ok, err := GetApprovals(id, db)
+		if err != nil {
+			log.Errorln("getting deliveryservice request approvals: " + err.Error())
+			handleErrs(http.StatusInternalServerError, tc.DBError)
+			return
+		}
+		if !ok {
+			handleErrs(http.StatusNotFound, errors.New("no deliveryservice request found with this id"))
+			return
+		}
+		respBts, err := json.Marshal(tc.DeliveryServiceRequestApprovalsResponse{Response: approvals})
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, err)
+			return
+		}
+		w.Header().Set(tc.ContentType, tc.ApplicationJson)
+		w.Write(respBts)
+	}
+}
@@ -0,0 +1,219 @@
+package request
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	tc "github.com/apache/incubator-trafficcontrol/lib/go-tc"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestEvaluateApprovals(t *testing.T) {
+	approverRole := 6
+	otherRole := 7
+	approve := review{DeliveryServiceRequestReview: tc.DeliveryServiceRequestReview{ReviewerID: 4, Reviewer: "alice", Verdict: tc.RequestVerdictApprove}}
+	roleApprove := review{DeliveryServiceRequestReview: tc.DeliveryServiceRequestReview{ReviewerID: 5, Reviewer: "bob", Verdict: tc.RequestVerdictApprove}, RoleID: &approverRole}
+	reject := review{DeliveryServiceRequestReview: tc.DeliveryServiceRequestReview{ReviewerID: 8, Reviewer: "carol", Verdict: tc.RequestVerdictReject}, RoleID: &approverRole}
+	outsiderApprove := review{DeliveryServiceRequestReview: tc.DeliveryServiceRequestReview{ReviewerID: 9, Reviewer: "dave", Verdict: tc.RequestVerdictApprove}, RoleID: &otherRole}
+	outsiderReject := review{DeliveryServiceRequestReview: tc.DeliveryServiceRequestReview{ReviewerID: 10, Reviewer: "erin", Verdict: tc.RequestVerdictReject}}
+	policy := func(required int) approvalPolicy {
+		return approvalPolicy{RequiredApprovals: required, ApproverIDs: pq.Int64Array{4}, ApproverRoleIDs: pq.Int64Array{int64(approverRole)}}
+	}
+	alicePolicy := approvalPolicy{RequiredApprovals: 1, ApproverIDs: pq.Int64Array{4}}
+	tests := []struct {
+		name       string
+		policies   []approvalPolicy
+		reviews    []review
+		approvals  int
+		rejections int
+		satisfied  bool
+	}{
+		{"no policy", nil, nil, 0, 0, true},
+		{"rejected without policy", nil, []review{reject}, 0, 0, true},
+		{"unapproved", []approvalPolicy{policy(1)}, nil, 0, 0, false},
+		{"approved by user", []approvalPolicy{policy(1)}, []review{approve}, 1, 0, true},
+		{"approved by role", []approvalPolicy{policy(1)}, []review{roleApprove}, 1, 0, true},
+		{"too few approvals", []approvalPolicy{policy(2)}, []review{approve}, 1, 0, false},
+		{"enough approvals", []approvalPolicy{policy(2)}, []review{approve, roleApprove}, 2, 0, true},
+		{"rejected", []approvalPolicy{policy(1)}, []review{approve, reject}, 1, 1, false},
+		{"approved by outsiders", []approvalPolicy{policy(2)}, []review{approve, outsiderApprove}, 1, 0, false},
+		{"rejected by outsider", []approvalPolicy{policy(1)}, []review{approve, outsiderReject}, 1, 0, true},
+		{"one of two policies approved", []approvalPolicy{policy(1), alicePolicy}, []review{roleApprove}, 1, 0, false},
+		{"both policies approved", []approvalPolicy{policy(1), alicePolicy}, []review{approve}, 1, 0, true},
+	}
+	for _, test := range tests {
+		actual := evaluateApprovals(test.policies, test.reviews)
+		if actual.Approvals != test.approvals || actual.Rejections != test.rejections || actual.Satisfied != test.satisfied {
+			t.Errorf("evaluateApprovals %s expected: %d approvals %d rejections satisfied %v, actual: %+v", test.name, test.approvals, test.rejections, test.satisfied, actual)
+		}
+		if len(actual.Reviews) != len(test.reviews) {
+			t.Errorf("evaluateApprovals %s expected: %d reviews, actual: %+v", test.name, len(test.reviews), actual.Reviews)
+		}
+	}
+}
+
+func TestApprovalsError(t *testing.T) {
+	approverRole := 6
+	reviews := []review{
+		{DeliveryServiceRequestReview: tc.DeliveryServiceRequestReview{ReviewerID: 4, Reviewer: "alice", Verdict: tc.RequestVerdictReject}, RoleID: &approverRole},
+		{DeliveryServiceRequestReview: tc.DeliveryServiceRequestReview{ReviewerID: 5, Reviewer: "bob", Verdict: tc.RequestVerdictApprove}, RoleID: &approverRole},
+		{DeliveryServiceRequestReview: tc.DeliveryServiceRequestReview{ReviewerID: 6, Reviewer: "carol", Verdict: tc.RequestVerdictReject}, RoleID: &approverRole},
+		{DeliveryServiceRequestReview: tc.DeliveryServiceRequestReview{ReviewerID: 7, Reviewer: "dave", Verdict: tc.RequestVerdictReject}},
+	}
+	policy := func(required int) approvalPolicy {
+		return approvalPolicy{RequiredApprovals: required, ApproverRoleIDs: pq.Int64Array{int64(approverRole)}}
+	}
+	tests := []struct {
+		approvals tc.DeliveryServiceRequestApprovals
+		expected  string
+	}{
+		{evaluateApprovals([]approvalPolicy{policy(1)}, reviews[1:2]), ""},
+		{evaluateApprovals([]approvalPolicy{policy(3)}, reviews[1:2]), "deliveryservice request has 1 of the 3 approvals required to become pending"},
+		{evaluateApprovals([]approvalPolicy{policy(1)}, reviews), "deliveryservice request was rejected by alice, carol"},
+		{evaluateApprovals([]approvalPolicy{policy(1), {RequiredApprovals: 1, ApproverIDs: pq.Int64Array{7}}}, reviews[1:2]), "deliveryservice request needs approvals from the approvers of each approval policy applying to it to become pending"},
+	}
+	for _, test := range tests {
+		err := approvalsError(test.approvals)
+		if (err == nil && test.expected != "") || (err != nil && err.Error() != test.expected) {
+			t.Errorf("approvalsError expected: %q, actual: %v", test.expected, err)
+		}
+	}
+}
+
+func TestGetApprovals(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	now := time.Date(2018, 5, 5, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"tenant_id", "cdn_id"}).AddRow(3, 2))
+	mock.ExpectQuery("deliveryservice_request_approval_policy").WithArgs(3, 2).WillReturnRows(policyRows().AddRow(2, []byte("{4}"), []byte("{6}")))
+	rows := sqlmock.NewRows([]string{"author_id", "author", "author_role", "verdict", "id", "last_updated"}).
+		AddRow(4, "alice", 1, []byte(tc.RequestVerdictApprove), 7, now).
+		AddRow(5, "bob", 6, []byte(tc.RequestVerdictApprove), 6, now).
+		AddRow(8, "carol", 1, []byte(tc.RequestVerdictReject), 5, now)
+	mock.ExpectQuery("deliveryservice_request_comment").WithArgs(1).WillReturnRows(rows)
+
+	approvals, ok, err := GetApprovals(1, db)
+	if err != nil || !ok {
+		t.Fatalf("GetApprovals expected: existing request, actual: %v %v", ok, err)
+	}
+	expected := tc.DeliveryServiceRequestApprovals{
+		RequiredApprovals: 2,
+		Approvals:         2,
+		Satisfied:         true,
+		Reviews: []tc.DeliveryServiceRequestReview{
+			{ReviewerID: 4, Reviewer: "alice", Verdict: tc.RequestVerdictApprove, CommentID: 7, LastUpdated: tc.TimeNoMod{Time: now, Valid: true}, Approver: true},
+			{ReviewerID: 5, Reviewer: "bob", Verdict: tc.RequestVerdictApprove, CommentID: 6, LastUpdated: tc.TimeNoMod{Time: now, Valid: true}, Approver: true},
+			{ReviewerID: 8, Reviewer: "carol", Verdict: tc.RequestVerdictReject, CommentID: 5, LastUpdated: tc.TimeNoMod{Time: now, Valid: true}},
+		},
+	}
+	if !reflect.DeepEqual(approvals, expected) {
+		t.Errorf("GetApprovals expected: %+v, actual: %+v", expected, approvals)
+	}
+
+	mock.ExpectQuery("SELECT").WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"tenant_id", "cdn_id"}))
+	if _, ok, err := GetApprovals(2, db); err != nil || ok {
+		t.Errorf("GetApprovals expected: missing request, actual: %v %v", ok, err)
+	}
+}
+
+func TestCheckApprovalsAfterEdit(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	// alice approved the request in comment 6, and then its delivery service was edited, so the request's edit comment id is 6, and her approval no longer counts.
+	mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"tenant_id", "cdn_id"}).AddRow(3, 2))
+	mock.ExpectQuery("deliveryservice_request_approval_policy").WithArgs(3, 2).WillReturnRows(policyRows().AddRow(1, []byte("{4,5}"), []byte("{}")))
+	mock.ExpectQuery(`c.id > r.ds_edit_comment_id`).WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"author_id", "author", "author_role", "verdict", "id", "last_updated"}))
+
+	err, errType := checkApprovals(1, db)
+	if errType != tc.DataConflictError {
+		t.Fatalf("checkApprovals of a request edited after approval expected: %v, actual: %v %v", tc.DataConflictError, errType, err)
+	}
+	if expected := "deliveryservice request has 0 of the 1 approvals required to become pending"; err.Error() != expected {
+		t.Errorf("checkApprovals of a request edited after approval expected: %q, actual: %q", expected, err.Error())
+	}
+
+	// after the edit, bob approves in comment 8, which counts.
+	mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"tenant_id", "cdn_id"}).AddRow(3, 2))
+	mock.ExpectQuery("deliveryservice_request_approval_policy").WithArgs(3, 2).WillReturnRows(policyRows().AddRow(1, []byte("{4,5}"), []byte("{}")))
+	rows := sqlmock.NewRows([]string{"author_id", "author", "author_role", "verdict", "id", "last_updated"}).AddRow(5, "bob", 1, []byte(tc.RequestVerdictApprove), 8, time.Now())
+	mock.ExpectQuery(`c.id > r.ds_edit_comment_id`).WithArgs(1).WillReturnRows(rows)
+	if err, errType := checkApprovals(1, db); err != nil || errType != tc.NoError {
+		t.Errorf("checkApprovals of a request approved after its edit expected: no error, actual: %v %v", errType, err)
+	}
+}
+
+func policyRows() *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"required_approvals", "approver_ids", "approver_role_ids"})
+}
+
+func TestValidateReview(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	reviewer := auth.CurrentUser{ID: 4, Capabilities: []string{ApproveCapability}}
+	if _, errType := ValidateReview(1, auth.CurrentUser{ID: 4}, db); errType != tc.ForbiddenError {
+		t.Errorf("ValidateReview without capability expected: %v, actual: %v", tc.ForbiddenError, errType)
+	}
+
+	tests := []struct {
+		authorID int
+		status   string
+		errType  tc.ApiErrorType
+	}{
+		{5, "submitted", tc.NoError},
+		{4, "submitted", tc.DataConflictError},
+		{5, "draft", tc.DataConflictError},
+		{5, "pending", tc.DataConflictError},
+	}
+	for _, test := range tests {
+		mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"author_id", "status"}).AddRow(test.authorID, []byte(test.status)))
+		if _, errType := ValidateReview(1, reviewer, db); errType != test.errType {
+			t.Errorf("ValidateReview of %s request by author %d expected: %v, actual: %v", test.status, test.authorID, test.errType, errType)
+		}
+	}
+
+	mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"author_id", "status"}))
+	if _, errType := ValidateReview(1, reviewer, db); errType != tc.DataMissingError {
+		t.Errorf("ValidateReview of missing request expected: %v, actual: %v", tc.DataMissingError, errType)
+	}
+}
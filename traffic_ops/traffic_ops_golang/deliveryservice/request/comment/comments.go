@@ -20,6 +20,7 @@ package comment
  */
 
 import (
+	"database/sql"
 	"errors"
 	"fmt"
 	"strconv"
@@ -29,6 +30,8 @@ import (
 	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/api"
 	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/auth"
 	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/dbhelpers"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/deliveryservice/request"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/tenant"
 	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/tovalidate"
 	"github.com/go-ozzo/ozzo-validation"
 	"github.com/jmoiron/sqlx"
@@ -77,10 +80,32 @@ func (comment TODeliveryServiceRequestComment) Validate(db *sqlx.DB) []error {
 	errs := validation.Errors{
 		"deliveryServiceRequestId": validation.Validate(comment.DeliveryServiceRequestID, validation.NotNil),
 		"value":                    validation.Validate(comment.Value, validation.NotNil),
+		"verdict":                  validation.Validate(comment.Verdict, validation.In(tc.RequestVerdictApprove, tc.RequestVerdictReject)),
 	}
 	return tovalidate.ToErrors(errs)
 }
 
+// IsTenantAuthorized implements the Tenantable interface to ensure the user is authorized on the tenant of the request being commented on, and of the request the comment is currently on, if it exists.
+func (comment TODeliveryServiceRequestComment) IsTenantAuthorized(user auth.CurrentUser, db *sqlx.DB) (bool, error) {
+	if comment.ID != nil && *comment.ID != 0 {
+		currentRequestID := 0
+		err := db.QueryRow(`SELECT deliveryservice_request_id FROM deliveryservice_request_comment WHERE id = $1`, *comment.ID).Scan(&currentRequestID)
+		if err != nil && err != sql.ErrNoRows {
+			return false, errors.New("querying comment deliveryservice request: " + err.Error())
+		}
+		if err == nil {
+			authorized, err := request.IsRequestTenantAuthorized(currentRequestID, user, db)
+			if err != nil || !authorized {
+				return authorized, err
+			}
+		}
+	}
+	if comment.DeliveryServiceRequestID == nil {
+		return true, nil
+	}
+	return request.IsRequestTenantAuthorized(*comment.DeliveryServiceRequestID, user, db)
+}
+
 func (comment *TODeliveryServiceRequestComment) Create(db *sqlx.DB, user auth.CurrentUser) (error, tc.ApiErrorType) {
 	rollbackTransaction := true
 	tx, err := db.Beginx()
@@ -99,6 +124,12 @@ func (comment *TODeliveryServiceRequestComment) Create(db *sqlx.DB, user auth.Cu
 		return tc.DBError, tc.SystemError
 	}
 
+	if comment.Verdict != nil {
+		if err, errType := request.ValidateReview(*comment.DeliveryServiceRequestID, user, tx); err != nil {
+			return err, errType
+		}
+	}
+
 	userID := tc.IDNoMod(user.ID)
 	comment.AuthorID = &userID
 
@@ -157,13 +188,16 @@ func (comment *TODeliveryServiceRequestComment) Read(db *sqlx.DB, parameters map
 		"authorId":                 dbhelpers.WhereColumnInfo{"dsrc.author_id", nil},
 		"author":                   dbhelpers.WhereColumnInfo{"a.username", nil},
 		"deliveryServiceRequestId": dbhelpers.WhereColumnInfo{"dsrc.deliveryservice_request_id", nil},
-		"id": dbhelpers.WhereColumnInfo{"dsrc.id", api.IsInt},
+		"id":                       dbhelpers.WhereColumnInfo{"dsrc.id", api.IsInt},
+		"lastUpdated":              dbhelpers.WhereColumnInfo{"dsrc.last_updated", nil},
 	}
 	where, orderBy, queryValues, errs := dbhelpers.BuildWhereAndOrderBy(parameters, queryParamsToQueryCols)
 	if len(errs) > 0 {
 		return nil, errs, tc.DataConflictError
 	}
 
+	where = tenant.AddTenancyCheck(where, queryValues, "CAST(dsr.deliveryservice->>'tenantId' AS bigint)", user)
+
 	query := selectQuery() + where + orderBy
 	log.Debugln("Query is ", query)
 
@@ -188,19 +222,6 @@ func (comment *TODeliveryServiceRequestComment) Read(db *sqlx.DB, parameters map
 }
 
 func (comment *TODeliveryServiceRequestComment) Update(db *sqlx.DB, user auth.CurrentUser) (error, tc.ApiErrorType) {
-
-	var current TODeliveryServiceRequestComment
-	err := db.QueryRowx(selectQuery() + `WHERE dsrc.id=` + strconv.Itoa(*comment.ID)).StructScan(&current)
-	if err != nil {
-		log.Errorf("Error querying DeliveryServiceRequestComments: %v", err)
-		return err, tc.SystemError
-	}
-
-	userID := tc.IDNoMod(user.ID)
-	if *current.AuthorID != userID {
-		return errors.New("Comments can only be updated by the author"), tc.DataConflictError
-	}
-
 	rollbackTransaction := true
 	tx, err := db.Beginx()
 	defer func() {
@@ -217,6 +238,40 @@ func (comment *TODeliveryServiceRequestComment) Update(db *sqlx.DB, user auth.Cu
 		log.Error.Printf("could not begin transaction: %v", err)
 		return tc.DBError, tc.SystemError
 	}
+	if err, errType := comment.UpdateTx(tx, user); err != nil {
+		return err, errType
+	}
+	err = tx.Commit()
+	if err != nil {
+		log.Errorln("Could not commit transaction: ", err)
+		return tc.DBError, tc.SystemError
+	}
+	rollbackTransaction = false
+	return nil, tc.NoError
+}
+
+// UpdateTx updates the delivery service request comment in the given transaction, which the caller commits or rolls back.
+func (comment *TODeliveryServiceRequestComment) UpdateTx(tx *sqlx.Tx, user auth.CurrentUser) (error, tc.ApiErrorType) {
+	var current TODeliveryServiceRequestComment
+	err := tx.QueryRowx(selectQuery() + `WHERE dsrc.id=` + strconv.Itoa(*comment.ID)).StructScan(&current)
+	if err != nil {
+		log.Errorf("Error querying DeliveryServiceRequestComments: %v", err)
+		return err, tc.SystemError
+	}
+
+	userID := tc.IDNoMod(user.ID)
+	if *current.AuthorID != userID {
+		return errors.New("Comments can only be updated by the author"), tc.DataConflictError
+	}
+
+	// a review is a record of the reviewer's verdict on the request at the time, so a new comment must be made to change it
+	if !verdictsEqual(comment.Verdict, current.Verdict) {
+		return errors.New("the verdict of a comment cannot be changed, add a new comment instead"), tc.DataConflictError
+	}
+	if current.Verdict != nil && *comment.DeliveryServiceRequestID != *current.DeliveryServiceRequestID {
+		return errors.New("a comment with a verdict cannot be moved to another deliveryservice request"), tc.DataConflictError
+	}
+
 	log.Debugf("about to run exec query: %s with comment: %++v", updateQuery(), comment)
 	resultRows, err := tx.NamedQuery(updateQuery(), comment)
 	if err != nil {
@@ -251,29 +306,10 @@ func (comment *TODeliveryServiceRequestComment) Update(db *sqlx.DB, user auth.Cu
 			return fmt.Errorf("this update affected too many rows: %d", rowsAffected), tc.SystemError
 		}
 	}
-	err = tx.Commit()
-	if err != nil {
-		log.Errorln("Could not commit transaction: ", err)
-		return tc.DBError, tc.SystemError
-	}
-	rollbackTransaction = false
 	return nil, tc.NoError
 }
 
 func (comment *TODeliveryServiceRequestComment) Delete(db *sqlx.DB, user auth.CurrentUser) (error, tc.ApiErrorType) {
-
-	var current TODeliveryServiceRequestComment
-	err := db.QueryRowx(selectQuery() + `WHERE dsrc.id=` + strconv.Itoa(*comment.ID)).StructScan(&current)
-	if err != nil {
-		log.Errorf("Error querying DeliveryServiceRequestComments: %v", err)
-		return err, tc.SystemError
-	}
-
-	userID := tc.IDNoMod(user.ID)
-	if *current.AuthorID != userID {
-		return errors.New("Comments can only be deleted by the author"), tc.DataConflictError
-	}
-
 	rollbackTransaction := true
 	tx, err := db.Beginx()
 	defer func() {
@@ -290,6 +326,32 @@ func (comment *TODeliveryServiceRequestComment) Delete(db *sqlx.DB, user auth.Cu
 		log.Error.Printf("could not begin transaction: %v", err)
 		return tc.DBError, tc.SystemError
 	}
+	if err, errType := comment.DeleteTx(tx, user); err != nil {
+		return err, errType
+	}
+	err = tx.Commit()
+	if err != nil {
+		log.Errorln("Could not commit transaction: ", err)
+		return tc.DBError, tc.SystemError
+	}
+	rollbackTransaction = false
+	return nil, tc.NoError
+}
+
+// DeleteTx deletes the delivery service request comment in the given transaction, which the caller commits or rolls back.
+func (comment *TODeliveryServiceRequestComment) DeleteTx(tx *sqlx.Tx, user auth.CurrentUser) (error, tc.ApiErrorType) {
+	var current TODeliveryServiceRequestComment
+	err := tx.QueryRowx(selectQuery() + `WHERE dsrc.id=` + strconv.Itoa(*comment.ID)).StructScan(&current)
+	if err != nil {
+		log.Errorf("Error querying DeliveryServiceRequestComments: %v", err)
+		return err, tc.SystemError
+	}
+
+	userID := tc.IDNoMod(user.ID)
+	if *current.AuthorID != userID {
+		return errors.New("Comments can only be deleted by the author"), tc.DataConflictError
+	}
+
 	log.Debugf("about to run exec query: %s with comment: %++v", deleteQuery(), comment)
 	result, err := tx.NamedExec(deleteQuery(), comment)
 	if err != nil {
@@ -307,23 +369,27 @@ func (comment *TODeliveryServiceRequestComment) Delete(db *sqlx.DB, user auth.Cu
 			return fmt.Errorf("this delete affected too many rows: %d", rowsAffected), tc.SystemError
 		}
 	}
-	err = tx.Commit()
-	if err != nil {
-		log.Errorln("Could not commit transaction: ", err)
-		return tc.DBError, tc.SystemError
-	}
-	rollbackTransaction = false
 	return nil, tc.NoError
 }
 
+// verdictsEqual returns whether the given comment verdicts, which may be nil, are the same.
+func verdictsEqual(a *string, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
 func insertQuery() string {
 	query := `INSERT INTO deliveryservice_request_comment (
 author_id,
 deliveryservice_request_id,
-value) VALUES (
+value,
+verdict) VALUES (
 :author_id,
 :deliveryservice_request_id,
-:value) RETURNING id,last_updated`
+:value,
+:verdict) RETURNING id,last_updated`
 	return query
 }
 
@@ -335,7 +401,8 @@ dsrc.deliveryservice_request_id,
 dsr.deliveryservice->>'xmlId' as xml_id,
 dsrc.id,
 dsrc.last_updated,
-dsrc.value
+dsrc.value,
+dsrc.verdict
 FROM deliveryservice_request_comment dsrc
 JOIN tm_user a ON dsrc.author_id = a.id
 JOIN deliveryservice_request dsr ON dsrc.deliveryservice_request_id = dsr.id
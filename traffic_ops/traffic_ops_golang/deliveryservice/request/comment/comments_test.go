@@ -25,6 +25,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
 	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/api"
 	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/test"
 )
@@ -87,4 +88,38 @@ func TestValidate(t *testing.T) {
 		t.Errorf("expected %s, got %s", expectedErrs, errs)
 	}
 
+	verdict := tc.RequestVerdictApprove
+	c.Verdict = &verdict
+	if errs = c.Validate(nil); len(errs) != 0 {
+		t.Errorf("expected no errors, got %s", errs)
+	}
+
+	verdict = "abstain"
+	expectedErrs = []error{errors.New(`'verdict' must be a valid value`)}
+	errs = c.Validate(nil)
+	if !reflect.DeepEqual(expectedErrs, errs) {
+		t.Errorf("expected %s, got %s", expectedErrs, errs)
+	}
+}
+
+func TestVerdictsEqual(t *testing.T) {
+	approve := tc.RequestVerdictApprove
+	approve2 := tc.RequestVerdictApprove
+	reject := tc.RequestVerdictReject
+	tests := []struct {
+		a     *string
+		b     *string
+		equal bool
+	}{
+		{nil, nil, true},
+		{&approve, &approve2, true},
+		{&approve, nil, false},
+		{nil, &reject, false},
+		{&approve, &reject, false},
+	}
+	for _, test := range tests {
+		if actual := verdictsEqual(test.a, test.b); actual != test.equal {
+			t.Errorf("verdictsEqual(%v, %v) expected: %v, actual: %v", test.a, test.b, test.equal, actual)
+		}
+	}
 }
@@ -0,0 +1,278 @@
+package request
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-log"
+	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
+	"github.com/apache/incubator-trafficcontrol/lib/go-util"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/config"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/deliveryservice"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/server"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// FulfillHandler applies a pending delivery service request to the live delivery service. In one transaction, the delivery service is created, updated or deleted as the request describes, and the request is completed and linked to the change log entry of the change. The request fails with a conflict if the delivery service has changed since the request was opened. If the body sets queueUpdates, updates are queued on the servers affected by the change. Like creating a delivery service directly, DNSSEC keys of a created delivery service are generated after the change is committed.
+func FulfillHandler(db *sqlx.DB, cfg config.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		handleErrs := tc.GetHandleErrorsFunc(w, r)
+		user, err := auth.GetCurrentUser(r.Context())
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, err)
+			return
+		}
+		params, err := api.GetCombinedParams(r)
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, err)
+			return
+		}
+		id, err := strconv.Atoi(params["id"])
+		if err != nil {
+			handleErrs(http.StatusBadRequest, errors.New("id must be an integer"))
+			return
+		}
+		fulfillReq := tc.DeliveryServiceRequestFulfillReq{}
+		if err := json.NewDecoder(r.Body).Decode(&fulfillReq); err != nil && err != io.EOF {
+			handleErrs(http.StatusBadRequest, errors.New("malformed JSON: "+err.Error()))
+			return
+		}
+
+		tx, err := db.Beginx()
+		if err != nil {
+			log.Errorln("could not begin transaction: " + err.Error())
+			handleErrs(http.StatusInternalServerError, tc.DBError)
+			return
+		}
+		committed := false
+		defer func() {
+			if committed {
+				return
+			}
+			if err := tx.Rollback(); err != nil {
+				log.Errorln("rolling back transaction: " + err.Error())
+			}
+		}()
+
+		fulfillment, err, errType := fulfill(db, tx, id, fulfillReq, *user)
+		if err != nil {
+			tc.HandleErrorsWithType([]error{err}, errType, handleErrs)
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			log.Errorln("Could not commit transaction: " + err.Error())
+			handleErrs(http.StatusInternalServerError, tc.DBError)
+			return
+		}
+		committed = true
+		if *fulfillment.Request.ChangeType == tc.RequestChangeTypeCreate {
+			deliveryservice.RefreshDNSSEC(db, cfg)
+		}
+
+		resp := tc.DeliveryServiceRequestFulfillmentResponse{
+			Response: fulfillment,
+			Alerts:   tc.CreateAlerts(tc.SuccessLevel, "Delivery service request "+strconv.Itoa(id)+" fulfilled: "+*fulfillment.Request.ChangeType+" of delivery service '"+TODeliveryServiceRequest(fulfillment.Request).getXMLID()+"'"),
+		}
+		respBts, err := json.Marshal(resp)
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, err)
+			return
+		}
+		w.Header().Set(tc.ContentType, tc.ApplicationJson)
+		w.Write(respBts)
+	}
+}
+
+// liveDS is the delivery service a request changes, as it currently exists.
+type liveDS struct {
+	ID    int
+	CDNID int
+	// Drifted is whether the delivery service was changed after the last_updated the request is based on.
+	Drifted     bool
+	LastUpdated tc.TimeNoMod
+}
+
+// getLiveDS returns the delivery service with the given xmlId, locked until the transaction ends, and whether it exists. It's drifted if it changed after the last_updated the request is based on, which is recorded when the request is opened or its delivery service edited; requests from before that was recorded fall back to their creation time.
+func getLiveDS(tx *sqlx.Tx, xmlID string, requestID int) (liveDS, bool, error) {
+	ds := liveDS{}
+	q := `
+SELECT ds.id, ds.cdn_id, ds.last_updated > COALESCE(r.ds_last_updated, r.created_at), ds.last_updated
+FROM deliveryservice AS ds, deliveryservice_request AS r
+WHERE ds.xml_id = $1 AND r.id = $2
+FOR UPDATE OF ds
+`
+	if err := tx.QueryRow(q, xmlID, requestID).Scan(&ds.ID, &ds.CDNID, &ds.Drifted, &ds.LastUpdated); err != nil {
+		if err == sql.ErrNoRows {
+			return liveDS{}, false, nil
+		}
+		return liveDS{}, false, errors.New("querying delivery service: " + err.Error())
+	}
+	return ds, true, nil
+}
+
+// getDSServerIDs returns the ids of the servers assigned to the delivery service.
+func getDSServerIDs(tx *sqlx.Tx, dsID int) ([]int, error) {
+	ids := []int{}
+	if err := tx.QueryRow(`SELECT ARRAY(SELECT server FROM deliveryservice_server WHERE deliveryservice = $1 ORDER BY server)`, dsID).Scan(pq.Array(&ids)); err != nil {
+		return nil, errors.New("querying delivery service servers: " + err.Error())
+	}
+	return ids, nil
+}
+
+// fulfill applies the pending request with the given id in the transaction, and returns the completed request and the change made. Tenancy and validation are checked with db, outside the transaction.
+func fulfill(db *sqlx.DB, tx *sqlx.Tx, id int, fulfillReq tc.DeliveryServiceRequestFulfillReq, user auth.CurrentUser) (tc.DeliveryServiceRequestFulfillment, error, tc.ApiErrorType) {
+	req := TODeliveryServiceRequest{}
+	if err := tx.QueryRowx(selectDeliveryServiceRequestsQuery()+`WHERE r.id = $1 FOR UPDATE OF r`, id).StructScan(&req); err != nil {
+		if err == sql.ErrNoRows {
+			return tc.DeliveryServiceRequestFulfillment{}, errors.New("no deliveryservice request found with this id"), tc.DataMissingError
+		}
+		log.Errorln("querying deliveryservice request: " + err.Error())
+		return tc.DeliveryServiceRequestFulfillment{}, tc.DBError, tc.SystemError
+	}
+	if err := req.Status.ValidTransition(tc.RequestStatusComplete); err != nil {
+		return tc.DeliveryServiceRequestFulfillment{}, errors.New("only pending deliveryservice requests can be fulfilled, this request is " + string(*req.Status)), tc.DataConflictError
+	}
+	if req.ChangeType == nil || req.DeliveryService == nil || req.DeliveryService.XMLID == nil {
+		return tc.DeliveryServiceRequestFulfillment{}, errors.New("the deliveryservice request has no change type or deliveryservice xmlId"), tc.DataMissingError
+	}
+	authorized, err := req.IsTenantAuthorized(user, db)
+	if err != nil {
+		log.Errorln("checking deliveryservice request tenancy: " + err.Error())
+		return tc.DeliveryServiceRequestFulfillment{}, tc.DBError, tc.SystemError
+	}
+	if !authorized {
+		return tc.DeliveryServiceRequestFulfillment{}, errors.New("not authorized on this tenant"), tc.ForbiddenError
+	}
+
+	xmlID := *req.DeliveryService.XMLID
+	live, exists, err := getLiveDS(tx, xmlID, id)
+	if err != nil {
+		log.Errorln("fulfilling deliveryservice request: " + err.Error())
+		return tc.DeliveryServiceRequestFulfillment{}, tc.DBError, tc.SystemError
+	}
+	switch {
+	case *req.ChangeType == tc.RequestChangeTypeCreate && exists:
+		return tc.DeliveryServiceRequestFulfillment{}, errors.New("delivery service '" + xmlID + "' was created after the request was opened"), tc.DataConflictError
+	case *req.ChangeType != tc.RequestChangeTypeCreate && !exists:
+		return tc.DeliveryServiceRequestFulfillment{}, errors.New("delivery service '" + xmlID + "' was deleted after the request was opened"), tc.DataConflictError
+	case exists && live.Drifted:
+		return tc.DeliveryServiceRequestFulfillment{}, errors.New("delivery service '" + xmlID + "' was changed at " + live.LastUpdated.String() + ", after the request was opened or last edited"), tc.DataConflictError
+	}
+
+	ds := deliveryservice.TODeliveryService(*req.DeliveryService)
+	ds.ID = nil
+	if exists {
+		ds.ID = &live.ID
+	}
+	if *req.ChangeType != tc.RequestChangeTypeDelete {
+		if errs := deliveryservice.Validate(db, req.DeliveryService); len(errs) > 0 {
+			return tc.DeliveryServiceRequestFulfillment{}, util.JoinErrs(errs), tc.DataConflictError
+		}
+	}
+	authorized, err = ds.IsTenantAuthorized(user, db)
+	if err != nil {
+		log.Errorln("checking delivery service tenancy: " + err.Error())
+		return tc.DeliveryServiceRequestFulfillment{}, tc.DBError, tc.SystemError
+	}
+	if !authorized {
+		return tc.DeliveryServiceRequestFulfillment{}, errors.New("not authorized on this tenant"), tc.ForbiddenError
+	}
+
+	serverIDs := []int{}
+	if exists {
+		// read before the change, because a deleted delivery service's assignments are deleted with it.
+		if serverIDs, err = getDSServerIDs(tx, live.ID); err != nil {
+			log.Errorln("fulfilling deliveryservice request: " + err.Error())
+			return tc.DeliveryServiceRequestFulfillment{}, tc.DBError, tc.SystemError
+		}
+	}
+
+	action := ""
+	errType := tc.NoError
+	switch *req.ChangeType {
+	case tc.RequestChangeTypeCreate:
+		action = api.Created
+		err, errType = ds.CreateTx(tx, user)
+	case tc.RequestChangeTypeUpdate:
+		action = api.Updated
+		err, errType = ds.UpdateTx(tx, user)
+	case tc.RequestChangeTypeDelete:
+		action = api.Deleted
+		err, errType = ds.DeleteTx(tx, user)
+	default:
+		return tc.DeliveryServiceRequestFulfillment{}, errors.New("unknown change type '" + *req.ChangeType + "'"), tc.DataConflictError
+	}
+	if err != nil {
+		return tc.DeliveryServiceRequestFulfillment{}, err, errType
+	}
+
+	msg, err := ds.ChangeLogMessage(action)
+	if err != nil {
+		log.Errorln("fulfilling deliveryservice request: " + err.Error())
+		return tc.DeliveryServiceRequestFulfillment{}, tc.DBError, tc.SystemError
+	}
+	logID, err := api.CreateChangeLogRawTxID(api.ApiChange, msg, user, tx)
+	if err != nil {
+		return tc.DeliveryServiceRequestFulfillment{}, tc.DBError, tc.SystemError
+	}
+	if _, err := tx.Exec(`UPDATE deliveryservice_request SET status = $1, change_log_id = $2 WHERE id = $3`, string(tc.RequestStatusComplete), logID, id); err != nil {
+		log.Errorln("completing deliveryservice request: " + err.Error())
+		return tc.DeliveryServiceRequestFulfillment{}, tc.DBError, tc.SystemError
+	}
+	complete := tc.RequestStatusComplete
+	req.Status = &complete
+	statusMsg, _ := deliveryServiceRequestStatus{req}.ChangeLogMessage(api.Updated)
+	if err := api.CreateChangeLogRawTx(api.ApiChange, statusMsg, user, tx); err != nil {
+		return tc.DeliveryServiceRequestFulfillment{}, tc.DBError, tc.SystemError
+	}
+
+	queued := []string{}
+	if fulfillReq.QueueUpdates {
+		cdnID := live.CDNID
+		if ds.CDNID != nil {
+			cdnID = *ds.CDNID
+		}
+		if queued, err = server.QueueDeliveryServiceUpdates(tx, xmlID, cdnID, serverIDs, user); err != nil {
+			log.Errorln("fulfilling deliveryservice request: queueing updates: " + err.Error())
+			return tc.DeliveryServiceRequestFulfillment{}, tc.DBError, tc.SystemError
+		}
+	}
+
+	fulfilled := TODeliveryServiceRequest{}
+	if err := tx.QueryRowx(selectDeliveryServiceRequestsQuery()+`WHERE r.id = $1`, id).StructScan(&fulfilled); err != nil {
+		log.Errorln("querying fulfilled deliveryservice request: " + err.Error())
+		return tc.DeliveryServiceRequestFulfillment{}, tc.DBError, tc.SystemError
+	}
+	fulfillment := tc.DeliveryServiceRequestFulfillment{Request: tc.DeliveryServiceRequestNullable(fulfilled), QueuedServers: queued}
+	if *req.ChangeType != tc.RequestChangeTypeDelete {
+		result := tc.DeliveryServiceNullable(ds)
+		fulfillment.DeliveryService = &result
+	}
+	return fulfillment, nil, tc.NoError
+}
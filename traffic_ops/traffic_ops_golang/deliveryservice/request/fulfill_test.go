@@ -0,0 +1,92 @@
+package request
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"testing"
+	"time"
+
+	tc "github.com/apache/incubator-trafficcontrol/lib/go-tc"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+	"github.com/jmoiron/sqlx"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestFulfillRejected(t *testing.T) {
+	tests := []struct {
+		name    string
+		rows    *sqlmock.Rows
+		errType tc.ApiErrorType
+	}{
+		{"missing", sqlmock.NewRows([]string{"id", "status", "change_type"}), tc.DataMissingError},
+		{"submitted", sqlmock.NewRows([]string{"id", "status", "change_type"}).AddRow(1, []byte("submitted"), "update"), tc.DataConflictError},
+		{"complete", sqlmock.NewRows([]string{"id", "status", "change_type"}).AddRow(1, []byte("complete"), "update"), tc.DataConflictError},
+		{"no change type", sqlmock.NewRows([]string{"id", "status"}).AddRow(1, []byte("pending")), tc.DataMissingError},
+	}
+	for _, test := range tests {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		db := sqlx.NewDb(mockDB, "sqlmock")
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(test.rows)
+		tx, err := db.Beginx()
+		if err != nil {
+			t.Fatalf("beginning transaction: %v", err)
+		}
+		if _, err, errType := fulfill(db, tx, 1, tc.DeliveryServiceRequestFulfillReq{}, auth.CurrentUser{}); err == nil || errType != test.errType {
+			t.Errorf("fulfill %s request expected: error type %v, actual: %v %v", test.name, test.errType, errType, err)
+		}
+		db.Close()
+	}
+}
+
+func TestGetLiveDS(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	mock.ExpectBegin()
+	rows := sqlmock.NewRows([]string{"id", "cdn_id", "drifted", "last_updated"}).AddRow(3, 2, true, time.Now())
+	// drift is checked against the delivery service's last_updated recorded when the request was opened or edited, not the request's creation time
+	mock.ExpectQuery(`ds.last_updated > COALESCE\(r.ds_last_updated, r.created_at\)`).WithArgs("ds1", 1).WillReturnRows(rows)
+	mock.ExpectQuery("SELECT").WithArgs("ds2", 1).WillReturnRows(sqlmock.NewRows([]string{"id", "cdn_id", "drifted", "last_updated"}))
+	tx, err := db.Beginx()
+	if err != nil {
+		t.Fatalf("beginning transaction: %v", err)
+	}
+
+	ds, exists, err := getLiveDS(tx, "ds1", 1)
+	if err != nil || !exists {
+		t.Fatalf("getLiveDS expected: existing delivery service, actual: %v %v", exists, err)
+	}
+	if ds.ID != 3 || ds.CDNID != 2 || !ds.Drifted {
+		t.Errorf("getLiveDS expected: id 3 cdn 2 drifted, actual: %+v", ds)
+	}
+	if _, exists, err := getLiveDS(tx, "ds2", 1); err != nil || exists {
+		t.Errorf("getLiveDS expected: no delivery service, actual: %v %v", exists, err)
+	}
+}
@@ -0,0 +1,443 @@
+package policy
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-log"
+	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/dbhelpers"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/tenant"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/tovalidate"
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// scopeConstraint is the unique index permitting one policy per tenant and CDN.
+const scopeConstraint = "deliveryservice_request_approval_policy_scope"
+
+// pqForeignKeyViolation is the Postgres error code of a reference to a row which doesn't exist.
+const pqForeignKeyViolation = "23503"
+
+// we need a type alias to define functions on
+type TOApprovalPolicy tc.DeliveryServiceRequestApprovalPolicyNullable
+
+// the refType is passed into the handlers where a copy of its type is used to decode the json.
+var refType = TOApprovalPolicy(tc.DeliveryServiceRequestApprovalPolicyNullable{})
+
+func GetRefType() *TOApprovalPolicy {
+	return &refType
+}
+
+func (policy TOApprovalPolicy) GetAuditName() string {
+	if policy.ID != nil {
+		return strconv.Itoa(*policy.ID)
+	}
+	return "unknown"
+}
+
+func (policy TOApprovalPolicy) GetKeyFieldsInfo() []api.KeyFieldInfo {
+	return []api.KeyFieldInfo{{"id", api.GetIntKey}}
+}
+
+// Implementation of the Identifier, Validator interface functions
+func (policy TOApprovalPolicy) GetKeys() (map[string]interface{}, bool) {
+	if policy.ID == nil {
+		return map[string]interface{}{"id": 0}, false
+	}
+	return map[string]interface{}{"id": *policy.ID}, true
+}
+
+func (policy *TOApprovalPolicy) SetKeys(keys map[string]interface{}) {
+	i, _ := keys["id"].(int) //this utilizes the non panicking type assertion, if the thrown away ok variable is false i will be the zero of the type, 0 here.
+	policy.ID = &i
+}
+
+func (policy TOApprovalPolicy) GetType() string {
+	return "deliveryservice_request_approval_policy"
+}
+
+func (policy TOApprovalPolicy) Validate(db *sqlx.DB) []error {
+	errs := validation.Errors{
+		"requiredApprovals": validation.Validate(policy.RequiredApprovals, validation.Required, validation.Min(1)),
+	}
+	if len(policy.ApproverIDs) == 0 && len(policy.ApproverRoleIDs) == 0 {
+		errs["approverIds"] = errors.New("must have at least one approver, or approverRoleIds at least one role")
+	}
+	return tovalidate.ToErrors(errs)
+}
+
+// IsTenantAuthorized implements the Tenantable interface to ensure the user is authorized on the tenant of the policy, and of the policy as it currently exists, if it does. Policies without a tenant apply to every tenant, and are only authorized to users of the root tenant.
+func (policy TOApprovalPolicy) IsTenantAuthorized(user auth.CurrentUser, db *sqlx.DB) (bool, error) {
+	if policy.ID != nil && *policy.ID != 0 {
+		var currentTenantID *int
+		err := db.QueryRow(`SELECT tenant_id FROM deliveryservice_request_approval_policy WHERE id = $1`, *policy.ID).Scan(&currentTenantID)
+		if err != nil && err != sql.ErrNoRows {
+			return false, errors.New("querying approval policy tenant: " + err.Error())
+		}
+		if err == nil {
+			authorized, err := isPolicyTenantAuthorized(currentTenantID, user, db)
+			if err != nil || !authorized {
+				return authorized, err
+			}
+		}
+	}
+	return isPolicyTenantAuthorized(policy.TenantID, user, db)
+}
+
+// isPolicyTenantAuthorized returns whether the user may manage policies of the given tenant, or, if it's nil, policies of every tenant.
+func isPolicyTenantAuthorized(tenantID *int, user auth.CurrentUser, db *sqlx.DB) (bool, error) {
+	if tenantID == nil {
+		return tenant.IsRootTenantUser(user, db)
+	}
+	return tenant.IsResourceAuthorizedToUser(*tenantID, user, db)
+}
+
+// parseWriteError returns the error to report for a failed insert or update of a policy.
+func parseWriteError(err error) (error, tc.ApiErrorType) {
+	pqErr, ok := err.(*pq.Error)
+	if !ok {
+		log.Errorf("received non pq error: %++v from approval policy write", err)
+		return tc.DBError, tc.SystemError
+	}
+	if pqErr.Constraint == scopeConstraint {
+		return errors.New("an approval policy for this tenant and cdn already exists"), tc.DataConflictError
+	}
+	if pqErr.Code == pqForeignKeyViolation {
+		switch pqErr.Constraint {
+		case "fk_tm_user":
+			return errors.New("an approver of the approval policy does not exist"), tc.DataConflictError
+		case "fk_role":
+			return errors.New("an approver role of the approval policy does not exist"), tc.DataConflictError
+		}
+		return errors.New("the tenant or cdn of the approval policy does not exist"), tc.DataConflictError
+	}
+	return dbhelpers.ParsePQUniqueConstraintError(pqErr)
+}
+
+// The TOApprovalPolicy implementation of the Creator interface
+// all implementations of Creator should use transactions and return the proper errorType
+// The insert sql returns the id and lastUpdated values of the newly inserted policy and have
+// to be added to the struct
+func (policy *TOApprovalPolicy) Create(db *sqlx.DB, user auth.CurrentUser) (error, tc.ApiErrorType) {
+	rollbackTransaction := true
+	tx, err := db.Beginx()
+	defer func() {
+		if tx == nil || !rollbackTransaction {
+			return
+		}
+		err := tx.Rollback()
+		if err != nil {
+			log.Errorln(errors.New("rolling back transaction: " + err.Error()))
+		}
+	}()
+
+	if err != nil {
+		log.Error.Printf("could not begin transaction: %v", err)
+		return tc.DBError, tc.SystemError
+	}
+	resultRows, err := tx.NamedQuery(insertQuery(), policy)
+	if err != nil {
+		return parseWriteError(err)
+	}
+	defer resultRows.Close()
+
+	var id int
+	var lastUpdated tc.TimeNoMod
+	rowsAffected := 0
+	for resultRows.Next() {
+		rowsAffected++
+		if err := resultRows.Scan(&id, &lastUpdated); err != nil {
+			log.Error.Printf("could not scan id from insert: %s\n", err)
+			return tc.DBError, tc.SystemError
+		}
+	}
+	if rowsAffected == 0 {
+		err = errors.New("no approval policy was inserted, no id was returned")
+		log.Errorln(err)
+		return tc.DBError, tc.SystemError
+	} else if rowsAffected > 1 {
+		err = errors.New("too many ids returned from approval policy insert")
+		log.Errorln(err)
+		return tc.DBError, tc.SystemError
+	}
+	policy.SetKeys(map[string]interface{}{"id": id})
+	policy.LastUpdated = &lastUpdated
+	if err := setApprovers(tx, *policy); err != nil {
+		return parseWriteError(err)
+	}
+	err = tx.Commit()
+	if err != nil {
+		log.Errorln("Could not commit transaction: ", err)
+		return tc.DBError, tc.SystemError
+	}
+	rollbackTransaction = false
+	return nil, tc.NoError
+}
+
+func (policy *TOApprovalPolicy) Read(db *sqlx.DB, parameters map[string]string, user auth.CurrentUser) ([]interface{}, []error, tc.ApiErrorType) {
+	policies, errs, errType := getPolicies(parameters, user, db)
+	if len(errs) > 0 {
+		return nil, errs, errType
+	}
+	iPolicies := []interface{}{}
+	for _, p := range policies {
+		iPolicies = append(iPolicies, p)
+	}
+	return iPolicies, []error{}, tc.NoError
+}
+
+func getPolicies(parameters map[string]string, user auth.CurrentUser, db *sqlx.DB) ([]tc.DeliveryServiceRequestApprovalPolicy, []error, tc.ApiErrorType) {
+	// Query Parameters to Database Query column mappings
+	// see the fields mapped in the SQL query
+	queryParamsToQueryCols := map[string]dbhelpers.WhereColumnInfo{
+		"id":          dbhelpers.WhereColumnInfo{"p.id", api.IsInt},
+		"tenantId":    dbhelpers.WhereColumnInfo{"p.tenant_id", api.IsInt},
+		"cdnId":       dbhelpers.WhereColumnInfo{"p.cdn_id", api.IsInt},
+		"lastUpdated": dbhelpers.WhereColumnInfo{"p.last_updated", nil},
+	}
+	where, orderBy, queryValues, errs := dbhelpers.BuildWhereAndOrderBy(parameters, queryParamsToQueryCols)
+	if len(errs) > 0 {
+		return nil, errs, tc.DataConflictError
+	}
+
+	where = tenant.AddTenancyCheck(where, queryValues, "p.tenant_id", user)
+
+	query := selectQuery() + where + orderBy
+	log.Debugln("Query is ", query)
+
+	rows, err := db.NamedQuery(query, queryValues)
+	if err != nil {
+		log.Errorf("Error querying approval policies: %v", err)
+		return nil, []error{tc.DBError}, tc.SystemError
+	}
+	defer rows.Close()
+
+	policies := []tc.DeliveryServiceRequestApprovalPolicy{}
+	for rows.Next() {
+		var p struct {
+			tc.DeliveryServiceRequestApprovalPolicy
+			ApproverIDs     pq.Int64Array `db:"approver_ids"`
+			ApproverRoleIDs pq.Int64Array `db:"approver_role_ids"`
+		}
+		if err = rows.StructScan(&p); err != nil {
+			log.Errorf("error parsing approval policy rows: %v", err)
+			return nil, []error{tc.DBError}, tc.SystemError
+		}
+		p.DeliveryServiceRequestApprovalPolicy.ApproverIDs = intsFromInt64s(p.ApproverIDs)
+		p.DeliveryServiceRequestApprovalPolicy.ApproverRoleIDs = intsFromInt64s(p.ApproverRoleIDs)
+		policies = append(policies, p.DeliveryServiceRequestApprovalPolicy)
+	}
+	return policies, nil, tc.NoError
+}
+
+func intsFromInt64s(int64s []int64) []int {
+	ints := make([]int, 0, len(int64s))
+	for _, i := range int64s {
+		ints = append(ints, int(i))
+	}
+	return ints
+}
+
+// The TOApprovalPolicy implementation of the Updater interface
+// all implementations of Updater should use transactions and return the proper errorType
+func (policy *TOApprovalPolicy) Update(db *sqlx.DB, user auth.CurrentUser) (error, tc.ApiErrorType) {
+	rollbackTransaction := true
+	tx, err := db.Beginx()
+	defer func() {
+		if tx == nil || !rollbackTransaction {
+			return
+		}
+		err := tx.Rollback()
+		if err != nil {
+			log.Errorln(errors.New("rolling back transaction: " + err.Error()))
+		}
+	}()
+
+	if err != nil {
+		log.Error.Printf("could not begin transaction: %v", err)
+		return tc.DBError, tc.SystemError
+	}
+	if err, errType := policy.UpdateTx(tx, user); err != nil {
+		return err, errType
+	}
+	err = tx.Commit()
+	if err != nil {
+		log.Errorln("Could not commit transaction: ", err)
+		return tc.DBError, tc.SystemError
+	}
+	rollbackTransaction = false
+	return nil, tc.NoError
+}
+
+// UpdateTx updates the approval policy in the given transaction, which the caller commits or rolls back.
+func (policy *TOApprovalPolicy) UpdateTx(tx *sqlx.Tx, user auth.CurrentUser) (error, tc.ApiErrorType) {
+	log.Debugf("about to run exec query: %s with approval policy: %++v", updateQuery(), policy)
+	resultRows, err := tx.NamedQuery(updateQuery(), policy)
+	if err != nil {
+		return parseWriteError(err)
+	}
+	defer resultRows.Close()
+
+	var lastUpdated tc.TimeNoMod
+	rowsAffected := 0
+	for resultRows.Next() {
+		rowsAffected++
+		if err := resultRows.Scan(&lastUpdated); err != nil {
+			log.Error.Printf("could not scan lastUpdated from insert: %s\n", err)
+			return tc.DBError, tc.SystemError
+		}
+	}
+	log.Debugf("lastUpdated: %++v", lastUpdated)
+	policy.LastUpdated = &lastUpdated
+	if rowsAffected != 1 {
+		if rowsAffected < 1 {
+			return errors.New("no approval policy found with this id"), tc.DataMissingError
+		} else {
+			return fmt.Errorf("this update affected too many rows: %d", rowsAffected), tc.SystemError
+		}
+	}
+	if err := setApprovers(tx, *policy); err != nil {
+		return parseWriteError(err)
+	}
+	return nil, tc.NoError
+}
+
+// setApprovers replaces the approvers of the policy with its ApproverIDs users and ApproverRoleIDs roles.
+func setApprovers(tx *sqlx.Tx, policy TOApprovalPolicy) error {
+	if _, err := tx.Exec(`DELETE FROM deliveryservice_request_approval_policy_user WHERE policy_id = $1`, *policy.ID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`INSERT INTO deliveryservice_request_approval_policy_user (policy_id, tm_user_id) SELECT DISTINCT $1::bigint, unnest($2::bigint[])`, *policy.ID, pq.Array(policy.ApproverIDs)); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM deliveryservice_request_approval_policy_role WHERE policy_id = $1`, *policy.ID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`INSERT INTO deliveryservice_request_approval_policy_role (policy_id, role_id) SELECT DISTINCT $1::bigint, unnest($2::bigint[])`, *policy.ID, pq.Array(policy.ApproverRoleIDs)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// The TOApprovalPolicy implementation of the Deleter interface
+// all implementations of Deleter should use transactions and return the proper errorType
+func (policy *TOApprovalPolicy) Delete(db *sqlx.DB, user auth.CurrentUser) (error, tc.ApiErrorType) {
+	rollbackTransaction := true
+	tx, err := db.Beginx()
+	defer func() {
+		if tx == nil || !rollbackTransaction {
+			return
+		}
+		err := tx.Rollback()
+		if err != nil {
+			log.Errorln(errors.New("rolling back transaction: " + err.Error()))
+		}
+	}()
+
+	if err != nil {
+		log.Error.Printf("could not begin transaction: %v", err)
+		return tc.DBError, tc.SystemError
+	}
+	if err, errType := policy.DeleteTx(tx, user); err != nil {
+		return err, errType
+	}
+	err = tx.Commit()
+	if err != nil {
+		log.Errorln("Could not commit transaction: ", err)
+		return tc.DBError, tc.SystemError
+	}
+	rollbackTransaction = false
+	return nil, tc.NoError
+}
+
+// DeleteTx deletes the approval policy in the given transaction, which the caller commits or rolls back.
+func (policy *TOApprovalPolicy) DeleteTx(tx *sqlx.Tx, user auth.CurrentUser) (error, tc.ApiErrorType) {
+	log.Debugf("about to run exec query: %s with approval policy: %++v", deleteQuery(), policy)
+	result, err := tx.NamedExec(deleteQuery(), policy)
+	if err != nil {
+		log.Errorf("received error: %++v from delete execution", err)
+		return tc.DBError, tc.SystemError
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return tc.DBError, tc.SystemError
+	}
+	if rowsAffected != 1 {
+		if rowsAffected < 1 {
+			return errors.New("no approval policy with that id found"), tc.DataMissingError
+		} else {
+			return fmt.Errorf("this delete affected too many rows: %d", rowsAffected), tc.SystemError
+		}
+	}
+	return nil, tc.NoError
+}
+
+func insertQuery() string {
+	query := `INSERT INTO deliveryservice_request_approval_policy (
+tenant_id,
+cdn_id,
+required_approvals) VALUES (
+:tenant_id,
+:cdn_id,
+:required_approvals) RETURNING id,last_updated`
+	return query
+}
+
+func selectQuery() string {
+	query := `SELECT
+p.cdn_id,
+c.name AS cdn_name,
+p.id,
+p.last_updated,
+p.required_approvals,
+p.tenant_id,
+t.name AS tenant,
+ARRAY(SELECT u.tm_user_id FROM deliveryservice_request_approval_policy_user u WHERE u.policy_id = p.id ORDER BY u.tm_user_id) AS approver_ids,
+ARRAY(SELECT r.role_id FROM deliveryservice_request_approval_policy_role r WHERE r.policy_id = p.id ORDER BY r.role_id) AS approver_role_ids
+FROM deliveryservice_request_approval_policy p
+LEFT JOIN cdn c ON p.cdn_id = c.id
+LEFT JOIN tenant t ON p.tenant_id = t.id
+`
+	return query
+}
+
+func updateQuery() string {
+	query := `UPDATE
+deliveryservice_request_approval_policy SET
+tenant_id=:tenant_id,
+cdn_id=:cdn_id,
+required_approvals=:required_approvals
+WHERE id=:id RETURNING last_updated`
+	return query
+}
+
+func deleteQuery() string {
+	query := `DELETE FROM deliveryservice_request_approval_policy
+WHERE id=:id`
+	return query
+}
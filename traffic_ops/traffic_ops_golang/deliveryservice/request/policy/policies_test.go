@@ -0,0 +1,211 @@
+package policy
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/test"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestGetPolicies(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	cols := []string{"cdn_id", "cdn_name", "id", "last_updated", "required_approvals", "tenant_id", "tenant", "approver_ids", "approver_role_ids"}
+	rows := sqlmock.NewRows(cols).
+		AddRow(2, "cdn2", 1, time.Now(), 2, nil, nil, []byte("{4,5}"), []byte("{}")).
+		AddRow(nil, nil, 2, time.Now(), 1, 3, "tenant3", []byte("{}"), []byte("{6}"))
+	mock.ExpectQuery("SELECT").WithArgs(4).WillReturnRows(rows)
+
+	policies, errs, errType := getPolicies(map[string]string{}, auth.CurrentUser{TenantID: 4}, db)
+	if len(errs) > 0 {
+		t.Fatalf("getPolicies expected: no errors, actual: %v with error type: %s", errs, errType.String())
+	}
+	if len(policies) != 2 {
+		t.Fatalf("getPolicies expected: 2 policies, actual: %v", len(policies))
+	}
+	if policies[0].CDNID == nil || *policies[0].CDNID != 2 || policies[0].TenantID != nil || policies[0].RequiredApprovals != 2 {
+		t.Errorf("getPolicies expected: cdn 2 policy requiring 2 approvals, actual: %+v", policies[0])
+	}
+	if policies[1].TenantID == nil || *policies[1].TenantID != 3 || policies[1].CDNID != nil {
+		t.Errorf("getPolicies expected: tenant 3 policy, actual: %+v", policies[1])
+	}
+	if !reflect.DeepEqual(policies[0].ApproverIDs, []int{4, 5}) || !reflect.DeepEqual(policies[0].ApproverRoleIDs, []int{}) {
+		t.Errorf("getPolicies expected: approvers 4 and 5, actual: %+v", policies[0])
+	}
+	if !reflect.DeepEqual(policies[1].ApproverIDs, []int{}) || !reflect.DeepEqual(policies[1].ApproverRoleIDs, []int{6}) {
+		t.Errorf("getPolicies expected: approver role 6, actual: %+v", policies[1])
+	}
+}
+
+func TestInterfaces(t *testing.T) {
+	var i interface{}
+	i = &TOApprovalPolicy{}
+
+	if _, ok := i.(api.Creator); !ok {
+		t.Errorf("approval policy must be creator")
+	}
+	if _, ok := i.(api.Reader); !ok {
+		t.Errorf("approval policy must be reader")
+	}
+	if _, ok := i.(api.Updater); !ok {
+		t.Errorf("approval policy must be updater")
+	}
+	if _, ok := i.(api.Deleter); !ok {
+		t.Errorf("approval policy must be deleter")
+	}
+	if _, ok := i.(api.Identifier); !ok {
+		t.Errorf("approval policy must be Identifier")
+	}
+	if _, ok := i.(api.Tenantable); !ok {
+		t.Errorf("approval policy must be Tenantable")
+	}
+}
+
+func TestValidation(t *testing.T) {
+	policy := TOApprovalPolicy{}
+	errs := test.SortErrors(policy.Validate(nil))
+	expected := []error{
+		errors.New(`'approverIds' must have at least one approver, or approverRoleIds at least one role`),
+		errors.New(`'requiredApprovals' cannot be blank`),
+	}
+	if !reflect.DeepEqual(expected, errs) {
+		t.Errorf(`expected %v, got %v`, expected, errs)
+	}
+
+	required := -1
+	policy.RequiredApprovals = &required
+	policy.ApproverIDs = []int{4}
+	errs = test.SortErrors(policy.Validate(nil))
+	expected = []error{errors.New(`'requiredApprovals' must be no less than 1`)}
+	if !reflect.DeepEqual(expected, errs) {
+		t.Errorf(`expected %v, got %v`, expected, errs)
+	}
+
+	required = 2
+	if errs = policy.Validate(nil); len(errs) != 0 {
+		t.Errorf(`expected no errors, got %v`, errs)
+	}
+
+	policy.ApproverIDs = nil
+	policy.ApproverRoleIDs = []int{6}
+	if errs = policy.Validate(nil); len(errs) != 0 {
+		t.Errorf(`expected no errors with approver roles, got %v`, errs)
+	}
+}
+
+func TestUpdateSetsApprovers(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	id := 1
+	required := 2
+	policy := TOApprovalPolicy{ID: &id, RequiredApprovals: &required, ApproverIDs: []int{4, 5}, ApproverRoleIDs: []int{6}}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE").WillReturnRows(sqlmock.NewRows([]string{"last_updated"}).AddRow(time.Now()))
+	mock.ExpectExec("DELETE FROM deliveryservice_request_approval_policy_user").WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO deliveryservice_request_approval_policy_user").WithArgs(1, "{4,5}").WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("DELETE FROM deliveryservice_request_approval_policy_role").WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO deliveryservice_request_approval_policy_role").WithArgs(1, "{6}").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	if err, errType := policy.Update(db, auth.CurrentUser{}); err != nil {
+		t.Fatalf("Update expected: no error, actual: %v %v", errType, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expected the approvers to be replaced: %v", err)
+	}
+}
+
+func TestIsTenantAuthorizedGlobalPolicy(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	//verifies policies without a tenant, new or existing, are only authorized to root tenant users
+	user := auth.CurrentUser{TenantID: 2}
+	mock.ExpectQuery("use_tenancy").WillReturnRows(sqlmock.NewRows([]string{"value"}).AddRow(true))
+	mock.ExpectQuery("parent_id IS NULL").WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	if authorized, err := (TOApprovalPolicy{}).IsTenantAuthorized(user, db); err != nil || authorized {
+		t.Errorf("IsTenantAuthorized of a global policy by a non-root user expected: false, actual: %v %v", authorized, err)
+	}
+
+	id := 1
+	tenantID := 2
+	mock.ExpectQuery("SELECT tenant_id").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"tenant_id"}).AddRow(nil))
+	mock.ExpectQuery("use_tenancy").WillReturnRows(sqlmock.NewRows([]string{"value"}).AddRow(true))
+	mock.ExpectQuery("parent_id IS NULL").WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	if authorized, err := (TOApprovalPolicy{ID: &id, TenantID: &tenantID}).IsTenantAuthorized(user, db); err != nil || authorized {
+		t.Errorf("IsTenantAuthorized of moving a global policy to a tenant by a non-root user expected: false, actual: %v %v", authorized, err)
+	}
+
+	mock.ExpectQuery("use_tenancy").WillReturnRows(sqlmock.NewRows([]string{"value"}).AddRow(true))
+	mock.ExpectQuery("parent_id IS NULL").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	if authorized, err := (TOApprovalPolicy{}).IsTenantAuthorized(auth.CurrentUser{TenantID: 1}, db); err != nil || !authorized {
+		t.Errorf("IsTenantAuthorized of a global policy by a root user expected: true, actual: %v %v", authorized, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expected the user's tenant to be checked: %v", err)
+	}
+}
+
+func TestParseWriteError(t *testing.T) {
+	tests := []struct {
+		err     error
+		errType tc.ApiErrorType
+	}{
+		{&pq.Error{Constraint: scopeConstraint, Detail: "Key (COALESCE(tenant_id, 0::bigint), COALESCE(cdn_id, 0::bigint))=(3, 0) already exists."}, tc.DataConflictError},
+		{&pq.Error{Code: pqForeignKeyViolation, Constraint: "fk_cdn"}, tc.DataConflictError},
+		{&pq.Error{Code: pqForeignKeyViolation, Constraint: "fk_tm_user"}, tc.DataConflictError},
+		{&pq.Error{Code: pqForeignKeyViolation, Constraint: "fk_role"}, tc.DataConflictError},
+		{errors.New("connection reset"), tc.SystemError},
+	}
+	for _, test := range tests {
+		if _, errType := parseWriteError(test.err); errType != test.errType {
+			t.Errorf("parseWriteError(%v) expected: %v, actual: %v", test.err, test.errType, errType)
+		}
+	}
+}
@@ -20,6 +20,7 @@ package request
  */
 
 import (
+	"database/sql"
 	"errors"
 	"fmt"
 	"strconv"
@@ -76,14 +77,15 @@ func (req TODeliveryServiceRequest) GetType() string {
 // Read implements the api.Reader interface
 func (req *TODeliveryServiceRequest) Read(db *sqlx.DB, parameters map[string]string, user auth.CurrentUser) ([]interface{}, []error, tc.ApiErrorType) {
 	queryParamsToQueryCols := map[string]dbhelpers.WhereColumnInfo{
-		"assignee":   dbhelpers.WhereColumnInfo{Column: "s.username"},
-		"assigneeId": dbhelpers.WhereColumnInfo{Column: "r.assignee_id", Checker: api.IsInt},
-		"author":     dbhelpers.WhereColumnInfo{Column: "a.username"},
-		"authorId":   dbhelpers.WhereColumnInfo{Column: "r.author_id", Checker: api.IsInt},
-		"changeType": dbhelpers.WhereColumnInfo{Column: "r.change_type"},
-		"id":         dbhelpers.WhereColumnInfo{Column: "r.id", Checker: api.IsInt},
-		"status":     dbhelpers.WhereColumnInfo{Column: "r.status"},
-		"xmlId":      dbhelpers.WhereColumnInfo{Column: "r.deliveryservice->>'xmlId'"},
+		"assignee":    dbhelpers.WhereColumnInfo{Column: "s.username"},
+		"assigneeId":  dbhelpers.WhereColumnInfo{Column: "r.assignee_id", Checker: api.IsInt},
+		"author":      dbhelpers.WhereColumnInfo{Column: "a.username"},
+		"authorId":    dbhelpers.WhereColumnInfo{Column: "r.author_id", Checker: api.IsInt},
+		"changeType":  dbhelpers.WhereColumnInfo{Column: "r.change_type"},
+		"id":          dbhelpers.WhereColumnInfo{Column: "r.id", Checker: api.IsInt},
+		"status":      dbhelpers.WhereColumnInfo{Column: "r.status"},
+		"xmlId":       dbhelpers.WhereColumnInfo{Column: "r.deliveryservice->>'xmlId'"},
+		"lastUpdated": dbhelpers.WhereColumnInfo{Column: "r.last_updated"},
 	}
 
 	p := parameters
@@ -101,6 +103,8 @@ func (req *TODeliveryServiceRequest) Read(db *sqlx.DB, parameters map[string]str
 		return nil, errs, tc.DataConflictError
 	}
 
+	where = tenant.AddTenancyCheck(where, queryValues, requestTenantIDCol, user)
+
 	query := selectDeliveryServiceRequestsQuery() + where + orderBy
 	log.Debugln("Query is ", query)
 
@@ -118,21 +122,15 @@ func (req *TODeliveryServiceRequest) Read(db *sqlx.DB, parameters map[string]str
 			log.Errorf("error parsing DeliveryServiceRequest rows: %v", err)
 			return nil, []error{tc.DBError}, tc.SystemError
 		}
-
-		// TODO: combine tenancy with the query above so there's a single db call
-		t, err := s.IsTenantAuthorized(user, db)
-		if err != nil {
-			log.Errorf("error checking tenancy: %v", err)
-			return nil, []error{tc.DBError}, tc.SystemError
-		}
-		if t {
-			deliveryServiceRequests = append(deliveryServiceRequests, s)
-		}
+		deliveryServiceRequests = append(deliveryServiceRequests, s)
 	}
 
 	return deliveryServiceRequests, []error{}, tc.NoError
 }
 
+// requestTenantIDCol is the tenant of the deliveryservice of the request aliased "r", used to filter requests by tenancy.
+const requestTenantIDCol = "CAST(r.deliveryservice->>'tenantId' AS bigint)"
+
 func selectDeliveryServiceRequestsQuery() string {
 
 	query := `SELECT
@@ -148,6 +146,7 @@ r.last_edited_by_id,
 r.last_updated,
 r.deliveryservice,
 r.status,
+r.change_log_id,
 r.deliveryservice->>'xmlId' as xml_id
 
 FROM deliveryservice_request r
@@ -158,8 +157,15 @@ LEFT OUTER JOIN tm_user e ON r.last_edited_by_id = e.id
 	return query
 }
 
-// IsTenantAuthorized implements the Tenantable interface to ensure the user is authorized on the deliveryservice tenant
+// IsTenantAuthorized implements the Tenantable interface to ensure the user is authorized on the deliveryservice tenant.
+// If the request already exists, the user must also be authorized on the tenant of its current deliveryservice, so requests can't be deleted, assigned or moved out of another tenant.
 func (req TODeliveryServiceRequest) IsTenantAuthorized(user auth.CurrentUser, db *sqlx.DB) (bool, error) {
+	if req.ID != nil && *req.ID != 0 {
+		authorized, err := IsRequestTenantAuthorized(*req.ID, user, db)
+		if err != nil || !authorized {
+			return authorized, err
+		}
+	}
 	ds := req.DeliveryService
 	if ds == nil {
 		// No deliveryservice applied yet -- wide open
@@ -172,18 +178,62 @@ func (req TODeliveryServiceRequest) IsTenantAuthorized(user auth.CurrentUser, db
 	return tenant.IsResourceAuthorizedToUser(*ds.TenantID, user, db)
 }
 
+// IsRequestTenantAuthorized returns whether the user is authorized on the tenant of the deliveryservice of the existing request with the given ID.
+// Requests which don't exist, or whose deliveryservice has no tenant, are authorized, leaving the caller to handle missing requests.
+func IsRequestTenantAuthorized(id int, user auth.CurrentUser, db *sqlx.DB) (bool, error) {
+	var tenantID *int
+	err := db.QueryRow(`SELECT (deliveryservice->>'tenantId')::bigint FROM deliveryservice_request WHERE id = $1`, id).Scan(&tenantID)
+	if err == sql.ErrNoRows || (err == nil && tenantID == nil) {
+		return true, nil
+	}
+	if err != nil {
+		return false, errors.New("querying deliveryservice request tenant: " + err.Error())
+	}
+	return tenant.IsResourceAuthorizedToUser(*tenantID, user, db)
+}
+
 // Update implements the tc.Updater interface.
 //all implementations of Updater should use transactions and return the proper errorType
 //ParsePQUniqueConstraintError is used to determine if a request with conflicting values exists
 //if so, it will return an errorType of DataConflict and the type should be appended to the
 //generic error message returned
 func (req *TODeliveryServiceRequest) Update(db *sqlx.DB, user auth.CurrentUser) (error, tc.ApiErrorType) {
+	rollbackTransaction := true
+	tx, err := db.Beginx()
+	defer func() {
+		if tx == nil || !rollbackTransaction {
+			return
+		}
+		err := tx.Rollback()
+		if err != nil {
+			log.Errorln(errors.New("rolling back transaction: " + err.Error()))
+		}
+	}()
+
+	if err != nil {
+		log.Error.Println("could not begin transaction: ", err.Error())
+		return err, tc.SystemError
+	}
+	if err, errType := req.UpdateTx(tx, user); err != nil {
+		return err, errType
+	}
+	err = tx.Commit()
+	if err != nil {
+		log.Errorln("Could not commit transaction: ", err)
+		return tc.DBError, tc.SystemError
+	}
+	rollbackTransaction = false
+	return nil, tc.NoError
+}
+
+// UpdateTx updates the delivery service request in the given transaction, which the caller commits or rolls back.
+func (req *TODeliveryServiceRequest) UpdateTx(tx *sqlx.Tx, user auth.CurrentUser) (error, tc.ApiErrorType) {
 	var current TODeliveryServiceRequest
 	if req.ID == nil {
 		log.Errorf("error updating DeliveryServiceRequest: ID is nil")
 		return errors.New("error updating DeliveryServiceRequest: ID is nil"), tc.DataMissingError
 	}
-	err := db.QueryRowx(selectDeliveryServiceRequestsQuery() + `WHERE r.id=` + strconv.Itoa(*req.ID)).StructScan(&current)
+	err := tx.QueryRowx(selectDeliveryServiceRequestsQuery() + `WHERE r.id=` + strconv.Itoa(*req.ID)).StructScan(&current)
 	if err != nil {
 		log.Errorf("Error querying DeliveryServiceRequests: %v", err)
 		return err, tc.SystemError
@@ -205,23 +255,6 @@ func (req *TODeliveryServiceRequest) Update(db *sqlx.DB, user auth.CurrentUser)
 			tc.DataConflictError
 	}
 
-	rollbackTransaction := true
-	tx, err := db.Beginx()
-	defer func() {
-		if tx == nil || !rollbackTransaction {
-			return
-		}
-		err := tx.Rollback()
-		if err != nil {
-			log.Errorln(errors.New("rolling back transaction: " + err.Error()))
-		}
-	}()
-
-	if err != nil {
-		log.Error.Println("could not begin transaction: ", err.Error())
-		return err, tc.SystemError
-	}
-
 	userID := tc.IDNoMod(user.ID)
 	req.LastEditedByID = &userID
 	resultRows, err := tx.NamedQuery(updateRequestQuery(), req)
@@ -257,12 +290,6 @@ func (req *TODeliveryServiceRequest) Update(db *sqlx.DB, user auth.CurrentUser)
 		return fmt.Errorf("this update affected too many rows: %d", rowsAffected), tc.SystemError
 	}
 
-	err = tx.Commit()
-	if err != nil {
-		log.Errorln("Could not commit transaction: ", err)
-		return tc.DBError, tc.SystemError
-	}
-	rollbackTransaction = false
 	return nil, tc.NoError
 }
 
@@ -373,21 +400,6 @@ func (req *TODeliveryServiceRequest) Create(db *sqlx.DB, user auth.CurrentUser)
 
 // Delete removes the request from the db
 func (req *TODeliveryServiceRequest) Delete(db *sqlx.DB, user auth.CurrentUser) (error, tc.ApiErrorType) {
-	var st tc.RequestStatus
-	log.Debugln("DELETING REQUEST WITH ID ", strconv.Itoa(*req.ID))
-	if req.ID == nil {
-		return errors.New("cannot delete deliveryservice_request -- ID is nil"), tc.SystemError
-	}
-
-	err := db.QueryRow(`SELECT status FROM deliveryservice_request WHERE id=` + strconv.Itoa(*req.ID)).Scan(&st)
-	if err != nil {
-		return err, tc.SystemError
-	}
-
-	if st == tc.RequestStatusComplete || st == tc.RequestStatusPending || st == tc.RequestStatusRejected {
-		return fmt.Errorf("cannot delete a deliveryservice_request with state %s", string(st)), tc.DataConflictError
-	}
-
 	rollbackTransaction := true
 	tx, err := db.Beginx()
 	defer func() {
@@ -404,6 +416,36 @@ func (req *TODeliveryServiceRequest) Delete(db *sqlx.DB, user auth.CurrentUser)
 		log.Error.Println("could not begin transaction: ", err.Error())
 		return tc.DBError, tc.SystemError
 	}
+	if err, errType := req.DeleteTx(tx, user); err != nil {
+		return err, errType
+	}
+	err = tx.Commit()
+	if err != nil {
+		log.Errorln("Could not commit transaction: ", err)
+		return tc.DBError, tc.SystemError
+	}
+	// success!
+	rollbackTransaction = false
+	return nil, tc.NoError
+}
+
+// DeleteTx deletes the delivery service request in the given transaction, which the caller commits or rolls back.
+func (req *TODeliveryServiceRequest) DeleteTx(tx *sqlx.Tx, user auth.CurrentUser) (error, tc.ApiErrorType) {
+	var st tc.RequestStatus
+	if req.ID == nil {
+		return errors.New("cannot delete deliveryservice_request -- ID is nil"), tc.SystemError
+	}
+	log.Debugln("DELETING REQUEST WITH ID ", strconv.Itoa(*req.ID))
+
+	err := tx.QueryRow(`SELECT status FROM deliveryservice_request WHERE id=` + strconv.Itoa(*req.ID)).Scan(&st)
+	if err != nil {
+		return err, tc.SystemError
+	}
+
+	if st == tc.RequestStatusComplete || st == tc.RequestStatusPending || st == tc.RequestStatusRejected {
+		return fmt.Errorf("cannot delete a deliveryservice_request with state %s", string(st)), tc.DataConflictError
+	}
+
 	query := `DELETE FROM deliveryservice_request WHERE id=` + strconv.Itoa(*req.ID)
 	log.Debugf("about to run exec query: %s", query)
 
@@ -425,13 +467,6 @@ func (req *TODeliveryServiceRequest) Delete(db *sqlx.DB, user auth.CurrentUser)
 		log.Errorln("the delete affected too many rows")
 		return fmt.Errorf("this delete affected too many rows: %d", rowsAffected), tc.SystemError
 	}
-	err = tx.Commit()
-	if err != nil {
-		log.Errorln("Could not commit transaction: ", err)
-		return tc.DBError, tc.SystemError
-	}
-	// success!
-	rollbackTransaction = false
 	return nil, tc.NoError
 }
 
@@ -520,6 +555,36 @@ func GetAssignRefType() *deliveryServiceRequestAssignment {
 
 // Update assignee only
 func (req *deliveryServiceRequestAssignment) Update(db *sqlx.DB, user auth.CurrentUser) (error, tc.ApiErrorType) {
+	rollbackTransaction := true
+	tx, err := db.Beginx()
+	defer func() {
+		if tx == nil || !rollbackTransaction {
+			return
+		}
+		err := tx.Rollback()
+		if err != nil {
+			log.Errorln(errors.New("rolling back transaction: " + err.Error()))
+		}
+	}()
+
+	if err != nil {
+		log.Error.Println("could not begin transaction: ", err.Error())
+		return err, tc.SystemError
+	}
+	if err, errType := req.UpdateTx(tx, user); err != nil {
+		return err, errType
+	}
+	err = tx.Commit()
+	if err != nil {
+		log.Errorln("Could not commit transaction: ", err)
+		return tc.DBError, tc.SystemError
+	}
+	rollbackTransaction = false
+	return nil, tc.NoError
+}
+
+// UpdateTx changes only the assignee of the request, in the given transaction, which the caller commits or rolls back. It overrides the UpdateTx of the embedded request, so conditional requests don't update the rest of the request.
+func (req *deliveryServiceRequestAssignment) UpdateTx(tx *sqlx.Tx, user auth.CurrentUser) (error, tc.ApiErrorType) {
 	// req represents the state the deliveryservice_request is to transition to
 	// we want to limit what changes here -- only assignee can change
 	if req.ID == nil {
@@ -528,10 +593,13 @@ func (req *deliveryServiceRequestAssignment) Update(db *sqlx.DB, user auth.Curre
 
 	// get original
 	var current TODeliveryServiceRequest
-	err := db.QueryRowx(selectDeliveryServiceRequestsQuery() + `WHERE r.id=` + strconv.Itoa(*req.ID)).StructScan(&current)
+	err := tx.QueryRowx(selectDeliveryServiceRequestsQuery()+`WHERE r.id = $1 FOR UPDATE OF r`, *req.ID).StructScan(&current)
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return errors.New("no deliveryservice request found with this id"), tc.DataMissingError
+		}
 		log.Errorf("Error querying DeliveryServiceRequests: %v", err)
-		return err, tc.SystemError
+		return tc.DBError, tc.SystemError
 	}
 
 	// unchanged (maybe both nil)
@@ -545,30 +613,8 @@ func (req *deliveryServiceRequestAssignment) Update(db *sqlx.DB, user auth.Curre
 	*req = deliveryServiceRequestAssignment{current}
 	req.AssigneeID = assigneeID
 
-	rollbackTransaction := true
-	tx, err := db.Beginx()
-	defer func() {
-		if tx == nil || !rollbackTransaction {
-			return
-		}
-		err := tx.Rollback()
-		if err != nil {
-			log.Errorln(errors.New("rolling back transaction: " + err.Error()))
-		}
-	}()
-
-	if err != nil {
-		log.Error.Println("could not begin transaction: ", err.Error())
-		return err, tc.SystemError
-	}
-
 	// LastEditedBy field should not change with status update
-	v := "null"
-	if req.AssigneeID != nil {
-		v = strconv.Itoa(*req.AssigneeID)
-	}
-	query := `UPDATE deliveryservice_request SET assignee_id = ` + v + ` WHERE id=` + strconv.Itoa(*req.ID)
-	_, err = tx.Exec(query)
+	_, err = tx.Exec(`UPDATE deliveryservice_request SET assignee_id = $1 WHERE id = $2`, req.AssigneeID, *req.ID)
 	if err != nil {
 		if pqErr, ok := err.(*pq.Error); ok {
 			err, eType := dbhelpers.ParsePQUniqueConstraintError(pqErr)
@@ -581,20 +627,12 @@ func (req *deliveryServiceRequestAssignment) Update(db *sqlx.DB, user auth.Curre
 		return tc.DBError, tc.SystemError
 	}
 
-	err = tx.Commit()
-	if err != nil {
-		log.Errorln("Could not commit transaction: ", err)
-		return tc.DBError, tc.SystemError
-	}
-
 	// update req with current info
-	err = db.QueryRowx(selectDeliveryServiceRequestsQuery() + ` WHERE r.id=` + strconv.Itoa(*req.ID)).StructScan(req)
+	err = tx.QueryRowx(selectDeliveryServiceRequestsQuery()+`WHERE r.id = $1`, *req.ID).StructScan(req)
 	if err != nil {
 		log.Errorf("Error querying DeliveryServiceRequests: %v", err)
-		return err, tc.SystemError
+		return tc.DBError, tc.SystemError
 	}
-
-	rollbackTransaction = false
 	return nil, tc.NoError
 }
 
@@ -628,50 +666,80 @@ func GetStatusRefType() *deliveryServiceRequestStatus {
 
 // Update status only
 func (req *deliveryServiceRequestStatus) Update(db *sqlx.DB, user auth.CurrentUser) (error, tc.ApiErrorType) {
+	rollbackTransaction := true
+	tx, err := db.Beginx()
+	defer func() {
+		if tx == nil || !rollbackTransaction {
+			return
+		}
+		err := tx.Rollback()
+		if err != nil {
+			log.Errorln(errors.New("rolling back transaction: " + err.Error()))
+		}
+	}()
+
+	if err != nil {
+		log.Error.Println("could not begin transaction: ", err.Error())
+		return err, tc.SystemError
+	}
+	if err, errType := req.UpdateTx(tx, user); err != nil {
+		return err, errType
+	}
+	err = tx.Commit()
+	if err != nil {
+		log.Errorln("Could not commit transaction: ", err)
+		return tc.DBError, tc.SystemError
+	}
+	rollbackTransaction = false
+	return nil, tc.NoError
+}
+
+// UpdateTx changes only the status of the request, in the given transaction, which the caller commits or rolls back. It overrides the UpdateTx of the embedded request, so conditional requests don't update the rest of the request, and get the same transition and approval checks.
+func (req *deliveryServiceRequestStatus) UpdateTx(tx *sqlx.Tx, user auth.CurrentUser) (error, tc.ApiErrorType) {
 	// req represents the state the deliveryservice_request is to transition to
 	// we want to limit what changes here -- only status can change,  and only according to the established rules
 	// for status transition
+	if req.ID == nil {
+		return errors.New("cannot update DeliveryServiceRequestStatus -- ID is nil"), tc.SystemError
+	}
+	if req.Status == nil {
+		return errors.New("Missing status for DeliveryServiceRequest"), tc.DataMissingError
+	}
 
 	// get original
 	var current TODeliveryServiceRequest
-	if req.ID == nil {
-		log.Errorf("error updating DeliveryServiceRequestStatus: ID is nil")
-	}
-	err := db.QueryRowx(selectDeliveryServiceRequestsQuery() + ` WHERE r.id=` + strconv.Itoa(*req.ID)).StructScan(&current)
+	err := tx.QueryRowx(selectDeliveryServiceRequestsQuery()+`WHERE r.id = $1 FOR UPDATE OF r`, *req.ID).StructScan(&current)
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return errors.New("no deliveryservice request found with this id"), tc.DataMissingError
+		}
 		log.Errorf("Error querying DeliveryServiceRequests: %v", err)
-		return err, tc.SystemError
+		return tc.DBError, tc.SystemError
 	}
 
 	if err = current.Status.ValidTransition(*req.Status); err != nil {
 		return err, tc.DataConflictError
 	}
 
+	// completing a request applies it, which only fulfilling it does
+	if *req.Status == tc.RequestStatusComplete {
+		return errors.New("deliveryservice requests can only be completed by fulfilling them with POST deliveryservice_requests/" + strconv.Itoa(*req.ID) + "/fulfill"), tc.DataConflictError
+	}
+
+	// a request can't become pending until it satisfies its approval policy
+	if *req.Status == tc.RequestStatusPending && *current.Status != tc.RequestStatusPending {
+		if err, errType := checkApprovals(*req.ID, tx); err != nil {
+			return err, errType
+		}
+	}
+
 	// keep everything else the same -- only update status
 	st := req.Status
 	*req = deliveryServiceRequestStatus{current}
 	req.Status = st
 
-	rollbackTransaction := true
-	tx, err := db.Beginx()
-	defer func() {
-		if tx == nil || !rollbackTransaction {
-			return
-		}
-		err := tx.Rollback()
-		if err != nil {
-			log.Errorln(errors.New("rolling back transaction: " + err.Error()))
-		}
-	}()
-
-	if err != nil {
-		log.Error.Println("could not begin transaction: ", err.Error())
-		return err, tc.SystemError
-	}
-
 	// LastEditedBy field should not change with status update
-	query := `UPDATE deliveryservice_request SET status = '` + string(*req.Status) + `' WHERE id=` + strconv.Itoa(*req.ID)
-	_, err = tx.Exec(query)
+	_, err = tx.Exec(`UPDATE deliveryservice_request SET status = $1 WHERE id = $2`, string(*req.Status), *req.ID)
 	if err != nil {
 		if pqErr, ok := err.(*pq.Error); ok {
 			err, eType := dbhelpers.ParsePQUniqueConstraintError(pqErr)
@@ -684,20 +752,12 @@ func (req *deliveryServiceRequestStatus) Update(db *sqlx.DB, user auth.CurrentUs
 		return tc.DBError, tc.SystemError
 	}
 
-	err = tx.Commit()
-	if err != nil {
-		log.Errorln("Could not commit transaction: ", err)
-		return tc.DBError, tc.SystemError
-	}
-
 	// update req with current info
-	err = db.QueryRowx(selectDeliveryServiceRequestsQuery() + ` WHERE r.id=` + strconv.Itoa(*req.ID)).StructScan(req)
+	err = tx.QueryRowx(selectDeliveryServiceRequestsQuery()+`WHERE r.id = $1`, *req.ID).StructScan(req)
 	if err != nil {
 		log.Errorf("Error querying DeliveryServiceRequests: %v", err)
-		return err, tc.SystemError
+		return tc.DBError, tc.SystemError
 	}
-
-	rollbackTransaction = false
 	return nil, tc.NoError
 }
 
@@ -20,10 +20,16 @@ package request
  */
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	tc "github.com/apache/incubator-trafficcontrol/lib/go-tc"
 	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/auth"
 	"github.com/jmoiron/sqlx"
 	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
 )
@@ -144,3 +150,151 @@ func TestGetDeliveryServiceRequest(t *testing.T) {
 		}
 	*/
 }
+
+func requestRows(status tc.RequestStatus) *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"author", "lasteditedby", "assignee", "assignee_id", "author_id", "change_type", "created_at", "id", "last_edited_by_id", "last_updated", "deliveryservice", "status", "change_log_id", "xml_id"}).
+		AddRow("alice", "alice", nil, nil, 2, "update", time.Now(), 1, 2, time.Now(), []byte(`{"xmlId":"ds1"}`), []byte(status), nil, "ds1")
+}
+
+func conditionalPut(t *testing.T, handler http.HandlerFunc, body string, ifMatch string) *http.Request {
+	w := httptest.NewRecorder()
+	r, err := http.NewRequest(http.MethodPut, "", strings.NewReader(body))
+	if err != nil {
+		t.Fatal("Error creating new request")
+	}
+	r.Header.Set(api.IfMatchHeader, ifMatch)
+	ctx := context.WithValue(r.Context(), auth.CurrentUserKey, auth.CurrentUser{UserName: "username", ID: 1, PrivLevel: auth.PrivLevelAdmin})
+	ctx = context.WithValue(ctx, api.PathParamsKey, map[string]string{"id": "1"})
+	r = r.WithContext(ctx)
+	handler(w, r)
+	return r
+}
+
+func TestConditionalStatusUpdate(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	//verifies only the status is changed, with the status transition checks, and rolled back when the request was modified
+	mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"tenant_id"}))
+	mock.ExpectBegin()
+	mock.ExpectQuery("FOR UPDATE OF r").WithArgs(1).WillReturnRows(requestRows(tc.RequestStatusSubmitted))
+	mock.ExpectExec(`UPDATE deliveryservice_request SET status = \$1 WHERE id = \$2`).WithArgs("draft", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(requestRows(tc.RequestStatusDraft))
+	mock.ExpectQuery("SELECT").WillReturnRows(requestRows(tc.RequestStatusSubmitted))
+	mock.ExpectRollback()
+
+	r := conditionalPut(t, api.UpdateHandler(GetStatusRefType(), db), `{"id":1,"status":"draft"}`, `"stale"`)
+	if status, _ := r.Context().Value(tc.StatusKey).(int); status != http.StatusPreconditionFailed {
+		t.Errorf("expected status %v, got %v", http.StatusPreconditionFailed, status)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expected only the status to be updated and rolled back: %v", err)
+	}
+
+	mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"tenant_id"}))
+	mock.ExpectBegin()
+	mock.ExpectQuery("FOR UPDATE OF r").WithArgs(1).WillReturnRows(requestRows(tc.RequestStatusDraft))
+	mock.ExpectRollback()
+
+	r = conditionalPut(t, api.UpdateHandler(GetStatusRefType(), db), `{"id":1,"status":"pending"}`, "*")
+	if status, _ := r.Context().Value(tc.StatusKey).(int); status != http.StatusBadRequest {
+		t.Errorf("invalid transition expected status %v, got %v", http.StatusBadRequest, status)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expected an invalid transition not to be written: %v", err)
+	}
+
+	mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"tenant_id"}))
+	mock.ExpectBegin()
+	mock.ExpectQuery("FOR UPDATE OF r").WithArgs(1).WillReturnRows(requestRows(tc.RequestStatusPending))
+	mock.ExpectRollback()
+
+	r = conditionalPut(t, api.UpdateHandler(GetStatusRefType(), db), `{"id":1,"status":"complete"}`, "*")
+	if status, _ := r.Context().Value(tc.StatusKey).(int); status != http.StatusBadRequest {
+		t.Errorf("completing expected status %v, got %v", http.StatusBadRequest, status)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expected requests to only be completed by fulfilling them: %v", err)
+	}
+}
+
+func TestConditionalAssignmentUpdate(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	//verifies only the assignee is changed, and committed when the request is unmodified
+	mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"tenant_id"}))
+	mock.ExpectBegin()
+	mock.ExpectQuery("FOR UPDATE OF r").WithArgs(1).WillReturnRows(requestRows(tc.RequestStatusSubmitted))
+	mock.ExpectExec(`UPDATE deliveryservice_request SET assignee_id = \$1 WHERE id = \$2`).WithArgs(3, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(requestRows(tc.RequestStatusSubmitted))
+	mock.ExpectQuery("SELECT").WillReturnRows(requestRows(tc.RequestStatusSubmitted))
+	mock.ExpectCommit()
+	mock.ExpectExec("INSERT").WillReturnResult(sqlmock.NewResult(1, 1))
+
+	r := conditionalPut(t, api.UpdateHandler(GetAssignRefType(), db), `{"id":1,"assigneeId":3}`, "*")
+	if status, _ := r.Context().Value(tc.StatusKey).(int); status != 0 && status != http.StatusOK {
+		t.Errorf("expected status %v, got %v", http.StatusOK, status)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expected only the assignee to be updated and committed: %v", err)
+	}
+}
+
+func TestStatusUpdateChecksApprovalsInTx(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	//verifies the approvals are checked after the request is locked, in the transaction writing the status
+	mock.ExpectBegin()
+	mock.ExpectQuery("FOR UPDATE OF r").WithArgs(1).WillReturnRows(requestRows(tc.RequestStatusSubmitted))
+	mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"tenant_id", "cdn_id"}).AddRow(3, 2))
+	mock.ExpectQuery("deliveryservice_request_approval_policy").WithArgs(3, 2).WillReturnRows(policyRows().AddRow(1, []byte("{5}"), []byte("{}")))
+	mock.ExpectQuery("deliveryservice_request_comment").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"author_id", "author", "author_role", "verdict", "id", "last_updated"}))
+	mock.ExpectRollback()
+
+	id := 1
+	pending := tc.RequestStatusPending
+	req := GetStatusRefType()
+	req.ID = &id
+	req.Status = &pending
+	if err, errType := req.Update(db, auth.CurrentUser{ID: 1}); errType != tc.DataConflictError {
+		t.Errorf("Update to pending without approvals expected: %v, actual: %v %v", tc.DataConflictError, errType, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expected the approvals to be checked in the transaction: %v", err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("FOR UPDATE OF r").WithArgs(1).WillReturnRows(requestRows(tc.RequestStatusSubmitted))
+	mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"tenant_id", "cdn_id"}).AddRow(3, 2))
+	mock.ExpectQuery("deliveryservice_request_approval_policy").WithArgs(3, 2).WillReturnRows(policyRows().AddRow(1, []byte("{5}"), []byte("{}")))
+	mock.ExpectQuery("deliveryservice_request_comment").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"author_id", "author", "author_role", "verdict", "id", "last_updated"}).
+		AddRow(5, "bob", 1, []byte(tc.RequestVerdictApprove), 8, time.Now()))
+	mock.ExpectExec("UPDATE deliveryservice_request SET status").WithArgs("pending", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(requestRows(tc.RequestStatusPending))
+	mock.ExpectCommit()
+
+	req.Status = &pending
+	if err, errType := req.Update(db, auth.CurrentUser{ID: 1}); err != nil {
+		t.Errorf("Update to pending with approvals expected: no error, actual: %v %v", errType, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expected the approved status to be committed: %v", err)
+	}
+}
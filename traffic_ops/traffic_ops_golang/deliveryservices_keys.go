@@ -36,9 +36,8 @@ import (
 	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/api"
 	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/auth"
 	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/config"
-	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/riaksvc"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/secretsvc"
 	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/tenant"
-	"github.com/basho/riak-go-client"
 	"github.com/jmoiron/sqlx"
 )
 
@@ -102,16 +101,12 @@ func getXMLID(cdnID sql.NullInt64, hostRegex string, db *sqlx.DB) (sql.NullStrin
 
 func getDeliveryServiceSSLKeysByXMLID(xmlID string, version string, db *sqlx.DB, cfg config.Config) ([]byte, error) {
 	var respBytes []byte
-	// create and start a cluster
-	cluster, err := riaksvc.GetRiakCluster(db, cfg.RiakAuthOptions)
+	store, err := secretsvc.Open(db, cfg)
 	if err != nil {
 		return nil, err
 	}
-	if err = cluster.Start(); err != nil {
-		return nil, err
-	}
 	defer func() {
-		if err := cluster.Stop(); err != nil {
+		if err := store.Close(); err != nil {
 			log.Errorf("%v\n", err)
 		}
 	}()
@@ -123,13 +118,13 @@ func getDeliveryServiceSSLKeysByXMLID(xmlID string, version string, db *sqlx.DB,
 	}
 
 	// get the deliveryservice ssl keys by xmlID and version
-	ro, err := riaksvc.FetchObjectValues(xmlID, SSLKeysBucket, cluster)
+	value, ok, err := store.Fetch(SSLKeysBucket, xmlID)
 	if err != nil {
 		return nil, err
 	}
 
 	// no keys we're found
-	if ro == nil {
+	if !ok {
 		alert := tc.CreateAlerts(tc.InfoLevel, "no object found for the specified key")
 		respBytes, err = json.Marshal(alert)
 		if err != nil {
@@ -140,7 +135,7 @@ func getDeliveryServiceSSLKeysByXMLID(xmlID string, version string, db *sqlx.DB,
 		var key tc.DeliveryServiceSSLKeys
 
 		// unmarshal into a response tc.DeliveryServiceSSLKeysResponse object.
-		if err := json.Unmarshal(ro[0].Value, &key); err != nil {
+		if err := json.Unmarshal(value, &key); err != nil {
 			log.Errorf("failed at unmarshaling sslkey response: %s\n", err)
 			return nil, err
 		}
@@ -301,32 +296,18 @@ func addDeliveryServiceSSLKeysHandler(db *sqlx.DB, cfg config.Config) http.Handl
 			return
 		}
 
-		// create and start a cluster
-		cluster, err := riaksvc.GetRiakCluster(db, cfg.RiakAuthOptions)
+		store, err := secretsvc.Open(db, cfg)
 		if err != nil {
 			handleErr(http.StatusInternalServerError, err)
 			return
 		}
-		if err = cluster.Start(); err != nil {
-			handleErr(http.StatusInternalServerError, err)
-			return
-		}
 		defer func() {
-			if err := cluster.Stop(); err != nil {
+			if err := store.Close(); err != nil {
 				log.Errorf("%v\n", err)
 			}
 		}()
 
-		// create a storage object and store the data
-		obj := &riak.Object{
-			ContentType:     "text/json",
-			Charset:         "utf-8",
-			ContentEncoding: "utf-8",
-			Key:             keysObj.DeliveryService,
-			Value:           []byte(keysJSON),
-		}
-
-		err = riaksvc.SaveObject(obj, SSLKeysBucket, cluster)
+		err = store.Save(SSLKeysBucket, keysObj.DeliveryService, keysJSON)
 		if err != nil {
 			log.Errorf("%v\n", err)
 			handleErr(http.StatusInternalServerError, err)
@@ -347,8 +328,8 @@ func getDeliveryServiceSSLKeysByHostNameHandler(db *sqlx.DB, cfg config.Config)
 		var hostName string
 		var hostRegex string
 
-		if cfg.RiakEnabled == false {
-			handleErr(http.StatusServiceUnavailable, fmt.Errorf("The RIAK service is unavailable"))
+		if !secretsvc.Enabled(cfg) {
+			handleErr(http.StatusServiceUnavailable, secretsvc.ErrUnavailable)
 			return
 		}
 
@@ -448,8 +429,8 @@ func getDeliveryServiceSSLKeysByXMLIDHandler(db *sqlx.DB, cfg config.Config) htt
 		handleErr := tc.GetHandleErrorsFunc(w, r)
 		var respBytes []byte
 
-		if cfg.RiakEnabled == false {
-			handleErr(http.StatusServiceUnavailable, fmt.Errorf("The RIAK service is unavailable"))
+		if !secretsvc.Enabled(cfg) {
+			handleErr(http.StatusServiceUnavailable, secretsvc.ErrUnavailable)
 			return
 		}
 
@@ -0,0 +1,399 @@
+package main
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-log"
+	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/config"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/events"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/secretsvc"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/tenant"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// getHTTPSDSSSLInfos returns the delivery services with an HTTPS protocol, ordered by XML ID. If cdn isn't empty, only its delivery services are returned. If user isn't nil, only the delivery services of the user's tenants are returned.
+func getHTTPSDSSSLInfos(db *sqlx.DB, cdn string, user *auth.CurrentUser) ([]dsSSLInfo, error) {
+	q := `
+SELECT ds.id, ds.xml_id, t.name, COALESCE(ds.routing_name, ''), cdn.name, cdn.domain_name, COALESCE(ds.ssl_key_version, 0), ARRAY(
+  SELECT r.pattern FROM deliveryservice_regex AS dsr
+  JOIN regex AS r ON r.id = dsr.regex
+  JOIN type AS rt ON rt.id = r.type
+  WHERE dsr.deliveryservice = ds.id
+  AND rt.name = 'HOST_REGEXP'
+  ORDER BY COALESCE(dsr.set_number, 0), r.id
+)
+FROM deliveryservice AS ds
+JOIN type AS t ON t.id = ds.type
+JOIN cdn ON cdn.id = ds.cdn_id
+WHERE ds.protocol IN (1, 2, 3)
+AND (:cdn = '' OR cdn.name = :cdn)
+AND (:all_tenants OR ` + tenant.TenancyCheckClause("ds.tenant_id") + `)
+ORDER BY ds.xml_id
+`
+	params := map[string]interface{}{"cdn": cdn, "all_tenants": user == nil, "user_tenant_id": 0}
+	if user != nil {
+		params["user_tenant_id"] = user.TenantID
+	}
+	rows, err := db.NamedQuery(q, params)
+	if err != nil {
+		return nil, errors.New("querying HTTPS delivery services: " + err.Error())
+	}
+	defer rows.Close()
+	dses := []dsSSLInfo{}
+	for rows.Next() {
+		ds := dsSSLInfo{}
+		if err := rows.Scan(&ds.ID, &ds.XMLID, &ds.Type, &ds.RoutingName, &ds.CDN, &ds.Domain, &ds.SSLKeyVersion, pq.Array(&ds.HostRegexes)); err != nil {
+			return nil, errors.New("scanning HTTPS delivery services: " + err.Error())
+		}
+		dses = append(dses, ds)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.New("scanning HTTPS delivery services: " + err.Error())
+	}
+	return dses, nil
+}
+
+// dsRoutingHostname returns the name clients request the delivery service by, from its first host regex, or "" if that regex isn't a routed name or a single hostname.
+func dsRoutingHostname(ds dsSSLInfo) string {
+	if len(ds.HostRegexes) == 0 {
+		return ""
+	}
+	pattern := ds.HostRegexes[0]
+	if match := wildcardHostRegex.FindStringSubmatch(pattern); match != nil {
+		return strings.ToLower(dsRoutingName(ds) + "." + strings.Replace(match[1], `\.`, ".", -1) + "." + ds.Domain)
+	}
+	if literalHostRegex.MatchString(pattern) {
+		return strings.ToLower(strings.Replace(pattern, `\.`, ".", -1))
+	}
+	return ""
+}
+
+// fetchLatestDSSSLKeys returns the latest SSL keys of the delivery service, or nil if it has none.
+func fetchLatestDSSSLKeys(store secretsvc.Store, xmlID string) (*tc.DeliveryServiceSSLKeys, error) {
+	value, ok, err := store.Fetch(SSLKeysBucket, xmlID+"-latest")
+	if err != nil {
+		return nil, errors.New("fetching SSL keys: " + err.Error())
+	}
+	if !ok {
+		return nil, nil
+	}
+	keys := tc.DeliveryServiceSSLKeys{}
+	if err := json.Unmarshal(value, &keys); err != nil {
+		return nil, errors.New("unmarshalling SSL keys: " + err.Error())
+	}
+	return &keys, nil
+}
+
+// decodeSSLCertificates returns the certificates of a stored crt, which is base64 encoded PEM, and may contain escaped newlines. The first certificate is the server certificate, followed by its chain.
+func decodeSSLCertificates(crt string) ([]*x509.Certificate, error) {
+	b64 := strings.Join(strings.Fields(strings.Replace(crt, `\n`, "", -1)), "")
+	pemBts, err := base64.StdEncoding.DecodeString(b64)
+	if err != nil {
+		return nil, errors.New("base64 decoding certificate: " + err.Error())
+	}
+	certs := []*x509.Certificate{}
+	for {
+		block, rest := pem.Decode(pemBts)
+		if block == nil {
+			break
+		}
+		pemBts = rest
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, errors.New("parsing certificate: " + err.Error())
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("no PEM certificate found")
+	}
+	return certs, nil
+}
+
+// sslRoots returns the roots delivery service certificates are verified against: the system roots, and the internal certificate authority if one is configured.
+func sslRoots(cfg config.Config) *x509.CertPool {
+	roots, err := x509.SystemCertPool()
+	if err != nil {
+		log.Warnln("loading system certificate roots, verifying delivery service certificates against the internal CA only: " + err.Error())
+		roots = x509.NewCertPool()
+	}
+	if cfg.SSLCACert != nil {
+		roots.AddCert(cfg.SSLCACert)
+	}
+	return roots
+}
+
+// dsSSLCertificate returns the certificate of the delivery service's SSL keys, which are nil if it has none, verified against the roots at the given time.
+func dsSSLCertificate(ds dsSSLInfo, keys *tc.DeliveryServiceSSLKeys, roots *x509.CertPool, now time.Time) tc.DeliveryServiceSSLCertificate {
+	cert := tc.DeliveryServiceSSLCertificate{
+		DeliveryService: ds.XMLID,
+		CDN:             ds.CDN,
+		SANs:            []string{},
+		RoutingHostname: dsRoutingHostname(ds),
+	}
+	if keys == nil {
+		cert.ChainStatus = tc.SSLChainStatusMissing
+		return cert
+	}
+	cert.Version = &keys.Version
+	certs, err := decodeSSLCertificates(keys.Certificate.Crt)
+	if err != nil {
+		cert.ChainStatus = tc.SSLChainStatusInvalid
+		cert.ChainError = err.Error()
+		return cert
+	}
+	leaf := certs[0]
+	days := int(math.Floor(leaf.NotAfter.Sub(now).Hours() / 24))
+	cert.Subject = leaf.Subject.String()
+	cert.Issuer = leaf.Issuer.String()
+	cert.SANs = append(cert.SANs, leaf.DNSNames...)
+	cert.NotBefore = &leaf.NotBefore
+	cert.NotAfter = &leaf.NotAfter
+	cert.DaysUntilExpiration = &days
+	if cert.RoutingHostname != "" {
+		cert.HostnameMismatch = leaf.VerifyHostname(cert.RoutingHostname) != nil
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, c := range certs[1:] {
+		intermediates.AddCert(c)
+	}
+	_, err = leaf.Verify(x509.VerifyOptions{
+		Intermediates: intermediates,
+		Roots:         roots,
+		CurrentTime:   now,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	})
+	switch {
+	case err == nil:
+		cert.ChainStatus = tc.SSLChainStatusValid
+	case isSelfSigned(leaf) && !now.Before(leaf.NotBefore) && !now.After(leaf.NotAfter):
+		cert.ChainStatus = tc.SSLChainStatusSelfSigned
+	default:
+		cert.ChainStatus = tc.SSLChainStatusInvalid
+		cert.ChainError = err.Error()
+	}
+	return cert
+}
+
+// isSelfSigned returns whether the certificate is its own issuer, and signed by its own key.
+func isSelfSigned(cert *x509.Certificate) bool {
+	return bytes.Equal(cert.RawIssuer, cert.RawSubject) && cert.CheckSignature(cert.SignatureAlgorithm, cert.RawTBSCertificate, cert.Signature) == nil
+}
+
+// getDSSSLCertificates returns the certificates of the delivery services. Delivery services whose keys can't be read are listed with an invalid chain, rather than failing the others.
+func getDSSSLCertificates(store secretsvc.Store, dses []dsSSLInfo, roots *x509.CertPool, now time.Time) []tc.DeliveryServiceSSLCertificate {
+	certs := []tc.DeliveryServiceSSLCertificate{}
+	for _, ds := range dses {
+		keys, err := fetchLatestDSSSLKeys(store, ds.XMLID)
+		if err != nil {
+			log.Errorln("delivery service " + ds.XMLID + " SSL certificate: " + err.Error())
+			cert := dsSSLCertificate(ds, nil, roots, now)
+			cert.ChainStatus = tc.SSLChainStatusInvalid
+			cert.ChainError = "reading SSL keys failed"
+			certs = append(certs, cert)
+			continue
+		}
+		certs = append(certs, dsSSLCertificate(ds, keys, roots, now))
+	}
+	return certs
+}
+
+// sslCertificateWarnings returns the problems with the certificate: missing, invalid, expiring within warningDays, or not valid for the routing hostname.
+func sslCertificateWarnings(cert tc.DeliveryServiceSSLCertificate, warningDays int) []string {
+	warnings := []string{}
+	switch cert.ChainStatus {
+	case tc.SSLChainStatusMissing:
+		return append(warnings, "no SSL keys")
+	case tc.SSLChainStatusInvalid:
+		warnings = append(warnings, "invalid certificate: "+cert.ChainError)
+	}
+	if days := cert.DaysUntilExpiration; days != nil && *days < 0 {
+		warnings = append(warnings, "expired "+strconv.Itoa(-*days)+" days ago")
+	} else if days != nil && *days <= warningDays {
+		warnings = append(warnings, "expires in "+strconv.Itoa(*days)+" days")
+	}
+	if cert.HostnameMismatch {
+		warnings = append(warnings, "not valid for routing hostname "+cert.RoutingHostname)
+	}
+	return warnings
+}
+
+// getDeliveryServiceSSLCertificatesHandler returns the parsed certificate of every HTTPS delivery service the user's tenants can access, so expiring and invalid certificates can be found without fetching each delivery service's keys. The cdn parameter limits the certificates to a CDN, and the days parameter to certificates expiring within that many days, including expired certificates.
+func getDeliveryServiceSSLCertificatesHandler(db *sqlx.DB, cfg config.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		handleErr := tc.GetHandleErrorsFunc(w, r)
+		if !secretsvc.Enabled(cfg) {
+			handleErr(http.StatusServiceUnavailable, secretsvc.ErrUnavailable)
+			return
+		}
+		ctx := r.Context()
+		user, err := auth.GetCurrentUser(ctx)
+		if err != nil {
+			handleErr(http.StatusInternalServerError, err)
+			return
+		}
+		params, err := api.GetCombinedParams(r)
+		if err != nil {
+			handleErr(http.StatusInternalServerError, err)
+			return
+		}
+		days := -1
+		if daysStr, ok := params["days"]; ok {
+			if days, err = strconv.Atoi(daysStr); err != nil || days < 0 {
+				handleErr(http.StatusBadRequest, errors.New("'days' must be a non-negative integer"))
+				return
+			}
+		}
+
+		dses, err := getHTTPSDSSSLInfos(db, params["cdn"], user)
+		if err != nil {
+			log.Errorln("getting delivery service SSL certificates: " + err.Error())
+			handleErr(http.StatusInternalServerError, tc.DBError)
+			return
+		}
+		store, err := secretsvc.Open(db, cfg)
+		if err != nil {
+			handleErr(http.StatusInternalServerError, err)
+			return
+		}
+		defer func() {
+			if err := store.Close(); err != nil {
+				log.Errorf("%v\n", err)
+			}
+		}()
+
+		certs := getDSSSLCertificates(store, dses, sslRoots(cfg), time.Now())
+		if days >= 0 {
+			expiring := []tc.DeliveryServiceSSLCertificate{}
+			for _, cert := range certs {
+				if cert.DaysUntilExpiration != nil && *cert.DaysUntilExpiration <= days {
+					expiring = append(expiring, cert)
+				}
+			}
+			certs = expiring
+		}
+
+		respBts, err := json.Marshal(tc.DeliveryServiceSSLCertificatesResponse{Response: certs})
+		if err != nil {
+			handleErr(http.StatusInternalServerError, err)
+			return
+		}
+		w.Header().Set(tc.ContentType, tc.ApplicationJson)
+		w.Write(respBts)
+	}
+}
+
+// startSSLCertificateCheck checks delivery service certificates at the configured interval, until the process exits. It does nothing if the check is disabled, or the secret store is unavailable.
+func startSSLCertificateCheck(db *sqlx.DB, cfg config.Config) {
+	interval := cfg.SSLCertCheckInterval()
+	if interval == 0 || !secretsvc.Enabled(cfg) {
+		return
+	}
+	go func() {
+		for range time.Tick(interval) {
+			if err := checkSSLCertificates(db, cfg, interval, time.Now()); err != nil {
+				log.Errorln("checking delivery service SSL certificates: " + err.Error())
+			}
+		}
+	}()
+}
+
+// checkSSLCertificates publishes an SSL certificate event for each HTTPS delivery service whose certificate has warnings. A delivery service isn't warned of again within the interval, so Traffic Ops instances checking at different times don't repeat each other's warnings; and the check is skipped if another Traffic Ops is running it.
+func checkSSLCertificates(db *sqlx.DB, cfg config.Config, interval time.Duration, now time.Time) error {
+	dses, err := getHTTPSDSSSLInfos(db, "", nil)
+	if err != nil {
+		return err
+	}
+	store, err := secretsvc.Open(db, cfg)
+	if err != nil {
+		return errors.New("opening secret store: " + err.Error())
+	}
+	defer func() {
+		if err := store.Close(); err != nil {
+			log.Errorf("%v\n", err)
+		}
+	}()
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return errors.New("beginning transaction: " + err.Error())
+	}
+	commitTx := false
+	defer func() {
+		if commitTx {
+			return
+		}
+		if err := tx.Rollback(); err != nil {
+			log.Errorln("rolling back SSL certificate check transaction: " + err.Error())
+		}
+	}()
+	locked := false
+	if err := tx.QueryRow(`SELECT pg_try_advisory_xact_lock(hashtext('ssl_certificate_check'))`).Scan(&locked); err != nil {
+		return errors.New("locking SSL certificate check: " + err.Error())
+	}
+	if !locked {
+		log.Infoln("SSL certificates are being checked by another Traffic Ops, skipping check")
+		return nil
+	}
+
+	for _, cert := range getDSSSLCertificates(store, dses, sslRoots(cfg), now) {
+		warnings := sslCertificateWarnings(cert, cfg.SSLCertWarningDays)
+		if len(warnings) == 0 {
+			continue
+		}
+		warned := false
+		if err := tx.QueryRow(`SELECT EXISTS(SELECT 1 FROM event WHERE type = $1 AND data->>'deliveryService' = $2 AND created > now() - $3 * interval '1 second')`, tc.EventTypeSSLCertificate, cert.DeliveryService, interval.Seconds()).Scan(&warned); err != nil {
+			return errors.New("querying SSL certificate events: " + err.Error())
+		}
+		if warned {
+			continue
+		}
+		msg := "SSL certificate of delivery service " + cert.DeliveryService + ": " + strings.Join(warnings, ", ")
+		if err := events.Publish(tc.EventTypeSSLCertificate, msg, tc.SSLCertificateEventData{DeliveryServiceSSLCertificate: cert, Warnings: warnings}, auth.CurrentUser{}, tx); err != nil {
+			return err
+		}
+		log.Warnln(msg)
+	}
+	if err := tx.Commit(); err != nil {
+		return errors.New("committing transaction: " + err.Error())
+	}
+	commitTx = true
+	return nil
+}
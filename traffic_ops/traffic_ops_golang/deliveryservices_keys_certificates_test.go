@@ -0,0 +1,210 @@
+package main
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
+)
+
+// mapStore is a secret store of a single bucket, for tests.
+type mapStore map[string][]byte
+
+func (s mapStore) Fetch(bucket string, key string) ([]byte, bool, error) {
+	v, ok := s[key]
+	return v, ok, nil
+}
+func (s mapStore) Save(bucket string, key string, value []byte) error { s[key] = value; return nil }
+func (s mapStore) Delete(bucket string, key string) error             { delete(s, key); return nil }
+func (s mapStore) Keys(bucket string) ([]string, error)               { return nil, nil }
+func (s mapStore) Close() error                                       { return nil }
+
+func testSSLCA(t *testing.T) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Lab CA"},
+		NotBefore:             time.Now().AddDate(-2, 0, 0),
+		NotAfter:              time.Now().AddDate(2, 0, 0),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
+
+func TestDSRoutingHostname(t *testing.T) {
+	tests := []struct {
+		ds       dsSSLInfo
+		expected string
+	}{
+		{dsSSLInfo{Type: "HTTP", RoutingName: "tr", Domain: "cdn.example.net", HostRegexes: []string{`.*\.demo1\..*`, `demo\.example\.com`}}, "tr.demo1.cdn.example.net"},
+		{dsSSLInfo{Type: "DNS", Domain: "cdn.example.net", HostRegexes: []string{`.*\.demo1\..*`}}, "edge.demo1.cdn.example.net"},
+		{dsSSLInfo{Type: "HTTP", RoutingName: "tr", Domain: "cdn.example.net", HostRegexes: []string{`Demo\.example\.com`}}, "demo.example.com"},
+		{dsSSLInfo{Type: "HTTP", RoutingName: "tr", Domain: "cdn.example.net", HostRegexes: []string{`^(.*)\.other$`}}, ""},
+		{dsSSLInfo{Type: "HTTP", RoutingName: "tr", Domain: "cdn.example.net"}, ""},
+	}
+	for _, test := range tests {
+		if actual := dsRoutingHostname(test.ds); actual != test.expected {
+			t.Errorf("routing hostname of %+v expected '%s', actual '%s'", test.ds, test.expected, actual)
+		}
+	}
+}
+
+func TestDecodeSSLCertificates(t *testing.T) {
+	caCert, caKey := testSSLCA(t)
+	keys, err := generateDSSSLCertificate(pkix.Name{CommonName: "*.demo1.cdn.example.net"}, []string{"*.demo1.cdn.example.net"}, 30, caCert, caKey, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// keys added by the Perl Traffic Ops may contain escaped newlines.
+	escaped := keys.Crt[:40] + `\n` + keys.Crt[40:]
+	certs, err := decodeSSLCertificates(escaped)
+	if err != nil {
+		t.Fatalf("decoding certificates: %v", err)
+	}
+	if len(certs) != 2 || certs[0].Subject.CommonName != "*.demo1.cdn.example.net" || certs[1].Subject.CommonName != "Lab CA" {
+		t.Errorf("expected the certificate and CA, actual %d certificates", len(certs))
+	}
+
+	if _, err := decodeSSLCertificates(BadData); err == nil {
+		t.Error("expected an error decoding bad data, actual nil")
+	}
+}
+
+func TestDSSSLCertificate(t *testing.T) {
+	caCert, caKey := testSSLCA(t)
+	roots := x509.NewCertPool()
+	roots.AddCert(caCert)
+	now := time.Now()
+	ds := dsSSLInfo{XMLID: "demo1", CDN: "cdn1", Type: "HTTP", RoutingName: "tr", Domain: "cdn.example.net", HostRegexes: []string{`.*\.demo1\..*`}}
+	wildcard := []string{"*.demo1.cdn.example.net"}
+
+	keysFor := func(hostnames []string, days int, issuedAt time.Time, ca bool) *tc.DeliveryServiceSSLKeys {
+		signerCert, signerKey := (*x509.Certificate)(nil), crypto.Signer(nil)
+		if ca {
+			signerCert, signerKey = caCert, caKey
+		}
+		cert, err := generateDSSSLCertificate(pkix.Name{CommonName: hostnames[0]}, hostnames, days, signerCert, signerKey, issuedAt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &tc.DeliveryServiceSSLKeys{Key: "demo1", Version: 2, Certificate: cert}
+	}
+
+	tests := []struct {
+		name     string
+		keys     *tc.DeliveryServiceSSLKeys
+		status   string
+		days     int
+		mismatch bool
+	}{
+		{"CA issued", keysFor(wildcard, 30, now, true), tc.SSLChainStatusValid, 29, false},
+		{"self-signed", keysFor(wildcard, 365, now, false), tc.SSLChainStatusSelfSigned, 364, false},
+		{"expired", keysFor(wildcard, 30, now.AddDate(0, 0, -40), true), tc.SSLChainStatusInvalid, -11, false},
+		{"expired self-signed", keysFor(wildcard, 30, now.AddDate(0, 0, -40), false), tc.SSLChainStatusInvalid, -11, false},
+		{"hostname mismatch", keysFor([]string{"*.other.cdn.example.net"}, 30, now, true), tc.SSLChainStatusValid, 29, true},
+	}
+	for _, test := range tests {
+		cert := dsSSLCertificate(ds, test.keys, roots, now)
+		if cert.ChainStatus != test.status {
+			t.Errorf("%s: expected chain status '%s', actual '%s' (%s)", test.name, test.status, cert.ChainStatus, cert.ChainError)
+		}
+		if cert.DaysUntilExpiration == nil || *cert.DaysUntilExpiration != test.days {
+			t.Errorf("%s: expected %d days until expiration, actual %v", test.name, test.days, cert.DaysUntilExpiration)
+		}
+		if cert.HostnameMismatch != test.mismatch {
+			t.Errorf("%s: expected hostname mismatch %v, actual %v", test.name, test.mismatch, cert.HostnameMismatch)
+		}
+		if cert.RoutingHostname != "tr.demo1.cdn.example.net" || cert.Version == nil || *cert.Version != 2 || cert.DeliveryService != "demo1" || cert.CDN != "cdn1" {
+			t.Errorf("%s: expected the delivery service's routing hostname and key version, actual %+v", test.name, cert)
+		}
+	}
+
+	missing := dsSSLCertificate(ds, nil, roots, now)
+	if missing.ChainStatus != tc.SSLChainStatusMissing || missing.NotAfter != nil || missing.SANs == nil {
+		t.Errorf("expected a missing certificate without certificate fields, actual %+v", missing)
+	}
+}
+
+func TestGetDSSSLCertificates(t *testing.T) {
+	keys, err := generateDSSSLCertificate(pkix.Name{CommonName: "*.demo1.cdn.example.net"}, []string{"*.demo1.cdn.example.net"}, 30, nil, nil, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	keysJSON, err := json.Marshal(tc.DeliveryServiceSSLKeys{Key: "demo1", Version: 1, Certificate: keys})
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := mapStore{"demo1-latest": keysJSON, "demo2-latest": []byte(`{"version": {}}`)}
+	dses := []dsSSLInfo{{XMLID: "demo1"}, {XMLID: "demo2"}, {XMLID: "demo3"}}
+
+	certs := getDSSSLCertificates(store, dses, x509.NewCertPool(), time.Now())
+	statuses := []string{}
+	for _, cert := range certs {
+		statuses = append(statuses, cert.DeliveryService+" "+cert.ChainStatus)
+	}
+	expected := []string{"demo1 " + tc.SSLChainStatusSelfSigned, "demo2 " + tc.SSLChainStatusInvalid, "demo3 " + tc.SSLChainStatusMissing}
+	if !reflect.DeepEqual(expected, statuses) {
+		t.Errorf("expected certificates %v, actual %v", expected, statuses)
+	}
+}
+
+func TestSSLCertificateWarnings(t *testing.T) {
+	days := func(d int) *int { return &d }
+	tests := []struct {
+		cert     tc.DeliveryServiceSSLCertificate
+		expected []string
+	}{
+		{tc.DeliveryServiceSSLCertificate{ChainStatus: tc.SSLChainStatusValid, DaysUntilExpiration: days(90)}, []string{}},
+		{tc.DeliveryServiceSSLCertificate{ChainStatus: tc.SSLChainStatusMissing}, []string{"no SSL keys"}},
+		{tc.DeliveryServiceSSLCertificate{ChainStatus: tc.SSLChainStatusSelfSigned, DaysUntilExpiration: days(30)}, []string{"expires in 30 days"}},
+		{tc.DeliveryServiceSSLCertificate{ChainStatus: tc.SSLChainStatusInvalid, ChainError: "x509: certificate has expired", DaysUntilExpiration: days(-3)}, []string{"invalid certificate: x509: certificate has expired", "expired 3 days ago"}},
+		{tc.DeliveryServiceSSLCertificate{ChainStatus: tc.SSLChainStatusValid, DaysUntilExpiration: days(90), RoutingHostname: "tr.demo1.cdn.example.net", HostnameMismatch: true}, []string{"not valid for routing hostname tr.demo1.cdn.example.net"}},
+	}
+	for _, test := range tests {
+		if actual := sslCertificateWarnings(test.cert, 30); !reflect.DeepEqual(test.expected, actual) {
+			t.Errorf("warnings of %+v expected %v, actual %v", test.cert, strings.Join(test.expected, "; "), strings.Join(actual, "; "))
+		}
+	}
+}
@@ -0,0 +1,387 @@
+package main
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-log"
+	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/config"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/secretsvc"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/tenant"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// sslKeyBits is the size of generated delivery service keys, the size the Perl Traffic Ops generated.
+const sslKeyBits = 2048
+
+// wildcardHostRegex matches the host regexes of delivery services routed by name, like `.*\.ds\..*`, capturing the delivery service's part of the name.
+var wildcardHostRegex = regexp.MustCompile(`^\.\*\\\.([A-Za-z0-9-]+(?:\\\.[A-Za-z0-9-]+)*)\\\.\.\*$`)
+
+// literalHostRegex matches the host regexes of a single hostname, like `ds\.example\.com`.
+var literalHostRegex = regexp.MustCompile(`^[A-Za-z0-9-]+(?:\\\.[A-Za-z0-9-]+)+$`)
+
+// dsSSLInfo is the delivery service data SSL keys are generated from.
+type dsSSLInfo struct {
+	ID            int
+	XMLID         string
+	Type          string
+	RoutingName   string
+	CDN           string
+	Domain        string
+	SSLKeyVersion int
+	// HostRegexes are the patterns of the delivery service's host regexes, in set number order.
+	HostRegexes []string
+}
+
+// getDSSSLInfo returns the delivery service, and false if it doesn't exist. The delivery service row is locked until the transaction ends, so its SSL key version isn't changed concurrently.
+func getDSSSLInfo(tx *sqlx.Tx, xmlID string) (dsSSLInfo, bool, error) {
+	q := `
+SELECT ds.id, t.name, COALESCE(ds.routing_name, ''), cdn.name, cdn.domain_name, COALESCE(ds.ssl_key_version, 0), ARRAY(
+  SELECT r.pattern FROM deliveryservice_regex AS dsr
+  JOIN regex AS r ON r.id = dsr.regex
+  JOIN type AS rt ON rt.id = r.type
+  WHERE dsr.deliveryservice = ds.id
+  AND rt.name = 'HOST_REGEXP'
+  ORDER BY COALESCE(dsr.set_number, 0), r.id
+)
+FROM deliveryservice AS ds
+JOIN type AS t ON t.id = ds.type
+JOIN cdn ON cdn.id = ds.cdn_id
+WHERE ds.xml_id = $1
+FOR UPDATE OF ds
+`
+	ds := dsSSLInfo{XMLID: xmlID}
+	if err := tx.QueryRow(q, xmlID).Scan(&ds.ID, &ds.Type, &ds.RoutingName, &ds.CDN, &ds.Domain, &ds.SSLKeyVersion, pq.Array(&ds.HostRegexes)); err != nil {
+		if err == sql.ErrNoRows {
+			return dsSSLInfo{}, false, nil
+		}
+		return dsSSLInfo{}, false, errors.New("querying delivery service: " + err.Error())
+	}
+	return ds, true, nil
+}
+
+// dsSSLHostnames returns the hostnames of the delivery service's host regexes, for the subject alternative names of its certificate. The names routed by the CDN are a wildcard for HTTP delivery services, which are routed by any first label, and under the routing name for DNS delivery services. Host regexes which aren't a routed name or a single hostname are skipped.
+func dsSSLHostnames(ds dsSSLInfo) []string {
+	names := []string{}
+	seen := map[string]struct{}{}
+	for _, pattern := range ds.HostRegexes {
+		name := ""
+		if match := wildcardHostRegex.FindStringSubmatch(pattern); match != nil {
+			first := "*"
+			if !strings.HasPrefix(ds.Type, "HTTP") {
+				first = dsRoutingName(ds)
+			}
+			name = first + "." + strings.Replace(match[1], `\.`, ".", -1) + "." + ds.Domain
+		} else if literalHostRegex.MatchString(pattern) {
+			name = strings.Replace(pattern, `\.`, ".", -1)
+		} else {
+			continue
+		}
+		name = strings.ToLower(name)
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		names = append(names, name)
+	}
+	return names
+}
+
+// dsRoutingName returns the first label of the names the CDN routes the delivery service by, which defaults to the routing name DNS delivery services were given before it could be set.
+func dsRoutingName(ds dsSSLInfo) string {
+	if ds.RoutingName == "" {
+		return "edge"
+	}
+	return ds.RoutingName
+}
+
+// generateDSSSLCertificate generates a private key, CSR, and certificate for the hostnames, whose first is the common name. The certificate is self-signed if caCert is nil, otherwise it's issued by the CA, and followed by the CA certificate. Each is base64 encoded PEM, as the Perl Traffic Ops stored them.
+func generateDSSSLCertificate(subject pkix.Name, hostnames []string, days int, caCert *x509.Certificate, caKey crypto.Signer, now time.Time) (tc.DeliveryServiceSSLKeysCertificate, error) {
+	key, err := rsa.GenerateKey(rand.Reader, sslKeyBits)
+	if err != nil {
+		return tc.DeliveryServiceSSLKeysCertificate{}, errors.New("generating key: " + err.Error())
+	}
+	csrDER, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{Subject: subject, DNSNames: hostnames}, key)
+	if err != nil {
+		return tc.DeliveryServiceSSLKeysCertificate{}, errors.New("creating CSR: " + err.Error())
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return tc.DeliveryServiceSSLKeysCertificate{}, errors.New("generating serial number: " + err.Error())
+	}
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               subject,
+		DNSNames:              hostnames,
+		NotBefore:             now,
+		NotAfter:              now.AddDate(0, 0, days),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	parent, signer := template, crypto.Signer(key)
+	if caCert != nil {
+		parent, signer = caCert, caKey
+	}
+	crtDER, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, signer)
+	if err != nil {
+		return tc.DeliveryServiceSSLKeysCertificate{}, errors.New("creating certificate: " + err.Error())
+	}
+	crtPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: crtDER})
+	if caCert != nil {
+		crtPEM = append(crtPEM, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caCert.Raw})...)
+	}
+	return tc.DeliveryServiceSSLKeysCertificate{
+		Crt: base64.StdEncoding.EncodeToString(crtPEM),
+		Key: base64.StdEncoding.EncodeToString(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})),
+		CSR: base64.StdEncoding.EncodeToString(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csrDER})),
+	}, nil
+}
+
+// sslSubject returns the certificate subject of the request, with the given common name.
+func sslSubject(req tc.DeliveryServiceSSLKeysGenerateReq, commonName string) pkix.Name {
+	subject := pkix.Name{CommonName: commonName}
+	for _, field := range []struct {
+		val *string
+		dst *[]string
+	}{
+		{req.Country, &subject.Country},
+		{req.State, &subject.Province},
+		{req.City, &subject.Locality},
+		{req.Organization, &subject.Organization},
+		{req.BusinessUnit, &subject.OrganizationalUnit},
+	} {
+		if field.val != nil && *field.val != "" {
+			*field.dst = []string{*field.val}
+		}
+	}
+	return subject
+}
+
+// generateDeliveryServiceSSLKeysHandler generates a private key, CSR, and certificate for a delivery service, and stores them as its latest SSL keys, like the Perl Traffic Ops generate. The certificate is self-signed, or issued by the internal CA for lab CDNs. For production CDNs, the CSR can be submitted to a real CA, and its certificate added in place of the self-signed one.
+func generateDeliveryServiceSSLKeysHandler(db *sqlx.DB, cfg config.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		handleErr := tc.GetHandleErrorsFunc(w, r)
+		if !secretsvc.Enabled(cfg) {
+			handleErr(http.StatusServiceUnavailable, secretsvc.ErrUnavailable)
+			return
+		}
+
+		ctx := r.Context()
+		pathParams, err := api.GetPathParams(ctx)
+		if err != nil {
+			handleErr(http.StatusInternalServerError, err)
+			return
+		}
+		user, err := auth.GetCurrentUser(ctx)
+		if err != nil {
+			handleErr(http.StatusInternalServerError, err)
+			return
+		}
+		xmlID := pathParams["xmlID"]
+
+		// check user tenancy access to this resource.
+		hasAccess, err, apiStatus := tenant.HasTenant(*user, xmlID, db)
+		if !hasAccess {
+			switch apiStatus {
+			case tc.SystemError:
+				handleErr(http.StatusInternalServerError, err)
+				return
+			case tc.DataMissingError:
+				handleErr(http.StatusBadRequest, err)
+				return
+			case tc.ForbiddenError:
+				handleErr(http.StatusForbidden, err)
+				return
+			}
+		}
+
+		req := tc.DeliveryServiceSSLKeysGenerateReq{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+			handleErr(http.StatusBadRequest, errors.New("malformed JSON: "+err.Error()))
+			return
+		}
+		signer := tc.SSLKeysSignerSelf
+		if req.Signer != nil {
+			signer = *req.Signer
+		}
+		caCert, caKey := (*x509.Certificate)(nil), crypto.Signer(nil)
+		switch signer {
+		case tc.SSLKeysSignerSelf:
+		case tc.SSLKeysSignerCA:
+			if cfg.SSLCACert == nil {
+				handleErr(http.StatusBadRequest, errors.New("'signer' can't be '"+tc.SSLKeysSignerCA+"': no internal CA is configured"))
+				return
+			}
+			caCert, caKey = cfg.SSLCACert, cfg.SSLCAKey
+		default:
+			handleErr(http.StatusBadRequest, errors.New("'signer' must be '"+tc.SSLKeysSignerSelf+"' or '"+tc.SSLKeysSignerCA+"'"))
+			return
+		}
+		if req.Country != nil && len(*req.Country) != 2 && *req.Country != "" {
+			handleErr(http.StatusBadRequest, errors.New("'country' must be a two letter country code"))
+			return
+		}
+
+		tx, err := db.Beginx()
+		if err != nil {
+			handleErr(http.StatusInternalServerError, errors.New("beginning transaction: "+err.Error()))
+			return
+		}
+		commitTx := false
+		defer func() {
+			if commitTx {
+				return
+			}
+			if err := tx.Rollback(); err != nil {
+				log.Errorln("rolling back SSL key generation transaction: " + err.Error())
+			}
+		}()
+
+		ds, ok, err := getDSSSLInfo(tx, xmlID)
+		if err != nil {
+			log.Errorln("generating delivery service " + xmlID + " SSL keys: " + err.Error())
+			handleErr(http.StatusInternalServerError, tc.DBError)
+			return
+		}
+		if !ok {
+			handleErr(http.StatusNotFound, errors.New("no such deliveryservice: '"+xmlID+"'"))
+			return
+		}
+		version := ds.SSLKeyVersion + 1
+		if req.Version != nil {
+			if *req.Version < ds.SSLKeyVersion {
+				handleErr(http.StatusBadRequest, errors.New("'version' can't be less than the delivery service's SSL key version "+strconv.Itoa(ds.SSLKeyVersion)))
+				return
+			}
+			version = *req.Version
+		}
+		hostnames := dsSSLHostnames(ds)
+		hostname := ""
+		if req.Hostname != nil && *req.Hostname != "" {
+			hostname = strings.ToLower(*req.Hostname)
+		} else if len(hostnames) > 0 {
+			hostname = hostnames[0]
+		} else {
+			handleErr(http.StatusBadRequest, errors.New("'hostname' is required, because the delivery service has no host regex to take it from"))
+			return
+		}
+		sans := []string{hostname}
+		for _, name := range hostnames {
+			if name != hostname {
+				sans = append(sans, name)
+			}
+		}
+
+		cert, err := generateDSSSLCertificate(sslSubject(req, hostname), sans, cfg.SSLCertDays, caCert, caKey, time.Now())
+		if err != nil {
+			log.Errorln("generating delivery service " + xmlID + " SSL keys: " + err.Error())
+			handleErr(http.StatusInternalServerError, errors.New("generating SSL keys"))
+			return
+		}
+		keys := tc.DeliveryServiceSSLKeys{
+			CDN:             ds.CDN,
+			DeliveryService: xmlID,
+			Hostname:        hostname,
+			Key:             xmlID,
+			Version:         version,
+			Certificate:     cert,
+		}
+		for _, field := range []struct {
+			val *string
+			dst *string
+		}{{req.Country, &keys.Country}, {req.State, &keys.State}, {req.City, &keys.City}, {req.Organization, &keys.Organization}, {req.BusinessUnit, &keys.BusinessUnit}} {
+			if field.val != nil {
+				*field.dst = *field.val
+			}
+		}
+		keysJSON, err := json.Marshal(keys)
+		if err != nil {
+			handleErr(http.StatusInternalServerError, err)
+			return
+		}
+
+		store, err := secretsvc.Open(db, cfg)
+		if err != nil {
+			handleErr(http.StatusInternalServerError, err)
+			return
+		}
+		defer func() {
+			if err := store.Close(); err != nil {
+				log.Errorf("%v\n", err)
+			}
+		}()
+		for _, key := range []string{xmlID + "-" + strconv.Itoa(version), xmlID + "-latest"} {
+			if err := store.Save(SSLKeysBucket, key, keysJSON); err != nil {
+				log.Errorln("saving delivery service " + xmlID + " SSL keys: " + err.Error())
+				handleErr(http.StatusInternalServerError, errors.New("saving SSL keys"))
+				return
+			}
+		}
+
+		if _, err := tx.Exec(`UPDATE deliveryservice SET ssl_key_version = $1 WHERE id = $2`, version, ds.ID); err != nil {
+			log.Errorln("updating delivery service " + xmlID + " SSL key version: " + err.Error())
+			handleErr(http.StatusInternalServerError, tc.DBError)
+			return
+		}
+		if err := api.CreateChangeLogRawTx(api.ApiChange, "Created ssl keys for Delivery Service "+xmlID, *user, tx); err != nil {
+			handleErr(http.StatusInternalServerError, errors.New("writing change log: "+err.Error()))
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			handleErr(http.StatusInternalServerError, errors.New("committing transaction: "+err.Error()))
+			return
+		}
+		commitTx = true
+
+		resp := struct {
+			tc.DeliveryServiceSSLKeysResponse
+			tc.Alerts
+		}{tc.DeliveryServiceSSLKeysResponse{Response: keys}, tc.CreateAlerts(tc.SuccessLevel, "Successfully created ssl keys for "+xmlID)}
+		respBts, err := json.Marshal(resp)
+		if err != nil {
+			handleErr(http.StatusInternalServerError, err)
+			return
+		}
+		w.Header().Set(tc.ContentType, tc.ApplicationJson)
+		w.Write(respBts)
+	}
+}
@@ -0,0 +1,187 @@
+package main
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
+)
+
+func TestDSSSLHostnames(t *testing.T) {
+	ds := dsSSLInfo{
+		Type:   "HTTP",
+		Domain: "cdn.example.net",
+		HostRegexes: []string{
+			`.*\.demo1\..*`,
+			`.*\.demo1\..*`,
+			`demo\.Example\.com`,
+			`^(.*)\.other$`,
+		},
+	}
+	expected := []string{"*.demo1.cdn.example.net", "demo.example.com"}
+	if actual := dsSSLHostnames(ds); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("HTTP hostnames expected %v, actual %v", expected, actual)
+	}
+
+	ds.Type = "DNS"
+	expected = []string{"edge.demo1.cdn.example.net", "demo.example.com"}
+	if actual := dsSSLHostnames(ds); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("DNS hostnames without a routing name expected %v, actual %v", expected, actual)
+	}
+
+	ds.RoutingName = "video"
+	expected = []string{"video.demo1.cdn.example.net", "demo.example.com"}
+	if actual := dsSSLHostnames(ds); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("DNS hostnames expected %v, actual %v", expected, actual)
+	}
+}
+
+func decodeSSLPEM(t *testing.T, b64 string) []*pem.Block {
+	bts, err := base64.StdEncoding.DecodeString(b64)
+	if err != nil {
+		t.Fatalf("decoding base64: %v", err)
+	}
+	blocks := []*pem.Block{}
+	for {
+		block, rest := pem.Decode(bts)
+		if block == nil {
+			return blocks
+		}
+		blocks = append(blocks, block)
+		bts = rest
+	}
+}
+
+func TestGenerateDSSSLCertificateSelfSigned(t *testing.T) {
+	hostnames := []string{"*.demo1.cdn.example.net", "demo.example.com"}
+	subject := sslSubject(tc.DeliveryServiceSSLKeysGenerateReq{Country: strPtr("US"), Organization: strPtr("Example")}, hostnames[0])
+	now := time.Now()
+	keys, err := generateDSSSLCertificate(subject, hostnames, 365, nil, nil, now)
+	if err != nil {
+		t.Fatalf("generating certificate: %v", err)
+	}
+
+	crts := decodeSSLPEM(t, keys.Crt)
+	if len(crts) != 1 {
+		t.Fatalf("expected 1 self-signed certificate, actual %d", len(crts))
+	}
+	crt, err := x509.ParseCertificate(crts[0].Bytes)
+	if err != nil {
+		t.Fatalf("parsing certificate: %v", err)
+	}
+	if err := crt.CheckSignature(crt.SignatureAlgorithm, crt.RawTBSCertificate, crt.Signature); err != nil {
+		t.Errorf("expected a self-signed certificate, actual error %v", err)
+	}
+	if !reflect.DeepEqual(crt.DNSNames, hostnames) || crt.Subject.CommonName != hostnames[0] || !reflect.DeepEqual(crt.Subject.Country, []string{"US"}) {
+		t.Errorf("expected certificate for %v, actual subject %v names %v", hostnames, crt.Subject, crt.DNSNames)
+	}
+	if !crt.NotAfter.Equal(now.AddDate(0, 0, 365).Truncate(time.Second)) {
+		t.Errorf("expected certificate to expire %v, actual %v", now.AddDate(0, 0, 365), crt.NotAfter)
+	}
+
+	keyBlocks := decodeSSLPEM(t, keys.Key)
+	if len(keyBlocks) != 1 || keyBlocks[0].Type != "RSA PRIVATE KEY" {
+		t.Fatalf("expected 1 RSA private key, actual %d blocks", len(keyBlocks))
+	}
+	key, err := x509.ParsePKCS1PrivateKey(keyBlocks[0].Bytes)
+	if err != nil {
+		t.Fatalf("parsing key: %v", err)
+	}
+	if !reflect.DeepEqual(crt.PublicKey, &key.PublicKey) {
+		t.Error("expected the certificate to be for the generated key")
+	}
+
+	csrBlocks := decodeSSLPEM(t, keys.CSR)
+	if len(csrBlocks) != 1 {
+		t.Fatalf("expected 1 CSR, actual %d", len(csrBlocks))
+	}
+	csr, err := x509.ParseCertificateRequest(csrBlocks[0].Bytes)
+	if err != nil {
+		t.Fatalf("parsing CSR: %v", err)
+	}
+	if err := csr.CheckSignature(); err != nil {
+		t.Errorf("CSR signature: %v", err)
+	}
+	if !reflect.DeepEqual(csr.DNSNames, hostnames) || csr.Subject.CommonName != hostnames[0] {
+		t.Errorf("expected CSR for %v, actual subject %v names %v", hostnames, csr.Subject, csr.DNSNames)
+	}
+}
+
+func TestGenerateDSSSLCertificateCA(t *testing.T) {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Lab CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caCert, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hostnames := []string{"*.demo1.cdn.example.net"}
+	keys, err := generateDSSSLCertificate(pkix.Name{CommonName: hostnames[0]}, hostnames, 30, caCert, caKey, time.Now())
+	if err != nil {
+		t.Fatalf("generating certificate: %v", err)
+	}
+	crts := decodeSSLPEM(t, keys.Crt)
+	if len(crts) != 2 {
+		t.Fatalf("expected the certificate and CA, actual %d certificates", len(crts))
+	}
+
+	// the certificate must be accepted when added back, as an issued certificate would be.
+	caPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}))
+	chain, err := verifyAndEncodeCertificate(keys.Crt, caPEM)
+	if err != nil {
+		t.Fatalf("verifying CA issued certificate: %v", err)
+	}
+	chainPEM, err := base64.StdEncoding.DecodeString(chain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(chainPEM), "BEGIN CERTIFICATE") {
+		t.Errorf("expected the verified chain to contain the certificate, actual %q", chainPEM)
+	}
+}
+
+func strPtr(s string) *string { return &s }
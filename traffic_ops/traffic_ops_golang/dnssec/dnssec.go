@@ -0,0 +1,211 @@
+package dnssec
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-log"
+	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/secretsvc"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Bucket is the secret store bucket of DNSSEC keys, which are stored by CDN name.
+const Bucket = "dnssec"
+
+const (
+	// TTLParameter is the Traffic Router profile parameter of the DNSKEY TTL.
+	TTLParameter = "tld.ttls.DNSKEY"
+	// GenerationMultiplierParameter is the Traffic Router profile parameter of the generation window multiplier.
+	GenerationMultiplierParameter = "DNSKEY.generation.multiplier"
+	// EffectiveMultiplierParameter is the Traffic Router profile parameter of the effective window multiplier.
+	EffectiveMultiplierParameter = "DNSKEY.effective.multiplier"
+)
+
+type cdnInfo struct {
+	Name          string
+	Domain        string
+	DNSSECEnabled bool
+}
+
+// lockKeys begins a transaction holding the lock of the CDN's DNSSEC keys, which serializes changes to them by every Traffic Ops. The lock is released when the transaction ends. If wait is false and the keys are already locked, it returns false.
+func lockKeys(db *sqlx.DB, cdn string, wait bool) (*sqlx.Tx, bool, error) {
+	tx, err := db.Beginx()
+	if err != nil {
+		return nil, false, errors.New("beginning transaction: " + err.Error())
+	}
+	locked := true
+	if wait {
+		_, err = tx.Exec(`SELECT pg_advisory_xact_lock(hashtext($1))`, "dnssec:"+cdn)
+	} else {
+		err = tx.QueryRow(`SELECT pg_try_advisory_xact_lock(hashtext($1))`, "dnssec:"+cdn).Scan(&locked)
+	}
+	if err != nil || !locked {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			log.Errorln("rolling back DNSSEC key lock transaction: " + rbErr.Error())
+		}
+		if err != nil {
+			return nil, false, errors.New("locking DNSSEC keys: " + err.Error())
+		}
+		return nil, false, nil
+	}
+	return tx, true, nil
+}
+
+// fetchKeys returns the DNSSEC keys of the CDN, and false if it has none.
+func fetchKeys(store secretsvc.Store, cdn string) (tc.DNSSECKeys, bool, error) {
+	val, ok, err := store.Fetch(Bucket, cdn)
+	if err != nil {
+		return nil, false, errors.New("fetching DNSSEC keys: " + err.Error())
+	}
+	if !ok {
+		return nil, false, nil
+	}
+	keys := tc.DNSSECKeys{}
+	if err := json.Unmarshal(val, &keys); err != nil {
+		return nil, false, errors.New("decoding DNSSEC keys: " + err.Error())
+	}
+	return keys, true, nil
+}
+
+func saveKeys(store secretsvc.Store, cdn string, keys tc.DNSSECKeys) error {
+	val, err := json.Marshal(keys)
+	if err != nil {
+		return errors.New("encoding DNSSEC keys: " + err.Error())
+	}
+	if err := store.Save(Bucket, cdn, val); err != nil {
+		return errors.New("saving DNSSEC keys: " + err.Error())
+	}
+	return nil
+}
+
+// getCDN returns the CDN with the given name, and false if it doesn't exist.
+func getCDN(db sqlx.Queryer, name string) (cdnInfo, bool, error) {
+	cdn := cdnInfo{}
+	if err := db.QueryRowx(`SELECT name, domain_name, dnssec_enabled FROM cdn WHERE name = $1`, name).Scan(&cdn.Name, &cdn.Domain, &cdn.DNSSECEnabled); err != nil {
+		if err == sql.ErrNoRows {
+			return cdnInfo{}, false, nil
+		}
+		return cdnInfo{}, false, errors.New("querying CDN: " + err.Error())
+	}
+	return cdn, true, nil
+}
+
+// getDNSSECCDNs returns the CDNs with DNSSEC enabled.
+func getDNSSECCDNs(db sqlx.Queryer) ([]cdnInfo, error) {
+	rows, err := db.Query(`SELECT name, domain_name FROM cdn WHERE dnssec_enabled ORDER BY name`)
+	if err != nil {
+		return nil, errors.New("querying CDNs: " + err.Error())
+	}
+	defer rows.Close()
+	cdns := []cdnInfo{}
+	for rows.Next() {
+		cdn := cdnInfo{DNSSECEnabled: true}
+		if err := rows.Scan(&cdn.Name, &cdn.Domain); err != nil {
+			return nil, errors.New("scanning CDNs: " + err.Error())
+		}
+		cdns = append(cdns, cdn)
+	}
+	return cdns, rows.Err()
+}
+
+// getDSZones returns the delivery services of the CDN and their DNS zones, which are the first host regex of each delivery service, under the CDN domain.
+func getDSZones(db sqlx.Queryer, cdn string, domain string) ([]dsZone, error) {
+	q := `
+SELECT ds.xml_id, t.name, COALESCE((
+  SELECT r.pattern FROM deliveryservice_regex AS dsr
+  JOIN regex AS r ON r.id = dsr.regex
+  JOIN type AS rt ON rt.id = r.type
+  WHERE dsr.deliveryservice = ds.id
+  AND rt.name = 'HOST_REGEXP'
+  AND COALESCE(dsr.set_number, 0) = 0
+  ORDER BY r.id
+  LIMIT 1
+), '')
+FROM deliveryservice AS ds
+JOIN type AS t ON t.id = ds.type
+JOIN cdn ON cdn.id = ds.cdn_id
+WHERE cdn.name = $1
+ORDER BY ds.xml_id
+`
+	rows, err := db.Query(q, cdn)
+	if err != nil {
+		return nil, errors.New("querying delivery services: " + err.Error())
+	}
+	defer rows.Close()
+	patternToHostReplacer := strings.NewReplacer(`\`, ``, `.*`, ``, `.`, ``)
+	dses := []dsZone{}
+	for rows.Next() {
+		ds := dsZone{}
+		pattern := ""
+		if err := rows.Scan(&ds.XMLID, &ds.Type, &pattern); err != nil {
+			return nil, errors.New("scanning delivery services: " + err.Error())
+		}
+		if host := patternToHostReplacer.Replace(pattern); host != "" {
+			ds.Zone = FQDN(host + "." + domain)
+		}
+		dses = append(dses, ds)
+	}
+	return dses, rows.Err()
+}
+
+// getWindows returns the key rollover windows of the CDN, from the parameters of its Traffic Router profiles. Missing and invalid parameters are the defaults.
+func getWindows(db sqlx.Queryer, cdn string) (Windows, error) {
+	q := `
+SELECT p.name, p.value FROM parameter AS p
+JOIN profile_parameter AS pp ON pp.parameter = p.id
+JOIN profile AS pr ON pr.id = pp.profile
+WHERE p.name IN ($2, $3, $4)
+AND (pr.name LIKE 'CCR%' OR pr.name LIKE 'TR%')
+AND pr.id IN (SELECT s.profile FROM server AS s JOIN cdn ON cdn.id = s.cdn_id WHERE cdn.name = $1)
+ORDER BY pr.name
+`
+	rows, err := db.Query(q, cdn, TTLParameter, GenerationMultiplierParameter, EffectiveMultiplierParameter)
+	if err != nil {
+		return Windows{}, errors.New("querying DNSKEY parameters: " + err.Error())
+	}
+	defer rows.Close()
+	w := DefaultWindows()
+	fields := map[string]*int64{TTLParameter: &w.TTL, GenerationMultiplierParameter: &w.GenerationMultiplier, EffectiveMultiplierParameter: &w.EffectiveMultiplier}
+	for rows.Next() {
+		name, val := "", ""
+		if err := rows.Scan(&name, &val); err != nil {
+			return Windows{}, errors.New("scanning DNSKEY parameters: " + err.Error())
+		}
+		field, ok := fields[name]
+		if !ok {
+			continue // already set, from the first profile by name
+		}
+		delete(fields, name)
+		num, err := strconv.ParseInt(strings.TrimSpace(val), 10, 64)
+		if err != nil || num <= 0 {
+			log.Warnln("CDN " + cdn + " parameter " + name + " '" + val + "' is not a positive integer, using the default")
+			continue
+		}
+		*field = num
+	}
+	return w, rows.Err()
+}
@@ -0,0 +1,51 @@
+package dnssec
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestGetWindows(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+
+	rows := sqlmock.NewRows([]string{"name", "value"}).
+		AddRow(TTLParameter, "30").
+		AddRow(TTLParameter, "90").
+		AddRow(GenerationMultiplierParameter, "x")
+	mock.ExpectQuery("SELECT").WithArgs("cdn", TTLParameter, GenerationMultiplierParameter, EffectiveMultiplierParameter).WillReturnRows(rows)
+
+	w, err := getWindows(db, "cdn")
+	if err != nil {
+		t.Fatalf("expected nil error, actual %v", err)
+	}
+	if expected := (Windows{TTL: 30, GenerationMultiplier: GenerationMultiplierDefault, EffectiveMultiplier: EffectiveMultiplierDefault}); w != expected {
+		t.Errorf("expected %+v, actual %+v", expected, w)
+	}
+}
@@ -0,0 +1,503 @@
+package dnssec
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-log"
+	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
+	"github.com/apache/incubator-trafficcontrol/lib/go-util"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/config"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/secretsvc"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// openStore opens the secret store. If it's unavailable, it writes the error response and returns false.
+func openStore(db *sqlx.DB, cfg config.Config, handleErrs func(int, ...error)) (secretsvc.Store, bool) {
+	if !secretsvc.Enabled(cfg) {
+		handleErrs(http.StatusServiceUnavailable, secretsvc.ErrUnavailable)
+		return nil, false
+	}
+	store, err := secretsvc.Open(db, cfg)
+	if err != nil {
+		log.Errorln("opening secret store: " + err.Error())
+		handleErrs(http.StatusInternalServerError, secretsvc.ErrUnavailable)
+		return nil, false
+	}
+	return store, true
+}
+
+func closeStore(store secretsvc.Store) {
+	if err := store.Close(); err != nil {
+		log.Errorln("closing secret store: " + err.Error())
+	}
+}
+
+// rollback rolls back the transaction, unless it was committed.
+func rollback(tx *sqlx.Tx, committed *bool) {
+	if *committed {
+		return
+	}
+	if err := tx.Rollback(); err != nil {
+		log.Errorln("rolling back DNSSEC key transaction: " + err.Error())
+	}
+}
+
+func writeJSON(w http.ResponseWriter, handleErrs func(int, ...error), resp interface{}) {
+	respBts, err := json.Marshal(resp)
+	if err != nil {
+		handleErrs(http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set(tc.ContentType, tc.ApplicationJson)
+	w.Write(respBts)
+}
+
+// GetKeysHandler returns the DNSSEC keys of a CDN and its delivery services, including their private keys.
+func GetKeysHandler(db *sqlx.DB, cfg config.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		handleErrs := tc.GetHandleErrorsFunc(w, r)
+		params, err := api.GetCombinedParams(r)
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, err)
+			return
+		}
+		cdn := params["name"]
+		store, ok := openStore(db, cfg, handleErrs)
+		if !ok {
+			return
+		}
+		defer closeStore(store)
+
+		keys, ok, err := fetchKeys(store, cdn)
+		if err != nil {
+			log.Errorln("getting CDN " + cdn + " DNSSEC keys: " + err.Error())
+			handleErrs(http.StatusInternalServerError, errors.New("getting DNSSEC keys"))
+			return
+		}
+		if !ok {
+			handleErrs(http.StatusNotFound, errors.New("no DNSSEC keys for CDN "+cdn))
+			return
+		}
+		writeJSON(w, handleErrs, tc.DNSSECKeysResponse{Response: keys})
+	}
+}
+
+type generateParams struct {
+	CDN               string
+	Name              string
+	TTL               int64
+	KSKExpirationDays int64
+	ZSKExpirationDays int64
+	EffectiveDate     int64
+}
+
+// parseGenerateReq validates the request, returning its values, with the effective date defaulting to now.
+func parseGenerateReq(req tc.CDNDNSSECGenerateReq, now int64) (generateParams, error) {
+	errs := []error{}
+	p := generateParams{EffectiveDate: now}
+	if req.Key == nil || *req.Key == "" {
+		errs = append(errs, errors.New("'key' is required"))
+	} else {
+		p.CDN = *req.Key
+	}
+	if req.Name == nil || *req.Name == "" {
+		errs = append(errs, errors.New("'name' is required"))
+	} else {
+		p.Name = *req.Name
+	}
+	positive := func(field string, num *json.Number, val *int64) {
+		if num == nil {
+			errs = append(errs, errors.New("'"+field+"' is required"))
+			return
+		}
+		i, err := num.Int64()
+		if err != nil || i <= 0 {
+			errs = append(errs, errors.New("'"+field+"' must be a positive integer"))
+			return
+		}
+		*val = i
+	}
+	positive("ttl", req.TTL, &p.TTL)
+	positive("kskExpirationDays", req.KSKExpirationDays, &p.KSKExpirationDays)
+	positive("zskExpirationDays", req.ZSKExpirationDays, &p.ZSKExpirationDays)
+	if req.EffectiveDateUnix != nil {
+		positive("effectiveDate", req.EffectiveDateUnix, &p.EffectiveDate)
+	}
+	if len(errs) > 0 {
+		return generateParams{}, util.JoinErrs(errs)
+	}
+	return p, nil
+}
+
+// GenerateHandler generates new DNSSEC keys for a CDN, and each of its delivery services. The current keys are kept until the new keys are effective. Delivery services without a host regex, which have no DNS zone, are skipped.
+func GenerateHandler(db *sqlx.DB, cfg config.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		handleErrs := tc.GetHandleErrorsFunc(w, r)
+		user, err := auth.GetCurrentUser(r.Context())
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, err)
+			return
+		}
+		req := tc.CDNDNSSECGenerateReq{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			handleErrs(http.StatusBadRequest, errors.New("malformed JSON: "+err.Error()))
+			return
+		}
+		now := time.Now().Unix()
+		p, err := parseGenerateReq(req, now)
+		if err != nil {
+			handleErrs(http.StatusBadRequest, err)
+			return
+		}
+		store, ok := openStore(db, cfg, handleErrs)
+		if !ok {
+			return
+		}
+		defer closeStore(store)
+
+		tx, _, err := lockKeys(db, p.CDN, true)
+		if err != nil {
+			log.Errorln("generating CDN " + p.CDN + " DNSSEC keys: " + err.Error())
+			handleErrs(http.StatusInternalServerError, tc.DBError)
+			return
+		}
+		committed := false
+		defer rollback(tx, &committed)
+
+		cdn, ok, err := getCDN(tx, p.CDN)
+		if err != nil {
+			log.Errorln("generating CDN " + p.CDN + " DNSSEC keys: " + err.Error())
+			handleErrs(http.StatusInternalServerError, tc.DBError)
+			return
+		}
+		if !ok {
+			handleErrs(http.StatusNotFound, errors.New("no CDN "+p.CDN))
+			return
+		}
+		dses, err := getDSZones(tx, cdn.Name, cdn.Domain)
+		if err != nil {
+			log.Errorln("generating CDN " + p.CDN + " DNSSEC keys: " + err.Error())
+			handleErrs(http.StatusInternalServerError, tc.DBError)
+			return
+		}
+		oldKeys, _, err := fetchKeys(store, p.CDN)
+		if err != nil {
+			log.Errorln("generating CDN " + p.CDN + " DNSSEC keys: " + err.Error())
+			handleErrs(http.StatusInternalServerError, errors.New("getting DNSSEC keys"))
+			return
+		}
+
+		keys := tc.DNSSECKeys{}
+		keys[p.CDN], err = generateKeySet(p.Name, p.TTL, p.KSKExpirationDays, p.ZSKExpirationDays, p.EffectiveDate, now, true, oldKeys[p.CDN])
+		if err != nil {
+			log.Errorln("generating CDN " + p.CDN + " DNSSEC keys: " + err.Error())
+			handleErrs(http.StatusInternalServerError, errors.New("generating DNSSEC keys"))
+			return
+		}
+		for _, ds := range dses {
+			if !hasKeys(ds.Type) {
+				continue
+			}
+			if ds.Zone == "" {
+				log.Warnln("DNSSEC keys of delivery service " + ds.XMLID + " in CDN " + p.CDN + " can't be generated: it has no host regex")
+				continue
+			}
+			keys[ds.XMLID], err = generateKeySet(ds.Zone, p.TTL, p.KSKExpirationDays, p.ZSKExpirationDays, p.EffectiveDate, now, false, oldKeys[ds.XMLID])
+			if err != nil {
+				log.Errorln("generating delivery service " + ds.XMLID + " DNSSEC keys: " + err.Error())
+				handleErrs(http.StatusInternalServerError, errors.New("generating DNSSEC keys"))
+				return
+			}
+		}
+		if err := saveKeys(store, p.CDN, keys); err != nil {
+			log.Errorln("generating CDN " + p.CDN + " DNSSEC keys: " + err.Error())
+			handleErrs(http.StatusInternalServerError, errors.New("saving DNSSEC keys"))
+			return
+		}
+
+		if err := api.CreateChangeLogRawTx(api.ApiChange, "Generated DNSSEC keys for CDN "+p.CDN, *user, tx); err != nil {
+			handleErrs(http.StatusInternalServerError, errors.New("writing change log: "+err.Error()))
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			handleErrs(http.StatusInternalServerError, errors.New("committing transaction: "+err.Error()))
+			return
+		}
+		committed = true
+		writeJSON(w, handleErrs, struct {
+			Response string `json:"response"`
+		}{"Successfully created dnssec keys for " + p.CDN})
+	}
+}
+
+// DeleteHandler deletes the DNSSEC keys of a CDN and its delivery services.
+func DeleteHandler(db *sqlx.DB, cfg config.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		handleErrs := tc.GetHandleErrorsFunc(w, r)
+		params, err := api.GetCombinedParams(r)
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, err)
+			return
+		}
+		cdn := params["name"]
+		user, err := auth.GetCurrentUser(r.Context())
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, err)
+			return
+		}
+		store, ok := openStore(db, cfg, handleErrs)
+		if !ok {
+			return
+		}
+		defer closeStore(store)
+
+		tx, _, err := lockKeys(db, cdn, true)
+		if err != nil {
+			log.Errorln("deleting CDN " + cdn + " DNSSEC keys: " + err.Error())
+			handleErrs(http.StatusInternalServerError, tc.DBError)
+			return
+		}
+		committed := false
+		defer rollback(tx, &committed)
+
+		if err := store.Delete(Bucket, cdn); err != nil {
+			log.Errorln("deleting CDN " + cdn + " DNSSEC keys: " + err.Error())
+			handleErrs(http.StatusInternalServerError, errors.New("deleting DNSSEC keys"))
+			return
+		}
+		if err := api.CreateChangeLogRawTx(api.ApiChange, "Deleted DNSSEC keys for CDN "+cdn, *user, tx); err != nil {
+			handleErrs(http.StatusInternalServerError, errors.New("writing change log: "+err.Error()))
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			handleErrs(http.StatusInternalServerError, errors.New("committing transaction: "+err.Error()))
+			return
+		}
+		committed = true
+		writeJSON(w, handleErrs, struct {
+			Response string `json:"response"`
+		}{"Successfully deleted dnssec keys for " + cdn})
+	}
+}
+
+// GenerateKSKHandler rolls over the key signing key of a CDN's top level domain. The replaced key expires when the new key is effective, so the DS records of both keys should be in the parent zone until then.
+func GenerateKSKHandler(db *sqlx.DB, cfg config.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		handleErrs := tc.GetHandleErrorsFunc(w, r)
+		params, err := api.GetCombinedParams(r)
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, err)
+			return
+		}
+		cdn := params["name"]
+		user, err := auth.GetCurrentUser(r.Context())
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, err)
+			return
+		}
+		req := tc.CDNDNSSECKSKGenerateReq{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+			handleErrs(http.StatusBadRequest, errors.New("malformed JSON: "+err.Error()))
+			return
+		}
+		now := time.Now().Unix()
+		expirationDays, effective := int64(0), now
+		if req.ExpirationDays != nil {
+			if *req.ExpirationDays <= 0 {
+				handleErrs(http.StatusBadRequest, errors.New("'expirationDays' must be a positive integer"))
+				return
+			}
+			expirationDays = *req.ExpirationDays
+		}
+		if req.EffectiveDateUnix != nil {
+			if *req.EffectiveDateUnix <= 0 {
+				handleErrs(http.StatusBadRequest, errors.New("'effectiveDate' must be a positive integer"))
+				return
+			}
+			effective = *req.EffectiveDateUnix
+		}
+		store, ok := openStore(db, cfg, handleErrs)
+		if !ok {
+			return
+		}
+		defer closeStore(store)
+
+		tx, _, err := lockKeys(db, cdn, true)
+		if err != nil {
+			log.Errorln("generating CDN " + cdn + " KSK: " + err.Error())
+			handleErrs(http.StatusInternalServerError, tc.DBError)
+			return
+		}
+		committed := false
+		defer rollback(tx, &committed)
+
+		keys, ok, err := fetchKeys(store, cdn)
+		if err != nil {
+			log.Errorln("generating CDN " + cdn + " KSK: " + err.Error())
+			handleErrs(http.StatusInternalServerError, errors.New("getting DNSSEC keys"))
+			return
+		}
+		if _, cdnOK := keys[cdn]; !ok || !cdnOK {
+			handleErrs(http.StatusNotFound, errors.New("no DNSSEC keys for CDN "+cdn+", which must be generated before its KSK can be rolled over"))
+			return
+		}
+		set, err := rollOver(keys[cdn], true, effective, now, expirationDays, true, true)
+		if err != nil {
+			handleErrs(http.StatusBadRequest, errors.New("rolling over KSK of CDN "+cdn+": "+err.Error()))
+			return
+		}
+		keys[cdn] = set
+		records, err := dsRecords(set, DigestTypeSHA256, now)
+		if err != nil {
+			log.Errorln("generating CDN " + cdn + " KSK: " + err.Error())
+			handleErrs(http.StatusInternalServerError, errors.New("creating DS records"))
+			return
+		}
+		if err := saveKeys(store, cdn, keys); err != nil {
+			log.Errorln("generating CDN " + cdn + " KSK: " + err.Error())
+			handleErrs(http.StatusInternalServerError, errors.New("saving DNSSEC keys"))
+			return
+		}
+
+		if err := api.CreateChangeLogRawTx(api.ApiChange, "Generated KSK for CDN "+cdn, *user, tx); err != nil {
+			handleErrs(http.StatusInternalServerError, errors.New("writing change log: "+err.Error()))
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			handleErrs(http.StatusInternalServerError, errors.New("committing transaction: "+err.Error()))
+			return
+		}
+		committed = true
+		writeJSON(w, handleErrs, struct {
+			tc.CDNDNSSECDSRecordsResponse
+			tc.Alerts
+		}{tc.CDNDNSSECDSRecordsResponse{Response: records}, tc.CreateAlerts(tc.SuccessLevel, "Generated KSK for CDN "+cdn+". Its DS record should be added to the parent zone.")})
+	}
+}
+
+// dsRecords returns the DS records of the unexpired key signing keys of the set.
+func dsRecords(set tc.DNSSECKeySet, digestType int, now int64) ([]tc.CDNDNSSECDSRecord, error) {
+	records := []tc.CDNDNSSECDSRecord{}
+	for _, key := range set.KSK {
+		if key.ExpirationDateUnix <= now {
+			continue
+		}
+		dnskey, err := ParseStoredKey(key)
+		if err != nil {
+			return nil, errors.New("parsing KSK " + key.Name + ": " + err.Error())
+		}
+		digest, err := dnskey.Digest(digestType)
+		if err != nil {
+			return nil, err
+		}
+		ttl, err := key.TTL.Int64()
+		if err != nil {
+			ttl = dnskey.TTL
+		}
+		name := FQDN(key.Name)
+		rec := tc.CDNDNSSECDSRecord{
+			Name:               name,
+			TTL:                ttl,
+			KeyTag:             dnskey.KeyTag(),
+			Algorithm:          dnskey.Algorithm,
+			DigestType:         digestType,
+			Digest:             digest,
+			Status:             key.Status,
+			EffectiveDateUnix:  key.EffectiveDateUnix,
+			ExpirationDateUnix: key.ExpirationDateUnix,
+		}
+		rec.Text = name + " " + strconv.FormatInt(ttl, 10) + " IN DS " + strconv.Itoa(rec.KeyTag) + " " + strconv.Itoa(rec.Algorithm) + " " + strconv.Itoa(digestType) + " " + digest
+		records = append(records, rec)
+	}
+	return records, nil
+}
+
+// DSRecordsHandler returns the DS records of a CDN's top level domain, to be published in the parent zone. These are the records of every key signing key which hasn't expired, including keys being rolled over to or from. The digest type is SHA-256, unless the digestType parameter is 1 for SHA-1.
+func DSRecordsHandler(db *sqlx.DB, cfg config.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		handleErrs := tc.GetHandleErrorsFunc(w, r)
+		params, err := api.GetCombinedParams(r)
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, err)
+			return
+		}
+		cdn := params["name"]
+		digestType := DigestTypeSHA256
+		if dtStr, ok := params["digestType"]; ok {
+			if digestType, err = strconv.Atoi(dtStr); err != nil || (digestType != DigestTypeSHA1 && digestType != DigestTypeSHA256) {
+				handleErrs(http.StatusBadRequest, errors.New("'digestType' must be 1 (SHA-1) or 2 (SHA-256)"))
+				return
+			}
+		}
+		store, ok := openStore(db, cfg, handleErrs)
+		if !ok {
+			return
+		}
+		defer closeStore(store)
+
+		keys, ok, err := fetchKeys(store, cdn)
+		if err != nil {
+			log.Errorln("getting CDN " + cdn + " DS records: " + err.Error())
+			handleErrs(http.StatusInternalServerError, errors.New("getting DNSSEC keys"))
+			return
+		}
+		if _, cdnOK := keys[cdn]; !ok || !cdnOK {
+			handleErrs(http.StatusNotFound, errors.New("no DNSSEC keys for CDN "+cdn))
+			return
+		}
+		records, err := dsRecords(keys[cdn], digestType, time.Now().Unix())
+		if err != nil {
+			log.Errorln("getting CDN " + cdn + " DS records: " + err.Error())
+			handleErrs(http.StatusInternalServerError, errors.New("creating DS records"))
+			return
+		}
+		writeJSON(w, handleErrs, tc.CDNDNSSECDSRecordsResponse{Response: records})
+	}
+}
+
+// RefreshHandler starts a refresh of the DNSSEC keys of every CDN, in the background, since generating keys can take minutes.
+func RefreshHandler(db *sqlx.DB, cfg config.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		handleErrs := tc.GetHandleErrorsFunc(w, r)
+		if !secretsvc.Enabled(cfg) {
+			handleErrs(http.StatusServiceUnavailable, secretsvc.ErrUnavailable)
+			return
+		}
+		go func() {
+			if err := Refresh(db, cfg); err != nil {
+				log.Errorln("refreshing DNSSEC keys: " + err.Error())
+			}
+		}()
+		w.Header().Set(tc.ContentType, tc.ApplicationJson)
+		w.WriteHeader(http.StatusAccepted)
+		respBts, _ := json.Marshal(tc.CreateAlerts(tc.SuccessLevel, "Started refreshing DNSSEC keys."))
+		w.Write(respBts)
+	}
+}
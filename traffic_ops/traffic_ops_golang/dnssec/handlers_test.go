@@ -0,0 +1,68 @@
+package dnssec
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
+)
+
+func TestDSRecords(t *testing.T) {
+	k, err := ParseDNSKey(rfc4034Key)
+	if err != nil {
+		t.Fatalf("expected nil error, actual %v", err)
+	}
+	public := tc.DNSSECKey{Name: "dskey.example.com.", TTL: "86400", Status: tc.DNSSECKeyStatusNew, EffectiveDateUnix: 10, ExpirationDateUnix: 200, Public: base64.StdEncoding.EncodeToString([]byte(k.String()))}
+	expired := public
+	expired.Status = tc.DNSSECKeyStatusExpired
+	expired.ExpirationDateUnix = 100
+	records, err := dsRecords(tc.DNSSECKeySet{KSK: []tc.DNSSECKey{public, expired}}, DigestTypeSHA1, 100)
+	if err != nil {
+		t.Fatalf("expected nil error, actual %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("expected 1 unexpired DS record, actual %v", len(records))
+	}
+	expected := "dskey.example.com. 86400 IN DS 60485 5 1 2bb183af5f22588179a53b0a98631fad1a292118"
+	if records[0].Text != expected || records[0].KeyTag != 60485 || records[0].Status != tc.DNSSECKeyStatusNew {
+		t.Errorf("expected '%v', actual %+v", expected, records[0])
+	}
+}
+
+func TestParseGenerateReq(t *testing.T) {
+	cdn, name, ttl, ksk, zsk := "cdn", "cdn.example.net", json.Number("60"), json.Number("365"), json.Number("30")
+	p, err := parseGenerateReq(tc.CDNDNSSECGenerateReq{Key: &cdn, Name: &name, TTL: &ttl, KSKExpirationDays: &ksk, ZSKExpirationDays: &zsk}, 42)
+	if err != nil {
+		t.Fatalf("expected nil error, actual %v", err)
+	}
+	if p != (generateParams{CDN: "cdn", Name: "cdn.example.net", TTL: 60, KSKExpirationDays: 365, ZSKExpirationDays: 30, EffectiveDate: 42}) {
+		t.Errorf("expected params with effective date now, actual %+v", p)
+	}
+
+	negative := json.Number("-1")
+	_, err = parseGenerateReq(tc.CDNDNSSECGenerateReq{Key: &cdn, TTL: &negative, KSKExpirationDays: &ksk}, 42)
+	expected := "'name' is required, 'ttl' must be a positive integer, 'zskExpirationDays' is required"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected error '%v', actual %v", expected, err)
+	}
+}
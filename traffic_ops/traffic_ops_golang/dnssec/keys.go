@@ -0,0 +1,285 @@
+package dnssec
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"hash"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
+)
+
+const (
+	// Algorithm is the DNSSEC algorithm of generated keys, RSASHA1, which is the algorithm Traffic Router signs with.
+	Algorithm = 5
+	// AlgorithmName is the mnemonic of Algorithm, used in the BIND private key format.
+	AlgorithmName = "RSASHA1"
+	// Protocol is the DNSKEY protocol, which must be 3 (RFC 4034 section 2.1.2).
+	Protocol = 3
+	// FlagsZSK are the DNSKEY flags of a zone signing key, the zone key flag.
+	FlagsZSK = 256
+	// FlagsKSK are the DNSKEY flags of a key signing key, the zone key and secure entry point flags.
+	FlagsKSK = 257
+	// ZSKBits is the size of generated zone signing keys.
+	ZSKBits = 1024
+	// KSKBits is the size of generated key signing keys.
+	KSKBits = 2048
+	// DigestTypeSHA1 is the DS digest type of SHA-1.
+	DigestTypeSHA1 = 1
+	// DigestTypeSHA256 is the DS digest type of SHA-256, the digest type of stored DS records.
+	DigestTypeSHA256 = 2
+)
+
+// KeyParams are the parameters of a key to generate. Dates are Unix epoch seconds.
+type KeyParams struct {
+	Name           string
+	TTL            int64
+	KSK            bool
+	InceptionDate  int64
+	ExpirationDate int64
+	EffectiveDate  int64
+	// DSRecord is whether to include the key's DS record, which is stored with the key signing keys of CDN top level domains.
+	DSRecord bool
+}
+
+// GenerateKey generates a new RSA key pair, in the form stored in the secret store.
+func GenerateKey(p KeyParams) (tc.DNSSECKey, error) {
+	flags, bits := FlagsZSK, ZSKBits
+	if p.KSK {
+		flags, bits = FlagsKSK, KSKBits
+	}
+	priv, err := rsa.GenerateKey(rand.Reader, bits)
+	if err != nil {
+		return tc.DNSSECKey{}, errors.New("generating RSA key: " + err.Error())
+	}
+	name := FQDN(p.Name)
+	dnskey := DNSKey{Name: name, TTL: p.TTL, Flags: flags, Protocol: Protocol, Algorithm: Algorithm, PublicKey: rsaPublicKey(&priv.PublicKey)}
+	key := tc.DNSSECKey{
+		InceptionDateUnix:  p.InceptionDate,
+		ExpirationDateUnix: p.ExpirationDate,
+		Name:               name,
+		TTL:                json.Number(strconv.FormatInt(p.TTL, 10)),
+		Status:             tc.DNSSECKeyStatusNew,
+		EffectiveDateUnix:  p.EffectiveDate,
+		Private:            base64.StdEncoding.EncodeToString([]byte(privateKeyText(priv))),
+		Public:             base64.StdEncoding.EncodeToString([]byte(dnskey.String())),
+	}
+	if p.DSRecord {
+		digest, err := dnskey.Digest(DigestTypeSHA256)
+		if err != nil {
+			return tc.DNSSECKey{}, errors.New("creating DS record: " + err.Error())
+		}
+		key.DSRecord = &tc.DNSSECKeyDSRecord{Algorithm: Algorithm, DigestType: DigestTypeSHA256, Digest: digest}
+	}
+	return key, nil
+}
+
+// rsaPublicKey returns the DNSKEY public key field of an RSA key (RFC 3110 section 2).
+func rsaPublicKey(pub *rsa.PublicKey) []byte {
+	e := big.NewInt(int64(pub.E)).Bytes()
+	n := pub.N.Bytes()
+	b := make([]byte, 0, 3+len(e)+len(n))
+	if len(e) <= 255 {
+		b = append(b, byte(len(e)))
+	} else {
+		b = append(b, 0, byte(len(e)>>8), byte(len(e)))
+	}
+	b = append(b, e...)
+	return append(b, n...)
+}
+
+// privateKeyText returns the private key in the BIND private key format, which Traffic Router reads.
+func privateKeyText(k *rsa.PrivateKey) string {
+	b64 := func(i *big.Int) string { return base64.StdEncoding.EncodeToString(i.Bytes()) }
+	return "Private-key-format: v1.2\n" +
+		"Algorithm: " + strconv.Itoa(Algorithm) + " (" + AlgorithmName + ")\n" +
+		"Modulus: " + b64(k.N) + "\n" +
+		"PublicExponent: " + b64(big.NewInt(int64(k.E))) + "\n" +
+		"PrivateExponent: " + b64(k.D) + "\n" +
+		"Prime1: " + b64(k.Primes[0]) + "\n" +
+		"Prime2: " + b64(k.Primes[1]) + "\n" +
+		"Exponent1: " + b64(k.Precomputed.Dp) + "\n" +
+		"Exponent2: " + b64(k.Precomputed.Dq) + "\n" +
+		"Coefficient: " + b64(k.Precomputed.Qinv) + "\n"
+}
+
+// DNSKey is a DNSKEY resource record.
+type DNSKey struct {
+	Name      string
+	TTL       int64
+	Flags     int
+	Protocol  int
+	Algorithm int
+	PublicKey []byte
+}
+
+// String returns the record in the zone file format.
+func (k DNSKey) String() string {
+	return k.Name + " " + strconv.FormatInt(k.TTL, 10) + " IN DNSKEY " + strconv.Itoa(k.Flags) + " " + strconv.Itoa(k.Protocol) + " " + strconv.Itoa(k.Algorithm) + " " + base64.StdEncoding.EncodeToString(k.PublicKey)
+}
+
+// RData returns the wire format RDATA of the record (RFC 4034 section 2.1).
+func (k DNSKey) RData() []byte {
+	b := make([]byte, 4, 4+len(k.PublicKey))
+	binary.BigEndian.PutUint16(b, uint16(k.Flags))
+	b[2] = byte(k.Protocol)
+	b[3] = byte(k.Algorithm)
+	return append(b, k.PublicKey...)
+}
+
+// KeyTag returns the key tag of the key, which identifies it in DS and RRSIG records (RFC 4034 appendix B).
+func (k DNSKey) KeyTag() int {
+	ac := 0
+	for i, b := range k.RData() {
+		if i&1 == 0 {
+			ac += int(b) << 8
+		} else {
+			ac += int(b)
+		}
+	}
+	ac += ac >> 16 & 0xFFFF
+	return ac & 0xFFFF
+}
+
+// Digest returns the hex digest of the DS record of the key, of the given digest type (RFC 4034 section 5.1.4).
+func (k DNSKey) Digest(digestType int) (string, error) {
+	var h hash.Hash
+	switch digestType {
+	case DigestTypeSHA1:
+		h = sha1.New()
+	case DigestTypeSHA256:
+		h = sha256.New()
+	default:
+		return "", errors.New("unsupported digest type " + strconv.Itoa(digestType))
+	}
+	owner, err := wireName(k.Name)
+	if err != nil {
+		return "", err
+	}
+	h.Write(owner)
+	h.Write(k.RData())
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// wireName returns the canonical wire format of a domain name, which is lower case (RFC 4034 section 6.2).
+func wireName(name string) ([]byte, error) {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	b := []byte{}
+	if name != "" {
+		for _, label := range strings.Split(name, ".") {
+			if len(label) == 0 || len(label) > 63 {
+				return nil, errors.New("malformed domain name '" + name + "'")
+			}
+			b = append(b, byte(len(label)))
+			b = append(b, label...)
+		}
+	}
+	b = append(b, 0)
+	if len(b) > 255 {
+		return nil, errors.New("domain name '" + name + "' is too long")
+	}
+	return b, nil
+}
+
+// FQDN returns the name with a trailing period, which is how key names are stored.
+func FQDN(name string) string {
+	if strings.HasSuffix(name, ".") {
+		return name
+	}
+	return name + "."
+}
+
+// ParseDNSKey parses a DNSKEY record in the zone file format. Besides the single line format keys are generated in, it accepts the parenthesized and commented forms written by other DNS tools, such as the Perl Traffic Ops. The owner name is required, and the TTL and class are optional.
+func ParseDNSKey(s string) (DNSKey, error) {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if comment := strings.Index(line, ";"); comment >= 0 {
+			lines[i] = line[:comment]
+		}
+	}
+	fields := strings.Fields(strings.NewReplacer("(", " ", ")", " ").Replace(strings.Join(lines, " ")))
+	if len(fields) == 0 {
+		return DNSKey{}, errors.New("malformed DNSKEY record: empty")
+	}
+	k := DNSKey{Name: fields[0]}
+	fields = fields[1:]
+	for len(fields) > 0 && !strings.EqualFold(fields[0], "DNSKEY") {
+		if strings.EqualFold(fields[0], "IN") {
+			fields = fields[1:]
+			continue
+		}
+		ttl, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			return DNSKey{}, errors.New("malformed DNSKEY record: unexpected '" + fields[0] + "'")
+		}
+		k.TTL = ttl
+		fields = fields[1:]
+	}
+	if len(fields) < 5 {
+		return DNSKey{}, errors.New("malformed DNSKEY record: missing fields")
+	}
+	nums := [3]int{}
+	for i, max := range [3]int{0xFFFF, 0xFF, 0xFF} {
+		num, err := strconv.Atoi(fields[1+i])
+		if err != nil || num < 0 || num > max {
+			return DNSKey{}, errors.New("malformed DNSKEY record: invalid number '" + fields[1+i] + "'")
+		}
+		nums[i] = num
+	}
+	k.Flags, k.Protocol, k.Algorithm = nums[0], nums[1], nums[2]
+	pub, err := base64.StdEncoding.DecodeString(strings.Join(fields[4:], ""))
+	if err != nil {
+		return DNSKey{}, errors.New("malformed DNSKEY record: public key: " + err.Error())
+	}
+	k.PublicKey = pub
+	return k, nil
+}
+
+// ParseStoredKey parses the DNSKEY record of a stored key.
+func ParseStoredKey(key tc.DNSSECKey) (DNSKey, error) {
+	public, err := decodeStoredBase64(key.Public)
+	if err != nil {
+		return DNSKey{}, errors.New("decoding public key: " + err.Error())
+	}
+	return ParseDNSKey(string(public))
+}
+
+// decodeStoredBase64 decodes a base64 key field. Keys generated by the Perl Traffic Ops have line breaks in their base64, which Traffic Router ignores.
+func decodeStoredBase64(s string) ([]byte, error) {
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, s)
+	return base64.StdEncoding.DecodeString(s)
+}
@@ -0,0 +1,146 @@
+package dnssec
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"encoding/base64"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
+)
+
+// rfc4034Key is the DNSKEY of the DS example in RFC 4034 section 5.4, in the multi-line format.
+const rfc4034Key = `dskey.example.com. 86400 IN DNSKEY 256 3 5 ( AQOeiiR0GOMYkDshWoSKz9Xz
+                                             fwJr1AYtsmx3TGkJaNXVbfi/
+                                             2pHm822aJ5iI9BMzNXxeYCmZ
+                                             DRD99WYwYqUSdjMmmAphXdvx
+                                             egXd/M5+X7OrzKBaMbCVdFLU
+                                             Uh6DhweJBjEVv5f2wwjM9Xzc
+                                             nOf+EPbtG9DMBmADjFDc2w/r
+                                             ljwvFw==
+                                             ) ;  key id = 60485`
+
+func TestParseDNSKeyDS(t *testing.T) {
+	k, err := ParseDNSKey(rfc4034Key)
+	if err != nil {
+		t.Fatalf("expected nil error, actual %v", err)
+	}
+	if k.Name != "dskey.example.com." || k.TTL != 86400 || k.Flags != 256 || k.Protocol != 3 || k.Algorithm != 5 {
+		t.Errorf("expected dskey.example.com. 86400 256 3 5, actual %+v", k)
+	}
+	if tag := k.KeyTag(); tag != 60485 {
+		t.Errorf("expected key tag 60485, actual %v", tag)
+	}
+	digest, err := k.Digest(DigestTypeSHA1)
+	if err != nil {
+		t.Fatalf("expected nil error, actual %v", err)
+	}
+	if expected := "2BB183AF5F22588179A53B0A98631FAD1A292118"; !strings.EqualFold(digest, expected) {
+		t.Errorf("expected digest %v, actual %v", expected, digest)
+	}
+	if _, err := k.Digest(4); err == nil {
+		t.Errorf("expected unsupported digest type error, actual nil")
+	}
+}
+
+func TestParseDNSKeyInvalid(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"example.com. 60 IN DNSKEY 257 3",
+		"example.com. sixty IN DNSKEY 257 3 5 AwEAAQ==",
+		"example.com. 60 IN DNSKEY 65536 3 5 AwEAAQ==",
+		"example.com. 60 IN DNSKEY 257 3 5 not-base64",
+	} {
+		if _, err := ParseDNSKey(s); err == nil {
+			t.Errorf("expected error parsing '%v', actual nil", s)
+		}
+	}
+}
+
+func TestGenerateKey(t *testing.T) {
+	key, err := GenerateKey(KeyParams{Name: "cdn.example.net", TTL: 60, KSK: true, InceptionDate: 1000, ExpirationDate: 2000, EffectiveDate: 1500, DSRecord: true})
+	if err != nil {
+		t.Fatalf("expected nil error, actual %v", err)
+	}
+	if key.Name != "cdn.example.net." || key.TTL.String() != "60" || key.Status != tc.DNSSECKeyStatusNew || key.InceptionDateUnix != 1000 || key.ExpirationDateUnix != 2000 || key.EffectiveDateUnix != 1500 {
+		t.Errorf("expected key fields from params, actual %+v", key)
+	}
+
+	dnskey, err := ParseStoredKey(key)
+	if err != nil {
+		t.Fatalf("expected nil error parsing public key, actual %v", err)
+	}
+	if dnskey.Name != "cdn.example.net." || dnskey.TTL != 60 || dnskey.Flags != FlagsKSK || dnskey.Protocol != Protocol || dnskey.Algorithm != Algorithm {
+		t.Errorf("expected KSK DNSKEY record, actual %+v", dnskey)
+	}
+	if key.DSRecord == nil {
+		t.Fatalf("expected DS record, actual nil")
+	}
+	digest, _ := dnskey.Digest(DigestTypeSHA256)
+	if *key.DSRecord != (tc.DNSSECKeyDSRecord{Algorithm: Algorithm, DigestType: DigestTypeSHA256, Digest: digest}) {
+		t.Errorf("expected DS record digest %v, actual %+v", digest, *key.DSRecord)
+	}
+
+	// the public key is the exponent length, exponent, and modulus, which must match the private key's
+	expLen := int(dnskey.PublicKey[0])
+	modulus := new(big.Int).SetBytes(dnskey.PublicKey[1+expLen:])
+	if modulus.BitLen() != KSKBits {
+		t.Errorf("expected %v bit modulus, actual %v", KSKBits, modulus.BitLen())
+	}
+	private, err := base64.StdEncoding.DecodeString(key.Private)
+	if err != nil {
+		t.Fatalf("expected nil error decoding private key, actual %v", err)
+	}
+	fields := map[string]string{}
+	for _, line := range strings.Split(strings.TrimSpace(string(private)), "\n") {
+		kv := strings.SplitN(line, ": ", 2)
+		if len(kv) != 2 {
+			t.Fatalf("expected private key line 'name: value', actual '%v'", line)
+		}
+		fields[kv[0]] = kv[1]
+	}
+	if fields["Private-key-format"] != "v1.2" || fields["Algorithm"] != "5 (RSASHA1)" {
+		t.Errorf("expected BIND v1.2 RSASHA1 private key, actual %v", fields)
+	}
+	for _, name := range []string{"PublicExponent", "PrivateExponent", "Prime1", "Prime2", "Exponent1", "Exponent2", "Coefficient"} {
+		if _, err := base64.StdEncoding.DecodeString(fields[name]); err != nil || fields[name] == "" {
+			t.Errorf("expected private key %v base64, actual '%v'", name, fields[name])
+		}
+	}
+	privModulus, _ := base64.StdEncoding.DecodeString(fields["Modulus"])
+	if new(big.Int).SetBytes(privModulus).Cmp(modulus) != 0 {
+		t.Errorf("expected private key modulus to match public key")
+	}
+}
+
+func TestParseStoredKeyLineBreaks(t *testing.T) {
+	// the Perl Traffic Ops stored base64 with line breaks
+	public := base64.StdEncoding.EncodeToString([]byte(strings.Replace(rfc4034Key, "\n", " ", -1)))
+	public = public[:40] + "\n" + public[40:]
+	k, err := ParseStoredKey(tc.DNSSECKey{Public: public})
+	if err != nil {
+		t.Fatalf("expected nil error, actual %v", err)
+	}
+	if k.KeyTag() != 60485 {
+		t.Errorf("expected key tag 60485, actual %v", k.KeyTag())
+	}
+}
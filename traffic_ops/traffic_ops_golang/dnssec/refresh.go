@@ -0,0 +1,111 @@
+package dnssec
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"errors"
+	"time"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-log"
+	"github.com/apache/incubator-trafficcontrol/lib/go-util"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/config"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/secretsvc"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// StartRefresh refreshes the DNSSEC keys of every CDN at the configured interval, until the process exits. It does nothing if the refresh is disabled, or the secret store is unavailable.
+func StartRefresh(db *sqlx.DB, cfg config.Config) {
+	interval := cfg.DNSSECRefreshInterval()
+	if interval == 0 || !secretsvc.Enabled(cfg) {
+		return
+	}
+	go func() {
+		for range time.Tick(interval) {
+			if err := Refresh(db, cfg); err != nil {
+				log.Errorln("refreshing DNSSEC keys: " + err.Error())
+			}
+		}
+	}()
+}
+
+// Refresh generates the keys of new delivery services, and rolls over keys about to expire, in every CDN with DNSSEC enabled. CDNs whose keys are locked, by a request changing them or a refresh by another Traffic Ops, are skipped.
+func Refresh(db *sqlx.DB, cfg config.Config) error {
+	cdns, err := getDNSSECCDNs(db)
+	if err != nil {
+		return err
+	}
+	store, err := secretsvc.Open(db, cfg)
+	if err != nil {
+		return errors.New("opening secret store: " + err.Error())
+	}
+	defer closeStore(store)
+
+	log.Debugln("refreshing DNSSEC keys")
+	errs := []error{}
+	for _, cdn := range cdns {
+		if err := refreshCDN(db, store, cdn); err != nil {
+			errs = append(errs, errors.New("CDN "+cdn.Name+": "+err.Error()))
+		}
+	}
+	log.Debugln("done refreshing DNSSEC keys")
+	return util.JoinErrs(errs)
+}
+
+func refreshCDN(db *sqlx.DB, store secretsvc.Store, cdn cdnInfo) error {
+	tx, ok, err := lockKeys(db, cdn.Name, false)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		log.Infoln("DNSSEC keys of CDN " + cdn.Name + " are locked, skipping refresh")
+		return nil
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil { // nothing is written, the transaction only holds the lock
+			log.Errorln("rolling back DNSSEC key refresh transaction: " + err.Error())
+		}
+	}()
+
+	keys, ok, err := fetchKeys(store, cdn.Name)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		log.Warnln("CDN " + cdn.Name + " has DNSSEC enabled, but no DNSSEC keys")
+		return nil
+	}
+	w, err := getWindows(tx, cdn.Name)
+	if err != nil {
+		return err
+	}
+	dses, err := getDSZones(tx, cdn.Name, cdn.Domain)
+	if err != nil {
+		return err
+	}
+	changed, err := refreshKeys(keys, cdn.Name, dses, w, time.Now().Unix())
+	if err != nil {
+		return err
+	}
+	if !changed {
+		return nil
+	}
+	return saveKeys(store, cdn.Name, keys)
+}
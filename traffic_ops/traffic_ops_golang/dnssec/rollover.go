@@ -0,0 +1,214 @@
+package dnssec
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-log"
+	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
+)
+
+const (
+	// TTLDefault is the DNSKEY TTL used if the CDN's Traffic Router profile has no tld.ttls.DNSKEY parameter.
+	TTLDefault = 60
+	// GenerationMultiplierDefault ...
+	GenerationMultiplierDefault = 10
+	// EffectiveMultiplierDefault ...
+	EffectiveMultiplierDefault = 10
+	// KSKExpirationDaysDefault is the lifetime of delivery service key signing keys generated by the refresh, if the CDN has no key signing key to take it from.
+	KSKExpirationDaysDefault = 365
+	// ZSKExpirationDaysDefault is the lifetime of delivery service zone signing keys generated by the refresh, if the CDN has no zone signing key to take it from.
+	ZSKExpirationDaysDefault = 30
+)
+
+const secondsPerDay = 86400
+
+// Windows are the key rollover windows of a CDN, from the parameters of its Traffic Router profile.
+type Windows struct {
+	// TTL is the DNSKEY TTL in seconds, the tld.ttls.DNSKEY parameter.
+	TTL int64
+	// GenerationMultiplier is the DNSKEY.generation.multiplier parameter. Keys are rolled over when they expire within TTL times GenerationMultiplier seconds.
+	GenerationMultiplier int64
+	// EffectiveMultiplier is the DNSKEY.effective.multiplier parameter. Rolled over keys become effective TTL times EffectiveMultiplier seconds before the keys they replace expire, so resolvers have the new DNSKEY before it's used.
+	EffectiveMultiplier int64
+}
+
+// DefaultWindows returns the windows of a CDN whose Traffic Router profile has none of the parameters.
+func DefaultWindows() Windows {
+	return Windows{TTL: TTLDefault, GenerationMultiplier: GenerationMultiplierDefault, EffectiveMultiplier: EffectiveMultiplierDefault}
+}
+
+// dsZone is a delivery service of a CDN, and its DNS zone.
+type dsZone struct {
+	XMLID string
+	Type  string
+	// Zone is the DNS zone of the delivery service, which is empty if it has no host regex.
+	Zone string
+}
+
+// hasKeys returns whether delivery services of the given type are signed, which are those Traffic Router answers DNS queries for.
+func hasKeys(dsType string) bool {
+	return strings.HasPrefix(dsType, "HTTP") || strings.HasPrefix(dsType, "DNS") || dsType == "STEERING" || dsType == "CLIENT_STEERING"
+}
+
+// newKeyIndex returns the index of the current key, the key with the status 'new', and false if there is none.
+func newKeyIndex(keys []tc.DNSSECKey) (int, bool) {
+	for i, key := range keys {
+		if key.Status == tc.DNSSECKeyStatusNew {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// expirationDays returns the lifetime of the current key in days, or def if there is no current key.
+func expirationDays(keys []tc.DNSSECKey, def int64) int64 {
+	i, ok := newKeyIndex(keys)
+	if !ok {
+		return def
+	}
+	return (keys[i].ExpirationDateUnix - keys[i].InceptionDateUnix) / secondsPerDay
+}
+
+// generateKeySet generates new keys for a zone, effective at the given date. The current keys of old are kept with the status 'existing', and expire when the new keys are effective.
+func generateKeySet(name string, ttl int64, kskDays int64, zskDays int64, effective int64, now int64, dsRecord bool, old tc.DNSSECKeySet) (tc.DNSSECKeySet, error) {
+	zsk, err := GenerateKey(KeyParams{Name: name, TTL: ttl, InceptionDate: now, ExpirationDate: now + zskDays*secondsPerDay, EffectiveDate: effective})
+	if err != nil {
+		return tc.DNSSECKeySet{}, errors.New("generating ZSK: " + err.Error())
+	}
+	ksk, err := GenerateKey(KeyParams{Name: name, TTL: ttl, KSK: true, InceptionDate: now, ExpirationDate: now + kskDays*secondsPerDay, EffectiveDate: effective, DSRecord: dsRecord})
+	if err != nil {
+		return tc.DNSSECKeySet{}, errors.New("generating KSK: " + err.Error())
+	}
+	set := tc.DNSSECKeySet{ZSK: []tc.DNSSECKey{zsk}, KSK: []tc.DNSSECKey{ksk}}
+	if i, ok := newKeyIndex(old.ZSK); ok {
+		existing := old.ZSK[i]
+		existing.Status = tc.DNSSECKeyStatusExisting
+		existing.ExpirationDateUnix = effective
+		set.ZSK = append(set.ZSK, existing)
+	}
+	if i, ok := newKeyIndex(old.KSK); ok {
+		existing := old.KSK[i]
+		existing.Status = tc.DNSSECKeyStatusExisting
+		existing.ExpirationDateUnix = effective
+		set.KSK = append(set.KSK, existing)
+	}
+	return set, nil
+}
+
+// rollOver replaces the current key signing or zone signing key of set with a new key, effective at the given date, and returns the new set. The replaced key is kept with the status 'expired', and if resetExpiration is true, it expires when the new key is effective. The new key has the name and TTL of the replaced key, and its lifetime unless expirationDays is positive.
+func rollOver(set tc.DNSSECKeySet, ksk bool, effective int64, now int64, expirationDays int64, resetExpiration bool, dsRecord bool) (tc.DNSSECKeySet, error) {
+	keys := set.ZSK
+	if ksk {
+		keys = set.KSK
+	}
+	i, ok := newKeyIndex(keys)
+	if !ok {
+		return set, errors.New("no current key to roll over")
+	}
+	old := keys[i]
+	ttl, err := old.TTL.Int64()
+	if err != nil {
+		return set, errors.New("current key TTL '" + old.TTL.String() + "' is not an integer")
+	}
+	lifetime := old.ExpirationDateUnix - old.InceptionDateUnix
+	if expirationDays > 0 {
+		lifetime = expirationDays * secondsPerDay
+	}
+	key, err := GenerateKey(KeyParams{Name: old.Name, TTL: ttl, KSK: ksk, InceptionDate: now, ExpirationDate: now + lifetime, EffectiveDate: effective, DSRecord: dsRecord})
+	if err != nil {
+		return set, err
+	}
+	old.Status = tc.DNSSECKeyStatusExpired
+	if resetExpiration {
+		old.ExpirationDateUnix = effective
+	}
+	if ksk {
+		set.KSK = []tc.DNSSECKey{key, old}
+	} else {
+		set.ZSK = []tc.DNSSECKey{key, old}
+	}
+	return set, nil
+}
+
+// refreshKeys generates the keys of the CDN's delivery services which have none, and rolls over the keys of the CDN and its delivery services which expire within the generation window, changing keys in place. It returns whether any keys were changed.
+//
+// The key signing key of the CDN is never rolled over by the refresh, because its DS record in the parent zone must be updated with it.
+func refreshKeys(keys tc.DNSSECKeys, cdn string, dses []dsZone, w Windows, now int64) (bool, error) {
+	kskDays := expirationDays(keys[cdn].KSK, KSKExpirationDaysDefault)
+	zskDays := expirationDays(keys[cdn].ZSK, ZSKExpirationDaysDefault)
+	rollBefore := now + w.TTL*w.GenerationMultiplier
+	changed := false
+
+	roll := func(name string, ksk bool) error {
+		set := keys[name]
+		current := set.ZSK
+		keyType := "ZSK"
+		if ksk {
+			current = set.KSK
+			keyType = "KSK"
+		}
+		i, ok := newKeyIndex(current)
+		if !ok || current[i].ExpirationDateUnix >= rollBefore {
+			return nil
+		}
+		log.Infoln("DNSSEC " + keyType + " of " + name + " in CDN " + cdn + " expires soon, rolling over")
+		effective := current[i].ExpirationDateUnix - w.TTL*w.EffectiveMultiplier
+		set, err := rollOver(set, ksk, effective, now, 0, false, false)
+		if err != nil {
+			return errors.New("rolling over " + keyType + " of " + name + ": " + err.Error())
+		}
+		keys[name] = set
+		changed = true
+		return nil
+	}
+
+	if err := roll(cdn, false); err != nil {
+		return changed, err
+	}
+	for _, ds := range dses {
+		if !hasKeys(ds.Type) {
+			continue
+		}
+		if _, ok := keys[ds.XMLID]; ok {
+			if err := roll(ds.XMLID, true); err != nil {
+				return changed, err
+			}
+			if err := roll(ds.XMLID, false); err != nil {
+				return changed, err
+			}
+			continue
+		}
+		if ds.Zone == "" {
+			log.Warnln("DNSSEC keys of delivery service " + ds.XMLID + " in CDN " + cdn + " can't be generated: it has no host regex")
+			continue
+		}
+		log.Infoln("generating DNSSEC keys of delivery service " + ds.XMLID + " in CDN " + cdn)
+		set, err := generateKeySet(ds.Zone, w.TTL, kskDays, zskDays, now, now, false, tc.DNSSECKeySet{})
+		if err != nil {
+			return changed, errors.New("generating keys of " + ds.XMLID + ": " + err.Error())
+		}
+		keys[ds.XMLID] = set
+		changed = true
+	}
+	return changed, nil
+}
@@ -0,0 +1,152 @@
+package dnssec
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
+)
+
+const day = secondsPerDay
+
+func TestGenerateKeySetKeepsCurrentKeys(t *testing.T) {
+	now := int64(100 * day)
+	old, err := generateKeySet("cdn.example.net.", 60, 365, 30, now-day, now-day, true, tc.DNSSECKeySet{})
+	if err != nil {
+		t.Fatalf("expected nil error, actual %v", err)
+	}
+	set, err := generateKeySet("cdn.example.net.", 60, 365, 30, now+day, now, true, old)
+	if err != nil {
+		t.Fatalf("expected nil error, actual %v", err)
+	}
+	if len(set.KSK) != 2 || len(set.ZSK) != 2 {
+		t.Fatalf("expected new and existing KSK and ZSK, actual %v KSKs %v ZSKs", len(set.KSK), len(set.ZSK))
+	}
+	for _, keys := range [][]tc.DNSSECKey{set.KSK, set.ZSK} {
+		if keys[0].Status != tc.DNSSECKeyStatusNew || keys[0].EffectiveDateUnix != now+day || keys[0].InceptionDateUnix != now {
+			t.Errorf("expected new key effective tomorrow, actual %+v", keys[0])
+		}
+		if keys[1].Status != tc.DNSSECKeyStatusExisting || keys[1].ExpirationDateUnix != now+day {
+			t.Errorf("expected existing key expiring when the new key is effective, actual %+v", keys[1])
+		}
+	}
+	if set.KSK[0].ExpirationDateUnix != now+365*day || set.ZSK[0].ExpirationDateUnix != now+30*day {
+		t.Errorf("expected KSK and ZSK expirations in 365 and 30 days, actual %v %v", set.KSK[0].ExpirationDateUnix, set.ZSK[0].ExpirationDateUnix)
+	}
+	if set.KSK[0].DSRecord == nil || set.ZSK[0].DSRecord != nil {
+		t.Errorf("expected only the KSK to have a DS record")
+	}
+}
+
+func TestRollOverKSK(t *testing.T) {
+	now := int64(1000 * day)
+	old, err := generateKeySet("cdn.example.net.", 60, 365, 30, now-10*day, now-10*day, true, tc.DNSSECKeySet{})
+	if err != nil {
+		t.Fatalf("expected nil error, actual %v", err)
+	}
+	old.KSK[0].TTL = json.Number("60") // TTLs may be strings, from the Perl Traffic Ops
+
+	set, err := rollOver(old, true, now+day, now, 0, true, true)
+	if err != nil {
+		t.Fatalf("expected nil error, actual %v", err)
+	}
+	if len(set.KSK) != 2 || len(set.ZSK) != 1 {
+		t.Fatalf("expected new and expired KSK and unchanged ZSK, actual %v KSKs %v ZSKs", len(set.KSK), len(set.ZSK))
+	}
+	if newKSK := set.KSK[0]; newKSK.Status != tc.DNSSECKeyStatusNew || newKSK.EffectiveDateUnix != now+day || newKSK.ExpirationDateUnix != now+365*day || newKSK.Name != "cdn.example.net." || newKSK.DSRecord == nil {
+		t.Errorf("expected new KSK with the old key's name and lifetime, actual %+v", newKSK)
+	}
+	if expired := set.KSK[1]; expired.Status != tc.DNSSECKeyStatusExpired || expired.ExpirationDateUnix != now+day || expired.Public != old.KSK[0].Public {
+		t.Errorf("expected old KSK expired when the new key is effective, actual %+v", expired)
+	}
+	if set.ZSK[0].Public != old.ZSK[0].Public {
+		t.Errorf("expected ZSK unchanged")
+	}
+
+	set, err = rollOver(old, false, now+day, now, 7, false, false)
+	if err != nil {
+		t.Fatalf("expected nil error, actual %v", err)
+	}
+	if set.ZSK[0].ExpirationDateUnix != now+7*day || set.ZSK[1].ExpirationDateUnix != old.ZSK[0].ExpirationDateUnix {
+		t.Errorf("expected new ZSK lifetime of 7 days and old ZSK expiration unchanged, actual %v %v", set.ZSK[0].ExpirationDateUnix, set.ZSK[1].ExpirationDateUnix)
+	}
+
+	if _, err := rollOver(tc.DNSSECKeySet{}, true, now, now, 0, true, true); err == nil {
+		t.Errorf("expected error rolling over a set without keys, actual nil")
+	}
+}
+
+func TestRefreshKeys(t *testing.T) {
+	now := int64(1000 * day)
+	w := Windows{TTL: 60, GenerationMultiplier: 10, EffectiveMultiplier: 10}
+	cdnKeys, err := generateKeySet("cdn.example.net.", 60, 365, 30, now-day, now-day, true, tc.DNSSECKeySet{})
+	if err != nil {
+		t.Fatalf("expected nil error, actual %v", err)
+	}
+	zskExpiration := now + 60*10 - 1 // expires within the generation window
+	cdnKeys.ZSK[0].ExpirationDateUnix = zskExpiration
+	unchanged, err := generateKeySet("ds2.cdn.example.net.", 60, 365, 30, now-day, now-day, false, tc.DNSSECKeySet{})
+	if err != nil {
+		t.Fatalf("expected nil error, actual %v", err)
+	}
+	keys := tc.DNSSECKeys{"cdn": cdnKeys, "ds2": unchanged}
+	dses := []dsZone{
+		{XMLID: "ds1", Type: "HTTP", Zone: "ds1.cdn.example.net."},
+		{XMLID: "ds2", Type: "DNS", Zone: "ds2.cdn.example.net."},
+		{XMLID: "ds3", Type: "ANY_MAP", Zone: "ds3.cdn.example.net."},
+		{XMLID: "ds4", Type: "HTTP", Zone: ""},
+	}
+
+	changed, err := refreshKeys(keys, "cdn", dses, w, now)
+	if err != nil {
+		t.Fatalf("expected nil error, actual %v", err)
+	}
+	if !changed {
+		t.Errorf("expected keys changed, actual unchanged")
+	}
+	if zsk := keys["cdn"].ZSK; len(zsk) != 2 || zsk[0].EffectiveDateUnix != zskExpiration-60*10 || zsk[1].Status != tc.DNSSECKeyStatusExpired {
+		t.Errorf("expected CDN ZSK rolled over, effective before the old ZSK expires, actual %+v", zsk)
+	}
+	if ksk := keys["cdn"].KSK; len(ksk) != 1 || ksk[0].Public != cdnKeys.KSK[0].Public {
+		t.Errorf("expected CDN KSK unchanged")
+	}
+	if ds1, ok := keys["ds1"]; !ok || len(ds1.KSK) != 1 || len(ds1.ZSK) != 1 || ds1.KSK[0].Name != "ds1.cdn.example.net." || ds1.KSK[0].ExpirationDateUnix != now+365*day {
+		t.Errorf("expected keys generated for ds1 with the CDN key lifetimes, actual %+v", ds1)
+	}
+	if keys["ds2"].KSK[0].Public != unchanged.KSK[0].Public || keys["ds2"].ZSK[0].Public != unchanged.ZSK[0].Public {
+		t.Errorf("expected ds2 keys unchanged")
+	}
+	if _, ok := keys["ds3"]; ok {
+		t.Errorf("expected no keys for ANY_MAP delivery service ds3")
+	}
+	if _, ok := keys["ds4"]; ok {
+		t.Errorf("expected no keys for delivery service ds4 without a zone")
+	}
+
+	changed, err = refreshKeys(keys, "cdn", dses, w, now)
+	if err != nil {
+		t.Fatalf("expected nil error, actual %v", err)
+	}
+	if changed {
+		t.Errorf("expected second refresh unchanged, actual changed")
+	}
+}
@@ -0,0 +1,172 @@
+package events
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-log"
+	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// SubscriberBuffer is the number of events buffered for each subscriber. Subscribers which fall further behind are dropped, and may catch up by replaying from the last event they got.
+const SubscriberBuffer = 100
+
+// pollInterval is how often the bus queues webhook deliveries of events which it wasn't notified of, and deletes expired events.
+const pollInterval = time.Minute
+
+// Bus sends the events published by every Traffic Ops to this Traffic Ops' subscribers, and queues their delivery to webhooks. Each event's deliveries are queued by only one Traffic Ops.
+type Bus struct {
+	db          *sqlx.DB
+	dispatcher  *Dispatcher
+	retention   time.Duration
+	m           sync.Mutex
+	subscribers map[chan tc.Event]struct{}
+	// lastID is the id of the newest event sent to subscribers. It's only used by the Listen goroutine.
+	lastID int64
+}
+
+// NewBus returns a bus which delivers events to webhooks with the given dispatcher, and keeps events for the given retention.
+func NewBus(db *sqlx.DB, dispatcher *Dispatcher, retention time.Duration) *Bus {
+	return &Bus{db: db, dispatcher: dispatcher, retention: retention, subscribers: map[chan tc.Event]struct{}{}}
+}
+
+// Subscribe returns a channel of new events, and a func to unsubscribe, which must be called when the subscriber is done. The channel is closed when the subscriber is unsubscribed, or if it falls SubscriberBuffer events behind.
+func (b *Bus) Subscribe() (<-chan tc.Event, func()) {
+	ch := make(chan tc.Event, SubscriberBuffer)
+	b.m.Lock()
+	b.subscribers[ch] = struct{}{}
+	b.m.Unlock()
+	return ch, func() {
+		b.m.Lock()
+		defer b.m.Unlock()
+		if _, ok := b.subscribers[ch]; ok {
+			delete(b.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// broadcast sends the event to every subscriber, without blocking. Subscribers whose buffer is full are dropped.
+func (b *Bus) broadcast(e tc.Event) {
+	b.m.Lock()
+	defer b.m.Unlock()
+	for ch := range b.subscribers {
+		select {
+		case ch <- e:
+		default:
+			log.Warnln("event bus: dropping subscriber which fell behind, at event " + strconv.FormatInt(e.ID, 10))
+			delete(b.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// Listen listens for the events published by every Traffic Ops, and sends them to subscribers and queues them for webhooks. It never returns, and should be run in its own goroutine.
+func (b *Bus) Listen(dbConnStr string) {
+	lastID, err := getLastEventID(b.db)
+	if err != nil {
+		log.Errorln("event bus: " + err.Error())
+	}
+	b.lastID = lastID
+
+	listener := pq.NewListener(dbConnStr, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Errorln("event bus listener: " + err.Error())
+		}
+	})
+	if err := listener.Listen(Channel); err != nil {
+		log.Errorln("event bus listening on " + Channel + ": " + err.Error())
+	}
+	b.dispatch()
+	for {
+		select {
+		case notification := <-listener.Notify:
+			if notification == nil {
+				// the connection was re-established, and notifications may have been missed
+				b.sendMissed()
+			} else if id, err := strconv.ParseInt(notification.Extra, 10, 64); err != nil {
+				log.Errorln("event bus: notification with malformed event id '" + notification.Extra + "'")
+			} else {
+				b.send(id)
+			}
+			b.dispatch()
+		case <-time.After(pollInterval):
+			b.dispatch()
+			if err := deleteExpired(b.db, b.retention); err != nil {
+				log.Errorln("event bus: " + err.Error())
+			}
+			if err := listener.Ping(); err != nil {
+				log.Errorln("event bus listener ping: " + err.Error())
+			}
+		}
+	}
+}
+
+// send sends the event with the given id to subscribers. Events are sent by id as they're notified, rather than reading every event after the last one, because ids are assigned before transactions commit, and so may be committed out of order.
+func (b *Bus) send(id int64) {
+	e, ok, err := getEvent(b.db, id)
+	if err != nil {
+		log.Errorln("event bus: " + err.Error())
+		return
+	}
+	if !ok {
+		return // expired already
+	}
+	b.broadcast(e)
+	if e.ID > b.lastID {
+		b.lastID = e.ID
+	}
+}
+
+// sendMissed sends the events after the last one sent to subscribers, after notifications may have been missed.
+func (b *Bus) sendMissed() {
+	for {
+		events, err := getEventsAfter(b.db, b.lastID, nil)
+		if err != nil {
+			log.Errorln("event bus: " + err.Error())
+			return
+		}
+		for _, e := range events {
+			b.broadcast(e)
+			b.lastID = e.ID
+		}
+		if len(events) < MaxReplay {
+			return
+		}
+	}
+}
+
+// dispatch queues the webhook deliveries of the events which haven't been queued by any Traffic Ops, and wakes the dispatcher's workers to deliver them.
+func (b *Bus) dispatch() {
+	queued, err := b.dispatcher.Queue(b.retention)
+	if err != nil {
+		log.Errorln("event bus: " + err.Error())
+		return
+	}
+	if queued > 0 {
+		b.dispatcher.Wake()
+	}
+}
@@ -0,0 +1,75 @@
+package events
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"testing"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
+)
+
+func TestBroadcast(t *testing.T) {
+	b := NewBus(nil, nil, 0)
+	events, unsubscribe := b.Subscribe()
+	defer unsubscribe()
+
+	b.broadcast(tc.Event{ID: 1})
+	b.broadcast(tc.Event{ID: 2})
+	for _, expected := range []int64{1, 2} {
+		if e := <-events; e.ID != expected {
+			t.Errorf("broadcast expected: event %v, actual: event %v", expected, e.ID)
+		}
+	}
+}
+
+func TestBroadcastDropsSlowSubscribers(t *testing.T) {
+	b := NewBus(nil, nil, 0)
+	slow, unsubscribeSlow := b.Subscribe()
+	defer unsubscribeSlow()
+	fast, unsubscribeFast := b.Subscribe()
+	defer unsubscribeFast()
+
+	for i := 0; i <= SubscriberBuffer; i++ {
+		b.broadcast(tc.Event{ID: int64(i)})
+		if i < SubscriberBuffer {
+			<-fast
+		}
+	}
+	for i := 0; i < SubscriberBuffer; i++ {
+		<-slow
+	}
+	if _, ok := <-slow; ok {
+		t.Errorf("broadcast to full subscriber expected: channel closed, actual: open")
+	}
+	if e, ok := <-fast; !ok || e.ID != SubscriberBuffer {
+		t.Errorf("broadcast to keeping-up subscriber expected: event %v, actual: event %v open %v", SubscriberBuffer, e.ID, ok)
+	}
+}
+
+func TestUnsubscribe(t *testing.T) {
+	b := NewBus(nil, nil, 0)
+	events, unsubscribe := b.Subscribe()
+	unsubscribe()
+	unsubscribe() // must be safe to call twice
+	if _, ok := <-events; ok {
+		t.Errorf("unsubscribe expected: channel closed, actual: open")
+	}
+	b.broadcast(tc.Event{ID: 1}) // must not panic sending to the closed channel
+}
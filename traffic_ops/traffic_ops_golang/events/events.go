@@ -0,0 +1,142 @@
+package events
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Channel is the Postgres notification channel the id of each new event is sent on, by a trigger on the event table.
+const Channel = "tc_event"
+
+// MaxReplay is the most events read at once, when catching up or replaying events to a reconnecting client.
+const MaxReplay = 1000
+
+// Publish writes an event of the given type, which is sent to event stream clients and webhooks once the transaction is committed. The data is marshalled as JSON, and may be nil.
+// Change log entries are published by the database, and don't need to be published here.
+func Publish(eventType string, message string, data interface{}, user auth.CurrentUser, tx sqlx.Execer) error {
+	dataBts := []byte(nil)
+	if data != nil {
+		bts, err := json.Marshal(data)
+		if err != nil {
+			return errors.New("marshalling event data: " + err.Error())
+		}
+		dataBts = bts
+	}
+	userID := sql.NullInt64{Int64: int64(user.ID), Valid: user.ID > 0}
+	if _, err := tx.Exec(`INSERT INTO event (type, message, data, tm_user) VALUES ($1, $2, $3, $4)`, eventType, message, dataBts, userID); err != nil {
+		return errors.New("inserting event: " + err.Error())
+	}
+	return nil
+}
+
+// eventCols are the columns of a tc.Event, selected from the event table as e, for scanEvents.
+const eventCols = `e.id, e.type, e.message, e.data, (SELECT u.username FROM tm_user AS u WHERE u.id = e.tm_user), e.created`
+
+// getEventsAfter returns up to MaxReplay events after the given id, oldest first. If types isn't empty, only events of those types are returned.
+func getEventsAfter(db *sqlx.DB, id int64, types []string) ([]tc.Event, error) {
+	rows, err := db.Query(`SELECT `+eventCols+` FROM event AS e WHERE e.id > $1 AND (CARDINALITY($2::text[]) = 0 OR e.type = ANY($2::text[])) ORDER BY e.id LIMIT $3`, id, pq.Array(types), MaxReplay)
+	if err != nil {
+		return nil, errors.New("querying events: " + err.Error())
+	}
+	return scanEvents(rows)
+}
+
+// getEvent returns the event with the given id, and whether it existed.
+func getEvent(db *sqlx.DB, id int64) (tc.Event, bool, error) {
+	rows, err := db.Query(`SELECT `+eventCols+` FROM event AS e WHERE e.id = $1`, id)
+	if err != nil {
+		return tc.Event{}, false, errors.New("querying event: " + err.Error())
+	}
+	events, err := scanEvents(rows)
+	if err != nil || len(events) == 0 {
+		return tc.Event{}, false, err
+	}
+	return events[0], true, nil
+}
+
+// getLastEventID returns the id of the newest event, or 0 if there are none.
+func getLastEventID(db *sqlx.DB) (int64, error) {
+	id := int64(0)
+	if err := db.QueryRow(`SELECT COALESCE(MAX(id), 0) FROM event`).Scan(&id); err != nil {
+		return 0, errors.New("querying last event id: " + err.Error())
+	}
+	return id, nil
+}
+
+// claimUndispatched marks the events which haven't had their webhook deliveries queued, and are newer than the retention, as dispatched, and returns them oldest first. Each event is only claimed by one transaction, even if other Traffic Ops instances claim at the same time, and is claimed again if the transaction is rolled back.
+func claimUndispatched(tx *sqlx.Tx, retention time.Duration) ([]tc.Event, error) {
+	rows, err := tx.Query(`UPDATE event AS e SET dispatched = TRUE WHERE NOT e.dispatched AND e.created > now() - $1 * interval '1 second' RETURNING `+eventCols, retention.Seconds())
+	if err != nil {
+		return nil, errors.New("claiming events: " + err.Error())
+	}
+	events, err := scanEvents(rows)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].ID < events[j].ID })
+	return events, nil
+}
+
+// deleteExpired deletes the events, and the successful webhook deliveries, older than the retention. Dead letters are kept.
+func deleteExpired(db *sqlx.DB, retention time.Duration) error {
+	if _, err := db.Exec(`DELETE FROM event WHERE created < now() - $1 * interval '1 second'`, retention.Seconds()); err != nil {
+		return errors.New("deleting expired events: " + err.Error())
+	}
+	if _, err := db.Exec(`DELETE FROM webhook_delivery WHERE delivered AND created < now() - $1 * interval '1 second'`, retention.Seconds()); err != nil {
+		return errors.New("deleting expired webhook deliveries: " + err.Error())
+	}
+	return nil
+}
+
+// scanEvents scans and closes rows of eventCols.
+func scanEvents(rows *sql.Rows) ([]tc.Event, error) {
+	defer rows.Close()
+	events := []tc.Event{}
+	for rows.Next() {
+		e := tc.Event{}
+		data := []byte(nil)
+		user := sql.NullString{}
+		if err := rows.Scan(&e.ID, &e.Type, &e.Message, &data, &user, &e.Created); err != nil {
+			return nil, errors.New("scanning events: " + err.Error())
+		}
+		if len(data) > 0 {
+			e.Data = json.RawMessage(data)
+		}
+		if user.Valid {
+			e.User = &user.String
+		}
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.New("scanning events: " + err.Error())
+	}
+	return events, nil
+}
@@ -0,0 +1,169 @@
+package events
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-log"
+	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/api"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// HeartbeatInterval is how often a comment is written to idle event streams, so proxies and clients don't time them out.
+const HeartbeatInterval = 30 * time.Second
+
+// StreamHandler serves events as they're published, as a server-sent events stream. The type parameter may be a comma-separated list of the event types to stream.
+// Clients which reconnect with the Last-Event-ID header, or the lastEventId parameter, are first sent the events they missed, which are kept for the event retention. Streams are ended by the server's write timeout, after which EventSource clients reconnect this way.
+func StreamHandler(bus *Bus, db *sqlx.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		handleErrs := tc.GetHandleErrorsFunc(w, r)
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			handleErrs(http.StatusInternalServerError, errors.New("event stream: response writer doesn't support flushing"))
+			return
+		}
+		params, err := api.GetCombinedParams(r)
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, err)
+			return
+		}
+		types, err := parseTypes(params["type"])
+		if err != nil {
+			handleErrs(http.StatusBadRequest, err)
+			return
+		}
+		lastIDStr := r.Header.Get("Last-Event-ID")
+		if lastIDStr == "" {
+			lastIDStr = params["lastEventId"]
+		}
+		lastID := int64(0)
+		if lastIDStr != "" {
+			if lastID, err = strconv.ParseInt(lastIDStr, 10, 64); err != nil {
+				handleErrs(http.StatusBadRequest, errors.New("last event id must be an integer"))
+				return
+			}
+		}
+
+		// subscribe before reading missed events, so none are published in between. Events both read and received are only written once.
+		newEvents, unsubscribe := bus.Subscribe()
+		defer unsubscribe()
+
+		missed := []tc.Event{}
+		for lastID > 0 {
+			events, err := getEventsAfter(db, lastID, types)
+			if err != nil {
+				log.Errorln("event stream: " + err.Error())
+				handleErrs(http.StatusInternalServerError, tc.DBError)
+				return
+			}
+			missed = append(missed, events...)
+			if len(events) < MaxReplay {
+				break
+			}
+			lastID = events[len(events)-1].ID
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.WriteHeader(http.StatusOK)
+		written := make(map[int64]struct{}, len(missed))
+		for _, e := range missed {
+			if err := writeEvent(w, e); err != nil {
+				return
+			}
+			written[e.ID] = struct{}{}
+		}
+		flusher.Flush()
+
+		heartbeat := time.NewTicker(HeartbeatInterval)
+		defer heartbeat.Stop()
+		for {
+			select {
+			case e, ok := <-newEvents:
+				if !ok {
+					return // fell behind; the client will reconnect and get what it missed
+				}
+				if _, ok := written[e.ID]; ok || !hasType(types, e.Type) {
+					continue
+				}
+				if err := writeEvent(w, e); err != nil {
+					return
+				}
+			case <-heartbeat.C:
+				if _, err := io.WriteString(w, ": heartbeat\n\n"); err != nil {
+					return
+				}
+			case <-r.Context().Done():
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// parseTypes parses a comma-separated list of event types. An empty list means every type.
+func parseTypes(s string) ([]string, error) {
+	types := []string{}
+	if s == "" {
+		return types, nil
+	}
+	for _, t := range strings.Split(s, ",") {
+		t = strings.TrimSpace(t)
+		if !hasType(tc.EventTypes, t) {
+			return nil, errors.New("unknown event type '" + t + "', must be one of " + strings.Join(tc.EventTypes, ", "))
+		}
+		types = append(types, t)
+	}
+	return types, nil
+}
+
+// hasType returns whether the event type is in types, or types is empty.
+func hasType(types []string, eventType string) bool {
+	if len(types) == 0 {
+		return true
+	}
+	for _, t := range types {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// writeEvent writes the event in the server-sent events format. The data is the event's JSON, which never contains newlines.
+func writeEvent(w io.Writer, e tc.Event) error {
+	bts, err := json.Marshal(e)
+	if err != nil {
+		log.Errorln("event stream: marshalling event " + strconv.FormatInt(e.ID, 10) + ": " + err.Error())
+		return nil
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, bts)
+	return err
+}
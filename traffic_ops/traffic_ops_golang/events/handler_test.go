@@ -0,0 +1,68 @@
+package events
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
+)
+
+func TestParseTypes(t *testing.T) {
+	types, err := parseTypes(tc.EventTypeSnapshot + ", " + tc.EventTypeQueueUpdate)
+	if err != nil {
+		t.Fatalf("parseTypes expected: no error, actual: %v", err)
+	}
+	if expected := []string{tc.EventTypeSnapshot, tc.EventTypeQueueUpdate}; !reflect.DeepEqual(types, expected) {
+		t.Errorf("parseTypes expected: %v, actual: %v", expected, types)
+	}
+	if types, err := parseTypes(""); err != nil || len(types) != 0 {
+		t.Errorf("parseTypes empty expected: no types, actual: %v error %v", types, err)
+	}
+	if _, err := parseTypes(tc.EventTypeSnapshot + ",nonexistent"); err == nil {
+		t.Errorf("parseTypes unknown type expected: error, actual: nil")
+	}
+}
+
+func TestHasType(t *testing.T) {
+	if !hasType(nil, tc.EventTypeChangeLog) {
+		t.Errorf("hasType with no types expected: true, actual: false")
+	}
+	if hasType([]string{tc.EventTypeSnapshot}, tc.EventTypeChangeLog) {
+		t.Errorf("hasType with other type expected: false, actual: true")
+	}
+}
+
+func TestWriteEvent(t *testing.T) {
+	user := "admin"
+	e := tc.Event{ID: 5, Type: tc.EventTypeSnapshot, Message: "Snapshot of CDN mycdn", Data: json.RawMessage(`{"cdn":"mycdn"}`), User: &user}
+	buf := &bytes.Buffer{}
+	if err := writeEvent(buf, e); err != nil {
+		t.Fatalf("writeEvent expected: no error, actual: %v", err)
+	}
+	data, _ := json.Marshal(e)
+	expected := "id: 5\nevent: snapshot\ndata: " + string(data) + "\n\n"
+	if actual := buf.String(); actual != expected {
+		t.Errorf("writeEvent expected: %q, actual: %q", expected, actual)
+	}
+}
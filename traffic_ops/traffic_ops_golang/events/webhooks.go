@@ -0,0 +1,252 @@
+package events
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-log"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// SignatureHeader is the header of webhook deliveries with the HMAC-SHA256 of the body, keyed by the webhook's secret, in the form "sha256=<hex>".
+const SignatureHeader = "X-TC-Signature"
+
+// EventTypeHeader is the header of webhook deliveries with the event type.
+const EventTypeHeader = "X-TC-Event"
+
+// EventIDHeader is the header of webhook deliveries with the event id. Receivers may use it to ignore events delivered more than once.
+const EventIDHeader = "X-TC-Event-ID"
+
+// maxResponseBytes is the most of a webhook response body read, so the connection may be reused.
+const maxResponseBytes = 64 * 1024
+
+// deliveryPollInterval is how often idle workers look for deliveries which are due, which were queued by another Traffic Ops, or are being retried.
+const deliveryPollInterval = 5 * time.Second
+
+// deliveryLeaseMargin is how much longer than the delivery timeout a claimed delivery is leased to its worker. If the worker's Traffic Ops stops before finishing the delivery, it's attempted again once the lease expires.
+const deliveryLeaseMargin = 30 * time.Second
+
+// Dispatcher delivers events to webhooks. Deliveries are queued in the webhook_delivery table, and attempted by a fixed number of workers on every Traffic Ops, in event order for each webhook. Failed deliveries are retried with exponential backoff, and kept as dead letters after every attempt fails.
+type Dispatcher struct {
+	db          *sqlx.DB
+	client      *http.Client
+	maxAttempts int
+	retryDelay  time.Duration
+	workers     int
+	wake        chan struct{}
+}
+
+// NewDispatcher returns a dispatcher with the given number of workers, which attempts each delivery up to maxAttempts times, with the given timeout, waiting retryDelay before the first retry and doubling it after each.
+func NewDispatcher(db *sqlx.DB, timeout time.Duration, maxAttempts int, retryDelay time.Duration, workers int) *Dispatcher {
+	return &Dispatcher{db: db, client: &http.Client{Timeout: timeout}, maxAttempts: maxAttempts, retryDelay: retryDelay, workers: workers, wake: make(chan struct{}, workers)}
+}
+
+// delivery is a queued delivery of an event to a webhook, as claimed by a worker.
+type delivery struct {
+	ID        int64
+	WebhookID int
+	URL       string
+	Secret    string
+	EventID   int64
+	EventType string
+	Payload   []byte
+	Attempts  int
+}
+
+// Start starts the dispatcher's workers. They run until the process exits.
+func (d *Dispatcher) Start() {
+	for i := 0; i < d.workers; i++ {
+		go d.work()
+	}
+}
+
+// Wake wakes idle workers, so newly queued deliveries are attempted without waiting for the next poll.
+func (d *Dispatcher) Wake() {
+	for i := 0; i < d.workers; i++ {
+		select {
+		case d.wake <- struct{}{}:
+		default:
+			return
+		}
+	}
+}
+
+// Queue claims the events whose webhook deliveries haven't been queued by any Traffic Ops, and queues a delivery of each to every active webhook of its type. Events and their deliveries are committed together, so an event is never claimed without its deliveries being queued. It returns the number of deliveries queued.
+func (d *Dispatcher) Queue(retention time.Duration) (int64, error) {
+	tx, err := d.db.Beginx()
+	if err != nil {
+		return 0, errors.New("beginning transaction: " + err.Error())
+	}
+	commitTx := false
+	defer func() {
+		if commitTx {
+			return
+		}
+		if err := tx.Rollback(); err != nil {
+			log.Errorln("webhook dispatcher: rolling back transaction: " + err.Error())
+		}
+	}()
+	events, err := claimUndispatched(tx, retention)
+	if err != nil {
+		return 0, err
+	}
+	queued := int64(0)
+	for _, e := range events {
+		payload, err := json.Marshal(e)
+		if err != nil {
+			return 0, errors.New("marshalling event " + strconv.FormatInt(e.ID, 10) + ": " + err.Error())
+		}
+		result, err := tx.Exec(`INSERT INTO webhook_delivery (webhook, event_id, event_type, payload) SELECT w.id, $1, $2, $3 FROM webhook AS w WHERE w.active AND (CARDINALITY(w.event_types) = 0 OR $2 = ANY(w.event_types)) ON CONFLICT (webhook, event_id) DO NOTHING`, e.ID, e.Type, payload)
+		if err != nil {
+			return 0, errors.New("queueing deliveries of event " + strconv.FormatInt(e.ID, 10) + ": " + err.Error())
+		}
+		if n, err := result.RowsAffected(); err == nil {
+			queued += n
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, errors.New("committing transaction: " + err.Error())
+	}
+	commitTx = true
+	return queued, nil
+}
+
+// work attempts deliveries as they're due, until the process exits.
+func (d *Dispatcher) work() {
+	for {
+		attempted, err := d.deliverNext()
+		if err != nil {
+			log.Errorln("webhook dispatcher: " + err.Error())
+		}
+		if attempted && err == nil {
+			continue
+		}
+		select {
+		case <-d.wake:
+		case <-time.After(deliveryPollInterval):
+		}
+	}
+}
+
+// deliverNext claims the next due delivery, attempts it once, and records the result. It returns whether a delivery was attempted.
+func (d *Dispatcher) deliverNext() (bool, error) {
+	dl, ok, err := claimDelivery(d.db, d.client.Timeout+deliveryLeaseMargin)
+	if err != nil || !ok {
+		return false, err
+	}
+	return true, d.finish(dl, d.post(dl))
+}
+
+// finish records the result of an attempt of the delivery. A successful delivery is marked delivered. A failed delivery is retried after an exponentially increasing delay, or is marked as a dead letter if every attempt has failed.
+func (d *Dispatcher) finish(dl delivery, postErr error) error {
+	attempts := dl.Attempts + 1
+	if postErr == nil {
+		if _, err := d.db.Exec(`UPDATE webhook_delivery SET delivered = TRUE, attempts = $2, last_error = '' WHERE id = $1`, dl.ID, attempts); err != nil {
+			return errors.New("webhook " + strconv.Itoa(dl.WebhookID) + ": marking event " + strconv.FormatInt(dl.EventID, 10) + " delivered: " + err.Error())
+		}
+		return nil
+	}
+	deadLetter := attempts >= d.maxAttempts
+	if deadLetter {
+		log.Errorln("webhook " + strconv.Itoa(dl.WebhookID) + ": giving up delivering event " + strconv.FormatInt(dl.EventID, 10) + " after " + strconv.Itoa(attempts) + " attempts: " + postErr.Error())
+	} else {
+		log.Warnln("webhook " + strconv.Itoa(dl.WebhookID) + ": delivering event " + strconv.FormatInt(dl.EventID, 10) + ", attempt " + strconv.Itoa(attempts) + ": " + postErr.Error())
+	}
+	retryDelay := d.retryDelay << uint(dl.Attempts)
+	if _, err := d.db.Exec(`UPDATE webhook_delivery SET attempts = $2, last_error = $3, dead_lettered = $4, next_attempt_at = now() + $5 * interval '1 second' WHERE id = $1`, dl.ID, attempts, postErr.Error(), deadLetter, retryDelay.Seconds()); err != nil {
+		return errors.New("webhook " + strconv.Itoa(dl.WebhookID) + ": recording failed delivery of event " + strconv.FormatInt(dl.EventID, 10) + ": " + err.Error())
+	}
+	return nil
+}
+
+// post makes one attempt to deliver the event to the webhook. Any 2xx response is success.
+func (d *Dispatcher) post(dl delivery) error {
+	req, err := http.NewRequest(http.MethodPost, dl.URL, bytes.NewReader(dl.Payload))
+	if err != nil {
+		return errors.New("creating request: " + err.Error())
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventTypeHeader, dl.EventType)
+	req.Header.Set(EventIDHeader, strconv.FormatInt(dl.EventID, 10))
+	req.Header.Set(SignatureHeader, Sign(dl.Secret, dl.Payload))
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, io.LimitReader(resp.Body, maxResponseBytes))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.New("response code " + strconv.Itoa(resp.StatusCode))
+	}
+	return nil
+}
+
+// Sign returns the signature of the payload for the SignatureHeader, which is the HMAC-SHA256 of the payload keyed by the secret.
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// claimDelivery leases the next due delivery to the caller for the given duration, and returns it, and whether there was one. Only the oldest pending delivery of each active webhook is due, so each webhook gets events in order, and a webhook's deliveries wait while an earlier one is being retried.
+func claimDelivery(db *sqlx.DB, lease time.Duration) (delivery, bool, error) {
+	qry := `
+UPDATE webhook_delivery AS d SET next_attempt_at = now() + $1 * interval '1 second'
+FROM webhook AS w
+WHERE w.id = d.webhook
+AND d.id = (
+  SELECT q.id FROM webhook_delivery AS q
+  JOIN webhook AS qw ON qw.id = q.webhook
+  WHERE qw.active
+  AND NOT q.delivered AND NOT q.dead_lettered
+  AND q.next_attempt_at <= now()
+  AND NOT EXISTS (
+    SELECT 1 FROM webhook_delivery AS p
+    WHERE p.webhook = q.webhook AND p.event_id < q.event_id
+    AND NOT p.delivered AND NOT p.dead_lettered
+  )
+  ORDER BY q.next_attempt_at, q.id
+  LIMIT 1
+  FOR UPDATE OF q SKIP LOCKED
+)
+RETURNING d.id, d.webhook, w.url, w.secret, d.event_id, d.event_type, d.payload, d.attempts
+`
+	dl := delivery{}
+	if err := db.QueryRow(qry, lease.Seconds()).Scan(&dl.ID, &dl.WebhookID, &dl.URL, &dl.Secret, &dl.EventID, &dl.EventType, &dl.Payload, &dl.Attempts); err != nil {
+		if err == sql.ErrNoRows {
+			return delivery{}, false, nil
+		}
+		return delivery{}, false, errors.New("claiming webhook delivery: " + err.Error())
+	}
+	return dl, true, nil
+}
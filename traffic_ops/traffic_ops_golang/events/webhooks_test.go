@@ -0,0 +1,221 @@
+package events
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
+	"github.com/jmoiron/sqlx"
+
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestSign(t *testing.T) {
+	// HMAC-SHA256 of "payload" keyed by "secret"
+	expected := "sha256=b82fcb791acec57859b989b430a826488ce2e479fdf92326bd0a2e8375a42ba4"
+	if actual := Sign("secret", []byte("payload")); actual != expected {
+		t.Errorf("Sign expected: %v, actual: %v", expected, actual)
+	}
+}
+
+// expectClaimDelivery expects a delivery to be claimed, and returns it with the given prior attempts, to the webhook at the given URL.
+func expectClaimDelivery(mock sqlmock.Sqlmock, url string, attempts int) {
+	cols := []string{"id", "webhook", "url", "secret", "event_id", "event_type", "payload", "attempts"}
+	rows := sqlmock.NewRows(cols).AddRow(3, 7, url, "secret", 42, tc.EventTypeSnapshot, []byte(`{"id":42}`), attempts)
+	mock.ExpectQuery("UPDATE webhook_delivery").WillReturnRows(rows)
+}
+
+func TestDeliverNext(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	requests := int32(0)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		body, _ := ioutil.ReadAll(r.Body)
+		if sig := r.Header.Get(SignatureHeader); sig != Sign("secret", body) {
+			t.Errorf("delivery signature expected: %v, actual: %v", Sign("secret", body), sig)
+		}
+		if typ := r.Header.Get(EventTypeHeader); typ != tc.EventTypeSnapshot {
+			t.Errorf("delivery event type header expected: %v, actual: %v", tc.EventTypeSnapshot, typ)
+		}
+		if id := r.Header.Get(EventIDHeader); id != "42" {
+			t.Errorf("delivery event id header expected: 42, actual: %v", id)
+		}
+	}))
+	defer srv.Close()
+
+	expectClaimDelivery(mock, srv.URL, 0)
+	mock.ExpectExec("UPDATE webhook_delivery SET delivered = TRUE").WithArgs(3, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	d := NewDispatcher(db, time.Second, 3, time.Second, 1)
+	attempted, err := d.deliverNext()
+	if err != nil || !attempted {
+		t.Fatalf("deliverNext expected: attempted, actual: attempted %v error %v", attempted, err)
+	}
+	if requests := atomic.LoadInt32(&requests); requests != 1 {
+		t.Errorf("deliverNext expected: 1 request, actual: %v", requests)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("deliverNext expected delivery marked delivered, actual: %v", err)
+	}
+}
+
+func TestDeliverNextRetry(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	// the second attempt fails, so the third is after twice the retry delay, and it isn't a dead letter
+	expectClaimDelivery(mock, srv.URL, 1)
+	mock.ExpectExec("UPDATE webhook_delivery SET attempts").WithArgs(3, 2, "response code 503", false, float64(2)).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	d := NewDispatcher(db, time.Second, 3, time.Second, 1)
+	if attempted, err := d.deliverNext(); err != nil || !attempted {
+		t.Fatalf("deliverNext expected: attempted, actual: attempted %v error %v", attempted, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("deliverNext failing expected retry, actual: %v", err)
+	}
+}
+
+func TestDeliverNextDeadLetter(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	expectClaimDelivery(mock, srv.URL, 1)
+	mock.ExpectExec("UPDATE webhook_delivery SET attempts").WithArgs(3, 2, "response code 500", true, float64(2)).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	d := NewDispatcher(db, time.Second, 2, time.Second, 1)
+	if attempted, err := d.deliverNext(); err != nil || !attempted {
+		t.Fatalf("deliverNext expected: attempted, actual: attempted %v error %v", attempted, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("deliverNext failing every attempt expected dead letter, actual: %v", err)
+	}
+}
+
+func TestDeliverNextNoneDue(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	mock.ExpectQuery("UPDATE webhook_delivery").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	d := NewDispatcher(db, time.Second, 2, time.Second, 1)
+	if attempted, err := d.deliverNext(); err != nil || attempted {
+		t.Errorf("deliverNext with no due deliveries expected: not attempted, actual: attempted %v error %v", attempted, err)
+	}
+}
+
+func TestQueue(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	created := time.Date(2018, 5, 3, 12, 0, 0, 0, time.UTC)
+	cols := []string{"id", "type", "message", "data", "username", "created"}
+	rows := sqlmock.NewRows(cols).
+		AddRow(2, tc.EventTypeChangeLog, "second", nil, nil, created).
+		AddRow(1, tc.EventTypeSnapshot, "first", nil, "admin", created)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE event").WillReturnRows(rows)
+	mock.ExpectExec("INSERT INTO webhook_delivery").WithArgs(int64(1), tc.EventTypeSnapshot, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("INSERT INTO webhook_delivery").WithArgs(int64(2), tc.EventTypeChangeLog, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	d := NewDispatcher(db, time.Second, 2, time.Second, 1)
+	queued, err := d.Queue(time.Hour)
+	if err != nil {
+		t.Fatalf("Queue expected: no error, actual: %v", err)
+	}
+	if queued != 3 {
+		t.Errorf("Queue expected: 3 deliveries queued, actual: %v", queued)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Queue expected: events claimed and deliveries queued oldest first in one transaction, actual: %v", err)
+	}
+}
+
+func TestQueueRollsBack(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	cols := []string{"id", "type", "message", "data", "username", "created"}
+	rows := sqlmock.NewRows(cols).AddRow(1, tc.EventTypeSnapshot, "first", nil, nil, time.Now())
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE event").WillReturnRows(rows)
+	mock.ExpectExec("INSERT INTO webhook_delivery").WillReturnError(errors.New("connection lost"))
+	mock.ExpectRollback()
+
+	d := NewDispatcher(db, time.Second, 2, time.Second, 1)
+	if _, err := d.Queue(time.Hour); err == nil {
+		t.Errorf("Queue failing to queue deliveries expected: error, actual: nil")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Queue failing to queue deliveries expected: events left unclaimed, actual: %v", err)
+	}
+}
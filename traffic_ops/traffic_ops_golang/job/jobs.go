@@ -0,0 +1,603 @@
+package job
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"errors"
+	"regexp/syntax"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-log"
+	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/ats"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/dbhelpers"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/tenant"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/tovalidate"
+
+	"github.com/go-ozzo/ozzo-validation"
+	"github.com/jmoiron/sqlx"
+)
+
+// StartTimeLayout is the format of job start times in requests, like the Perl Traffic Ops. Times are in the Traffic Ops server's local time zone, unless given with a UTC offset in the tc.TimeLayout format, as they are in responses.
+const StartTimeLayout = "2006-01-02 15:04:05"
+
+// MaxStartTimeWindow is how far from now a job may start.
+const MaxStartTimeWindow = 2 * 24 * time.Hour
+
+// NonUrgentDelay is how long after its start time a job starts, unless it's urgent. This gives caches time to receive the regex_revalidate.config before the invalidation begins.
+const NonUrgentDelay = time.Minute
+
+const assetType = "file"
+
+// ReadUnassignedCapability is the capability to view the jobs of delivery services which aren't assigned to the user, when tenancy is disabled.
+const ReadUnassignedCapability = "job-unassigned-read"
+
+// WriteUnassignedCapability is the capability to invalidate content of delivery services which aren't assigned to the user, when tenancy is disabled.
+const WriteUnassignedCapability = "job-unassigned-write"
+
+//we need a type alias to define functions on
+type TOJob tc.JobNullable
+
+//the refType is passed into the handlers where a copy of its type is used to decode the json.
+var refType = TOJob{}
+
+func GetRefType() *TOJob {
+	return &refType
+}
+
+func (job TOJob) GetKeyFieldsInfo() []api.KeyFieldInfo {
+	return []api.KeyFieldInfo{{"id", api.GetIntKey}}
+}
+
+//Implementation of the Identifier, Validator interface functions
+func (job TOJob) GetKeys() (map[string]interface{}, bool) {
+	if job.ID == nil {
+		return map[string]interface{}{"id": 0}, false
+	}
+	return map[string]interface{}{"id": *job.ID}, true
+}
+
+func (job *TOJob) SetKeys(keys map[string]interface{}) {
+	i, _ := keys["id"].(int) //this utilizes the non panicking type assertion, if the thrown away ok variable is false i will be the zero of the type, 0 here.
+	job.ID = &i
+}
+
+func (job TOJob) GetAuditName() string {
+	if job.AssetURL != nil {
+		return *job.AssetURL
+	}
+	if job.ID != nil {
+		return strconv.Itoa(*job.ID)
+	}
+	return "unknown"
+}
+
+func (job TOJob) GetType() string {
+	return "job"
+}
+
+// ChangeLogMessage implements the api.ChangeLogger interface. Created jobs are logged like the Perl Traffic Ops.
+func (job TOJob) ChangeLogMessage(action string) (string, error) {
+	if job.DeliveryService == nil || job.AssetURL == nil || job.Parameters == nil {
+		return "", errors.New("job missing delivery service, asset URL or parameters")
+	}
+	desc := *job.DeliveryService + " [ " + *job.AssetURL + " - " + *job.Parameters + " ]"
+	if action == api.Created {
+		return "Invalidate content request submitted for " + desc, nil
+	}
+	return action + " invalidate content request for " + desc, nil
+}
+
+func (job TOJob) Validate(db *sqlx.DB) []error {
+	maxDays, err := ats.GetMaxRevalDurationDays(db.DB)
+	if err != nil {
+		log.Errorln("getting max reval duration days: " + err.Error())
+		return []error{tc.DBError}
+	}
+	errs := validation.Errors{
+		"dsId":      validation.Validate(job.DeliveryServiceID, validation.Required),
+		"keyword":   validation.Validate(job.Keyword, validation.In(tc.JobKeywordPurge)),
+		"regex":     validation.Validate(job.Regex, validation.Required, validation.By(isRegex)),
+		"startTime": validation.Validate(job.StartTime, validation.Required, validation.By(isNearStartTime)),
+		"ttl":       validation.Validate(job.TTL, validation.Required, validation.Min(ats.MinRevalTTLHours), validation.Max(maxDays*24)),
+	}
+	return tovalidate.ToErrors(errs)
+}
+
+// isRegex is a validation func which returns an error if the value isn't a valid regular expression. ATS uses PCRE, which Go's RE2 syntax is close enough to, to catch mistakes. PCRE features RE2 doesn't support, like lookarounds and backreferences, aren't checked, rather than rejected.
+func isRegex(value interface{}) error {
+	re, ok := value.(*string)
+	if !ok || re == nil {
+		return nil
+	}
+	if _, err := syntax.Parse(*re, syntax.Perl); err != nil {
+		if err, ok := err.(*syntax.Error); ok && (err.Code == syntax.ErrInvalidPerlOp || err.Code == syntax.ErrInvalidEscape) {
+			return nil
+		}
+		return errors.New("must be a valid regular expression; PCRE lookarounds and backreferences are allowed, but otherwise it must be valid Go RE2 syntax: " + err.Error())
+	}
+	return nil
+}
+
+// isNearStartTime is a validation func which returns an error if the value isn't a start time within MaxStartTimeWindow of now.
+func isNearStartTime(value interface{}) error {
+	st, ok := value.(*string)
+	if !ok || st == nil {
+		return nil
+	}
+	t, err := ParseStartTime(*st)
+	if err != nil {
+		return errors.New("must be in the form YYYY-MM-DD HH:MM:SS")
+	}
+	if diff := time.Since(t); diff > MaxStartTimeWindow || diff < -MaxStartTimeWindow {
+		return errors.New("must be within two days from now")
+	}
+	return nil
+}
+
+// ParseStartTime parses a job start time in the StartTimeLayout or tc.TimeLayout format.
+func ParseStartTime(s string) (time.Time, error) {
+	if t, err := time.Parse(tc.TimeLayout, s); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation(StartTimeLayout, s, time.Local)
+}
+
+// MakeAssetURL returns the asset URL regex of a job invalidating the given path regex of a delivery service with the given origin.
+func MakeAssetURL(orgServerFQDN string, regex string) string {
+	return strings.TrimSuffix(orgServerFQDN, "/") + "/" + strings.TrimPrefix(regex, "/")
+}
+
+// MakeParameters returns the job parameters of the given TTL in hours, which regex_revalidate.config is generated from.
+func MakeParameters(ttlHours int) string {
+	return "TTL:" + strconv.Itoa(ttlHours) + "h"
+}
+
+// IsTenantAuthorized implements the Tenantable interface to ensure the user is authorized on the delivery service of the job, and of the existing job, if it exists.
+func (job TOJob) IsTenantAuthorized(user auth.CurrentUser, db *sqlx.DB) (bool, error) {
+	if job.ID != nil && *job.ID != 0 {
+		dsID := sql.NullInt64{}
+		err := db.QueryRow(`SELECT job_deliveryservice FROM job WHERE id = $1`, *job.ID).Scan(&dsID)
+		if err != nil && err != sql.ErrNoRows {
+			return false, errors.New("querying job delivery service: " + err.Error())
+		}
+		if err == nil && dsID.Valid {
+			authorized, err := isDSAuthorized(int(dsID.Int64), user, db)
+			if err != nil || !authorized {
+				return authorized, err
+			}
+		}
+	}
+	if job.DeliveryServiceID == nil {
+		return true, nil
+	}
+	return isDSAuthorized(*job.DeliveryServiceID, user, db)
+}
+
+// isDSAuthorized returns whether the user may invalidate content of the given delivery service. Like the Perl Traffic Ops, if tenancy is disabled, users without the WriteUnassignedCapability must be assigned to the delivery service.
+// Delivery services which don't exist are authorized, leaving the caller to handle them.
+func isDSAuthorized(dsID int, user auth.CurrentUser, db *sqlx.DB) (bool, error) {
+	tenantID := sql.NullInt64{}
+	if err := db.QueryRow(`SELECT tenant_id FROM deliveryservice WHERE id = $1`, dsID).Scan(&tenantID); err != nil {
+		if err == sql.ErrNoRows {
+			return true, nil
+		}
+		return false, errors.New("querying delivery service tenant: " + err.Error())
+	}
+	useTenancy, err := tenant.IsTenancyEnabled(db)
+	if err != nil {
+		return false, errors.New("checking tenancy: " + err.Error())
+	}
+	if !useTenancy {
+		if user.HasCapability(WriteUnassignedCapability) {
+			return true, nil
+		}
+		assigned := false
+		if err := db.QueryRow(`SELECT EXISTS(SELECT 1 FROM deliveryservice_tmuser WHERE deliveryservice = $1 AND tm_user_id = $2)`, dsID, user.ID).Scan(&assigned); err != nil {
+			return false, errors.New("querying delivery service user assignment: " + err.Error())
+		}
+		return assigned, nil
+	}
+	if !tenantID.Valid {
+		return true, nil
+	}
+	return tenant.IsResourceAuthorizedToUser(int(tenantID.Int64), user, db)
+}
+
+func (job *TOJob) Read(db *sqlx.DB, parameters map[string]string, user auth.CurrentUser) ([]interface{}, []error, tc.ApiErrorType) {
+	queryParamsToQueryCols := map[string]dbhelpers.WhereColumnInfo{
+		"assetUrl":        dbhelpers.WhereColumnInfo{"j.asset_url", nil},
+		"createdBy":       dbhelpers.WhereColumnInfo{"u.username", nil},
+		"deliveryService": dbhelpers.WhereColumnInfo{"ds.xml_id", nil},
+		"dsId":            dbhelpers.WhereColumnInfo{"j.job_deliveryservice", api.IsInt},
+		"id":              dbhelpers.WhereColumnInfo{"j.id", api.IsInt},
+		"keyword":         dbhelpers.WhereColumnInfo{"j.keyword", nil},
+		"startTime":       dbhelpers.WhereColumnInfo{"j.start_time", nil},
+		"userId":          dbhelpers.WhereColumnInfo{"j.job_user", api.IsInt},
+	}
+
+	p := parameters
+	if _, ok := parameters["orderby"]; !ok {
+		// like the Perl Traffic Ops, default to the newest jobs first. Making a copy of parameters to not modify input arg
+		p = make(map[string]string, len(parameters))
+		for k, v := range parameters {
+			p[k] = v
+		}
+		p["orderby"] = "startTime"
+		p["sortOrder"] = "desc"
+	}
+
+	where, orderBy, queryValues, errs := dbhelpers.BuildWhereAndOrderBy(p, queryParamsToQueryCols)
+	if len(errs) > 0 {
+		return nil, errs, tc.DataConflictError
+	}
+
+	if !user.HasCapability(ReadUnassignedCapability) {
+		useTenancy, err := tenant.IsTenancyEnabled(db)
+		if err != nil {
+			log.Errorln("checking tenancy: " + err.Error())
+			return nil, []error{tc.DBError}, tc.SystemError
+		}
+		if !useTenancy {
+			if where == "" {
+				where = "\nWHERE "
+			} else {
+				where += " AND "
+			}
+			where += "ds.id IN (SELECT deliveryservice FROM deliveryservice_tmuser WHERE tm_user_id = :current_user_id)"
+			queryValues["current_user_id"] = user.ID
+		}
+	}
+	where = tenant.AddTenancyCheck(where, queryValues, "ds.tenant_id", user)
+
+	query := selectQuery() + where + orderBy
+	log.Debugln("Query is ", query)
+
+	rows, err := db.NamedQuery(query, queryValues)
+	if err != nil {
+		log.Errorln("querying jobs: " + err.Error())
+		return nil, []error{tc.DBError}, tc.SystemError
+	}
+	defer rows.Close()
+
+	jobs := []interface{}{}
+	for rows.Next() {
+		j := tc.JobNullable{}
+		if err = rows.StructScan(&j); err != nil {
+			log.Errorln("scanning jobs: " + err.Error())
+			return nil, []error{tc.DBError}, tc.SystemError
+		}
+		jobs = append(jobs, j)
+	}
+	return jobs, []error{}, tc.NoError
+}
+
+// Create implements the api.Creator interface. It creates a purge job for the delivery service, and queues revalidation on the servers which will invalidate its content.
+func (job *TOJob) Create(db *sqlx.DB, user auth.CurrentUser) (error, tc.ApiErrorType) {
+	startTime, err := ParseStartTime(*job.StartTime)
+	if err != nil {
+		return errors.New("startTime must be in the form YYYY-MM-DD HH:MM:SS"), tc.DataConflictError
+	}
+	if job.Urgent == nil || !*job.Urgent {
+		startTime = startTime.Add(NonUrgentDelay)
+	}
+
+	rollbackTransaction := true
+	tx, err := db.Beginx()
+	defer func() {
+		if tx == nil || !rollbackTransaction {
+			return
+		}
+		err := tx.Rollback()
+		if err != nil {
+			log.Errorln(errors.New("rolling back transaction: " + err.Error()))
+		}
+	}()
+
+	if err != nil {
+		log.Error.Printf("could not begin transaction: %v", err)
+		return tc.DBError, tc.SystemError
+	}
+
+	xmlID, orgServerFQDN, ok, err := getDSOrigin(tx, *job.DeliveryServiceID)
+	if err != nil {
+		log.Errorln("getting job delivery service origin: " + err.Error())
+		return tc.DBError, tc.SystemError
+	}
+	if !ok {
+		return errors.New("no delivery service with that id"), tc.DataMissingError
+	}
+
+	assetURL := MakeAssetURL(orgServerFQDN, *job.Regex)
+	params := MakeParameters(*job.TTL)
+	id := 0
+	startTimeStr := ""
+	if err := tx.QueryRow(insertQuery(), tc.JobKeywordPurge, params, assetURL, assetType, startTime, user.ID, *job.DeliveryServiceID).Scan(&id, &startTimeStr); err != nil {
+		log.Errorln("inserting job: " + err.Error())
+		return tc.DBError, tc.SystemError
+	}
+
+	if err := queueRevalidation(tx, *job.DeliveryServiceID); err != nil {
+		log.Errorln("queueing revalidation for job: " + err.Error())
+		return tc.DBError, tc.SystemError
+	}
+
+	keyword := tc.JobKeywordPurge
+	job.SetKeys(map[string]interface{}{"id": id})
+	job.AssetURL = &assetURL
+	job.CreatedBy = &user.UserName
+	job.DeliveryService = &xmlID
+	job.Keyword = &keyword
+	job.Parameters = &params
+	job.StartTime = &startTimeStr
+	err = tx.Commit()
+	if err != nil {
+		log.Errorln("Could not commit transaction: ", err)
+		return tc.DBError, tc.SystemError
+	}
+	rollbackTransaction = false
+	return nil, tc.NoError
+}
+
+// Update implements the api.Updater interface. It changes the regex, TTL and start time of the job, and queues revalidation. A job's delivery service can't be changed.
+func (job *TOJob) Update(db *sqlx.DB, user auth.CurrentUser) (error, tc.ApiErrorType) {
+	rollbackTransaction := true
+	tx, err := db.Beginx()
+	defer func() {
+		if tx == nil || !rollbackTransaction {
+			return
+		}
+		err := tx.Rollback()
+		if err != nil {
+			log.Errorln(errors.New("rolling back transaction: " + err.Error()))
+		}
+	}()
+
+	if err != nil {
+		log.Error.Printf("could not begin transaction: %v", err)
+		return tc.DBError, tc.SystemError
+	}
+	if err, errType := job.UpdateTx(tx, user); err != nil {
+		return err, errType
+	}
+	err = tx.Commit()
+	if err != nil {
+		log.Errorln("Could not commit transaction: ", err)
+		return tc.DBError, tc.SystemError
+	}
+	rollbackTransaction = false
+	return nil, tc.NoError
+}
+
+// UpdateTx updates the job in the given transaction, which the caller commits or rolls back.
+func (job *TOJob) UpdateTx(tx *sqlx.Tx, user auth.CurrentUser) (error, tc.ApiErrorType) {
+	startTime, err := ParseStartTime(*job.StartTime)
+	if err != nil {
+		return errors.New("startTime must be in the form YYYY-MM-DD HH:MM:SS"), tc.DataConflictError
+	}
+	if job.Urgent == nil || !*job.Urgent {
+		startTime = startTime.Add(NonUrgentDelay)
+	}
+
+	current, ok, err := getJob(tx, *job.ID)
+	if err != nil {
+		log.Errorln("getting job: " + err.Error())
+		return tc.DBError, tc.SystemError
+	}
+	if !ok {
+		return errors.New("no job found with this id"), tc.DataMissingError
+	}
+	if current.DeliveryServiceID == nil || *current.DeliveryServiceID != *job.DeliveryServiceID {
+		return errors.New("the delivery service of a job cannot be changed"), tc.DataConflictError
+	}
+
+	_, orgServerFQDN, ok, err := getDSOrigin(tx, *job.DeliveryServiceID)
+	if err != nil {
+		log.Errorln("getting job delivery service origin: " + err.Error())
+		return tc.DBError, tc.SystemError
+	}
+	if !ok {
+		return errors.New("no delivery service with that id"), tc.DataMissingError
+	}
+
+	assetURL := MakeAssetURL(orgServerFQDN, *job.Regex)
+	params := MakeParameters(*job.TTL)
+	startTimeStr := ""
+	if err := tx.QueryRow(updateQuery(), params, assetURL, startTime, *job.ID).Scan(&startTimeStr); err != nil {
+		log.Errorln("updating job: " + err.Error())
+		return tc.DBError, tc.SystemError
+	}
+
+	if err := queueRevalidation(tx, *job.DeliveryServiceID); err != nil {
+		log.Errorln("queueing revalidation for job: " + err.Error())
+		return tc.DBError, tc.SystemError
+	}
+
+	job.AssetURL = &assetURL
+	job.CreatedBy = current.CreatedBy
+	job.DeliveryService = current.DeliveryService
+	job.Keyword = current.Keyword
+	job.Parameters = &params
+	job.StartTime = &startTimeStr
+	return nil, tc.NoError
+}
+
+// Delete implements the api.Deleter interface. It deletes the job, and queues revalidation, so caches stop invalidating its content.
+func (job *TOJob) Delete(db *sqlx.DB, user auth.CurrentUser) (error, tc.ApiErrorType) {
+	rollbackTransaction := true
+	tx, err := db.Beginx()
+	defer func() {
+		if tx == nil || !rollbackTransaction {
+			return
+		}
+		err := tx.Rollback()
+		if err != nil {
+			log.Errorln(errors.New("rolling back transaction: " + err.Error()))
+		}
+	}()
+
+	if err != nil {
+		log.Error.Printf("could not begin transaction: %v", err)
+		return tc.DBError, tc.SystemError
+	}
+	if err, errType := job.DeleteTx(tx, user); err != nil {
+		return err, errType
+	}
+	err = tx.Commit()
+	if err != nil {
+		log.Errorln("Could not commit transaction: ", err)
+		return tc.DBError, tc.SystemError
+	}
+	rollbackTransaction = false
+	return nil, tc.NoError
+}
+
+// DeleteTx deletes the job in the given transaction, which the caller commits or rolls back.
+func (job *TOJob) DeleteTx(tx *sqlx.Tx, user auth.CurrentUser) (error, tc.ApiErrorType) {
+	current, ok, err := getJob(tx, *job.ID)
+	if err != nil {
+		log.Errorln("getting job: " + err.Error())
+		return tc.DBError, tc.SystemError
+	}
+	if !ok {
+		return errors.New("no job found with this id"), tc.DataMissingError
+	}
+
+	if _, err := tx.Exec(`DELETE FROM job WHERE id = $1`, *job.ID); err != nil {
+		log.Errorln("deleting job: " + err.Error())
+		return tc.DBError, tc.SystemError
+	}
+
+	if current.DeliveryServiceID != nil {
+		if err := queueRevalidation(tx, *current.DeliveryServiceID); err != nil {
+			log.Errorln("queueing revalidation for job: " + err.Error())
+			return tc.DBError, tc.SystemError
+		}
+	}
+
+	*job = TOJob(current)
+	return nil, tc.NoError
+}
+
+// getJob returns the job with the given ID, and whether it existed.
+func getJob(tx *sqlx.Tx, id int) (tc.JobNullable, bool, error) {
+	j := tc.JobNullable{}
+	if err := tx.QueryRowx(selectQuery()+`WHERE j.id = $1`, id).StructScan(&j); err != nil {
+		if err == sql.ErrNoRows {
+			return tc.JobNullable{}, false, nil
+		}
+		return tc.JobNullable{}, false, errors.New("querying job: " + err.Error())
+	}
+	return j, true, nil
+}
+
+// getDSOrigin returns the xml_id and origin of the given delivery service, and whether it existed.
+func getDSOrigin(tx *sqlx.Tx, dsID int) (string, string, bool, error) {
+	xmlID := ""
+	orgServerFQDN := ""
+	if err := tx.QueryRow(`SELECT xml_id, COALESCE(org_server_fqdn, '') FROM deliveryservice WHERE id = $1`, dsID).Scan(&xmlID, &orgServerFQDN); err != nil {
+		if err == sql.ErrNoRows {
+			return "", "", false, nil
+		}
+		return "", "", false, errors.New("querying delivery service origin: " + err.Error())
+	}
+	return xmlID, orgServerFQDN, true, nil
+}
+
+// queueRevalidation queues revalidation on the servers in the CDN of the given delivery service, so they get the new regex_revalidate.config. Like the Perl Traffic Ops, only servers which aren't OFFLINE or PRE_PROD, and whose profile has a regex_revalidate.config location, are queued; and if the global use_reval_pending parameter isn't set, a full update is queued instead.
+func queueRevalidation(tx *sqlx.Tx, dsID int) error {
+	qry := `
+WITH use_reval_pending AS (
+  SELECT COALESCE((SELECT p.value FROM parameter as p WHERE p.name = 'use_reval_pending' AND p.config_file = 'global' LIMIT 1), '0') <> '0' AS value
+)
+UPDATE server SET
+  reval_pending = (server.reval_pending OR use_reval_pending.value),
+  upd_pending = (server.upd_pending OR NOT use_reval_pending.value)
+FROM use_reval_pending
+WHERE server.cdn_id = (SELECT ds.cdn_id FROM deliveryservice as ds WHERE ds.id = $1)
+AND server.status NOT IN (SELECT st.id FROM status as st WHERE st.name IN ('OFFLINE', 'PRE_PROD'))
+AND server.profile IN (
+  SELECT pp.profile FROM profile_parameter as pp
+  JOIN parameter as p ON pp.parameter = p.id
+  WHERE p.name = 'location' AND p.config_file = $2
+)
+`
+	if _, err := tx.Exec(qry, dsID, ats.RegexRevalidateFileName); err != nil {
+		return errors.New("updating servers: " + err.Error())
+	}
+	return nil
+}
+
+// selectQuery returns the query of jobs. The start time is selected as text, which is in the tc.TimeLayout format, like the Perl Traffic Ops. It uses CAST rather than '::', because it's used in sqlx named queries.
+func selectQuery() string {
+	query := `SELECT
+j.id,
+j.asset_url,
+u.username AS created_by,
+ds.xml_id AS deliveryservice,
+j.job_deliveryservice AS ds_id,
+j.keyword,
+COALESCE(j.parameters, '') AS parameters,
+CAST(j.start_time AS text) AS start_time
+FROM job j
+JOIN tm_user u ON j.job_user = u.id
+LEFT JOIN deliveryservice ds ON j.job_deliveryservice = ds.id
+`
+	return query
+}
+
+func insertQuery() string {
+	query := `INSERT INTO job (
+agent,
+keyword,
+parameters,
+asset_url,
+asset_type,
+status,
+start_time,
+entered_time,
+job_user,
+job_deliveryservice) VALUES (
+(SELECT id FROM job_agent WHERE name = 'dummy'),
+$1,
+$2,
+$3,
+$4,
+(SELECT id FROM job_status WHERE name = 'PENDING'),
+$5,
+now(),
+$6,
+$7) RETURNING id, CAST(start_time AS text)`
+	return query
+}
+
+func updateQuery() string {
+	query := `UPDATE job SET
+parameters=$1,
+asset_url=$2,
+start_time=$3
+WHERE id=$4 RETURNING CAST(start_time AS text)`
+	return query
+}
@@ -0,0 +1,153 @@
+package job
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+	"github.com/jmoiron/sqlx"
+
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func strPtr(s string) *string { return &s }
+func intPtr(i int) *int       { return &i }
+
+func TestMakeAssetURL(t *testing.T) {
+	tests := []struct {
+		origin   string
+		regex    string
+		expected string
+	}{
+		{"http://origin.example.net", "/path/.*\\.jpg", "http://origin.example.net/path/.*\\.jpg"},
+		{"http://origin.example.net", "path/.*", "http://origin.example.net/path/.*"},
+		{"http://origin.example.net/", "/path", "http://origin.example.net/path"},
+	}
+	for _, test := range tests {
+		if actual := MakeAssetURL(test.origin, test.regex); actual != test.expected {
+			t.Errorf("MakeAssetURL(%q, %q) expected: %q, actual: %q", test.origin, test.regex, test.expected, actual)
+		}
+	}
+}
+
+func TestParseStartTime(t *testing.T) {
+	st, err := ParseStartTime("2018-05-01 12:00:00+00")
+	if err != nil {
+		t.Fatalf("ParseStartTime with offset expected: no error, actual: %v", err)
+	}
+	if expected := time.Date(2018, 5, 1, 12, 0, 0, 0, time.UTC); !st.Equal(expected) {
+		t.Errorf("ParseStartTime with offset expected: %v, actual: %v", expected, st)
+	}
+
+	st, err = ParseStartTime("2018-05-01 12:00:00")
+	if err != nil {
+		t.Fatalf("ParseStartTime expected: no error, actual: %v", err)
+	}
+	if expected := time.Date(2018, 5, 1, 12, 0, 0, 0, time.Local); !st.Equal(expected) {
+		t.Errorf("ParseStartTime expected: %v, actual: %v", expected, st)
+	}
+
+	if _, err := ParseStartTime("05/01/2018"); err == nil {
+		t.Errorf("ParseStartTime of an invalid time expected: error, actual: nil")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	now := time.Now().Format(StartTimeLayout)
+	tests := []struct {
+		name string
+		job  TOJob
+		errs []string
+	}{
+		{"valid", TOJob{DeliveryServiceID: intPtr(1), Regex: strPtr(`/path/.*\.jpg`), StartTime: strPtr(now), TTL: intPtr(48)}, nil},
+		{"missing", TOJob{}, []string{"dsId", "regex", "startTime", "ttl"}},
+		{"pcre lookahead", TOJob{DeliveryServiceID: intPtr(1), Regex: strPtr(`/path/(?!keep/).*\.jpg`), StartTime: strPtr(now), TTL: intPtr(48)}, nil},
+		{"pcre backreference", TOJob{DeliveryServiceID: intPtr(1), Regex: strPtr(`/(a|b)/\1/.*`), StartTime: strPtr(now), TTL: intPtr(48)}, nil},
+		{"bad regex", TOJob{DeliveryServiceID: intPtr(1), Regex: strPtr(`/path/(.*`), StartTime: strPtr(now), TTL: intPtr(48)}, []string{"regex"}},
+		{"ttl too long", TOJob{DeliveryServiceID: intPtr(1), Regex: strPtr(`/path`), StartTime: strPtr(now), TTL: intPtr(24*30 + 1)}, []string{"ttl"}},
+		{"start too late", TOJob{DeliveryServiceID: intPtr(1), Regex: strPtr(`/path`), StartTime: strPtr(time.Now().Add(72 * time.Hour).Format(StartTimeLayout)), TTL: intPtr(1)}, []string{"startTime"}},
+		{"bad start", TOJob{DeliveryServiceID: intPtr(1), Regex: strPtr(`/path`), StartTime: strPtr("tomorrow"), TTL: intPtr(1)}, []string{"startTime"}},
+		{"bad keyword", TOJob{DeliveryServiceID: intPtr(1), Regex: strPtr(`/path`), StartTime: strPtr(now), TTL: intPtr(1), Keyword: strPtr("REFRESH")}, []string{"keyword"}},
+	}
+	for _, test := range tests {
+		mock.ExpectQuery("SELECT").WithArgs("maxRevalDurationDays", "regex_revalidate.config").WillReturnRows(sqlmock.NewRows([]string{"value"}).AddRow("30"))
+		errs := test.job.Validate(db)
+		if len(errs) != len(test.errs) {
+			t.Errorf("Validate %s expected: %d errors, actual: %v", test.name, len(test.errs), errs)
+			continue
+		}
+		for _, field := range test.errs {
+			found := false
+			for _, err := range errs {
+				if strings.HasPrefix(err.Error(), "'"+field+"'") {
+					found = true
+					break
+				}
+			}
+			if !found {
+				t.Errorf("Validate %s expected: error on '%s', actual: %v", test.name, field, errs)
+			}
+		}
+	}
+}
+
+func TestRead(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	cols := []string{"id", "asset_url", "created_by", "deliveryservice", "ds_id", "keyword", "parameters", "start_time"}
+	rows := sqlmock.NewRows(cols).
+		AddRow(2, "http://origin.example.net/b", "admin", "ds1", 1, "PURGE", "TTL:24h", "2018-05-02 12:00:00+00").
+		AddRow(1, "http://origin.example.net/a", "admin", "ds1", 1, "PURGE", "TTL:48h", "2018-05-01 12:00:00+00")
+	mock.ExpectQuery("SELECT").WithArgs("1", 3).WillReturnRows(rows)
+
+	user := auth.CurrentUser{ID: 5, TenantID: 3, PrivLevel: auth.PrivLevelOperations, Capabilities: []string{ReadUnassignedCapability}}
+	jobs, errs, _ := GetRefType().Read(db, map[string]string{"dsId": "1"}, user)
+	if len(errs) > 0 {
+		t.Fatalf("Read expected: no errors, actual: %v", errs)
+	}
+	if len(jobs) != 2 {
+		t.Fatalf("Read expected: 2 jobs, actual: %d", len(jobs))
+	}
+	j, ok := jobs[0].(tc.JobNullable)
+	if !ok {
+		t.Fatalf("Read expected: tc.JobNullable, actual: %T", jobs[0])
+	}
+	if *j.ID != 2 || *j.AssetURL != "http://origin.example.net/b" || *j.DeliveryService != "ds1" || *j.Parameters != "TTL:24h" || *j.StartTime != "2018-05-02 12:00:00+00" {
+		t.Errorf("Read expected: job 2, actual: %+v", j)
+	}
+}
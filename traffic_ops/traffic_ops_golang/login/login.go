@@ -0,0 +1,174 @@
+package login
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-log"
+	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/config"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/tocookie"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// DisallowedRoleName is the role of users who may not log in.
+const DisallowedRoleName = "disallowed"
+
+// LoginHandler authenticates the username and password of the request body with the configured authenticators, and sets the cookie which authenticates later requests. Users authenticated by LDAP are created or updated with the role and tenant of their groups.
+func LoginHandler(db *sqlx.DB, cfg config.Config) http.HandlerFunc {
+	authenticators := auth.GetAuthenticators(cfg, db)
+	return func(w http.ResponseWriter, r *http.Request) {
+		handleErrs := tc.GetHandleErrorsFunc(w, r)
+		defer r.Body.Close()
+
+		creds := tc.UserCredentials{}
+		if err := json.NewDecoder(r.Body).Decode(&creds); err != nil {
+			handleErrs(http.StatusBadRequest, errors.New("malformed login request: "+err.Error()))
+			return
+		}
+
+		identity, err := auth.Authenticate(authenticators, creds.Username, creds.Password)
+		if err != nil {
+			log.Errorln(err.Error())
+			handleErrs(http.StatusInternalServerError, errors.New("Unable to check credentials, please try again later."))
+			return
+		}
+		if identity == nil {
+			handleErrs(http.StatusUnauthorized, errors.New("Invalid username or password."))
+			return
+		}
+		if identity.Role != "" {
+			if err := syncUser(db, *identity); err != nil {
+				log.Errorln("syncing user '" + identity.UserName + "': " + err.Error())
+				handleErrs(http.StatusInternalServerError, tc.DBError)
+				return
+			}
+		}
+
+		allowed, err := isAllowed(db, identity.UserName)
+		if err != nil {
+			log.Errorln("checking user '" + identity.UserName + "': " + err.Error())
+			handleErrs(http.StatusInternalServerError, tc.DBError)
+			return
+		}
+		if !allowed {
+			handleErrs(http.StatusUnauthorized, errors.New("Invalid username or password."))
+			return
+		}
+
+		session, err := auth.CreateSession(db, identity.UserName, r.RemoteAddr, r.UserAgent())
+		if err != nil {
+			log.Errorln("creating session for user '" + identity.UserName + "': " + err.Error())
+			handleErrs(http.StatusInternalServerError, tc.DBError)
+			return
+		}
+
+		expiry := time.Now().Add(tocookie.DefaultDuration)
+		cookieVal := tocookie.NewSession(identity.UserName, session, expiry, cfg.Secrets[0])
+		http.SetCookie(w, &http.Cookie{Name: tocookie.Name, Value: cookieVal, Path: "/", HttpOnly: true})
+		writeAlert(w, handleErrs, "Successfully logged in.")
+	}
+}
+
+// LogoutHandler expires the cookie of the logged in user, and revokes its session, so copies of the cookie are no longer valid. Cookies created by the Perl Traffic Ops have no session, and copies of them remain valid until they expire.
+func LogoutHandler(db *sqlx.DB, cfg config.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		handleErrs := tc.GetHandleErrorsFunc(w, r)
+		if cookie, err := r.Cookie(tocookie.Name); err == nil {
+			if parsed, err := tocookie.Parse(cfg.Secrets[0], cookie.Value); err == nil && parsed.Session != "" {
+				if err := auth.DeleteSession(db, parsed.Session); err != nil {
+					log.Errorln(err.Error())
+					handleErrs(http.StatusInternalServerError, tc.DBError)
+					return
+				}
+			}
+		}
+		// the auth middleware sets a refreshed cookie, which this replaces
+		w.Header().Del("Set-Cookie")
+		http.SetCookie(w, &http.Cookie{Name: tocookie.Name, Value: "", Path: "/", HttpOnly: true, MaxAge: -1, Expires: time.Unix(0, 0)})
+		writeAlert(w, handleErrs, "You are logged out.")
+	}
+}
+
+// isAllowed returns whether the user exists in Traffic Ops, and is allowed to log in. Users authenticated outside Traffic Ops, such as by LDAP, must still exist, because their role is in Traffic Ops.
+func isAllowed(db *sqlx.DB, username string) (bool, error) {
+	roleName := ""
+	q := `SELECT COALESCE(r.name, '') FROM tm_user AS u LEFT JOIN role AS r ON u.role = r.id WHERE u.username = $1`
+	if err := db.QueryRow(q, username).Scan(&roleName); err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, errors.New("querying user role: " + err.Error())
+	}
+	return roleName != DisallowedRoleName, nil
+}
+
+// syncUser sets the role and tenant of a user authenticated outside Traffic Ops, creating the user if the identity is provisioned. Users with a local password are managed in Traffic Ops, and aren't changed.
+func syncUser(db *sqlx.DB, identity auth.Identity) error {
+	roleID := 0
+	if err := db.QueryRow(`SELECT id FROM role WHERE name = $1`, identity.Role).Scan(&roleID); err != nil {
+		if err == sql.ErrNoRows {
+			return errors.New("role '" + identity.Role + "' does not exist")
+		}
+		return errors.New("querying role: " + err.Error())
+	}
+	tenantID := sql.NullInt64{}
+	if identity.Tenant != "" {
+		if err := db.QueryRow(`SELECT id FROM tenant WHERE name = $1`, identity.Tenant).Scan(&tenantID); err != nil {
+			if err == sql.ErrNoRows {
+				return errors.New("tenant '" + identity.Tenant + "' does not exist")
+			}
+			return errors.New("querying tenant: " + err.Error())
+		}
+	}
+
+	q := `
+WITH updated AS (
+  UPDATE tm_user SET role = $2, tenant_id = $3 WHERE username = $1 AND local_passwd IS NULL RETURNING id
+)
+INSERT INTO tm_user (username, role, tenant_id, new_user)
+SELECT $1, $2, $3, false WHERE $4 AND NOT EXISTS (SELECT 1 FROM tm_user WHERE username = $1)
+`
+	result, err := db.Exec(q, identity.UserName, roleID, tenantID, identity.Provision)
+	if err != nil {
+		return errors.New("updating user: " + err.Error())
+	}
+	if created, err := result.RowsAffected(); err == nil && created > 0 {
+		log.Infoln("provisioned user '" + identity.UserName + "' with role '" + identity.Role + "'")
+	}
+	return nil
+}
+
+func writeAlert(w http.ResponseWriter, handleErrs func(status int, errs ...error), msg string) {
+	respBts, err := json.Marshal(tc.CreateAlerts(tc.SuccessLevel, msg))
+	if err != nil {
+		handleErrs(http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set(tc.ContentType, tc.ApplicationJson)
+	w.Write(respBts)
+}
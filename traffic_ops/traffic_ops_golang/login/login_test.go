@@ -0,0 +1,164 @@
+package login
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/config"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/tocookie"
+	"github.com/jmoiron/sqlx"
+
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestLoginHandler(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	hashed, err := auth.DerivePassword("password")
+	if err != nil {
+		t.Fatalf("deriving password: %v", err)
+	}
+	cfg := config.Config{Secrets: []string{"secret"}, ConfigTrafficOpsGolang: config.ConfigTrafficOpsGolang{Authenticators: []string{config.AuthenticatorLocal}}}
+
+	tests := []struct {
+		name     string
+		passwd   interface{}
+		role     string
+		body     string
+		status   int
+		loggedIn bool
+	}{
+		{"valid", hashed, "admin", `{"u":"user1","p":"password"}`, http.StatusOK, true},
+		{"wrong password", hashed, "admin", `{"u":"user1","p":"wrong"}`, http.StatusUnauthorized, false},
+		{"disallowed", hashed, DisallowedRoleName, `{"u":"user1","p":"password"}`, http.StatusUnauthorized, false},
+		{"no local password", nil, "admin", `{"u":"user1","p":"password"}`, http.StatusUnauthorized, false},
+	}
+	for _, test := range tests {
+		mock.ExpectQuery("SELECT local_passwd").WithArgs("user1").WillReturnRows(sqlmock.NewRows([]string{"local_passwd"}).AddRow(test.passwd))
+		if test.status == http.StatusOK || test.role == DisallowedRoleName {
+			mock.ExpectQuery("SELECT COALESCE").WithArgs("user1").WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow(test.role))
+		}
+		if test.loggedIn {
+			mock.ExpectExec("DELETE FROM user_session").WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec("INSERT INTO user_session").WithArgs(sqlmock.AnyArg(), "user1", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+		}
+
+		w := httptest.NewRecorder()
+		r, err := http.NewRequest(http.MethodPost, "/api/1.3/user/login", strings.NewReader(test.body))
+		if err != nil {
+			t.Fatal("Error creating new request")
+		}
+		LoginHandler(db, cfg)(w, r)
+
+		status, ok := r.Context().Value(tc.StatusKey).(int)
+		if !ok {
+			status = http.StatusOK
+		}
+		if status != test.status {
+			t.Errorf("%s: expected status %v, actual %v", test.name, test.status, status)
+		}
+		cookie := w.Header().Get("Set-Cookie")
+		if loggedIn := strings.HasPrefix(cookie, tocookie.Name+"="); loggedIn != test.loggedIn {
+			t.Errorf("%s: expected logged in %v, actual cookie '%s'", test.name, test.loggedIn, cookie)
+		}
+		if test.loggedIn {
+			parsed, err := tocookie.Parse(cfg.Secrets[0], strings.TrimPrefix(strings.Split(cookie, ";")[0], tocookie.Name+"="))
+			if err != nil || parsed.Session == "" {
+				t.Errorf("%s: expected a cookie with a session, actual %+v error %v", test.name, parsed, err)
+			}
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestSyncUser(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	identity := auth.Identity{UserName: "alice", Role: "operations", Tenant: "ops", Provision: true}
+	mock.ExpectQuery("SELECT id FROM role").WithArgs("operations").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+	mock.ExpectQuery("SELECT id FROM tenant").WithArgs("ops").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+	mock.ExpectExec("UPDATE tm_user").WithArgs("alice", 3, 5, true).WillReturnResult(sqlmock.NewResult(0, 1))
+	if err := syncUser(db, identity); err != nil {
+		t.Errorf("expected no error, actual %v", err)
+	}
+
+	identity.Role = "nonexistent"
+	mock.ExpectQuery("SELECT id FROM role").WithArgs("nonexistent").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	if err := syncUser(db, identity); err == nil || err.Error() != "role 'nonexistent' does not exist" {
+		t.Errorf("expected nonexistent role error, actual %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestLogoutHandler(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+	cfg := config.Config{Secrets: []string{"secret"}}
+
+	w := httptest.NewRecorder()
+	r, err := http.NewRequest(http.MethodPost, "/api/1.3/user/logout", nil)
+	if err != nil {
+		t.Fatal("Error creating new request")
+	}
+	r.AddCookie(&http.Cookie{Name: tocookie.Name, Value: tocookie.NewSession("user1", "session1", time.Now().Add(time.Hour), cfg.Secrets[0])})
+	http.SetCookie(w, &http.Cookie{Name: tocookie.Name, Value: "refreshed", Path: "/"})
+	mock.ExpectExec("DELETE FROM user_session").WithArgs("session1").WillReturnResult(sqlmock.NewResult(0, 1))
+	LogoutHandler(db, cfg)(w, r)
+
+	cookies := w.HeaderMap["Set-Cookie"]
+	if len(cookies) != 1 || !strings.HasPrefix(cookies[0], tocookie.Name+"=;") || !strings.Contains(cookies[0], "Max-Age=0") {
+		t.Errorf("expected a single expired cookie, actual %v", cookies)
+	}
+	expected := `{"alerts":[{"text":"You are logged out.","level":"success"}]}`
+	if w.Body.String() != expected {
+		t.Errorf("expected body %s, actual %s", expected, w.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
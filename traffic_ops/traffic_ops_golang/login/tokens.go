@@ -0,0 +1,176 @@
+package login
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-log"
+	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// GetTokensHandler serves the API tokens of the current user. The tokens themselves aren't stored, so only their names and expirations are served.
+func GetTokensHandler(db *sqlx.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		handleErrs := tc.GetHandleErrorsFunc(w, r)
+		user, err := auth.GetCurrentUser(r.Context())
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, err)
+			return
+		}
+
+		tokens, err := getTokens(db, user.ID)
+		if err != nil {
+			log.Errorln("getting API tokens: " + err.Error())
+			handleErrs(http.StatusInternalServerError, tc.DBError)
+			return
+		}
+		respBts, err := json.Marshal(tc.APITokensResponse{Response: tokens})
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, err)
+			return
+		}
+		w.Header().Set(tc.ContentType, tc.ApplicationJson)
+		w.Write(respBts)
+	}
+}
+
+// CreateTokenHandler creates an API token for the current user, and serves it. This is the only time the token is served, because only its hash is stored.
+func CreateTokenHandler(db *sqlx.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		handleErrs := tc.GetHandleErrorsFunc(w, r)
+		defer r.Body.Close()
+		user, err := auth.GetCurrentUser(r.Context())
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, err)
+			return
+		}
+
+		req := tc.APITokenRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			handleErrs(http.StatusBadRequest, errors.New("malformed API token request: "+err.Error()))
+			return
+		}
+		if errs := validateTokenRequest(req); len(errs) > 0 {
+			handleErrs(http.StatusBadRequest, errs...)
+			return
+		}
+
+		token, err := auth.GenerateToken()
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, err)
+			return
+		}
+		created := tc.APITokenCreated{APIToken: tc.APIToken{Name: req.Name, Expires: req.Expires}, Token: token}
+		q := `INSERT INTO api_token (tm_user, name, token_hash, expires) VALUES ($1, $2, $3, $4) RETURNING id, last_updated`
+		if err := db.QueryRow(q, user.ID, req.Name, auth.HashToken(token), req.Expires).Scan(&created.ID, &created.LastUpdated); err != nil {
+			log.Errorln("inserting API token: " + err.Error())
+			handleErrs(http.StatusInternalServerError, tc.DBError)
+			return
+		}
+		api.CreateChangeLogRaw(api.ApiChange, "Created API token: "+req.Name+" id: "+strconv.Itoa(created.ID)+" for user: "+user.UserName, *user, db)
+
+		resp := tc.APITokenCreatedResponse{Response: created, Alerts: tc.CreateAlerts(tc.SuccessLevel, "API token was created. Save the token, it will not be shown again.")}
+		respBts, err := json.Marshal(resp)
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, err)
+			return
+		}
+		w.Header().Set(tc.ContentType, tc.ApplicationJson)
+		w.Write(respBts)
+	}
+}
+
+// DeleteTokenHandler revokes an API token of the current user. Admins may revoke the tokens of any user.
+func DeleteTokenHandler(db *sqlx.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		handleErrs := tc.GetHandleErrorsFunc(w, r)
+		user, err := auth.GetCurrentUser(r.Context())
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, err)
+			return
+		}
+		params, err := api.GetPathParams(r.Context())
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, err)
+			return
+		}
+		id, err := strconv.Atoi(params["id"])
+		if err != nil {
+			handleErrs(http.StatusBadRequest, errors.New("id must be an integer"))
+			return
+		}
+
+		name := ""
+		q := `DELETE FROM api_token WHERE id = $1 AND (tm_user = $2 OR $3) RETURNING name`
+		if err := db.QueryRow(q, id, user.ID, user.HasCapability("user-write")).Scan(&name); err != nil {
+			if err == sql.ErrNoRows {
+				handleErrs(http.StatusNotFound, errors.New("no API token with that id found"))
+				return
+			}
+			log.Errorln("deleting API token: " + err.Error())
+			handleErrs(http.StatusInternalServerError, tc.DBError)
+			return
+		}
+		api.CreateChangeLogRaw(api.ApiChange, "Revoked API token: "+name+" id: "+strconv.Itoa(id), *user, db)
+		writeAlert(w, handleErrs, "API token was revoked.")
+	}
+}
+
+func getTokens(db *sqlx.DB, userID int) ([]tc.APIToken, error) {
+	rows, err := db.Query(`SELECT id, name, expires, last_updated FROM api_token WHERE tm_user = $1 ORDER BY id`, userID)
+	if err != nil {
+		return nil, errors.New("querying API tokens: " + err.Error())
+	}
+	defer rows.Close()
+	tokens := []tc.APIToken{}
+	for rows.Next() {
+		token := tc.APIToken{}
+		expires := tc.Time{}
+		if err := rows.Scan(&token.ID, &token.Name, &expires, &token.LastUpdated); err != nil {
+			return nil, errors.New("scanning API tokens: " + err.Error())
+		}
+		if expires.Valid {
+			token.Expires = &expires.Time
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, nil
+}
+
+func validateTokenRequest(req tc.APITokenRequest) []error {
+	errs := []error{}
+	if req.Name == "" {
+		errs = append(errs, errors.New("name is required"))
+	}
+	if req.Expires != nil && !req.Expires.After(time.Now()) {
+		errs = append(errs, errors.New("expires must be in the future"))
+	}
+	return errs
+}
@@ -0,0 +1,136 @@
+package login
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+	"github.com/jmoiron/sqlx"
+
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func newTokenRequest(t *testing.T, method string, body string, user auth.CurrentUser, params map[string]string) *http.Request {
+	r, err := http.NewRequest(method, "/api/1.3/user/tokens", strings.NewReader(body))
+	if err != nil {
+		t.Fatal("Error creating new request")
+	}
+	ctx := context.WithValue(r.Context(), auth.CurrentUserKey, user)
+	ctx = context.WithValue(ctx, api.PathParamsKey, params)
+	return r.WithContext(ctx)
+}
+
+func TestCreateTokenHandler(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	user := auth.CurrentUser{UserName: "bot", ID: 2, PrivLevel: auth.PrivLevelOperations}
+	mock.ExpectQuery("INSERT INTO api_token").WithArgs(2, "ci", sqlmock.AnyArg(), nil).WillReturnRows(sqlmock.NewRows([]string{"id", "last_updated"}).AddRow(7, time.Now()))
+	mock.ExpectExec("INSERT INTO log").WillReturnResult(sqlmock.NewResult(1, 1))
+
+	w := httptest.NewRecorder()
+	r := newTokenRequest(t, http.MethodPost, `{"name":"ci"}`, user, map[string]string{})
+	CreateTokenHandler(db)(w, r)
+
+	resp := tc.APITokenCreatedResponse{}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshalling response '%s': %v", w.Body.String(), err)
+	}
+	if resp.Response.ID != 7 || resp.Response.Name != "ci" || resp.Response.Expires != nil {
+		t.Errorf("expected token 7 'ci' with no expiration, actual %+v", resp.Response)
+	}
+	if len(resp.Response.Token) == 0 {
+		t.Error("expected the created token in the response")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestCreateTokenHandlerInvalid(t *testing.T) {
+	mockDB, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	w := httptest.NewRecorder()
+	r := newTokenRequest(t, http.MethodPost, `{"expires":"2001-01-01T00:00:00Z"}`, auth.CurrentUser{ID: 2}, map[string]string{})
+	CreateTokenHandler(db)(w, r)
+
+	expected := `{"alerts":[{"text":"name is required","level":"error"},{"text":"expires must be in the future","level":"error"}]}`
+	if w.Body.String() != expected {
+		t.Errorf("expected body %s, actual %s", expected, w.Body.String())
+	}
+	if status, _ := r.Context().Value(tc.StatusKey).(int); status != http.StatusBadRequest {
+		t.Errorf("expected status %v, actual %v", http.StatusBadRequest, status)
+	}
+}
+
+func TestDeleteTokenHandler(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	user := auth.CurrentUser{UserName: "bot", ID: 2, PrivLevel: auth.PrivLevelOperations}
+
+	// only admins may revoke other users' tokens
+	mock.ExpectQuery("DELETE FROM api_token").WithArgs(7, 2, false).WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("ci"))
+	mock.ExpectExec("INSERT INTO log").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("DELETE FROM api_token").WithArgs(8, 2, false).WillReturnRows(sqlmock.NewRows([]string{"name"}))
+
+	w := httptest.NewRecorder()
+	r := newTokenRequest(t, http.MethodDelete, "", user, map[string]string{"id": "7"})
+	DeleteTokenHandler(db)(w, r)
+	expected := `{"alerts":[{"text":"API token was revoked.","level":"success"}]}`
+	if w.Body.String() != expected {
+		t.Errorf("expected body %s, actual %s", expected, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	r = newTokenRequest(t, http.MethodDelete, "", user, map[string]string{"id": "8"})
+	DeleteTokenHandler(db)(w, r)
+	if status, _ := r.Context().Value(tc.StatusKey).(int); status != http.StatusNotFound {
+		t.Errorf("expected status %v, actual %v", http.StatusNotFound, status)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
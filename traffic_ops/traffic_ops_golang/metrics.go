@@ -0,0 +1,65 @@
+package main
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/metrics"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var requestsTotal = metrics.NewCounter("traffic_ops_requests_total", "Requests served by Traffic Ops Golang routes, by route, method, and response code.", "route", "method", "code")
+var requestDuration = metrics.NewHistogram("traffic_ops_request_duration_seconds", "Latency of requests served by Traffic Ops Golang routes, by route and method.", nil, "route", "method")
+
+var proxyRequestsTotal = metrics.NewCounter("traffic_ops_proxy_requests_total", "Requests proxied to Traffic Ops Perl, by method and response code.", "method", "code")
+var proxyRequestDuration = metrics.NewHistogram("traffic_ops_proxy_request_duration_seconds", "Latency of requests proxied to Traffic Ops Perl, by method.", nil, "method")
+
+// wrapMetrics returns the handler of the given route, which counts and times its requests.
+func wrapMetrics(route string, method string, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		iw := &Interceptor{w: w}
+		h(iw, r)
+		requestsTotal.Inc(route, method, strconv.Itoa(interceptedCode(iw)))
+		requestDuration.Observe(time.Since(start).Seconds(), route, method)
+	}
+}
+
+// interceptedCode returns the response code written to the Interceptor. If nothing was written, net/http sends a 200.
+func interceptedCode(iw *Interceptor) int {
+	if iw.code == 0 {
+		return http.StatusOK
+	}
+	return iw.code
+}
+
+// registerDBMetrics registers gauges of the connection pool statistics of the given database. It must only be called once.
+func registerDBMetrics(db *sqlx.DB) {
+	metrics.NewGaugeFunc("traffic_ops_db_max_open_connections", "Maximum number of open connections to the database.", func() float64 { return float64(db.Stats().MaxOpenConnections) })
+	metrics.NewGaugeFunc("traffic_ops_db_open_connections", "Number of open connections to the database, in use or idle.", func() float64 { return float64(db.Stats().OpenConnections) })
+	metrics.NewGaugeFunc("traffic_ops_db_in_use_connections", "Number of database connections in use.", func() float64 { return float64(db.Stats().InUse) })
+	metrics.NewGaugeFunc("traffic_ops_db_idle_connections", "Number of idle database connections.", func() float64 { return float64(db.Stats().Idle) })
+	metrics.NewCounterFunc("traffic_ops_db_wait_count_total", "Number of times a request waited for a database connection.", func() float64 { return float64(db.Stats().WaitCount) })
+	metrics.NewCounterFunc("traffic_ops_db_wait_duration_seconds_total", "Time spent waiting for database connections.", func() float64 { return db.Stats().WaitDuration.Seconds() })
+}
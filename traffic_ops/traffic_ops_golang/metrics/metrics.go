@@ -0,0 +1,274 @@
+// Package metrics is a minimal registry of counters, histograms, and gauges, served in the Prometheus text exposition format.
+// Packages declare their metrics as package variables with NewCounter, NewHistogram, or NewGaugeFunc, which register them to be served by Handler.
+package metrics
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ContentType is the content type of the Prometheus text exposition format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefaultBuckets are the histogram bucket upper bounds, in seconds, suitable for the latency of most API requests.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+type metric interface {
+	name() string
+	write(w io.Writer)
+}
+
+var registry = struct {
+	m       sync.Mutex
+	metrics map[string]metric
+}{metrics: map[string]metric{}}
+
+// register adds the metric to those served by Handler. It panics if a metric of the same name is already registered, because metrics are declared at init, and a duplicate is a programming error.
+func register(m metric) {
+	registry.m.Lock()
+	defer registry.m.Unlock()
+	if _, ok := registry.metrics[m.name()]; ok {
+		panic("metric '" + m.name() + "' registered twice")
+	}
+	registry.metrics[m.name()] = m
+}
+
+// Write writes all registered metrics to w in the Prometheus text format, sorted by name.
+func Write(w io.Writer) {
+	registry.m.Lock()
+	metrics := make([]metric, 0, len(registry.metrics))
+	for _, m := range registry.metrics {
+		metrics = append(metrics, m)
+	}
+	registry.m.Unlock()
+	sort.Slice(metrics, func(i, j int) bool { return metrics[i].name() < metrics[j].name() })
+	for _, m := range metrics {
+		m.write(w)
+	}
+}
+
+// Handler serves all registered metrics in the Prometheus text format.
+func Handler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		buf := &bytes.Buffer{}
+		Write(buf)
+		w.Header().Set("Content-Type", ContentType)
+		w.Write(buf.Bytes())
+	}
+}
+
+// Counter is a count which only increases, partitioned by label values.
+type Counter struct {
+	metricName string
+	help       string
+	labels     []string
+	m          sync.Mutex
+	values     map[string]float64
+}
+
+// NewCounter creates and registers a counter with the given label names. The name should end in _total.
+func NewCounter(name, help string, labels ...string) *Counter {
+	c := &Counter{metricName: name, help: help, labels: labels, values: map[string]float64{}}
+	register(c)
+	return c
+}
+
+// Inc increments the counter with the given label values, which must be given in the order of the counter's labels.
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds v to the counter with the given label values. Negative values are ignored, because counters never decrease.
+func (c *Counter) Add(v float64, labelValues ...string) {
+	if v < 0 {
+		return
+	}
+	key := labelKey(c.labels, labelValues)
+	c.m.Lock()
+	c.values[key] += v
+	c.m.Unlock()
+}
+
+func (c *Counter) name() string { return c.metricName }
+
+func (c *Counter) write(w io.Writer) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	writeHeader(w, c.metricName, c.help, "counter")
+	for _, key := range sortedKeys(c.values) {
+		fmt.Fprintf(w, "%s%s %s\n", c.metricName, key, formatFloat(c.values[key]))
+	}
+}
+
+// Histogram counts observations, such as durations, in cumulative buckets, partitioned by label values.
+type Histogram struct {
+	metricName string
+	help       string
+	labels     []string
+	buckets    []float64
+	m          sync.Mutex
+	values     map[string]*histogramValue
+}
+
+type histogramValue struct {
+	labelValues []string
+	counts      []uint64 // counts[i] is the number of observations <= buckets[i], and not in a smaller bucket.
+	count       uint64
+	sum         float64
+}
+
+// NewHistogram creates and registers a histogram with the given bucket upper bounds and label names. If buckets is nil, DefaultBuckets are used.
+func NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	h := &Histogram{metricName: name, help: help, labels: labels, buckets: buckets, values: map[string]*histogramValue{}}
+	register(h)
+	return h
+}
+
+// Observe records the observation v with the given label values, which must be given in the order of the histogram's labels.
+func (h *Histogram) Observe(v float64, labelValues ...string) {
+	key := labelKey(h.labels, labelValues)
+	h.m.Lock()
+	defer h.m.Unlock()
+	hv, ok := h.values[key]
+	if !ok {
+		hv = &histogramValue{labelValues: append([]string{}, labelValues...), counts: make([]uint64, len(h.buckets))}
+		h.values[key] = hv
+	}
+	if i := sort.SearchFloat64s(h.buckets, v); i < len(h.buckets) {
+		hv.counts[i]++
+	}
+	hv.count++
+	hv.sum += v
+}
+
+func (h *Histogram) name() string { return h.metricName }
+
+func (h *Histogram) write(w io.Writer) {
+	h.m.Lock()
+	defer h.m.Unlock()
+	writeHeader(w, h.metricName, h.help, "histogram")
+	keys := make([]string, 0, len(h.values))
+	for key := range h.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		hv := h.values[key]
+		bucketLabels := append(append([]string{}, h.labels...), "le")
+		bucketValues := make([]string, len(h.labels)+1)
+		copy(bucketValues, hv.labelValues)
+		cumulative := uint64(0)
+		for i, le := range h.buckets {
+			cumulative += hv.counts[i]
+			bucketValues[len(bucketValues)-1] = formatFloat(le)
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.metricName, labelKey(bucketLabels, bucketValues), cumulative)
+		}
+		bucketValues[len(bucketValues)-1] = "+Inf"
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.metricName, labelKey(bucketLabels, bucketValues), hv.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.metricName, key, formatFloat(hv.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.metricName, key, hv.count)
+	}
+}
+
+// GaugeFunc is a value which may go up or down, read from a func when served, such as the size of a pool.
+type GaugeFunc struct {
+	metricName string
+	help       string
+	metricType string
+	f          func() float64
+}
+
+// NewGaugeFunc creates and registers a gauge whose value is returned by f when metrics are served.
+func NewGaugeFunc(name, help string, f func() float64) *GaugeFunc {
+	g := &GaugeFunc{metricName: name, help: help, metricType: "gauge", f: f}
+	register(g)
+	return g
+}
+
+// NewCounterFunc creates and registers a counter whose value is returned by f when metrics are served, for counts kept elsewhere, such as by database/sql. The name should end in _total.
+func NewCounterFunc(name, help string, f func() float64) *GaugeFunc {
+	g := &GaugeFunc{metricName: name, help: help, metricType: "counter", f: f}
+	register(g)
+	return g
+}
+
+func (g *GaugeFunc) name() string { return g.metricName }
+
+func (g *GaugeFunc) write(w io.Writer) {
+	writeHeader(w, g.metricName, g.help, g.metricType)
+	fmt.Fprintf(w, "%s %s\n", g.metricName, formatFloat(g.f()))
+}
+
+func writeHeader(w io.Writer, name, help, metricType string) {
+	fmt.Fprintf(w, "# HELP %s %s\n", name, strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, metricType)
+}
+
+// labelKey returns the Prometheus label set of the given names and values, e.g. `{method="GET",code="200"}`, or the empty string if there are no labels. Missing values are empty, and extra values are ignored.
+func labelKey(names []string, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	escaper := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		value := ""
+		if i < len(values) {
+			value = values[i]
+		}
+		pairs[i] = name + `="` + escaper.Replace(value) + `"`
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func sortedKeys(m map[string]float64) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	case math.IsNaN(f):
+		return "NaN"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
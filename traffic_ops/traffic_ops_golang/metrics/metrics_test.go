@@ -0,0 +1,98 @@
+package metrics
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCounter(t *testing.T) {
+	c := NewCounter("test_counter_total", "A test counter.", "method", "code")
+	c.Inc("GET", "200")
+	c.Inc("GET", "200")
+	c.Add(3, "POST", "500")
+	c.Add(-1, "POST", "500")
+	c.Inc(`GE"T`, "200")
+
+	buf := &bytes.Buffer{}
+	c.write(buf)
+	expected := `# HELP test_counter_total A test counter.
+# TYPE test_counter_total counter
+test_counter_total{method="GET",code="200"} 2
+test_counter_total{method="GE\"T",code="200"} 1
+test_counter_total{method="POST",code="500"} 3
+`
+	if buf.String() != expected {
+		t.Errorf("Counter.write expected:\n%s\nactual:\n%s", expected, buf.String())
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	h := NewHistogram("test_histogram_seconds", "A test histogram.", []float64{1, 0.1}, "route")
+	h.Observe(0.05, "a")
+	h.Observe(0.5, "a")
+	h.Observe(5, "a")
+
+	buf := &bytes.Buffer{}
+	h.write(buf)
+	expected := `# HELP test_histogram_seconds A test histogram.
+# TYPE test_histogram_seconds histogram
+test_histogram_seconds_bucket{route="a",le="0.1"} 1
+test_histogram_seconds_bucket{route="a",le="1"} 2
+test_histogram_seconds_bucket{route="a",le="+Inf"} 3
+test_histogram_seconds_sum{route="a"} 5.55
+test_histogram_seconds_count{route="a"} 3
+`
+	if buf.String() != expected {
+		t.Errorf("Histogram.write expected:\n%s\nactual:\n%s", expected, buf.String())
+	}
+}
+
+func TestHandler(t *testing.T) {
+	NewGaugeFunc("test_gauge", "A test gauge.", func() float64 { return 42 })
+
+	w := httptest.NewRecorder()
+	r, err := http.NewRequest(http.MethodGet, "/metrics", nil)
+	if err != nil {
+		t.Fatalf("creating request: %v", err)
+	}
+	Handler()(w, r)
+
+	if ct := w.Header().Get("Content-Type"); ct != ContentType {
+		t.Errorf("Handler expected: Content-Type %s, actual: %s", ContentType, ct)
+	}
+	if body := w.Body.String(); !strings.Contains(body, "# TYPE test_gauge gauge\ntest_gauge 42\n") {
+		t.Errorf("Handler expected: test_gauge 42, actual:\n%s", body)
+	}
+}
+
+func TestRegisterDuplicate(t *testing.T) {
+	NewCounter("test_duplicate_total", "A test counter.")
+	defer func() {
+		if recover() == nil {
+			t.Errorf("NewCounter expected: panic registering a duplicate name, actual: no panic")
+		}
+	}()
+	NewCounter("test_duplicate_total", "A test counter.")
+}
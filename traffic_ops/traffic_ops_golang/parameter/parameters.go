@@ -48,6 +48,9 @@ var (
 	HiddenField = "********"
 )
 
+// SecureReadCapability is the capability required to see the values of secure parameters, which are otherwise hidden.
+const SecureReadCapability = "secure-params-read"
+
 //we need a type alias to define functions on
 type TOParameter tc.ParameterNullable
 
@@ -129,6 +132,20 @@ func (pl *TOParameter) Create(db *sqlx.DB, user auth.CurrentUser) (error, tc.Api
 		log.Error.Printf("could not begin transaction: %v", err)
 		return tc.DBError, tc.SystemError
 	}
+	if err, errType := pl.CreateTx(tx, user); err != nil {
+		return err, errType
+	}
+	err = tx.Commit()
+	if err != nil {
+		log.Errorln("Could not commit transaction: ", err)
+		return tc.DBError, tc.SystemError
+	}
+	rollbackTransaction = false
+	return nil, tc.NoError
+}
+
+// CreateTx creates the parameter in the given transaction, without committing it, so many can be created atomically by api.BulkCreateHandler.
+func (pl *TOParameter) CreateTx(tx *sqlx.Tx, user auth.CurrentUser) (error, tc.ApiErrorType) {
 	resultRows, err := tx.NamedQuery(insertQuery(), pl)
 	if err != nil {
 		if pqErr, ok := err.(*pq.Error); ok {
@@ -166,12 +183,6 @@ func (pl *TOParameter) Create(db *sqlx.DB, user auth.CurrentUser) (error, tc.Api
 
 	pl.SetKeys(map[string]interface{}{IDQueryParam: id})
 	pl.LastUpdated = &lastUpdated
-	err = tx.Commit()
-	if err != nil {
-		log.Errorln("Could not commit transaction: ", err)
-		return tc.DBError, tc.SystemError
-	}
-	rollbackTransaction = false
 	return nil, tc.NoError
 }
 
@@ -191,15 +202,14 @@ secure) VALUES (
 func (parameter *TOParameter) Read(db *sqlx.DB, parameters map[string]string, user auth.CurrentUser) ([]interface{}, []error, tc.ApiErrorType) {
 	var rows *sqlx.Rows
 
-	privLevel := user.PrivLevel
-
 	// Query Parameters to Database Query column mappings
 	// see the fields mapped in the SQL query
 	queryParamsToQueryCols := map[string]dbhelpers.WhereColumnInfo{
-		ConfigFileQueryParam: dbhelpers.WhereColumnInfo{"p.config_file", nil},
-		IDQueryParam:         dbhelpers.WhereColumnInfo{"p.id", api.IsInt},
-		NameQueryParam:       dbhelpers.WhereColumnInfo{"p.name", nil},
-		SecureQueryParam:     dbhelpers.WhereColumnInfo{"p.secure", api.IsBool},
+		ConfigFileQueryParam:            dbhelpers.WhereColumnInfo{"p.config_file", nil},
+		IDQueryParam:                    dbhelpers.WhereColumnInfo{"p.id", api.IsInt},
+		NameQueryParam:                  dbhelpers.WhereColumnInfo{"p.name", nil},
+		SecureQueryParam:                dbhelpers.WhereColumnInfo{"p.secure", api.IsBool},
+		dbhelpers.LastUpdatedQueryParam: dbhelpers.WhereColumnInfo{"p.last_updated", nil},
 	}
 
 	where, orderBy, queryValues, errs := dbhelpers.BuildWhereAndOrderBy(parameters, queryParamsToQueryCols)
@@ -229,7 +239,7 @@ func (parameter *TOParameter) Read(db *sqlx.DB, parameters map[string]string, us
 			isSecure = *p.Secure
 		}
 
-		if isSecure && (privLevel < auth.PrivLevelAdmin) {
+		if isSecure && !user.HasCapability(SecureReadCapability) {
 			p.Value = &HiddenField
 		}
 		params = append(params, p)
@@ -261,6 +271,20 @@ func (pl *TOParameter) Update(db *sqlx.DB, user auth.CurrentUser) (error, tc.Api
 		log.Error.Printf("could not begin transaction: %v", err)
 		return tc.DBError, tc.SystemError
 	}
+	if err, errType := pl.UpdateTx(tx, user); err != nil {
+		return err, errType
+	}
+	err = tx.Commit()
+	if err != nil {
+		log.Errorln("Could not commit transaction: ", err)
+		return tc.DBError, tc.SystemError
+	}
+	rollbackTransaction = false
+	return nil, tc.NoError
+}
+
+// UpdateTx updates the parameter in the given transaction, without committing it, so many can be updated atomically by api.BulkUpdateHandler.
+func (pl *TOParameter) UpdateTx(tx *sqlx.Tx, user auth.CurrentUser) (error, tc.ApiErrorType) {
 	log.Debugf("about to run exec query: %s with parameter: %++v", updateQuery(), pl)
 	resultRows, err := tx.NamedQuery(updateQuery(), pl)
 	if err != nil {
@@ -294,12 +318,6 @@ func (pl *TOParameter) Update(db *sqlx.DB, user auth.CurrentUser) (error, tc.Api
 		}
 		return fmt.Errorf("this update affected too many rows: %d", rowsAffected), tc.SystemError
 	}
-	err = tx.Commit()
-	if err != nil {
-		log.Errorln("Could not commit transaction: ", err)
-		return tc.DBError, tc.SystemError
-	}
-	rollbackTransaction = false
 	return nil, tc.NoError
 }
 
@@ -322,6 +340,20 @@ func (pl *TOParameter) Delete(db *sqlx.DB, user auth.CurrentUser) (error, tc.Api
 		log.Error.Printf("could not begin transaction: %v", err)
 		return tc.DBError, tc.SystemError
 	}
+	if err, errType := pl.DeleteTx(tx, user); err != nil {
+		return err, errType
+	}
+	err = tx.Commit()
+	if err != nil {
+		log.Errorln("Could not commit transaction: ", err)
+		return tc.DBError, tc.SystemError
+	}
+	rollbackTransaction = false
+	return nil, tc.NoError
+}
+
+// DeleteTx deletes the parameter in the given transaction, without committing it, so many can be deleted atomically by api.BulkDeleteHandler.
+func (pl *TOParameter) DeleteTx(tx *sqlx.Tx, user auth.CurrentUser) (error, tc.ApiErrorType) {
 	log.Debugf("about to run exec query: %s with parameter: %++v", deleteQuery(), pl)
 	result, err := tx.NamedExec(deleteQuery(), pl)
 	if err != nil {
@@ -339,12 +371,6 @@ func (pl *TOParameter) Delete(db *sqlx.DB, user auth.CurrentUser) (error, tc.Api
 		return fmt.Errorf("this create affected too many rows: %d", rowsAffected), tc.SystemError
 	}
 
-	err = tx.Commit()
-	if err != nil {
-		log.Errorln("Could not commit transaction: ", err)
-		return tc.DBError, tc.SystemError
-	}
-	rollbackTransaction = false
 	return nil, tc.NoError
 }
 
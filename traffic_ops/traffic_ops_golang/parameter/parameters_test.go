@@ -120,6 +120,15 @@ func TestInterfaces(t *testing.T) {
 	if _, ok := i.(api.Deleter); !ok {
 		t.Errorf("Parameter must be Deleter")
 	}
+	if _, ok := i.(api.BulkCreator); !ok {
+		t.Errorf("Parameter must be BulkCreator")
+	}
+	if _, ok := i.(api.BulkUpdater); !ok {
+		t.Errorf("Parameter must be BulkUpdater")
+	}
+	if _, ok := i.(api.BulkDeleter); !ok {
+		t.Errorf("Parameter must be BulkDeleter")
+	}
 	if _, ok := i.(api.Identifier); !ok {
 		t.Errorf("Parameter must be Identifier")
 	}
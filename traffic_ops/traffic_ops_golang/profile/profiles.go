@@ -106,8 +106,9 @@ func (prof *TOProfile) Read(db *sqlx.DB, parameters map[string]string, user auth
 	// Query Parameters to Database Query column mappings
 	// see the fields mapped in the SQL query
 	queryParamsToQueryCols := map[string]dbhelpers.WhereColumnInfo{
-		NameQueryParam: dbhelpers.WhereColumnInfo{"prof.name", nil},
-		IDQueryParam:   dbhelpers.WhereColumnInfo{"prof.id", api.IsInt},
+		NameQueryParam:                  dbhelpers.WhereColumnInfo{"prof.name", nil},
+		IDQueryParam:                    dbhelpers.WhereColumnInfo{"prof.id", api.IsInt},
+		dbhelpers.LastUpdatedQueryParam: dbhelpers.WhereColumnInfo{"prof.last_updated", nil},
 	}
 	where, orderBy, queryValues, errs := dbhelpers.BuildWhereAndOrderBy(parameters, queryParamsToQueryCols)
 	if len(errs) > 0 {
@@ -168,7 +169,6 @@ LEFT JOIN cdn c ON prof.cdn = c.id`
 func ReadParameters(db *sqlx.DB, parameters map[string]string, user auth.CurrentUser, profile v13.ProfileNullable) ([]v13.ParameterNullable, []error) {
 
 	var rows *sqlx.Rows
-	privLevel := user.PrivLevel
 	queryValues := make(map[string]interface{})
 	queryValues["profile_id"] = *profile.ID
 
@@ -192,7 +192,7 @@ func ReadParameters(db *sqlx.DB, parameters map[string]string, user auth.Current
 		if param.Secure != nil {
 			isSecure = *param.Secure
 		}
-		if isSecure && (privLevel < auth.PrivLevelAdmin) {
+		if isSecure && !user.HasCapability(parameter.SecureReadCapability) {
 			param.Value = &parameter.HiddenField
 		}
 		params = append(params, param)
@@ -237,6 +237,20 @@ func (prof *TOProfile) Update(db *sqlx.DB, user auth.CurrentUser) (error, tc.Api
 		log.Error.Printf("could not begin transaction: %v", err)
 		return tc.DBError, tc.SystemError
 	}
+	if err, errType := prof.UpdateTx(tx, user); err != nil {
+		return err, errType
+	}
+	err = tx.Commit()
+	if err != nil {
+		log.Errorln("Could not commit transaction: ", err)
+		return tc.DBError, tc.SystemError
+	}
+	rollbackTransaction = false
+	return nil, tc.NoError
+}
+
+// UpdateTx updates the profile in the given transaction, which the caller commits or rolls back.
+func (prof *TOProfile) UpdateTx(tx *sqlx.Tx, user auth.CurrentUser) (error, tc.ApiErrorType) {
 	log.Debugf("about to run exec query: %s with profile: %++v", updateQuery(), prof)
 	resultRows, err := tx.NamedQuery(updateQuery(), prof)
 	if err != nil {
@@ -269,12 +283,6 @@ func (prof *TOProfile) Update(db *sqlx.DB, user auth.CurrentUser) (error, tc.Api
 		}
 		return fmt.Errorf("this update affected too many rows: %d", rowsAffected), tc.SystemError
 	}
-	err = tx.Commit()
-	if err != nil {
-		log.Errorln("Could not commit transaction: ", err)
-		return tc.DBError, tc.SystemError
-	}
-	rollbackTransaction = false
 	return nil, tc.NoError
 }
 
@@ -367,6 +375,20 @@ func (prof *TOProfile) Delete(db *sqlx.DB, user auth.CurrentUser) (error, tc.Api
 		log.Error.Printf("could not begin transaction: %v", err)
 		return tc.DBError, tc.SystemError
 	}
+	if err, errType := prof.DeleteTx(tx, user); err != nil {
+		return err, errType
+	}
+	err = tx.Commit()
+	if err != nil {
+		log.Errorln("Could not commit transaction: ", err)
+		return tc.DBError, tc.SystemError
+	}
+	rollbackTransaction = false
+	return nil, tc.NoError
+}
+
+// DeleteTx deletes the profile in the given transaction, which the caller commits or rolls back.
+func (prof *TOProfile) DeleteTx(tx *sqlx.Tx, user auth.CurrentUser) (error, tc.ApiErrorType) {
 	log.Debugf("about to run exec query: %s with profile: %++v", deleteQuery(), prof)
 	result, err := tx.NamedExec(deleteQuery(), prof)
 	if err != nil {
@@ -384,12 +406,6 @@ func (prof *TOProfile) Delete(db *sqlx.DB, user auth.CurrentUser) (error, tc.Api
 		return fmt.Errorf("this create affected too many rows: %d", rowsAffected), tc.SystemError
 	}
 
-	err = tx.Commit()
-	if err != nil {
-		log.Errorln("Could not commit transaction: ", err)
-		return tc.DBError, tc.SystemError
-	}
-	rollbackTransaction = false
 	return nil, tc.NoError
 }
 
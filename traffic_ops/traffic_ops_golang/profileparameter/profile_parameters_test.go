@@ -105,6 +105,12 @@ func TestInterfaces(t *testing.T) {
 	if _, ok := i.(api.Deleter); !ok {
 		t.Errorf("ProfileParameter must be Deleter")
 	}
+	if _, ok := i.(api.BulkCreator); !ok {
+		t.Errorf("ProfileParameter must be BulkCreator")
+	}
+	if _, ok := i.(api.BulkDeleter); !ok {
+		t.Errorf("ProfileParameter must be BulkDeleter")
+	}
 	if _, ok := i.(api.Identifier); !ok {
 		t.Errorf("ProfileParameter must be Identifier")
 	}
@@ -28,6 +28,7 @@ import (
 	"time"
 
 	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/metrics"
 
 	"github.com/basho/riak-go-client"
 	"github.com/jmoiron/sqlx"
@@ -66,9 +67,17 @@ func (ri RiakStorageCluster) Start() error {
 	return ri.Cluster.Start()
 }
 
+var riakCommandsTotal = metrics.NewCounter("traffic_ops_riak_commands_total", "Commands executed on the Riak cluster, by command.", "command")
+var riakCommandErrorsTotal = metrics.NewCounter("traffic_ops_riak_command_errors_total", "Commands which failed on the Riak cluster, by command.", "command")
+
 // Execute ...
 func (ri RiakStorageCluster) Execute(command riak.Command) error {
-	return ri.Cluster.Execute(command)
+	riakCommandsTotal.Inc(command.Name())
+	err := ri.Cluster.Execute(command)
+	if err != nil {
+		riakCommandErrorsTotal.Inc(command.Name())
+	}
+	return err
 }
 
 func GetRiakConfig(riakConfigFile string) (bool, *riak.AuthOptions, error) {
@@ -164,6 +173,32 @@ func SaveObject(obj *riak.Object, bucket string, cluster StorageCluster) error {
 	return nil
 }
 
+// lists the keys of a bucket in riak storage. Listing keys reads every key in the cluster, so it should only be used by tools such as migrations, not by API requests.
+func ListKeys(bucket string, cluster StorageCluster) ([]string, error) {
+	if cluster == nil {
+		return nil, errors.New("ERROR: No valid cluster on which to execute a command")
+	}
+	// build the list keys command
+	cmd, err := riak.NewListKeysCommandBuilder().
+		WithBucket(bucket).
+		WithAllowListing().
+		WithTimeout(timeOut).
+		Build()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = cluster.Execute(cmd); err != nil {
+		return nil, err
+	}
+	lkc := cmd.(*riak.ListKeysCommand)
+
+	if lkc.Response == nil {
+		return []string{}, nil
+	}
+	return lkc.Response.Keys, nil
+}
+
 // returns a riak cluster of online riak nodes.
 func GetRiakCluster(db *sqlx.DB, authOptions *riak.AuthOptions) (StorageCluster, error) {
 	riakServerQuery := `
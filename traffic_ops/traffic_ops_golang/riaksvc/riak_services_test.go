@@ -137,6 +137,30 @@ func TestDeleteObject(t *testing.T) {
 	}
 }
 
+func TestListKeys(t *testing.T) {
+	cluster := &MockStorageCluster{
+		Running: true,
+	}
+
+	keys, err := ListKeys("bucket", cluster)
+	if err != nil {
+		t.Error("expected nil error got ", err)
+	}
+	if len(keys) != 0 {
+		t.Error("expected no keys got ", keys)
+	}
+
+	_, err = ListKeys("", cluster)
+	if err == nil {
+		t.Error("expected an empty bucket error but got nil error", err)
+	}
+
+	_, err = ListKeys("bucket", nil)
+	if err == nil {
+		t.Error("expected an nil cluster error but got nil error", err)
+	}
+}
+
 func TestGetRiakCluster(t *testing.T) {
 	mockDB, mock, err := sqlmock.New()
 	if err != nil {
@@ -0,0 +1,439 @@
+package role
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-log"
+	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/dbhelpers"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/tovalidate"
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+//we need a type alias to define functions on
+type TORole tc.RoleNullable
+
+//the refType is passed into the handlers where a copy of its type is used to decode the json.
+var refType = TORole(tc.RoleNullable{})
+
+func GetRefType() *TORole {
+	return &refType
+}
+
+func (role TORole) GetAuditName() string {
+	if role.Name != nil {
+		return *role.Name
+	}
+	if role.ID != nil {
+		return strconv.Itoa(*role.ID)
+	}
+	return "unknown"
+}
+
+func (role TORole) GetKeyFieldsInfo() []api.KeyFieldInfo {
+	return []api.KeyFieldInfo{{"id", api.GetIntKey}}
+}
+
+//Implementation of the Identifier, Validator interface functions
+func (role TORole) GetKeys() (map[string]interface{}, bool) {
+	if role.ID == nil {
+		return map[string]interface{}{"id": 0}, false
+	}
+	return map[string]interface{}{"id": *role.ID}, true
+}
+
+func (role *TORole) SetKeys(keys map[string]interface{}) {
+	i, _ := keys["id"].(int) //this utilizes the non panicking type assertion, if the thrown away ok variable is false i will be the zero of the type, 0 here.
+	role.ID = &i
+}
+
+func (role TORole) GetType() string {
+	return "role"
+}
+
+func (role TORole) Validate(db *sqlx.DB) []error {
+	errs := validation.Errors{
+		"name":      validation.Validate(role.Name, validation.NotNil, validation.Required),
+		"privLevel": validation.Validate(role.PrivLevel, validation.NotNil),
+	}
+	errsResponse := tovalidate.ToErrors(errs)
+	if role.Capabilities != nil && len(*role.Capabilities) > 0 {
+		missing, err := getMissingCapabilities(db, *role.Capabilities)
+		if err != nil {
+			log.Errorln("validating role capabilities: " + err.Error())
+			return append(errsResponse, tc.DBError)
+		}
+		if len(missing) > 0 {
+			errsResponse = append(errsResponse, fmt.Errorf("capabilities: %v do not exist", missing))
+		}
+	}
+	return errsResponse
+}
+
+// getMissingCapabilities returns the given capabilities which don't exist.
+func getMissingCapabilities(db *sqlx.DB, capabilities []string) ([]string, error) {
+	existing := []string{}
+	if err := db.QueryRow(`SELECT ARRAY(SELECT name FROM capability WHERE name = ANY($1))`, pq.Array(capabilities)).Scan(pq.Array(&existing)); err != nil {
+		return nil, errors.New("querying capabilities: " + err.Error())
+	}
+	exists := map[string]struct{}{}
+	for _, c := range existing {
+		exists[c] = struct{}{}
+	}
+	missing := []string{}
+	for _, c := range capabilities {
+		if _, ok := exists[c]; !ok {
+			missing = append(missing, c)
+		}
+	}
+	return missing, nil
+}
+
+// checkGrantable returns a ForbiddenError if the role has a capability or privilege level the user doesn't have, because a user may not grant more than they have themselves.
+func (role TORole) checkGrantable(user auth.CurrentUser) (error, tc.ApiErrorType) {
+	if role.PrivLevel != nil && *role.PrivLevel > user.PrivLevel {
+		return errors.New("cannot grant a privLevel greater than your own"), tc.ForbiddenError
+	}
+	if role.Capabilities == nil {
+		return nil, tc.NoError
+	}
+	for _, c := range *role.Capabilities {
+		if !user.HasCapability(c) {
+			return errors.New("cannot grant capability " + c + " which you do not have"), tc.ForbiddenError
+		}
+	}
+	return nil, tc.NoError
+}
+
+// setCapabilities replaces the capabilities of the role, if the role has them; else the role's current capabilities are read into it.
+func (role *TORole) setCapabilities(tx *sqlx.Tx) error {
+	if role.Capabilities == nil {
+		caps := []string{}
+		if err := tx.QueryRow(selectCapabilitiesQuery(), *role.ID).Scan(pq.Array(&caps)); err != nil {
+			return errors.New("querying role capabilities: " + err.Error())
+		}
+		role.Capabilities = &caps
+		return nil
+	}
+	if _, err := tx.Exec(`DELETE FROM role_capability WHERE role_id = $1`, *role.ID); err != nil {
+		return errors.New("deleting role capabilities: " + err.Error())
+	}
+	if _, err := tx.Exec(`INSERT INTO role_capability (role_id, cap_name) SELECT $1, UNNEST($2::text[])`, *role.ID, pq.Array(*role.Capabilities)); err != nil {
+		return errors.New("inserting role capabilities: " + err.Error())
+	}
+	return nil
+}
+
+//The TORole implementation of the Creator interface
+//all implementations of Creator should use transactions and return the proper errorType
+//ParsePQUniqueConstraintError is used to determine if a role with conflicting values exists
+//The role's capabilities are inserted in the same transaction
+func (role *TORole) Create(db *sqlx.DB, user auth.CurrentUser) (error, tc.ApiErrorType) {
+	if err, errType := role.checkGrantable(user); err != nil {
+		return err, errType
+	}
+	rollbackTransaction := true
+	tx, err := db.Beginx()
+	defer func() {
+		if tx == nil || !rollbackTransaction {
+			return
+		}
+		err := tx.Rollback()
+		if err != nil {
+			log.Errorln(errors.New("rolling back transaction: " + err.Error()))
+		}
+	}()
+
+	if err != nil {
+		log.Error.Printf("could not begin transaction: %v", err)
+		return tc.DBError, tc.SystemError
+	}
+	resultRows, err := tx.NamedQuery(insertQuery(), role)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok {
+			err, eType := dbhelpers.ParsePQUniqueConstraintError(pqErr)
+			if eType == tc.DataConflictError {
+				return errors.New("a role with " + err.Error()), eType
+			}
+			return err, eType
+		}
+		log.Errorf("received non pq error: %++v from create execution", err)
+		return tc.DBError, tc.SystemError
+	}
+	defer resultRows.Close()
+
+	var id int
+	var lastUpdated tc.TimeNoMod
+	rowsAffected := 0
+	for resultRows.Next() {
+		rowsAffected++
+		if err := resultRows.Scan(&id, &lastUpdated); err != nil {
+			log.Error.Printf("could not scan id from insert: %s\n", err)
+			return tc.DBError, tc.SystemError
+		}
+	}
+	if rowsAffected == 0 {
+		err = errors.New("no role was inserted, no id was returned")
+		log.Errorln(err)
+		return tc.DBError, tc.SystemError
+	} else if rowsAffected > 1 {
+		err = errors.New("too many ids returned from role insert")
+		log.Errorln(err)
+		return tc.DBError, tc.SystemError
+	}
+	resultRows.Close()
+	role.SetKeys(map[string]interface{}{"id": id})
+	role.LastUpdated = &lastUpdated
+	if err := role.setCapabilities(tx); err != nil {
+		log.Errorln(err)
+		return tc.DBError, tc.SystemError
+	}
+	err = tx.Commit()
+	if err != nil {
+		log.Errorln("Could not commit transaction: ", err)
+		return tc.DBError, tc.SystemError
+	}
+	rollbackTransaction = false
+	return nil, tc.NoError
+}
+
+func (role *TORole) Read(db *sqlx.DB, parameters map[string]string, user auth.CurrentUser) ([]interface{}, []error, tc.ApiErrorType) {
+	// Query Parameters to Database Query column mappings
+	// see the fields mapped in the SQL query
+	queryParamsToQueryCols := map[string]dbhelpers.WhereColumnInfo{
+		"id":          dbhelpers.WhereColumnInfo{"r.id", api.IsInt},
+		"name":        dbhelpers.WhereColumnInfo{"r.name", nil},
+		"privLevel":   dbhelpers.WhereColumnInfo{"r.priv_level", api.IsInt},
+		"lastUpdated": dbhelpers.WhereColumnInfo{"r.last_updated", nil},
+	}
+	where, orderBy, queryValues, errs := dbhelpers.BuildWhereAndOrderBy(parameters, queryParamsToQueryCols)
+	if len(errs) > 0 {
+		return nil, errs, tc.DataConflictError
+	}
+
+	query := selectQuery() + where + orderBy
+	log.Debugln("Query is ", query)
+
+	rows, err := db.NamedQuery(query, queryValues)
+	if err != nil {
+		log.Errorf("Error querying Roles: %v", err)
+		return nil, []error{tc.DBError}, tc.SystemError
+	}
+	defer rows.Close()
+
+	roles := []interface{}{}
+	for rows.Next() {
+		r := tc.Role{Capabilities: []string{}}
+		if err = rows.Scan(&r.ID, &r.Name, &r.Description, &r.PrivLevel, pq.Array(&r.Capabilities), &r.LastUpdated); err != nil {
+			log.Errorf("error parsing Role rows: %v", err)
+			return nil, []error{tc.DBError}, tc.SystemError
+		}
+		roles = append(roles, r)
+	}
+
+	return roles, []error{}, tc.NoError
+}
+
+//The TORole implementation of the Updater interface
+//all implementations of Updater should use transactions and return the proper errorType
+//If the role has capabilities, they replace the existing ones; else the existing ones are kept
+func (role *TORole) Update(db *sqlx.DB, user auth.CurrentUser) (error, tc.ApiErrorType) {
+	rollbackTransaction := true
+	tx, err := db.Beginx()
+	defer func() {
+		if tx == nil || !rollbackTransaction {
+			return
+		}
+		err := tx.Rollback()
+		if err != nil {
+			log.Errorln(errors.New("rolling back transaction: " + err.Error()))
+		}
+	}()
+
+	if err != nil {
+		log.Error.Printf("could not begin transaction: %v", err)
+		return tc.DBError, tc.SystemError
+	}
+	if err, errType := role.UpdateTx(tx, user); err != nil {
+		return err, errType
+	}
+	err = tx.Commit()
+	if err != nil {
+		log.Errorln("Could not commit transaction: ", err)
+		return tc.DBError, tc.SystemError
+	}
+	rollbackTransaction = false
+	return nil, tc.NoError
+}
+
+// UpdateTx updates the role in the given transaction, which the caller commits or rolls back.
+func (role *TORole) UpdateTx(tx *sqlx.Tx, user auth.CurrentUser) (error, tc.ApiErrorType) {
+	if err, errType := role.checkGrantable(user); err != nil {
+		return err, errType
+	}
+
+	log.Debugf("about to run exec query: %s with role: %++v", updateQuery(), role)
+	resultRows, err := tx.NamedQuery(updateQuery(), role)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok {
+			err, eType := dbhelpers.ParsePQUniqueConstraintError(pqErr)
+			if eType == tc.DataConflictError {
+				return errors.New("a role with " + err.Error()), eType
+			}
+			return err, eType
+		}
+		log.Errorf("received error: %++v from update execution", err)
+		return tc.DBError, tc.SystemError
+	}
+	defer resultRows.Close()
+
+	var lastUpdated tc.TimeNoMod
+	rowsAffected := 0
+	for resultRows.Next() {
+		rowsAffected++
+		if err := resultRows.Scan(&lastUpdated); err != nil {
+			log.Error.Printf("could not scan lastUpdated from update: %s\n", err)
+			return tc.DBError, tc.SystemError
+		}
+	}
+	role.LastUpdated = &lastUpdated
+	if rowsAffected != 1 {
+		if rowsAffected < 1 {
+			return errors.New("no role found with this id"), tc.DataMissingError
+		}
+		return fmt.Errorf("this update affected too many rows: %d", rowsAffected), tc.SystemError
+	}
+	resultRows.Close()
+	if err := role.setCapabilities(tx); err != nil {
+		log.Errorln(err)
+		return tc.DBError, tc.SystemError
+	}
+	return nil, tc.NoError
+}
+
+//The TORole implementation of the Deleter interface
+//Roles assigned to any user may not be deleted, because the users would be left without a role
+func (role *TORole) Delete(db *sqlx.DB, user auth.CurrentUser) (error, tc.ApiErrorType) {
+	rollbackTransaction := true
+	tx, err := db.Beginx()
+	defer func() {
+		if tx == nil || !rollbackTransaction {
+			return
+		}
+		err := tx.Rollback()
+		if err != nil {
+			log.Errorln(errors.New("rolling back transaction: " + err.Error()))
+		}
+	}()
+
+	if err != nil {
+		log.Error.Printf("could not begin transaction: %v", err)
+		return tc.DBError, tc.SystemError
+	}
+	if err, errType := role.DeleteTx(tx, user); err != nil {
+		return err, errType
+	}
+	err = tx.Commit()
+	if err != nil {
+		log.Errorln("Could not commit transaction: ", err)
+		return tc.DBError, tc.SystemError
+	}
+	rollbackTransaction = false
+	return nil, tc.NoError
+}
+
+// DeleteTx deletes the role in the given transaction, which the caller commits or rolls back.
+func (role *TORole) DeleteTx(tx *sqlx.Tx, user auth.CurrentUser) (error, tc.ApiErrorType) {
+	userCount := 0
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM tm_user WHERE role = $1`, *role.ID).Scan(&userCount); err != nil {
+		log.Errorln("querying role users: " + err.Error())
+		return tc.DBError, tc.SystemError
+	}
+	if userCount > 0 {
+		return fmt.Errorf("role is assigned to %d users, and cannot be deleted", userCount), tc.DataConflictError
+	}
+	log.Debugf("about to run exec query: %s with role: %++v", deleteQuery(), role)
+	result, err := tx.NamedExec(deleteQuery(), role)
+	if err != nil {
+		log.Errorf("received error: %++v from delete execution", err)
+		return tc.DBError, tc.SystemError
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return tc.DBError, tc.SystemError
+	}
+	if rowsAffected != 1 {
+		if rowsAffected < 1 {
+			return errors.New("no role with that id found"), tc.DataMissingError
+		}
+		return fmt.Errorf("this delete affected too many rows: %d", rowsAffected), tc.SystemError
+	}
+	return nil, tc.NoError
+}
+
+func insertQuery() string {
+	query := `INSERT INTO role (
+name,
+description,
+priv_level) VALUES (:name, :description, :priv_level) RETURNING id,last_updated`
+	return query
+}
+
+func selectQuery() string {
+	query := `SELECT
+r.id,
+r.name,
+COALESCE(r.description, '') AS description,
+r.priv_level,
+ARRAY(SELECT rc.cap_name FROM role_capability AS rc WHERE rc.role_id = r.id ORDER BY rc.cap_name) AS capabilities,
+r.last_updated
+
+FROM role r`
+	return query
+}
+
+func selectCapabilitiesQuery() string {
+	query := `SELECT ARRAY(SELECT cap_name FROM role_capability WHERE role_id = $1 ORDER BY cap_name)`
+	return query
+}
+
+func updateQuery() string {
+	query := `UPDATE
+role SET
+name=:name,
+description=:description,
+priv_level=:priv_level
+WHERE id=:id RETURNING last_updated`
+	return query
+}
+
+func deleteQuery() string {
+	query := `DELETE FROM role
+WHERE id=:id`
+	return query
+}
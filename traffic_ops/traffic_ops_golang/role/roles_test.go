@@ -0,0 +1,167 @@
+package role
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/test"
+	"github.com/jmoiron/sqlx"
+
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestReadRoles(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	rows := sqlmock.NewRows(test.ColsFromStructByTag("db", tc.Role{}))
+	rows = rows.AddRow(1, "admin", "super-user", 30, "{all-read,all-write}", time.Now())
+	rows = rows.AddRow(2, "read-only", "", 10, "{}", time.Now())
+	mock.ExpectQuery("SELECT").WillReturnRows(rows)
+
+	roles, errs, errType := refType.Read(db, map[string]string{}, auth.CurrentUser{})
+	if len(errs) > 0 {
+		t.Fatalf("role.Read expected: no errors, actual: %v with error type: %s", errs, errType.String())
+	}
+	if len(roles) != 2 {
+		t.Fatalf("role.Read expected: len(roles) == 2, actual: %v", len(roles))
+	}
+	if caps := roles[0].(tc.Role).Capabilities; !reflect.DeepEqual(caps, []string{"all-read", "all-write"}) {
+		t.Errorf("role.Read expected: capabilities [all-read all-write], actual: %v", caps)
+	}
+	if caps := roles[1].(tc.Role).Capabilities; caps == nil || len(caps) != 0 {
+		t.Errorf("role.Read expected: empty capabilities, actual: %v", caps)
+	}
+}
+
+func TestCreateRole(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	name := "steering"
+	privLevel := 15
+	caps := []string{"ds-read", "ds-steering-write"}
+	role := TORole{Name: &name, PrivLevel: &privLevel, Capabilities: &caps}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO role").WillReturnRows(sqlmock.NewRows([]string{"id", "last_updated"}).AddRow(4, time.Now()))
+	mock.ExpectExec("DELETE FROM role_capability").WithArgs(4).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO role_capability").WithArgs(4, "{\"ds-read\",\"ds-steering-write\"}").WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	user := auth.CurrentUser{PrivLevel: auth.PrivLevelAdmin, Capabilities: []string{auth.CapabilityAllRead, auth.CapabilityAllWrite}}
+	if err, errType := role.Create(db, user); err != nil {
+		t.Fatalf("role.Create expected: no error, actual: %v with error type: %s", err, errType.String())
+	}
+	if role.ID == nil || *role.ID != 4 {
+		t.Errorf("role.Create expected: id 4, actual: %v", role.ID)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestCheckGrantable(t *testing.T) {
+	privLevel := 15
+	caps := []string{"ds-read", "ds-steering-write"}
+	role := TORole{PrivLevel: &privLevel, Capabilities: &caps}
+
+	type testCase struct {
+		user       auth.CurrentUser
+		grantable  bool
+		reasonDesc string
+	}
+	testCases := []testCase{
+		{auth.CurrentUser{PrivLevel: 30, Capabilities: []string{auth.CapabilityAllRead, auth.CapabilityAllWrite}}, true, "wildcards"},
+		{auth.CurrentUser{PrivLevel: 15, Capabilities: []string{"ds-read", "ds-steering-write", "role-write"}}, true, "same capabilities"},
+		{auth.CurrentUser{PrivLevel: 15, Capabilities: []string{"ds-read", "role-write"}}, false, "missing capability"},
+		{auth.CurrentUser{PrivLevel: 10, Capabilities: []string{auth.CapabilityAllRead, auth.CapabilityAllWrite}}, false, "lower privLevel"},
+	}
+	for _, tc := range testCases {
+		err, _ := role.checkGrantable(tc.user)
+		if (err == nil) != tc.grantable {
+			t.Errorf("checkGrantable with %s expected grantable %v, actual error: %v", tc.reasonDesc, tc.grantable, err)
+		}
+	}
+}
+
+func TestInterfaces(t *testing.T) {
+	var i interface{}
+	i = &TORole{}
+
+	if _, ok := i.(api.Creator); !ok {
+		t.Errorf("role must be creator")
+	}
+	if _, ok := i.(api.Reader); !ok {
+		t.Errorf("role must be reader")
+	}
+	if _, ok := i.(api.Updater); !ok {
+		t.Errorf("role must be updater")
+	}
+	if _, ok := i.(api.Deleter); !ok {
+		t.Errorf("role must be deleter")
+	}
+	if _, ok := i.(api.Identifier); !ok {
+		t.Errorf("role must be Identifier")
+	}
+}
+
+func TestValidation(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	if errs := (TORole{}).Validate(db); len(errs) != 2 {
+		t.Errorf("role.Validate expected: errors for name and privLevel, actual: %v", errs)
+	}
+
+	name := "steering"
+	privLevel := 15
+	caps := []string{"ds-read", "no-such-capability"}
+	role := TORole{Name: &name, PrivLevel: &privLevel, Capabilities: &caps}
+	mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows([]string{"array"}).AddRow("{ds-read}"))
+	errs := role.Validate(db)
+	if len(errs) != 1 || errs[0].Error() != "capabilities: [no-such-capability] do not exist" {
+		t.Errorf("role.Validate expected: error for the missing capability, actual: %v", errs)
+	}
+}
@@ -32,25 +32,37 @@ import (
 	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/about"
 	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/api"
 	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/asn"
-	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/ats"
 	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/cachegroup"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/capability"
 	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/cdn"
 
 	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/crconfig"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/deliveryservice"
 	dsrequest "github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/deliveryservice/request"
 	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/deliveryservice/request/comment"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/deliveryservice/request/policy"
 	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/division"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/dnssec"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/events"
 	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/hwinfo"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/job"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/login"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/metrics"
 	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/parameter"
 	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/physlocation"
 	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/ping"
 	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/profile"
 	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/profileparameter"
 	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/region"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/role"
 	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/server"
 	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/status"
 	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/systeminfo"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/tenant"
 	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/types"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/user"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/webhook"
 
 	"github.com/basho/riak-go-client"
 )
@@ -77,163 +89,276 @@ func Routes(d ServerData) ([]Route, []RawRoute, http.Handler, error) {
 		// 1.2 routes are simply a Go replacement for the equivalent Perl route. They may or may not conform with the API guidelines (https://cwiki.apache.org/confluence/display/TC/API+Guidelines).
 
 		//ASN: CRUD
-		{1.2, http.MethodGet, `asns/?(\.json)?$`, api.ReadHandler(asn.GetRefType(), d.DB), auth.PrivLevelReadOnly, Authenticated, nil},
-		{1.2, http.MethodGet, `asns/{id}$`, api.ReadHandler(asn.GetRefType(), d.DB), auth.PrivLevelReadOnly, Authenticated, nil},
-		{1.2, http.MethodPut, `asns/{id}$`, api.UpdateHandler(asn.GetRefType(), d.DB), auth.PrivLevelOperations, Authenticated, nil},
-		{1.2, http.MethodPost, `asns/?$`, api.CreateHandler(asn.GetRefType(), d.DB), auth.PrivLevelOperations, Authenticated, nil},
-		{1.2, http.MethodDelete, `asns/{id}$`, api.DeleteHandler(asn.GetRefType(), d.DB), auth.PrivLevelOperations, Authenticated, nil},
+		{1.2, http.MethodGet, `asns/?(\.json)?$`, api.ReadHandler(asn.GetRefType(), d.DB), "asn-read", Authenticated, nil},
+		{1.2, http.MethodGet, `asns/{id}$`, api.ReadHandler(asn.GetRefType(), d.DB), "asn-read", Authenticated, nil},
+		{1.2, http.MethodPut, `asns/{id}$`, api.UpdateHandler(asn.GetRefType(), d.DB), "asn-write", Authenticated, nil},
+		{1.2, http.MethodPost, `asns/?$`, api.CreateHandler(asn.GetRefType(), d.DB), "asn-write", Authenticated, nil},
+		{1.2, http.MethodDelete, `asns/{id}$`, api.DeleteHandler(asn.GetRefType(), d.DB), "asn-write", Authenticated, nil},
 
 		//CacheGroup: CRUD
-		{1.2, http.MethodGet, `cachegroups/?(\.json)?$`, api.ReadHandler(cachegroup.GetRefType(), d.DB), auth.PrivLevelReadOnly, Authenticated, nil},
-		{1.2, http.MethodGet, `cachegroups/{id}$`, api.ReadHandler(cachegroup.GetRefType(), d.DB), auth.PrivLevelReadOnly, Authenticated, nil},
-		{1.2, http.MethodPut, `cachegroups/{id}$`, api.UpdateHandler(cachegroup.GetRefType(), d.DB), auth.PrivLevelOperations, Authenticated, nil},
-		{1.2, http.MethodPost, `cachegroups/?$`, api.CreateHandler(cachegroup.GetRefType(), d.DB), auth.PrivLevelOperations, Authenticated, nil},
-		{1.2, http.MethodDelete, `cachegroups/{id}$`, api.DeleteHandler(cachegroup.GetRefType(), d.DB), auth.PrivLevelOperations, Authenticated, nil},
+		{1.2, http.MethodGet, `cachegroups/?(\.json)?$`, api.ReadHandler(cachegroup.GetRefType(), d.DB), "cache-group-read", Authenticated, nil},
+		{1.2, http.MethodGet, `cachegroups/{id}$`, api.ReadHandler(cachegroup.GetRefType(), d.DB), "cache-group-read", Authenticated, nil},
+		{1.2, http.MethodPut, `cachegroups/{id}$`, api.UpdateHandler(cachegroup.GetRefType(), d.DB), "cache-group-write", Authenticated, nil},
+		{1.2, http.MethodPost, `cachegroups/?$`, api.CreateHandler(cachegroup.GetRefType(), d.DB), "cache-group-write", Authenticated, nil},
+		{1.2, http.MethodDelete, `cachegroups/{id}$`, api.DeleteHandler(cachegroup.GetRefType(), d.DB), "cache-group-write", Authenticated, nil},
 
 		//CDN
-		{1.2, http.MethodGet, `cdns/capacity$`, handlerToFunc(proxyHandler), 0, NoAuth, []Middleware{}},
-		{1.2, http.MethodGet, `cdns/configs$`, handlerToFunc(proxyHandler), 0, NoAuth, []Middleware{}},
-		{1.2, http.MethodGet, `cdns/domains$`, handlerToFunc(proxyHandler), 0, NoAuth, []Middleware{}},
-		{1.2, http.MethodGet, `cdns/health$`, handlerToFunc(proxyHandler), 0, NoAuth, []Middleware{}},
-		{1.2, http.MethodGet, `cdns/routing$`, handlerToFunc(proxyHandler), 0, NoAuth, []Middleware{}},
+		{1.2, http.MethodGet, `cdns/capacity$`, cdn.CapacityHandler(d.DB), "cdn-read", Authenticated, nil},
+		{1.2, http.MethodGet, `cdns/configs$`, handlerToFunc(proxyHandler), "", NoAuth, []Middleware{}},
+		{1.2, http.MethodGet, `cdns/domains$`, handlerToFunc(proxyHandler), "", NoAuth, []Middleware{}},
+		{1.2, http.MethodGet, `cdns/health$`, cdn.HealthHandler(d.DB), "cdn-read", Authenticated, nil},
+		{1.2, http.MethodGet, `cdns/{name}/health$`, cdn.HealthHandler(d.DB), "cdn-read", Authenticated, nil},
+		{1.2, http.MethodGet, `cdns/routing$`, cdn.RoutingHandler(d.DB), "cdn-read", Authenticated, nil},
 
 		//CDN: CRUD
-		{1.2, http.MethodGet, `cdns/?(\.json)?$`, api.ReadHandler(cdn.GetRefType(), d.DB), auth.PrivLevelReadOnly, Authenticated, nil},
-		{1.2, http.MethodGet, `cdns/{id}$`, api.ReadHandler(cdn.GetRefType(), d.DB), auth.PrivLevelReadOnly, Authenticated, nil},
-		{1.2, http.MethodPut, `cdns/{id}$`, api.UpdateHandler(cdn.GetRefType(), d.DB), auth.PrivLevelOperations, Authenticated, nil},
-		{1.2, http.MethodPost, `cdns/?$`, api.CreateHandler(cdn.GetRefType(), d.DB), auth.PrivLevelOperations, Authenticated, nil},
-		{1.2, http.MethodDelete, `cdns/{id}$`, api.DeleteHandler(cdn.GetRefType(), d.DB), auth.PrivLevelOperations, Authenticated, nil},
+		{1.2, http.MethodGet, `cdns/?(\.json)?$`, api.ReadHandler(cdn.GetRefType(), d.DB), "cdn-read", Authenticated, nil},
+		{1.2, http.MethodGet, `cdns/{id}$`, api.ReadHandler(cdn.GetRefType(), d.DB), "cdn-read", Authenticated, nil},
+		{1.2, http.MethodPut, `cdns/{id}$`, api.UpdateHandler(cdn.GetRefType(), d.DB), "cdn-write", Authenticated, nil},
+		{1.2, http.MethodPost, `cdns/?$`, api.CreateHandler(cdn.GetRefType(), d.DB), "cdn-write", Authenticated, nil},
+		{1.2, http.MethodDelete, `cdns/{id}$`, api.DeleteHandler(cdn.GetRefType(), d.DB), "cdn-write", Authenticated, nil},
+
+		//CDN: DNSSEC keys
+		{1.2, http.MethodGet, `cdns/name/{name}/dnsseckeys/?(\.json)?$`, dnssec.GetKeysHandler(d.DB, d.Config), "ds-security-keys-read", Authenticated, nil},
+		{1.2, http.MethodGet, `cdns/name/{name}/dnsseckeys/delete/?(\.json)?$`, dnssec.DeleteHandler(d.DB, d.Config), "ds-security-keys-write", Authenticated, nil},
+		{1.2, http.MethodPost, `cdns/dnsseckeys/generate/?(\.json)?$`, dnssec.GenerateHandler(d.DB, d.Config), "ds-security-keys-write", Authenticated, nil},
 
 		//CDN: Monitoring: Traffic Monitor
-		{1.2, http.MethodGet, `cdns/{name}/configs/monitoring(\.json)?$`, monitoringHandler(d.DB), auth.PrivLevelReadOnly, Authenticated, nil},
+		{1.2, http.MethodGet, `cdns/{name}/configs/monitoring(\.json)?$`, monitoringHandler(d.DB), "cdn-read", Authenticated, nil},
 
 		//Division: CRUD
-		{1.2, http.MethodGet, `divisions/?(\.json)?$`, api.ReadHandler(division.GetRefType(), d.DB), auth.PrivLevelReadOnly, Authenticated, nil},
-		{1.2, http.MethodGet, `divisions/{id}$`, api.ReadHandler(division.GetRefType(), d.DB), auth.PrivLevelReadOnly, Authenticated, nil},
-		{1.2, http.MethodPut, `divisions/{id}$`, api.UpdateHandler(division.GetRefType(), d.DB), auth.PrivLevelOperations, Authenticated, nil},
-		{1.2, http.MethodPost, `divisions/?$`, api.CreateHandler(division.GetRefType(), d.DB), auth.PrivLevelOperations, Authenticated, nil},
-		{1.2, http.MethodDelete, `divisions/{id}$`, api.DeleteHandler(division.GetRefType(), d.DB), auth.PrivLevelOperations, Authenticated, nil},
+		{1.2, http.MethodGet, `divisions/?(\.json)?$`, api.ReadHandler(division.GetRefType(), d.DB), "division-read", Authenticated, nil},
+		{1.2, http.MethodGet, `divisions/{id}$`, api.ReadHandler(division.GetRefType(), d.DB), "division-read", Authenticated, nil},
+		{1.2, http.MethodPut, `divisions/{id}$`, api.UpdateHandler(division.GetRefType(), d.DB), "division-write", Authenticated, nil},
+		{1.2, http.MethodPost, `divisions/?$`, api.CreateHandler(division.GetRefType(), d.DB), "division-write", Authenticated, nil},
+		{1.2, http.MethodDelete, `divisions/{id}$`, api.DeleteHandler(division.GetRefType(), d.DB), "division-write", Authenticated, nil},
 
 		//HWInfo
-		{1.2, http.MethodGet, `hwinfo-wip/?(\.json)?$`, hwinfo.HWInfoHandler(d.DB), auth.PrivLevelReadOnly, Authenticated, nil},
+		{1.2, http.MethodGet, `hwinfo-wip/?(\.json)?$`, hwinfo.HWInfoHandler(d.DB), "server-read", Authenticated, nil},
+
+		//Parameter: bulk CRUD, which must precede parameters/{id}
+		{1.3, http.MethodPost, `parameters/bulk/?$`, api.BulkCreateHandler(parameter.GetRefType(), d.DB), "params-write", Authenticated, nil},
+		{1.3, http.MethodPut, `parameters/bulk/?$`, api.BulkUpdateHandler(parameter.GetRefType(), d.DB), "params-write", Authenticated, nil},
+		{1.3, http.MethodDelete, `parameters/bulk/?$`, api.BulkDeleteHandler(parameter.GetRefType(), d.DB), "params-write", Authenticated, nil},
 
 		//Parameter: CRUD
-		{1.2, http.MethodGet, `parameters/?(\.json)?$`, api.ReadHandler(parameter.GetRefType(), d.DB), auth.PrivLevelReadOnly, Authenticated, nil},
-		{1.2, http.MethodGet, `parameters/{id}$`, api.ReadHandler(parameter.GetRefType(), d.DB), auth.PrivLevelReadOnly, Authenticated, nil},
-		{1.2, http.MethodPut, `parameters/{id}$`, api.UpdateHandler(parameter.GetRefType(), d.DB), auth.PrivLevelOperations, Authenticated, nil},
-		{1.2, http.MethodPost, `parameters/?$`, api.CreateHandler(parameter.GetRefType(), d.DB), auth.PrivLevelOperations, Authenticated, nil},
-		{1.2, http.MethodDelete, `parameters/{id}$`, api.DeleteHandler(parameter.GetRefType(), d.DB), auth.PrivLevelOperations, Authenticated, nil},
+		{1.2, http.MethodGet, `parameters/?(\.json)?$`, api.ReadHandler(parameter.GetRefType(), d.DB), "params-read", Authenticated, nil},
+		{1.2, http.MethodGet, `parameters/{id}$`, api.ReadHandler(parameter.GetRefType(), d.DB), "params-read", Authenticated, nil},
+		{1.2, http.MethodPut, `parameters/{id}$`, api.UpdateHandler(parameter.GetRefType(), d.DB), "params-write", Authenticated, nil},
+		{1.2, http.MethodPost, `parameters/?$`, api.CreateHandler(parameter.GetRefType(), d.DB), "params-write", Authenticated, nil},
+		{1.2, http.MethodDelete, `parameters/{id}$`, api.DeleteHandler(parameter.GetRefType(), d.DB), "params-write", Authenticated, nil},
 
 		//Phys_Location: CRUD
-		{1.2, http.MethodGet, `phys_locations/?(\.json)?$`, api.ReadHandler(physlocation.GetRefType(), d.DB), auth.PrivLevelReadOnly, Authenticated, nil},
-		{1.2, http.MethodGet, `phys_locations/{id}$`, api.ReadHandler(physlocation.GetRefType(), d.DB), auth.PrivLevelReadOnly, Authenticated, nil},
-		{1.2, http.MethodPut, `phys_locations/{id}$`, api.UpdateHandler(physlocation.GetRefType(), d.DB), auth.PrivLevelOperations, Authenticated, nil},
-		{1.2, http.MethodPost, `phys_locations/?$`, api.CreateHandler(physlocation.GetRefType(), d.DB), auth.PrivLevelOperations, Authenticated, nil},
-		{1.2, http.MethodDelete, `phys_locations/{id}$`, api.DeleteHandler(physlocation.GetRefType(), d.DB), auth.PrivLevelOperations, Authenticated, nil},
+		{1.2, http.MethodGet, `phys_locations/?(\.json)?$`, api.ReadHandler(physlocation.GetRefType(), d.DB), "phys-location-read", Authenticated, nil},
+		{1.2, http.MethodGet, `phys_locations/{id}$`, api.ReadHandler(physlocation.GetRefType(), d.DB), "phys-location-read", Authenticated, nil},
+		{1.2, http.MethodPut, `phys_locations/{id}$`, api.UpdateHandler(physlocation.GetRefType(), d.DB), "phys-location-write", Authenticated, nil},
+		{1.2, http.MethodPost, `phys_locations/?$`, api.CreateHandler(physlocation.GetRefType(), d.DB), "phys-location-write", Authenticated, nil},
+		{1.2, http.MethodDelete, `phys_locations/{id}$`, api.DeleteHandler(physlocation.GetRefType(), d.DB), "phys-location-write", Authenticated, nil},
 
 		//Ping
-		{1.2, http.MethodGet, `ping$`, ping.PingHandler(), 0, NoAuth, nil},
+		{1.2, http.MethodGet, `ping$`, ping.PingHandler(), "", NoAuth, nil},
 
 		//Profile: CRUD
-		{1.2, http.MethodGet, `profiles/?(\.json)?$`, api.ReadHandler(profile.GetRefType(), d.DB), auth.PrivLevelReadOnly, Authenticated, nil},
-		{1.2, http.MethodGet, `profiles/{id}$`, api.ReadHandler(profile.GetRefType(), d.DB), auth.PrivLevelReadOnly, Authenticated, nil},
-		{1.2, http.MethodPut, `profiles/{id}$`, api.UpdateHandler(profile.GetRefType(), d.DB), auth.PrivLevelOperations, Authenticated, nil},
-		{1.2, http.MethodPost, `profiles/?$`, api.CreateHandler(profile.GetRefType(), d.DB), auth.PrivLevelOperations, Authenticated, nil},
-		{1.2, http.MethodDelete, `profiles/{id}$`, api.DeleteHandler(profile.GetRefType(), d.DB), auth.PrivLevelOperations, Authenticated, nil},
+		{1.2, http.MethodGet, `profiles/?(\.json)?$`, api.ReadHandler(profile.GetRefType(), d.DB), "profile-read", Authenticated, nil},
+		{1.2, http.MethodGet, `profiles/{id}$`, api.ReadHandler(profile.GetRefType(), d.DB), "profile-read", Authenticated, nil},
+		{1.2, http.MethodPut, `profiles/{id}$`, api.UpdateHandler(profile.GetRefType(), d.DB), "profile-write", Authenticated, nil},
+		{1.2, http.MethodPost, `profiles/?$`, api.CreateHandler(profile.GetRefType(), d.DB), "profile-write", Authenticated, nil},
+		{1.2, http.MethodDelete, `profiles/{id}$`, api.DeleteHandler(profile.GetRefType(), d.DB), "profile-write", Authenticated, nil},
 
 		//Region: CRUD
-		{1.2, http.MethodGet, `regions/?(\.json)?$`, api.ReadHandler(region.GetRefType(), d.DB), auth.PrivLevelReadOnly, Authenticated, nil},
-		{1.2, http.MethodGet, `regions/{id}$`, api.ReadHandler(region.GetRefType(), d.DB), auth.PrivLevelReadOnly, Authenticated, nil},
-		{1.2, http.MethodPut, `regions/{id}$`, api.UpdateHandler(region.GetRefType(), d.DB), auth.PrivLevelOperations, Authenticated, nil},
-		{1.2, http.MethodPost, `regions/?$`, api.CreateHandler(region.GetRefType(), d.DB), auth.PrivLevelOperations, Authenticated, nil},
-		{1.2, http.MethodDelete, `regions/{id}$`, api.DeleteHandler(region.GetRefType(), d.DB), auth.PrivLevelOperations, Authenticated, nil},
+		{1.2, http.MethodGet, `regions/?(\.json)?$`, api.ReadHandler(region.GetRefType(), d.DB), "region-read", Authenticated, nil},
+		{1.2, http.MethodGet, `regions/{id}$`, api.ReadHandler(region.GetRefType(), d.DB), "region-read", Authenticated, nil},
+		{1.2, http.MethodPut, `regions/{id}$`, api.UpdateHandler(region.GetRefType(), d.DB), "region-write", Authenticated, nil},
+		{1.2, http.MethodPost, `regions/?$`, api.CreateHandler(region.GetRefType(), d.DB), "region-write", Authenticated, nil},
+		{1.2, http.MethodDelete, `regions/{id}$`, api.DeleteHandler(region.GetRefType(), d.DB), "region-write", Authenticated, nil},
 
 		//Server
-		{1.2, http.MethodGet, `servers/checks$`, handlerToFunc(proxyHandler), 0, NoAuth, []Middleware{}},
-		{1.2, http.MethodGet, `servers/details$`, handlerToFunc(proxyHandler), 0, NoAuth, []Middleware{}},
-		{1.2, http.MethodGet, `servers/status$`, handlerToFunc(proxyHandler), 0, NoAuth, []Middleware{}},
-		{1.2, http.MethodGet, `servers/totals$`, handlerToFunc(proxyHandler), 0, NoAuth, []Middleware{}},
+		{1.2, http.MethodGet, `servers/checks$`, handlerToFunc(proxyHandler), "", NoAuth, []Middleware{}},
+		{1.2, http.MethodGet, `servers/details$`, handlerToFunc(proxyHandler), "", NoAuth, []Middleware{}},
+		{1.2, http.MethodGet, `servers/status$`, server.GetStatusCountsHandler(d.DB), "server-read", Authenticated, nil},
+		{1.2, http.MethodGet, `servers/totals$`, server.GetTypeCountsHandler(d.DB), "server-read", Authenticated, nil},
+
+		//Server: bulk CRUD, which must precede servers/{id}
+		{1.3, http.MethodPost, `servers/bulk/?$`, api.BulkCreateHandler(server.GetRefType(), d.DB), "server-write", Authenticated, nil},
+		{1.3, http.MethodPut, `servers/bulk/?$`, api.BulkUpdateHandler(server.GetRefType(), d.DB), "server-write", Authenticated, nil},
+		{1.3, http.MethodDelete, `servers/bulk/?$`, api.BulkDeleteHandler(server.GetRefType(), d.DB), "server-write", Authenticated, nil},
 
 		//Server: CRUD
-		{1.2, http.MethodGet, `servers/?(\.json)?$`, api.ReadHandler(server.GetRefType(), d.DB), auth.PrivLevelReadOnly, Authenticated, nil},
-		{1.2, http.MethodGet, `servers/{id}$`, api.ReadHandler(server.GetRefType(), d.DB), auth.PrivLevelReadOnly, Authenticated, nil},
-		{1.2, http.MethodPut, `servers/{id}$`, api.UpdateHandler(server.GetRefType(), d.DB), auth.PrivLevelOperations, Authenticated, nil},
-		{1.2, http.MethodPost, `servers/?$`, api.CreateHandler(server.GetRefType(), d.DB), auth.PrivLevelOperations, Authenticated, nil},
-		{1.2, http.MethodDelete, `servers/{id}$`, api.DeleteHandler(server.GetRefType(), d.DB), auth.PrivLevelOperations, Authenticated, nil},
+		{1.2, http.MethodGet, `servers/?(\.json)?$`, api.ReadHandler(server.GetRefType(), d.DB), "server-read", Authenticated, nil},
+		{1.2, http.MethodGet, `servers/{id}$`, api.ReadHandler(server.GetRefType(), d.DB), "server-read", Authenticated, nil},
+		{1.2, http.MethodPut, `servers/{id}$`, api.UpdateHandler(server.GetRefType(), d.DB), "server-write", Authenticated, nil},
+		{1.2, http.MethodPost, `servers/?$`, api.CreateHandler(server.GetRefType(), d.DB), "server-write", Authenticated, nil},
+		{1.2, http.MethodDelete, `servers/{id}$`, api.DeleteHandler(server.GetRefType(), d.DB), "server-write", Authenticated, nil},
+
+		//Server: queue updates
+		{1.2, http.MethodPost, `servers/{id}/queue_update/?$`, server.QueueUpdateHandler(d.DB), "server-write", Authenticated, nil},
+		{1.2, http.MethodPost, `cachegroups/{id}/queue_update/?$`, server.QueueCachegroupUpdatesHandler(d.DB), "server-write", Authenticated, nil},
+		{1.2, http.MethodPost, `cdns/{id}/queue_update/?$`, server.QueueCDNUpdatesHandler(d.DB), "server-write", Authenticated, nil},
+
+		//Server: ATS config files
+		{1.2, http.MethodGet, `servers/{id}/configfiles/ats/parent\.config/?$`, ats.ParentDotConfigHandler(d.DB), "cache-config-files-read", Authenticated, nil},
+		{1.2, http.MethodGet, `servers/{id}/configfiles/ats/remap\.config/?$`, ats.RemapDotConfigHandler(d.DB), "cache-config-files-read", Authenticated, nil},
+
+		//CDN: ATS config files
+		{1.2, http.MethodGet, `cdns/{id}/configfiles/ats/regex_revalidate\.config/?$`, ats.RegexRevalidateDotConfigHandler(d.DB), "cache-config-files-read", Authenticated, nil},
 
 		//Status: CRUD
-		{1.2, http.MethodGet, `statuses/?(\.json)?$`, api.ReadHandler(status.GetRefType(), d.DB), auth.PrivLevelReadOnly, Authenticated, nil},
-		{1.2, http.MethodGet, `statuses/{id}$`, api.ReadHandler(status.GetRefType(), d.DB), auth.PrivLevelReadOnly, Authenticated, nil},
-		{1.2, http.MethodPut, `statuses/{id}$`, api.UpdateHandler(status.GetRefType(), d.DB), auth.PrivLevelOperations, Authenticated, nil},
-		{1.2, http.MethodPost, `statuses/?$`, api.CreateHandler(status.GetRefType(), d.DB), auth.PrivLevelOperations, Authenticated, nil},
-		{1.2, http.MethodDelete, `statuses/{id}$`, api.DeleteHandler(status.GetRefType(), d.DB), auth.PrivLevelOperations, Authenticated, nil},
+		{1.2, http.MethodGet, `statuses/?(\.json)?$`, api.ReadHandler(status.GetRefType(), d.DB), "status-read", Authenticated, nil},
+		{1.2, http.MethodGet, `statuses/{id}$`, api.ReadHandler(status.GetRefType(), d.DB), "status-read", Authenticated, nil},
+		{1.2, http.MethodPut, `statuses/{id}$`, api.UpdateHandler(status.GetRefType(), d.DB), "status-write", Authenticated, nil},
+		{1.2, http.MethodPost, `statuses/?$`, api.CreateHandler(status.GetRefType(), d.DB), "status-write", Authenticated, nil},
+		{1.2, http.MethodDelete, `statuses/{id}$`, api.DeleteHandler(status.GetRefType(), d.DB), "status-write", Authenticated, nil},
 
 		//System
-		{1.2, http.MethodGet, `system/info/?(\.json)?$`, systeminfo.Handler(d.DB), auth.PrivLevelReadOnly, Authenticated, nil},
+		{1.2, http.MethodGet, `system/info/?(\.json)?$`, systeminfo.Handler(d.DB), "params-read", Authenticated, nil},
 
 		//Type: CRUD
-		{1.2, http.MethodGet, `types/?(\.json)?$`, api.ReadHandler(types.GetRefType(), d.DB), auth.PrivLevelReadOnly, Authenticated, nil},
-		{1.2, http.MethodGet, `types/{id}$`, api.ReadHandler(types.GetRefType(), d.DB), auth.PrivLevelReadOnly, Authenticated, nil},
-		{1.2, http.MethodPut, `types/{id}$`, api.UpdateHandler(types.GetRefType(), d.DB), auth.PrivLevelOperations, Authenticated, nil},
-		{1.2, http.MethodPost, `types/?$`, api.CreateHandler(types.GetRefType(), d.DB), auth.PrivLevelOperations, Authenticated, nil},
-		{1.2, http.MethodDelete, `types/{id}$`, api.DeleteHandler(types.GetRefType(), d.DB), auth.PrivLevelOperations, Authenticated, nil},
+		{1.2, http.MethodGet, `types/?(\.json)?$`, api.ReadHandler(types.GetRefType(), d.DB), "type-read", Authenticated, nil},
+		{1.2, http.MethodGet, `types/{id}$`, api.ReadHandler(types.GetRefType(), d.DB), "type-read", Authenticated, nil},
+		{1.2, http.MethodPut, `types/{id}$`, api.UpdateHandler(types.GetRefType(), d.DB), "type-write", Authenticated, nil},
+		{1.2, http.MethodPost, `types/?$`, api.CreateHandler(types.GetRefType(), d.DB), "type-write", Authenticated, nil},
+		{1.2, http.MethodDelete, `types/{id}$`, api.DeleteHandler(types.GetRefType(), d.DB), "type-write", Authenticated, nil},
 
 		// ************************************************** 1.3 Routes *************************************************************************************
 		// 1.3 routes exist only in a Go. There is NO equivalent Perl route. They should conform with the API guidelines (https://cwiki.apache.org/confluence/display/TC/API+Guidelines).
 
 		//About
-		{1.3, http.MethodGet, `about/?(\.json)?$`, about.Handler(), auth.PrivLevelReadOnly, Authenticated, nil},
+		{1.3, http.MethodGet, `about/?(\.json)?$`, about.Handler(), "basic-read", Authenticated, nil},
+
+		//User: login, logout, and API tokens
+		{1.3, http.MethodPost, `user/login/?$`, login.LoginHandler(d.DB, d.Config), "", NoAuth, nil},
+		{1.3, http.MethodPost, `user/logout/?$`, login.LogoutHandler(d.DB, d.Config), "basic-write", Authenticated, nil},
+		{1.3, http.MethodGet, `user/tokens/?(\.json)?$`, login.GetTokensHandler(d.DB), "basic-read", Authenticated, nil},
+		{1.3, http.MethodPost, `user/tokens/?$`, login.CreateTokenHandler(d.DB), "basic-write", Authenticated, nil},
+		{1.3, http.MethodDelete, `user/tokens/{id}$`, login.DeleteTokenHandler(d.DB), "basic-write", Authenticated, nil},
+
+		//Capability: CRUD
+		{1.3, http.MethodGet, `capabilities/?(\.json)?$`, api.ReadHandler(capability.GetRefType(), d.DB), "role-read", Authenticated, nil},
+		{1.3, http.MethodGet, `capabilities/{name}$`, api.ReadHandler(capability.GetRefType(), d.DB), "role-read", Authenticated, nil},
+		{1.3, http.MethodPut, `capabilities/{name}$`, api.UpdateHandler(capability.GetRefType(), d.DB), "role-write", Authenticated, nil},
+		{1.3, http.MethodPost, `capabilities/?$`, api.CreateHandler(capability.GetRefType(), d.DB), "role-write", Authenticated, nil},
+		{1.3, http.MethodDelete, `capabilities/{name}$`, api.DeleteHandler(capability.GetRefType(), d.DB), "role-write", Authenticated, nil},
+
+		//Role: CRUD
+		{1.3, http.MethodGet, `roles/?(\.json)?$`, api.ReadHandler(role.GetRefType(), d.DB), "role-read", Authenticated, nil},
+		{1.3, http.MethodGet, `roles/{id}$`, api.ReadHandler(role.GetRefType(), d.DB), "role-read", Authenticated, nil},
+		{1.3, http.MethodPut, `roles/{id}$`, api.UpdateHandler(role.GetRefType(), d.DB), "role-write", Authenticated, nil},
+		{1.3, http.MethodPost, `roles/?$`, api.CreateHandler(role.GetRefType(), d.DB), "role-write", Authenticated, nil},
+		{1.3, http.MethodDelete, `roles/{id}$`, api.DeleteHandler(role.GetRefType(), d.DB), "role-write", Authenticated, nil},
+
+		//Tenant: CRUD
+		{1.3, http.MethodGet, `tenants/?(\.json)?$`, api.ReadHandler(tenant.GetRefType(), d.DB), "tenant-read", Authenticated, nil},
+		{1.3, http.MethodGet, `tenants/{id}$`, api.ReadHandler(tenant.GetRefType(), d.DB), "tenant-read", Authenticated, nil},
+		{1.3, http.MethodPut, `tenants/{id}$`, api.UpdateHandler(tenant.GetRefType(), d.DB), "tenant-write", Authenticated, nil},
+		{1.3, http.MethodPost, `tenants/?$`, api.CreateHandler(tenant.GetRefType(), d.DB), "tenant-write", Authenticated, nil},
+		{1.3, http.MethodDelete, `tenants/{id}$`, api.DeleteHandler(tenant.GetRefType(), d.DB), "tenant-write", Authenticated, nil},
+
+		//User: read only, filtered by tenant
+		{1.3, http.MethodGet, `users/?(\.json)?$`, api.ReadHandler(user.GetRefType(), d.DB), "user-read", Authenticated, nil},
+		{1.3, http.MethodGet, `users/{id}$`, api.ReadHandler(user.GetRefType(), d.DB), "user-read", Authenticated, nil},
+		{1.3, http.MethodGet, `users/{id}/sessions/?(\.json)?$`, user.GetSessionsHandler(d.DB), "user-read", Authenticated, nil},
+		{1.3, http.MethodDelete, `users/{id}/sessions/?$`, user.DeleteSessionsHandler(d.DB), "user-write", Authenticated, nil},
+		{1.3, http.MethodDelete, `users/{id}/sessions/{session}$`, user.DeleteSessionsHandler(d.DB), "user-write", Authenticated, nil},
+
+		//CDN: DNSSEC keys
+		{1.3, http.MethodDelete, `cdns/name/{name}/dnsseckeys/?$`, dnssec.DeleteHandler(d.DB, d.Config), "ds-security-keys-write", Authenticated, nil},
+		{1.3, http.MethodPost, `cdns/{name}/dnsseckeys/ksk/generate/?$`, dnssec.GenerateKSKHandler(d.DB, d.Config), "ds-security-keys-write", Authenticated, nil},
+		{1.3, http.MethodGet, `cdns/{name}/dnsseckeys/ds/?$`, dnssec.DSRecordsHandler(d.DB, d.Config), "ds-security-keys-read", Authenticated, nil},
+		{1.3, http.MethodPost, `cdns/dnsseckeys/refresh/?$`, dnssec.RefreshHandler(d.DB, d.Config), "ds-security-keys-write", Authenticated, nil},
 
 		//Delivery service request: CRUD
-		{1.3, http.MethodGet, `deliveryservice_requests/?(\.json)?$`, api.ReadHandler(dsrequest.GetRefType(), d.DB), auth.PrivLevelReadOnly, Authenticated, nil},
-		{1.3, http.MethodPut, `deliveryservice_requests/?$`, api.UpdateHandler(dsrequest.GetRefType(), d.DB), auth.PrivLevelPortal, Authenticated, nil},
-		{1.3, http.MethodPost, `deliveryservice_requests/?$`, api.CreateHandler(dsrequest.GetRefType(), d.DB), auth.PrivLevelPortal, Authenticated, nil},
-		{1.3, http.MethodDelete, `deliveryservice_requests/?$`, api.DeleteHandler(dsrequest.GetRefType(), d.DB), auth.PrivLevelPortal, Authenticated, nil},
+		{1.3, http.MethodGet, `deliveryservice_requests/?(\.json)?$`, api.ReadHandler(dsrequest.GetRefType(), d.DB), "ds-request-read", Authenticated, nil},
+		{1.3, http.MethodPut, `deliveryservice_requests/?$`, api.UpdateHandler(dsrequest.GetRefType(), d.DB), "ds-request-write", Authenticated, nil},
+		{1.3, http.MethodPost, `deliveryservice_requests/?$`, api.CreateHandler(dsrequest.GetRefType(), d.DB), "ds-request-write", Authenticated, nil},
+		{1.3, http.MethodDelete, `deliveryservice_requests/?$`, api.DeleteHandler(dsrequest.GetRefType(), d.DB), "ds-request-write", Authenticated, nil},
 
 		//Delivery service request: Actions
-		{1.3, http.MethodPut, `deliveryservice_requests/{id}/assign$`, api.UpdateHandler(dsrequest.GetAssignRefType(), d.DB), auth.PrivLevelOperations, Authenticated, nil},
-		{1.3, http.MethodPut, `deliveryservice_requests/{id}/status$`, api.UpdateHandler(dsrequest.GetStatusRefType(), d.DB), auth.PrivLevelPortal, Authenticated, nil},
+		{1.3, http.MethodPut, `deliveryservice_requests/{id}/assign$`, api.UpdateHandler(dsrequest.GetAssignRefType(), d.DB), "ds-request-assign", Authenticated, nil},
+		{1.3, http.MethodPut, `deliveryservice_requests/{id}/status$`, api.UpdateHandler(dsrequest.GetStatusRefType(), d.DB), "ds-request-write", Authenticated, nil},
+		{1.3, http.MethodPost, `deliveryservice_requests/{id}/fulfill/?$`, dsrequest.FulfillHandler(d.DB, d.Config), "ds-write", Authenticated, nil},
+		{1.3, http.MethodGet, `deliveryservice_requests/{id}/approvals/?(\.json)?$`, dsrequest.ApprovalsHandler(d.DB), "ds-request-read", Authenticated, nil},
+
+		//Jobs: CRUD
+		{1.3, http.MethodGet, `jobs/?(\.json)?$`, api.ReadHandler(job.GetRefType(), d.DB), "job-read", Authenticated, nil},
+		{1.3, http.MethodGet, `jobs/{id}$`, api.ReadHandler(job.GetRefType(), d.DB), "job-read", Authenticated, nil},
+		{1.3, http.MethodPut, `jobs/{id}$`, api.UpdateHandler(job.GetRefType(), d.DB), "job-write", Authenticated, nil},
+		{1.3, http.MethodPost, `jobs/?$`, api.CreateHandler(job.GetRefType(), d.DB), "job-write", Authenticated, nil},
+		{1.3, http.MethodDelete, `jobs/{id}$`, api.DeleteHandler(job.GetRefType(), d.DB), "job-write", Authenticated, nil},
+
+		//Events: the change feed, as server-sent events. Not buffered by the default middleware, so events are written as they're published.
+		{1.3, http.MethodGet, `events/stream/?$`, events.StreamHandler(d.EventBus, d.DB), "event-read", Authenticated, []Middleware{}},
+
+		//Webhooks: CRUD
+		{1.3, http.MethodGet, `webhooks/?(\.json)?$`, api.ReadHandler(webhook.GetRefType(), d.DB), "webhook-read", Authenticated, nil},
+		{1.3, http.MethodGet, `webhooks/dead_letters/?$`, webhook.DeadLettersHandler(d.DB), "webhook-read", Authenticated, nil},
+		{1.3, http.MethodGet, `webhooks/{id}$`, api.ReadHandler(webhook.GetRefType(), d.DB), "webhook-read", Authenticated, nil},
+		{1.3, http.MethodPut, `webhooks/{id}$`, api.UpdateHandler(webhook.GetRefType(), d.DB), "webhook-write", Authenticated, nil},
+		{1.3, http.MethodPost, `webhooks/?$`, api.CreateHandler(webhook.GetRefType(), d.DB), "webhook-write", Authenticated, nil},
+		{1.3, http.MethodDelete, `webhooks/{id}$`, api.DeleteHandler(webhook.GetRefType(), d.DB), "webhook-write", Authenticated, nil},
 
 		//Delivery service request comment: CRUD
-		{1.3, http.MethodGet, `deliveryservice_request_comments/?(\.json)?$`, api.ReadHandler(comment.GetRefType(), d.DB), auth.PrivLevelReadOnly, Authenticated, nil},
-		{1.3, http.MethodPut, `deliveryservice_request_comments/?$`, api.UpdateHandler(comment.GetRefType(), d.DB), auth.PrivLevelPortal, Authenticated, nil},
-		{1.3, http.MethodPost, `deliveryservice_request_comments/?$`, api.CreateHandler(comment.GetRefType(), d.DB), auth.PrivLevelPortal, Authenticated, nil},
-		{1.3, http.MethodDelete, `deliveryservice_request_comments/?$`, api.DeleteHandler(comment.GetRefType(), d.DB), auth.PrivLevelPortal, Authenticated, nil},
+		{1.3, http.MethodGet, `deliveryservice_request_comments/?(\.json)?$`, api.ReadHandler(comment.GetRefType(), d.DB), "ds-request-read", Authenticated, nil},
+		{1.3, http.MethodPut, `deliveryservice_request_comments/?$`, api.UpdateHandler(comment.GetRefType(), d.DB), "ds-request-write", Authenticated, nil},
+		{1.3, http.MethodPost, `deliveryservice_request_comments/?$`, api.CreateHandler(comment.GetRefType(), d.DB), "ds-request-write", Authenticated, nil},
+		{1.3, http.MethodDelete, `deliveryservice_request_comments/?$`, api.DeleteHandler(comment.GetRefType(), d.DB), "ds-request-write", Authenticated, nil},
+
+		//Delivery service request approval policies: CRUD
+		{1.3, http.MethodGet, `deliveryservice_request_approval_policies/?(\.json)?$`, api.ReadHandler(policy.GetRefType(), d.DB), "ds-request-read", Authenticated, nil},
+		{1.3, http.MethodPut, `deliveryservice_request_approval_policies/?$`, api.UpdateHandler(policy.GetRefType(), d.DB), "ds-request-policy-write", Authenticated, nil},
+		{1.3, http.MethodPost, `deliveryservice_request_approval_policies/?$`, api.CreateHandler(policy.GetRefType(), d.DB), "ds-request-policy-write", Authenticated, nil},
+		{1.3, http.MethodDelete, `deliveryservice_request_approval_policies/?$`, api.DeleteHandler(policy.GetRefType(), d.DB), "ds-request-policy-write", Authenticated, nil},
+
+		//Delivery services: CRUD
+		{1.3, http.MethodGet, `deliveryservices/?(\.json)?$`, api.ReadHandler(deliveryservice.GetRefType(), d.DB), "ds-read", Authenticated, nil},
+		{1.3, http.MethodGet, `deliveryservices/{id}$`, api.ReadHandler(deliveryservice.GetRefType(), d.DB), "ds-read", Authenticated, nil},
+		{1.3, http.MethodPut, `deliveryservices/{id}$`, api.UpdateHandler(deliveryservice.GetRefType(), d.DB), "ds-write", Authenticated, nil},
+		{1.3, http.MethodPost, `deliveryservices/?$`, deliveryservice.CreateHandler(d.DB, d.Config), "ds-write", Authenticated, nil},
+		{1.3, http.MethodDelete, `deliveryservices/{id}$`, api.DeleteHandler(deliveryservice.GetRefType(), d.DB), "ds-write", Authenticated, nil},
 
 		//Delivery service uri signing keys: CRUD
-		{1.3, http.MethodGet, `deliveryservices/{xmlID}/urisignkeys$`, getURIsignkeysHandler(d.DB, d.Config), auth.PrivLevelAdmin, Authenticated, nil},
-		{1.3, http.MethodPost, `deliveryservices/{xmlID}/urisignkeys$`, saveDeliveryServiceURIKeysHandler(d.DB, d.Config), auth.PrivLevelAdmin, Authenticated, nil},
-		{1.3, http.MethodPut, `deliveryservices/{xmlID}/urisignkeys$`, saveDeliveryServiceURIKeysHandler(d.DB, d.Config), auth.PrivLevelAdmin, Authenticated, nil},
-		{1.3, http.MethodDelete, `deliveryservices/{xmlID}/urisignkeys$`, removeDeliveryServiceURIKeysHandler(d.DB, d.Config), auth.PrivLevelAdmin, Authenticated, nil},
+		{1.3, http.MethodGet, `deliveryservices/{xmlID}/urisignkeys$`, getURIsignkeysHandler(d.DB, d.Config), "ds-security-keys-read", Authenticated, nil},
+		{1.3, http.MethodPost, `deliveryservices/{xmlID}/urisignkeys$`, saveDeliveryServiceURIKeysHandler(d.DB, d.Config), "ds-security-keys-write", Authenticated, nil},
+		{1.3, http.MethodPut, `deliveryservices/{xmlID}/urisignkeys$`, saveDeliveryServiceURIKeysHandler(d.DB, d.Config), "ds-security-keys-write", Authenticated, nil},
+		{1.3, http.MethodDelete, `deliveryservices/{xmlID}/urisignkeys$`, removeDeliveryServiceURIKeysHandler(d.DB, d.Config), "ds-security-keys-write", Authenticated, nil},
 
 		//Servers
-		{1.3, http.MethodPost, `servers/{id}/deliveryservices$`, server.AssignDeliveryServicesToServerHandler(d.DB), auth.PrivLevelOperations, Authenticated, nil},
-		{1.3, http.MethodGet, `servers/{host_name}/update_status$`, server.GetServerUpdateStatusHandler(d.DB), auth.PrivLevelReadOnly, Authenticated, nil},
+		{1.3, http.MethodPost, `servers/{id}/deliveryservices$`, server.AssignDeliveryServicesToServerHandler(d.DB), "ds-cache-write", Authenticated, nil},
+		{1.3, http.MethodGet, `servers/{host_name}/update_status$`, server.GetServerUpdateStatusHandler(d.DB), "server-read", Authenticated, nil},
+		{1.3, http.MethodGet, `cdns/{name}/update_status/?$`, server.GetCDNServerUpdateStatusHandler(d.DB), "server-read", Authenticated, nil},
 
 		//ProfileParameters
-		{1.3, http.MethodGet, `profile_parameters/?(\.json)?$`, api.ReadHandler(profileparameter.GetRefType(), d.DB), auth.PrivLevelReadOnly, Authenticated, nil},
-		{1.3, http.MethodGet, `profile_parameters/{id}$`, api.ReadHandler(profileparameter.GetRefType(), d.DB), auth.PrivLevelReadOnly, Authenticated, nil},
-		{1.3, http.MethodPost, `profile_parameters/?$`, api.CreateHandler(profileparameter.GetRefType(), d.DB), auth.PrivLevelOperations, Authenticated, nil},
-		{1.3, http.MethodDelete, `profile_parameters/{id}$`, api.DeleteHandler(profileparameter.GetRefType(), d.DB), auth.PrivLevelOperations, Authenticated, nil},
+		{1.3, http.MethodGet, `profile_parameters/?(\.json)?$`, api.ReadHandler(profileparameter.GetRefType(), d.DB), "params-read", Authenticated, nil},
+		{1.3, http.MethodGet, `profile_parameters/{id}$`, api.ReadHandler(profileparameter.GetRefType(), d.DB), "params-read", Authenticated, nil},
+		{1.3, http.MethodPost, `profile_parameters/bulk/?$`, api.BulkCreateHandler(profileparameter.GetRefType(), d.DB), "params-write", Authenticated, nil},
+		{1.3, http.MethodDelete, `profile_parameters/bulk/?$`, api.BulkDeleteHandler(profileparameter.GetRefType(), d.DB), "params-write", Authenticated, nil},
+		{1.3, http.MethodPost, `profile_parameters/?$`, api.CreateHandler(profileparameter.GetRefType(), d.DB), "params-write", Authenticated, nil},
+		{1.3, http.MethodDelete, `profile_parameters/{id}$`, api.DeleteHandler(profileparameter.GetRefType(), d.DB), "params-write", Authenticated, nil},
 
 		//SSLKeys deliveryservice endpoints here that are marked  marked as '-wip' need to have tenancy checks added
-		{1.3, http.MethodGet, `deliveryservices-wip/xmlId/{xmlID}/sslkeys$`, getDeliveryServiceSSLKeysByXMLIDHandler(d.DB, d.Config), auth.PrivLevelAdmin, Authenticated, nil},
-		{1.3, http.MethodGet, `deliveryservices-wip/hostname/{hostName}/sslkeys$`, getDeliveryServiceSSLKeysByHostNameHandler(d.DB, d.Config), auth.PrivLevelAdmin, Authenticated, nil},
-		{1.3, http.MethodPost, `deliveryservices-wip/hostname/{hostName}/sslkeys/add$`, addDeliveryServiceSSLKeysHandler(d.DB, d.Config), auth.PrivLevelAdmin, Authenticated, nil},
+		{1.3, http.MethodGet, `deliveryservices-wip/xmlId/{xmlID}/sslkeys$`, getDeliveryServiceSSLKeysByXMLIDHandler(d.DB, d.Config), "ds-security-keys-read", Authenticated, nil},
+		{1.3, http.MethodGet, `deliveryservices-wip/hostname/{hostName}/sslkeys$`, getDeliveryServiceSSLKeysByHostNameHandler(d.DB, d.Config), "ds-security-keys-read", Authenticated, nil},
+		{1.3, http.MethodPost, `deliveryservices-wip/hostname/{hostName}/sslkeys/add$`, addDeliveryServiceSSLKeysHandler(d.DB, d.Config), "ds-security-keys-write", Authenticated, nil},
+		{1.3, http.MethodPost, `deliveryservices/{xmlID}/sslkeys/generate/?$`, generateDeliveryServiceSSLKeysHandler(d.DB, d.Config), "ds-security-keys-write", Authenticated, nil},
+		{1.3, http.MethodGet, `deliveryservices/sslkeys/certificates/?$`, getDeliveryServiceSSLCertificatesHandler(d.DB, d.Config), "ds-security-keys-read", Authenticated, nil},
 
 		//CRConfig
-		{1.2, http.MethodGet, `cdns/{cdn}/snapshot/?$`, crconfig.SnapshotGetHandler(d.DB, d.Config), crconfig.PrivLevel, Authenticated, nil},
-		{1.2, http.MethodGet, `cdns/{cdn}/snapshot/new/?$`, crconfig.Handler(d.DB, d.Config), crconfig.PrivLevel, Authenticated, nil},
-		{1.2, http.MethodPut, `cdns/{id}/snapshot/?$`, crconfig.SnapshotHandler(d.DB, d.Config), crconfig.PrivLevel, Authenticated, nil},
-		{1.2, http.MethodPut, `snapshot/{cdn}/?$`, crconfig.SnapshotHandler(d.DB, d.Config), crconfig.PrivLevel, Authenticated, nil},
+		{1.2, http.MethodGet, `cdns/{cdn}/snapshot/?$`, crconfig.SnapshotGetHandler(d.DB, d.Config), crconfig.SnapshotReadCapability, Authenticated, nil},
+		{1.2, http.MethodGet, `cdns/{cdn}/snapshot/new/?$`, crconfig.Handler(d.DB, d.Config), crconfig.SnapshotReadCapability, Authenticated, nil},
+		{1.2, http.MethodPut, `cdns/{id}/snapshot/?$`, crconfig.SnapshotHandler(d.DB, d.Config), crconfig.SnapshotWriteCapability, Authenticated, nil},
+		{1.2, http.MethodPut, `snapshot/{cdn}/?$`, crconfig.SnapshotHandler(d.DB, d.Config), crconfig.SnapshotWriteCapability, Authenticated, nil},
+		{1.3, http.MethodGet, `cdns/{cdn}/snapshot/validate/?$`, crconfig.SnapshotValidateHandler(d.DB, d.Config), crconfig.SnapshotReadCapability, Authenticated, nil},
+		{1.3, http.MethodGet, `cdns/{cdn}/snapshot/diff/?$`, crconfig.SnapshotDiffHandler(d.DB, d.Config), crconfig.SnapshotReadCapability, Authenticated, nil},
+		{1.3, http.MethodGet, `cdns/{cdn}/snapshot/history/?$`, crconfig.SnapshotHistoryHandler(d.DB, d.Config), crconfig.SnapshotReadCapability, Authenticated, nil},
+		{1.3, http.MethodPost, `cdns/{cdn}/snapshot/rollback/?$`, crconfig.SnapshotRollbackHandler(d.DB, d.Config), crconfig.SnapshotWriteCapability, Authenticated, nil},
 	}
 
 	// rawRoutes are served at the root path. These should be almost exclusively old Perl pre-API routes, which have yet to be converted in all clients. New routes should be in the versioned API path.
 	rawRoutes := []RawRoute{
 		// DEPRECATED - use PUT /api/1.2/snapshot/{cdn}
-		{http.MethodGet, `tools/write_crconfig/{cdn}/?$`, crconfig.SnapshotOldGUIHandler(d.DB, d.Config), crconfig.PrivLevel, Authenticated, nil},
+		{http.MethodGet, `tools/write_crconfig/{cdn}/?$`, crconfig.SnapshotOldGUIHandler(d.DB, d.Config), crconfig.SnapshotWriteCapability, Authenticated, nil},
 		// DEPRECATED - use GET /api/1.2/cdns/{cdn}/snapshot
-		{http.MethodGet, `CRConfig-Snapshots/{cdn}/CRConfig.json?$`, crconfig.SnapshotOldGetHandler(d.DB, d.Config), crconfig.PrivLevel, Authenticated, nil},
+		{http.MethodGet, `CRConfig-Snapshots/{cdn}/CRConfig.json?$`, crconfig.SnapshotOldGetHandler(d.DB, d.Config), crconfig.SnapshotReadCapability, Authenticated, nil},
+		// Prometheus metrics. Unauthenticated, so scrapers don't need a login; only counts and latencies are exposed.
+		{http.MethodGet, `^metrics$`, metrics.Handler(), "", NoAuth, nil},
 	}
 
 	return routes, rawRoutes, proxyHandler, nil
@@ -27,10 +27,14 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/apache/incubator-trafficcontrol/lib/go-log"
 	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/auth"
 	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/config"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/dnssec"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/events"
 
 	"github.com/jmoiron/sqlx"
 )
@@ -44,24 +48,24 @@ type Middleware func(handlerFunc http.HandlerFunc) http.HandlerFunc
 // Route ...
 type Route struct {
 	// Order matters! Do not reorder this! Routes() uses positional construction for readability.
-	Version           float64
-	Method            string
-	Path              string
-	Handler           http.HandlerFunc
-	RequiredPrivLevel int
-	Authenticated     bool
-	Middlewares       []Middleware
+	Version            float64
+	Method             string
+	Path               string
+	Handler            http.HandlerFunc
+	RequiredCapability string
+	Authenticated      bool
+	Middlewares        []Middleware
 }
 
 // RawRoute is an HTTP route to be served at the root, rather than under /api/version. Raw Routes should be rare, and almost exclusively converted old Perl routes which have yet to be moved to an API path.
 type RawRoute struct {
 	// Order matters! Do not reorder this! Routes() uses positional construction for readability.
-	Method            string
-	Path              string
-	Handler           http.HandlerFunc
-	RequiredPrivLevel int
-	Authenticated     bool
-	Middlewares       []Middleware
+	Method             string
+	Path               string
+	Handler            http.HandlerFunc
+	RequiredCapability string
+	Authenticated      bool
+	Middlewares        []Middleware
 }
 
 func getDefaultMiddleware() []Middleware {
@@ -71,7 +75,8 @@ func getDefaultMiddleware() []Middleware {
 // ServerData ...
 type ServerData struct {
 	config.Config
-	DB *sqlx.DB
+	DB       *sqlx.DB
+	EventBus *events.Bus
 }
 
 // CompiledRoute ...
@@ -114,25 +119,25 @@ func CreateRouteMap(rs []Route, rawRoutes []RawRoute, authBase AuthBase) map[str
 			}
 			vstr := strconv.FormatFloat(version, 'f', -1, 64)
 			path := RoutePrefix + "/" + vstr + "/" + r.Path
-			middlewares := getRouteMiddleware(r.Middlewares, authBase, r.Authenticated, r.RequiredPrivLevel)
-			m[r.Method] = append(m[r.Method], PathHandler{Path: path, Handler: use(r.Handler, middlewares)})
+			middlewares := getRouteMiddleware(r.Middlewares, authBase, r.Authenticated, r.RequiredCapability)
+			m[r.Method] = append(m[r.Method], PathHandler{Path: path, Handler: wrapMetrics(path, r.Method, use(r.Handler, middlewares))})
 			log.Infof("adding route %v %v\n", r.Method, path)
 		}
 	}
 	for _, r := range rawRoutes {
-		middlewares := getRouteMiddleware(r.Middlewares, authBase, r.Authenticated, r.RequiredPrivLevel)
-		m[r.Method] = append(m[r.Method], PathHandler{Path: r.Path, Handler: use(r.Handler, middlewares)})
+		middlewares := getRouteMiddleware(r.Middlewares, authBase, r.Authenticated, r.RequiredCapability)
+		m[r.Method] = append(m[r.Method], PathHandler{Path: r.Path, Handler: wrapMetrics(r.Path, r.Method, use(r.Handler, middlewares))})
 		log.Infof("adding raw route %v %v\n", r.Method, r.Path)
 	}
 	return m
 }
 
-func getRouteMiddleware(middlewares []Middleware, authBase AuthBase, authenticated bool, privLevel int) []Middleware {
+func getRouteMiddleware(middlewares []Middleware, authBase AuthBase, authenticated bool, capability string) []Middleware {
 	if middlewares == nil {
 		middlewares = getDefaultMiddleware()
 	}
-	if authenticated { // a privLevel of zero is an unauthenticated endpoint.
-		authWrapper := authBase.GetWrapper(privLevel)
+	if authenticated { // unauthenticated endpoints have no capability.
+		authWrapper := authBase.GetWrapper(capability)
 		middlewares = append([]Middleware{authWrapper}, middlewares...)
 	}
 	return middlewares
@@ -195,6 +200,13 @@ func Handler(routes map[string][]CompiledRoute, catchall http.Handler, w http.Re
 
 // RegisterRoutes - parses the routes and registers the handlers with the Go Router
 func RegisterRoutes(d ServerData) error {
+	dispatcher := events.NewDispatcher(d.DB, time.Duration(d.Config.WebhookTimeoutSecs)*time.Second, d.Config.WebhookMaxAttempts, time.Duration(d.Config.WebhookRetrySecs)*time.Second, d.Config.WebhookWorkers)
+	dispatcher.Start()
+	d.EventBus = events.NewBus(d.DB, dispatcher, d.Config.EventRetention())
+	go d.EventBus.Listen(d.Config.DBConnectionString())
+	dnssec.StartRefresh(d.DB, d.Config)
+	startSSLCertificateCheck(d.DB, d.Config)
+
 	routeSlice, rawRoutes, catchall, err := Routes(d)
 	if err != nil {
 		return err
@@ -202,10 +214,25 @@ func RegisterRoutes(d ServerData) error {
 
 	userInfoStmt, err := prepareUserInfoStmt(d.DB)
 	if err != nil {
-		return fmt.Errorf("Error preparing db priv level query: %s", err)
+		return fmt.Errorf("Error preparing db user info query: %s", err)
+	}
+
+	checkTokenStmt, err := d.DB.Preparex(auth.CheckTokenQuery)
+	if err != nil {
+		return fmt.Errorf("Error preparing db token query: %s", err)
+	}
+
+	checkSessionStmt, err := d.DB.Preparex(auth.CheckSessionQuery)
+	if err != nil {
+		return fmt.Errorf("Error preparing db session query: %s", err)
 	}
 
-	authBase := AuthBase{secret: d.Config.Secrets[0], getCurrentUserInfoStmt: userInfoStmt, override: nil} //we know d.Config.Secrets is a slice of at least one or start up would fail.
+	registerDBMetrics(d.DB)
+
+	userCache := auth.NewUserCache(d.Config.UserCacheTTL())
+	go userCache.Listen(d.Config.DBConnectionString())
+
+	authBase := AuthBase{secret: d.Config.Secrets[0], getCurrentUserInfoStmt: userInfoStmt, override: nil, checkTokenStmt: checkTokenStmt, checkSessionStmt: checkSessionStmt, userCache: userCache} //we know d.Config.Secrets is a slice of at least one or start up would fail.
 	routes := CreateRouteMap(routeSlice, rawRoutes, authBase)
 	compiledRoutes := CompileRoutes(routes)
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
@@ -214,8 +241,11 @@ func RegisterRoutes(d ServerData) error {
 	return nil
 }
 
+// userCapabilitiesCol selects the capabilities of the role r, for the user info query.
+const userCapabilitiesCol = "ARRAY(SELECT rc.cap_name FROM role_capability AS rc WHERE rc.role_id = r.id ORDER BY rc.cap_name) AS capabilities"
+
 func prepareUserInfoStmt(db *sqlx.DB) (*sqlx.Stmt, error) {
-	return db.Preparex("SELECT r.priv_level, u.id, u.username, COALESCE(u.tenant_id, -1) AS tenant_id FROM tm_user AS u JOIN role AS r ON u.role = r.id WHERE u.username = $1")
+	return db.Preparex("SELECT r.priv_level, u.id, u.username, COALESCE(u.tenant_id, -1) AS tenant_id, " + userCapabilitiesCol + " FROM tm_user AS u JOIN role AS r ON u.role = r.id WHERE u.username = $1")
 }
 
 func use(h http.HandlerFunc, middlewares []Middleware) http.HandlerFunc {
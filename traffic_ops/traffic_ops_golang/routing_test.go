@@ -28,8 +28,9 @@ import (
 	"bytes"
 	"context"
 	"net/http/httptest"
+	"net/url"
 
-	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/config"
 )
 
 type key int
@@ -42,7 +43,7 @@ func TestCreateRouteMap(t *testing.T) {
 			ctx := context.WithValue(r.Context(), AuthWasCalled, "true")
 			handlerFunc(w, r.WithContext(ctx))
 		}
-	}}
+	}, nil, nil, nil}
 
 	PathOneHandler := func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
@@ -63,9 +64,9 @@ func TestCreateRouteMap(t *testing.T) {
 	}
 
 	routes := []Route{
-		{1.2, http.MethodGet, `path1`, PathOneHandler, auth.PrivLevelReadOnly, true, nil},
-		{1.2, http.MethodGet, `path2`, PathTwoHandler, 0, false, nil},
-		{1.2, http.MethodGet, `path3`, PathThreeHandler, 0, false, []Middleware{}},
+		{1.2, http.MethodGet, `path1`, PathOneHandler, "server-read", true, nil},
+		{1.2, http.MethodGet, `path2`, PathTwoHandler, "", false, nil},
+		{1.2, http.MethodGet, `path3`, PathThreeHandler, "", false, []Middleware{}},
 	}
 
 	rawRoutes := []RawRoute{}
@@ -110,6 +111,26 @@ func TestCreateRouteMap(t *testing.T) {
 	}
 }
 
+// TestRoutesRequireCapability checks that every authenticated route requires a capability, and no unauthenticated route does, because an empty capability is granted to every user.
+func TestRoutesRequireCapability(t *testing.T) {
+	d := ServerData{Config: config.Config{URL: &url.URL{Scheme: "http", Host: "localhost"}}}
+	d.Secrets = []string{"secret"}
+	routes, rawRoutes, _, err := Routes(d)
+	if err != nil {
+		t.Fatalf("getting routes: %v", err)
+	}
+	for _, r := range routes {
+		if r.Authenticated == (r.RequiredCapability == "") {
+			t.Errorf("route %v %v %v: authenticated %v with capability '%v'", r.Version, r.Method, r.Path, r.Authenticated, r.RequiredCapability)
+		}
+	}
+	for _, r := range rawRoutes {
+		if r.Authenticated == (r.RequiredCapability == "") {
+			t.Errorf("raw route %v %v: authenticated %v with capability '%v'", r.Method, r.Path, r.Authenticated, r.RequiredCapability)
+		}
+	}
+}
+
 func getAuthWasCalled(ctx context.Context) string {
 	val := ctx.Value(AuthWasCalled)
 	if val != nil {
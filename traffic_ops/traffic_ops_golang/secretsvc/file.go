@@ -0,0 +1,130 @@
+package secretsvc
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// fileStore is a Store of unencrypted secrets in a local directory, as a file per key in a directory per bucket. It's intended for development and testing, without a RIAK cluster.
+type fileStore struct {
+	dir string
+}
+
+func newFileStore(dir string) (Store, error) {
+	if dir == "" {
+		return nil, errors.New("no secret store directory")
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, errors.New("creating secret store directory: " + err.Error())
+	}
+	return fileStore{dir: dir}, nil
+}
+
+func (s fileStore) Fetch(bucket string, key string) ([]byte, bool, error) {
+	path, err := s.path(bucket, key)
+	if err != nil {
+		return nil, false, err
+	}
+	value, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, errors.New("reading secret: " + err.Error())
+	}
+	return value, true, nil
+}
+
+func (s fileStore) Save(bucket string, key string, value []byte) error {
+	path, err := s.path(bucket, key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return errors.New("creating secret bucket directory: " + err.Error())
+	}
+	if err := ioutil.WriteFile(path, value, 0600); err != nil {
+		return errors.New("writing secret: " + err.Error())
+	}
+	return nil
+}
+
+func (s fileStore) Delete(bucket string, key string) error {
+	path, err := s.path(bucket, key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return errors.New("deleting secret: " + err.Error())
+	}
+	return nil
+}
+
+func (s fileStore) Keys(bucket string) ([]string, error) {
+	if err := validateName(bucket); err != nil {
+		return nil, errors.New("bucket " + err.Error())
+	}
+	files, err := ioutil.ReadDir(filepath.Join(s.dir, bucket))
+	if os.IsNotExist(err) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, errors.New("reading secret bucket directory: " + err.Error())
+	}
+	keys := []string{}
+	for _, file := range files {
+		if !file.IsDir() {
+			keys = append(keys, file.Name())
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func (s fileStore) Close() error {
+	return nil
+}
+
+// path returns the file path of the key in the bucket. Buckets and keys are file names, so they can't contain separators, or reference other directories.
+func (s fileStore) path(bucket string, key string) (string, error) {
+	if err := validateName(bucket); err != nil {
+		return "", errors.New("bucket " + err.Error())
+	}
+	if err := validateName(key); err != nil {
+		return "", errors.New("key " + err.Error())
+	}
+	return filepath.Join(s.dir, bucket, key), nil
+}
+
+func validateName(name string) error {
+	if name == "" {
+		return errors.New("cannot be empty")
+	}
+	if name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return errors.New("'" + name + "' is not a valid name")
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package secretsvc
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func tempFileStore(t *testing.T) (Store, string) {
+	dir, err := ioutil.TempDir("", "secretsvc")
+	if err != nil {
+		t.Fatalf("creating temp dir: %v", err)
+	}
+	store, err := newFileStore(dir)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("newFileStore expected: nil error, actual: %v", err)
+	}
+	return store, dir
+}
+
+func TestFileStore(t *testing.T) {
+	store, dir := tempFileStore(t)
+	defer os.RemoveAll(dir)
+
+	if _, ok, err := store.Fetch("ssl", "ds1-latest"); err != nil || ok {
+		t.Errorf("Fetch of a missing key expected: false nil error, actual: %v %v", ok, err)
+	}
+	if keys, err := store.Keys("ssl"); err != nil || len(keys) != 0 {
+		t.Errorf("Keys of a missing bucket expected: no keys nil error, actual: %v %v", keys, err)
+	}
+
+	if err := store.Save("ssl", "ds1-latest", []byte(`{"key":"value"}`)); err != nil {
+		t.Fatalf("Save expected: nil error, actual: %v", err)
+	}
+	if err := store.Save("ssl", "ds0-latest", []byte(`{}`)); err != nil {
+		t.Fatalf("Save expected: nil error, actual: %v", err)
+	}
+
+	value, ok, err := store.Fetch("ssl", "ds1-latest")
+	if err != nil || !ok || string(value) != `{"key":"value"}` {
+		t.Errorf("Fetch expected: saved value, actual: %s %v %v", value, ok, err)
+	}
+	keys, err := store.Keys("ssl")
+	if expected := []string{"ds0-latest", "ds1-latest"}; err != nil || !reflect.DeepEqual(expected, keys) {
+		t.Errorf("Keys expected: %v, actual: %v %v", expected, keys, err)
+	}
+
+	if err := store.Delete("ssl", "ds1-latest"); err != nil {
+		t.Errorf("Delete expected: nil error, actual: %v", err)
+	}
+	if _, ok, err := store.Fetch("ssl", "ds1-latest"); err != nil || ok {
+		t.Errorf("Fetch of a deleted key expected: false nil error, actual: %v %v", ok, err)
+	}
+	if err := store.Delete("ssl", "ds1-latest"); err != nil {
+		t.Errorf("Delete of a missing key expected: nil error, actual: %v", err)
+	}
+}
+
+func TestFileStoreInvalidNames(t *testing.T) {
+	store, dir := tempFileStore(t)
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{"", ".", "..", "../ssl", "a/b", `a\b`} {
+		if err := store.Save("ssl", name, []byte(`{}`)); err == nil {
+			t.Errorf("Save of key '%s' expected: error, actual: nil error", name)
+		}
+		if _, _, err := store.Fetch(name, "ds1"); err == nil {
+			t.Errorf("Fetch of bucket '%s' expected: error, actual: nil error", name)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package secretsvc
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"io"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// postgresStore is a Store of secrets encrypted with AES-GCM in the secret table of the Traffic Ops database.
+type postgresStore struct {
+	db  *sqlx.DB
+	gcm cipher.AEAD
+}
+
+func newPostgresStore(db *sqlx.DB, key []byte) (Store, error) {
+	if len(key) == 0 {
+		return nil, errors.New("no secret store key, cannot encrypt secrets")
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.New("creating secret store cipher: " + err.Error())
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.New("creating secret store cipher: " + err.Error())
+	}
+	return postgresStore{db: db, gcm: gcm}, nil
+}
+
+func (s postgresStore) Fetch(bucket string, key string) ([]byte, bool, error) {
+	encrypted := []byte{}
+	if err := s.db.QueryRow(`SELECT value FROM secret WHERE bucket = $1 AND key = $2`, bucket, key).Scan(&encrypted); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, false, nil
+		}
+		return nil, false, errors.New("querying secret: " + err.Error())
+	}
+	value, err := s.decrypt(bucket, key, encrypted)
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+func (s postgresStore) Save(bucket string, key string, value []byte) error {
+	encrypted, err := s.encrypt(bucket, key, value)
+	if err != nil {
+		return err
+	}
+	q := `
+INSERT INTO secret (bucket, key, value) VALUES ($1, $2, $3)
+ON CONFLICT (bucket, key) DO UPDATE SET value = EXCLUDED.value, last_updated = now()
+`
+	if _, err := s.db.Exec(q, bucket, key, encrypted); err != nil {
+		return errors.New("saving secret: " + err.Error())
+	}
+	return nil
+}
+
+func (s postgresStore) Delete(bucket string, key string) error {
+	if _, err := s.db.Exec(`DELETE FROM secret WHERE bucket = $1 AND key = $2`, bucket, key); err != nil {
+		return errors.New("deleting secret: " + err.Error())
+	}
+	return nil
+}
+
+func (s postgresStore) Keys(bucket string) ([]string, error) {
+	rows, err := s.db.Query(`SELECT key FROM secret WHERE bucket = $1 ORDER BY key`, bucket)
+	if err != nil {
+		return nil, errors.New("querying secret keys: " + err.Error())
+	}
+	defer rows.Close()
+	keys := []string{}
+	for rows.Next() {
+		key := ""
+		if err := rows.Scan(&key); err != nil {
+			return nil, errors.New("scanning secret keys: " + err.Error())
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// Close does nothing, because the store uses the Traffic Ops database, which is closed by its owner.
+func (s postgresStore) Close() error {
+	return nil
+}
+
+// encrypt returns the nonce followed by the encrypted value. The bucket and key are authenticated with the value, so an encrypted value can't be moved to another key.
+func (s postgresStore) encrypt(bucket string, key string, value []byte) ([]byte, error) {
+	nonce := make([]byte, s.gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, errors.New("creating secret nonce: " + err.Error())
+	}
+	return s.gcm.Seal(nonce, nonce, value, additionalData(bucket, key)), nil
+}
+
+func (s postgresStore) decrypt(bucket string, key string, encrypted []byte) ([]byte, error) {
+	if len(encrypted) < s.gcm.NonceSize() {
+		return nil, errors.New("decrypting secret: value too short")
+	}
+	nonce := encrypted[:s.gcm.NonceSize()]
+	value, err := s.gcm.Open(nil, nonce, encrypted[s.gcm.NonceSize():], additionalData(bucket, key))
+	if err != nil {
+		return nil, errors.New("decrypting secret: " + err.Error())
+	}
+	return value, nil
+}
+
+func additionalData(bucket string, key string) []byte {
+	return []byte(bucket + "/" + key)
+}
@@ -0,0 +1,99 @@
+package secretsvc
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+var testKey = []byte("0123456789abcdef0123456789abcdef")
+
+func TestPostgresStoreEncryption(t *testing.T) {
+	store, err := newPostgresStore(nil, testKey)
+	if err != nil {
+		t.Fatalf("newPostgresStore expected: nil error, actual: %v", err)
+	}
+	ps := store.(postgresStore)
+
+	value := []byte(`{"key":"value"}`)
+	encrypted, err := ps.encrypt("ssl", "ds1-latest", value)
+	if err != nil {
+		t.Fatalf("encrypt expected: nil error, actual: %v", err)
+	}
+	if bytes.Contains(encrypted, value) {
+		t.Errorf("encrypt expected: value to be encrypted, actual: %s", encrypted)
+	}
+
+	decrypted, err := ps.decrypt("ssl", "ds1-latest", encrypted)
+	if err != nil || !bytes.Equal(value, decrypted) {
+		t.Errorf("decrypt expected: %s, actual: %s %v", value, decrypted, err)
+	}
+
+	// the value is bound to its bucket and key
+	if _, err := ps.decrypt("ssl", "ds2-latest", encrypted); err == nil {
+		t.Errorf("decrypt with a different key expected: error, actual: nil error")
+	}
+
+	if _, err := newPostgresStore(nil, nil); err == nil {
+		t.Errorf("newPostgresStore with no key expected: error, actual: nil error")
+	}
+	if _, err := newPostgresStore(nil, []byte("short")); err == nil {
+		t.Errorf("newPostgresStore with an invalid key expected: error, actual: nil error")
+	}
+}
+
+func TestPostgresStoreFetch(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	store, err := newPostgresStore(db, testKey)
+	if err != nil {
+		t.Fatalf("newPostgresStore expected: nil error, actual: %v", err)
+	}
+	value := []byte(`{"key":"value"}`)
+	encrypted, err := store.(postgresStore).encrypt("ssl", "ds1-latest", value)
+	if err != nil {
+		t.Fatalf("encrypt expected: nil error, actual: %v", err)
+	}
+
+	mock.ExpectQuery("SELECT").WithArgs("ssl", "ds1-latest").WillReturnRows(sqlmock.NewRows([]string{"value"}).AddRow(encrypted))
+	mock.ExpectQuery("SELECT").WithArgs("ssl", "ds2-latest").WillReturnRows(sqlmock.NewRows([]string{"value"}))
+
+	actual, ok, err := store.Fetch("ssl", "ds1-latest")
+	if err != nil || !ok || !bytes.Equal(value, actual) {
+		t.Errorf("Fetch expected: %s, actual: %s %v %v", value, actual, ok, err)
+	}
+	if _, ok, err := store.Fetch("ssl", "ds2-latest"); err != nil || ok {
+		t.Errorf("Fetch of a missing key expected: false nil error, actual: %v %v", ok, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
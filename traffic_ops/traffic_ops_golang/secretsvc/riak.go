@@ -0,0 +1,79 @@
+package secretsvc
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/config"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/riaksvc"
+
+	"github.com/basho/riak-go-client"
+	"github.com/jmoiron/sqlx"
+)
+
+// riakStore is a Store of the online RIAK servers.
+type riakStore struct {
+	cluster riaksvc.StorageCluster
+}
+
+// openRiakStore creates and starts a cluster of the online RIAK servers.
+func openRiakStore(db *sqlx.DB, cfg config.Config) (Store, error) {
+	cluster, err := riaksvc.GetRiakCluster(db, cfg.RiakAuthOptions)
+	if err != nil {
+		return nil, err
+	}
+	if err := cluster.Start(); err != nil {
+		return nil, err
+	}
+	return riakStore{cluster: cluster}, nil
+}
+
+func (s riakStore) Fetch(bucket string, key string) ([]byte, bool, error) {
+	ro, err := riaksvc.FetchObjectValues(key, bucket, s.cluster)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(ro) == 0 || ro[0].Value == nil {
+		return nil, false, nil
+	}
+	return ro[0].Value, true, nil
+}
+
+func (s riakStore) Save(bucket string, key string, value []byte) error {
+	obj := &riak.Object{
+		ContentType:     "text/json",
+		Charset:         "utf-8",
+		ContentEncoding: "utf-8",
+		Key:             key,
+		Value:           value,
+	}
+	return riaksvc.SaveObject(obj, bucket, s.cluster)
+}
+
+func (s riakStore) Delete(bucket string, key string) error {
+	return riaksvc.DeleteObject(key, bucket, s.cluster)
+}
+
+func (s riakStore) Keys(bucket string) ([]string, error) {
+	return riaksvc.ListKeys(bucket, s.cluster)
+}
+
+func (s riakStore) Close() error {
+	return s.cluster.Stop()
+}
@@ -0,0 +1,98 @@
+package secretsvc
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/config"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Store is a storage backend for secrets, such as delivery service SSL keys and URI signing keys. Secrets are opaque values, stored by bucket and key.
+type Store interface {
+	// Fetch returns the value of the key in the bucket, and false if it doesn't exist.
+	Fetch(bucket string, key string) ([]byte, bool, error)
+	// Save creates or replaces the value of the key in the bucket.
+	Save(bucket string, key string, value []byte) error
+	// Delete removes the key from the bucket. Deleting a key which doesn't exist isn't an error.
+	Delete(bucket string, key string) error
+	// Keys returns all keys in the bucket.
+	Keys(bucket string) ([]string, error)
+	// Close releases the resources of the store, such as cluster connections. The store must not be used after it's closed.
+	Close() error
+}
+
+// Open returns the secret store of the configured backend. The returned store must be closed by the caller.
+func Open(db *sqlx.DB, cfg config.Config) (Store, error) {
+	return OpenBackend(cfg.SecretStore.Backend, db, cfg)
+}
+
+// OpenBackend returns the secret store of the given backend, using the backend's settings in cfg. This allows opening a backend other than the configured one, for example to migrate secrets between backends.
+func OpenBackend(backend string, db *sqlx.DB, cfg config.Config) (Store, error) {
+	switch backend {
+	case config.SecretStoreRiak:
+		return openRiakStore(db, cfg)
+	case config.SecretStorePostgres:
+		return newPostgresStore(db, cfg.SecretStoreKey)
+	case config.SecretStoreFile:
+		return newFileStore(cfg.SecretStore.Directory)
+	default:
+		return nil, fmt.Errorf("unknown secret store backend '%s'", backend)
+	}
+}
+
+// Enabled returns whether the configured secret store backend can be used. The riak backend requires the riak config, which is optional.
+func Enabled(cfg config.Config) bool {
+	if cfg.SecretStore.Backend == config.SecretStoreRiak {
+		return cfg.RiakEnabled
+	}
+	return true
+}
+
+// ErrUnavailable is returned by handlers when the secret store is not enabled.
+var ErrUnavailable = errors.New("The secret store service is unavailable")
+
+// Copy copies all keys in the given buckets from one store to another, replacing existing keys in the destination. It returns the number of keys copied, which is the number copied before the error if one occurs.
+func Copy(from Store, to Store, buckets []string) (int, error) {
+	copied := 0
+	for _, bucket := range buckets {
+		keys, err := from.Keys(bucket)
+		if err != nil {
+			return copied, errors.New("listing bucket '" + bucket + "' keys: " + err.Error())
+		}
+		for _, key := range keys {
+			value, ok, err := from.Fetch(bucket, key)
+			if err != nil {
+				return copied, errors.New("fetching bucket '" + bucket + "' key '" + key + "': " + err.Error())
+			}
+			if !ok {
+				continue // deleted since listing
+			}
+			if err := to.Save(bucket, key, value); err != nil {
+				return copied, errors.New("saving bucket '" + bucket + "' key '" + key + "': " + err.Error())
+			}
+			copied++
+		}
+	}
+	return copied, nil
+}
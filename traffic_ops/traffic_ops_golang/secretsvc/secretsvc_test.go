@@ -0,0 +1,85 @@
+package secretsvc
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"os"
+	"testing"
+
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/config"
+)
+
+func TestCopy(t *testing.T) {
+	from, fromDir := tempFileStore(t)
+	defer os.RemoveAll(fromDir)
+	to, toDir := tempFileStore(t)
+	defer os.RemoveAll(toDir)
+
+	secrets := map[string]map[string]string{
+		"ssl":              {"ds0-latest": `{"ds":0}`, "ds1-latest": `{"ds":1}`},
+		"cdn_uri_sig_keys": {"ds0": `{"issuer":{}}`},
+		"dnssec":           {"cdn0": `{}`},
+	}
+	for bucket, keys := range secrets {
+		for key, value := range keys {
+			if err := from.Save(bucket, key, []byte(value)); err != nil {
+				t.Fatalf("Save expected: nil error, actual: %v", err)
+			}
+		}
+	}
+	if err := to.Save("ssl", "ds1-latest", []byte(`{"ds":"old"}`)); err != nil {
+		t.Fatalf("Save expected: nil error, actual: %v", err)
+	}
+
+	copied, err := Copy(from, to, []string{"ssl", "cdn_uri_sig_keys", "url_sig_keys"})
+	if err != nil {
+		t.Fatalf("Copy expected: nil error, actual: %v", err)
+	}
+	if copied != 3 {
+		t.Errorf("Copy expected: 3 copied, actual: %v", copied)
+	}
+	for _, bucket := range []string{"ssl", "cdn_uri_sig_keys"} {
+		for key, expected := range secrets[bucket] {
+			if value, ok, err := to.Fetch(bucket, key); err != nil || !ok || string(value) != expected {
+				t.Errorf("Copy expected: bucket %s key %s to be %s, actual: %s %v %v", bucket, key, expected, value, ok, err)
+			}
+		}
+	}
+	if _, ok, _ := to.Fetch("dnssec", "cdn0"); ok {
+		t.Errorf("Copy expected: buckets not given to not be copied, actual: dnssec copied")
+	}
+}
+
+func TestEnabled(t *testing.T) {
+	cfg := config.Config{}
+	cfg.SecretStore.Backend = config.SecretStoreRiak
+	if Enabled(cfg) {
+		t.Errorf("Enabled expected: riak without riak config to be disabled, actual: enabled")
+	}
+	cfg.RiakEnabled = true
+	if !Enabled(cfg) {
+		t.Errorf("Enabled expected: riak with riak config to be enabled, actual: disabled")
+	}
+	cfg = config.Config{}
+	cfg.SecretStore.Backend = config.SecretStoreFile
+	if !Enabled(cfg) {
+		t.Errorf("Enabled expected: file to be enabled, actual: disabled")
+	}
+}
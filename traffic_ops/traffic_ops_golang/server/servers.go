@@ -20,6 +20,7 @@ package server
  */
 
 import (
+	"database/sql"
 	"errors"
 	"fmt"
 	"strconv"
@@ -30,6 +31,7 @@ import (
 	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/api"
 	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/auth"
 	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/dbhelpers"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/tenant"
 	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/tovalidate"
 
 	validation "github.com/go-ozzo/ozzo-validation"
@@ -38,6 +40,9 @@ import (
 	"github.com/lib/pq"
 )
 
+// PasswordsReadCapability is the capability required to see the ILO and XMPP passwords of servers, which are otherwise hidden.
+const PasswordsReadCapability = "server-passwords-read"
+
 //we need a type alias to define functions on
 type TOServer v13.ServerNullable
 
@@ -149,6 +154,27 @@ func (server *TOServer) Validate(db *sqlx.DB) []error {
 	return errs
 }
 
+// IsTenantAuthorized implements the Tenantable interface to ensure the user is authorized on the current tenant of the server, if it exists, and on the requested tenant.
+// Servers with no tenant are shared, and may be modified by any user.
+func (server *TOServer) IsTenantAuthorized(user auth.CurrentUser, db *sqlx.DB) (bool, error) {
+	if server.ID != nil && *server.ID != 0 {
+		var currentTenantID *int
+		if err := db.QueryRow(`SELECT tenant_id FROM server WHERE id = $1`, *server.ID).Scan(&currentTenantID); err != nil && err != sql.ErrNoRows {
+			return false, fmt.Errorf("querying server tenant: %v", err)
+		}
+		if currentTenantID != nil {
+			authorized, err := tenant.IsResourceAuthorizedToUser(*currentTenantID, user, db)
+			if err != nil || !authorized {
+				return authorized, err
+			}
+		}
+	}
+	if server.TenantID == nil {
+		return true, nil
+	}
+	return tenant.IsResourceAuthorizedToUser(*server.TenantID, user, db)
+}
+
 // ChangeLogMessage implements the api.ChangeLogger interface for a custom log message
 func (server TOServer) ChangeLogMessage(action string) (string, error) {
 
@@ -180,9 +206,7 @@ func (server TOServer) ChangeLogMessage(action string) (string, error) {
 func (server *TOServer) Read(db *sqlx.DB, params map[string]string, user auth.CurrentUser) ([]interface{}, []error, tc.ApiErrorType) {
 	returnable := []interface{}{}
 
-	privLevel := user.PrivLevel
-
-	servers, errs, errType := getServers(params, db, privLevel)
+	servers, errs, errType := getServers(params, db, user)
 	if len(errs) > 0 {
 		for _, err := range errs {
 			if err.Error() == `id cannot parse to integer` {
@@ -199,7 +223,7 @@ func (server *TOServer) Read(db *sqlx.DB, params map[string]string, user auth.Cu
 	return returnable, nil, tc.NoError
 }
 
-func getServers(params map[string]string, db *sqlx.DB, privLevel int) ([]tc.ServerNullable, []error, tc.ApiErrorType) {
+func getServers(params map[string]string, db *sqlx.DB, user auth.CurrentUser) ([]tc.ServerNullable, []error, tc.ApiErrorType) {
 	var rows *sqlx.Rows
 	var err error
 
@@ -214,6 +238,7 @@ func getServers(params map[string]string, db *sqlx.DB, privLevel int) ([]tc.Serv
 		"profileId":    dbhelpers.WhereColumnInfo{"s.profile", api.IsInt},
 		"status":       dbhelpers.WhereColumnInfo{"st.name", nil},
 		"type":         dbhelpers.WhereColumnInfo{"t.name", nil},
+		"lastUpdated":  dbhelpers.WhereColumnInfo{"s.last_updated", nil},
 	}
 
 	where, orderBy, queryValues, errs := dbhelpers.BuildWhereAndOrderBy(params, queryParamsToSQLCols)
@@ -221,6 +246,8 @@ func getServers(params map[string]string, db *sqlx.DB, privLevel int) ([]tc.Serv
 		return nil, errs, tc.DataConflictError
 	}
 
+	where = tenant.AddTenancyCheck(where, queryValues, "s.tenant_id", user)
+
 	query := selectQuery() + where + orderBy
 	log.Debugln("Query is ", query)
 
@@ -239,7 +266,7 @@ func getServers(params map[string]string, db *sqlx.DB, privLevel int) ([]tc.Serv
 		if err = rows.StructScan(&s); err != nil {
 			return nil, []error{fmt.Errorf("getting servers: %v", err)}, tc.SystemError
 		}
-		if privLevel < auth.PrivLevelAdmin {
+		if !user.HasCapability(PasswordsReadCapability) {
 			s.ILOPassword = &HiddenField
 			s.XMPPPasswd = &HiddenField
 		}
@@ -293,6 +320,7 @@ s.router_port_name,
 st.name as status,
 s.status as status_id,
 s.tcp_port,
+s.tenant_id,
 t.name as server_type,
 s.type as server_type_id,
 s.upd_pending as upd_pending,
@@ -333,7 +361,20 @@ func (server *TOServer) Update(db *sqlx.DB, user auth.CurrentUser) (error, tc.Ap
 		log.Error.Printf("could not begin transaction: %v", err)
 		return tc.DBError, tc.SystemError
 	}
+	if err, errType := server.UpdateTx(tx, user); err != nil {
+		return err, errType
+	}
+	err = tx.Commit()
+	if err != nil {
+		log.Errorln("Could not commit transaction: ", err)
+		return tc.DBError, tc.SystemError
+	}
+	rollbackTransaction = false
+	return nil, tc.NoError
+}
 
+// UpdateTx updates the server in the given transaction, without committing it, so many can be updated atomically by api.BulkUpdateHandler.
+func (server *TOServer) UpdateTx(tx *sqlx.Tx, user auth.CurrentUser) (error, tc.ApiErrorType) {
 	log.Debugf("about to run exec query: %s with server: %++v", updateQuery(), server)
 	resultRows, err := tx.NamedQuery(updateQuery(), server)
 	if err != nil {
@@ -351,7 +392,6 @@ func (server *TOServer) Update(db *sqlx.DB, user auth.CurrentUser) (error, tc.Ap
 	defer resultRows.Close()
 
 	var lastUpdated tc.TimeNoMod
-	var id int
 	rowsAffected := 0
 	for resultRows.Next() {
 		rowsAffected++
@@ -370,14 +410,7 @@ func (server *TOServer) Update(db *sqlx.DB, user auth.CurrentUser) (error, tc.Ap
 		log.Errorln(err)
 		return tc.DBError, tc.SystemError
 	}
-	server.SetID(id)
 	server.LastUpdated = &lastUpdated
-	err = tx.Commit()
-	if err != nil {
-		log.Errorln("Could not commit transaction: ", err)
-		return tc.DBError, tc.SystemError
-	}
-	rollbackTransaction = false
 	return nil, tc.NoError
 }
 
@@ -412,6 +445,7 @@ router_host_name=:router_host_name,
 router_port_name=:router_port_name,
 status=:status_id,
 tcp_port=:tcp_port,
+tenant_id=:tenant_id,
 type=:server_type_id,
 upd_pending=:upd_pending
 WHERE id=:id RETURNING last_updated`
@@ -442,6 +476,20 @@ func (server *TOServer) Create(db *sqlx.DB, user auth.CurrentUser) (error, tc.Ap
 		log.Error.Printf("could not begin transaction: %v", err)
 		return tc.DBError, tc.SystemError
 	}
+	if err, errType := server.CreateTx(tx, user); err != nil {
+		return err, errType
+	}
+	err = tx.Commit()
+	if err != nil {
+		log.Errorln("Could not commit transaction: ", err)
+		return tc.DBError, tc.SystemError
+	}
+	rollbackTransaction = false
+	return nil, tc.NoError
+}
+
+// CreateTx creates the server in the given transaction, without committing it, so many can be created atomically by api.BulkCreateHandler.
+func (server *TOServer) CreateTx(tx *sqlx.Tx, user auth.CurrentUser) (error, tc.ApiErrorType) {
 	if server.XMPPID == nil || *server.XMPPID == "" {
 		server.XMPPID = server.HostName
 	}
@@ -482,12 +530,6 @@ func (server *TOServer) Create(db *sqlx.DB, user auth.CurrentUser) (error, tc.Ap
 	}
 	server.SetKeys(map[string]interface{}{"id": id})
 	server.LastUpdated = &lastUpdated
-	err = tx.Commit()
-	if err != nil {
-		log.Errorln("Could not commit transaction: ", err)
-		return tc.DBError, tc.SystemError
-	}
-	rollbackTransaction = false
 	return nil, tc.NoError
 }
 
@@ -521,6 +563,7 @@ router_host_name,
 router_port_name,
 status,
 tcp_port,
+tenant_id,
 type,
 upd_pending) VALUES (
 :cachegroup_id,
@@ -551,6 +594,7 @@ upd_pending) VALUES (
 :router_port_name,
 :status_id,
 :tcp_port,
+:tenant_id,
 :server_type_id,
 :upd_pending) RETURNING id,last_updated`
 	return query
@@ -575,6 +619,20 @@ func (server *TOServer) Delete(db *sqlx.DB, user auth.CurrentUser) (error, tc.Ap
 		log.Error.Printf("could not begin transaction: %v", err)
 		return tc.DBError, tc.SystemError
 	}
+	if err, errType := server.DeleteTx(tx, user); err != nil {
+		return err, errType
+	}
+	err = tx.Commit()
+	if err != nil {
+		log.Errorln("Could not commit transaction: ", err)
+		return tc.DBError, tc.SystemError
+	}
+	rollbackTransaction = false
+	return nil, tc.NoError
+}
+
+// DeleteTx deletes the server in the given transaction, without committing it, so many can be deleted atomically by api.BulkDeleteHandler.
+func (server *TOServer) DeleteTx(tx *sqlx.Tx, user auth.CurrentUser) (error, tc.ApiErrorType) {
 	log.Debugf("about to run exec query: %s with server: %++v", deleteServerQuery(), server)
 	result, err := tx.NamedExec(deleteServerQuery(), server)
 	if err != nil {
@@ -592,12 +650,6 @@ func (server *TOServer) Delete(db *sqlx.DB, user auth.CurrentUser) (error, tc.Ap
 			return fmt.Errorf("this create affected too many rows: %d", rowsAffected), tc.SystemError
 		}
 	}
-	err = tx.Commit()
-	if err != nil {
-		log.Errorln("Could not commit transaction: ", err)
-		return tc.DBError, tc.SystemError
-	}
-	rollbackTransaction = false
 	return nil, tc.NoError
 }
 
@@ -0,0 +1,164 @@
+package server
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-log"
+	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/tenant"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// GetTypeCountsHandler serves the number of servers of each type, of the servers the user's tenant may see.
+func GetTypeCountsHandler(db *sqlx.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		handleErrs := tc.GetHandleErrorsFunc(w, r)
+		user, err := auth.GetCurrentUser(r.Context())
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, err)
+			return
+		}
+		counts, err := getTypeCounts(db, *user)
+		if err != nil {
+			log.Errorln("getting server type counts: " + err.Error())
+			handleErrs(http.StatusInternalServerError, tc.DBError)
+			return
+		}
+		respBts, err := json.Marshal(tc.ServerTypeCountsResponse{Response: counts})
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, err)
+			return
+		}
+		w.Header().Set(tc.ContentType, tc.ApplicationJson)
+		w.Write(respBts)
+	}
+}
+
+// GetStatusCountsHandler serves the number of servers with each status, of the servers the user's tenant may see. The type query parameter is a regular expression, which filters the servers by type name.
+func GetStatusCountsHandler(db *sqlx.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		handleErrs := tc.GetHandleErrorsFunc(w, r)
+		user, err := auth.GetCurrentUser(r.Context())
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, err)
+			return
+		}
+		params, err := api.GetCombinedParams(r)
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, err)
+			return
+		}
+		counts, err := getStatusCounts(db, params["type"], *user)
+		if err != nil {
+			log.Errorln("getting server status counts: " + err.Error())
+			handleErrs(http.StatusInternalServerError, tc.DBError)
+			return
+		}
+		respBts, err := json.Marshal(tc.ServerStatusCountsResponse{Response: counts})
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, err)
+			return
+		}
+		w.Header().Set(tc.ContentType, tc.ApplicationJson)
+		w.Write(respBts)
+	}
+}
+
+func getTypeCounts(db *sqlx.DB, user auth.CurrentUser) ([]tc.ServerTypeCount, error) {
+	q := `
+SELECT t.name, COUNT(s.id)
+FROM server AS s
+JOIN type AS t ON s.type = t.id
+WHERE ` + tenant.TenancyCheckClause("s.tenant_id") + `
+GROUP BY t.name
+ORDER BY t.name
+`
+	rows, err := db.NamedQuery(q, map[string]interface{}{"user_tenant_id": user.TenantID})
+	if err != nil {
+		return nil, errors.New("querying server type counts: " + err.Error())
+	}
+	defer rows.Close()
+	counts := []tc.ServerTypeCount{}
+	for rows.Next() {
+		count := tc.ServerTypeCount{}
+		if err := rows.Scan(&count.Type, &count.Count); err != nil {
+			return nil, errors.New("scanning server type counts: " + err.Error())
+		}
+		counts = append(counts, count)
+	}
+	return counts, nil
+}
+
+// getStatusCounts returns the number of servers with each status, of servers whose type matches the given regular expression, if it isn't empty.
+// Statuses with no servers are omitted, unless there are no servers at all, in which case every status is returned with a count of 0.
+func getStatusCounts(db *sqlx.DB, typeRegex string, user auth.CurrentUser) (map[string]int, error) {
+	q := `
+SELECT st.name, COUNT(s.id)
+FROM server AS s
+JOIN status AS st ON s.status = st.id
+JOIN type AS t ON s.type = t.id
+WHERE (:type_regex = '' OR t.name ~ :type_regex) AND ` + tenant.TenancyCheckClause("s.tenant_id") + `
+GROUP BY st.name
+`
+	rows, err := db.NamedQuery(q, map[string]interface{}{"type_regex": typeRegex, "user_tenant_id": user.TenantID})
+	if err != nil {
+		return nil, errors.New("querying server status counts: " + err.Error())
+	}
+	defer rows.Close()
+	counts := map[string]int{}
+	for rows.Next() {
+		status, count := "", 0
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, errors.New("scanning server status counts: " + err.Error())
+		}
+		counts[status] = count
+	}
+	if len(counts) > 0 {
+		return counts, nil
+	}
+
+	noServers := false
+	if err := db.QueryRow(`SELECT NOT EXISTS(SELECT 1 FROM server)`).Scan(&noServers); err != nil {
+		return nil, errors.New("querying whether servers exist: " + err.Error())
+	}
+	if !noServers {
+		return counts, nil
+	}
+	statusRows, err := db.Query(`SELECT name FROM status`)
+	if err != nil {
+		return nil, errors.New("querying statuses: " + err.Error())
+	}
+	defer statusRows.Close()
+	for statusRows.Next() {
+		status := ""
+		if err := statusRows.Scan(&status); err != nil {
+			return nil, errors.New("scanning statuses: " + err.Error())
+		}
+		counts[status] = 0
+	}
+	return counts, nil
+}
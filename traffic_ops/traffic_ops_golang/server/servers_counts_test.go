@@ -0,0 +1,93 @@
+package server
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"testing"
+
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+	"github.com/jmoiron/sqlx"
+
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestGetTypeCounts(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"name", "count"}).AddRow("EDGE", 3).AddRow("MID", 1)
+	mock.ExpectQuery("SELECT").WithArgs(2).WillReturnRows(rows)
+
+	counts, err := getTypeCounts(db, auth.CurrentUser{TenantID: 2})
+	if err != nil {
+		t.Fatalf("getTypeCounts expected: no error, actual: %v", err)
+	}
+	if len(counts) != 2 || counts[0].Type != "EDGE" || counts[0].Count != 3 || counts[1].Type != "MID" || counts[1].Count != 1 {
+		t.Errorf("getTypeCounts expected: EDGE 3, MID 1, actual: %+v", counts)
+	}
+}
+
+func TestGetStatusCounts(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"name", "count"}).AddRow("ONLINE", 2).AddRow("REPORTED", 5)
+	mock.ExpectQuery("SELECT").WithArgs("^EDGE", "^EDGE", 2).WillReturnRows(rows)
+
+	counts, err := getStatusCounts(db, "^EDGE", auth.CurrentUser{TenantID: 2})
+	if err != nil {
+		t.Fatalf("getStatusCounts expected: no error, actual: %v", err)
+	}
+	if len(counts) != 2 || counts["ONLINE"] != 2 || counts["REPORTED"] != 5 {
+		t.Errorf("getStatusCounts expected: ONLINE 2, REPORTED 5, actual: %+v", counts)
+	}
+}
+
+func TestGetStatusCountsNoServers(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT").WithArgs("", "", 2).WillReturnRows(sqlmock.NewRows([]string{"name", "count"}))
+	mock.ExpectQuery("SELECT NOT EXISTS").WillReturnRows(sqlmock.NewRows([]string{"not_exists"}).AddRow(true))
+	mock.ExpectQuery("SELECT name FROM status").WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("ONLINE").AddRow("OFFLINE"))
+
+	counts, err := getStatusCounts(db, "", auth.CurrentUser{TenantID: 2})
+	if err != nil {
+		t.Fatalf("getStatusCounts expected: no error, actual: %v", err)
+	}
+	if len(counts) != 2 || counts["ONLINE"] != 0 || counts["OFFLINE"] != 0 {
+		t.Errorf("getStatusCounts expected: every status with 0, actual: %+v", counts)
+	}
+}
@@ -0,0 +1,335 @@
+package server
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-log"
+	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/events"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/tenant"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// QueueUpdateHandler queues or dequeues updates or revalidation on the server with the path id.
+func QueueUpdateHandler(db *sqlx.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		handleErrs := tc.GetHandleErrorsFunc(w, r)
+		user, id, req, ok := getQueueUpdateParams(w, r, handleErrs)
+		if !ok {
+			return
+		}
+		req.Level = "" // a single server has no level to limit
+
+		hostName := ""
+		tenantID := sql.NullInt64{}
+		if err := db.QueryRow(`SELECT host_name, tenant_id FROM server WHERE id = $1`, id).Scan(&hostName, &tenantID); err != nil {
+			if err == sql.ErrNoRows {
+				handleErrs(http.StatusNotFound, errors.New("no server with that id found"))
+				return
+			}
+			log.Errorln("querying server: " + err.Error())
+			handleErrs(http.StatusInternalServerError, tc.DBError)
+			return
+		}
+		if tenantID.Valid {
+			authorized, err := tenant.IsResourceAuthorizedToUser(int(tenantID.Int64), *user, db)
+			if err != nil {
+				log.Errorln("checking server tenancy: " + err.Error())
+				handleErrs(http.StatusInternalServerError, tc.DBError)
+				return
+			}
+			if !authorized {
+				handleErrs(http.StatusForbidden, errors.New("not authorized on this tenant"))
+				return
+			}
+		}
+
+		names, err := queueUpdates(db, "s.id = :server_id", map[string]interface{}{"server_id": id}, req, *user)
+		if err != nil {
+			log.Errorln("queueing updates on server '" + hostName + "': " + err.Error())
+			handleErrs(http.StatusInternalServerError, tc.DBError)
+			return
+		}
+		msg := queueUpdateMessage(req, hostName)
+		api.CreateChangeLogRaw(api.ApiChange, msg, *user, db)
+		publishQueueUpdate(req, msg, names, *user, db)
+
+		resp := tc.ServerQueueUpdateResponse{
+			Response: tc.ServerQueueUpdate{ServerID: id, Action: req.Action, Reval: req.Reval},
+			Alerts:   tc.CreateAlerts(tc.SuccessLevel, msg),
+		}
+		respBts, err := json.Marshal(resp)
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, err)
+			return
+		}
+		w.Header().Set(tc.ContentType, tc.ApplicationJson)
+		w.Write(respBts)
+	}
+}
+
+// QueueCachegroupUpdatesHandler queues or dequeues updates or revalidation on the servers in the cachegroup with the path id, in the CDN given in the request. Servers the user's tenant may not modify are skipped.
+func QueueCachegroupUpdatesHandler(db *sqlx.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		handleErrs := tc.GetHandleErrorsFunc(w, r)
+		user, id, req, ok := getQueueUpdateParams(w, r, handleErrs)
+		if !ok {
+			return
+		}
+
+		cgName := ""
+		if err := db.QueryRow(`SELECT name FROM cachegroup WHERE id = $1`, id).Scan(&cgName); err != nil {
+			if err == sql.ErrNoRows {
+				handleErrs(http.StatusNotFound, errors.New("no cachegroup with that id found"))
+				return
+			}
+			log.Errorln("querying cachegroup: " + err.Error())
+			handleErrs(http.StatusInternalServerError, tc.DBError)
+			return
+		}
+
+		if req.CDN == nil && req.CDNID == nil {
+			handleErrs(http.StatusBadRequest, errors.New("cdn or cdnId is required"))
+			return
+		}
+		cdnID := 0
+		cdnName := ""
+		var cdnRow *sql.Row
+		if req.CDNID != nil {
+			cdnRow = db.QueryRow(`SELECT id, name FROM cdn WHERE id = $1`, *req.CDNID)
+		} else {
+			cdnRow = db.QueryRow(`SELECT id, name FROM cdn WHERE name = $1`, *req.CDN)
+		}
+		if err := cdnRow.Scan(&cdnID, &cdnName); err != nil {
+			if err == sql.ErrNoRows {
+				handleErrs(http.StatusBadRequest, errors.New("cdn does not exist"))
+				return
+			}
+			log.Errorln("querying cdn: " + err.Error())
+			handleErrs(http.StatusInternalServerError, tc.DBError)
+			return
+		}
+
+		names, err := queueUpdates(db, "s.cachegroup = :cachegroup_id AND s.cdn_id = :cdn_id", map[string]interface{}{"cachegroup_id": id, "cdn_id": cdnID}, req, *user)
+		if err != nil {
+			log.Errorln("queueing updates on cachegroup '" + cgName + "': " + err.Error())
+			handleErrs(http.StatusInternalServerError, tc.DBError)
+			return
+		}
+		msg := queueUpdateMessage(req, cgName+" cache group")
+		api.CreateChangeLogRaw(api.ApiChange, msg, *user, db)
+		publishQueueUpdate(req, msg, names, *user, db)
+
+		resp := tc.CachegroupQueueUpdatesResponse{
+			Response: tc.CachegroupQueueUpdates{
+				CachegroupID:   id,
+				CachegroupName: cgName,
+				CDN:            cdnName,
+				Action:         req.Action,
+				Reval:          req.Reval,
+				Level:          req.Level,
+				ServerNames:    names,
+			},
+			Alerts: tc.CreateAlerts(tc.SuccessLevel, msg),
+		}
+		respBts, err := json.Marshal(resp)
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, err)
+			return
+		}
+		w.Header().Set(tc.ContentType, tc.ApplicationJson)
+		w.Write(respBts)
+	}
+}
+
+// QueueCDNUpdatesHandler queues or dequeues updates or revalidation on the servers in the CDN with the path id. Servers the user's tenant may not modify are skipped.
+func QueueCDNUpdatesHandler(db *sqlx.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		handleErrs := tc.GetHandleErrorsFunc(w, r)
+		user, id, req, ok := getQueueUpdateParams(w, r, handleErrs)
+		if !ok {
+			return
+		}
+
+		cdnName := ""
+		if err := db.QueryRow(`SELECT name FROM cdn WHERE id = $1`, id).Scan(&cdnName); err != nil {
+			if err == sql.ErrNoRows {
+				handleErrs(http.StatusNotFound, errors.New("no cdn with that id found"))
+				return
+			}
+			log.Errorln("querying cdn: " + err.Error())
+			handleErrs(http.StatusInternalServerError, tc.DBError)
+			return
+		}
+
+		names, err := queueUpdates(db, "s.cdn_id = :cdn_id", map[string]interface{}{"cdn_id": id}, req, *user)
+		if err != nil {
+			log.Errorln("queueing updates on cdn '" + cdnName + "': " + err.Error())
+			handleErrs(http.StatusInternalServerError, tc.DBError)
+			return
+		}
+		msg := queueUpdateMessage(req, cdnName)
+		api.CreateChangeLogRaw(api.ApiChange, msg, *user, db)
+		publishQueueUpdate(req, msg, names, *user, db)
+
+		resp := tc.CDNQueueUpdatesResponse{
+			Response: tc.CDNQueueUpdates{
+				CDNID:       id,
+				Action:      req.Action,
+				Reval:       req.Reval,
+				Level:       req.Level,
+				ServerNames: names,
+			},
+			Alerts: tc.CreateAlerts(tc.SuccessLevel, msg),
+		}
+		respBts, err := json.Marshal(resp)
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, err)
+			return
+		}
+		w.Header().Set(tc.ContentType, tc.ApplicationJson)
+		w.Write(respBts)
+	}
+}
+
+// getQueueUpdateParams returns the current user, the path id, and the validated queue update request. If any are invalid, an error is written and false is returned.
+func getQueueUpdateParams(w http.ResponseWriter, r *http.Request, handleErrs func(status int, errs ...error)) (*auth.CurrentUser, int, tc.ServerQueueUpdateRequest, bool) {
+	user, err := auth.GetCurrentUser(r.Context())
+	if err != nil {
+		handleErrs(http.StatusInternalServerError, err)
+		return nil, 0, tc.ServerQueueUpdateRequest{}, false
+	}
+	params, err := api.GetPathParams(r.Context())
+	if err != nil {
+		handleErrs(http.StatusInternalServerError, err)
+		return nil, 0, tc.ServerQueueUpdateRequest{}, false
+	}
+	id, err := strconv.Atoi(params["id"])
+	if err != nil {
+		handleErrs(http.StatusBadRequest, errors.New("id must be an integer"))
+		return nil, 0, tc.ServerQueueUpdateRequest{}, false
+	}
+	req := tc.ServerQueueUpdateRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handleErrs(http.StatusBadRequest, errors.New("malformed JSON: "+err.Error()))
+		return nil, 0, tc.ServerQueueUpdateRequest{}, false
+	}
+	if err := validateQueueUpdateRequest(req); err != nil {
+		handleErrs(http.StatusBadRequest, err)
+		return nil, 0, tc.ServerQueueUpdateRequest{}, false
+	}
+	return user, id, req, true
+}
+
+func validateQueueUpdateRequest(req tc.ServerQueueUpdateRequest) error {
+	if req.Action != tc.QueueUpdateActionQueue && req.Action != tc.QueueUpdateActionDequeue {
+		return errors.New("action must be '" + tc.QueueUpdateActionQueue + "' or '" + tc.QueueUpdateActionDequeue + "'")
+	}
+	if req.Level != "" && req.Level != tc.QueueUpdateLevelEdge && req.Level != tc.QueueUpdateLevelMid {
+		return errors.New("level must be '" + tc.QueueUpdateLevelEdge + "' or '" + tc.QueueUpdateLevelMid + "', or omitted for all servers")
+	}
+	return nil
+}
+
+// queueUpdates sets or clears the update or revalidation pending flag of the servers matching the given where clause, whose named parameters are in queryValues, and returns their sorted host names.
+// Only servers whose type begins with the request level, and which the user's tenant may modify, are changed.
+func queueUpdates(db namedQueryer, where string, queryValues map[string]interface{}, req tc.ServerQueueUpdateRequest, user auth.CurrentUser) ([]string, error) {
+	col := "upd_pending"
+	if req.Reval {
+		col = "reval_pending"
+	}
+	queryValues["pending"] = req.Action == tc.QueueUpdateActionQueue
+	queryValues["type_prefix"] = req.Level + "%"
+	queryValues["user_tenant_id"] = user.TenantID
+	q := `
+UPDATE server AS s SET ` + col + ` = :pending
+FROM type AS t
+WHERE s.type = t.id AND ` + where + ` AND t.name LIKE :type_prefix AND ` + tenant.TenancyCheckClause("s.tenant_id") + `
+RETURNING s.host_name
+`
+	rows, err := db.NamedQuery(q, queryValues)
+	if err != nil {
+		return nil, errors.New("updating servers: " + err.Error())
+	}
+	defer rows.Close()
+	names := []string{}
+	for rows.Next() {
+		name := ""
+		if err := rows.Scan(&name); err != nil {
+			return nil, errors.New("scanning updated servers: " + err.Error())
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// namedQueryer is a *sqlx.DB or *sqlx.Tx.
+type namedQueryer interface {
+	NamedQuery(query string, arg interface{}) (*sqlx.Rows, error)
+}
+
+// QueueDeliveryServiceUpdates queues updates, in the given transaction, on the servers whose configuration depends on a delivery service: the given servers assigned to it, which must be read before a delivery service is deleted, and the mids of its CDN. Servers the user's tenant may not modify are skipped. The change is logged and published with the transaction, and the sorted host names of the servers are returned.
+func QueueDeliveryServiceUpdates(tx *sqlx.Tx, xmlID string, cdnID int, serverIDs []int, user auth.CurrentUser) ([]string, error) {
+	req := tc.ServerQueueUpdateRequest{Action: tc.QueueUpdateActionQueue}
+	names, err := queueUpdates(tx, "(s.id = ANY(:server_ids) OR (s.cdn_id = :cdn_id AND t.name LIKE 'MID%'))", map[string]interface{}{"server_ids": pq.Array(serverIDs), "cdn_id": cdnID}, req, user)
+	if err != nil {
+		return nil, err
+	}
+	msg := queueUpdateMessage(req, "delivery service "+xmlID)
+	if err := api.CreateChangeLogRawTx(api.ApiChange, msg, user, tx); err != nil {
+		return nil, errors.New("writing change log: " + err.Error())
+	}
+	if err := events.Publish(tc.EventTypeQueueUpdate, msg, tc.QueueUpdateEventData{Action: req.Action, Servers: names}, user, tx); err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+// publishQueueUpdate publishes the event of the given request on the servers with the given names. The servers were already changed, so errors are logged rather than failing the request.
+func publishQueueUpdate(req tc.ServerQueueUpdateRequest, msg string, names []string, user auth.CurrentUser, db *sqlx.DB) {
+	data := tc.QueueUpdateEventData{Action: req.Action, Reval: req.Reval, Level: req.Level, Servers: names}
+	if err := events.Publish(tc.EventTypeQueueUpdate, msg, data, user, db); err != nil {
+		log.Errorln("publishing queue update event: " + err.Error())
+	}
+}
+
+// queueUpdateMessage returns the change log message of the given request on the given servers, like the Perl Traffic Ops, e.g. "Server updates queued for mycdn".
+func queueUpdateMessage(req tc.ServerQueueUpdateRequest, target string) string {
+	what := "updates"
+	if req.Reval {
+		what = "revalidations"
+	}
+	if req.Level != "" {
+		target = req.Level + " servers of " + target
+	}
+	return "Server " + what + " " + req.Action + "d for " + target
+}
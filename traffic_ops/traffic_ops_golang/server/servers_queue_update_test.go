@@ -0,0 +1,155 @@
+package server
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+	"github.com/jmoiron/sqlx"
+
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestValidateQueueUpdateRequest(t *testing.T) {
+	tests := []struct {
+		req   tc.ServerQueueUpdateRequest
+		valid bool
+	}{
+		{tc.ServerQueueUpdateRequest{Action: "queue"}, true},
+		{tc.ServerQueueUpdateRequest{Action: "dequeue", Reval: true, Level: "MID"}, true},
+		{tc.ServerQueueUpdateRequest{Action: "queue", Level: "EDGE"}, true},
+		{tc.ServerQueueUpdateRequest{}, false},
+		{tc.ServerQueueUpdateRequest{Action: "enqueue"}, false},
+		{tc.ServerQueueUpdateRequest{Action: "queue", Level: "ORG"}, false},
+	}
+	for _, test := range tests {
+		if err := validateQueueUpdateRequest(test.req); (err == nil) != test.valid {
+			t.Errorf("validateQueueUpdateRequest(%+v) expected valid: %v, actual: %v", test.req, test.valid, err)
+		}
+	}
+}
+
+func TestQueueUpdateMessage(t *testing.T) {
+	tests := []struct {
+		req      tc.ServerQueueUpdateRequest
+		expected string
+	}{
+		{tc.ServerQueueUpdateRequest{Action: "queue"}, "Server updates queued for mycdn"},
+		{tc.ServerQueueUpdateRequest{Action: "dequeue", Reval: true}, "Server revalidations dequeued for mycdn"},
+		{tc.ServerQueueUpdateRequest{Action: "queue", Level: "MID"}, "Server updates queued for MID servers of mycdn"},
+	}
+	for _, test := range tests {
+		if actual := queueUpdateMessage(test.req, "mycdn"); actual != test.expected {
+			t.Errorf("queueUpdateMessage expected: %q, actual: %q", test.expected, actual)
+		}
+	}
+}
+
+func TestQueueUpdates(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"host_name"}).AddRow("edge2").AddRow("edge1")
+	mock.ExpectQuery("UPDATE server AS s SET reval_pending").WithArgs(true, 2, "EDGE%", 3).WillReturnRows(rows)
+
+	req := tc.ServerQueueUpdateRequest{Action: tc.QueueUpdateActionQueue, Reval: true, Level: tc.QueueUpdateLevelEdge}
+	names, err := queueUpdates(db, "s.cdn_id = :cdn_id", map[string]interface{}{"cdn_id": 2}, req, auth.CurrentUser{TenantID: 3})
+	if err != nil {
+		t.Fatalf("queueUpdates expected: no error, actual: %v", err)
+	}
+	if expected := []string{"edge1", "edge2"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("queueUpdates expected: %v, actual: %v", expected, names)
+	}
+}
+
+func TestQueueDeliveryServiceUpdates(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	mock.ExpectBegin()
+	rows := sqlmock.NewRows([]string{"host_name"}).AddRow("mid1").AddRow("edge1")
+	mock.ExpectQuery("UPDATE server AS s SET upd_pending").WithArgs(true, sqlmock.AnyArg(), 2, "%", 3).WillReturnRows(rows)
+	mock.ExpectExec("INSERT INTO log").WithArgs("APICHANGE", "Server updates queued for delivery service ds1", 4).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO event").WithArgs(tc.EventTypeQueueUpdate, "Server updates queued for delivery service ds1", sqlmock.AnyArg(), 4).WillReturnResult(sqlmock.NewResult(1, 1))
+
+	tx, err := db.Beginx()
+	if err != nil {
+		t.Fatalf("beginning transaction: %v", err)
+	}
+	names, err := QueueDeliveryServiceUpdates(tx, "ds1", 2, []int{5}, auth.CurrentUser{ID: 4, TenantID: 3})
+	if err != nil {
+		t.Fatalf("QueueDeliveryServiceUpdates expected: no error, actual: %v", err)
+	}
+	if expected := []string{"edge1", "mid1"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("QueueDeliveryServiceUpdates expected: %v, actual: %v", expected, names)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetCDNServerUpdateStatus(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT EXISTS").WithArgs("mycdn").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	rows := sqlmock.NewRows([]string{"id", "host_name", "type", "server_reval_pending", "use_reval_pending", "upd_pending", "status", "parent_upd_pending", "parent_reval_pending"})
+	rows.AddRow(1, "edge1", "EDGE", false, true, false, "REPORTED", true, false)
+	rows.AddRow(2, "mid1", "MID", false, true, true, "REPORTED", false, false)
+	mock.ExpectQuery("SELECT").WithArgs("mycdn").WillReturnRows(rows)
+
+	statuses, ok, err := getCDNServerUpdateStatus("mycdn", db)
+	if err != nil {
+		t.Fatalf("getCDNServerUpdateStatus expected: no error, actual: %v", err)
+	}
+	if !ok {
+		t.Fatalf("getCDNServerUpdateStatus expected: cdn to exist, actual: not found")
+	}
+	expected := []tc.ServerUpdateStatus{
+		{HostName: "edge1", UseRevalPending: true, HostId: 1, Status: "REPORTED", ParentPending: true},
+		{HostName: "mid1", UpdatePending: true, UseRevalPending: true, HostId: 2, Status: "REPORTED"},
+	}
+	if !reflect.DeepEqual(statuses, expected) {
+		t.Errorf("getCDNServerUpdateStatus expected: %+v, actual: %+v", expected, statuses)
+	}
+
+	mock.ExpectQuery("SELECT EXISTS").WithArgs("nocdn").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	if _, ok, err := getCDNServerUpdateStatus("nocdn", db); err != nil || ok {
+		t.Errorf("getCDNServerUpdateStatus of a nonexistent cdn expected: not found, actual: ok %v err %v", ok, err)
+	}
+}
@@ -150,6 +150,7 @@ func TestGetServersByCachegroup(t *testing.T) {
 			ts.Status,
 			ts.StatusID,
 			ts.TCPPort,
+			ts.TenantID,
 			ts.Type,
 			ts.TypeID,
 			ts.UpdPending,
@@ -160,7 +161,7 @@ func TestGetServersByCachegroup(t *testing.T) {
 	mock.ExpectQuery("SELECT").WillReturnRows(rows)
 	v := map[string]string{"cachegroup": "2"}
 
-	servers, errs, errType := getServers(v, db, auth.PrivLevelAdmin)
+	servers, errs, errType := getServers(v, db, auth.CurrentUser{PrivLevel: auth.PrivLevelAdmin, TenantID: 1})
 	log.Debugln("%v-->", servers)
 	if len(errs) > 0 {
 		t.Errorf("getServers expected: no errors, actual: %v with error type: %s", errs, errType.String())
@@ -20,8 +20,8 @@ package server
  */
 
 import (
-	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 
@@ -59,9 +59,39 @@ func GetServerUpdateStatusHandler(db *sqlx.DB) http.HandlerFunc {
 	}
 }
 
-func getServerUpdateStatus(hostName string, db *sqlx.DB) ([]tc.ServerUpdateStatus, error) {
-	baseSelectStatement :=
-		`WITH parentservers AS (SELECT ps.id, ps.cachegroup, ps.cdn_id, ps.upd_pending, ps.reval_pending FROM server ps
+// GetCDNServerUpdateStatusHandler serves the update status of every server in the CDN with the path name, in the same form as GetServerUpdateStatusHandler, so caches can poll a single request for the whole CDN.
+func GetCDNServerUpdateStatusHandler(db *sqlx.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		handleErrs := tc.GetHandleErrorsFunc(w, r)
+
+		params, err := api.GetCombinedParams(r)
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, err)
+			return
+		}
+
+		serverUpdateStatus, ok, err := getCDNServerUpdateStatus(params["name"], db)
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, err)
+			return
+		}
+		if !ok {
+			handleErrs(http.StatusNotFound, errors.New("no cdn with that name found"))
+			return
+		}
+
+		respBts, err := json.Marshal(serverUpdateStatus)
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, err)
+			return
+		}
+
+		w.Header().Set(tc.ContentType, tc.ApplicationJson)
+		w.Write(respBts)
+	}
+}
+
+const serverUpdateStatusSelect = `WITH parentservers AS (SELECT ps.id, ps.cachegroup, ps.cdn_id, ps.upd_pending, ps.reval_pending FROM server ps
          LEFT JOIN status AS pstatus ON pstatus.id = ps.status
          WHERE pstatus.name != 'OFFLINE' ),
          use_reval_pending AS (SELECT value::boolean FROM parameter WHERE name = 'use_reval_pending' AND config_file = 'global' UNION ALL SELECT FALSE FETCH FIRST 1 ROW ONLY)
@@ -71,26 +101,46 @@ func getServerUpdateStatus(hostName string, db *sqlx.DB) ([]tc.ServerUpdateStatu
          LEFT JOIN type ON type.id = s.type
          LEFT JOIN parentservers ps ON ps.cachegroup = cg.parent_cachegroup_id AND ps.cdn_id = s.cdn_id AND type.name = 'EDGE'` //remove the EDGE reference if other server types should have their parents processed
 
-	groupBy := ` GROUP BY s.id, s.host_name, type.name, server_reval_pending, use_reval_pending.value, s.upd_pending, status.name ORDER BY s.id;`
+const serverUpdateStatusGroupBy = ` GROUP BY s.id, s.host_name, type.name, server_reval_pending, use_reval_pending.value, s.upd_pending, status.name ORDER BY s.id;`
 
-	updateStatuses := []tc.ServerUpdateStatus{}
-	var rows *sql.Rows
-	var err error
+func getServerUpdateStatus(hostName string, db *sqlx.DB) ([]tc.ServerUpdateStatus, error) {
 	if hostName == "all" {
-		rows, err = db.Query(baseSelectStatement + groupBy)
+		updateStatuses, err := queryServerUpdateStatus(db, serverUpdateStatusSelect+serverUpdateStatusGroupBy)
 		if err != nil {
-			log.Error.Printf("could not execute select server update status query: %s\n", err)
-			return nil, tc.DBError
+			return nil, err
 		}
-	} else {
-		rows, err = db.Query(baseSelectStatement+` WHERE s.host_name = $1`+groupBy, hostName)
-		if err != nil {
-			log.Error.Printf("could not execute select server update status by hostname query: %s\n", err)
-			return nil, tc.DBError
+		for i := range updateStatuses { //if we want to return the parent data for servers when all is used remove this loop
+			updateStatuses[i].ParentRevalPending = false
+			updateStatuses[i].ParentPending = false
 		}
+		return updateStatuses, nil
+	}
+	return queryServerUpdateStatus(db, serverUpdateStatusSelect+` WHERE s.host_name = $1`+serverUpdateStatusGroupBy, hostName)
+}
+
+// getCDNServerUpdateStatus returns the update status of every server in the given CDN, including whether their parents have pending updates. If the CDN doesn't exist, false is returned.
+func getCDNServerUpdateStatus(cdnName string, db *sqlx.DB) ([]tc.ServerUpdateStatus, bool, error) {
+	exists := false
+	if err := db.QueryRow(`SELECT EXISTS(SELECT 1 FROM cdn WHERE name = $1)`, cdnName).Scan(&exists); err != nil {
+		log.Errorln("querying whether cdn exists: " + err.Error())
+		return nil, false, tc.DBError
+	}
+	if !exists {
+		return nil, false, nil
+	}
+	updateStatuses, err := queryServerUpdateStatus(db, serverUpdateStatusSelect+` WHERE s.cdn_id = (SELECT id FROM cdn WHERE name = $1)`+serverUpdateStatusGroupBy, cdnName)
+	return updateStatuses, true, err
+}
+
+func queryServerUpdateStatus(db *sqlx.DB, query string, args ...interface{}) ([]tc.ServerUpdateStatus, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		log.Error.Printf("could not execute select server update status query: %s\n", err)
+		return nil, tc.DBError
 	}
 	defer rows.Close()
 
+	updateStatuses := []tc.ServerUpdateStatus{}
 	for rows.Next() {
 		var serverUpdateStatus tc.ServerUpdateStatus
 		var serverType string
@@ -98,10 +148,6 @@ func getServerUpdateStatus(hostName string, db *sqlx.DB) ([]tc.ServerUpdateStatu
 			log.Error.Printf("could not scan server update status: %s\n", err)
 			return nil, tc.DBError
 		}
-		if hostName == "all" { //if we want to return the parent data for servers when all is used remove this block
-			serverUpdateStatus.ParentRevalPending = false
-			serverUpdateStatus.ParentPending = false
-		}
 		updateStatuses = append(updateStatuses, serverUpdateStatus)
 	}
 	return updateStatuses, nil
@@ -26,6 +26,7 @@ import (
 
 	tc "github.com/apache/incubator-trafficcontrol/lib/go-tc"
 	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/parameter"
 
 	"github.com/jmoiron/sqlx"
 )
@@ -40,9 +41,7 @@ func Handler(db *sqlx.DB) http.HandlerFunc {
 			handleErrs(http.StatusInternalServerError, err)
 			return
 		}
-		privLevel := user.PrivLevel
-
-		resp, err := getSystemInfoResponse(db, privLevel)
+		resp, err := getSystemInfoResponse(db, user.HasCapability(parameter.SecureReadCapability))
 		if err != nil {
 			handleErrs(http.StatusInternalServerError, err)
 			return
@@ -58,8 +57,8 @@ func Handler(db *sqlx.DB) http.HandlerFunc {
 		fmt.Fprintf(w, "%s", respBts)
 	}
 }
-func getSystemInfoResponse(db *sqlx.DB, privLevel int) (*tc.SystemInfoResponse, error) {
-	info, err := getSystemInfo(db, privLevel)
+func getSystemInfoResponse(db *sqlx.DB, showSecure bool) (*tc.SystemInfoResponse, error) {
+	info, err := getSystemInfo(db, showSecure)
 	if err != nil {
 		return nil, fmt.Errorf("getting SystemInfo: %v", err)
 	}
@@ -69,7 +68,7 @@ func getSystemInfoResponse(db *sqlx.DB, privLevel int) (*tc.SystemInfoResponse,
 	return &resp, nil
 }
 
-func getSystemInfo(db *sqlx.DB, privLevel int) (map[string]string, error) {
+func getSystemInfo(db *sqlx.DB, showSecure bool) (map[string]string, error) {
 	// system info returns all global parameters
 	query := `SELECT
 p.name,
@@ -100,8 +99,8 @@ WHERE p.config_file='global'`
 
 		name := p.Name
 		value := p.Value
-		if isSecure && privLevel < auth.PrivLevelAdmin {
-			// Secure params only visible to admin
+		if isSecure && !showSecure {
+			// Secure params are only visible with the secure params capability
 			continue
 		}
 
@@ -28,7 +28,6 @@ import (
 	"encoding/json"
 
 	tc "github.com/apache/incubator-trafficcontrol/lib/go-tc"
-	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/auth"
 	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/test"
 	"github.com/jmoiron/sqlx"
 )
@@ -93,7 +92,7 @@ func TestGetSystemInfo(t *testing.T) {
 	}
 
 	mock.ExpectQuery("SELECT.*WHERE p.config_file='global'").WillReturnRows(rows)
-	sysinfo, err := getSystemInfo(db, auth.PrivLevelReadOnly)
+	sysinfo, err := getSystemInfo(db, false)
 	if err != nil {
 		t.Errorf("getSystemInfo expected: nil error, actual: %v", err)
 	}
@@ -118,6 +118,80 @@ func GetUserTenantList(user auth.CurrentUser, db *sqlx.DB) ([]Tenant, error) {
 	return tenants, nil
 }
 
+// IsTenancyEnabled returns whether the global use_tenancy parameter is set. Tenancy is disabled if the parameter doesn't exist. The db may be a transaction.
+func IsTenancyEnabled(db sqlx.Queryer) (bool, error) {
+	query := `SELECT COALESCE(value::boolean,FALSE) AS value FROM parameter WHERE name = 'use_tenancy' AND config_file = 'global' UNION ALL SELECT FALSE FETCH FIRST 1 ROW ONLY`
+	useTenancy := false
+	if err := db.QueryRowx(query).Scan(&useTenancy); err != nil {
+		return false, errors.New("querying use_tenancy parameter: " + err.Error())
+	}
+	return useTenancy, nil
+}
+
+// useTenancySubquery is a SQL boolean expression of whether the global use_tenancy parameter is set.
+// It uses CAST rather than '::', because it's used in sqlx named queries, which unescape '::' to ':'.
+const useTenancySubquery = `(SELECT COALESCE((SELECT CAST(value AS boolean) FROM parameter WHERE name = 'use_tenancy' AND config_file = 'global' FETCH FIRST 1 ROW ONLY), FALSE))`
+
+// TenancyCheckClause returns a SQL boolean expression which is true if the given tenant column is accessible to the user with the given tenant ID, which must be bound as the named parameter :user_tenant_id.
+// A resource is accessible if it has no tenant, if its tenant is an active tenant in the user's tenant tree, or if the global use_tenancy parameter is not set.
+// This has the same semantics as IsResourceAuthorizedToUser, but may be used to filter many rows in a single query.
+// The tenant column must not contain '::' casts, because sqlx named queries unescape '::' to ':'; use CAST instead.
+func TenancyCheckClause(tenantColumn string) string {
+	return `(NOT ` + useTenancySubquery + `
+OR ` + tenantColumn + ` IS NULL
+OR ` + tenantColumn + ` IN (WITH RECURSIVE q AS (SELECT id, active FROM tenant WHERE id = :user_tenant_id
+UNION SELECT t.id, t.active FROM tenant t JOIN q ON q.id = t.parent_id) SELECT id FROM q WHERE active))`
+}
+
+// AddTenancyCheck appends the TenancyCheckClause for the given tenant column to the given where clause, as built by dbhelpers.BuildWhereAndOrderBy, and adds the user's tenant to the query values.
+func AddTenancyCheck(where string, queryValues map[string]interface{}, tenantColumn string, user auth.CurrentUser) string {
+	if where == "" {
+		where = "\nWHERE "
+	} else {
+		where += " AND "
+	}
+	queryValues["user_tenant_id"] = user.TenantID
+	return where + TenancyCheckClause(tenantColumn)
+}
+
+// IsTenantInUserTree returns whether the given tenant is the user's tenant or one of its descendants, regardless of whether the given tenant is active.
+// The user's own tenant must be active. This is used to authorize managing tenants themselves, where inactive tenants must remain visible in order to be activated.
+// If use_tenancy is set to false, this returns true.
+func IsTenantInUserTree(tenantID int, user auth.CurrentUser, db *sqlx.DB) (bool, error) {
+	useTenancy, err := IsTenancyEnabled(db)
+	if err != nil {
+		return false, err
+	}
+	if !useTenancy {
+		return true, nil
+	}
+	query := `WITH RECURSIVE q AS (SELECT id FROM tenant WHERE id = $1 AND active
+	UNION SELECT t.id FROM tenant t JOIN q ON q.id = t.parent_id)
+	SELECT EXISTS(SELECT id FROM q WHERE id = $2)`
+	inTree := false
+	if err := db.QueryRow(query, user.TenantID, tenantID).Scan(&inTree); err != nil {
+		return false, errors.New("querying user tenant tree: " + err.Error())
+	}
+	return inTree, nil
+}
+
+// IsRootTenantUser returns whether the user's tenant is the active root tenant, whose tree includes every tenant. Only root tenant users may manage resources which apply to every tenant.
+// If use_tenancy is set to false, this returns true.
+func IsRootTenantUser(user auth.CurrentUser, db *sqlx.DB) (bool, error) {
+	useTenancy, err := IsTenancyEnabled(db)
+	if err != nil {
+		return false, err
+	}
+	if !useTenancy {
+		return true, nil
+	}
+	isRoot := false
+	if err := db.QueryRow(`SELECT EXISTS(SELECT id FROM tenant WHERE id = $1 AND parent_id IS NULL AND active)`, user.TenantID).Scan(&isRoot); err != nil {
+		return false, errors.New("querying user tenant: " + err.Error())
+	}
+	return isRoot, nil
+}
+
 // returns a boolean value describing if the user has access to the provided resource tenant id and an error
 // if use_tenancy is set to false (0 in the db) this method will return true allowing access.
 func IsResourceAuthorizedToUser(resourceTenantID int, user auth.CurrentUser, db *sqlx.DB) (bool, error) {
@@ -0,0 +1,41 @@
+package tenant
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// TestTenancyCheckClauseNamed checks that the clause survives sqlx named query compilation, which unescapes '::' to ':'.
+func TestTenancyCheckClauseNamed(t *testing.T) {
+	q, args, err := sqlx.Named("SELECT id FROM server AS s WHERE "+TenancyCheckClause("s.tenant_id"), map[string]interface{}{"user_tenant_id": 1})
+	if err != nil {
+		t.Fatalf("sqlx.Named expected: no error, actual: %v", err)
+	}
+	if len(args) != 1 {
+		t.Errorf("sqlx.Named expected: 1 arg, actual: %v", len(args))
+	}
+	if strings.Contains(strings.Replace(q, "::", "", -1), ":") {
+		t.Errorf("sqlx.Named expected: no single colons, actual: %s", q)
+	}
+}
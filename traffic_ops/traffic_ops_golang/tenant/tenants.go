@@ -0,0 +1,506 @@
+package tenant
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-log"
+	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/dbhelpers"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/tovalidate"
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// pqForeignKeyViolation is the Postgres error code of a foreign key violation, returned when deleting a tenant which delivery services, users or servers still belong to.
+const pqForeignKeyViolation = "23503"
+
+//we need a type alias to define functions on
+type TOTenant tc.TenantNullable
+
+//the refType is passed into the handlers where a copy of its type is used to decode the json.
+var refType = TOTenant(tc.TenantNullable{})
+
+func GetRefType() *TOTenant {
+	return &refType
+}
+
+func (ten TOTenant) GetAuditName() string {
+	if ten.Name != nil {
+		return *ten.Name
+	}
+	if ten.ID != nil {
+		return strconv.Itoa(*ten.ID)
+	}
+	return "unknown"
+}
+
+func (ten TOTenant) GetKeyFieldsInfo() []api.KeyFieldInfo {
+	return []api.KeyFieldInfo{{"id", api.GetIntKey}}
+}
+
+//Implementation of the Identifier, Validator interface functions
+func (ten TOTenant) GetKeys() (map[string]interface{}, bool) {
+	if ten.ID == nil {
+		return map[string]interface{}{"id": 0}, false
+	}
+	return map[string]interface{}{"id": *ten.ID}, true
+}
+
+func (ten *TOTenant) SetKeys(keys map[string]interface{}) {
+	i, _ := keys["id"].(int) //this utilizes the non panicking type assertion, if the thrown away ok variable is false i will be the zero of the type, 0 here.
+	ten.ID = &i
+}
+
+func (ten TOTenant) GetType() string {
+	return "tenant"
+}
+
+// Validate requires a parent for every tenant but the root, which can have no parent and must stay active.
+func (ten TOTenant) Validate(db *sqlx.DB) []error {
+	errs := tovalidate.ToErrors(validation.Errors{
+		"name":   validation.Validate(ten.Name, validation.NotNil, validation.Required),
+		"active": validation.Validate(ten.Active, validation.NotNil),
+	})
+	if len(errs) > 0 {
+		return errs
+	}
+
+	isRoot := false
+	if ten.ID != nil && *ten.ID != 0 {
+		if err := db.QueryRow(`SELECT parent_id IS NULL FROM tenant WHERE id = $1`, *ten.ID).Scan(&isRoot); err != nil && err != sql.ErrNoRows {
+			log.Errorln("querying tenant parent: " + err.Error())
+			return []error{tc.DBError}
+		}
+	}
+	if isRoot {
+		if ten.ParentID != nil {
+			errs = append(errs, errors.New("the root tenant cannot have a parent"))
+		}
+		if !*ten.Active {
+			errs = append(errs, errors.New("the root tenant cannot be inactive"))
+		}
+		return errs
+	}
+	if ten.ParentID == nil {
+		return append(errs, errors.New("parentId: cannot be blank"))
+	}
+	if ten.ID != nil && *ten.ParentID == *ten.ID {
+		errs = append(errs, errors.New("a tenant cannot be its own parent"))
+	}
+	return errs
+}
+
+// IsTenantAuthorized implements the Tenantable interface to ensure the user is authorized on the tenant, its requested parent, and its current parent if the parent is being changed.
+func (ten TOTenant) IsTenantAuthorized(user auth.CurrentUser, db *sqlx.DB) (bool, error) {
+	if ten.ID != nil && *ten.ID != 0 {
+		authorized, err := IsTenantInUserTree(*ten.ID, user, db)
+		if err != nil || !authorized {
+			return authorized, err
+		}
+		var currentParentID *int
+		if err := db.QueryRow(`SELECT parent_id FROM tenant WHERE id = $1`, *ten.ID).Scan(&currentParentID); err != nil && err != sql.ErrNoRows {
+			return false, errors.New("querying tenant parent: " + err.Error())
+		}
+		if currentParentID != nil && ten.ParentID != nil && *currentParentID != *ten.ParentID {
+			authorized, err := IsTenantInUserTree(*currentParentID, user, db)
+			if err != nil || !authorized {
+				return authorized, err
+			}
+		}
+	}
+	if ten.ParentID == nil {
+		return true, nil
+	}
+	return IsTenantInUserTree(*ten.ParentID, user, db)
+}
+
+// checkParentActive returns an error if the tenant is active and its parent is not, because an active tenant under an inactive one would be inaccessible.
+func (ten TOTenant) checkParentActive(tx *sqlx.Tx) (error, tc.ApiErrorType) {
+	if ten.ParentID == nil || ten.Active == nil || !*ten.Active {
+		return nil, tc.NoError
+	}
+	parentActive := false
+	if err := tx.QueryRow(`SELECT active FROM tenant WHERE id = $1`, *ten.ParentID).Scan(&parentActive); err != nil {
+		if err == sql.ErrNoRows {
+			return errors.New("parent tenant " + strconv.Itoa(*ten.ParentID) + " does not exist"), tc.DataConflictError
+		}
+		log.Errorln("querying parent tenant: " + err.Error())
+		return tc.DBError, tc.SystemError
+	}
+	if !parentActive {
+		return errors.New("a tenant cannot be active under an inactive parent"), tc.DataConflictError
+	}
+	return nil, tc.NoError
+}
+
+//The TOTenant implementation of the Creator interface
+//all implementations of Creator should use transactions and return the proper errorType
+//ParsePQUniqueConstraintError is used to determine if a tenant with conflicting values exists
+//if so, it will return an errorType of DataConflict and the type should be appended to the
+//generic error message returned
+//The insert sql returns the id and lastUpdated values of the newly inserted tenant and have
+//to be added to the struct
+func (ten *TOTenant) Create(db *sqlx.DB, user auth.CurrentUser) (error, tc.ApiErrorType) {
+	rollbackTransaction := true
+	tx, err := db.Beginx()
+	defer func() {
+		if tx == nil || !rollbackTransaction {
+			return
+		}
+		err := tx.Rollback()
+		if err != nil {
+			log.Errorln(errors.New("rolling back transaction: " + err.Error()))
+		}
+	}()
+
+	if err != nil {
+		log.Error.Printf("could not begin transaction: %v", err)
+		return tc.DBError, tc.SystemError
+	}
+	if err, errType := ten.checkParentActive(tx); err != nil {
+		return err, errType
+	}
+	resultRows, err := tx.NamedQuery(insertQuery(), ten)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok {
+			err, eType := dbhelpers.ParsePQUniqueConstraintError(pqErr)
+			if eType == tc.DataConflictError {
+				return errors.New("a tenant with " + err.Error()), eType
+			}
+			return err, eType
+		}
+		log.Errorf("received non pq error: %++v from create execution", err)
+		return tc.DBError, tc.SystemError
+	}
+	defer resultRows.Close()
+
+	var id int
+	var lastUpdated tc.TimeNoMod
+	rowsAffected := 0
+	for resultRows.Next() {
+		rowsAffected++
+		if err := resultRows.Scan(&id, &lastUpdated); err != nil {
+			log.Error.Printf("could not scan id from insert: %s\n", err)
+			return tc.DBError, tc.SystemError
+		}
+	}
+	if rowsAffected == 0 {
+		err = errors.New("no tenant was inserted, no id was returned")
+		log.Errorln(err)
+		return tc.DBError, tc.SystemError
+	} else if rowsAffected > 1 {
+		err = errors.New("too many ids returned from tenant insert")
+		log.Errorln(err)
+		return tc.DBError, tc.SystemError
+	}
+	ten.SetKeys(map[string]interface{}{"id": id})
+	ten.LastUpdated = &lastUpdated
+	err = tx.Commit()
+	if err != nil {
+		log.Errorln("Could not commit transaction: ", err)
+		return tc.DBError, tc.SystemError
+	}
+	rollbackTransaction = false
+	return nil, tc.NoError
+}
+
+// Read returns the tenants in the user's tenant tree, including inactive ones, so they can be activated. If use_tenancy is not set, all tenants are returned.
+func (ten *TOTenant) Read(db *sqlx.DB, parameters map[string]string, user auth.CurrentUser) ([]interface{}, []error, tc.ApiErrorType) {
+	// Query Parameters to Database Query column mappings
+	// see the fields mapped in the SQL query
+	queryParamsToQueryCols := map[string]dbhelpers.WhereColumnInfo{
+		"active":      dbhelpers.WhereColumnInfo{"t.active", api.IsBool},
+		"id":          dbhelpers.WhereColumnInfo{"t.id", api.IsInt},
+		"name":        dbhelpers.WhereColumnInfo{"t.name", nil},
+		"parentId":    dbhelpers.WhereColumnInfo{"t.parent_id", api.IsInt},
+		"parentName":  dbhelpers.WhereColumnInfo{"p.name", nil},
+		"lastUpdated": dbhelpers.WhereColumnInfo{"t.last_updated", nil},
+	}
+	where, orderBy, queryValues, errs := dbhelpers.BuildWhereAndOrderBy(parameters, queryParamsToQueryCols)
+	if len(errs) > 0 {
+		return nil, errs, tc.DataConflictError
+	}
+	if where == "" {
+		where = "\nWHERE "
+	} else {
+		where += " AND "
+	}
+	where += userTreeClause
+	queryValues["user_tenant_id"] = user.TenantID
+
+	query := selectQuery() + where + orderBy
+	log.Debugln("Query is ", query)
+
+	rows, err := db.NamedQuery(query, queryValues)
+	if err != nil {
+		log.Errorf("Error querying Tenants: %v", err)
+		return nil, []error{tc.DBError}, tc.SystemError
+	}
+	defer rows.Close()
+
+	tenants := []interface{}{}
+	for rows.Next() {
+		var s tc.TenantNullable
+		if err = rows.StructScan(&s); err != nil {
+			log.Errorf("error parsing Tenant rows: %v", err)
+			return nil, []error{tc.DBError}, tc.SystemError
+		}
+		tenants = append(tenants, s)
+	}
+
+	return tenants, []error{}, tc.NoError
+}
+
+//The TOTenant implementation of the Updater interface
+//all implementations of Updater should use transactions and return the proper errorType
+//A tenant may not be moved under itself or any of its descendants, and deactivating a tenant deactivates all its descendants
+func (ten *TOTenant) Update(db *sqlx.DB, user auth.CurrentUser) (error, tc.ApiErrorType) {
+	rollbackTransaction := true
+	tx, err := db.Beginx()
+	defer func() {
+		if tx == nil || !rollbackTransaction {
+			return
+		}
+		err := tx.Rollback()
+		if err != nil {
+			log.Errorln(errors.New("rolling back transaction: " + err.Error()))
+		}
+	}()
+
+	if err != nil {
+		log.Error.Printf("could not begin transaction: %v", err)
+		return tc.DBError, tc.SystemError
+	}
+	if err, errType := ten.UpdateTx(tx, user); err != nil {
+		return err, errType
+	}
+	err = tx.Commit()
+	if err != nil {
+		log.Errorln("Could not commit transaction: ", err)
+		return tc.DBError, tc.SystemError
+	}
+	rollbackTransaction = false
+	return nil, tc.NoError
+}
+
+// UpdateTx updates the tenant in the given transaction, which the caller commits or rolls back.
+func (ten *TOTenant) UpdateTx(tx *sqlx.Tx, user auth.CurrentUser) (error, tc.ApiErrorType) {
+	// Row locks can't stop concurrent moves of two tenants under each other, so updates with a parent are serialized by a table lock, and the descendant check sees every committed move.
+	// The lock is taken before any row lock, or deactivating descendants could deadlock with a concurrent move.
+	if ten.ParentID != nil {
+		if _, err := tx.Exec(`LOCK TABLE tenant IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+			log.Errorln("locking tenant table: " + err.Error())
+			return tc.DBError, tc.SystemError
+		}
+	}
+	var currentParentID *int
+	if err := tx.QueryRow(`SELECT parent_id FROM tenant WHERE id = $1 FOR UPDATE`, *ten.ID).Scan(&currentParentID); err != nil {
+		if err == sql.ErrNoRows {
+			return errors.New("no tenant found with this id"), tc.DataMissingError
+		}
+		log.Errorln("querying tenant: " + err.Error())
+		return tc.DBError, tc.SystemError
+	}
+	if ten.ParentID != nil && (currentParentID == nil || *currentParentID != *ten.ParentID) {
+		isDescendant := false
+		if err := tx.QueryRow(descendantQuery(), *ten.ID, *ten.ParentID).Scan(&isDescendant); err != nil {
+			log.Errorln("querying tenant descendants: " + err.Error())
+			return tc.DBError, tc.SystemError
+		}
+		if isDescendant {
+			return errors.New("a tenant cannot be moved under itself or one of its descendants"), tc.DataConflictError
+		}
+	}
+	if err, errType := ten.checkParentActive(tx); err != nil {
+		return err, errType
+	}
+
+	log.Debugf("about to run exec query: %s with tenant: %++v", updateQuery(), ten)
+	resultRows, err := tx.NamedQuery(updateQuery(), ten)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok {
+			err, eType := dbhelpers.ParsePQUniqueConstraintError(pqErr)
+			if eType == tc.DataConflictError {
+				return errors.New("a tenant with " + err.Error()), eType
+			}
+			return err, eType
+		}
+		log.Errorf("received error: %++v from update execution", err)
+		return tc.DBError, tc.SystemError
+	}
+	defer resultRows.Close()
+
+	var lastUpdated tc.TimeNoMod
+	rowsAffected := 0
+	for resultRows.Next() {
+		rowsAffected++
+		if err := resultRows.Scan(&lastUpdated); err != nil {
+			log.Error.Printf("could not scan lastUpdated from update: %s\n", err)
+			return tc.DBError, tc.SystemError
+		}
+	}
+	ten.LastUpdated = &lastUpdated
+	if rowsAffected != 1 {
+		if rowsAffected < 1 {
+			return errors.New("no tenant found with this id"), tc.DataMissingError
+		}
+		return fmt.Errorf("this update affected too many rows: %d", rowsAffected), tc.SystemError
+	}
+	resultRows.Close()
+
+	if !*ten.Active {
+		if _, err := tx.Exec(deactivateDescendantsQuery(), *ten.ID); err != nil {
+			log.Errorln("deactivating tenant descendants: " + err.Error())
+			return tc.DBError, tc.SystemError
+		}
+	}
+	return nil, tc.NoError
+}
+
+//The TOTenant implementation of the Deleter interface
+//Tenants with children, or which delivery services, users or servers belong to, may not be deleted
+func (ten *TOTenant) Delete(db *sqlx.DB, user auth.CurrentUser) (error, tc.ApiErrorType) {
+	rollbackTransaction := true
+	tx, err := db.Beginx()
+	defer func() {
+		if tx == nil || !rollbackTransaction {
+			return
+		}
+		err := tx.Rollback()
+		if err != nil {
+			log.Errorln(errors.New("rolling back transaction: " + err.Error()))
+		}
+	}()
+
+	if err != nil {
+		log.Error.Printf("could not begin transaction: %v", err)
+		return tc.DBError, tc.SystemError
+	}
+	if err, errType := ten.DeleteTx(tx, user); err != nil {
+		return err, errType
+	}
+	err = tx.Commit()
+	if err != nil {
+		log.Errorln("Could not commit transaction: ", err)
+		return tc.DBError, tc.SystemError
+	}
+	rollbackTransaction = false
+	return nil, tc.NoError
+}
+
+// DeleteTx deletes the tenant in the given transaction, which the caller commits or rolls back.
+func (ten *TOTenant) DeleteTx(tx *sqlx.Tx, user auth.CurrentUser) (error, tc.ApiErrorType) {
+	childCount := 0
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM tenant WHERE parent_id = $1`, *ten.ID).Scan(&childCount); err != nil {
+		log.Errorln("querying tenant children: " + err.Error())
+		return tc.DBError, tc.SystemError
+	}
+	if childCount > 0 {
+		return fmt.Errorf("tenant has %d child tenants, and cannot be deleted", childCount), tc.DataConflictError
+	}
+	log.Debugf("about to run exec query: %s with tenant: %++v", deleteQuery(), ten)
+	result, err := tx.NamedExec(deleteQuery(), ten)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == pqForeignKeyViolation {
+			return errors.New("tenant is in use by delivery services, users or servers, and cannot be deleted"), tc.DataConflictError
+		}
+		log.Errorf("received error: %++v from delete execution", err)
+		return tc.DBError, tc.SystemError
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return tc.DBError, tc.SystemError
+	}
+	if rowsAffected != 1 {
+		if rowsAffected < 1 {
+			return errors.New("no tenant with that id found"), tc.DataMissingError
+		}
+		return fmt.Errorf("this delete affected too many rows: %d", rowsAffected), tc.SystemError
+	}
+	return nil, tc.NoError
+}
+
+// userTreeClause is true for tenants in the tree of the active tenant bound as :user_tenant_id, regardless of whether they're active, or for all tenants if use_tenancy is not set.
+const userTreeClause = `(NOT ` + useTenancySubquery + `
+OR t.id IN (WITH RECURSIVE q AS (SELECT id FROM tenant WHERE id = :user_tenant_id AND active
+UNION SELECT c.id FROM tenant c JOIN q ON q.id = c.parent_id) SELECT id FROM q))`
+
+func insertQuery() string {
+	query := `INSERT INTO tenant (
+name,
+active,
+parent_id) VALUES (
+:name,
+:active,
+:parent_id) RETURNING id,last_updated`
+	return query
+}
+
+func selectQuery() string {
+	query := `SELECT
+t.id,
+t.name,
+t.active,
+t.parent_id,
+p.name AS parent_name,
+t.last_updated
+
+FROM tenant t
+LEFT JOIN tenant p ON t.parent_id = p.id`
+	return query
+}
+
+func updateQuery() string {
+	query := `UPDATE
+tenant SET
+name=:name,
+active=:active,
+parent_id=:parent_id
+WHERE id=:id RETURNING last_updated`
+	return query
+}
+
+func deleteQuery() string {
+	query := `DELETE FROM tenant
+WHERE id=:id`
+	return query
+}
+
+// descendantQuery returns whether the tenant $2 is the tenant $1 or one of its descendants.
+func descendantQuery() string {
+	return `WITH RECURSIVE q AS (SELECT id FROM tenant WHERE id = $1
+UNION SELECT t.id FROM tenant t JOIN q ON q.id = t.parent_id)
+SELECT EXISTS(SELECT id FROM q WHERE id = $2)`
+}
+
+// deactivateDescendantsQuery deactivates all descendants of the tenant $1.
+func deactivateDescendantsQuery() string {
+	return `WITH RECURSIVE q AS (SELECT id FROM tenant WHERE parent_id = $1
+UNION SELECT t.id FROM tenant t JOIN q ON q.id = t.parent_id)
+UPDATE tenant SET active = false WHERE id IN (SELECT id FROM q) AND active`
+}
@@ -0,0 +1,231 @@
+package tenant
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/test"
+	"github.com/jmoiron/sqlx"
+
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestReadTenants(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	rows := sqlmock.NewRows(test.ColsFromStructByTag("db", tc.TenantNullable{}))
+	rows = rows.AddRow(1, "root", true, nil, nil, time.Now())
+	rows = rows.AddRow(2, "customer", false, 1, "root", time.Now())
+	mock.ExpectQuery("SELECT").WithArgs(1).WillReturnRows(rows)
+
+	tenants, errs, errType := refType.Read(db, map[string]string{}, auth.CurrentUser{TenantID: 1})
+	if len(errs) > 0 {
+		t.Fatalf("tenant.Read expected: no errors, actual: %v with error type: %s", errs, errType.String())
+	}
+	if len(tenants) != 2 {
+		t.Fatalf("tenant.Read expected: len(tenants) == 2, actual: %v", len(tenants))
+	}
+	if parent := tenants[0].(tc.TenantNullable).ParentID; parent != nil {
+		t.Errorf("tenant.Read expected: root tenant with no parent, actual: %v", *parent)
+	}
+	if parentName := tenants[1].(tc.TenantNullable).ParentName; parentName == nil || *parentName != "root" {
+		t.Errorf("tenant.Read expected: parent name root, actual: %v", parentName)
+	}
+}
+
+func TestUpdateTenantCycle(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	id := 2
+	parentID := 3
+	name := "customer"
+	active := true
+	ten := TOTenant{ID: &id, Name: &name, Active: &active, ParentID: &parentID}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("LOCK TABLE tenant IN SHARE ROW EXCLUSIVE MODE").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT parent_id FROM tenant").WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"parent_id"}).AddRow(1))
+	mock.ExpectQuery("WITH RECURSIVE").WithArgs(2, 3).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectRollback()
+
+	err, errType := ten.Update(db, auth.CurrentUser{})
+	if err == nil || errType != tc.DataConflictError {
+		t.Errorf("tenant.Update under a descendant expected: data conflict error, actual: %v with error type: %s", err, errType.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestUpdateTenantDeactivate(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	id := 2
+	parentID := 1
+	name := "customer"
+	active := false
+	ten := TOTenant{ID: &id, Name: &name, Active: &active, ParentID: &parentID}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("LOCK TABLE tenant IN SHARE ROW EXCLUSIVE MODE").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT parent_id FROM tenant").WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"parent_id"}).AddRow(1))
+	mock.ExpectQuery("UPDATE").WillReturnRows(sqlmock.NewRows([]string{"last_updated"}).AddRow(time.Now()))
+	mock.ExpectExec("UPDATE tenant SET active = false").WithArgs(2).WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectCommit()
+
+	if err, errType := ten.Update(db, auth.CurrentUser{}); err != nil {
+		t.Fatalf("tenant.Update expected: no error, actual: %v with error type: %s", err, errType.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestCreateTenantInactiveParent(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	parentID := 2
+	name := "team"
+	active := true
+	ten := TOTenant{Name: &name, Active: &active, ParentID: &parentID}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT active FROM tenant").WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"active"}).AddRow(false))
+	mock.ExpectRollback()
+
+	err, errType := ten.Create(db, auth.CurrentUser{})
+	if err == nil || errType != tc.DataConflictError {
+		t.Errorf("tenant.Create under an inactive parent expected: data conflict error, actual: %v with error type: %s", err, errType.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestDeleteTenantWithChildren(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	id := 2
+	ten := TOTenant{ID: &id}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT COUNT").WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectRollback()
+
+	err, errType := ten.Delete(db, auth.CurrentUser{})
+	if err == nil || errType != tc.DataConflictError {
+		t.Errorf("tenant.Delete with children expected: data conflict error, actual: %v with error type: %s", err, errType.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestValidation(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	if errs := (TOTenant{}).Validate(db); len(errs) != 2 {
+		t.Errorf("tenant.Validate expected: errors for name and active, actual: %v", errs)
+	}
+
+	name := "team"
+	active := true
+	if errs := (TOTenant{Name: &name, Active: &active}).Validate(db); len(errs) != 1 {
+		t.Errorf("tenant.Validate expected: error for missing parentId, actual: %v", errs)
+	}
+
+	id := 1
+	inactive := false
+	mock.ExpectQuery("SELECT parent_id IS NULL").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"root"}).AddRow(true))
+	if errs := (TOTenant{ID: &id, Name: &name, Active: &inactive}).Validate(db); len(errs) != 1 || errs[0].Error() != "the root tenant cannot be inactive" {
+		t.Errorf("tenant.Validate expected: error for inactive root tenant, actual: %v", errs)
+	}
+}
+
+func TestInterfaces(t *testing.T) {
+	var i interface{}
+	i = &TOTenant{}
+
+	if _, ok := i.(api.Creator); !ok {
+		t.Errorf("tenant must be creator")
+	}
+	if _, ok := i.(api.Reader); !ok {
+		t.Errorf("tenant must be reader")
+	}
+	if _, ok := i.(api.Updater); !ok {
+		t.Errorf("tenant must be updater")
+	}
+	if _, ok := i.(api.Deleter); !ok {
+		t.Errorf("tenant must be deleter")
+	}
+	if _, ok := i.(api.Identifier); !ok {
+		t.Errorf("tenant must be Identifier")
+	}
+	if _, ok := i.(api.Tenantable); !ok {
+		t.Errorf("tenant must be Tenantable")
+	}
+}
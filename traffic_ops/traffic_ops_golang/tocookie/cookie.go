@@ -31,6 +31,8 @@ type Cookie struct {
 	AuthData    string `json:"auth_data"`
 	ExpiresUnix int64  `json:"expires"`
 	By          string `json:"by"`
+	// Session is the ID of the server-side session of the cookie, which can be revoked. Cookies created by the Perl Traffic Ops have no session.
+	Session string `json:"session,omitempty"`
 }
 
 func checkHmac(message, messageMAC, key []byte) bool {
@@ -94,12 +96,21 @@ func NewRawMsg(msg, key []byte) string {
 }
 
 func New(user string, expiration time.Time, key string) string {
-	cookieMsg := Cookie{By: GeneratedByStr, AuthData: user, ExpiresUnix: expiration.Unix()}
+	return NewSession(user, "", expiration, key)
+}
+
+// NewSession returns a new serialized cookie of the given server-side session.
+func NewSession(user string, session string, expiration time.Time, key string) string {
+	cookieMsg := Cookie{By: GeneratedByStr, AuthData: user, ExpiresUnix: expiration.Unix(), Session: session}
 	msg, _ := json.Marshal(cookieMsg)
 	return NewRawMsg(msg, []byte(key))
 }
 
 // Update takes an existing cookie and returns a new serialized cookie with an updated expiration
-func Refresh(c *Cookie, key string) string {
-	return New(c.AuthData, time.Now().Add(DefaultDuration), key)
+// Cookies without a session aren't refreshed, since they can't be revoked; they're only valid until they expire, and then the user must log in again.
+func Refresh(c *Cookie, key string) (string, error) {
+	if c.Session == "" {
+		return "", fmt.Errorf("cookie of %s has no session to refresh", c.AuthData)
+	}
+	return NewSession(c.AuthData, c.Session, time.Now().Add(DefaultDuration), key), nil
 }
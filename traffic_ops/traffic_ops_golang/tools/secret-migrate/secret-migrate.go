@@ -0,0 +1,98 @@
+package main
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/config"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/secretsvc"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+)
+
+// defaultBuckets are the buckets of all secrets stored by Traffic Ops.
+var defaultBuckets = []string{"ssl", "dnssec", "url_sig_keys", "cdn_uri_sig_keys"}
+
+// secret-migrate copies secrets, such as SSL keys and URI signing keys, from one secret store backend to another. The backends use the settings of the Traffic Ops config files, so the secret_store key_file or directory of the backends being migrated must be set in cdn.conf.
+//
+// For example, to copy all secrets from Riak to the postgres backend:
+//
+//	secret-migrate -cfg cdn.conf -dbcfg database.conf -riakcfg riak.conf -from riak -to postgres
+func main() {
+	configFileName := flag.String("cfg", "", "The config file path")
+	dbConfigFileName := flag.String("dbcfg", "", "The db config file path")
+	riakConfigFileName := flag.String("riakcfg", "", "The riak config file path, required to migrate to or from riak")
+	from := flag.String("from", config.SecretStoreRiak, "The backend to copy secrets from: riak, postgres, or file")
+	to := flag.String("to", "", "The backend to copy secrets to: riak, postgres, or file")
+	buckets := flag.String("buckets", strings.Join(defaultBuckets, ","), "The comma separated buckets to copy")
+	flag.Parse()
+
+	if *to == "" || *from == *to {
+		fmt.Println("Error: -to must be a backend other than -from")
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	cfg, err := config.LoadConfig(*configFileName, *dbConfigFileName, *riakConfigFileName, "", "")
+	if err != nil {
+		fmt.Println("Error loading config: " + err.Error())
+		os.Exit(1)
+	}
+
+	copied, err := migrate(cfg, *from, *to, strings.Split(*buckets, ","))
+	fmt.Printf("Copied %d secrets from %s to %s\n", copied, *from, *to)
+	if err != nil {
+		fmt.Println("Error: " + err.Error())
+		os.Exit(1)
+	}
+}
+
+// migrate copies the secrets of the given buckets from one backend to another, returning the number of secrets copied.
+func migrate(cfg config.Config, from string, to string, buckets []string) (int, error) {
+	sslStr := "require"
+	if !cfg.DB.SSL {
+		sslStr = "disable"
+	}
+	db, err := sqlx.Open("postgres", fmt.Sprintf("postgres://%s:%s@%s/%s?sslmode=%s", cfg.DB.User, cfg.DB.Password, cfg.DB.Hostname, cfg.DB.DBName, sslStr))
+	if err != nil {
+		return 0, errors.New("opening database: " + err.Error())
+	}
+	defer db.Close()
+
+	fromStore, err := secretsvc.OpenBackend(from, db, cfg)
+	if err != nil {
+		return 0, errors.New("opening " + from + " secret store: " + err.Error())
+	}
+	defer fromStore.Close()
+
+	toStore, err := secretsvc.OpenBackend(to, db, cfg)
+	if err != nil {
+		return 0, errors.New("opening " + to + " secret store: " + err.Error())
+	}
+	defer toStore.Close()
+
+	return secretsvc.Copy(fromStore, toStore, buckets)
+}
@@ -32,9 +32,8 @@ import (
 	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/api"
 	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/auth"
 	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/config"
-	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/riaksvc"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/secretsvc"
 	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/tenant"
-	"github.com/basho/riak-go-client"
 	"github.com/jmoiron/sqlx"
 	"github.com/lestrrat/go-jwx/jwk"
 )
@@ -48,13 +47,13 @@ type URISignerKeyset struct {
 	Keys       []jwk.EssentialHeader `json:"keys"`
 }
 
-// endpoint handler for fetching uri signing keys from riak
+// endpoint handler for fetching uri signing keys from the secret store
 func getURIsignkeysHandler(db *sqlx.DB, cfg config.Config) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		handleErr := tc.GetHandleErrorsFunc(w, r)
 
-		if cfg.RiakEnabled == false {
-			handleErr(http.StatusServiceUnavailable, fmt.Errorf("The RIAK service is unavailable"))
+		if !secretsvc.Enabled(cfg) {
+			handleErr(http.StatusServiceUnavailable, secretsvc.ErrUnavailable)
 			return
 		}
 
@@ -89,31 +88,24 @@ func getURIsignkeysHandler(db *sqlx.DB, cfg config.Config) http.HandlerFunc {
 			}
 		}
 
-		// create and start a cluster
-		cluster, err := riaksvc.GetRiakCluster(db, cfg.RiakAuthOptions)
+		store, err := secretsvc.Open(db, cfg)
 		if err != nil {
 			handleErr(http.StatusInternalServerError, err)
 			return
 		}
-		if err = cluster.Start(); err != nil {
-			handleErr(http.StatusInternalServerError, err)
-			return
-		}
 		defer func() {
-			if err := cluster.Stop(); err != nil {
+			if err := store.Close(); err != nil {
 				log.Errorf("%v\n", err)
 			}
 		}()
 
-		ro, err := riaksvc.FetchObjectValues(xmlID, CDNURIKeysBucket, cluster)
+		respBytes, ok, err := store.Fetch(CDNURIKeysBucket, xmlID)
 		if err != nil {
 			handleErr(http.StatusInternalServerError, err)
 			return
 		}
 
-		var respBytes []byte
-
-		if ro == nil {
+		if !ok {
 			var empty URISignerKeyset
 			respBytes, err = json.Marshal(empty)
 			if err != nil {
@@ -122,8 +114,6 @@ func getURIsignkeysHandler(db *sqlx.DB, cfg config.Config) http.HandlerFunc {
 				fmt.Fprintf(w, http.StatusText(http.StatusInternalServerError))
 				return
 			}
-		} else {
-			respBytes = ro[0].Value
 		}
 		w.Header().Set("Content-Type", "application/json")
 		fmt.Fprintf(w, "%s", respBytes)
@@ -135,8 +125,8 @@ func removeDeliveryServiceURIKeysHandler(db *sqlx.DB, cfg config.Config) http.Ha
 	return func(w http.ResponseWriter, r *http.Request) {
 		handleErr := tc.GetHandleErrorsFunc(w, r)
 
-		if cfg.RiakEnabled == false {
-			handleErr(http.StatusServiceUnavailable, fmt.Errorf("The RIAK service is unavailable"))
+		if !secretsvc.Enabled(cfg) {
+			handleErr(http.StatusServiceUnavailable, secretsvc.ErrUnavailable)
 			return
 		}
 
@@ -171,23 +161,18 @@ func removeDeliveryServiceURIKeysHandler(db *sqlx.DB, cfg config.Config) http.Ha
 			}
 		}
 
-		// create and start a cluster
-		cluster, err := riaksvc.GetRiakCluster(db, cfg.RiakAuthOptions)
+		store, err := secretsvc.Open(db, cfg)
 		if err != nil {
 			handleErr(http.StatusInternalServerError, err)
 			return
 		}
-		if err = cluster.Start(); err != nil {
-			handleErr(http.StatusInternalServerError, err)
-			return
-		}
 		defer func() {
-			if err := cluster.Stop(); err != nil {
+			if err := store.Close(); err != nil {
 				log.Errorf("%v\n", err)
 			}
 		}()
 
-		ro, err := riaksvc.FetchObjectValues(xmlID, CDNURIKeysBucket, cluster)
+		_, ok, err := store.Fetch(CDNURIKeysBucket, xmlID)
 		if err != nil {
 			handleErr(http.StatusInternalServerError, err)
 			return
@@ -196,9 +181,9 @@ func removeDeliveryServiceURIKeysHandler(db *sqlx.DB, cfg config.Config) http.Ha
 		// fetch the object and delete it if it exists.
 		var alert tc.Alerts
 
-		if ro == nil || ro[0].Value == nil {
+		if !ok {
 			alert = tc.CreateAlerts(tc.InfoLevel, "not deleted, no object found to delete")
-		} else if err := riaksvc.DeleteObject(xmlID, CDNURIKeysBucket, cluster); err != nil {
+		} else if err := store.Delete(CDNURIKeysBucket, xmlID); err != nil {
 			handleErr(http.StatusInternalServerError, err)
 			return
 		} else { // object successfully deleted
@@ -225,8 +210,8 @@ func saveDeliveryServiceURIKeysHandler(db *sqlx.DB, cfg config.Config) http.Hand
 
 		defer r.Body.Close()
 
-		if cfg.RiakEnabled == false {
-			handleErr(http.StatusServiceUnavailable, fmt.Errorf("The RIAK service is unavailable"))
+		if !secretsvc.Enabled(cfg) {
+			handleErr(http.StatusServiceUnavailable, secretsvc.ErrUnavailable)
 			return
 		}
 
@@ -280,32 +265,18 @@ func saveDeliveryServiceURIKeysHandler(db *sqlx.DB, cfg config.Config) http.Hand
 			return
 		}
 
-		// create and start a cluster
-		cluster, err := riaksvc.GetRiakCluster(db, cfg.RiakAuthOptions)
+		store, err := secretsvc.Open(db, cfg)
 		if err != nil {
 			handleErr(http.StatusInternalServerError, err)
 			return
 		}
-		if err = cluster.Start(); err != nil {
-			handleErr(http.StatusInternalServerError, err)
-			return
-		}
 		defer func() {
-			if err := cluster.Stop(); err != nil {
+			if err := store.Close(); err != nil {
 				log.Errorf("%v\n", err)
 			}
 		}()
 
-		// create a storage object and store the data
-		obj := &riak.Object{
-			ContentType:     "text/json",
-			Charset:         "utf-8",
-			ContentEncoding: "utf-8",
-			Key:             xmlID,
-			Value:           []byte(data),
-		}
-
-		err = riaksvc.SaveObject(obj, CDNURIKeysBucket, cluster)
+		err = store.Save(CDNURIKeysBucket, xmlID, data)
 		if err != nil {
 			log.Errorf("%v\n", err)
 			handleErr(http.StatusInternalServerError, err)
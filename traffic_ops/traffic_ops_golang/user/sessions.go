@@ -0,0 +1,187 @@
+package user
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-log"
+	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/tenant"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// WriteCapability is the capability required to see and revoke the sessions of other users.
+const WriteCapability = "user-write"
+
+// GetSessionsHandler serves the active login sessions of the user with the path id. Users may see their own sessions, and users with WriteCapability those of any user of their tenants.
+func GetSessionsHandler(db *sqlx.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		handleErrs := tc.GetHandleErrorsFunc(w, r)
+		username, userID, ok := getSessionUser(w, r, db, handleErrs)
+		if !ok {
+			return
+		}
+		currentUser, err := auth.GetCurrentUser(r.Context())
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, err)
+			return
+		}
+		if !canReadSessions(*currentUser, userID) {
+			handleErrs(http.StatusForbidden, errors.New("only the user, or users with the "+WriteCapability+" capability, may see a user's sessions"))
+			return
+		}
+		sessions, err := getSessions(db, userID)
+		if err != nil {
+			log.Errorln("getting sessions of user '" + username + "': " + err.Error())
+			handleErrs(http.StatusInternalServerError, tc.DBError)
+			return
+		}
+		respBts, err := json.Marshal(tc.UserSessionsResponse{Response: sessions})
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, err)
+			return
+		}
+		w.Header().Set(tc.ContentType, tc.ApplicationJson)
+		w.Write(respBts)
+	}
+}
+
+// DeleteSessionsHandler revokes the login sessions of the user with the path id. If the path has a session, only that session is revoked, otherwise all of the user's sessions are.
+func DeleteSessionsHandler(db *sqlx.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		handleErrs := tc.GetHandleErrorsFunc(w, r)
+		username, userID, ok := getSessionUser(w, r, db, handleErrs)
+		if !ok {
+			return
+		}
+		params, err := api.GetPathParams(r.Context())
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, err)
+			return
+		}
+		sessionID, oneSession := params["session"]
+
+		q := `DELETE FROM user_session WHERE tm_user = $1 AND ($2 = '' OR id = $2)`
+		result, err := db.Exec(q, userID, sessionID)
+		if err != nil {
+			log.Errorln("deleting sessions of user '" + username + "': " + err.Error())
+			handleErrs(http.StatusInternalServerError, tc.DBError)
+			return
+		}
+		deleted, err := result.RowsAffected()
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, tc.DBError)
+			return
+		}
+		if oneSession && deleted == 0 {
+			handleErrs(http.StatusNotFound, errors.New("no session with that id found"))
+			return
+		}
+
+		user, err := auth.GetCurrentUser(r.Context())
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, err)
+			return
+		}
+		api.CreateChangeLogRaw(api.ApiChange, "Revoked "+strconv.FormatInt(deleted, 10)+" sessions of user: "+username, *user, db)
+
+		respBts, err := json.Marshal(tc.CreateAlerts(tc.SuccessLevel, strconv.FormatInt(deleted, 10)+" sessions were revoked."))
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, err)
+			return
+		}
+		w.Header().Set(tc.ContentType, tc.ApplicationJson)
+		w.Write(respBts)
+	}
+}
+
+// getSessionUser returns the name and ID of the user with the path id, if the current user is authorized on its tenant. If not, or the user doesn't exist, an error is written and false is returned.
+func getSessionUser(w http.ResponseWriter, r *http.Request, db *sqlx.DB, handleErrs func(status int, errs ...error)) (string, int, bool) {
+	currentUser, err := auth.GetCurrentUser(r.Context())
+	if err != nil {
+		handleErrs(http.StatusInternalServerError, err)
+		return "", 0, false
+	}
+	params, err := api.GetPathParams(r.Context())
+	if err != nil {
+		handleErrs(http.StatusInternalServerError, err)
+		return "", 0, false
+	}
+	userID, err := strconv.Atoi(params["id"])
+	if err != nil {
+		handleErrs(http.StatusBadRequest, errors.New("id must be an integer"))
+		return "", 0, false
+	}
+
+	username := ""
+	tenantID := sql.NullInt64{}
+	if err := db.QueryRow(`SELECT username, tenant_id FROM tm_user WHERE id = $1`, userID).Scan(&username, &tenantID); err != nil {
+		if err == sql.ErrNoRows {
+			handleErrs(http.StatusNotFound, errors.New("no user with that id found"))
+			return "", 0, false
+		}
+		log.Errorln("querying user: " + err.Error())
+		handleErrs(http.StatusInternalServerError, tc.DBError)
+		return "", 0, false
+	}
+	if tenantID.Valid {
+		authorized, err := tenant.IsResourceAuthorizedToUser(int(tenantID.Int64), *currentUser, db)
+		if err != nil {
+			log.Errorln("checking user tenancy: " + err.Error())
+			handleErrs(http.StatusInternalServerError, tc.DBError)
+			return "", 0, false
+		}
+		if !authorized {
+			handleErrs(http.StatusForbidden, errors.New("not authorized on this tenant"))
+			return "", 0, false
+		}
+	}
+	return username, userID, true
+}
+
+// canReadSessions returns whether the user may see the sessions of the user with the given ID.
+func canReadSessions(user auth.CurrentUser, userID int) bool {
+	return user.ID == userID || user.HasCapability(WriteCapability)
+}
+
+func getSessions(db *sqlx.DB, userID int) ([]tc.UserSession, error) {
+	rows, err := db.Query(`SELECT id, created, last_seen, remote_addr, user_agent FROM user_session WHERE tm_user = $1 ORDER BY created`, userID)
+	if err != nil {
+		return nil, errors.New("querying sessions: " + err.Error())
+	}
+	defer rows.Close()
+	sessions := []tc.UserSession{}
+	for rows.Next() {
+		s := tc.UserSession{}
+		if err := rows.Scan(&s.ID, &s.Created, &s.LastSeen, &s.RemoteAddr, &s.UserAgent); err != nil {
+			return nil, errors.New("scanning sessions: " + err.Error())
+		}
+		sessions = append(sessions, s)
+	}
+	return sessions, nil
+}
@@ -0,0 +1,76 @@
+package user
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+	"github.com/jmoiron/sqlx"
+
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestGetSessions(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	now := time.Now()
+	rows := sqlmock.NewRows([]string{"id", "created", "last_seen", "remote_addr", "user_agent"})
+	rows = rows.AddRow("abc", now, now, "192.0.2.1:1234", nil)
+	mock.ExpectQuery("SELECT.*FROM user_session").WithArgs(1).WillReturnRows(rows)
+
+	sessions, err := getSessions(db, 1)
+	if err != nil {
+		t.Fatalf("getSessions expected: no error, actual: %v", err)
+	}
+	if len(sessions) != 1 {
+		t.Fatalf("getSessions expected: 1 session, actual: %v", len(sessions))
+	}
+	if s := sessions[0]; s.ID != "abc" || s.RemoteAddr == nil || *s.RemoteAddr != "192.0.2.1:1234" || s.UserAgent != nil {
+		t.Errorf("getSessions expected: session abc from 192.0.2.1:1234 with no user agent, actual: %+v", s)
+	}
+}
+
+func TestCanReadSessions(t *testing.T) {
+	tests := []struct {
+		name     string
+		user     auth.CurrentUser
+		expected bool
+	}{
+		{"owner", auth.CurrentUser{ID: 1, Capabilities: []string{"user-read"}}, true},
+		{"other user", auth.CurrentUser{ID: 2, Capabilities: []string{"user-read"}}, false},
+		{"all-read", auth.CurrentUser{ID: 2, Capabilities: []string{auth.CapabilityAllRead}}, false},
+		{"user-write", auth.CurrentUser{ID: 2, Capabilities: []string{"user-read", WriteCapability}}, true},
+		{"all-write", auth.CurrentUser{ID: 2, Capabilities: []string{auth.CapabilityAllWrite}}, true},
+	}
+	for _, test := range tests {
+		if actual := canReadSessions(test.user, 1); actual != test.expected {
+			t.Errorf("canReadSessions %s expected: %v, actual: %v", test.name, test.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package user
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"strconv"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-log"
+	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/dbhelpers"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/tenant"
+	"github.com/jmoiron/sqlx"
+)
+
+//we need a type alias to define functions on
+type TOUser tc.UserNullable
+
+//the refType is passed into the handlers where a copy of its type is used to decode the json.
+var refType = TOUser(tc.UserNullable{})
+
+func GetRefType() *TOUser {
+	return &refType
+}
+
+func (user TOUser) GetAuditName() string {
+	if user.Username != nil {
+		return *user.Username
+	}
+	if user.ID != nil {
+		return strconv.Itoa(*user.ID)
+	}
+	return "unknown"
+}
+
+func (user TOUser) GetKeyFieldsInfo() []api.KeyFieldInfo {
+	return []api.KeyFieldInfo{{"id", api.GetIntKey}}
+}
+
+//Implementation of the Identifier interface functions
+func (user TOUser) GetKeys() (map[string]interface{}, bool) {
+	if user.ID == nil {
+		return map[string]interface{}{"id": 0}, false
+	}
+	return map[string]interface{}{"id": *user.ID}, true
+}
+
+func (user *TOUser) SetKeys(keys map[string]interface{}) {
+	i, _ := keys["id"].(int) //this utilizes the non panicking type assertion, if the thrown away ok variable is false i will be the zero of the type, 0 here.
+	user.ID = &i
+}
+
+func (user TOUser) GetType() string {
+	return "user"
+}
+
+// IsTenantAuthorized implements the Tenantable interface to ensure the current user is authorized on the tenant of the user.
+// Users with no tenant are visible to everyone, as they are in Perl.
+func (user TOUser) IsTenantAuthorized(currentUser auth.CurrentUser, db *sqlx.DB) (bool, error) {
+	if user.TenantID == nil {
+		return true, nil
+	}
+	return tenant.IsResourceAuthorizedToUser(*user.TenantID, currentUser, db)
+}
+
+// Read returns the users the current user may see, which are those in the current user's tenant tree, and those with no tenant.
+func (user *TOUser) Read(db *sqlx.DB, parameters map[string]string, currentUser auth.CurrentUser) ([]interface{}, []error, tc.ApiErrorType) {
+	// Query Parameters to Database Query column mappings
+	// see the fields mapped in the SQL query
+	queryParamsToQueryCols := map[string]dbhelpers.WhereColumnInfo{
+		"id":          dbhelpers.WhereColumnInfo{"u.id", api.IsInt},
+		"role":        dbhelpers.WhereColumnInfo{"r.name", nil},
+		"tenant":      dbhelpers.WhereColumnInfo{"t.name", nil},
+		"tenantId":    dbhelpers.WhereColumnInfo{"u.tenant_id", api.IsInt},
+		"username":    dbhelpers.WhereColumnInfo{"u.username", nil},
+		"lastUpdated": dbhelpers.WhereColumnInfo{"u.last_updated", nil},
+	}
+	where, orderBy, queryValues, errs := dbhelpers.BuildWhereAndOrderBy(parameters, queryParamsToQueryCols)
+	if len(errs) > 0 {
+		return nil, errs, tc.DataConflictError
+	}
+	where = tenant.AddTenancyCheck(where, queryValues, "u.tenant_id", currentUser)
+
+	query := selectQuery() + where + orderBy
+	log.Debugln("Query is ", query)
+
+	rows, err := db.NamedQuery(query, queryValues)
+	if err != nil {
+		log.Errorf("Error querying Users: %v", err)
+		return nil, []error{tc.DBError}, tc.SystemError
+	}
+	defer rows.Close()
+
+	users := []interface{}{}
+	for rows.Next() {
+		var s tc.UserNullable
+		if err = rows.StructScan(&s); err != nil {
+			log.Errorf("error parsing User rows: %v", err)
+			return nil, []error{tc.DBError}, tc.SystemError
+		}
+		users = append(users, s)
+	}
+
+	return users, []error{}, tc.NoError
+}
+
+func selectQuery() string {
+	query := `SELECT
+u.address_line1,
+u.address_line2,
+u.city,
+u.company,
+u.country,
+u.email,
+u.full_name,
+u.gid,
+u.id,
+u.last_updated,
+u.new_user,
+u.phone_number,
+u.postal_code,
+u.public_ssh_key,
+u.registration_sent,
+u.role,
+r.name AS role_name,
+u.state_or_province,
+t.name AS tenant,
+u.tenant_id,
+u.uid,
+u.username
+
+FROM tm_user u
+LEFT JOIN role r ON u.role = r.id
+LEFT JOIN tenant t ON u.tenant_id = t.id`
+	return query
+}
@@ -0,0 +1,89 @@
+package user
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/test"
+	"github.com/jmoiron/sqlx"
+
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestReadUsers(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	cols := test.ColsFromStructByTag("db", tc.UserNullable{})
+	rows := sqlmock.NewRows(cols)
+	row := make([]driver.Value, len(cols))
+	for i, col := range cols {
+		switch col {
+		case "id":
+			row[i] = 1
+		case "username":
+			row[i] = "admin"
+		case "tenant_id":
+			row[i] = 2
+		case "last_updated":
+			row[i] = time.Now()
+		}
+	}
+	rows = rows.AddRow(row...)
+	mock.ExpectQuery("SELECT").WithArgs("admin", 2).WillReturnRows(rows)
+
+	users, errs, errType := refType.Read(db, map[string]string{"username": "admin"}, auth.CurrentUser{TenantID: 2})
+	if len(errs) > 0 {
+		t.Fatalf("user.Read expected: no errors, actual: %v with error type: %s", errs, errType.String())
+	}
+	if len(users) != 1 {
+		t.Fatalf("user.Read expected: len(users) == 1, actual: %v", len(users))
+	}
+	if u := users[0].(tc.UserNullable); u.Username == nil || *u.Username != "admin" || u.Email != nil {
+		t.Errorf("user.Read expected: user admin with no email, actual: %+v", u)
+	}
+}
+
+func TestInterfaces(t *testing.T) {
+	var i interface{}
+	i = &TOUser{}
+
+	if _, ok := i.(api.Reader); !ok {
+		t.Errorf("user must be reader")
+	}
+	if _, ok := i.(api.Identifier); !ok {
+		t.Errorf("user must be Identifier")
+	}
+	if _, ok := i.(api.Tenantable); !ok {
+		t.Errorf("user must be Tenantable")
+	}
+}
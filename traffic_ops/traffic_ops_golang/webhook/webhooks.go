@@ -0,0 +1,330 @@
+package webhook
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-log"
+	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/api"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/auth"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/dbhelpers"
+	"github.com/apache/incubator-trafficcontrol/traffic_ops/traffic_ops_golang/tovalidate"
+
+	"github.com/go-ozzo/ozzo-validation"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+//we need a type alias to define functions on
+type TOWebhook tc.WebhookNullable
+
+//the refType is passed into the handlers where a copy of its type is used to decode the json.
+var refType = TOWebhook{}
+
+func GetRefType() *TOWebhook {
+	return &refType
+}
+
+func (hook TOWebhook) GetKeyFieldsInfo() []api.KeyFieldInfo {
+	return []api.KeyFieldInfo{{"id", api.GetIntKey}}
+}
+
+//Implementation of the Identifier, Validator interface functions
+func (hook TOWebhook) GetKeys() (map[string]interface{}, bool) {
+	if hook.ID == nil {
+		return map[string]interface{}{"id": 0}, false
+	}
+	return map[string]interface{}{"id": *hook.ID}, true
+}
+
+func (hook *TOWebhook) SetKeys(keys map[string]interface{}) {
+	i, _ := keys["id"].(int) //this utilizes the non panicking type assertion, if the thrown away ok variable is false i will be the zero of the type, 0 here.
+	hook.ID = &i
+}
+
+func (hook TOWebhook) GetAuditName() string {
+	if hook.URL != nil {
+		return *hook.URL
+	}
+	if hook.ID != nil {
+		return strconv.Itoa(*hook.ID)
+	}
+	return "unknown"
+}
+
+func (hook TOWebhook) GetType() string {
+	return "webhook"
+}
+
+// Validate implements the api.Validator interface. The secret is only required when creating a webhook; if it's omitted from an update, the existing secret is kept.
+func (hook TOWebhook) Validate(db *sqlx.DB) []error {
+	secretRules := []validation.Rule{validation.Length(1, 0)}
+	if hook.ID == nil {
+		secretRules = append(secretRules, validation.Required)
+	}
+	errs := validation.Errors{
+		"url":        validation.Validate(hook.URL, validation.Required, validation.By(isHTTPURL)),
+		"secret":     validation.Validate(hook.Secret, secretRules...),
+		"eventTypes": validation.Validate(hook.EventTypes, validation.By(areEventTypes)),
+	}
+	return tovalidate.ToErrors(errs)
+}
+
+// isHTTPURL is a validation func which returns an error if the value isn't an absolute HTTP or HTTPS URL.
+func isHTTPURL(value interface{}) error {
+	s, ok := value.(*string)
+	if !ok || s == nil {
+		return nil
+	}
+	u, err := url.Parse(*s)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("must be an absolute http or https URL")
+	}
+	return nil
+}
+
+// areEventTypes is a validation func which returns an error if the value isn't a list of event types.
+func areEventTypes(value interface{}) error {
+	types, ok := value.([]string)
+	if !ok {
+		return nil
+	}
+	for _, t := range types {
+		valid := false
+		for _, eventType := range tc.EventTypes {
+			if t == eventType {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return errors.New("'" + t + "' is not an event type, must be one of " + strings.Join(tc.EventTypes, ", "))
+		}
+	}
+	return nil
+}
+
+func (hook *TOWebhook) Read(db *sqlx.DB, parameters map[string]string, user auth.CurrentUser) ([]interface{}, []error, tc.ApiErrorType) {
+	queryParamsToQueryCols := map[string]dbhelpers.WhereColumnInfo{
+		"active": dbhelpers.WhereColumnInfo{"active", api.IsBool},
+		"id":     dbhelpers.WhereColumnInfo{"id", api.IsInt},
+		"url":    dbhelpers.WhereColumnInfo{"url", nil},
+	}
+	where, orderBy, queryValues, errs := dbhelpers.BuildWhereAndOrderBy(parameters, queryParamsToQueryCols)
+	if len(errs) > 0 {
+		return nil, errs, tc.DataConflictError
+	}
+
+	query := selectQuery() + where + orderBy
+	log.Debugln("Query is ", query)
+
+	rows, err := db.NamedQuery(query, queryValues)
+	if err != nil {
+		log.Errorln("querying webhooks: " + err.Error())
+		return nil, []error{tc.DBError}, tc.SystemError
+	}
+	defer rows.Close()
+
+	hooks := []interface{}{}
+	for rows.Next() {
+		h := tc.WebhookNullable{}
+		if err = rows.Scan(&h.ID, &h.URL, pq.Array(&h.EventTypes), &h.Active, &h.LastUpdated); err != nil {
+			log.Errorln("scanning webhooks: " + err.Error())
+			return nil, []error{tc.DBError}, tc.SystemError
+		}
+		hooks = append(hooks, h)
+	}
+	return hooks, []error{}, tc.NoError
+}
+
+// Create implements the api.Creator interface. The secret isn't returned.
+func (hook *TOWebhook) Create(db *sqlx.DB, user auth.CurrentUser) (error, tc.ApiErrorType) {
+	if hook.EventTypes == nil {
+		hook.EventTypes = []string{}
+	}
+	if hook.Active == nil {
+		active := true
+		hook.Active = &active
+	}
+	lastUpdated := tc.TimeNoMod{}
+	if err := db.QueryRow(insertQuery(), *hook.URL, *hook.Secret, pq.Array(hook.EventTypes), *hook.Active).Scan(&hook.ID, &lastUpdated); err != nil {
+		log.Errorln("inserting webhook: " + err.Error())
+		return tc.DBError, tc.SystemError
+	}
+	hook.LastUpdated = &lastUpdated
+	hook.Secret = nil
+	return nil, tc.NoError
+}
+
+// Update implements the api.Updater interface. If the secret is omitted, the existing secret is kept. The secret isn't returned.
+func (hook *TOWebhook) Update(db *sqlx.DB, user auth.CurrentUser) (error, tc.ApiErrorType) {
+	return hook.update(db)
+}
+
+// UpdateTx updates the webhook in the given transaction, which the caller commits or rolls back.
+func (hook *TOWebhook) UpdateTx(tx *sqlx.Tx, user auth.CurrentUser) (error, tc.ApiErrorType) {
+	return hook.update(tx)
+}
+
+func (hook *TOWebhook) update(db sqlx.Queryer) (error, tc.ApiErrorType) {
+	if hook.EventTypes == nil {
+		hook.EventTypes = []string{}
+	}
+	if hook.Active == nil {
+		active := true
+		hook.Active = &active
+	}
+	secret := sql.NullString{}
+	if hook.Secret != nil {
+		secret = sql.NullString{String: *hook.Secret, Valid: true}
+	}
+	lastUpdated := tc.TimeNoMod{}
+	if err := db.QueryRowx(updateQuery(), *hook.URL, secret, pq.Array(hook.EventTypes), *hook.Active, *hook.ID).Scan(&lastUpdated); err != nil {
+		if err == sql.ErrNoRows {
+			return errors.New("no webhook found with this id"), tc.DataMissingError
+		}
+		log.Errorln("updating webhook: " + err.Error())
+		return tc.DBError, tc.SystemError
+	}
+	hook.LastUpdated = &lastUpdated
+	hook.Secret = nil
+	return nil, tc.NoError
+}
+
+// Delete implements the api.Deleter interface. The webhook's dead letters are deleted with it.
+func (hook *TOWebhook) Delete(db *sqlx.DB, user auth.CurrentUser) (error, tc.ApiErrorType) {
+	return hook.delete(db)
+}
+
+// DeleteTx deletes the webhook in the given transaction, which the caller commits or rolls back.
+func (hook *TOWebhook) DeleteTx(tx *sqlx.Tx, user auth.CurrentUser) (error, tc.ApiErrorType) {
+	return hook.delete(tx)
+}
+
+func (hook *TOWebhook) delete(db sqlx.Queryer) (error, tc.ApiErrorType) {
+	hookURL := ""
+	if err := db.QueryRowx(`DELETE FROM webhook WHERE id = $1 RETURNING url`, *hook.ID).Scan(&hookURL); err != nil {
+		if err == sql.ErrNoRows {
+			return errors.New("no webhook found with this id"), tc.DataMissingError
+		}
+		log.Errorln("deleting webhook: " + err.Error())
+		return tc.DBError, tc.SystemError
+	}
+	hook.URL = &hookURL
+	return nil, tc.NoError
+}
+
+// DeadLettersHandler serves the events which failed to be delivered to webhooks, newest first. The webhookId parameter limits them to one webhook.
+func DeadLettersHandler(db *sqlx.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		handleErrs := tc.GetHandleErrorsFunc(w, r)
+		params, err := api.GetCombinedParams(r)
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, err)
+			return
+		}
+		webhookID := sql.NullInt64{}
+		if idStr, ok := params["webhookId"]; ok {
+			id, err := strconv.Atoi(idStr)
+			if err != nil {
+				handleErrs(http.StatusBadRequest, errors.New("webhookId must be an integer"))
+				return
+			}
+			webhookID = sql.NullInt64{Int64: int64(id), Valid: true}
+		}
+		letters, err := getDeadLetters(db, webhookID)
+		if err != nil {
+			log.Errorln("getting webhook dead letters: " + err.Error())
+			handleErrs(http.StatusInternalServerError, tc.DBError)
+			return
+		}
+		respBts, err := json.Marshal(tc.WebhookDeadLettersResponse{Response: letters})
+		if err != nil {
+			handleErrs(http.StatusInternalServerError, err)
+			return
+		}
+		w.Header().Set(tc.ContentType, tc.ApplicationJson)
+		w.Write(respBts)
+	}
+}
+
+// getDeadLetters returns the dead letters of the given webhook, or of every webhook if the id is null, newest first.
+func getDeadLetters(db *sqlx.DB, webhookID sql.NullInt64) ([]tc.WebhookDeadLetter, error) {
+	rows, err := db.Query(`SELECT id, webhook, event_id, event_type, payload, attempts, last_error, created FROM webhook_delivery WHERE dead_lettered AND ($1::bigint IS NULL OR webhook = $1) ORDER BY id DESC`, webhookID)
+	if err != nil {
+		return nil, errors.New("querying: " + err.Error())
+	}
+	defer rows.Close()
+	letters := []tc.WebhookDeadLetter{}
+	for rows.Next() {
+		l := tc.WebhookDeadLetter{}
+		payload := []byte(nil)
+		if err := rows.Scan(&l.ID, &l.WebhookID, &l.EventID, &l.EventType, &payload, &l.Attempts, &l.LastError, &l.Created); err != nil {
+			return nil, errors.New("scanning: " + err.Error())
+		}
+		l.Payload = json.RawMessage(payload)
+		letters = append(letters, l)
+	}
+	return letters, nil
+}
+
+// selectQuery returns the query of webhooks. Secrets are never selected.
+func selectQuery() string {
+	query := `SELECT
+id,
+url,
+event_types,
+active,
+last_updated
+FROM webhook
+`
+	return query
+}
+
+func insertQuery() string {
+	query := `INSERT INTO webhook (
+url,
+secret,
+event_types,
+active) VALUES (
+$1,
+$2,
+$3,
+$4) RETURNING id, last_updated`
+	return query
+}
+
+func updateQuery() string {
+	query := `UPDATE webhook SET
+url=$1,
+secret=COALESCE($2, secret),
+event_types=$3,
+active=$4
+WHERE id=$5 RETURNING last_updated`
+	return query
+}
@@ -0,0 +1,62 @@
+package webhook
+
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/apache/incubator-trafficcontrol/lib/go-tc"
+)
+
+func strPtr(s string) *string { return &s }
+func intPtr(i int) *int       { return &i }
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		hook   TOWebhook
+		errors []string
+	}{
+		{"valid", TOWebhook{URL: strPtr("https://hooks.example.net/tc"), Secret: strPtr("s3cret"), EventTypes: []string{tc.EventTypeSnapshot}}, nil},
+		{"update keeping secret", TOWebhook{ID: intPtr(1), URL: strPtr("http://hooks.example.net/tc")}, nil},
+		{"create without secret", TOWebhook{URL: strPtr("http://hooks.example.net/tc")}, []string{"secret"}},
+		{"relative url", TOWebhook{URL: strPtr("hooks.example.net/tc"), Secret: strPtr("s3cret")}, []string{"url"}},
+		{"unknown event type", TOWebhook{URL: strPtr("http://hooks.example.net/tc"), Secret: strPtr("s3cret"), EventTypes: []string{"nonexistent"}}, []string{"eventTypes"}},
+	}
+	for _, test := range tests {
+		errs := test.hook.Validate(nil)
+		if len(errs) != len(test.errors) {
+			t.Errorf("Validate %s expected: %v errors, actual: %v", test.name, len(test.errors), errs)
+			continue
+		}
+		for _, field := range test.errors {
+			found := false
+			for _, err := range errs {
+				if strings.HasPrefix(err.Error(), "'"+field+"'") {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("Validate %s expected: error on %v, actual: %v", test.name, field, errs)
+			}
+		}
+	}
+}
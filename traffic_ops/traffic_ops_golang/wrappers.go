@@ -29,6 +29,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 	"unicode"
@@ -49,10 +50,16 @@ type AuthBase struct {
 	secret                 string
 	getCurrentUserInfoStmt *sqlx.Stmt
 	override               Middleware
+	// checkTokenStmt gets the username of an unexpired API token, by the token's hash. See auth.CheckTokenQuery.
+	checkTokenStmt *sqlx.Stmt
+	// checkSessionStmt checks that the session of a cookie hasn't been revoked. See auth.CheckSessionQuery.
+	checkSessionStmt *sqlx.Stmt
+	// userCache caches the users, sessions and tokens of getCurrentUserInfoStmt, checkSessionStmt and checkTokenStmt. It may be nil, which caches nothing.
+	userCache *auth.UserCache
 }
 
-// GetWrapper ...
-func (a AuthBase) GetWrapper(privLevelRequired int) Middleware {
+// GetWrapper returns middleware which authenticates the user, by API token or cookie, and forbids users whose role doesn't have the required capability.
+func (a AuthBase) GetWrapper(capabilityRequired string) Middleware {
 	if a.override != nil {
 		return a.override
 	}
@@ -80,6 +87,35 @@ func (a AuthBase) GetWrapper(privLevelRequired int) Middleware {
 				fmt.Fprintf(w, "%s", errBytes)
 			}
 
+			// API tokens are accepted instead of the cookie. Token requests don't get a cookie, so clients using tokens never use sessions.
+			if token, ok := auth.GetBearerToken(r); ok {
+				tokenUsername, valid, err := a.userCache.GetTokenUsername(auth.HashToken(token), func(tokenHash string) (string, time.Time, bool, error) {
+					return auth.CheckToken(a.checkTokenStmt, tokenHash)
+				})
+				if err != nil {
+					log.Errorln(err.Error())
+					handleErr(http.StatusInternalServerError, tc.DBError)
+					return
+				}
+				currentUserInfo := auth.CurrentUser{ID: -1}
+				if valid {
+					currentUserInfo = a.userCache.GetUser(tokenUsername, func(username string) auth.CurrentUser {
+						return auth.GetCurrentUserFromDB(a.getCurrentUserInfoStmt, username)
+					})
+				}
+				if currentUserInfo.ID == -1 {
+					handleErr(http.StatusUnauthorized, errors.New("Unauthorized, invalid or expired token."))
+					return
+				}
+				username = currentUserInfo.UserName
+				if !currentUserInfo.HasCapability(capabilityRequired) {
+					handleErr(http.StatusForbidden, errors.New("Forbidden."))
+					return
+				}
+				handlerFunc(w, r.WithContext(context.WithValue(r.Context(), auth.CurrentUserKey, currentUserInfo)))
+				return
+			}
+
 			cookie, err := r.Cookie(tocookie.Name)
 			if err != nil {
 				log.Errorf("error getting cookie: %s", err)
@@ -100,14 +136,32 @@ func (a AuthBase) GetWrapper(privLevelRequired int) Middleware {
 			}
 
 			username = oldCookie.AuthData
-			currentUserInfo := auth.GetCurrentUserFromDB(a.getCurrentUserInfoStmt, username)
-			if currentUserInfo.PrivLevel < privLevelRequired {
+			if oldCookie.Session != "" {
+				valid, err := a.userCache.IsSessionValid(oldCookie.Session, func(sessionID string) (bool, error) {
+					return auth.CheckSession(a.checkSessionStmt, sessionID, username)
+				})
+				if err != nil {
+					log.Errorln(err.Error())
+					handleErr(http.StatusInternalServerError, tc.DBError)
+					return
+				}
+				if !valid {
+					handleErr(http.StatusUnauthorized, errors.New("Unauthorized, session revoked, please log in."))
+					return
+				}
+			}
+			currentUserInfo := a.userCache.GetUser(username, func(username string) auth.CurrentUser {
+				return auth.GetCurrentUserFromDB(a.getCurrentUserInfoStmt, username)
+			})
+			if !currentUserInfo.HasCapability(capabilityRequired) {
 				handleErr(http.StatusForbidden, errors.New("Forbidden."))
 				return
 			}
 
-			newCookieVal := tocookie.Refresh(oldCookie, a.secret)
-			http.SetCookie(w, &http.Cookie{Name: tocookie.Name, Value: newCookieVal, Path: "/", HttpOnly: true})
+			// cookies without a session, created by the Perl Traffic Ops, can't be revoked, so they aren't refreshed
+			if newCookieVal, err := tocookie.Refresh(oldCookie, a.secret); err == nil {
+				http.SetCookie(w, &http.Cookie{Name: tocookie.Name, Value: newCookieVal, Path: "/", HttpOnly: true})
+			}
 
 			ctx := r.Context()
 			ctx = context.WithValue(ctx, auth.CurrentUserKey, currentUserInfo)
@@ -120,7 +174,8 @@ func (a AuthBase) GetWrapper(privLevelRequired int) Middleware {
 func wrapHeaders(h http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Credentials", "true")
-		w.Header().Set("Access-Control-Allow-Headers", "Origin, X-Requested-With, Content-Type, Accept, Set-Cookie, Cookie")
+		w.Header().Set("Access-Control-Allow-Headers", "Origin, X-Requested-With, Content-Type, Accept, Authorization, Set-Cookie, Cookie, If-Match, If-None-Match, If-Modified-Since, If-Unmodified-Since")
+		w.Header().Set("Access-Control-Expose-Headers", "ETag, Last-Modified")
 		w.Header().Set("Access-Control-Allow-Methods", "POST,GET,OPTIONS,PUT,DELETE")
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Header().Set("X-Server-Name", ServerName)
@@ -151,6 +206,8 @@ func wrapAccessLog(secret string, h http.Handler) http.HandlerFunc {
 		}
 		start := time.Now()
 		defer func() {
+			proxyRequestsTotal.Inc(r.Method, strconv.Itoa(interceptedCode(iw)))
+			proxyRequestDuration.Observe(time.Since(start).Seconds(), r.Method)
 			log.EventfRaw(`%s - %s [%s] "%v %v HTTP/1.1" %v %v %v "%v"`, r.RemoteAddr, user, time.Now().Format(AccessLogTimeFormat), r.Method, r.URL.Path, iw.code, iw.byteCount, int(time.Now().Sub(start)/time.Millisecond), r.UserAgent())
 		}()
 		h.ServeHTTP(iw, r)
@@ -254,6 +311,13 @@ func (i *Interceptor) Header() http.Header {
 	return i.w.Header()
 }
 
+// Flush implements http.Flusher, if the intercepted writer does, so streaming responses may be intercepted.
+func (i *Interceptor) Flush() {
+	if f, ok := i.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
 // BodyInterceptor fulfills the Writer interface, but records the body and doesn't actually write. This allows performing operations on the entire body written by a handler, for example, compressing or hashing. To actually write, call `RealWrite()`. Note this means `len(b)` and `nil` are always returned by `Write()`, any real write errors will be returned by `RealWrite()`.
 type BodyInterceptor struct {
 	w    http.ResponseWriter
@@ -65,6 +65,7 @@ func TestWrapHeaders(t *testing.T) {
 		"Access-Control-Allow-Headers":     nil,
 		"Access-Control-Allow-Methods":     nil,
 		"Access-Control-Allow-Origin":      nil,
+		"Access-Control-Expose-Headers":    nil,
 		"Content-Type":                     nil,
 		"Whole-Content-Sha512":             nil,
 		"X-Server-Name":                    nil,
@@ -135,8 +136,8 @@ func TestWrapAuth(t *testing.T) {
 	id := 1
 	secret := "secret"
 
-	rows := sqlmock.NewRows([]string{"priv_level", "username", "id", "tenant_id"})
-	rows.AddRow(30, "user1", 1, 1)
+	rows := sqlmock.NewRows([]string{"priv_level", "username", "id", "tenant_id", "capabilities"})
+	rows.AddRow(30, "user1", 1, 1, "{all-read,all-write}")
 	mock.ExpectPrepare("SELECT").ExpectQuery().WithArgs(userName).WillReturnRows(rows)
 
 	sqlStatement, err := prepareUserInfoStmt(db)
@@ -144,7 +145,7 @@ func TestWrapAuth(t *testing.T) {
 		t.Fatalf("could not create priv statement: %v\n", err)
 	}
 
-	authBase := AuthBase{secret, sqlStatement, nil}
+	authBase := AuthBase{secret, sqlStatement, nil, nil, nil, nil}
 
 	cookie := tocookie.New(userName, time.Now().Add(time.Minute), secret)
 
@@ -166,7 +167,7 @@ func TestWrapAuth(t *testing.T) {
 		fmt.Fprintf(w, "%s", respBts)
 	}
 
-	authWrapper := authBase.GetWrapper(15)
+	authWrapper := authBase.GetWrapper("server-write")
 
 	f := authWrapper(handler)
 
@@ -178,7 +179,7 @@ func TestWrapAuth(t *testing.T) {
 
 	r.Header.Add("Cookie", tocookie.Name+"="+cookie)
 
-	expected := auth.CurrentUser{UserName: userName, ID: id, PrivLevel: 30, TenantID: 1}
+	expected := auth.CurrentUser{UserName: userName, ID: id, PrivLevel: 30, TenantID: 1, Capabilities: []string{"all-read", "all-write"}}
 
 	expectedBody, err := json.Marshal(expected)
 	if err != nil {
@@ -191,6 +192,11 @@ func TestWrapAuth(t *testing.T) {
 		t.Errorf("received: %s\n expected: %s\n", w.Body.Bytes(), expectedBody)
 	}
 
+	// the cookie has no session, so it can't be revoked, and isn't refreshed
+	if setCookie := w.Header().Get("Set-Cookie"); setCookie != "" {
+		t.Errorf("received: Set-Cookie %s\n expected: no refreshed cookie without a session\n", setCookie)
+	}
+
 	w = httptest.NewRecorder()
 	r, err = http.NewRequest("", "/", nil)
 	if err != nil {
@@ -211,3 +217,114 @@ func TestWrapAuth(t *testing.T) {
 }
 
 // TODO: TestWrapAccessLog
+
+func TestWrapAuthToken(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	defer db.Close()
+
+	token := "token1"
+	mock.ExpectPrepare("SELECT")
+	userStmt, err := prepareUserInfoStmt(db)
+	if err != nil {
+		t.Fatalf("could not create user statement: %v\n", err)
+	}
+	mock.ExpectPrepare("SELECT")
+	tokenStmt, err := db.Preparex(auth.CheckTokenQuery)
+	if err != nil {
+		t.Fatalf("could not create token statement: %v\n", err)
+	}
+
+	authBase := AuthBase{"secret", userStmt, nil, tokenStmt, nil, nil}
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		user, err := auth.GetCurrentUser(r.Context())
+		if err != nil {
+			t.Fatalf("unable to get user: %v", err)
+			return
+		}
+		fmt.Fprintf(w, "%s", user.UserName)
+	}
+	f := authBase.GetWrapper("server-read")(handler)
+
+	userCols := []string{"priv_level", "username", "id", "tenant_id", "capabilities"}
+	mock.ExpectQuery("SELECT.*FROM api_token").WithArgs(auth.HashToken(token)).WillReturnRows(sqlmock.NewRows([]string{"username", "expires"}).AddRow("user1", nil))
+	mock.ExpectQuery("SELECT.*FROM tm_user").WithArgs("user1").WillReturnRows(sqlmock.NewRows(userCols).AddRow(30, "user1", 1, 1, "{server-read}"))
+
+	w := httptest.NewRecorder()
+	r, err := http.NewRequest("", "/", nil)
+	if err != nil {
+		t.Error("Error creating new request")
+	}
+	r.Header.Add("Authorization", auth.BearerPrefix+token)
+	f(w, r)
+
+	if w.Body.String() != "user1" {
+		t.Errorf("received: %s\n expected: %s\n", w.Body.String(), "user1")
+	}
+	if _, ok := w.HeaderMap["Set-Cookie"]; ok {
+		t.Error("expected no cookie for a token request")
+	}
+
+	// a user whose role doesn't have the capability is forbidden
+	mock.ExpectQuery("SELECT.*FROM api_token").WithArgs(auth.HashToken(token)).WillReturnRows(sqlmock.NewRows([]string{"username", "expires"}).AddRow("user1", time.Now().Add(time.Hour)))
+	mock.ExpectQuery("SELECT.*FROM tm_user").WithArgs("user1").WillReturnRows(sqlmock.NewRows(userCols).AddRow(30, "user1", 1, 1, "{all-write,server-write}"))
+
+	w = httptest.NewRecorder()
+	r, err = http.NewRequest("", "/", nil)
+	if err != nil {
+		t.Error("Error creating new request")
+	}
+	r.Header.Add("Authorization", auth.BearerPrefix+token)
+	f(w, r)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("received status: %v expected: %v", w.Code, http.StatusForbidden)
+	}
+
+	// an unknown or expired token isn't found by the query
+	mock.ExpectQuery("SELECT.*FROM api_token").WithArgs(auth.HashToken(token)).WillReturnRows(sqlmock.NewRows([]string{"username", "expires"}))
+
+	w = httptest.NewRecorder()
+	r, err = http.NewRequest("", "/", nil)
+	if err != nil {
+		t.Error("Error creating new request")
+	}
+	r.Header.Add("Authorization", auth.BearerPrefix+token)
+	f(w, r)
+
+	expectedError := `{"alerts":[{"text":"Unauthorized, invalid or expired token.","level":"error"}]}`
+	if w.Body.String() != expectedError {
+		t.Errorf("received: %s\n expected: %s\n", w.Body.String(), expectedError)
+	}
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("received status: %v expected: %v", w.Code, http.StatusUnauthorized)
+	}
+
+	// with a cache, the token and its user are only queried once
+	authBase.userCache = auth.NewUserCache(time.Minute)
+	f = authBase.GetWrapper("server-read")(handler)
+	mock.ExpectQuery("SELECT.*FROM api_token").WithArgs(auth.HashToken(token)).WillReturnRows(sqlmock.NewRows([]string{"username", "expires"}).AddRow("user1", nil))
+	mock.ExpectQuery("SELECT.*FROM tm_user").WithArgs("user1").WillReturnRows(sqlmock.NewRows(userCols).AddRow(30, "user1", 1, 1, "{server-read}"))
+	for i := 0; i < 2; i++ {
+		w = httptest.NewRecorder()
+		r, err = http.NewRequest("", "/", nil)
+		if err != nil {
+			t.Error("Error creating new request")
+		}
+		r.Header.Add("Authorization", auth.BearerPrefix+token)
+		f(w, r)
+		if w.Body.String() != "user1" {
+			t.Errorf("cached request %v received: %s\n expected: %s\n", i, w.Body.String(), "user1")
+		}
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
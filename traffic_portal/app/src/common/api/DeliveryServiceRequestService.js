@@ -108,6 +108,23 @@ var DeliveryServiceRequestService = function(Restangular, $http, $q, locationUti
 		return request.promise;
 	};
 
+	this.fulfillDeliveryServiceRequest = function(id) {
+		var request = $q.defer();
+
+		$http.post(ENV.api['root'] + "deliveryservice_requests/" + id + "/fulfill", {})
+			.then(
+				function(result) {
+					request.resolve(result.data.response);
+				},
+				function(fault) {
+					messageModel.setMessages(fault.data.alerts, false);
+					request.reject(fault);
+				}
+			);
+
+		return request.promise;
+	};
+
 	this.getDeliveryServiceRequestComments = function(queryParams) {
 		return Restangular.all('deliveryservice_request_comments').getList(queryParams);
 	};
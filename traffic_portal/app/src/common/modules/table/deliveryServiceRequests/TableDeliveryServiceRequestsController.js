@@ -267,7 +267,7 @@ var TableDeliveryServicesRequestsController = function(dsRequests, $scope, $stat
 
 		var params = {
 			title: 'Complete Delivery Service Request',
-			message: 'Are you sure you want to mark this delivery service request as complete?'
+			message: 'Are you sure you want to complete this delivery service request, and apply its change to the ' + request.deliveryService.xmlId + ' delivery service?'
 		};
 		var modalInstance = $uibModal.open({
 			templateUrl: 'common/modules/dialog/confirm/dialog.confirm.tpl.html',
@@ -280,7 +280,7 @@ var TableDeliveryServicesRequestsController = function(dsRequests, $scope, $stat
 			}
 		});
 		modalInstance.result.then(function() {
-			deliveryServiceRequestService.updateDeliveryServiceRequestStatus(request.id, 'complete').
+			deliveryServiceRequestService.fulfillDeliveryServiceRequest(request.id).
 				then(function() {
 					$scope.refresh();
 					createComment(request, 'Enter comment...');
@@ -48,7 +48,7 @@ var FormEditDeliveryServiceRequestController = function(deliveryServiceRequest,
 	};
 
 	$scope.fulfillable = function() {
-		return $scope.dsRequest.status == 'submitted';
+		return ($scope.dsRequest.status == 'submitted' || $scope.dsRequest.status == 'pending');
 	};
 
 	$scope.open = function() {
@@ -89,16 +89,23 @@ var FormEditDeliveryServiceRequestController = function(deliveryServiceRequest,
 		});
 	};
 
-	var updateDeliveryServiceRequest = function() {
-		var promises = [];
-		// update the ds request if the ds request actually changed
-		if ($scope.deliveryServiceForm.$dirty) {
-			promises.push(deliveryServiceRequestService.updateDeliveryServiceRequest($scope.dsRequest.id, $scope.dsRequest));
-		}
-		// make sure the ds request is assigned to the user that is fulfilling the request
-		promises.push(deliveryServiceRequestService.assignDeliveryServiceRequest($scope.dsRequest.id, userModel.user.id));
-		// set the status to 'pending'
-		promises.push(deliveryServiceRequestService.updateDeliveryServiceRequestStatus($scope.dsRequest.id, 'pending'));
+	var fulfillDeliveryServiceRequest = function() {
+		// save the ds request if it actually changed, assign it to the user that is fulfilling it, and make it pending, before fulfilling it
+		var saved = ($scope.deliveryServiceForm.$dirty) ? deliveryServiceRequestService.updateDeliveryServiceRequest($scope.dsRequest.id, $scope.dsRequest) : $q.when();
+		return saved.
+			then(function() {
+				return deliveryServiceRequestService.assignDeliveryServiceRequest($scope.dsRequest.id, userModel.user.id);
+			}).
+			then(function() {
+				if ($scope.dsRequest.status == 'pending') {
+					return;
+				}
+				return deliveryServiceRequestService.updateDeliveryServiceRequestStatus($scope.dsRequest.id, 'pending');
+			}).
+			then(function() {
+				// the ds is created, updated or deleted per the ds request, and the ds request completed, by fulfilling it
+				return deliveryServiceRequestService.fulfillDeliveryServiceRequest($scope.dsRequest.id);
+			});
 	};
 
 	$scope.fulfillRequest = function(ds) {
@@ -118,31 +125,15 @@ var FormEditDeliveryServiceRequestController = function(deliveryServiceRequest,
 			}
 		});
 		modalInstance.result.then(function() {
-			// create, update or delete the ds per the ds request
-			if ($scope.changeType == 'create') {
-				deliveryServiceService.createDeliveryService(ds).
+			if ($scope.changeType == 'create' || $scope.changeType == 'update') {
+				fulfillDeliveryServiceRequest().
 					then(
-						function(result) {
-							updateDeliveryServiceRequest(); // after a successful create, update the ds request, assignee and status
-							messageModel.setMessages([ { level: 'success', text: 'Delivery Service [ ' + ds.xmlId + ' ] created' } ], true);
-							locationUtils.navigateToPath('/delivery-services/' + result.data.response[0].id + '?type=' + result.data.response[0].type);
+						function(fulfillment) {
+							messageModel.setMessages([ { level: 'success', text: 'Delivery Service [ ' + ds.xmlId + ' ] ' + $scope.changeType + 'd' } ], true);
+							locationUtils.navigateToPath('/delivery-services/' + fulfillment.deliveryService.id + '?type=' + $stateParams.type);
 						},
-						function(fault) {
+						function() {
 							$anchorScroll(); // scrolls window to top
-							messageModel.setMessages(fault.data.alerts, false);
-						}
-					);
-			} else if ($scope.changeType == 'update') {
-				deliveryServiceService.updateDeliveryService(ds).
-					then(
-						function(result) {
-							updateDeliveryServiceRequest(); // after a successful update, update the ds request, assignee and status
-							messageModel.setMessages([ { level: 'success', text: 'Delivery Service [ ' + ds.xmlId + ' ] updated' } ], true);
-							locationUtils.navigateToPath('/delivery-services/' + result.data.response[0].id + '?type=' + result.data.response[0].type);
-						},
-						function(fault) {
-							$anchorScroll(); // scrolls window to top
-							messageModel.setMessages(fault.data.alerts, false);
 						}
 					);
 			} else if ($scope.changeType == 'delete') {
@@ -162,16 +153,14 @@ var FormEditDeliveryServiceRequestController = function(deliveryServiceRequest,
 					}
 				});
 				modalInstance.result.then(function() {
-					deliveryServiceService.deleteDeliveryService(ds).
+					fulfillDeliveryServiceRequest().
 						then(
 							function() {
-								updateDeliveryServiceRequest(); // after a successful delete, update the ds request, assignee and status and navigate to ds requests page
 								messageModel.setMessages([ { level: 'success', text: 'Delivery service [ ' + ds.xmlId + ' ] deleted' } ], true);
 								locationUtils.navigateToPath('/delivery-service-requests');
 							},
-							function(fault) {
+							function() {
 								$anchorScroll(); // scrolls window to top
-								messageModel.setMessages(fault.data.alerts, false);
 							}
 						);
 				}, function () {